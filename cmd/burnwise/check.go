@@ -0,0 +1,88 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"burnwise/internal/db"
+	"burnwise/internal/models"
+	"burnwise/internal/repository"
+	"burnwise/internal/service"
+)
+
+// Exit codes of -check-budgets, for cron jobs and shell notifications
+const (
+	checkOK       = 0
+	checkWarning  = 1
+	checkCritical = 2
+	checkFailed   = 3
+)
+
+// checkCodes are the exit codes of the budget alert levels
+var checkCodes = map[models.AlertLevel]int{
+	models.AlertNone:     checkOK,
+	models.AlertWarning:  checkWarning,
+	models.AlertCritical: checkCritical,
+}
+
+// runCheckBudgets prints one line for each active budget with at least the
+// warning share spent and returns checkWarning if there are any, or
+// checkCritical if one is past the critical share. Thresholds that are nil
+// are taken from the settings.
+func runCheckBudgets(dataDir string, warnFlag, critFlag *float64) int {
+	database, err := db.InitDB(db.GetDBPath(dataDir))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize database: %v\n", err)
+		return checkFailed
+	}
+	sqlDB, err := database.DB()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get database connection: %v\n", err)
+		return checkFailed
+	}
+	defer sqlDB.Close()
+
+	settingsService, err := service.NewSettingsService(dataDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize settings: %v\n", err)
+		return checkFailed
+	}
+
+	warn, crit := settingsService.Get().BudgetAlerts.Thresholds()
+	if warnFlag != nil {
+		warn = *warnFlag
+	}
+	if critFlag != nil {
+		crit = *critFlag
+	}
+	if warn < 0 || crit < warn {
+		fmt.Fprintf(os.Stderr, "Invalid thresholds: -warn %g must not be negative and at most -crit %g\n", warn, crit)
+		return checkFailed
+	}
+
+	txRepo := repository.NewTransactionRepository(database)
+	budgetService := service.NewBudgetService(repository.NewBudgetRepository(database), txRepo)
+
+	over, err := budgetService.GetOverThreshold(context.Background(), warn)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to check budgets: %v\n", err)
+		return checkFailed
+	}
+
+	code := checkOK
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	for _, status := range over {
+		level := status.AlertLevel(warn, crit)
+		mark := "⚠"
+		if level == models.AlertCritical {
+			mark = "✗"
+		}
+		code = max(code, checkCodes[level])
+		fmt.Fprintf(w, "%s %s\t%.0f%%\t$%.2f / $%.2f\n",
+			mark, status.Budget.CategoryLabel(), status.PercentUsed, status.Spent, status.Budget.Amount)
+	}
+	w.Flush()
+	return code
+}
@@ -0,0 +1,109 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"burnwise/internal/db"
+	"burnwise/internal/models"
+	"burnwise/internal/service"
+)
+
+// runDoctor prints the resolved data paths and checks that the database and
+// settings file can be read. It returns the process exit code.
+func runDoctor(dataDir string) int {
+	healthy := true
+
+	fmt.Println("BurnWise doctor")
+	fmt.Println()
+
+	// Data directory
+	fmt.Printf("Data directory: %s\n", dataDir)
+	if info, err := os.Stat(dataDir); err != nil {
+		if os.IsNotExist(err) {
+			fmt.Println("  - does not exist yet (created on first run)")
+		} else {
+			fmt.Printf("  ✗ %v\n", err)
+			healthy = false
+		}
+	} else if !info.IsDir() {
+		fmt.Println("  ✗ exists but is not a directory")
+		healthy = false
+	} else if err := db.EnsureDataDir(dataDir); err != nil {
+		fmt.Printf("  ✗ %v\n", err)
+		healthy = false
+	} else {
+		fmt.Println("  ✓ exists and is writable")
+	}
+
+	// Database
+	dbPath := db.GetDBPath(dataDir)
+	fmt.Printf("Database:       %s\n", dbPath)
+	if _, err := os.Stat(dbPath); os.IsNotExist(err) {
+		fmt.Println("  - not created yet (created on first run)")
+	} else if version, err := db.ReadSchemaVersion(dbPath); err != nil {
+		fmt.Printf("  ✗ %v\n", err)
+		healthy = false
+	} else {
+		fmt.Printf("  ✓ schema version %d (current: %d)\n", version, db.SchemaVersion)
+	}
+
+	// Settings
+	settingsPath := db.GetSettingsPath(dataDir)
+	fmt.Printf("Settings:       %s\n", settingsPath)
+	data, err := os.ReadFile(settingsPath)
+	if os.IsNotExist(err) {
+		fmt.Println("  - not created yet (defaults are written on first run)")
+	} else if err != nil {
+		fmt.Printf("  ✗ %v\n", err)
+		healthy = false
+	} else {
+		var settings models.Settings
+		if err := json.Unmarshal(data, &settings); err != nil {
+			fmt.Printf("  ✗ failed to parse: %v\n", err)
+			healthy = false
+		} else {
+			fmt.Printf("  ✓ parses (version %s, default currency %s)\n", settings.Version, settings.Currencies.Default)
+			if !checkRatesFile(settings.Currencies, dataDir) {
+				healthy = false
+			}
+			if !models.IsKnownRoundingMode(settings.Currencies.Rounding) {
+				fmt.Printf("  ✗ unknown rounding mode %q (use half_up, half_even or down)\n", settings.Currencies.Rounding)
+				healthy = false
+			}
+		}
+	}
+
+	fmt.Println()
+	if !healthy {
+		fmt.Println("Problems found.")
+		return 1
+	}
+	fmt.Println("Everything looks good.")
+	return 0
+}
+
+// checkRatesFile validates the exchange rates file named in the settings and
+// lists the currencies it provides
+func checkRatesFile(currencies models.CurrencySettings, dataDir string) bool {
+	path := currencies.RatesFilePath(dataDir)
+	if path == "" {
+		return true
+	}
+
+	fmt.Printf("Rates file:     %s\n", path)
+	loaded, err := service.NewCurrencyService(nil).LoadRatesFromFile(path)
+	if err != nil {
+		fmt.Printf("  ✗ %v\n", err)
+		return false
+	}
+
+	source := "used when the API can't be reached"
+	if currencies.PreferFileRates {
+		source = "preferred over the API"
+	}
+	fmt.Printf("  ✓ rates for %s (%s)\n", strings.Join(loaded, ", "), source)
+	return true
+}
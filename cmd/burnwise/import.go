@@ -0,0 +1,189 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/signal"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"burnwise/internal/db"
+	"burnwise/internal/models"
+	"burnwise/internal/repository"
+	"burnwise/internal/service"
+)
+
+// runImport previews the transactions in a CSV file, marking rows with
+// validation problems, and imports the valid rows once confirmed. Unless
+// noSeed is set, a fresh database gets the default categories first. It
+// returns the process exit code.
+func runImport(dataDir, path string, assumeYes, noSeed bool) int {
+	database, err := db.InitDB(db.GetDBPath(dataDir))
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize database: %v\n", err)
+		return 1
+	}
+	sqlDB, err := database.DB()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to get database connection: %v\n", err)
+		return 1
+	}
+	defer sqlDB.Close()
+
+	settingsService, err := service.NewSettingsService(dataDir)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to initialize settings: %v\n", err)
+		return 1
+	}
+
+	ctx := context.Background()
+	txRepo := repository.NewTransactionRepository(database)
+	categoryRepo := repository.NewCategoryRepository(database)
+	seeded, err := seedDefaultCategories(ctx, service.NewCategoryService(categoryRepo), noSeed)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		return 1
+	}
+	if len(seeded) > 0 {
+		fmt.Printf("Created %d default categories.\n", len(seeded))
+	}
+	txService := service.NewTransactionService(txRepo, newCurrencyService(dataDir, settingsService))
+	importService := service.NewImportService(txService, categoryRepo)
+
+	file, err := os.Open(path)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to open %s: %v\n", path, err)
+		return 1
+	}
+	defer file.Close()
+
+	transactions, err := importService.ParseCSV(ctx, file)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to parse %s: %v\n", path, err)
+		return 1
+	}
+	if len(transactions) == 0 {
+		fmt.Println("No rows to import.")
+		return 0
+	}
+
+	problems := importService.ValidateTransactions(ctx, transactions)
+	byRow := make(map[int][]service.ImportError)
+	for _, problem := range problems {
+		byRow[problem.Row] = append(byRow[problem.Row], problem)
+	}
+	var warnings []service.ImportError
+	for _, warning := range importService.DateWarnings(transactions, time.Now()) {
+		if len(byRow[warning.Row]) == 0 {
+			warnings = append(warnings, warning)
+		}
+	}
+
+	fmt.Printf("Preview of %s\n\n", path)
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ROW\t\tDATE\tTYPE\tCATEGORY\tDESCRIPTION\tAMOUNT")
+	for row, tx := range transactions {
+		status := "✓"
+		if len(byRow[row]) > 0 {
+			status = "✗"
+		} else if hasWarning(warnings, row) {
+			status = "⚠"
+		}
+		date := "?"
+		if !tx.Date.IsZero() {
+			date = tx.Date.Format("2006-01-02")
+		}
+		fmt.Fprintf(w, "%d\t%s\t%s\t%s\t%s\t%s\t%.2f %s\n",
+			row+1, status, date, tx.DisplayType(), tx.Category.Name, tx.Description, tx.Amount, tx.Currency)
+	}
+	w.Flush()
+
+	valid := len(transactions) - len(byRow)
+	if len(problems) > 0 {
+		fmt.Printf("\n%d rows have problems and will be skipped:\n", len(byRow))
+		for _, problem := range problems {
+			fmt.Printf("  ✗ %v\n", problem)
+		}
+	}
+
+	if len(warnings) > 0 {
+		fmt.Printf("\n%d rows have unusual dates, possibly typos, and will be imported as they are:\n", len(warnings))
+		for _, warning := range warnings {
+			fmt.Printf("  ⚠ %v\n", warning)
+		}
+	}
+
+	if valid == 0 {
+		fmt.Println("\nNothing to import.")
+		return 1
+	}
+
+	var validRows []*models.Transaction
+	for row, tx := range transactions {
+		if len(byRow[row]) == 0 {
+			validRows = append(validRows, tx)
+		}
+	}
+	archived, err := importService.ArchivedCategories(ctx, validRows)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to check categories: %v\n", err)
+		return 1
+	}
+	for _, category := range archived {
+		fmt.Printf("\nCategory %q is archived and will be unarchived.", category.Name)
+	}
+	if len(archived) > 0 {
+		fmt.Println()
+	}
+
+	question := fmt.Sprintf("\nImport %d valid rows?", valid)
+	if len(warnings) > 0 {
+		question = fmt.Sprintf("\nImport %d valid rows, %d of them with unusual dates?", valid, len(warnings))
+	}
+	if !assumeYes && !confirm(question) {
+		fmt.Println("Import cancelled, nothing was written.")
+		return 0
+	}
+
+	// Ctrl+C stops the import; it is written all at once, so nothing is
+	// written then either
+	importCtx, stop := signal.NotifyContext(ctx, os.Interrupt)
+	defer stop()
+	imported, _, err := importService.Import(importCtx, transactions)
+	if errors.Is(err, context.Canceled) {
+		fmt.Println("\nImport interrupted, nothing was written.")
+		return 1
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Import failed, nothing was written: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Imported %d transactions.\n", imported)
+	return 0
+}
+
+// hasWarning reports whether one of warnings is about row
+func hasWarning(warnings []service.ImportError, row int) bool {
+	for _, warning := range warnings {
+		if warning.Row == row {
+			return true
+		}
+	}
+	return false
+}
+
+// confirm asks a yes/no question on stdin, defaulting to no
+func confirm(question string) bool {
+	fmt.Printf("%s [y/N] ", question)
+	answer, err := bufio.NewReader(os.Stdin).ReadString('\n')
+	if err != nil {
+		return false
+	}
+	answer = strings.ToLower(strings.TrimSpace(answer))
+	return answer == "y" || answer == "yes"
+}
@@ -1,14 +1,19 @@
 package main
 
 import (
+	"context"
+	"errors"
 	"flag"
 	"fmt"
 	"log"
 	"os"
+	"os/signal"
+	"path/filepath"
 	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 
+	"burnwise/internal/api"
 	"burnwise/internal/db"
 	"burnwise/internal/models"
 	"burnwise/internal/repository"
@@ -18,18 +23,110 @@ import (
 
 func main() {
 	// Parse command-line flags
-	exportCmd := flag.String("export", "", "Export data to CSV (transactions, report, budgets)")
+	exportCmd := flag.String("export", "", "Export data to CSV (transactions, report, yearly, budgets, categories, category-history)")
+	formatFlag := flag.String("format", "csv", "Export format: csv, or md for a Markdown report")
 	outputFile := flag.String("output", "", "Output file for export")
 	monthFlag := flag.Int("month", 0, "Month for report export (1-12)")
-	yearFlag := flag.Int("year", time.Now().Year(), "Year for report export")
+	yearFlag := flag.Int("year", time.Now().Year(), "Year for report and yearly export")
+	categoryFlag := flag.Uint("category", 0, "Category ID for category-history export (default: all categories)")
+	dataDirFlag := flag.String("data-dir", "", "Directory for the database and settings (default: $XDG_DATA_HOME/burnwise or ~/.local/share/burnwise)")
+	doctorFlag := flag.Bool("doctor", false, "Print resolved paths and check the database and settings file")
+	processDateFlag := flag.String("process-date", "", "Process recurring transactions due up to this date (YYYY-MM-DD, default: today)")
+	importFlag := flag.String("import", "", "Preview a transactions CSV file and import its valid rows")
+	yesFlag := flag.Bool("yes", false, "Import without asking for confirmation")
+	dryRunFlag := flag.Bool("dry-run", false, "Report the recurring transactions that would be generated, without writing them")
+	serveFlag := flag.String("serve", "", "Serve a read-only JSON API on this address instead of starting the UI (e.g. :8123, bound to localhost)")
+	tokenFlag := flag.String("token", "", "Bearer token required by the -serve API")
+	noSeedFlag := flag.Bool("no-seed", false, "Don't create the default categories on a fresh database")
+	checkBudgetsFlag := flag.Bool("check-budgets", false, "Print the budgets past the warning threshold; exits 1 if there are any, 2 if one is past the critical threshold")
+	warnFlag := flag.Float64("warn", 0, "Warning threshold for -check-budgets, in percent of the budget (default from settings, 80); 0 lists every budget")
+	critFlag := flag.Float64("crit", 0, "Critical threshold for -check-budgets, in percent of the budget (default from settings, 100)")
+	readOnlyFlag := flag.Bool("read-only", false, "Open the database read-only, e.g. while another instance is running")
 	flag.Parse()
 
+	processDate := time.Now()
+	if *processDateFlag != "" {
+		parsed, err := time.ParseInLocation("2006-01-02", *processDateFlag, time.Local)
+		if err != nil {
+			log.Fatalf("Invalid -process-date %q: expected YYYY-MM-DD", *processDateFlag)
+		}
+		// Include everything due on the given day
+		processDate = parsed.AddDate(0, 0, 1).Add(-time.Second)
+	}
+
+	dataDir := *dataDirFlag
+	if dataDir == "" {
+		dataDir = db.GetDefaultDataDir()
+	}
+
+	// Handle doctor command
+	if *doctorFlag {
+		os.Exit(runDoctor(dataDir))
+	}
+
+	if err := db.EnsureDataDir(dataDir); err != nil {
+		log.Fatalf("Error: %v", err)
+	}
+	if moved, err := db.MigrateLegacySettings(dataDir); err != nil {
+		log.Printf("Warning: %v", err)
+	} else if moved != "" {
+		log.Printf("Moved the settings from %s to %s", moved, db.GetSettingsPath(dataDir))
+	}
+
+	// Handle import command
+	if *importFlag != "" {
+		os.Exit(runImport(dataDir, *importFlag, *yesFlag, *noSeedFlag))
+	}
+
+	// Handle budget check command
+	if *checkBudgetsFlag {
+		// Only thresholds given on the command line override the settings,
+		// so that -warn 0 lists every budget
+		var warn, crit *float64
+		flag.Visit(func(f *flag.Flag) {
+			switch f.Name {
+			case "warn":
+				warn = warnFlag
+			case "crit":
+				crit = critFlag
+			}
+		})
+		os.Exit(runCheckBudgets(dataDir, warn, crit))
+	}
+
 	// Handle export command
 	if *exportCmd != "" {
-		handleExport(*exportCmd, *outputFile, *monthFlag, *yearFlag)
+		handleExport(dataDir, *exportCmd, *formatFlag, *outputFile, *monthFlag, *yearFlag, *categoryFlag, *noSeedFlag)
 		return
 	}
-	database, err := db.InitDB(db.GetDefaultDBPath())
+
+	// Only one instance writes to the database; a second one can read it.
+	// A dry run writes nothing, so it doesn't need the lock either
+	readOnly := *readOnlyFlag
+	var lock *db.InstanceLock
+	if !readOnly && !*dryRunFlag {
+		var err error
+		lock, err = db.AcquireLock(dataDir)
+		var running *db.AlreadyRunningError
+		switch {
+		case errors.As(err, &running):
+			fmt.Printf("%v.\n", running)
+			if !confirm("Continue read-only?") {
+				os.Exit(1)
+			}
+			readOnly = true
+		case err != nil:
+			log.Fatalf("Error: %v", err)
+		default:
+			defer lock.Release()
+		}
+	}
+
+	openDB := db.InitDB
+	if readOnly {
+		openDB = db.OpenReadOnly
+	}
+	database, err := openDB(db.GetDBPath(dataDir))
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -40,8 +137,12 @@ func main() {
 	}
 	defer sqlDB.Close()
 
-	// Initialize settings service
-	settingsService, err := service.NewSettingsService("data")
+	// Initialize settings service, which a read-only instance never saves
+	newSettingsService := service.NewSettingsService
+	if readOnly {
+		newSettingsService = service.NewReadOnlySettingsService
+	}
+	settingsService, err := newSettingsService(dataDir)
 	if err != nil {
 		log.Fatalf("Failed to initialize settings: %v", err)
 	}
@@ -50,30 +151,157 @@ func main() {
 	categoryRepo := repository.NewCategoryRepository(database)
 	budgetRepo := repository.NewBudgetRepository(database)
 	recurringRepo := repository.NewRecurringTransactionRepository(database)
+	accountRepo := repository.NewAccountRepository(database)
 
-	currencyService := service.NewCurrencyService(settingsService)
+	currencyService := newCurrencyService(dataDir, settingsService)
 	txService := service.NewTransactionService(txRepo, currencyService)
 	txService.SetRecurringRepo(recurringRepo)
 	categoryService := service.NewCategoryService(categoryRepo)
+	categoryService.SetCurrencyService(currencyService)
 	budgetService := service.NewBudgetService(budgetRepo, txRepo)
 	recurringService := service.NewRecurringTransactionService(recurringRepo, txRepo, currencyService)
+	budgetService.SetRecurringService(recurringService)
+	accountService := service.NewAccountService(accountRepo)
+
+	ctx := context.Background()
 
-	// Process any due recurring transactions on startup
-	if _, err := recurringService.ProcessDueTransactions(time.Now()); err != nil {
-		log.Printf("Warning: Failed to process recurring transactions: %v", err)
+	var seeded []*models.Category
+	if !readOnly && !*dryRunFlag {
+		seeded, err = seedDefaultCategories(ctx, categoryService, *noSeedFlag)
+		if err != nil {
+			log.Printf("Warning: %v", err)
+		}
+	}
+
+	// Track destructive actions so they can be undone within the session
+	undoService := service.NewUndoService(service.DefaultUndoLimit)
+	txService.SetUndoService(undoService)
+	categoryService.SetUndoService(undoService)
+	budgetService.SetUndoService(undoService)
+	recurringService.SetUndoService(undoService)
+
+	// Preview catch-up processing without writing anything
+	if *dryRunFlag {
+		os.Exit(runDryRun(recurringService, processDate))
+	}
+
+	// Process any due recurring transactions on startup, which the
+	// instance holding the lock does when it is read-only
+	startedAt := time.Now()
+	if !readOnly {
+		if _, err := recurringService.ProcessDueTransactions(ctx, processDate); err != nil {
+			log.Printf("Warning: Failed to process recurring transactions: %v", err)
+		}
+	}
+
+	// Serve the JSON API for dashboards instead of the UI. It only reads, so
+	// the lock is let go once the due transactions are in and the UI can be
+	// started alongside it
+	if *serveFlag != "" {
+		if lock != nil {
+			lock.Release()
+		}
+		server := api.NewServer(txService, categoryService, budgetService, recurringService)
+		server.SetToken(*tokenFlag)
+		os.Exit(runServe(server, *serveFlag))
+	}
+
+	// Warnings can't go to the terminal while the UI has it
+	currencyService.SetLogger(openLogFile(dataDir))
+
+	app := ui.NewApp(txService, categoryService, budgetService, currencyService, settingsService, recurringService, undoService)
+	if len(seeded) > 0 {
+		app.SetWelcome(seeded)
+	}
+	app.SetReadOnly(readOnly)
+	app.SetAccountService(accountService)
+
+	// Point out what needs attention before the dashboard
+	digest, err := service.NewSummaryService(budgetService, recurringService).GetStartupDigest(ctx, startedAt)
+	if err != nil {
+		log.Printf("Warning: Failed to build the startup summary: %v", err)
+	} else if !digest.IsEmpty() {
+		app.SetStartupDigest(digest)
 	}
 
-	app := ui.NewApp(txService, categoryService, budgetService, currencyService, settingsService, recurringService)
+	// Exports started from the command palette are written to the data directory
+	exportService := service.NewExportService(txService)
+	exportService.SetBudgetService(budgetService)
+	exportService.SetSettingsService(settingsService)
+	exportService.SetCategoryService(categoryService)
+	app.SetExportService(exportService, dataDir)
 
 	p := tea.NewProgram(app, tea.WithAltScreen())
 	if _, err := p.Run(); err != nil {
 		fmt.Printf("Error running program: %v\n", err)
 		os.Exit(1)
 	}
+
+	// Snapshot the month's transactions after a clean exit, which the
+	// instance holding the lock does when it is read-only
+	if autoExport := settingsService.Get().AutoExport; autoExport.Enabled && !readOnly {
+		if _, err := exportService.AutoExportMonth(ctx, autoExport.Dir(dataDir), autoExport.KeepCount(), time.Now()); err != nil {
+			log.Printf("Warning: Failed to auto-export transactions: %v", err)
+		}
+	}
+}
+
+// newCurrencyService creates the currency service, keeping fetched rates in
+// the data directory unless the settings are read-only, and loads the
+// exchange rates file named in the settings, if any. Warnings, such as a
+// stale rate being used, are logged to stderr.
+func newCurrencyService(dataDir string, settingsService *service.SettingsService) *service.CurrencyService {
+	currencyService := service.NewCurrencyService(settingsService)
+	currencyService.SetLogger(log.Default())
+	if !settingsService.IsReadOnly() {
+		if err := currencyService.SetRatesCachePath(filepath.Join(dataDir, "rates_cache.json")); err != nil {
+			log.Printf("Warning: Failed to load cached exchange rates: %v", err)
+		}
+	}
+	if path := settingsService.Get().Currencies.RatesFilePath(dataDir); path != "" {
+		if _, err := currencyService.LoadRatesFromFile(path); err != nil {
+			log.Printf("Warning: Failed to load exchange rates: %v", err)
+		}
+	}
+	return currencyService
+}
+
+// openLogFile returns a logger appending to burnwise.log in the data
+// directory, or nil when the file can't be opened
+func openLogFile(dataDir string) *log.Logger {
+	file, err := os.OpenFile(filepath.Join(dataDir, "burnwise.log"), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		log.Printf("Warning: Failed to open log file: %v", err)
+		return nil
+	}
+	return log.New(file, "", log.LstdFlags)
+}
+
+// seedDefaultCategories gives a fresh database the default categories,
+// unless noSeed is set, and returns those it created
+func seedDefaultCategories(ctx context.Context, categoryService *service.CategoryService, noSeed bool) ([]*models.Category, error) {
+	if noSeed {
+		return nil, nil
+	}
+	seeded, err := categoryService.SeedOnFirstRun(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create default categories: %w", err)
+	}
+	return seeded, nil
 }
 
-func handleExport(exportType, outputFile string, month, year int) {
-	database, err := db.InitDB(db.GetDefaultDBPath())
+func handleExport(dataDir, exportType, format, outputFile string, month, year int, categoryID uint, noSeed bool) {
+	if format != "csv" && format != "md" {
+		fmt.Printf("Unknown export format: %s\n", format)
+		fmt.Println("Available formats: csv, md")
+		os.Exit(1)
+	}
+	if format == "md" && exportType != "report" {
+		fmt.Println("Markdown format is only available for -export report")
+		os.Exit(1)
+	}
+
+	database, err := db.InitDB(db.GetDBPath(dataDir))
 	if err != nil {
 		log.Fatalf("Failed to initialize database: %v", err)
 	}
@@ -85,7 +313,7 @@ func handleExport(exportType, outputFile string, month, year int) {
 	defer sqlDB.Close()
 
 	// Initialize settings service
-	settingsService, err := service.NewSettingsService("data")
+	settingsService, err := service.NewSettingsService(dataDir)
 	if err != nil {
 		log.Fatalf("Failed to initialize settings: %v", err)
 	}
@@ -93,11 +321,16 @@ func handleExport(exportType, outputFile string, month, year int) {
 	// Initialize services
 	txRepo := repository.NewTransactionRepository(database)
 	budgetRepo := repository.NewBudgetRepository(database)
+	categoryRepo := repository.NewCategoryRepository(database)
 	
-	currencyService := service.NewCurrencyService(settingsService)
+	currencyService := newCurrencyService(dataDir, settingsService)
 	txService := service.NewTransactionService(txRepo, currencyService)
 	budgetService := service.NewBudgetService(budgetRepo, txRepo)
+	categoryService := service.NewCategoryService(categoryRepo)
 	exportService := service.NewExportService(txService)
+	exportService.SetBudgetService(budgetService)
+	exportService.SetSettingsService(settingsService)
+	exportService.SetCategoryService(categoryService)
 
 	// Determine output
 	var output *os.File
@@ -111,10 +344,18 @@ func handleExport(exportType, outputFile string, month, year int) {
 		defer output.Close()
 	}
 
+	// Ctrl+C stops a long export instead of leaving it running
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+
+	if _, err := seedDefaultCategories(ctx, categoryService, noSeed); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
 	switch exportType {
 	case "transactions":
 		filter := &models.TransactionFilter{}
-		if err := exportService.ExportTransactionsCSV(output, filter); err != nil {
+		if err := exportService.ExportTransactionsCSV(ctx, output, filter); err != nil {
 			log.Fatalf("Failed to export transactions: %v", err)
 		}
 		if outputFile != "" {
@@ -125,24 +366,92 @@ func handleExport(exportType, outputFile string, month, year int) {
 		if month == 0 {
 			month = int(time.Now().Month())
 		}
-		if err := exportService.ExportMonthlyReportCSV(output, year, time.Month(month)); err != nil {
+		if format == "md" {
+			report, err := exportService.GenerateMonthlyReportMarkdown(ctx, year, time.Month(month))
+			if err != nil {
+				log.Fatalf("Failed to generate report: %v", err)
+			}
+			if _, err := fmt.Fprint(output, report); err != nil {
+				log.Fatalf("Failed to write report: %v", err)
+			}
+		} else if err := exportService.ExportMonthlyReportCSV(ctx, output, year, time.Month(month)); err != nil {
 			log.Fatalf("Failed to export report: %v", err)
 		}
 		if outputFile != "" {
 			fmt.Printf("Monthly report exported to %s\n", outputFile)
 		}
 
+	case "yearly":
+		if err := exportService.ExportYearlyReportCSV(ctx, output, year, time.Now()); err != nil {
+			log.Fatalf("Failed to export yearly report: %v", err)
+		}
+		if outputFile != "" {
+			fmt.Printf("Yearly report exported to %s\n", outputFile)
+		}
+
 	case "budgets":
-		if err := exportService.ExportBudgetStatusCSV(output, budgetService); err != nil {
+		if err := exportService.ExportBudgetStatusCSV(ctx, output, budgetService); err != nil {
 			log.Fatalf("Failed to export budgets: %v", err)
 		}
 		if outputFile != "" {
 			fmt.Printf("Budget status exported to %s\n", outputFile)
 		}
 
+	case "categories":
+		if err := exportService.ExportCategoriesCSV(ctx, output); err != nil {
+			log.Fatalf("Failed to export categories: %v", err)
+		}
+		if outputFile != "" {
+			fmt.Printf("Categories exported to %s\n", outputFile)
+		}
+
+	case "category-history":
+		if categoryID != 0 {
+			err = exportService.ExportCategoryHistoryCSV(ctx, output, categoryID)
+		} else {
+			err = exportService.ExportAllCategoryHistoryCSV(ctx, output)
+		}
+		if err != nil {
+			log.Fatalf("Failed to export category history: %v", err)
+		}
+		if outputFile != "" {
+			fmt.Printf("Category history exported to %s\n", outputFile)
+		}
+
 	default:
 		fmt.Printf("Unknown export type: %s\n", exportType)
-		fmt.Println("Available types: transactions, report, budgets")
+		fmt.Println("Available types: transactions, report, yearly, budgets, categories, category-history")
 		os.Exit(1)
 	}
+}
+
+func runDryRun(recurringService *service.RecurringTransactionService, asOf time.Time) int {
+	preview, err := recurringService.PreviewDueTransactions(context.Background(), asOf)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to preview recurring transactions: %v\n", err)
+		return 1
+	}
+
+	fmt.Printf("Dry run: recurring transactions due up to %s\n\n", asOf.Format("2006-01-02"))
+	if len(preview) == 0 {
+		fmt.Println("Nothing to generate.")
+		return 0
+	}
+
+	var income, expenses float64
+	for _, tx := range preview {
+		sign := "-"
+		if tx.Type == models.TransactionTypeIncome {
+			sign = "+"
+			income += tx.AmountUSD
+		} else {
+			expenses += tx.AmountUSD
+		}
+		fmt.Printf("%s  %-20s %-30s %s%10.2f %s\n",
+			tx.Date.Format("2006-01-02"), tx.Category.Name, tx.Description, sign, tx.Amount, tx.Currency)
+	}
+
+	fmt.Printf("\n%d transactions would be generated (income $%.2f, expenses $%.2f).\n", len(preview), income, expenses)
+	fmt.Println("Run without -dry-run to create them.")
+	return 0
 }
\ No newline at end of file
@@ -0,0 +1,39 @@
+package main
+
+import (
+	"fmt"
+	"net"
+	"net/http"
+	"os"
+	"time"
+
+	"burnwise/internal/api"
+)
+
+// runServe serves the read-only JSON API until the process is stopped. It
+// returns the process exit code.
+func runServe(server *api.Server, addr string) int {
+	addr = listenAddress(addr)
+	httpServer := &http.Server{
+		Addr:              addr,
+		Handler:           server.Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
+	fmt.Printf("Serving the BurnWise API on http://%s\n", addr)
+	if err := httpServer.ListenAndServe(); err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to serve: %v\n", err)
+		return 1
+	}
+	return 0
+}
+
+// listenAddress binds to localhost when addr only names a port, e.g.
+// ":8123", so the API is not exposed to the network unless asked for
+func listenAddress(addr string) string {
+	host, port, err := net.SplitHostPort(addr)
+	if err != nil || host != "" {
+		return addr
+	}
+	return net.JoinHostPort("127.0.0.1", port)
+}
@@ -0,0 +1,205 @@
+package api
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"burnwise/internal/models"
+	"burnwise/internal/service"
+)
+
+// defaultUpcomingDays is how far ahead /recurring/upcoming looks when no
+// days parameter is given
+const defaultUpcomingDays = 30
+
+// Server serves read-only JSON endpoints backed by the services, for
+// pulling figures into external dashboards
+type Server struct {
+	txService        *service.TransactionService
+	categoryService  *service.CategoryService
+	budgetService    *service.BudgetService
+	recurringService *service.RecurringTransactionService
+	token            string
+}
+
+func NewServer(
+	txService *service.TransactionService,
+	categoryService *service.CategoryService,
+	budgetService *service.BudgetService,
+	recurringService *service.RecurringTransactionService,
+) *Server {
+	return &Server{
+		txService:        txService,
+		categoryService:  categoryService,
+		budgetService:    budgetService,
+		recurringService: recurringService,
+	}
+}
+
+// SetToken requires requests to send "Authorization: Bearer <token>".
+// An empty token leaves the endpoints open.
+func (s *Server) SetToken(token string) {
+	s.token = token
+}
+
+// Handler returns the HTTP handler with all endpoints registered
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /summary/current-month", s.handleCurrentMonthSummary)
+	mux.HandleFunc("GET /burn-rate", s.handleBurnRate)
+	mux.HandleFunc("GET /transactions", s.handleTransactions)
+	mux.HandleFunc("GET /budgets/status", s.handleBudgetStatus)
+	mux.HandleFunc("GET /recurring/upcoming", s.handleUpcoming)
+	return s.authenticate(mux)
+}
+
+func (s *Server) authenticate(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.token != "" {
+			given, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+			if !ok || subtle.ConstantTimeCompare([]byte(given), []byte(s.token)) != 1 {
+				w.Header().Set("WWW-Authenticate", "Bearer")
+				writeError(w, http.StatusUnauthorized, fmt.Errorf("missing or invalid bearer token"))
+				return
+			}
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+func (s *Server) handleCurrentMonthSummary(w http.ResponseWriter, r *http.Request) {
+	summary, err := s.txService.GetCurrentMonthSummary(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to get summary: %w", err))
+		return
+	}
+	writeJSON(w, summary)
+}
+
+func (s *Server) handleBurnRate(w http.ResponseWriter, r *http.Request) {
+	burnRate, err := s.txService.GetCurrentMonthBurnRate(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to get burn rate: %w", err))
+		return
+	}
+	writeJSON(w, burnRate)
+}
+
+// handleTransactions lists transactions, optionally limited to a date range
+// (from and to as YYYY-MM-DD, both inclusive) and a category given by ID
+// or name
+func (s *Server) handleTransactions(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	filter := &models.TransactionFilter{}
+
+	if from := query.Get("from"); from != "" {
+		date, err := time.ParseInLocation("2006-01-02", from, time.Local)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid from date %q: expected YYYY-MM-DD", from))
+			return
+		}
+		filter.StartDate = date
+	}
+	if to := query.Get("to"); to != "" {
+		date, err := time.ParseInLocation("2006-01-02", to, time.Local)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid to date %q: expected YYYY-MM-DD", to))
+			return
+		}
+		// Include everything on the given day
+		filter.EndDate = date.AddDate(0, 0, 1).Add(-time.Second)
+	}
+
+	if category := query.Get("category"); category != "" {
+		id, err := s.findCategory(r.Context(), category)
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		filter.CategoryID = id
+	}
+
+	transactions, err := s.txService.GetByFilter(r.Context(), filter)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to get transactions: %w", err))
+		return
+	}
+	if transactions == nil {
+		transactions = []*models.Transaction{}
+	}
+	writeJSON(w, transactions)
+}
+
+// findCategory resolves a category ID or a case-insensitive category name
+func (s *Server) findCategory(ctx context.Context, value string) (uint, error) {
+	if id, err := strconv.ParseUint(value, 10, 64); err == nil {
+		if _, err := s.categoryService.GetByID(ctx, uint(id)); err != nil {
+			return 0, fmt.Errorf("unknown category %q", value)
+		}
+		return uint(id), nil
+	}
+
+	categories, err := s.categoryService.GetAll(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get categories: %w", err)
+	}
+	for _, category := range categories {
+		if strings.EqualFold(category.Name, value) {
+			return category.ID, nil
+		}
+	}
+	return 0, fmt.Errorf("unknown category %q", value)
+}
+
+func (s *Server) handleBudgetStatus(w http.ResponseWriter, r *http.Request) {
+	statuses, err := s.budgetService.GetAllStatuses(r.Context())
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to get budget status: %w", err))
+		return
+	}
+	if statuses == nil {
+		statuses = []*models.BudgetStatus{}
+	}
+	writeJSON(w, statuses)
+}
+
+func (s *Server) handleUpcoming(w http.ResponseWriter, r *http.Request) {
+	days := defaultUpcomingDays
+	if value := r.URL.Query().Get("days"); value != "" {
+		parsed, err := strconv.Atoi(value)
+		if err != nil || parsed < 0 {
+			writeError(w, http.StatusBadRequest, fmt.Errorf("invalid days %q: expected a non-negative number", value))
+			return
+		}
+		days = parsed
+	}
+
+	upcoming, err := s.recurringService.GetUpcoming(r.Context(), days)
+	if err != nil {
+		writeError(w, http.StatusInternalServerError, fmt.Errorf("failed to get upcoming recurring transactions: %w", err))
+		return
+	}
+	if upcoming == nil {
+		upcoming = []*models.RecurringTransaction{}
+	}
+	writeJSON(w, upcoming)
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
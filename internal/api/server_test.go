@@ -0,0 +1,199 @@
+package api
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"gorm.io/gorm"
+
+	"burnwise/internal/models"
+	"burnwise/internal/repository"
+	"burnwise/internal/service"
+	test "burnwise/test/helpers"
+)
+
+func setupServer(t *testing.T) (*Server, *gorm.DB) {
+	db := test.SetupTestDB(t)
+
+	settingsService, err := service.NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+
+	txRepo := repository.NewTransactionRepository(db)
+	recurringRepo := repository.NewRecurringTransactionRepository(db)
+	currencyService := service.NewCurrencyService(settingsService)
+	txService := service.NewTransactionService(txRepo, currencyService)
+	txService.SetRecurringRepo(recurringRepo)
+
+	server := NewServer(
+		txService,
+		service.NewCategoryService(repository.NewCategoryRepository(db)),
+		service.NewBudgetService(repository.NewBudgetRepository(db), txRepo),
+		service.NewRecurringTransactionService(recurringRepo, txRepo, currencyService),
+	)
+	return server, db
+}
+
+func get(t *testing.T, handler http.Handler, target string, v interface{}) *httptest.ResponseRecorder {
+	req := httptest.NewRequest(http.MethodGet, target, nil)
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+	if v != nil && rec.Code == http.StatusOK {
+		require.NoError(t, json.Unmarshal(rec.Body.Bytes(), v))
+	}
+	return rec
+}
+
+func TestServer_SummaryAndBurnRate(t *testing.T) {
+	server, db := setupServer(t)
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	test.CreateTestTransaction(t, db, 40, food.ID)
+	test.CreateTestTransaction(t, db, 60, food.ID)
+	handler := server.Handler()
+
+	var summary models.TransactionSummary
+	rec := get(t, handler, "/summary/current-month", &summary)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, 100.0, summary.TotalExpenses)
+	assert.Equal(t, 2, summary.Count)
+
+	var burnRate models.BurnRateSummary
+	rec = get(t, handler, "/burn-rate", &burnRate)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Equal(t, 100.0, burnRate.OneTimeExpenses)
+	assert.Equal(t, 100.0, burnRate.TotalBurn)
+}
+
+func TestServer_Transactions(t *testing.T) {
+	server, db := setupServer(t)
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	rent := test.CreateTestCategory(t, db, "Rent", models.TransactionTypeExpense)
+
+	create := func(categoryID uint, amount float64, date string) {
+		tx := test.CreateTestTransaction(t, db, amount, categoryID)
+		parsed, err := time.ParseInLocation("2006-01-02 15:04", date, time.Local)
+		require.NoError(t, err)
+		require.NoError(t, db.Model(tx).Update("date", parsed).Error)
+	}
+	create(food.ID, 12, "2025-03-01 09:00")
+	create(food.ID, 30, "2025-03-31 21:00")
+	create(rent.ID, 1200, "2025-03-05 10:00")
+	create(food.ID, 8, "2025-04-01 08:00")
+	handler := server.Handler()
+
+	t.Run("date range includes the last day", func(t *testing.T) {
+		var transactions []models.Transaction
+		rec := get(t, handler, "/transactions?from=2025-03-01&to=2025-03-31", &transactions)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.Len(t, transactions, 3)
+	})
+
+	t.Run("category by name or ID", func(t *testing.T) {
+		var byName, byID []models.Transaction
+		get(t, handler, "/transactions?from=2025-03-01&to=2025-03-31&category=food", &byName)
+		get(t, handler, "/transactions?from=2025-03-01&to=2025-03-31&category="+strconv.FormatUint(uint64(food.ID), 10), &byID)
+		assert.Len(t, byName, 2)
+		assert.Equal(t, byName, byID)
+		for _, tx := range byName {
+			assert.Equal(t, "Food", tx.Category.Name)
+		}
+	})
+
+	t.Run("no matches is an empty list", func(t *testing.T) {
+		rec := get(t, handler, "/transactions?from=2030-01-01", nil)
+		require.Equal(t, http.StatusOK, rec.Code)
+		assert.JSONEq(t, "[]", rec.Body.String())
+	})
+
+	t.Run("bad parameters", func(t *testing.T) {
+		rec := get(t, handler, "/transactions?from=03/01/2025", nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), "invalid from date")
+
+		rec = get(t, handler, "/transactions?category=Travel", nil)
+		assert.Equal(t, http.StatusBadRequest, rec.Code)
+		assert.Contains(t, rec.Body.String(), `unknown category \"Travel\"`)
+	})
+}
+
+func TestServer_BudgetStatus(t *testing.T) {
+	server, db := setupServer(t)
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	test.CreateTestBudget(t, db, food.ID, 200)
+	test.CreateTestTransaction(t, db, 50, food.ID)
+
+	var statuses []models.BudgetStatus
+	rec := get(t, server.Handler(), "/budgets/status", &statuses)
+	require.Equal(t, http.StatusOK, rec.Code)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, 50.0, statuses[0].Spent)
+	assert.Equal(t, 150.0, statuses[0].Remaining)
+	assert.Equal(t, "Food", statuses[0].Budget.Category.Name)
+}
+
+func TestServer_UpcomingRecurring(t *testing.T) {
+	server, db := setupServer(t)
+	rent := test.CreateTestCategory(t, db, "Rent", models.TransactionTypeExpense)
+
+	for _, dueIn := range []int{5, 60} {
+		require.NoError(t, db.Create(&models.RecurringTransaction{
+			Type:        models.TransactionTypeExpense,
+			Amount:      100,
+			Currency:    "USD",
+			CategoryID:  rent.ID,
+			Frequency:   models.FrequencyMonthly,
+			StartDate:   time.Now(),
+			NextDueDate: time.Now().AddDate(0, 0, dueIn),
+			IsActive:    true,
+		}).Error)
+	}
+	handler := server.Handler()
+
+	var upcoming []models.RecurringTransaction
+	rec := get(t, handler, "/recurring/upcoming", &upcoming)
+	require.Equal(t, http.StatusOK, rec.Code)
+	assert.Len(t, upcoming, 1)
+
+	get(t, handler, "/recurring/upcoming?days=90", &upcoming)
+	assert.Len(t, upcoming, 2)
+
+	rec = get(t, handler, "/recurring/upcoming?days=soon", nil)
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+}
+
+func TestServer_Token(t *testing.T) {
+	server, _ := setupServer(t)
+	server.SetToken("s3cret")
+	handler := server.Handler()
+
+	rec := get(t, handler, "/burn-rate", nil)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code)
+	assert.Equal(t, "Bearer", rec.Header().Get("WWW-Authenticate"))
+
+	for token, want := range map[string]int{
+		"Bearer wrong":  http.StatusUnauthorized,
+		"s3cret":        http.StatusUnauthorized,
+		"Bearer s3cret": http.StatusOK,
+	} {
+		req := httptest.NewRequest(http.MethodGet, "/burn-rate", nil)
+		req.Header.Set("Authorization", token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, want, rec.Code, token)
+	}
+}
+
+func TestServer_ReadOnly(t *testing.T) {
+	server, _ := setupServer(t)
+
+	req := httptest.NewRequest(http.MethodPost, "/transactions", nil)
+	rec := httptest.NewRecorder()
+	server.Handler().ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+}
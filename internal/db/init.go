@@ -12,6 +12,14 @@ import (
 	"burnwise/internal/models"
 )
 
+// SchemaVersion is stored in the SQLite user_version pragma after migrations
+const SchemaVersion = 22
+
+// busyTimeoutParam makes a connection wait up to 5s for another one, such
+// as a second Burnwise, to finish writing before failing with "database is
+// locked"
+const busyTimeoutParam = "_busy_timeout=5000"
+
 func InitDB(dbPath string) (*gorm.DB, error) {
 	dir := filepath.Dir(dbPath)
 	if err := os.MkdirAll(dir, 0755); err != nil {
@@ -22,7 +30,8 @@ func InitDB(dbPath string) (*gorm.DB, error) {
 		Logger: logger.Default.LogMode(logger.Silent),
 	}
 
-	db, err := gorm.Open(sqlite.Open(dbPath), config)
+	// WAL lets the database be read while another connection writes
+	db, err := gorm.Open(sqlite.Open(dbPath+"?"+busyTimeoutParam+"&_journal_mode=WAL"), config)
 	if err != nil {
 		return nil, fmt.Errorf("failed to connect to database: %w", err)
 	}
@@ -31,8 +40,30 @@ func InitDB(dbPath string) (*gorm.DB, error) {
 		return nil, fmt.Errorf("failed to run migrations: %w", err)
 	}
 
-	if err := seedDefaultData(db); err != nil {
-		return nil, fmt.Errorf("failed to seed default data: %w", err)
+	if err := db.Exec(fmt.Sprintf("PRAGMA user_version = %d", SchemaVersion)).Error; err != nil {
+		return nil, fmt.Errorf("failed to record schema version: %w", err)
+	}
+
+	return db, nil
+}
+
+// OpenReadOnly opens an existing database without migrating it or allowing
+// writes, for looking at the data while another Burnwise has it open. The
+// database must already be at SchemaVersion.
+func OpenReadOnly(dbPath string) (*gorm.DB, error) {
+	db, err := gorm.Open(sqlite.Open("file:"+dbPath+"?mode=ro&"+busyTimeoutParam), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	version, err := GetSchemaVersion(db)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema version: %w", err)
+	}
+	if version != SchemaVersion {
+		return nil, fmt.Errorf("database schema is at version %d, not %d; it can't be migrated read-only", version, SchemaVersion)
 	}
 
 	return db, nil
@@ -43,34 +74,45 @@ func runMigrations(db *gorm.DB) error {
 		&models.Transaction{},
 		&models.Category{},
 		&models.Budget{},
+		&models.BudgetCategory{},
+		&models.BudgetAmountHistory{},
 		&models.CategoryHistory{},
 		&models.RecurringTransaction{},
 		&models.RecurringTransactionOccurrence{},
+		&models.RecurringTransactionPriceHistory{},
+		&models.IncomeAllocation{},
+		&models.Account{},
+		&models.Attachment{},
+		&models.SplitItem{},
 	)
 }
 
-func seedDefaultData(db *gorm.DB) error {
-	var count int64
-	db.Model(&models.Category{}).Where("is_default = ?", true).Count(&count)
-	if count > 0 {
-		return nil
+// GetSchemaVersion reads the schema version recorded in the database
+func GetSchemaVersion(db *gorm.DB) (int, error) {
+	var version int
+	err := db.Raw("PRAGMA user_version").Scan(&version).Error
+	return version, err
+}
+
+// ReadSchemaVersion opens an existing database read-only and returns its
+// schema version without running migrations
+func ReadSchemaVersion(dbPath string) (int, error) {
+	db, err := gorm.Open(sqlite.Open("file:"+dbPath+"?mode=ro"), &gorm.Config{
+		Logger: logger.Default.LogMode(logger.Silent),
+	})
+	if err != nil {
+		return 0, fmt.Errorf("failed to open database: %w", err)
 	}
 
-	categories := models.GetDefaultCategories()
-	for _, category := range categories {
-		if err := db.Create(&category).Error; err != nil {
-			return fmt.Errorf("failed to create default category %s: %w", category.Name, err)
-		}
+	sqlDB, err := db.DB()
+	if err != nil {
+		return 0, fmt.Errorf("failed to get database connection: %w", err)
 	}
+	defer sqlDB.Close()
 
-	return nil
+	return GetSchemaVersion(db)
 }
 
 func GetDefaultDBPath() string {
-	homeDir, err := os.UserHomeDir()
-	if err != nil {
-		return "data/burnwise.db"
-	}
-
-	return filepath.Join(homeDir, ".local", "share", "burnwise", "burnwise.db")
+	return GetDBPath(GetDefaultDataDir())
 }
\ No newline at end of file
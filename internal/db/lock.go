@@ -0,0 +1,135 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+)
+
+const lockFileName = "burnwise.lock"
+
+// AlreadyRunningError is returned by AcquireLock while another Burnwise
+// holds the lock on the data directory
+type AlreadyRunningError struct {
+	PID int
+}
+
+func (e *AlreadyRunningError) Error() string {
+	return fmt.Sprintf("Burnwise is already running (pid %d)", e.PID)
+}
+
+// InstanceLock marks a data directory as in use by this process
+type InstanceLock struct {
+	path string
+}
+
+// AcquireLock creates a lock file holding this process's PID in dataDir. A
+// lock left behind by a process that is no longer running is taken over;
+// one held by a running process gives an *AlreadyRunningError. A lock file
+// whose PID can't be read is never removed, as it can't be told apart from
+// a running process's.
+//
+// The PID is written to a temporary file first and then linked into place,
+// which fails if a lock file exists, so the lock file never exists without
+// its PID.
+func AcquireLock(dataDir string) (*InstanceLock, error) {
+	path := filepath.Join(dataDir, lockFileName)
+	tempPath := fmt.Sprintf("%s.%d.tmp", path, os.Getpid())
+	file, err := os.OpenFile(tempPath, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create lock file: %w", err)
+	}
+	defer os.Remove(tempPath)
+	_, err = file.WriteString(strconv.Itoa(os.Getpid()))
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to write lock file: %w", err)
+	}
+
+	for attempt := 0; attempt < 2; attempt++ {
+		err := os.Link(tempPath, path)
+		if err == nil {
+			return &InstanceLock{path: path}, nil
+		}
+		if !errors.Is(err, os.ErrExist) {
+			return nil, fmt.Errorf("failed to create lock file: %w", err)
+		}
+
+		pid, ok := readLockPID(path)
+		if !ok {
+			return nil, fmt.Errorf("lock file %s can't be read; remove it if no Burnwise is running", path)
+		}
+		if processRunning(pid) {
+			return nil, &AlreadyRunningError{PID: pid}
+		}
+		if err := removeStaleLock(path, pid); err != nil {
+			return nil, err
+		}
+	}
+	return nil, fmt.Errorf("failed to acquire lock file %s", path)
+}
+
+// removeStaleLock removes the lock file left by pid. It is moved aside
+// first, so that if another process took the lock over in the meantime its
+// lock is put back rather than removed.
+func removeStaleLock(path string, pid int) error {
+	asidePath := fmt.Sprintf("%s.%d.stale", path, os.Getpid())
+	if err := os.Rename(path, asidePath); err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return nil
+		}
+		return fmt.Errorf("failed to remove stale lock file: %w", err)
+	}
+	defer os.Remove(asidePath)
+
+	if moved, ok := readLockPID(asidePath); !ok || moved != pid {
+		// Not the stale lock; if another lock has replaced it since, the
+		// next attempt reports that one
+		if err := os.Link(asidePath, path); err != nil && !errors.Is(err, os.ErrExist) {
+			return fmt.Errorf("failed to restore lock file: %w", err)
+		}
+		if !ok {
+			return fmt.Errorf("lock file %s can't be read; remove it if no Burnwise is running", path)
+		}
+		return &AlreadyRunningError{PID: moved}
+	}
+	return nil
+}
+
+// Release removes the lock file, unless another process has taken it over
+func (l *InstanceLock) Release() error {
+	if pid, ok := readLockPID(l.path); !ok || pid != os.Getpid() {
+		return nil
+	}
+	return os.Remove(l.path)
+}
+
+func readLockPID(path string) (int, bool) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return 0, false
+	}
+	pid, err := strconv.Atoi(strings.TrimSpace(string(data)))
+	return pid, err == nil && pid > 0
+}
+
+// processRunning reports whether a process with pid exists. Signal 0 checks
+// for it without sending anything; where that isn't supported the process
+// is assumed to run.
+func processRunning(pid int) bool {
+	if pid == os.Getpid() {
+		return true
+	}
+	process, err := os.FindProcess(pid)
+	if err != nil {
+		return false
+	}
+	err = process.Signal(syscall.Signal(0))
+	return err == nil || !errors.Is(err, os.ErrProcessDone) && !errors.Is(err, syscall.ESRCH)
+}
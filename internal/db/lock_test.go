@@ -0,0 +1,101 @@
+package db
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strconv"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// exitedPID returns the PID of a process that has already exited
+func exitedPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command(os.Args[0], "-test.run=^$")
+	require.NoError(t, cmd.Run())
+	return cmd.Process.Pid
+}
+
+func TestAcquireLock(t *testing.T) {
+	dataDir := t.TempDir()
+	path := filepath.Join(dataDir, lockFileName)
+
+	lock, err := AcquireLock(dataDir)
+	require.NoError(t, err)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(os.Getpid()), string(data))
+
+	// Held by a running process, here this one
+	_, err = AcquireLock(dataDir)
+	var running *AlreadyRunningError
+	require.ErrorAs(t, err, &running)
+	assert.Equal(t, os.Getpid(), running.PID)
+
+	require.NoError(t, lock.Release())
+	assert.NoFileExists(t, path)
+
+	// No temporary files are left behind
+	entries, err := os.ReadDir(dataDir)
+	require.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestAcquireLock_TakesOverStaleLock(t *testing.T) {
+	dataDir := t.TempDir()
+	path := filepath.Join(dataDir, lockFileName)
+	require.NoError(t, os.WriteFile(path, []byte(strconv.Itoa(exitedPID(t))), 0644))
+
+	lock, err := AcquireLock(dataDir)
+	require.NoError(t, err)
+	defer lock.Release()
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(os.Getpid()), string(data))
+}
+
+func TestAcquireLock_KeepsUnreadableLock(t *testing.T) {
+	dataDir := t.TempDir()
+	path := filepath.Join(dataDir, lockFileName)
+
+	// An empty lock file could be another process's, so it stays
+	require.NoError(t, os.WriteFile(path, nil, 0644))
+	_, err := AcquireLock(dataDir)
+	assert.ErrorContains(t, err, "can't be read")
+	assert.FileExists(t, path)
+}
+
+func TestInstanceLock_ReleaseKeepsTakenOverLock(t *testing.T) {
+	dataDir := t.TempDir()
+	path := filepath.Join(dataDir, lockFileName)
+
+	lock, err := AcquireLock(dataDir)
+	require.NoError(t, err)
+
+	// Another process took the lock over, e.g. after this one was
+	// suspended long enough to look stale
+	require.NoError(t, os.WriteFile(path, []byte("12345"), 0644))
+	require.NoError(t, lock.Release())
+	assert.FileExists(t, path)
+}
+
+func TestRemoveStaleLock_PutsBackReplacedLock(t *testing.T) {
+	dataDir := t.TempDir()
+	path := filepath.Join(dataDir, lockFileName)
+	stale := exitedPID(t)
+
+	// The stale lock was replaced by a live one before it could be removed
+	require.NoError(t, os.WriteFile(path, []byte(strconv.Itoa(os.Getpid())), 0644))
+	err := removeStaleLock(path, stale)
+	var running *AlreadyRunningError
+	require.ErrorAs(t, err, &running)
+	assert.Equal(t, os.Getpid(), running.PID)
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Equal(t, strconv.Itoa(os.Getpid()), string(data))
+}
@@ -0,0 +1,99 @@
+package db
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+const (
+	dataDirName      = "burnwise"
+	dbFileName       = "burnwise.db"
+	settingsFileName = "settings.json"
+)
+
+// legacySettingsPath is where earlier versions kept the settings, relative
+// to the working directory
+var legacySettingsPath = filepath.Join("data", settingsFileName)
+
+// GetDefaultDataDir returns the directory holding the database and settings.
+// It honours XDG_DATA_HOME and falls back to ~/.local/share/burnwise.
+func GetDefaultDataDir() string {
+	if xdgDataHome := os.Getenv("XDG_DATA_HOME"); xdgDataHome != "" {
+		return filepath.Join(xdgDataHome, dataDirName)
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "data"
+	}
+
+	return filepath.Join(homeDir, ".local", "share", dataDirName)
+}
+
+// GetDBPath returns the SQLite database path inside a data directory
+func GetDBPath(dataDir string) string {
+	return filepath.Join(dataDir, dbFileName)
+}
+
+// GetSettingsPath returns the settings file path inside a data directory
+func GetSettingsPath(dataDir string) string {
+	return filepath.Join(dataDir, settingsFileName)
+}
+
+// EnsureDataDir creates the data directory if needed and verifies that it
+// is writable
+func EnsureDataDir(dataDir string) error {
+	if err := os.MkdirAll(dataDir, 0755); err != nil {
+		if errors.Is(err, fs.ErrPermission) {
+			return fmt.Errorf("cannot create data directory %s: permission denied (use -data-dir to choose another location)", dataDir)
+		}
+		return fmt.Errorf("failed to create data directory %s: %w", dataDir, err)
+	}
+
+	probe, err := os.CreateTemp(dataDir, ".write-check-*")
+	if err != nil {
+		if errors.Is(err, fs.ErrPermission) {
+			return fmt.Errorf("data directory %s is not writable: permission denied (use -data-dir to choose another location)", dataDir)
+		}
+		return fmt.Errorf("data directory %s is not writable: %w", dataDir, err)
+	}
+	probe.Close()
+	os.Remove(probe.Name())
+
+	return nil
+}
+
+// MigrateLegacySettings moves the settings an earlier version left in
+// ./data/settings.json into dataDir, unless it already has settings. It
+// returns the path they were moved from, or "" when there was nothing to
+// move.
+func MigrateLegacySettings(dataDir string) (string, error) {
+	settingsPath := GetSettingsPath(dataDir)
+	if _, err := os.Stat(settingsPath); !os.IsNotExist(err) {
+		return "", nil
+	}
+
+	data, err := os.ReadFile(legacySettingsPath)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read old settings %s: %w", legacySettingsPath, err)
+	}
+
+	tempPath := settingsPath + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return "", fmt.Errorf("failed to move old settings: %w", err)
+	}
+	if err := os.Rename(tempPath, settingsPath); err != nil {
+		os.Remove(tempPath)
+		return "", fmt.Errorf("failed to move old settings: %w", err)
+	}
+	// The copy is in place, so an old file that can't be removed is only
+	// left behind
+	os.Remove(legacySettingsPath)
+	return legacySettingsPath, nil
+}
@@ -0,0 +1,41 @@
+package db
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrateLegacySettings(t *testing.T) {
+	t.Chdir(t.TempDir())
+	dataDir := t.TempDir()
+
+	// Nothing to move
+	moved, err := MigrateLegacySettings(dataDir)
+	require.NoError(t, err)
+	assert.Empty(t, moved)
+
+	require.NoError(t, os.MkdirAll("data", 0755))
+	require.NoError(t, os.WriteFile(legacySettingsPath, []byte(`{"version": "old"}`), 0644))
+	moved, err = MigrateLegacySettings(dataDir)
+	require.NoError(t, err)
+	assert.Equal(t, legacySettingsPath, moved)
+	data, err := os.ReadFile(GetSettingsPath(dataDir))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version": "old"}`, string(data))
+	assert.NoFileExists(t, legacySettingsPath)
+
+	// Settings already in the data directory are kept
+	require.NoError(t, os.WriteFile(legacySettingsPath, []byte(`{"version": "older"}`), 0644))
+	moved, err = MigrateLegacySettings(dataDir)
+	require.NoError(t, err)
+	assert.Empty(t, moved)
+	data, err = os.ReadFile(GetSettingsPath(dataDir))
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"version": "old"}`, string(data))
+	assert.FileExists(t, legacySettingsPath)
+	assert.NoFileExists(t, filepath.Join(dataDir, settingsFileName+".tmp"))
+}
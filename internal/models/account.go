@@ -0,0 +1,92 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+)
+
+type AccountType string
+
+const (
+	AccountTypeCash    AccountType = "cash"
+	AccountTypeDebit   AccountType = "debit"
+	AccountTypeCredit  AccountType = "credit"
+	AccountTypeSavings AccountType = "savings"
+)
+
+// AccountTypes lists the account types in the order pickers offer them
+var AccountTypes = []AccountType{AccountTypeCash, AccountTypeDebit, AccountTypeCredit, AccountTypeSavings}
+
+// NoAccountName is shown for transactions not assigned to an account
+const NoAccountName = "No account"
+
+// Account is where a transaction's money came from or went to, such as a
+// wallet or a credit card. Accounts are optional: transactions without one
+// are counted under NoAccountName.
+type Account struct {
+	ID   uint        `gorm:"primaryKey" json:"id"`
+	Name string      `gorm:"type:varchar(100);not null;uniqueIndex" json:"name"`
+	Type AccountType `gorm:"type:varchar(20);not null" json:"type"`
+	// OpeningBalance is what the account held, in USD, before the first
+	// transaction recorded against it. Credit cards owing money are negative.
+	OpeningBalance float64   `gorm:"not null;default:0" json:"opening_balance"`
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+func (a *Account) Validate() error {
+	if strings.TrimSpace(a.Name) == "" {
+		return errors.New("account name is required")
+	}
+
+	if !a.Type.IsValid() {
+		return fmt.Errorf("invalid account type %q", a.Type)
+	}
+
+	return nil
+}
+
+// IsValid reports whether t is one of AccountTypes
+func (t AccountType) IsValid() bool {
+	for _, known := range AccountTypes {
+		if t == known {
+			return true
+		}
+	}
+	return false
+}
+
+// AccountSummary totals an account's transactions in a period, in USD.
+// Account is nil for the transactions without one. Transfers are kept
+// apart from income and expenses, since they only move money between
+// accounts.
+type AccountSummary struct {
+	Account        *Account `json:"account,omitempty"`
+	Income         float64  `json:"income"`
+	Expenses       float64  `json:"expenses"`
+	TransferredIn  float64  `json:"transferred_in"`
+	TransferredOut float64  `json:"transferred_out"`
+	Count          int      `json:"count"`
+	Percentage     float64  `json:"percentage"` // share of the period's expenses
+}
+
+// Name is the account's name, or NoAccountName
+func (s *AccountSummary) Name() string {
+	if s.Account == nil {
+		return NoAccountName
+	}
+	return s.Account.Name
+}
+
+// Balance is the opening balance plus the income and transfers in, less
+// the expenses and transfers out. It is only the running balance when the
+// period starts at the beginning.
+func (s *AccountSummary) Balance() float64 {
+	var opening float64
+	if s.Account != nil {
+		opening = s.Account.OpeningBalance
+	}
+	return RoundUSD(opening + s.Income - s.Expenses + s.TransferredIn - s.TransferredOut)
+}
@@ -0,0 +1,59 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// IncomeAllocation earmarks part of an income transaction, such as a
+// paycheck, for a purpose like taxes, savings or spending. Amount is in the
+// transaction's currency.
+type IncomeAllocation struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	TransactionID uint      `gorm:"not null;index" json:"transaction_id"`
+	Name          string    `gorm:"type:varchar(100);not null" json:"name"`
+	Amount        float64   `gorm:"not null" json:"amount"`
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
+}
+
+func (a *IncomeAllocation) Validate() error {
+	if strings.TrimSpace(a.Name) == "" {
+		return errors.New("allocation name is required")
+	}
+
+	if a.Amount <= 0 {
+		return errors.New("allocation amount must be positive")
+	}
+
+	return nil
+}
+
+// AllocationTotal is how much of the income in a period went to one
+// allocation, in USD
+type AllocationTotal struct {
+	Name      string  `json:"name"`
+	AmountUSD float64 `json:"amount_usd"`
+}
+
+// AllocationSummary breaks a period's income down by where it was
+// allocated, e.g. "of $5,000 income, $1,000 went to Savings"
+type AllocationSummary struct {
+	TotalIncome float64            `json:"total_income"`
+	Allocations []*AllocationTotal `json:"allocations"`
+}
+
+// Allocated is the part of the income allocated to anything
+func (s *AllocationSummary) Allocated() float64 {
+	var total float64
+	for _, allocation := range s.Allocations {
+		total += allocation.AmountUSD
+	}
+	return RoundUSD(total)
+}
+
+// Unallocated is the part of the income not allocated to anything
+func (s *AllocationSummary) Unallocated() float64 {
+	return RoundUSD(s.TotalIncome - s.Allocated())
+}
@@ -0,0 +1,25 @@
+package models
+
+import (
+	"errors"
+	"strings"
+	"time"
+)
+
+// Attachment links a transaction to a file kept elsewhere, such as a
+// scanned receipt. Only the path is stored, so the file can later be moved
+// or deleted without affecting the transaction.
+type Attachment struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	TransactionID uint      `gorm:"not null;index" json:"transaction_id"`
+	Path          string    `gorm:"type:varchar(1024);not null" json:"path"`
+	AddedAt       time.Time `gorm:"not null" json:"added_at"`
+}
+
+func (a *Attachment) Validate() error {
+	if strings.TrimSpace(a.Path) == "" {
+		return errors.New("attachment path is required")
+	}
+
+	return nil
+}
@@ -2,6 +2,9 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -15,18 +18,66 @@ const (
 )
 
 type Budget struct {
-	ID         uint           `gorm:"primaryKey" json:"id"`
-	Name       string         `gorm:"type:varchar(100);not null" json:"name"`
-	CategoryID uint           `gorm:"not null" json:"category_id"`
-	Amount     float64        `gorm:"not null" json:"amount"`
-	Period     BudgetPeriod   `gorm:"type:varchar(20);not null" json:"period"`
-	StartDate  time.Time      `gorm:"not null" json:"start_date"`
-	EndDate    *time.Time     `json:"end_date,omitempty"`
-	CreatedAt  time.Time      `json:"created_at"`
-	UpdatedAt  time.Time      `json:"updated_at"`
-	DeletedAt  gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
+	ID              uint           `gorm:"primaryKey" json:"id"`
+	Name            string         `gorm:"type:varchar(100);not null" json:"name"`
+	CategoryID      uint           `gorm:"not null" json:"category_id"`
+	Amount          float64        `gorm:"not null" json:"amount"`
+	// PercentOfIncome makes the budget a share of the month's income
+	// instead of the fixed Amount, e.g. 30 for 30%
+	PercentOfIncome *float64       `json:"percent_of_income,omitempty"`
+	// ProrateFirstPeriod allows only the share of the amount for the part
+	// of the first period from StartDate on, so a budget created mid-month
+	// isn't held against the whole month's spending
+	ProrateFirstPeriod bool        `gorm:"not null;default:false" json:"prorate_first_period"`
+	Period          BudgetPeriod   `gorm:"type:varchar(20);not null" json:"period"`
+	StartDate       time.Time      `gorm:"not null" json:"start_date"`
+	EndDate         *time.Time     `json:"end_date,omitempty"`
+	Notes           string         `gorm:"type:text" json:"notes,omitempty"`
+	CreatedAt       time.Time      `json:"created_at"`
+	UpdatedAt       time.Time      `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt `gorm:"index" json:"deleted_at,omitempty"`
 
-	Category Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Category   Category   `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+	Categories []Category `gorm:"many2many:budget_categories" json:"categories,omitempty"`
+}
+
+// BudgetCategory links a group budget to each of its categories. Budgets
+// without links cover only their CategoryID.
+type BudgetCategory struct {
+	BudgetID   uint `gorm:"primaryKey" json:"budget_id"`
+	CategoryID uint `gorm:"primaryKey" json:"category_id"`
+}
+
+// IsGroup reports whether the budget spans more than one category
+func (b *Budget) IsGroup() bool {
+	return len(b.Categories) > 1
+}
+
+// CategoryIDs returns the IDs of all categories the budget covers
+func (b *Budget) CategoryIDs() []uint {
+	if len(b.Categories) == 0 {
+		return []uint{b.CategoryID}
+	}
+
+	ids := make([]uint, len(b.Categories))
+	for i, category := range b.Categories {
+		ids[i] = category.ID
+	}
+	return ids
+}
+
+// CategoryLabel returns the category shown for the budget, joining the
+// names of a group budget's categories
+func (b *Budget) CategoryLabel() string {
+	if !b.IsGroup() {
+		return strings.TrimSpace(b.Category.Icon + " " + b.Category.Name)
+	}
+
+	names := make([]string, len(b.Categories))
+	for i, category := range b.Categories {
+		names[i] = category.Name
+	}
+	return strings.Join(names, " + ")
 }
 
 func (b *Budget) Validate() error {
@@ -38,7 +89,14 @@ func (b *Budget) Validate() error {
 		return errors.New("category is required")
 	}
 
-	if b.Amount <= 0 {
+	if b.PercentOfIncome != nil {
+		if *b.PercentOfIncome <= 0 || *b.PercentOfIncome > 100 {
+			return errors.New("percent of income must be between 0 and 100")
+		}
+		if b.Period != BudgetPeriodMonthly {
+			return errors.New("percent-of-income budgets must be monthly")
+		}
+	} else if b.Amount <= 0 {
 		return errors.New("budget amount must be positive")
 	}
 
@@ -57,6 +115,46 @@ func (b *Budget) Validate() error {
 	return nil
 }
 
+// IsPercentOfIncome reports whether the budget's amount follows the
+// month's income rather than being fixed
+func (b *Budget) IsPercentOfIncome() bool {
+	return b.PercentOfIncome != nil
+}
+
+// AmountFor returns what the budget allows in a month with the given
+// income, in USD: the share of it for a percent-of-income budget, or else
+// the fixed amount
+func (b *Budget) AmountFor(income float64) float64 {
+	if b.PercentOfIncome == nil {
+		return b.Amount
+	}
+	return RoundUSD(income * *b.PercentOfIncome / 100)
+}
+
+// ProratedAmount returns what the budget allows out of amount in the period
+// starting at periodStart. For a prorated budget's first period that is
+// the share of the period's days from StartDate on, e.g. half of it for a
+// monthly budget started on the 16th of a 30-day month; for any other
+// period it is all of amount.
+func (b *Budget) ProratedAmount(amount float64, periodStart time.Time) float64 {
+	if !b.IsProratedIn(periodStart) {
+		return amount
+	}
+
+	periodEnd := b.AddPeriods(periodStart, 1)
+	year, month, day := b.StartDate.Date()
+	startDay := time.Date(year, month, day, 0, 0, 0, 0, periodStart.Location())
+	days := periodEnd.Sub(periodStart).Hours() / 24
+	left := periodEnd.Sub(startDay).Hours() / 24
+	return RoundUSD(amount * math.Round(left) / math.Round(days))
+}
+
+// IsProratedIn reports whether the period starting at periodStart is a
+// prorated budget's first
+func (b *Budget) IsProratedIn(periodStart time.Time) bool {
+	return b.ProrateFirstPeriod && !b.StartDate.Before(periodStart) && b.StartDate.Before(b.AddPeriods(periodStart, 1))
+}
+
 func (b *Budget) BeforeCreate(tx *gorm.DB) error {
 	return b.Validate()
 }
@@ -67,20 +165,31 @@ func (b *Budget) IsActive() bool {
 }
 
 func (b *Budget) GetCurrentPeriodStart() time.Time {
-	now := time.Now()
-	
+	return b.PeriodStartAt(time.Now())
+}
+
+// PeriodStartAt returns the start of the budget period containing date
+func (b *Budget) PeriodStartAt(date time.Time) time.Time {
 	switch b.Period {
 	case BudgetPeriodMonthly:
-		year, month, _ := now.Date()
-		return time.Date(year, month, 1, 0, 0, 0, 0, now.Location())
+		year, month, _ := date.Date()
+		return time.Date(year, month, 1, 0, 0, 0, 0, date.Location())
 	case BudgetPeriodYearly:
-		year := now.Year()
-		return time.Date(year, 1, 1, 0, 0, 0, 0, now.Location())
+		year := date.Year()
+		return time.Date(year, 1, 1, 0, 0, 0, 0, date.Location())
 	default:
 		return b.StartDate
 	}
 }
 
+// AddPeriods moves start, the start of a budget period, by n periods
+func (b *Budget) AddPeriods(start time.Time, n int) time.Time {
+	if b.Period == BudgetPeriodYearly {
+		return start.AddDate(n, 0, 0)
+	}
+	return start.AddDate(0, n, 0)
+}
+
 func (b *Budget) GetCurrentPeriodEnd() time.Time {
 	start := b.GetCurrentPeriodStart()
 	
@@ -97,6 +206,83 @@ func (b *Budget) GetCurrentPeriodEnd() time.Time {
 	}
 }
 
+// BudgetProposal is a suggested monthly budget for a category, based on
+// what was spent in it last month
+type BudgetProposal struct {
+	Category  Category `json:"category"`
+	LastMonth float64  `json:"last_month"`
+	Amount    float64  `json:"amount"`
+}
+
+// BudgetAmountHistory records a change to a budget's amount, so that its
+// past periods are measured against the amount they had rather than the
+// current one
+type BudgetAmountHistory struct {
+	ID            uint      `gorm:"primaryKey" json:"id"`
+	BudgetID      uint      `gorm:"not null;index" json:"budget_id"`
+	OldAmount     float64   `gorm:"not null" json:"old_amount"`
+	NewAmount     float64   `gorm:"not null" json:"new_amount"`
+	EffectiveFrom time.Time `gorm:"not null" json:"effective_from"` // start of the first period with the new amount
+	ChangedAt     time.Time `gorm:"not null" json:"changed_at"`
+}
+
+// AmountAt returns the budget's amount in the period starting at
+// periodStart, given its amount changes oldest first
+func (b *Budget) AmountAt(changes []*BudgetAmountHistory, periodStart time.Time) float64 {
+	if len(changes) == 0 {
+		return b.Amount
+	}
+	amount := changes[0].OldAmount
+	for _, change := range changes {
+		if !change.EffectiveFrom.After(periodStart) {
+			amount = change.NewAmount
+		}
+	}
+	return amount
+}
+
+// BudgetSuggestion proposes a new amount for a budget that was over, or
+// well under, its amount in each of its last few completed periods
+type BudgetSuggestion struct {
+	Budget Budget    `json:"budget"`
+	Spent  []float64 `json:"spent"` // per period, oldest first
+	Over   bool      `json:"over"`
+	Amount float64   `json:"amount"`
+}
+
+// Message explains the suggestion, e.g. "Food over budget 3 months in a
+// row — consider raising to $650"
+func (s *BudgetSuggestion) Message() string {
+	unit := "months"
+	if s.Budget.Period == BudgetPeriodYearly {
+		unit = "years"
+	}
+	name := s.Budget.CategoryLabel()
+	if !s.Budget.IsGroup() && s.Budget.Category.Name != "" {
+		name = s.Budget.Category.Name
+	}
+
+	if s.Over {
+		return fmt.Sprintf("%s over budget %d %s in a row — consider raising to $%.0f", name, len(s.Spent), unit, s.Amount)
+	}
+	return fmt.Sprintf("%s well under budget %d %s in a row — consider lowering to $%.0f", name, len(s.Spent), unit, s.Amount)
+}
+
+// BudgetPeriodSpending is a budget's amount against what was spent in one
+// of its periods
+type BudgetPeriodSpending struct {
+	Start      time.Time `json:"start"`
+	End        time.Time `json:"end"`
+	Budgeted   float64   `json:"budgeted"`
+	Spent      float64   `json:"spent"`
+	InProgress bool      `json:"in_progress"` // the current period, still running
+}
+
+// IsOver reports whether more than the budget was spent in the period
+func (p *BudgetPeriodSpending) IsOver() bool {
+	return p.Spent > p.Budgeted
+}
+
 type BudgetStatus struct {
 	Budget       Budget  `json:"budget"`
 	Spent        float64 `json:"spent"`
@@ -105,11 +291,193 @@ type BudgetStatus struct {
 	IsOverBudget bool    `json:"is_over_budget"`
 	DaysLeft     int     `json:"days_left"`
 	DailyBudget  float64 `json:"daily_budget"`
+	// Committed is what the active recurring expenses in the budget's
+	// categories cost per period, in USD
+	Committed    float64 `json:"committed"`
+	// savedAmount is the budget's amount as saved, once Budget.Amount
+	// has been set to what it allows in the current period instead
+	savedAmount  *float64
+}
+
+// SetPeriodAmount sets what the budget allows in the current period, e.g.
+// its share of the month's income, keeping the amount it is saved with
+func (bs *BudgetStatus) SetPeriodAmount(amount float64) {
+	if bs.savedAmount == nil {
+		saved := bs.Budget.Amount
+		bs.savedAmount = &saved
+	}
+	bs.Budget.Amount = amount
+}
+
+// SavedBudget returns a copy of the budget with the amount it is saved
+// with, rather than what it allows in the current period, for editing
+func (bs *BudgetStatus) SavedBudget() *Budget {
+	budget := bs.Budget
+	if bs.savedAmount != nil {
+		budget.Amount = *bs.savedAmount
+	}
+	return &budget
+}
+
+// IsOverCommitted reports whether the recurring expenses alone cost more
+// per period than the budget allows
+func (bs *BudgetStatus) IsOverCommitted() bool {
+	return bs.Committed > bs.Budget.Amount
+}
+
+// AlertLevel is how far a budget is past the alert thresholds
+type AlertLevel int
+
+const (
+	// AlertNone is less than the warning share spent
+	AlertNone AlertLevel = iota
+	// AlertWarning is at least the warning share spent
+	AlertWarning
+	// AlertCritical is more than the critical share spent
+	AlertCritical
+)
+
+// AlertLevel returns AlertNone while less than warn percent of the budget
+// is spent, AlertWarning from there on and AlertCritical once more than
+// crit percent is
+func (bs *BudgetStatus) AlertLevel(warn, crit float64) AlertLevel {
+	switch {
+	case bs.PercentUsed > crit:
+		return AlertCritical
+	case bs.PercentUsed >= warn:
+		return AlertWarning
+	default:
+		return AlertNone
+	}
+}
+
+// SpendingLimitStatus is the month-to-date expenses against the overall
+// monthly spending limit
+type SpendingLimitStatus struct {
+	Limit       float64 `json:"limit"`
+	Spent       float64 `json:"spent"`
+	Remaining   float64 `json:"remaining"`
+	PercentUsed float64 `json:"percent_used"`
+	IsNearLimit bool    `json:"is_near_limit"`
+	IsOverLimit bool    `json:"is_over_limit"`
+}
+
+// SpendingVelocity compares what has been spent so far this month with
+// where spending at an even pace toward the month's target would be by
+// today. Pace is how far ahead (positive) or behind (negative) of that it
+// is, in percent.
+type SpendingVelocity struct {
+	Target      float64 `json:"target"`
+	Spent       float64 `json:"spent"`
+	Expected    float64 `json:"expected"`
+	Day         int     `json:"day"`
+	DaysInMonth int     `json:"days_in_month"`
+	Pace        float64 `json:"pace"`
+}
+
+// OnPaceTolerance is how many percent off the even pace spending can be
+// and still count as on pace
+const OnPaceTolerance = 5
+
+// NewSpendingVelocity compares spent with the share of target for the days
+// of the month up to and including now's
+func NewSpendingVelocity(spent, target float64, now time.Time) *SpendingVelocity {
+	daysInMonth := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, now.Location()).Day()
+	return NewSpendingVelocityExpecting(spent, target, target*float64(now.Day())/float64(daysInMonth), now)
+}
+
+// NewSpendingVelocityExpecting compares spent with expected, what an even
+// pace toward target allows by now, for a target that doesn't all run from
+// the start of the month
+func NewSpendingVelocityExpecting(spent, target, expected float64, now time.Time) *SpendingVelocity {
+	daysInMonth := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, now.Location()).Day()
+	v := &SpendingVelocity{
+		Target:      target,
+		Spent:       spent,
+		Expected:    expected,
+		Day:         now.Day(),
+		DaysInMonth: daysInMonth,
+	}
+	if v.Expected > 0 {
+		v.Pace = (v.Spent/v.Expected - 1) * 100
+	}
+	return v
+}
+
+// IsAhead reports whether more is being spent than an even pace allows
+func (v *SpendingVelocity) IsAhead() bool {
+	return v.Pace > OnPaceTolerance
+}
+
+// IsBehind reports whether less is being spent than an even pace allows
+func (v *SpendingVelocity) IsBehind() bool {
+	return v.Pace < -OnPaceTolerance
+}
+
+// Status reads e.g. "You're 15% ahead of pace"
+func (v *SpendingVelocity) Status() string {
+	switch {
+	case v.IsAhead():
+		return fmt.Sprintf("You're %.0f%% ahead of pace", v.Pace)
+	case v.IsBehind():
+		return fmt.Sprintf("You're %.0f%% behind pace", -v.Pace)
+	default:
+		return "You're on pace"
+	}
+}
+
+// DailyAllowance is how much can be spent on each day left in a period,
+// today included, to stay within a budget or the overall monthly limit.
+// PerDay is negative once more than the amount has been spent.
+type DailyAllowance struct {
+	BudgetID uint    `json:"budget_id,omitempty"` // zero for the monthly limit
+	Name     string  `json:"name"`
+	Amount   float64 `json:"amount"`
+	Spent    float64 `json:"spent"`
+	DaysLeft int     `json:"days_left"`
+	PerDay   float64 `json:"per_day"`
+}
+
+// IsOver reports whether more than the amount has been spent already
+func (a *DailyAllowance) IsOver() bool {
+	return a.PerDay < 0
+}
+
+// BudgetWarnPercent is the share of a budget, or of the monthly limit, that
+// can be spent before it is shown as nearly used up
+const BudgetWarnPercent = 80
+
+func (ls *SpendingLimitStatus) Calculate() {
+	ls.Remaining = ls.Limit - ls.Spent
+	ls.PercentUsed = (ls.Spent / ls.Limit) * 100
+	ls.IsOverLimit = ls.Spent > ls.Limit
+	ls.IsNearLimit = !ls.IsOverLimit && ls.PercentUsed > BudgetWarnPercent
+}
+
+// Level is 0 within the limit, 1 near it and 2 over it, so that crossing a
+// threshold shows as a higher level
+func (ls *SpendingLimitStatus) Level() int {
+	switch {
+	case ls.IsOverLimit:
+		return 2
+	case ls.IsNearLimit:
+		return 1
+	default:
+		return 0
+	}
 }
 
 func (bs *BudgetStatus) Calculate() {
 	bs.Remaining = bs.Budget.Amount - bs.Spent
-	bs.PercentUsed = (bs.Spent / bs.Budget.Amount) * 100
+	switch {
+	case bs.Budget.Amount > 0:
+		bs.PercentUsed = (bs.Spent / bs.Budget.Amount) * 100
+	case bs.Spent > 0:
+		// A percent-of-income budget in a month without income yet
+		bs.PercentUsed = 100
+	default:
+		bs.PercentUsed = 0
+	}
 	bs.IsOverBudget = bs.Spent > bs.Budget.Amount
 	
 	end := bs.Budget.GetCurrentPeriodEnd()
@@ -123,6 +491,16 @@ func (bs *BudgetStatus) Calculate() {
 	}
 }
 
+// BudgetProjection is a budget's status together with what the current
+// period is expected to end at once the recurring expenses still due in it
+// are charged
+type BudgetProjection struct {
+	BudgetStatus
+	PendingRecurring float64 `json:"pending_recurring"`
+	Projected        float64 `json:"projected"`
+	IsProjectedOver  bool    `json:"is_projected_over"`
+}
+
 type BudgetFilter struct {
 	CategoryID uint
 	Period     BudgetPeriod
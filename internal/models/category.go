@@ -2,22 +2,34 @@ package models
 
 import (
 	"errors"
+	"fmt"
 	"time"
+	"unicode/utf8"
 
 	"gorm.io/gorm"
 )
 
+// MaxIconRunes is the longest icon allowed, in runes rather than bytes, so
+// emoji built from several code points, such as flags and family emoji, fit
+const MaxIconRunes = 8
+
 type Category struct {
-	ID        uint            `gorm:"primaryKey" json:"id"`
-	Name      string          `gorm:"type:varchar(100);not null;uniqueIndex:idx_category_name_type" json:"name"`
-	Type      TransactionType `gorm:"type:varchar(20);not null;uniqueIndex:idx_category_name_type" json:"type"`
-	Icon      string          `gorm:"type:varchar(10)" json:"icon"`
-	Color     string          `gorm:"type:varchar(7)" json:"color"`
-	ParentID  *uint           `json:"parent_id,omitempty"`
-	IsDefault bool            `gorm:"default:false" json:"is_default"`
-	CreatedAt time.Time       `json:"created_at"`
-	UpdatedAt time.Time       `json:"updated_at"`
-	DeletedAt gorm.DeletedAt  `gorm:"index" json:"deleted_at,omitempty"`
+	ID              uint            `gorm:"primaryKey" json:"id"`
+	Name            string          `gorm:"type:varchar(100);not null;uniqueIndex:idx_category_name_type" json:"name"`
+	Type            TransactionType `gorm:"type:varchar(20);not null;uniqueIndex:idx_category_name_type" json:"type"`
+	Icon            string          `gorm:"type:varchar(10)" json:"icon"`
+	Color           string          `gorm:"type:varchar(7)" json:"color"`
+	ParentID        *uint           `json:"parent_id,omitempty"`
+	IsDefault       bool            `gorm:"default:false" json:"is_default"`
+	SortOrder       int             `gorm:"default:0" json:"sort_order"` // position among its type once reordered; 0 sorts by name
+	IsArchived      bool            `gorm:"default:false" json:"is_archived"` // hidden from pickers, still shown in reports and history
+	IsPinned        bool            `gorm:"default:false" json:"is_pinned"`   // listed first in pickers
+	// DefaultCurrency is picked in the transaction and recurring forms when
+	// the category is selected; empty uses the settings' default
+	DefaultCurrency string          `gorm:"type:varchar(3)" json:"default_currency,omitempty"`
+	CreatedAt       time.Time       `json:"created_at"`
+	UpdatedAt       time.Time       `json:"updated_at"`
+	DeletedAt       gorm.DeletedAt  `gorm:"index" json:"deleted_at,omitempty"`
 
 	Parent       *Category     `gorm:"foreignKey:ParentID" json:"parent,omitempty"`
 	Transactions []Transaction `gorm:"foreignKey:CategoryID" json:"transactions,omitempty"`
@@ -32,10 +44,18 @@ func (c *Category) Validate() error {
 		return errors.New("invalid category type")
 	}
 
+	if utf8.RuneCountInString(c.Icon) > MaxIconRunes {
+		return fmt.Errorf("icon must be at most %d characters", MaxIconRunes)
+	}
+
 	if c.Color != "" && len(c.Color) != 7 {
 		return errors.New("color must be a hex code (e.g., #FF5733)")
 	}
 
+	if c.DefaultCurrency != "" && len(c.DefaultCurrency) != 3 {
+		return errors.New("default currency must be a 3-letter code (e.g., AED)")
+	}
+
 	return nil
 }
 
@@ -76,9 +96,30 @@ func GetDefaultCategories() []Category {
 	return categories
 }
 
+// OtherCategoryName is the name of the default expense category for
+// transactions that fit nowhere else
+const OtherCategoryName = "Other"
+
+// CategoryTrend compares a category's total this month with last month
+type CategoryTrend struct {
+	CurrentMonth  float64 `json:"current_month"`
+	PreviousMonth float64 `json:"previous_month"`
+}
+
+// Change returns the percentage change from last month. It is zero when
+// there was nothing last month to compare against.
+func (t *CategoryTrend) Change() float64 {
+	if t.PreviousMonth == 0 {
+		return 0
+	}
+	return (t.CurrentMonth - t.PreviousMonth) / t.PreviousMonth * 100
+}
+
 type CategoryWithTotal struct {
 	Category
 	Total     float64 `json:"total"`
 	Count     int     `json:"count"`
 	Percentage float64 `json:"percentage"`
+	Average   float64 `json:"average"`
+	Median    float64 `json:"median"`
 }
\ No newline at end of file
@@ -0,0 +1,107 @@
+package models
+
+import "math"
+
+// currencyDecimals lists the currencies whose minor unit is not two
+// decimal places (ISO 4217). Any other currency uses two.
+var currencyDecimals = map[string]int{
+	"JPY": 0,
+	"KRW": 0,
+	"VND": 0,
+	"CLP": 0,
+	"ISK": 0,
+	"BHD": 3,
+	"KWD": 3,
+	"OMR": 3,
+	"JOD": 3,
+	"TND": 3,
+}
+
+// CurrencyDecimals returns how many decimal places amounts in currency have
+func CurrencyDecimals(currency string) int {
+	if decimals, ok := currencyDecimals[currency]; ok {
+		return decimals
+	}
+	return 2
+}
+
+// RoundUSD rounds a USD amount to cents. Stored USD amounts and the totals
+// built from them are rounded so float noise doesn't show up in summaries.
+func RoundUSD(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+// RoundingMode is how amounts converted to USD are rounded to cents
+type RoundingMode string
+
+const (
+	RoundHalfUp   RoundingMode = "half_up"   // to the nearest cent, halves away from zero
+	RoundHalfEven RoundingMode = "half_even" // to the nearest cent, halves to the even cent
+	RoundDown     RoundingMode = "down"      // dropping fractions of a cent
+)
+
+// RoundingModes lists the rounding modes the settings accept
+var RoundingModes = []RoundingMode{RoundHalfUp, RoundHalfEven, RoundDown}
+
+// IsKnownRoundingMode reports whether mode is one of RoundingModes. Empty
+// counts as known, meaning RoundHalfUp.
+func IsKnownRoundingMode(mode RoundingMode) bool {
+	if mode == "" {
+		return true
+	}
+	for _, m := range RoundingModes {
+		if m == mode {
+			return true
+		}
+	}
+	return false
+}
+
+// Round rounds a USD amount to cents. An empty or unknown mode rounds half
+// up, like RoundUSD.
+func (m RoundingMode) Round(amount float64) float64 {
+	// Cents are snapped to a millionth first, so that float noise such as
+	// 1.15*100 = 114.99999999999999 doesn't decide the rounding
+	cents := math.Round(amount*100*1e6) / 1e6
+	switch m {
+	case RoundHalfEven:
+		cents = math.RoundToEven(cents)
+	case RoundDown:
+		cents = math.Trunc(cents)
+	default:
+		cents = math.Round(cents)
+	}
+	return cents / 100
+}
+
+// RateSource tells where the exchange rate behind a converted USD amount
+// came from
+type RateSource string
+
+const (
+	RateSourceFixed  RateSource = "fixed"  // a fixed rate from the settings
+	RateSourceLive   RateSource = "live"   // fetched from the API just now
+	RateSourceCache  RateSource = "cache"  // fetched from the API within the last hour
+	RateSourceStale  RateSource = "stale"  // fetched from the API over an hour ago, used while it couldn't be reached
+	RateSourceFile   RateSource = "file"   // loaded from the rates file
+	RateSourceManual RateSource = "manual" // the USD amount was entered by hand
+)
+
+// Describe says how the USD amount was arrived at, for showing next to it
+func (s RateSource) Describe() string {
+	switch s {
+	case RateSourceFixed:
+		return "at a fixed rate"
+	case RateSourceLive:
+		return "at a live rate"
+	case RateSourceCache:
+		return "at a cached live rate"
+	case RateSourceStale:
+		return "at an outdated rate (the rates API was unreachable)"
+	case RateSourceFile:
+		return "at a rate from the rates file"
+	case RateSourceManual:
+		return "entered by hand"
+	}
+	return ""
+}
@@ -3,6 +3,8 @@ package models
 import (
 	"errors"
 	"fmt"
+	"math"
+	"strings"
 	"time"
 
 	"gorm.io/gorm"
@@ -17,6 +19,15 @@ const (
 	FrequencyYearly  RecurrenceFrequency = "yearly"
 )
 
+// MaxFrequencyValue is the longest interval each frequency accepts, about
+// a year for the shorter ones. Anything beyond is most likely a typo.
+var MaxFrequencyValue = map[RecurrenceFrequency]int{
+	FrequencyDaily:   365,
+	FrequencyWeekly:  52,
+	FrequencyMonthly: 12,
+	FrequencyYearly:  10,
+}
+
 type RecurringTransaction struct {
 	ID             uint                `gorm:"primaryKey" json:"id"`
 	Type           TransactionType     `gorm:"type:varchar(20);not null" json:"type"`
@@ -35,11 +46,32 @@ type RecurringTransaction struct {
 	UpdatedAt      time.Time           `json:"updated_at"`
 	DeletedAt      gorm.DeletedAt      `gorm:"index" json:"deleted_at,omitempty"`
 
+	// AnnualIncreasePercent raises the amount on every anniversary of the
+	// start date, as with rent indexed to inflation. IncreasesApplied is how
+	// many anniversaries the amount already reflects.
+	AnnualIncreasePercent float64 `gorm:"default:0" json:"annual_increase_percent,omitempty"`
+	IncreasesApplied      int     `gorm:"default:0" json:"increases_applied,omitempty"`
+
 	// Relationships
 	Category     Category      `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
 	Transactions []Transaction `gorm:"foreignKey:RecurringTransactionID" json:"transactions,omitempty"`
 }
 
+// MaxAnnualIncreasePercent is the largest yearly increase a recurring
+// transaction accepts
+const MaxAnnualIncreasePercent = 100
+
+// RecurringTransactionPriceHistory records a change to a recurring
+// transaction's amount, such as a subscription raising its price
+type RecurringTransactionPriceHistory struct {
+	ID                     uint      `gorm:"primaryKey" json:"id"`
+	RecurringTransactionID uint      `gorm:"not null;index" json:"recurring_transaction_id"`
+	OldAmount              float64   `gorm:"not null" json:"old_amount"`
+	NewAmount              float64   `gorm:"not null" json:"new_amount"`
+	Currency               string    `gorm:"type:varchar(3);not null" json:"currency"`
+	ChangedAt              time.Time `gorm:"not null" json:"changed_at"`
+}
+
 // RecurringTransactionOccurrence tracks modifications to specific occurrences
 type RecurringTransactionOccurrence struct {
 	ID                     uint            `gorm:"primaryKey" json:"id"`
@@ -56,6 +88,42 @@ type RecurringTransactionOccurrence struct {
 	RecurringTransaction RecurringTransaction `gorm:"foreignKey:RecurringTransactionID" json:"recurring_transaction,omitempty"`
 }
 
+// NextIncome is the next occurrence of a recurring income that will
+// actually be paid, after skips and modifications
+type NextIncome struct {
+	Recurring   *RecurringTransaction
+	Date        time.Time
+	Description string
+	AmountUSD   float64
+}
+
+// RecurringMonthlyTotals is what the recurring transactions come to per
+// month, in USD. Paused transactions are left out.
+type RecurringMonthlyTotals struct {
+	Income   float64
+	Expenses float64
+	Net      float64
+}
+
+// SumMonthlyAmounts adds up the monthly USD amounts of the recurring
+// transactions, given by ID, by type. Paused transactions are left out.
+func SumMonthlyAmounts(items []*RecurringTransaction, amountsUSD map[uint]float64) *RecurringMonthlyTotals {
+	totals := &RecurringMonthlyTotals{}
+	for _, rt := range items {
+		if !rt.IsActive {
+			continue
+		}
+		switch rt.Type {
+		case TransactionTypeIncome:
+			totals.Income += amountsUSD[rt.ID]
+		case TransactionTypeExpense:
+			totals.Expenses += amountsUSD[rt.ID]
+		}
+	}
+	totals.Net = totals.Income - totals.Expenses
+	return totals
+}
+
 const (
 	OccurrenceActionSkip   = "skip"
 	OccurrenceActionModify = "modify"
@@ -78,10 +146,18 @@ func (rt *RecurringTransaction) Validate() error {
 		return errors.New("category is required")
 	}
 
+	if rt.Description != "" && strings.TrimSpace(rt.Description) == "" {
+		return errors.New("description cannot be only whitespace")
+	}
+
 	if rt.FrequencyValue < 1 {
 		rt.FrequencyValue = 1
 	}
 
+	if rt.AnnualIncreasePercent < 0 || rt.AnnualIncreasePercent > MaxAnnualIncreasePercent {
+		return fmt.Errorf("annual increase must be between 0 and %d percent", MaxAnnualIncreasePercent)
+	}
+
 	// Validate frequency
 	switch rt.Frequency {
 	case FrequencyDaily, FrequencyWeekly, FrequencyMonthly, FrequencyYearly:
@@ -90,6 +166,10 @@ func (rt *RecurringTransaction) Validate() error {
 		return fmt.Errorf("invalid frequency: %s", rt.Frequency)
 	}
 
+	if limit := MaxFrequencyValue[rt.Frequency]; rt.FrequencyValue > limit {
+		return fmt.Errorf("frequency value for %s rules must be at most %d", rt.Frequency, limit)
+	}
+
 	// Ensure start date is set
 	if rt.StartDate.IsZero() {
 		rt.StartDate = time.Now()
@@ -155,11 +235,54 @@ func (rt *RecurringTransaction) ShouldDeactivate(asOf time.Time) bool {
 	return asOf.After(*rt.EndDate)
 }
 
-// GenerateTransaction creates a transaction from this recurring transaction
+// DaysUntilEnd counts the calendar days from now to the end date, 0 when
+// it ends today. It is only meaningful when EndDate is set.
+func (rt *RecurringTransaction) DaysUntilEnd(now time.Time) int {
+	return CalendarDaysBetween(now, *rt.EndDate)
+}
+
+// CalendarDaysBetween counts the calendar days from from's date to to's,
+// both taken in from's location, e.g. 1 from today to any time tomorrow
+func CalendarDaysBetween(from, to time.Time) int {
+	to = to.In(from.Location())
+	// Counted between UTC midnights so a DST change doesn't shift the count
+	start := time.Date(from.Year(), from.Month(), from.Day(), 0, 0, 0, 0, time.UTC)
+	end := time.Date(to.Year(), to.Month(), to.Day(), 0, 0, 0, 0, time.UTC)
+	return int(end.Sub(start).Hours() / 24)
+}
+
+// AnniversariesBy counts the anniversaries of the start date on or before
+// date
+func (rt *RecurringTransaction) AnniversariesBy(date time.Time) int {
+	years := 0
+	for !rt.StartDate.AddDate(years+1, 0, 0).After(date) {
+		years++
+	}
+	return years
+}
+
+// AmountOn is the amount of an occurrence on date: the amount raised by the
+// annual increase for each anniversary it doesn't reflect yet, rounded to
+// the currency's decimals every year
+func (rt *RecurringTransaction) AmountOn(date time.Time) float64 {
+	amount := rt.Amount
+	if rt.AnnualIncreasePercent == 0 {
+		return amount
+	}
+
+	scale := math.Pow10(CurrencyDecimals(rt.Currency))
+	for i := rt.IncreasesApplied; i < rt.AnniversariesBy(date); i++ {
+		amount = math.Round(amount*(1+rt.AnnualIncreasePercent/100)*scale) / scale
+	}
+	return amount
+}
+
+// GenerateTransaction creates a transaction from this recurring transaction,
+// with the annual increases due by date applied
 func (rt *RecurringTransaction) GenerateTransaction(date time.Time) *Transaction {
 	return &Transaction{
 		Type:                   rt.Type,
-		Amount:                 rt.Amount,
+		Amount:                 rt.AmountOn(date),
 		Currency:               rt.Currency,
 		CategoryID:             rt.CategoryID,
 		Description:            rt.Description,
@@ -168,6 +291,23 @@ func (rt *RecurringTransaction) GenerateTransaction(date time.Time) *Transaction
 	}
 }
 
+// MonthlyAmount is the amount averaged over a month, in the transaction's
+// currency, whether or not it is paused
+func (rt *RecurringTransaction) MonthlyAmount() float64 {
+	switch rt.Frequency {
+	case FrequencyDaily:
+		return rt.Amount * 30.44 / float64(rt.FrequencyValue) // Average days per month
+	case FrequencyWeekly:
+		return rt.Amount * 4.33 / float64(rt.FrequencyValue) // Average weeks per month
+	case FrequencyMonthly:
+		return rt.Amount / float64(rt.FrequencyValue)
+	case FrequencyYearly:
+		return rt.Amount / (12 * float64(rt.FrequencyValue))
+	default:
+		return rt.Amount
+	}
+}
+
 // GetFrequencyDisplay returns a human-readable frequency description
 func (rt *RecurringTransaction) GetFrequencyDisplay() string {
 	if rt.FrequencyValue == 1 {
@@ -1,6 +1,9 @@
 package models
 
 import (
+	"fmt"
+	"path/filepath"
+	"regexp"
 	"time"
 )
 
@@ -8,21 +11,236 @@ import (
 type Settings struct {
 	Currencies CurrencySettings `json:"currencies"`
 	UI         UISettings       `json:"ui"`
-	Version    string          `json:"version"`
+	// MonthlyLimit caps the total expenses of a month, in USD, across all
+	// categories. Zero means no limit.
+	MonthlyLimit float64             `json:"monthly_limit,omitempty"`
+	AutoExport   AutoExportSettings  `json:"auto_export"`
+	BudgetAlerts BudgetAlertSettings `json:"budget_alerts"`
+	Version      string              `json:"version"`
+}
+
+// AutoExportSettings controls the CSV snapshot of the month's transactions
+// written each time the app exits cleanly
+type AutoExportSettings struct {
+	Enabled bool `json:"enabled"`
+	// Directory is where the snapshots go, the exports folder in the data
+	// directory when empty; a relative path is taken from the data directory
+	Directory string `json:"directory,omitempty"`
+	// Keep is how many snapshots are kept, the oldest being removed first;
+	// DefaultAutoExportKeep when zero
+	Keep int `json:"keep,omitempty"`
+}
+
+// DefaultAutoExportKeep is how many auto-exports are kept unless the
+// settings say otherwise
+const DefaultAutoExportKeep = 12
+
+// KeepCount returns how many auto-exports to keep
+func (a AutoExportSettings) KeepCount() int {
+	if a.Keep > 0 {
+		return a.Keep
+	}
+	return DefaultAutoExportKeep
+}
+
+// Dir returns the directory the auto-exports go to, resolving Directory
+// against dataDir
+func (a AutoExportSettings) Dir(dataDir string) string {
+	if a.Directory == "" {
+		return filepath.Join(dataDir, "exports")
+	}
+	if filepath.IsAbs(a.Directory) {
+		return a.Directory
+	}
+	return filepath.Join(dataDir, a.Directory)
+}
+
+// BudgetAlertSettings are the shares of a budget, in percent, at which
+// -check-budgets warns about it and reports it as blown
+type BudgetAlertSettings struct {
+	// WarnPercent is BudgetWarnPercent when zero
+	WarnPercent float64 `json:"warn_percent,omitempty"`
+	// CritPercent is DefaultBudgetCritPercent when zero
+	CritPercent float64 `json:"crit_percent,omitempty"`
+}
+
+// DefaultBudgetCritPercent is the share of a budget past which it is blown
+// unless the settings say otherwise
+const DefaultBudgetCritPercent = 100
+
+// Thresholds returns the warning and critical percentages
+func (b BudgetAlertSettings) Thresholds() (warn, crit float64) {
+	warn, crit = BudgetWarnPercent, DefaultBudgetCritPercent
+	if b.WarnPercent > 0 {
+		warn = b.WarnPercent
+	}
+	if b.CritPercent > 0 {
+		crit = b.CritPercent
+	}
+	return warn, crit
 }
 
 // CurrencySettings holds currency-related configuration
 type CurrencySettings struct {
-	Enabled    []string           `json:"enabled"`
-	Default    string             `json:"default"`
-	FixedRates map[string]float64 `json:"fixed_rates"`
+	Enabled         []string           `json:"enabled"`
+	Default         string             `json:"default"`
+	FixedRates      map[string]float64 `json:"fixed_rates"`
+	RatesFile       string             `json:"rates_file,omitempty"`
+	PreferFileRates bool               `json:"prefer_file_rates,omitempty"`
+	// Rounding is how converted USD amounts are rounded to cents before
+	// they are stored, RoundHalfUp when empty
+	Rounding RoundingMode `json:"rounding,omitempty"`
+}
+
+// RatesFilePath returns the path of the rates file, or "" without one; a
+// relative RatesFile is taken from dataDir
+func (c CurrencySettings) RatesFilePath(dataDir string) string {
+	if c.RatesFile == "" || filepath.IsAbs(c.RatesFile) {
+		return c.RatesFile
+	}
+	return filepath.Join(dataDir, c.RatesFile)
 }
 
 // UISettings holds UI-related preferences
 type UISettings struct {
-	DateFormat    string `json:"date_format"`
-	DecimalPlaces int    `json:"decimal_places"`
-	Theme         string `json:"theme"`
+	DateFormat    string            `json:"date_format"`
+	DecimalPlaces int               `json:"decimal_places"`
+	Theme         string            `json:"theme"`
+	Dashboard     DashboardSettings `json:"dashboard"`
+	// TagPattern is a regular expression that picks a tag, such as a
+	// project code, out of expense descriptions for the tag breakdown in
+	// reports. Its first capture group is the tag, or the whole match if it
+	// has none.
+	TagPattern string `json:"tag_pattern,omitempty"`
+}
+
+// DashboardSettings holds the order and visibility of the dashboard widgets
+type DashboardSettings struct {
+	Widgets []DashboardWidget `json:"widgets"`
+}
+
+// DashboardWidget is one dashboard section and whether it is shown
+type DashboardWidget struct {
+	Name    string `json:"name"`
+	Enabled bool   `json:"enabled"`
+}
+
+// Dashboard widget names
+const (
+	WidgetBurnRate     = "burn_rate"
+	WidgetNextIncome   = "next_income"
+	WidgetSummary      = "summary"
+	WidgetLimit        = "monthly_limit"
+	WidgetPace         = "pace"
+	WidgetBudgets      = "budgets"
+	WidgetTransactions = "transactions"
+)
+
+// DashboardWidgets lists every dashboard widget in its default order
+var DashboardWidgets = []string{WidgetBurnRate, WidgetNextIncome, WidgetSummary, WidgetLimit, WidgetPace, WidgetBudgets, WidgetTransactions}
+
+// DashboardWidgetTitles are the names widgets are shown with in the UI
+var DashboardWidgetTitles = map[string]string{
+	WidgetBurnRate:     "Monthly burn rate",
+	WidgetNextIncome:   "Next income",
+	WidgetSummary:      "Income & expenses",
+	WidgetLimit:        "Monthly spending limit",
+	WidgetPace:         "Spending pace",
+	WidgetBudgets:      "Budget overview",
+	WidgetTransactions: "Recent transactions",
+}
+
+// Layout returns the configured widgets in order. Widgets missing from the
+// settings, such as ones added after the file was written, are appended
+// enabled; unknown and repeated names are dropped.
+func (d DashboardSettings) Layout() []DashboardWidget {
+	layout := make([]DashboardWidget, 0, len(DashboardWidgets))
+	seen := make(map[string]bool)
+	for _, widget := range d.Widgets {
+		if _, ok := DashboardWidgetTitles[widget.Name]; !ok || seen[widget.Name] {
+			continue
+		}
+		seen[widget.Name] = true
+		layout = append(layout, widget)
+	}
+	for _, name := range DashboardWidgets {
+		if !seen[name] {
+			layout = append(layout, DashboardWidget{Name: name, Enabled: true})
+		}
+	}
+	return layout
+}
+
+// MaxDecimalPlaces is the most decimal places amounts can be shown with
+const MaxDecimalPlaces = 6
+
+// DateFormatPresets are the date layouts offered in the settings view
+var DateFormatPresets = []string{
+	"2006-01-02",
+	"02/01/2006",
+	"01/02/2006",
+	"02 Jan 2006",
+	"Jan 2, 2006",
+}
+
+// Themes are the color themes the UI can render
+var Themes = []string{"default", "ocean", "forest"}
+
+// Validate checks the UI preferences before they are saved
+func (u *UISettings) Validate() error {
+	if u.DateFormat == "" {
+		return fmt.Errorf("date format is required")
+	}
+
+	if !IsDateLayout(u.DateFormat) {
+		return fmt.Errorf("date format %q is not a Go date layout showing the year, month and day, such as 2006-01-02", u.DateFormat)
+	}
+
+	if u.DecimalPlaces < 0 || u.DecimalPlaces > MaxDecimalPlaces {
+		return fmt.Errorf("decimal places must be between 0 and %d", MaxDecimalPlaces)
+	}
+
+	if !IsKnownTheme(u.Theme) {
+		return fmt.Errorf("unknown theme %q", u.Theme)
+	}
+
+	seen := make(map[string]bool)
+	for _, widget := range u.Dashboard.Widgets {
+		if _, ok := DashboardWidgetTitles[widget.Name]; !ok {
+			return fmt.Errorf("unknown dashboard widget %q", widget.Name)
+		}
+		if seen[widget.Name] {
+			return fmt.Errorf("dashboard widget %q is listed twice", widget.Name)
+		}
+		seen[widget.Name] = true
+	}
+
+	if u.TagPattern != "" {
+		if _, err := regexp.Compile(u.TagPattern); err != nil {
+			return fmt.Errorf("invalid tag pattern: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// IsDateLayout reports whether layout is a Go time layout that shows a full
+// date: formatting a day with it and parsing the result back gives the
+// same day
+func IsDateLayout(layout string) bool {
+	day := time.Date(2006, time.November, 23, 0, 0, 0, 0, time.UTC)
+	parsed, err := time.Parse(layout, day.Format(layout))
+	return err == nil && parsed.Year() == day.Year() && parsed.Month() == day.Month() && parsed.Day() == day.Day()
+}
+
+// IsKnownTheme reports whether theme is one of Themes
+func IsKnownTheme(theme string) bool {
+	for _, t := range Themes {
+		if t == theme {
+			return true
+		}
+	}
+	return false
 }
 
 // DefaultSettings returns the default application settings
@@ -34,6 +252,7 @@ func DefaultSettings() *Settings {
 			FixedRates: map[string]float64{
 				"AED": 3.6725,
 			},
+			Rounding: RoundHalfUp,
 		},
 		UI: UISettings{
 			DateFormat:    "2006-01-02",
@@ -96,13 +315,31 @@ func (s *Settings) SetDefaultCurrency(currency string) bool {
 type CategoryHistoryAction string
 
 const (
-	CategoryActionCreated CategoryHistoryAction = "created"
-	CategoryActionRenamed CategoryHistoryAction = "renamed"
-	CategoryActionMerged  CategoryHistoryAction = "merged"
-	CategoryActionDeleted CategoryHistoryAction = "deleted"
-	CategoryActionEdited  CategoryHistoryAction = "edited"
+	CategoryActionCreated    CategoryHistoryAction = "created"
+	CategoryActionRenamed    CategoryHistoryAction = "renamed"
+	CategoryActionMerged     CategoryHistoryAction = "merged"
+	CategoryActionUnmerged   CategoryHistoryAction = "unmerged"
+	CategoryActionDeleted    CategoryHistoryAction = "deleted"
+	CategoryActionEdited     CategoryHistoryAction = "edited"
+	CategoryActionArchived   CategoryHistoryAction = "archived"
+	CategoryActionUnarchived CategoryHistoryAction = "unarchived"
 )
 
+// CategoryMergeMoves records what merging a category moved into the
+// target, so that unmerging it moves back exactly those and leaves what was
+// in the target already
+type CategoryMergeMoves struct {
+	TransactionIDs []uint `json:"transaction_ids,omitempty"`
+	SplitItemIDs   []uint `json:"split_item_ids,omitempty"`
+	BudgetIDs      []uint `json:"budget_ids,omitempty"`
+	// LinkedBudgetIDs are the group budgets whose link to the source was
+	// moved to the target, and DroppedBudgetIDs the ones whose link was
+	// dropped because they already covered the target
+	LinkedBudgetIDs  []uint `json:"linked_budget_ids,omitempty"`
+	DroppedBudgetIDs []uint `json:"dropped_budget_ids,omitempty"`
+	RecurringIDs     []uint `json:"recurring_ids,omitempty"`
+}
+
 // CategoryHistory tracks changes to categories
 type CategoryHistory struct {
 	ID               uint                  `gorm:"primaryKey" json:"id"`
@@ -117,8 +354,10 @@ type CategoryHistory struct {
 	TargetCategoryID *uint                 `json:"target_category_id,omitempty"`
 	TransactionCount int                   `json:"transaction_count"`
 	Notes            string                `gorm:"type:text" json:"notes,omitempty"`
-	CreatedAt        time.Time             `json:"created_at"`
+	// Moved is what a merge moved into the target, for undoing it
+	Moved     *CategoryMergeMoves `gorm:"serializer:json" json:"moved,omitempty"`
+	CreatedAt time.Time           `json:"created_at"`
 
 	Category       *Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
 	TargetCategory *Category `gorm:"foreignKey:TargetCategoryID" json:"target_category,omitempty"`
-}
\ No newline at end of file
+}
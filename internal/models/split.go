@@ -0,0 +1,82 @@
+package models
+
+import (
+	"errors"
+	"fmt"
+	"math"
+)
+
+// SplitItem is one line of a split transaction, such as the household
+// items on a supermarket receipt that is otherwise groceries. Amount is in
+// the transaction's currency; AmountUSD is its share of the transaction's
+// USD amount. Category totals count the lines of a split transaction
+// instead of the transaction itself.
+type SplitItem struct {
+	ID            uint    `gorm:"primaryKey" json:"id"`
+	TransactionID uint    `gorm:"not null;index" json:"transaction_id"`
+	CategoryID    uint    `gorm:"not null;index" json:"category_id"`
+	Amount        float64 `gorm:"not null" json:"amount"`
+	AmountUSD     float64 `gorm:"not null" json:"amount_usd"`
+	Description   string  `gorm:"type:varchar(255)" json:"description,omitempty"`
+
+	Category Category `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
+}
+
+func (s *SplitItem) Validate() error {
+	if s.CategoryID == 0 {
+		return errors.New("each split line needs a category")
+	}
+
+	if s.Amount <= 0 {
+		return errors.New("split line amounts must be positive")
+	}
+
+	return nil
+}
+
+// MinSplitLines is how many lines a split transaction has at least
+const MinSplitLines = 2
+
+// CheckSplitTotal makes sure the lines add up to amount once each is
+// rounded to the currency's smallest unit, as they are shown
+func CheckSplitTotal(amount float64, currency string, lines []SplitItem) error {
+	decimals := CurrencyDecimals(currency)
+	unit := math.Pow10(decimals)
+	var total float64
+	for _, line := range lines {
+		total += math.Round(line.Amount*unit) / unit
+	}
+
+	if math.Abs(total-amount) >= 0.5/unit {
+		return fmt.Errorf("split lines total %.*f %s, not the %.*f %s of the transaction",
+			decimals, total, currency, decimals, amount, currency)
+	}
+	return nil
+}
+
+// SplitRemainder is what is left of amount for the last line once the
+// others are taken out, rounded to the currency's smallest unit
+func SplitRemainder(amount float64, currency string, others []SplitItem) float64 {
+	remainder := amount
+	for _, line := range others {
+		remainder -= line.Amount
+	}
+	unit := math.Pow10(CurrencyDecimals(currency))
+	return math.Round(remainder*unit) / unit
+}
+
+// SpreadUSD sets each line's AmountUSD to its share of amountUSD, rounded
+// to cents. The last line absorbs what rounding leaves over, so the lines
+// always add up to amountUSD exactly.
+func SpreadUSD(amount, amountUSD float64, lines []SplitItem) {
+	if len(lines) == 0 {
+		return
+	}
+
+	var spread float64
+	for i := range lines[:len(lines)-1] {
+		lines[i].AmountUSD = RoundUSD(lines[i].Amount / amount * amountUSD)
+		spread += lines[i].AmountUSD
+	}
+	lines[len(lines)-1].AmountUSD = RoundUSD(amountUSD - spread)
+}
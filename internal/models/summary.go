@@ -0,0 +1,77 @@
+package models
+
+// StartupDigest lists what needs attention when the app starts, which is
+// otherwise spread over several views
+type StartupDigest struct {
+	OverBudget []*BudgetStatus         `json:"over_budget"`
+	Expiring   []*RecurringTransaction `json:"expiring"`  // ending soon, soonest first
+	Generated  []*Transaction          `json:"generated"` // catch-up transactions from recurring ones
+}
+
+// IsEmpty reports whether nothing needs attention
+func (d *StartupDigest) IsEmpty() bool {
+	return len(d.OverBudget) == 0 && len(d.Expiring) == 0 && len(d.Generated) == 0
+}
+
+// YearOverYear compares a month with the same month a year earlier, overall
+// and per category, e.g. March 2025 against March 2024
+type YearOverYear struct {
+	Current    *TransactionSummary   `json:"current"`
+	Previous   *TransactionSummary   `json:"previous"`
+	Categories []*CategoryComparison `json:"categories"`
+}
+
+// CategoryComparison is one category's total in the month and in the same
+// month a year earlier. IsNew is set for categories created after that
+// earlier month, which had nothing to compare against.
+type CategoryComparison struct {
+	Category
+	Current  float64 `json:"current"`
+	Previous float64 `json:"previous"`
+	IsNew    bool    `json:"is_new"`
+}
+
+// Delta is how much the total changed since a year earlier
+func (c *CategoryComparison) Delta() float64 {
+	return RoundUSD(c.Current - c.Previous)
+}
+
+// Change returns the percentage change since a year earlier. It is zero
+// when there was nothing a year earlier to compare against.
+func (c *CategoryComparison) Change() float64 {
+	if c.Previous == 0 {
+		return 0
+	}
+	return (c.Current - c.Previous) / c.Previous * 100
+}
+
+// YearlyReport sums up a year month by month. Months that haven't started
+// yet are nil rather than zero, so they don't drag the averages down.
+type YearlyReport struct {
+	Year          int                  `json:"year"`
+	Months        [12]*MonthlyTotal    `json:"months"`
+	Total         *TransactionSummary  `json:"total"`
+	TopCategories []*CategoryWithTotal `json:"top_categories"` // the biggest expense categories
+	Largest       *Transaction         `json:"largest"`        // the biggest single expense, nil without any
+}
+
+// MonthsElapsed counts the months of the year that have started
+func (r *YearlyReport) MonthsElapsed() int {
+	count := 0
+	for _, month := range r.Months {
+		if month != nil {
+			count++
+		}
+	}
+	return count
+}
+
+// AverageExpenses is the monthly average of the expenses over the months
+// that have started, or zero when none has
+func (r *YearlyReport) AverageExpenses() float64 {
+	elapsed := r.MonthsElapsed()
+	if elapsed == 0 {
+		return 0
+	}
+	return RoundUSD(r.Total.TotalExpenses / float64(elapsed))
+}
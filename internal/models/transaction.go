@@ -2,6 +2,8 @@ package models
 
 import (
 	"errors"
+	"fmt"
+	"strconv"
 	"time"
 
 	"gorm.io/gorm"
@@ -21,16 +23,29 @@ type Transaction struct {
 	Amount                 float64         `gorm:"not null" json:"amount"`
 	Currency               string          `gorm:"type:varchar(3);not null" json:"currency"`
 	AmountUSD              float64         `gorm:"not null" json:"amount_usd"`
+	ManualUSD              bool            `gorm:"not null;default:false" json:"manual_usd"` // AmountUSD was entered by hand, not converted
+	RateSource             RateSource      `gorm:"type:varchar(10)" json:"rate_source,omitempty"` // empty for USD, which needs no rate
 	CategoryID             uint            `gorm:"not null" json:"category_id"`
 	Description            string          `gorm:"type:varchar(255)" json:"description"`
+	IsRefund               bool            `gorm:"not null;default:false" json:"is_refund"`
+	RefundOfID             *uint           `gorm:"index" json:"refund_of_id,omitempty"` // the expense a refund gives money back for, if linked
 	Date                   time.Time       `gorm:"not null" json:"date"`
 	RecurringTransactionID *uint           `json:"recurring_transaction_id,omitempty"`
+	AccountID              *uint           `gorm:"index" json:"account_id,omitempty"` // the account paid from or into, if any
+	TransferPairID         *uint           `gorm:"index" json:"transfer_pair_id,omitempty"` // the other half of a transfer
+	IsTransferIn           bool            `gorm:"not null;default:false" json:"is_transfer_in"` // the half of a transfer moving money into its account
 	CreatedAt              time.Time       `json:"created_at"`
 	UpdatedAt              time.Time       `json:"updated_at"`
 	DeletedAt              gorm.DeletedAt  `gorm:"index" json:"deleted_at,omitempty"`
 
 	Category             Category              `gorm:"foreignKey:CategoryID" json:"category,omitempty"`
 	RecurringTransaction *RecurringTransaction `gorm:"foreignKey:RecurringTransactionID" json:"recurring_transaction,omitempty"`
+	RefundOf             *Transaction          `gorm:"foreignKey:RefundOfID" json:"refund_of,omitempty"`
+	Account              *Account              `gorm:"foreignKey:AccountID" json:"account,omitempty"`
+	Attachments          []Attachment          `gorm:"foreignKey:TransactionID" json:"attachments,omitempty"`
+	// Splits are the line items of a split transaction, which count towards
+	// their own categories instead of the transaction's
+	Splits               []SplitItem           `gorm:"foreignKey:TransactionID" json:"splits,omitempty"`
 }
 
 func (t *Transaction) Validate() error {
@@ -42,14 +57,40 @@ func (t *Transaction) Validate() error {
 		return errors.New("amount must be positive")
 	}
 
+	if t.IsRefund && t.Type != TransactionTypeExpense {
+		return errors.New("only expense transactions can be refunds")
+	}
+
+	if t.RefundOfID != nil && !t.IsRefund {
+		return errors.New("only refunds can be linked to an original transaction")
+	}
+
+	if t.RefundOfID != nil && t.ID != 0 && *t.RefundOfID == t.ID {
+		return errors.New("a refund can't be linked to itself")
+	}
+
+	if t.ManualUSD && t.AmountUSD <= 0 {
+		return errors.New("USD amount must be positive")
+	}
+
 	if len(t.Currency) != 3 {
 		return errors.New("currency must be a 3-letter ISO code")
 	}
 
-	if t.CategoryID == 0 {
+	// Transfers move money between accounts, so they have no category but
+	// always an account
+	if t.CategoryID == 0 && t.Type != TransactionTypeTransfer {
 		return errors.New("category is required")
 	}
 
+	if t.Type == TransactionTypeTransfer && t.AccountID == nil {
+		return errors.New("a transfer needs an account")
+	}
+
+	if t.IsTransferIn && t.Type != TransactionTypeTransfer {
+		return errors.New("only transfers can move money into an account")
+	}
+
 	if t.Date.IsZero() {
 		return errors.New("date is required")
 	}
@@ -57,6 +98,54 @@ func (t *Transaction) Validate() error {
 	return nil
 }
 
+// NetAmountUSD returns the USD amount as it counts towards its category's
+// total: refunds are negative so they net against the category's expenses.
+func (t *Transaction) NetAmountUSD() float64 {
+	if t.IsRefund {
+		return -t.AmountUSD
+	}
+	return t.AmountUSD
+}
+
+// DisplayType returns the type label shown in lists and exports
+func (t *Transaction) DisplayType() string {
+	if t.IsRefund {
+		return "refund"
+	}
+	return string(t.Type)
+}
+
+// CategoryLabel is the category's icon and name, or a transfer's label
+// since transfers have no category. Split transactions show how many lines
+// they have.
+func (t *Transaction) CategoryLabel() string {
+	if t.Type == TransactionTypeTransfer {
+		return "⇄ Transfer"
+	}
+	if t.IsSplit() {
+		return fmt.Sprintf("✂ Split (%d)", len(t.Splits))
+	}
+	return t.Category.Icon + " " + t.Category.Name
+}
+
+// IsSplit reports whether the transaction is split across categories. The
+// splits must have been loaded with it.
+func (t *Transaction) IsSplit() bool {
+	return len(t.Splits) > 0
+}
+
+// IsOutflow reports whether money leaves with the transaction: an expense
+// that isn't a refund, or the outgoing half of a transfer
+func (t *Transaction) IsOutflow() bool {
+	switch t.Type {
+	case TransactionTypeExpense:
+		return !t.IsRefund
+	case TransactionTypeTransfer:
+		return !t.IsTransferIn
+	}
+	return false
+}
+
 func (t *Transaction) BeforeCreate(tx *gorm.DB) error {
 	if err := t.Validate(); err != nil {
 		return err
@@ -69,6 +158,28 @@ func (t *Transaction) BeforeCreate(tx *gorm.DB) error {
 	return nil
 }
 
+// BeforeSave rounds the converted USD amount to cents. The amount itself
+// is kept as entered.
+func (t *Transaction) BeforeSave(tx *gorm.DB) error {
+	t.AmountUSD = RoundUSD(t.AmountUSD)
+	return nil
+}
+
+type TransactionSortField string
+
+const (
+	SortByDate     TransactionSortField = "date"
+	SortByAmount   TransactionSortField = "amount"
+	SortByCategory TransactionSortField = "category"
+)
+
+type SortDirection string
+
+const (
+	SortDesc SortDirection = "desc"
+	SortAsc  SortDirection = "asc"
+)
+
 type TransactionFilter struct {
 	Type       TransactionType
 	CategoryID uint
@@ -78,25 +189,188 @@ type TransactionFilter struct {
 	MaxAmount  float64
 	Currency   string
 	Search     string
+	SortBy     TransactionSortField
+	SortDir    SortDirection
+	// OutsideDates lists the transactions before StartDate or after
+	// EndDate instead of those between them
+	OutsideDates bool
+}
+
+// FilterPreset names a predefined filter of the transaction list
+type FilterPreset string
+
+const (
+	PresetThisMonth     FilterPreset = "This month"
+	PresetLastMonth     FilterPreset = "Last month"
+	PresetThisYear      FilterPreset = "This year"
+	PresetUncategorized FilterPreset = "Uncategorized" // the default "Other" expense category
+	PresetUnusualDates  FilterPreset = "Unusual dates" // outside SaneDateWindow
+)
+
+// ApplyPreset replaces the period and category of f with those of preset,
+// keeping its search, type, amounts and sort order
+func (f *TransactionFilter) ApplyPreset(preset *TransactionFilter) {
+	f.StartDate = preset.StartDate
+	f.EndDate = preset.EndDate
+	f.OutsideDates = preset.OutsideDates
+	f.CategoryID = preset.CategoryID
+}
+
+// Dates more than UnusualFutureDays ahead or UnusualPastYears back are
+// likely typos, such as 2035 for 2025, which no report would ever show
+const (
+	UnusualFutureDays = 1
+	UnusualPastYears  = 5
+)
+
+// SaneDateWindow returns the earliest and latest dates that look plausible
+// for a transaction entered on the date of now
+func SaneDateWindow(now time.Time) (earliest, latest time.Time) {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	return today.AddDate(-UnusualPastYears, 0, 0), today.AddDate(0, 0, UnusualFutureDays+1).Add(-time.Second)
+}
+
+// DateWarning says why date looks like a typo, e.g. "Date is 2035-06-01,
+// in the future", or returns "" when it is within SaneDateWindow
+func DateWarning(date, now time.Time) string {
+	earliest, latest := SaneDateWindow(now)
+	switch {
+	case date.After(latest):
+		return fmt.Sprintf("Date is %s, in the future", date.Format("2006-01-02"))
+	case date.Before(earliest):
+		return fmt.Sprintf("Date is %s, more than %d years ago", date.Format("2006-01-02"), UnusualPastYears)
+	}
+	return ""
+}
+
+// CurrencyStat is how much one currency is used: its transaction count and
+// the volume in that currency and in USD
+type CurrencyStat struct {
+	Currency string  `json:"currency"`
+	Count    int     `json:"count"`
+	Total    float64 `json:"total"`
+	TotalUSD float64 `json:"total_usd"`
+}
+
+// UntaggedLabel is the tag breakdown's group for descriptions the tag
+// pattern doesn't match
+const UntaggedLabel = "(untagged)"
+
+// TagTotal is the net amount spent, in USD, on expenses whose description
+// carries one tag
+type TagTotal struct {
+	Tag   string  `json:"tag"`
+	Total float64 `json:"total"`
+	Count int     `json:"count"`
+}
+
+// DescriptionStat is the net amount spent, in USD, on expenses with one
+// description, such as a merchant. Description is the spelling of the most
+// recent of them.
+type DescriptionStat struct {
+	Description string  `json:"description"`
+	Count       int     `json:"count"`
+	Total       float64 `json:"total"`
+}
+
+// DescriptionOrder is which descriptions come first in GetTopDescriptions
+type DescriptionOrder string
+
+const (
+	// DescriptionsByTotal puts those with the most spent on them first
+	DescriptionsByTotal DescriptionOrder = "total"
+	// DescriptionsByCount puts the most frequent first
+	DescriptionsByCount DescriptionOrder = "count"
+)
+
+// DailyTotal is the net amount spent on one day, in USD. Date is local
+// midnight of that day.
+type DailyTotal struct {
+	Date  time.Time `json:"date"`
+	Total float64   `json:"total"`
+}
+
+// MonthlyTotal is the income and expenses of one month, in USD, refunds
+// netted against expenses. Month is local midnight of the first day.
+type MonthlyTotal struct {
+	Month    time.Time `json:"month"`
+	Income   float64   `json:"income"`
+	Expenses float64   `json:"expenses"`
+	Count    int       `json:"count"`
+}
+
+// Net is what was left of the month's income after its expenses
+func (m *MonthlyTotal) Net() float64 {
+	return RoundUSD(m.Income - m.Expenses)
+}
+
+// DefaultAmountBuckets are the bounds, in USD, of the transaction size ranges
+// in the reports: under $10, $10–50, $50–200 and $200 or more
+var DefaultAmountBuckets = []float64{10, 50, 200}
+
+// AmountBucketLabels names the ranges below, between and above the
+// ascending bounds, smallest first, e.g. "<$10", "$10–50", "$50–200" and
+// "$200+". A range includes its lower bound.
+func AmountBucketLabels(bounds []float64) []string {
+	format := func(bound float64) string {
+		return strconv.FormatFloat(bound, 'f', -1, 64)
+	}
+
+	if len(bounds) == 0 {
+		return nil
+	}
+	labels := []string{"<$" + format(bounds[0])}
+	for i := 1; i < len(bounds); i++ {
+		labels = append(labels, "$"+format(bounds[i-1])+"–"+format(bounds[i]))
+	}
+	return append(labels, "$"+format(bounds[len(bounds)-1])+"+")
 }
 
 type TransactionSummary struct {
-	TotalIncome   float64
-	TotalExpenses float64
-	Balance       float64
-	Count         int
+	TotalIncome   float64 `json:"total_income"`
+	TotalExpenses float64 `json:"total_expenses"`
+	Balance       float64 `json:"balance"`
+	Count         int     `json:"count"`
+
+	// The totals split by whether the transactions were generated by a
+	// recurring rule (fixed) or entered once (variable)
+	RecurringIncome   float64 `json:"recurring_income"`
+	OneTimeIncome     float64 `json:"one_time_income"`
+	RecurringExpenses float64 `json:"recurring_expenses"`
+	OneTimeExpenses   float64 `json:"one_time_expenses"`
 }
 
 func (ts *TransactionSummary) CalculateBalance() {
-	ts.Balance = ts.TotalIncome - ts.TotalExpenses
+	ts.Balance = RoundUSD(ts.TotalIncome - ts.TotalExpenses)
+}
+
+// SavingsRateTarget is the savings rate (in percent) considered healthy
+const SavingsRateTarget = 20.0
+
+// SavingsRate returns (income - expenses) / income as a percentage.
+// It is zero when there is no income.
+func (ts *TransactionSummary) SavingsRate() float64 {
+	if ts.TotalIncome == 0 {
+		return 0
+	}
+	return (ts.TotalIncome - ts.TotalExpenses) / ts.TotalIncome * 100
+}
+
+// IncomeExpenseRatio returns income divided by expenses, or zero when there
+// are no expenses
+func (ts *TransactionSummary) IncomeExpenseRatio() float64 {
+	if ts.TotalExpenses == 0 {
+		return 0
+	}
+	return ts.TotalIncome / ts.TotalExpenses
 }
 
 type BurnRateSummary struct {
-	RecurringExpenses   float64
-	RecurringCount      int
-	OneTimeExpenses     float64
-	OneTimeCount        int
-	TotalBurn           float64
-	ProjectedMonthly    float64
-	ProjectedYearly     float64
+	RecurringExpenses float64 `json:"recurring_expenses"`
+	RecurringCount    int     `json:"recurring_count"`
+	OneTimeExpenses   float64 `json:"one_time_expenses"`
+	OneTimeCount      int     `json:"one_time_count"`
+	TotalBurn         float64 `json:"total_burn"`
+	ProjectedMonthly  float64 `json:"projected_monthly"`
+	ProjectedYearly   float64 `json:"projected_yearly"`
 }
\ No newline at end of file
@@ -0,0 +1,137 @@
+package repository
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+
+	"burnwise/internal/models"
+)
+
+type AccountRepository struct {
+	db *gorm.DB
+}
+
+func NewAccountRepository(db *gorm.DB) *AccountRepository {
+	registerErrorTranslation(db)
+	return &AccountRepository{db: db}
+}
+
+func (r *AccountRepository) Create(ctx context.Context, account *models.Account) error {
+	return r.db.WithContext(ctx).Create(account).Error
+}
+
+func (r *AccountRepository) GetByID(ctx context.Context, id uint) (*models.Account, error) {
+	var account models.Account
+	if err := r.db.WithContext(ctx).First(&account, id).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+// GetByName finds the account with the given name, ignoring case
+func (r *AccountRepository) GetByName(ctx context.Context, name string) (*models.Account, error) {
+	var account models.Account
+	if err := r.db.WithContext(ctx).Where("LOWER(name) = LOWER(?)", name).First(&account).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *AccountRepository) Update(ctx context.Context, account *models.Account) error {
+	return r.db.WithContext(ctx).Save(account).Error
+}
+
+// Delete removes the account, leaving its transactions without one
+func (r *AccountRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Unscoped().Model(&models.Transaction{}).
+			Where("account_id = ?", id).
+			Update("account_id", nil).Error; err != nil {
+			return err
+		}
+		return tx.Delete(&models.Account{}, id).Error
+	})
+}
+
+// CountTransfers counts the transfer halves paid from or into the account
+func (r *AccountRepository) CountTransfers(ctx context.Context, id uint) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Model(&models.Transaction{}).
+		Where("account_id = ? AND type = ?", id, models.TransactionTypeTransfer).
+		Count(&count).Error
+	return count, err
+}
+
+func (r *AccountRepository) GetAll(ctx context.Context) ([]*models.Account, error) {
+	var accounts []*models.Account
+	err := r.db.WithContext(ctx).Order("name ASC").Find(&accounts).Error
+	return accounts, err
+}
+
+// GetSummaries totals the income, expenses and transfers of each account
+// in the period, refunds netted against expenses. Every account is included, even
+// without transactions, followed by the transactions without an account
+// if there are any.
+func (r *AccountRepository) GetSummaries(ctx context.Context, start, end time.Time) ([]*models.AccountSummary, error) {
+	accounts, err := r.GetAll(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		AccountID      *uint
+		Income         float64
+		Expenses       float64
+		TransferredIn  float64
+		TransferredOut float64
+		Count          int
+	}
+	err = r.db.WithContext(ctx).Model(&models.Transaction{}).
+		Select("account_id, "+
+			"COALESCE(SUM(CASE WHEN type = ? THEN amount_usd ELSE 0 END), 0) as income, "+
+			"COALESCE(SUM(CASE WHEN type = ? THEN "+netAmountSQL+" ELSE 0 END), 0) as expenses, "+
+			"COALESCE(SUM(CASE WHEN type = ? AND is_transfer_in THEN amount_usd ELSE 0 END), 0) as transferred_in, "+
+			"COALESCE(SUM(CASE WHEN type = ? AND NOT is_transfer_in THEN amount_usd ELSE 0 END), 0) as transferred_out, "+
+			"COUNT(*) as count", models.TransactionTypeIncome, models.TransactionTypeExpense,
+			models.TransactionTypeTransfer, models.TransactionTypeTransfer).
+		Where("date >= ? AND date <= ?", start, end).
+		Group("account_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	summaries := make([]*models.AccountSummary, len(accounts))
+	byID := make(map[uint]*models.AccountSummary, len(accounts))
+	for i, account := range accounts {
+		summaries[i] = &models.AccountSummary{Account: account}
+		byID[account.ID] = summaries[i]
+	}
+
+	var unassigned *models.AccountSummary
+	for _, row := range rows {
+		summary := &models.AccountSummary{}
+		if row.AccountID == nil {
+			unassigned = summary
+		} else if byID[*row.AccountID] != nil {
+			summary = byID[*row.AccountID]
+		} else {
+			// Left behind by an account removed outside Burnwise
+			continue
+		}
+		// SQLite sums in floating point, so the totals are rounded back to cents
+		summary.Income = models.RoundUSD(row.Income)
+		summary.Expenses = models.RoundUSD(math.Max(row.Expenses, 0))
+		summary.TransferredIn = models.RoundUSD(row.TransferredIn)
+		summary.TransferredOut = models.RoundUSD(row.TransferredOut)
+		summary.Count = row.Count
+	}
+	if unassigned != nil {
+		summaries = append(summaries, unassigned)
+	}
+
+	return summaries, nil
+}
@@ -1,6 +1,9 @@
 package repository
 
 import (
+	"context"
+	"fmt"
+	"math"
 	"time"
 
 	"gorm.io/gorm"
@@ -13,63 +16,133 @@ type BudgetRepository struct {
 }
 
 func NewBudgetRepository(db *gorm.DB) *BudgetRepository {
+	registerErrorTranslation(db)
 	return &BudgetRepository{db: db}
 }
 
-func (r *BudgetRepository) Create(budget *models.Budget) error {
-	return r.db.Create(budget).Error
+func (r *BudgetRepository) Create(ctx context.Context, budget *models.Budget) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Categories").Create(budget).Error; err != nil {
+			return err
+		}
+		return replaceBudgetCategories(tx, budget)
+	})
 }
 
-func (r *BudgetRepository) GetByID(id uint) (*models.Budget, error) {
+func (r *BudgetRepository) GetByID(ctx context.Context, id uint) (*models.Budget, error) {
 	var budget models.Budget
-	err := r.db.Preload("Category").First(&budget, id).Error
+	err := r.db.WithContext(ctx).Preload("Category").Preload("Categories").First(&budget, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &budget, nil
 }
 
-func (r *BudgetRepository) Update(budget *models.Budget) error {
-	return r.db.Save(budget).Error
+func (r *BudgetRepository) Update(ctx context.Context, budget *models.Budget) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Categories").Save(budget).Error; err != nil {
+			return err
+		}
+		return replaceBudgetCategories(tx, budget)
+	})
+}
+
+// UpdateWithAmountChange updates a budget and records the change of its
+// amount in one database transaction
+func (r *BudgetRepository) UpdateWithAmountChange(ctx context.Context, budget *models.Budget, change *models.BudgetAmountHistory) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Omit("Categories").Save(budget).Error; err != nil {
+			return err
+		}
+		if err := replaceBudgetCategories(tx, budget); err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
 }
 
-func (r *BudgetRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Budget{}, id).Error
+// GetAmountHistory retrieves the amount changes of a budget, oldest first
+func (r *BudgetRepository) GetAmountHistory(ctx context.Context, budgetID uint) ([]*models.BudgetAmountHistory, error) {
+	var history []*models.BudgetAmountHistory
+	err := r.db.WithContext(ctx).Where("budget_id = ?", budgetID).
+		Order("changed_at ASC, id ASC").
+		Find(&history).Error
+	return history, err
 }
 
-func (r *BudgetRepository) GetAll() ([]*models.Budget, error) {
+// replaceBudgetCategories rewrites the category links of a group budget
+func replaceBudgetCategories(tx *gorm.DB, budget *models.Budget) error {
+	if err := tx.Where("budget_id = ?", budget.ID).Delete(&models.BudgetCategory{}).Error; err != nil {
+		return fmt.Errorf("failed to clear budget categories: %w", err)
+	}
+
+	for _, category := range budget.Categories {
+		link := &models.BudgetCategory{BudgetID: budget.ID, CategoryID: category.ID}
+		if err := tx.Create(link).Error; err != nil {
+			return fmt.Errorf("failed to link budget category: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (r *BudgetRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Budget{}, id).Error
+}
+
+func (r *BudgetRepository) Restore(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&models.Budget{}).
+		Where("id = ?", id).
+		Update("deleted_at", nil).Error
+}
+
+func (r *BudgetRepository) GetAll(ctx context.Context) ([]*models.Budget, error) {
 	var budgets []*models.Budget
-	err := r.db.Preload("Category").Find(&budgets).Error
+	err := r.db.WithContext(ctx).Preload("Category").Preload("Categories").Find(&budgets).Error
 	return budgets, err
 }
 
-func (r *BudgetRepository) GetActive() ([]*models.Budget, error) {
-	now := time.Now()
+func (r *BudgetRepository) GetActive(ctx context.Context) ([]*models.Budget, error) {
+	return r.GetActiveAt(ctx, time.Now())
+}
+
+// GetActiveAt returns the budgets running at the given time
+func (r *BudgetRepository) GetActiveAt(ctx context.Context, at time.Time) ([]*models.Budget, error) {
 	var budgets []*models.Budget
 	
-	err := r.db.Preload("Category").
-		Where("start_date <= ?", now).
-		Where("end_date IS NULL OR end_date >= ?", now).
+	err := r.db.WithContext(ctx).Preload("Category").Preload("Categories").
+		Where("start_date <= ?", at).
+		Where("end_date IS NULL OR end_date >= ?", at).
 		Find(&budgets).Error
 	
 	return budgets, err
 }
 
-func (r *BudgetRepository) GetByCategory(categoryID uint) ([]*models.Budget, error) {
+// GetCategories returns the categories with the given IDs
+func (r *BudgetRepository) GetCategories(ctx context.Context, ids []uint) ([]*models.Category, error) {
+	var categories []*models.Category
+	err := r.db.WithContext(ctx).Where("id IN ?", ids).Find(&categories).Error
+	return categories, err
+}
+
+func (r *BudgetRepository) GetByCategory(ctx context.Context, categoryID uint) ([]*models.Budget, error) {
 	var budgets []*models.Budget
-	err := r.db.Preload("Category").
+	err := r.db.WithContext(ctx).Preload("Category").
 		Where("category_id = ?", categoryID).
 		Order("start_date DESC").
 		Find(&budgets).Error
 	return budgets, err
 }
 
-func (r *BudgetRepository) GetActiveByCategoryAndPeriod(categoryID uint, period models.BudgetPeriod) (*models.Budget, error) {
+// GetActiveByCategoryAndPeriod returns the active single-category budget for
+// a category. Group budgets are not considered.
+func (r *BudgetRepository) GetActiveByCategoryAndPeriod(ctx context.Context, categoryID uint, period models.BudgetPeriod) (*models.Budget, error) {
 	now := time.Now()
 	var budget models.Budget
 	
-	err := r.db.Preload("Category").
+	err := r.db.WithContext(ctx).Preload("Category").
 		Where("category_id = ? AND period = ?", categoryID, period).
+		Where("id NOT IN (SELECT budget_id FROM budget_categories)").
 		Where("start_date <= ?", now).
 		Where("end_date IS NULL OR end_date >= ?", now).
 		First(&budget).Error
@@ -85,8 +158,24 @@ func (r *BudgetRepository) GetActiveByCategoryAndPeriod(categoryID uint, period
 	return &budget, nil
 }
 
-func (r *BudgetRepository) GetByFilter(filter *models.BudgetFilter) ([]*models.Budget, error) {
-	query := r.db.Preload("Category")
+// GetActiveGroupsByPeriod returns the active budgets spanning several
+// categories for a period
+func (r *BudgetRepository) GetActiveGroupsByPeriod(ctx context.Context, period models.BudgetPeriod) ([]*models.Budget, error) {
+	now := time.Now()
+	var budgets []*models.Budget
+	
+	err := r.db.WithContext(ctx).Preload("Category").Preload("Categories").
+		Where("period = ?", period).
+		Where("id IN (SELECT budget_id FROM budget_categories)").
+		Where("start_date <= ?", now).
+		Where("end_date IS NULL OR end_date >= ?", now).
+		Find(&budgets).Error
+	
+	return budgets, err
+}
+
+func (r *BudgetRepository) GetByFilter(ctx context.Context, filter *models.BudgetFilter) ([]*models.Budget, error) {
+	query := r.db.WithContext(ctx).Preload("Category").Preload("Categories")
 
 	if filter.CategoryID != 0 {
 		query = query.Where("category_id = ?", filter.CategoryID)
@@ -115,27 +204,54 @@ func (r *BudgetRepository) GetByFilter(filter *models.BudgetFilter) ([]*models.B
 	return budgets, err
 }
 
-func (r *BudgetRepository) GetSpentAmount(budgetID uint, start, end time.Time) (float64, error) {
+func (r *BudgetRepository) GetSpentAmount(ctx context.Context, budgetID uint, start, end time.Time) (float64, error) {
 	var budget models.Budget
-	if err := r.db.First(&budget, budgetID).Error; err != nil {
+	if err := r.db.WithContext(ctx).Preload("Categories").First(&budget, budgetID).Error; err != nil {
 		return 0, err
 	}
 
+	return r.spentIn(ctx, &budget, start, end)
+}
+
+// GetSpentPerPeriod returns what was spent against the budget in each of
+// periods consecutive periods, oldest first, the first starting at first
+func (r *BudgetRepository) GetSpentPerPeriod(ctx context.Context, budgetID uint, first time.Time, periods int) ([]float64, error) {
+	var budget models.Budget
+	if err := r.db.WithContext(ctx).Preload("Categories").First(&budget, budgetID).Error; err != nil {
+		return nil, err
+	}
+
+	spent := make([]float64, periods)
+	for i := range spent {
+		start := budget.AddPeriods(first, i)
+		end := budget.AddPeriods(start, 1).Add(-time.Second)
+		amount, err := r.spentIn(ctx, &budget, start, end)
+		if err != nil {
+			return nil, err
+		}
+		spent[i] = amount
+	}
+	return spent, nil
+}
+
+func (r *BudgetRepository) spentIn(ctx context.Context, budget *models.Budget, start, end time.Time) (float64, error) {
+	// The lines of split transactions count towards their own categories
 	var spent float64
-	err := r.db.Model(&models.Transaction{}).
-		Select("COALESCE(SUM(amount_usd), 0)").
-		Where("category_id = ? AND type = ? AND date >= ? AND date <= ?", 
-			budget.CategoryID, 
+	err := r.db.WithContext(ctx).Table(categoryLinesSQL+" AS transactions").
+		Select("COALESCE(SUM("+netAmountSQL+"), 0)").
+		Where("transactions.deleted_at IS NULL").
+		Where("category_id IN ? AND type = ? AND date >= ? AND date <= ?", 
+			budget.CategoryIDs(), 
 			models.TransactionTypeExpense,
 			start, 
 			end).
 		Scan(&spent).Error
 
-	return spent, err
+	return math.Max(spent, 0), err
 }
 
-func (r *BudgetRepository) GetAllWithStatus() ([]*models.BudgetStatus, error) {
-	budgets, err := r.GetActive()
+func (r *BudgetRepository) GetAllWithStatus(ctx context.Context) ([]*models.BudgetStatus, error) {
+	budgets, err := r.GetActive(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -149,7 +265,7 @@ func (r *BudgetRepository) GetAllWithStatus() ([]*models.BudgetStatus, error) {
 		periodStart := budget.GetCurrentPeriodStart()
 		periodEnd := budget.GetCurrentPeriodEnd()
 
-		spent, err := r.GetSpentAmount(budget.ID, periodStart, periodEnd)
+		spent, err := r.GetSpentAmount(ctx, budget.ID, periodStart, periodEnd)
 		if err != nil {
 			return nil, err
 		}
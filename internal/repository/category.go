@@ -1,10 +1,14 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"sort"
 	"time"
 	
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"burnwise/internal/models"
 )
@@ -14,33 +18,51 @@ type CategoryRepository struct {
 }
 
 func NewCategoryRepository(db *gorm.DB) *CategoryRepository {
+	registerErrorTranslation(db)
 	return &CategoryRepository{db: db}
 }
 
-func (r *CategoryRepository) Create(category *models.Category) error {
-	return r.db.Create(category).Error
+// Create adds the category, at the end of its type once the categories of
+// that type have been put in a custom order
+func (r *CategoryRepository) Create(ctx context.Context, category *models.Category) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return createCategory(tx, category)
+	})
 }
 
-func (r *CategoryRepository) GetByID(id uint) (*models.Category, error) {
+func createCategory(tx *gorm.DB, category *models.Category) error {
+	if category.SortOrder == 0 {
+		var last int
+		if err := tx.Model(&models.Category{}).Where("type = ?", category.Type).
+			Select("COALESCE(MAX(sort_order), 0)").Scan(&last).Error; err != nil {
+			return fmt.Errorf("failed to find the category order: %w", err)
+		}
+		if last > 0 {
+			category.SortOrder = last + 1
+		}
+	}
+	return tx.Create(category).Error
+}
+
+func (r *CategoryRepository) GetByID(ctx context.Context, id uint) (*models.Category, error) {
 	var category models.Category
-	err := r.db.First(&category, id).Error
+	err := r.db.WithContext(ctx).First(&category, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &category, nil
 }
 
-func (r *CategoryRepository) Update(category *models.Category) error {
-	return r.db.Save(category).Error
+func (r *CategoryRepository) Update(ctx context.Context, category *models.Category) error {
+	return r.db.WithContext(ctx).Save(category).Error
 }
 
-func (r *CategoryRepository) Delete(id uint) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		var count int64
-		if err := tx.Model(&models.Transaction{}).Where("category_id = ?", id).Count(&count).Error; err != nil {
+func (r *CategoryRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		count, err := usageCount(tx, id)
+		if err != nil {
 			return err
 		}
-		
 		if count > 0 {
 			return gorm.ErrRecordNotFound
 		}
@@ -49,30 +71,119 @@ func (r *CategoryRepository) Delete(id uint) error {
 	})
 }
 
-func (r *CategoryRepository) GetAll() ([]*models.Category, error) {
+func (r *CategoryRepository) Restore(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&models.Category{}).
+		Where("id = ?", id).
+		Update("deleted_at", nil).Error
+}
+
+func (r *CategoryRepository) GetAll(ctx context.Context) ([]*models.Category, error) {
+	var categories []*models.Category
+	err := r.db.WithContext(ctx).Order("type ASC, sort_order ASC, name ASC").Find(&categories).Error
+	return categories, err
+}
+
+// SetSortOrder numbers the given categories 1, 2, 3... in the order given
+func (r *CategoryRepository) SetSortOrder(ctx context.Context, ids []uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for i, id := range ids {
+			if err := tx.Model(&models.Category{}).Where("id = ?", id).Update("sort_order", i+1).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// CountAll counts every category ever created, including deleted ones
+func (r *CategoryRepository) CountAll(ctx context.Context) (int64, error) {
+	var count int64
+	err := r.db.WithContext(ctx).Unscoped().Model(&models.Category{}).Count(&count).Error
+	return count, err
+}
+
+// GetByType returns the categories of a type that aren't archived
+func (r *CategoryRepository) GetByType(ctx context.Context, txType models.TransactionType) ([]*models.Category, error) {
 	var categories []*models.Category
-	err := r.db.Order("type ASC, name ASC").Find(&categories).Error
+	err := r.db.WithContext(ctx).Where("type = ? AND is_archived = ?", txType, false).Order("sort_order ASC, name ASC").Find(&categories).Error
 	return categories, err
 }
 
-func (r *CategoryRepository) GetByType(txType models.TransactionType) ([]*models.Category, error) {
+// GetAllByType returns every category of a type, archived or not
+func (r *CategoryRepository) GetAllByType(ctx context.Context, txType models.TransactionType) ([]*models.Category, error) {
 	var categories []*models.Category
-	err := r.db.Where("type = ?", txType).Order("name ASC").Find(&categories).Error
+	err := r.db.WithContext(ctx).Where("type = ?", txType).Order("sort_order ASC, name ASC").Find(&categories).Error
 	return categories, err
 }
 
-func (r *CategoryRepository) GetDefault() ([]*models.Category, error) {
+// GetActive returns the categories of both types that aren't archived
+func (r *CategoryRepository) GetActive(ctx context.Context) ([]*models.Category, error) {
 	var categories []*models.Category
-	err := r.db.Where("is_default = ?", true).Order("type ASC, name ASC").Find(&categories).Error
+	err := r.db.WithContext(ctx).Where("is_archived = ?", false).Order("type ASC, sort_order ASC, name ASC").Find(&categories).Error
 	return categories, err
 }
 
-func (r *CategoryRepository) GetWithTotals(start, end time.Time) ([]*models.CategoryWithTotal, error) {
+// SetPinned pins or unpins a category
+func (r *CategoryRepository) SetPinned(ctx context.Context, id uint, pinned bool) error {
+	result := r.db.WithContext(ctx).Model(&models.Category{}).Where("id = ?", id).Update("is_pinned", pinned)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// SetArchived archives or unarchives a category and records it in the
+// history, in one transaction
+func (r *CategoryRepository) SetArchived(ctx context.Context, id uint, archived bool) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		return setArchived(tx, id, archived)
+	})
+}
+
+// setArchived archives or unarchives the category within a database
+// transaction, recording it in the category's history when it changes
+func setArchived(tx *gorm.DB, id uint, archived bool) error {
+	var category models.Category
+	if err := tx.First(&category, id).Error; err != nil {
+		return err
+	}
+	if category.IsArchived == archived {
+		return nil
+	}
+
+	if err := tx.Model(&category).Update("is_archived", archived).Error; err != nil {
+		return err
+	}
+
+	action := models.CategoryActionArchived
+	if !archived {
+		action = models.CategoryActionUnarchived
+	}
+	history := &models.CategoryHistory{
+		CategoryID: id,
+		Action:     action,
+	}
+	if err := tx.Create(history).Error; err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+	return nil
+}
+
+func (r *CategoryRepository) GetDefault(ctx context.Context) ([]*models.Category, error) {
+	var categories []*models.Category
+	err := r.db.WithContext(ctx).Where("is_default = ?", true).Order("type ASC, sort_order ASC, name ASC").Find(&categories).Error
+	return categories, err
+}
+
+func (r *CategoryRepository) GetWithTotals(ctx context.Context, start, end time.Time) ([]*models.CategoryWithTotal, error) {
 	var results []*models.CategoryWithTotal
 
-	err := r.db.Table("categories").
-		Select("categories.*, COALESCE(SUM(transactions.amount_usd), 0) as total, COUNT(transactions.id) as count").
-		Joins("LEFT JOIN transactions ON categories.id = transactions.category_id AND transactions.date >= ? AND transactions.date <= ? AND transactions.deleted_at IS NULL", start, end).
+	err := r.db.WithContext(ctx).Table("categories").
+		Select("categories.*, COALESCE(SUM("+netAmountSQL+"), 0) as total, COUNT(transactions.id) as count").
+		Joins("LEFT JOIN "+categoryLinesSQL+" AS transactions ON categories.id = transactions.category_id AND transactions.date >= ? AND transactions.date <= ? AND transactions.deleted_at IS NULL", start, end).
 		Where("categories.deleted_at IS NULL").
 		Group("categories.id").
 		Order("categories.type ASC, total DESC").
@@ -84,6 +195,7 @@ func (r *CategoryRepository) GetWithTotals(start, end time.Time) ([]*models.Cate
 
 	totalsByType := make(map[models.TransactionType]float64)
 	for _, result := range results {
+		result.Total = math.Max(result.Total, 0)
 		totalsByType[result.Type] += result.Total
 	}
 
@@ -96,88 +208,364 @@ func (r *CategoryRepository) GetWithTotals(start, end time.Time) ([]*models.Cate
 	return results, nil
 }
 
-func (r *CategoryRepository) FindByName(name string, txType models.TransactionType) (*models.Category, error) {
+func (r *CategoryRepository) FindByName(ctx context.Context, name string, txType models.TransactionType) (*models.Category, error) {
 	var category models.Category
-	err := r.db.Where("name = ? AND type = ?", name, txType).First(&category).Error
+	err := r.db.WithContext(ctx).Where("name = ? AND type = ?", name, txType).First(&category).Error
 	if err != nil {
 		return nil, err
 	}
 	return &category, nil
 }
 
-func (r *CategoryRepository) GetUsageCount(categoryID uint) (int64, error) {
+// GetUsageCount counts the transactions filed under the category, split
+// transactions included when any of their lines is
+func (r *CategoryRepository) GetUsageCount(ctx context.Context, categoryID uint) (int64, error) {
+	return usageCount(r.db.WithContext(ctx), categoryID)
+}
+
+// usageCount is GetUsageCount within db. The lines of a deleted split
+// transaction are kept for restoring it, but no longer count.
+func usageCount(db *gorm.DB, categoryID uint) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Transaction{}).Where("category_id = ?", categoryID).Count(&count).Error
+	err := db.Table(categoryLinesSQL+" AS transactions").
+		Where("transactions.category_id = ? AND transactions.deleted_at IS NULL", categoryID).
+		Distinct("transactions.id").
+		Count(&count).Error
 	return count, err
 }
 
-func (r *CategoryRepository) MergeCategories(sourceID, targetID uint) error {
-	return r.db.Transaction(func(tx *gorm.DB) error {
-		// Verify both categories exist
-		var source, target models.Category
-		if err := tx.First(&source, sourceID).Error; err != nil {
-			return fmt.Errorf("source category not found: %w", err)
-		}
+func (r *CategoryRepository) MergeCategories(ctx context.Context, sourceID, targetID uint) error {
+	return r.MergeMany(ctx, []uint{sourceID}, targetID)
+}
+
+// MergeMany merges several source categories into one target in a single
+// database transaction, writing one history record per source. Budgets and
+// recurring transactions move to the target along with the transactions.
+func (r *CategoryRepository) MergeMany(ctx context.Context, sourceIDs []uint, targetID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var target models.Category
 		if err := tx.First(&target, targetID).Error; err != nil {
 			return fmt.Errorf("target category not found: %w", err)
 		}
+		return mergeMany(tx, sourceIDs, &target)
+	})
+}
 
-		// Count transactions to be migrated
-		var count int64
-		if err := tx.Model(&models.Transaction{}).Where("category_id = ?", sourceID).Count(&count).Error; err != nil {
+// MergeIntoNew creates the target category and merges the source
+// categories into it in the same database transaction, so a failed merge
+// leaves no new category behind
+func (r *CategoryRepository) MergeIntoNew(ctx context.Context, sourceIDs []uint, target *models.Category) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := createCategory(tx, target); err != nil {
 			return err
 		}
+		return mergeMany(tx, sourceIDs, target)
+	})
+}
+
+func mergeMany(tx *gorm.DB, sourceIDs []uint, target *models.Category) error {
+	if err := checkBudgetConflicts(tx, sourceIDs, target); err != nil {
+		return err
+	}
+
+	for _, sourceID := range sourceIDs {
+		if err := mergeInto(tx, sourceID, target); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func mergeInto(tx *gorm.DB, sourceID uint, target *models.Category) error {
+	var source models.Category
+	if err := tx.First(&source, sourceID).Error; err != nil {
+		return fmt.Errorf("source category not found: %w", err)
+	}
+	if source.Type != target.Type {
+		return fmt.Errorf("cannot merge categories of different types (%s -> %s)", source.Type, target.Type)
+	}
+
+	// Recurring transactions generate into their category, so one of
+	// another type would keep generating transactions that don't fit it
+	var mismatched int64
+	if err := tx.Unscoped().Model(&models.RecurringTransaction{}).
+		Where("category_id = ? AND type <> ?", sourceID, target.Type).
+		Count(&mismatched).Error; err != nil {
+		return fmt.Errorf("failed to check recurring transactions: %w", err)
+	}
+	if mismatched > 0 {
+		return fmt.Errorf("cannot merge '%s' into '%s': %d recurring transactions in it are not of type %s",
+			source.Name, target.Name, mismatched, target.Type)
+	}
+
+	// Remember what is moved, so the merge can be undone
+	moved := &models.CategoryMergeMoves{}
+	if err := tx.Model(&models.Transaction{}).Where("category_id = ?", sourceID).Pluck("id", &moved.TransactionIDs).Error; err != nil {
+		return fmt.Errorf("failed to find transactions: %w", err)
+	}
+	if err := tx.Model(&models.SplitItem{}).Where("category_id = ?", sourceID).Pluck("id", &moved.SplitItemIDs).Error; err != nil {
+		return fmt.Errorf("failed to find split lines: %w", err)
+	}
+	// Deleted recurring transactions move too, so undoing their deletion
+	// doesn't bring them back in the merged category
+	if err := tx.Unscoped().Model(&models.RecurringTransaction{}).Where("category_id = ?", sourceID).Pluck("id", &moved.RecurringIDs).Error; err != nil {
+		return fmt.Errorf("failed to find recurring transactions: %w", err)
+	}
+	count := len(moved.TransactionIDs)
+
+	// Update all transactions from source to target category
+	if err := tx.Model(&models.Transaction{}).
+		Where("category_id = ?", sourceID).
+		Update("category_id", target.ID).Error; err != nil {
+		return fmt.Errorf("failed to migrate transactions: %w", err)
+	}
+
+	if err := tx.Model(&models.SplitItem{}).
+		Where("category_id = ?", sourceID).
+		Update("category_id", target.ID).Error; err != nil {
+		return fmt.Errorf("failed to migrate split lines: %w", err)
+	}
+
+	budgetCount, err := migrateBudgets(tx, sourceID, target.ID, moved)
+	if err != nil {
+		return err
+	}
+
+	// UpdateColumn skips the validation hook on the empty model
+	if err := tx.Unscoped().Model(&models.RecurringTransaction{}).
+		Where("category_id = ?", sourceID).
+		UpdateColumn("category_id", target.ID).Error; err != nil {
+		return fmt.Errorf("failed to migrate recurring transactions: %w", err)
+	}
+
+	// Record the merge in history
+	targetID := target.ID
+	history := &models.CategoryHistory{
+		CategoryID:       sourceID,
+		Action:           models.CategoryActionMerged,
+		OldName:          source.Name,
+		TargetCategoryID: &targetID,
+		TransactionCount: count,
+		Notes: fmt.Sprintf("Merged '%s' into '%s' with %d transactions, %d budgets and %d recurring transactions",
+			source.Name, target.Name, count, budgetCount, len(moved.RecurringIDs)),
+		Moved: moved,
+	}
+	if err := tx.Create(history).Error; err != nil {
+		return fmt.Errorf("failed to record history: %w", err)
+	}
+
+	// Delete the source category
+	if err := tx.Delete(&source).Error; err != nil {
+		return fmt.Errorf("failed to delete source category: %w", err)
+	}
+
+	return nil
+}
+
+// checkBudgetConflicts fails if merging would leave the target with two
+// active single-category budgets for the same period, either because the
+// target already has one or because two of the sources do
+func checkBudgetConflicts(tx *gorm.DB, sourceIDs []uint, target *models.Category) error {
+	now := time.Now()
+	var budgets []*models.Budget
+	err := tx.Preload("Category").
+		Where("category_id IN ?", append([]uint{target.ID}, sourceIDs...)).
+		Where("id NOT IN (SELECT budget_id FROM budget_categories)").
+		Where("start_date <= ?", now).
+		Where("end_date IS NULL OR end_date >= ?", now).
+		Find(&budgets).Error
+	if err != nil {
+		return fmt.Errorf("failed to check budgets: %w", err)
+	}
+
+	// Check the target's budgets first, so a clash names the target
+	// before the source
+	sort.SliceStable(budgets, func(i, j int) bool {
+		return budgets[i].CategoryID == target.ID && budgets[j].CategoryID != target.ID
+	})
+
+	claimed := make(map[models.BudgetPeriod]*models.Budget)
+	for _, budget := range budgets {
+		if existing, ok := claimed[budget.Period]; ok {
+			return fmt.Errorf("cannot merge: '%s' and '%s' both have an active %s budget; delete or end one of them first",
+				existing.Category.Name, budget.Category.Name, budget.Period)
+		}
+		claimed[budget.Period] = budget
+	}
+	return nil
+}
 
-		// Update all transactions from source to target category
-		if err := tx.Model(&models.Transaction{}).
-			Where("category_id = ?", sourceID).
-			Update("category_id", targetID).Error; err != nil {
+// migrateBudgets points the budgets covering the source category at the
+// target instead, recording them in moved, and returns how many budgets
+// were changed. Group budgets that already include the target simply drop
+// the source.
+func migrateBudgets(tx *gorm.DB, sourceID, targetID uint, moved *models.CategoryMergeMoves) (int, error) {
+	var budgetIDs []uint
+	if err := tx.Model(&models.Budget{}).
+		Where("category_id = ? OR id IN (SELECT budget_id FROM budget_categories WHERE category_id = ?)", sourceID, sourceID).
+		Pluck("id", &budgetIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to find budgets: %w", err)
+	}
+	if len(budgetIDs) == 0 {
+		return 0, nil
+	}
+
+	if err := tx.Model(&models.Budget{}).Where("category_id = ?", sourceID).Pluck("id", &moved.BudgetIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to find budgets: %w", err)
+	}
+	if err := tx.Model(&models.BudgetCategory{}).
+		Where("category_id = ? AND budget_id IN (SELECT budget_id FROM budget_categories WHERE category_id = ?)", sourceID, targetID).
+		Pluck("budget_id", &moved.DroppedBudgetIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to find budget categories: %w", err)
+	}
+	if err := tx.Model(&models.BudgetCategory{}).
+		Where("category_id = ? AND budget_id NOT IN (SELECT budget_id FROM budget_categories WHERE category_id = ?)", sourceID, targetID).
+		Pluck("budget_id", &moved.LinkedBudgetIDs).Error; err != nil {
+		return 0, fmt.Errorf("failed to find budget categories: %w", err)
+	}
+
+	if err := tx.Model(&models.Budget{}).
+		Where("category_id = ?", sourceID).
+		Update("category_id", targetID).Error; err != nil {
+		return 0, fmt.Errorf("failed to migrate budgets: %w", err)
+	}
+
+	if err := tx.Where("category_id = ? AND budget_id IN (SELECT budget_id FROM budget_categories WHERE category_id = ?)", sourceID, targetID).
+		Delete(&models.BudgetCategory{}).Error; err != nil {
+		return 0, fmt.Errorf("failed to migrate budget categories: %w", err)
+	}
+	if err := tx.Model(&models.BudgetCategory{}).
+		Where("category_id = ?", sourceID).
+		Update("category_id", targetID).Error; err != nil {
+		return 0, fmt.Errorf("failed to migrate budget categories: %w", err)
+	}
+
+	return len(budgetIDs), nil
+}
+
+// UnmergeCategories restores a merged source category and moves back what
+// the latest merge of it into the target moved, as recorded in its history.
+// Anything that has since left the target stays where it is.
+func (r *CategoryRepository) UnmergeCategories(ctx context.Context, sourceID, targetID uint) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var merge models.CategoryHistory
+		if err := tx.Where("category_id = ? AND target_category_id = ? AND action = ?", sourceID, targetID, models.CategoryActionMerged).
+			Order("id DESC").First(&merge).Error; err != nil {
+			return fmt.Errorf("merge not found: %w", err)
+		}
+		moved := merge.Moved
+		if moved == nil {
+			moved = &models.CategoryMergeMoves{}
+		}
+
+		if err := tx.Unscoped().Model(&models.Category{}).
+			Where("id = ?", sourceID).
+			Update("deleted_at", nil).Error; err != nil {
+			return fmt.Errorf("failed to restore source category: %w", err)
+		}
+
+		var source models.Category
+		if err := tx.First(&source, sourceID).Error; err != nil {
+			return fmt.Errorf("source category not found: %w", err)
+		}
+
+		if err := moveBack(tx.Model(&models.Transaction{}), moved.TransactionIDs, "id", sourceID, targetID); err != nil {
 			return fmt.Errorf("failed to migrate transactions: %w", err)
 		}
+		if err := moveBack(tx.Model(&models.SplitItem{}), moved.SplitItemIDs, "id", sourceID, targetID); err != nil {
+			return fmt.Errorf("failed to migrate split lines: %w", err)
+		}
+		if err := moveBack(tx.Model(&models.Budget{}), moved.BudgetIDs, "id", sourceID, targetID); err != nil {
+			return fmt.Errorf("failed to migrate budgets: %w", err)
+		}
+		if err := moveBack(tx.Model(&models.BudgetCategory{}), moved.LinkedBudgetIDs, "budget_id", sourceID, targetID); err != nil {
+			return fmt.Errorf("failed to migrate budget categories: %w", err)
+		}
+		for _, budgetID := range moved.DroppedBudgetIDs {
+			link := &models.BudgetCategory{BudgetID: budgetID, CategoryID: sourceID}
+			if err := tx.Clauses(clause.OnConflict{DoNothing: true}).Create(link).Error; err != nil {
+				return fmt.Errorf("failed to migrate budget categories: %w", err)
+			}
+		}
+		if err := moveBack(tx.Unscoped().Model(&models.RecurringTransaction{}), moved.RecurringIDs, "id", sourceID, targetID); err != nil {
+			return fmt.Errorf("failed to migrate recurring transactions: %w", err)
+		}
 
-		// Record the merge in history
+		count := len(moved.TransactionIDs)
 		history := &models.CategoryHistory{
 			CategoryID:       sourceID,
-			Action:           models.CategoryActionMerged,
-			OldName:          source.Name,
+			Action:           models.CategoryActionUnmerged,
+			NewName:          source.Name,
 			TargetCategoryID: &targetID,
-			TransactionCount: int(count),
-			Notes:            fmt.Sprintf("Merged '%s' into '%s' with %d transactions", source.Name, target.Name, count),
+			TransactionCount: count,
+			Notes:            fmt.Sprintf("Restored '%s' with %d transactions", source.Name, count),
 		}
 		if err := tx.Create(history).Error; err != nil {
 			return fmt.Errorf("failed to record history: %w", err)
 		}
 
-		// Delete the source category
-		if err := tx.Delete(&source).Error; err != nil {
-			return fmt.Errorf("failed to delete source category: %w", err)
-		}
-
 		return nil
 	})
 }
 
-func (r *CategoryRepository) CreateHistory(history *models.CategoryHistory) error {
-	return r.db.Create(history).Error
+// moveBack points the rows of query with the given IDs in column that are
+// still in the target category back at the source. UpdateColumn skips the
+// validation hooks on the empty model.
+func moveBack(query *gorm.DB, ids []uint, column string, sourceID, targetID uint) error {
+	if len(ids) == 0 {
+		return nil
+	}
+	return query.Where(column+" IN ? AND category_id = ?", ids, targetID).
+		UpdateColumn("category_id", sourceID).Error
+}
+
+func (r *CategoryRepository) CreateHistory(ctx context.Context, history *models.CategoryHistory) error {
+	return r.db.WithContext(ctx).Create(history).Error
 }
 
-func (r *CategoryRepository) GetHistory(categoryID uint) ([]*models.CategoryHistory, error) {
+func (r *CategoryRepository) GetHistory(ctx context.Context, categoryID uint) ([]*models.CategoryHistory, error) {
 	var history []*models.CategoryHistory
-	err := r.db.Where("category_id = ?", categoryID).
+	err := r.db.WithContext(ctx).Where("category_id = ?", categoryID).
 		Order("created_at DESC").
 		Find(&history).Error
 	return history, err
 }
 
-func (r *CategoryRepository) GetAllWithUsageCount() ([]*models.CategoryWithTotal, error) {
+// GetHistoryLog returns the history of a category in chronological order,
+// including merges into it, with both categories loaded even when deleted
+func (r *CategoryRepository) GetHistoryLog(ctx context.Context, categoryID uint) ([]*models.CategoryHistory, error) {
+	return r.historyLog(r.db.WithContext(ctx).Where("category_id = ? OR target_category_id = ?", categoryID, categoryID))
+}
+
+// GetAllHistory returns the history of every category in chronological order
+func (r *CategoryRepository) GetAllHistory(ctx context.Context) ([]*models.CategoryHistory, error) {
+	return r.historyLog(r.db.WithContext(ctx))
+}
+
+func (r *CategoryRepository) historyLog(query *gorm.DB) ([]*models.CategoryHistory, error) {
+	var history []*models.CategoryHistory
+	unscoped := func(db *gorm.DB) *gorm.DB { return db.Unscoped() }
+	err := query.Preload("Category", unscoped).
+		Preload("TargetCategory", unscoped).
+		Order("created_at ASC, id ASC").
+		Find(&history).Error
+	return history, err
+}
+
+// GetAllWithUsageCount returns every category with its transaction count and
+// lifetime total in USD. Refunds are netted against the expenses they file
+// under and split transactions count under their lines' categories, as in
+// the reports.
+func (r *CategoryRepository) GetAllWithUsageCount(ctx context.Context) ([]*models.CategoryWithTotal, error) {
 	var results []*models.CategoryWithTotal
 
-	err := r.db.Table("categories").
-		Select("categories.*, COUNT(transactions.id) as count").
-		Joins("LEFT JOIN transactions ON categories.id = transactions.category_id AND transactions.deleted_at IS NULL").
+	err := r.db.WithContext(ctx).Table("categories").
+		Select("categories.*, COUNT(DISTINCT transactions.id) as count, COALESCE(SUM("+netAmountSQL+"), 0) as total").
+		Joins("LEFT JOIN "+categoryLinesSQL+" AS transactions ON categories.id = transactions.category_id AND transactions.deleted_at IS NULL").
 		Where("categories.deleted_at IS NULL").
 		Group("categories.id").
-		Order("categories.type ASC, categories.name ASC").
+		Order("categories.type ASC, categories.sort_order ASC, categories.name ASC").
 		Scan(&results).Error
 
 	return results, err
@@ -0,0 +1,48 @@
+package repository
+
+import (
+	"errors"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// ErrDatabaseBusy is returned for a write that failed because another
+// connection, such as a second Burnwise, held the database lock for longer
+// than the busy timeout
+var ErrDatabaseBusy = errors.New("database busy, retry")
+
+// ErrReadOnly is returned for a write to a database opened read-only
+var ErrReadOnly = errors.New("database is open read-only, changes can't be saved")
+
+const translateErrorsCallback = "burnwise:translate_errors"
+
+// registerErrorTranslation makes writes through db return ErrDatabaseBusy
+// and ErrReadOnly instead of the driver's errors. Every repository calls it
+// on the connection it is given; only the first call registers anything.
+func registerErrorTranslation(db *gorm.DB) {
+	callbacks := db.Callback()
+	if callbacks.Create().Get(translateErrorsCallback) != nil {
+		return
+	}
+	callbacks.Create().After("*").Register(translateErrorsCallback, translateErrors)
+	callbacks.Update().After("*").Register(translateErrorsCallback, translateErrors)
+	callbacks.Delete().After("*").Register(translateErrorsCallback, translateErrors)
+	callbacks.Raw().After("*").Register(translateErrorsCallback, translateErrors)
+}
+
+// translateErrors matches the driver's error messages rather than its error
+// codes, so the package builds without cgo too
+func translateErrors(db *gorm.DB) {
+	if db.Error == nil {
+		return
+	}
+
+	message := db.Error.Error()
+	switch {
+	case strings.Contains(message, "database is locked"), strings.Contains(message, "database table is locked"):
+		db.Error = ErrDatabaseBusy
+	case strings.Contains(message, "attempt to write a readonly database"):
+		db.Error = ErrReadOnly
+	}
+}
@@ -1,6 +1,7 @@
 package repository
 
 import (
+	"context"
 	"time"
 
 	"gorm.io/gorm"
@@ -13,18 +14,19 @@ type RecurringTransactionRepository struct {
 }
 
 func NewRecurringTransactionRepository(db *gorm.DB) *RecurringTransactionRepository {
+	registerErrorTranslation(db)
 	return &RecurringTransactionRepository{db: db}
 }
 
 // Create creates a new recurring transaction
-func (r *RecurringTransactionRepository) Create(rt *models.RecurringTransaction) error {
-	return r.db.Create(rt).Error
+func (r *RecurringTransactionRepository) Create(ctx context.Context, rt *models.RecurringTransaction) error {
+	return r.db.WithContext(ctx).Create(rt).Error
 }
 
 // GetByID retrieves a recurring transaction by ID
-func (r *RecurringTransactionRepository) GetByID(id uint) (*models.RecurringTransaction, error) {
+func (r *RecurringTransactionRepository) GetByID(ctx context.Context, id uint) (*models.RecurringTransaction, error) {
 	var rt models.RecurringTransaction
-	err := r.db.Preload("Category").First(&rt, id).Error
+	err := r.db.WithContext(ctx).Preload("Category").First(&rt, id).Error
 	if err != nil {
 		return nil, err
 	}
@@ -32,26 +34,55 @@ func (r *RecurringTransactionRepository) GetByID(id uint) (*models.RecurringTran
 }
 
 // Update updates a recurring transaction
-func (r *RecurringTransactionRepository) Update(rt *models.RecurringTransaction) error {
-	return r.db.Save(rt).Error
+func (r *RecurringTransactionRepository) Update(ctx context.Context, rt *models.RecurringTransaction) error {
+	return r.db.WithContext(ctx).Save(rt).Error
+}
+
+// UpdateWithPriceChange updates a recurring transaction and records the
+// change of its amount in one database transaction
+func (r *RecurringTransactionRepository) UpdateWithPriceChange(ctx context.Context, rt *models.RecurringTransaction, change *models.RecurringTransactionPriceHistory) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Save(rt).Error; err != nil {
+			return err
+		}
+		return tx.Create(change).Error
+	})
+}
+
+// GetPriceHistory retrieves the amount changes of a recurring transaction,
+// oldest first
+func (r *RecurringTransactionRepository) GetPriceHistory(ctx context.Context, recurringTransactionID uint) ([]*models.RecurringTransactionPriceHistory, error) {
+	var history []*models.RecurringTransactionPriceHistory
+	err := r.db.WithContext(ctx).Where("recurring_transaction_id = ?", recurringTransactionID).
+		Order("changed_at ASC, id ASC").
+		Find(&history).Error
+	return history, err
 }
 
 // Delete soft deletes a recurring transaction
-func (r *RecurringTransactionRepository) Delete(id uint) error {
-	return r.db.Delete(&models.RecurringTransaction{}, id).Error
+func (r *RecurringTransactionRepository) Delete(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Delete(&models.RecurringTransaction{}, id).Error
+}
+
+// Restore reverses a soft delete of a recurring transaction. UpdateColumn
+// skips the validation hook on the empty model.
+func (r *RecurringTransactionRepository) Restore(ctx context.Context, id uint) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&models.RecurringTransaction{}).
+		Where("id = ?", id).
+		UpdateColumn("deleted_at", nil).Error
 }
 
 // GetAll retrieves all recurring transactions
-func (r *RecurringTransactionRepository) GetAll() ([]*models.RecurringTransaction, error) {
+func (r *RecurringTransactionRepository) GetAll(ctx context.Context) ([]*models.RecurringTransaction, error) {
 	var rts []*models.RecurringTransaction
-	err := r.db.Preload("Category").Order("next_due_date ASC").Find(&rts).Error
+	err := r.db.WithContext(ctx).Preload("Category").Order("next_due_date ASC").Find(&rts).Error
 	return rts, err
 }
 
 // GetActive retrieves all active recurring transactions
-func (r *RecurringTransactionRepository) GetActive() ([]*models.RecurringTransaction, error) {
+func (r *RecurringTransactionRepository) GetActive(ctx context.Context) ([]*models.RecurringTransaction, error) {
 	var rts []*models.RecurringTransaction
-	err := r.db.Preload("Category").
+	err := r.db.WithContext(ctx).Preload("Category").
 		Where("is_active = ?", true).
 		Order("next_due_date ASC").
 		Find(&rts).Error
@@ -59,9 +90,9 @@ func (r *RecurringTransactionRepository) GetActive() ([]*models.RecurringTransac
 }
 
 // GetDue retrieves all recurring transactions due by a specific date
-func (r *RecurringTransactionRepository) GetDue(asOf time.Time) ([]*models.RecurringTransaction, error) {
+func (r *RecurringTransactionRepository) GetDue(ctx context.Context, asOf time.Time) ([]*models.RecurringTransaction, error) {
 	var rts []*models.RecurringTransaction
-	err := r.db.Preload("Category").
+	err := r.db.WithContext(ctx).Preload("Category").
 		Where("is_active = ? AND next_due_date <= ?", true, asOf).
 		Where("end_date IS NULL OR end_date >= ?", asOf).
 		Order("next_due_date ASC").
@@ -70,9 +101,9 @@ func (r *RecurringTransactionRepository) GetDue(asOf time.Time) ([]*models.Recur
 }
 
 // GetByCategory retrieves all recurring transactions for a specific category
-func (r *RecurringTransactionRepository) GetByCategory(categoryID uint) ([]*models.RecurringTransaction, error) {
+func (r *RecurringTransactionRepository) GetByCategory(ctx context.Context, categoryID uint) ([]*models.RecurringTransaction, error) {
 	var rts []*models.RecurringTransaction
-	err := r.db.Preload("Category").
+	err := r.db.WithContext(ctx).Preload("Category").
 		Where("category_id = ?", categoryID).
 		Order("next_due_date ASC").
 		Find(&rts).Error
@@ -80,17 +111,32 @@ func (r *RecurringTransactionRepository) GetByCategory(categoryID uint) ([]*mode
 }
 
 // UpdateNextDueDate updates the next due date for a recurring transaction
-func (r *RecurringTransactionRepository) UpdateNextDueDate(id uint, nextDueDate time.Time) error {
+func (r *RecurringTransactionRepository) UpdateNextDueDate(ctx context.Context, id uint, nextDueDate time.Time) error {
 	// Use UpdateColumn to skip hooks
-	return r.db.Model(&models.RecurringTransaction{}).
+	return r.db.WithContext(ctx).Model(&models.RecurringTransaction{}).
 		Where("id = ?", id).
 		UpdateColumn("next_due_date", nextDueDate).Error
 }
 
+// UpdateEndDate sets or, when endDate is nil, clears the end date of a
+// recurring transaction
+func (r *RecurringTransactionRepository) UpdateEndDate(ctx context.Context, id uint, endDate *time.Time) error {
+	result := r.db.WithContext(ctx).Model(&models.RecurringTransaction{}).
+		Where("id = ?", id).
+		UpdateColumn("end_date", endDate)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
 // UpdateLastProcessed updates the last processed date for a recurring transaction
-func (r *RecurringTransactionRepository) UpdateLastProcessed(id uint, lastProcessed time.Time) error {
+func (r *RecurringTransactionRepository) UpdateLastProcessed(ctx context.Context, id uint, lastProcessed time.Time) error {
 	// Use UpdateColumns to skip hooks
-	return r.db.Model(&models.RecurringTransaction{}).
+	return r.db.WithContext(ctx).Model(&models.RecurringTransaction{}).
 		Where("id = ?", id).
 		UpdateColumns(map[string]interface{}{
 			"last_processed": lastProcessed,
@@ -99,30 +145,30 @@ func (r *RecurringTransactionRepository) UpdateLastProcessed(id uint, lastProces
 }
 
 // Deactivate deactivates a recurring transaction
-func (r *RecurringTransactionRepository) Deactivate(id uint) error {
+func (r *RecurringTransactionRepository) Deactivate(ctx context.Context, id uint) error {
 	// Use UpdateColumn to skip hooks
-	return r.db.Model(&models.RecurringTransaction{}).
+	return r.db.WithContext(ctx).Model(&models.RecurringTransaction{}).
 		Where("id = ?", id).
 		UpdateColumn("is_active", false).Error
 }
 
 // Activate activates a recurring transaction
-func (r *RecurringTransactionRepository) Activate(id uint) error {
+func (r *RecurringTransactionRepository) Activate(ctx context.Context, id uint) error {
 	// Use UpdateColumn to skip hooks
-	return r.db.Model(&models.RecurringTransaction{}).
+	return r.db.WithContext(ctx).Model(&models.RecurringTransaction{}).
 		Where("id = ?", id).
 		UpdateColumn("is_active", true).Error
 }
 
 // CreateOccurrence creates a recurring transaction occurrence record
-func (r *RecurringTransactionRepository) CreateOccurrence(occurrence *models.RecurringTransactionOccurrence) error {
-	return r.db.Create(occurrence).Error
+func (r *RecurringTransactionRepository) CreateOccurrence(ctx context.Context, occurrence *models.RecurringTransactionOccurrence) error {
+	return r.db.WithContext(ctx).Create(occurrence).Error
 }
 
 // GetOccurrence retrieves an occurrence for a specific date
-func (r *RecurringTransactionRepository) GetOccurrence(recurringTransactionID uint, date time.Time) (*models.RecurringTransactionOccurrence, error) {
+func (r *RecurringTransactionRepository) GetOccurrence(ctx context.Context, recurringTransactionID uint, date time.Time) (*models.RecurringTransactionOccurrence, error) {
 	var occurrence models.RecurringTransactionOccurrence
-	err := r.db.Where("recurring_transaction_id = ? AND DATE(occurrence_date) = DATE(?)", 
+	err := r.db.WithContext(ctx).Where("recurring_transaction_id = ? AND DATE(occurrence_date) = DATE(?)", 
 		recurringTransactionID, date).
 		First(&occurrence).Error
 	
@@ -133,36 +179,103 @@ func (r *RecurringTransactionRepository) GetOccurrence(recurringTransactionID ui
 }
 
 // GetOccurrences retrieves all occurrences for a recurring transaction
-func (r *RecurringTransactionRepository) GetOccurrences(recurringTransactionID uint) ([]*models.RecurringTransactionOccurrence, error) {
+func (r *RecurringTransactionRepository) GetOccurrences(ctx context.Context, recurringTransactionID uint) ([]*models.RecurringTransactionOccurrence, error) {
 	var occurrences []*models.RecurringTransactionOccurrence
-	err := r.db.Where("recurring_transaction_id = ?", recurringTransactionID).
+	err := r.db.WithContext(ctx).Where("recurring_transaction_id = ?", recurringTransactionID).
 		Order("occurrence_date DESC").
 		Find(&occurrences).Error
 	return occurrences, err
 }
 
 // GetGeneratedTransactions retrieves all transactions generated from a recurring transaction
-func (r *RecurringTransactionRepository) GetGeneratedTransactions(recurringTransactionID uint) ([]*models.Transaction, error) {
+func (r *RecurringTransactionRepository) GetGeneratedTransactions(ctx context.Context, recurringTransactionID uint) ([]*models.Transaction, error) {
 	var transactions []*models.Transaction
-	err := r.db.Where("recurring_transaction_id = ?", recurringTransactionID).
+	err := r.db.WithContext(ctx).Where("recurring_transaction_id = ?", recurringTransactionID).
 		Order("date DESC").
 		Find(&transactions).Error
 	return transactions, err
 }
 
+// GetGeneratedSince retrieves the transactions generated from any recurring
+// transaction since the given time, oldest first
+func (r *RecurringTransactionRepository) GetGeneratedSince(ctx context.Context, since time.Time) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	err := r.db.WithContext(ctx).Preload("Category").
+		Where("recurring_transaction_id IS NOT NULL AND created_at >= ?", since).
+		Order("date ASC").
+		Find(&transactions).Error
+	return transactions, err
+}
+
 // CountGeneratedTransactions counts transactions generated from a recurring transaction
-func (r *RecurringTransactionRepository) CountGeneratedTransactions(recurringTransactionID uint) (int64, error) {
+func (r *RecurringTransactionRepository) CountGeneratedTransactions(ctx context.Context, recurringTransactionID uint) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Transaction{}).
+	err := r.db.WithContext(ctx).Model(&models.Transaction{}).
 		Where("recurring_transaction_id = ?", recurringTransactionID).
 		Count(&count).Error
 	return count, err
 }
 
+// CountGeneratedByRecurring counts the transactions generated from each
+// recurring transaction in one query, keyed by recurring transaction ID.
+// Recurring transactions that haven't generated any are left out.
+func (r *RecurringTransactionRepository) CountGeneratedByRecurring(ctx context.Context) (map[uint]int64, error) {
+	var rows []struct {
+		RecurringTransactionID uint
+		Count                  int64
+	}
+	err := r.db.WithContext(ctx).Model(&models.Transaction{}).
+		Select("recurring_transaction_id, COUNT(*) as count").
+		Where("recurring_transaction_id IS NOT NULL").
+		Group("recurring_transaction_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	counts := make(map[uint]int64, len(rows))
+	for _, row := range rows {
+		counts[row.RecurringTransactionID] = row.Count
+	}
+	return counts, nil
+}
+
+// GetLatestGeneratedRates returns, for each recurring transaction that has
+// generated any transactions in its current currency, the rate of the
+// latest one as USD per unit of that currency, keyed by recurring
+// transaction ID
+func (r *RecurringTransactionRepository) GetLatestGeneratedRates(ctx context.Context) (map[uint]float64, error) {
+	var rows []struct {
+		RecurringTransactionID uint
+		Rate                   float64
+	}
+	err := r.db.WithContext(ctx).Raw(`
+		SELECT t.recurring_transaction_id, t.amount_usd / t.amount AS rate
+		FROM transactions t
+		WHERE t.id = (
+			SELECT latest.id FROM transactions latest
+			JOIN recurring_transactions rt ON rt.id = latest.recurring_transaction_id
+			WHERE latest.recurring_transaction_id = t.recurring_transaction_id
+				AND latest.currency = rt.currency
+				AND latest.deleted_at IS NULL AND latest.amount > 0 AND latest.amount_usd > 0
+			ORDER BY latest.date DESC, latest.id DESC
+			LIMIT 1
+		)`).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	rates := make(map[uint]float64, len(rows))
+	for _, row := range rows {
+		rates[row.RecurringTransactionID] = row.Rate
+	}
+	return rates, nil
+}
+
 // GetExpiring retrieves recurring transactions expiring within a date range
-func (r *RecurringTransactionRepository) GetExpiring(start, end time.Time) ([]*models.RecurringTransaction, error) {
+func (r *RecurringTransactionRepository) GetExpiring(ctx context.Context, start, end time.Time) ([]*models.RecurringTransaction, error) {
 	var rts []*models.RecurringTransaction
-	err := r.db.Preload("Category").
+	err := r.db.WithContext(ctx).Preload("Category").
 		Where("is_active = ? AND end_date IS NOT NULL AND end_date BETWEEN ? AND ?", 
 			true, start, end).
 		Order("end_date ASC").
@@ -1,84 +1,244 @@
 package repository
 
 import (
+	"context"
 	"fmt"
+	"math"
 	"time"
 
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 
 	"burnwise/internal/models"
 )
 
+// netAmountSQL sums transaction amounts with refunds netted against expenses
+const netAmountSQL = "CASE WHEN transactions.is_refund THEN -transactions.amount_usd ELSE transactions.amount_usd END"
+
+// categoryLinesSQL lists what each transaction counts towards by
+// category: the lines of a split transaction, or the transaction itself.
+// Its columns are named like the transactions table's, so aliased as
+// transactions it can stand in for the table in category totals.
+const categoryLinesSQL = "(SELECT t.id, t.type, t.date, t.is_refund, t.recurring_transaction_id, t.deleted_at, " +
+	"COALESCE(s.category_id, t.category_id) AS category_id, COALESCE(s.amount_usd, t.amount_usd) AS amount_usd " +
+	"FROM transactions t LEFT JOIN split_items s ON s.transaction_id = t.id)"
+
+// recurringSumSQL sums amount over the transactions generated by a recurring rule
+func recurringSumSQL(amount string) string {
+	return "COALESCE(SUM(CASE WHEN transactions.recurring_transaction_id IS NOT NULL THEN " + amount + " ELSE 0 END), 0)"
+}
+
 type TransactionRepository struct {
 	db *gorm.DB
 }
 
 func NewTransactionRepository(db *gorm.DB) *TransactionRepository {
+	registerErrorTranslation(db)
 	return &TransactionRepository{db: db}
 }
 
-func (r *TransactionRepository) Create(tx *models.Transaction) error {
-	return r.db.Create(tx).Error
+func (r *TransactionRepository) Create(ctx context.Context, tx *models.Transaction) error {
+	return r.db.WithContext(ctx).Create(tx).Error
+}
+
+// CreateMany inserts all transactions in a single database transaction, so
+// either every one is stored or none are. Associations such as Category are
+// never written, but archived categories the transactions go into are
+// unarchived in the same database transaction, as they are in use again.
+func (r *TransactionRepository) CreateMany(ctx context.Context, txs []*models.Transaction) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		seen := make(map[uint]bool)
+		for _, t := range txs {
+			if err := tx.Omit(clause.Associations).Create(t).Error; err != nil {
+				return err
+			}
+			if seen[t.CategoryID] {
+				continue
+			}
+			seen[t.CategoryID] = true
+			if err := setArchived(tx, t.CategoryID, false); err != nil {
+				return fmt.Errorf("failed to unarchive category #%d: %w", t.CategoryID, err)
+			}
+		}
+		return nil
+	})
+}
+
+// CreateTransfer stores both halves of a transfer in a single database
+// transaction and links each to the other
+func (r *TransactionRepository) CreateTransfer(ctx context.Context, out, in *models.Transaction) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		for _, t := range []*models.Transaction{out, in} {
+			if err := tx.Omit(clause.Associations).Create(t).Error; err != nil {
+				return err
+			}
+		}
+		out.TransferPairID = &in.ID
+		in.TransferPairID = &out.ID
+		for _, t := range []*models.Transaction{out, in} {
+			if err := tx.Model(t).Update("transfer_pair_id", t.TransferPairID).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
 }
 
-func (r *TransactionRepository) GetByID(id uint) (*models.Transaction, error) {
+func (r *TransactionRepository) GetByID(ctx context.Context, id uint) (*models.Transaction, error) {
 	var tx models.Transaction
-	err := r.db.Preload("Category").First(&tx, id).Error
+	err := r.db.WithContext(ctx).Preload("Category").Preload("RefundOf.Category").Preload("Account").Preload("Attachments").Preload("Splits.Category").First(&tx, id).Error
 	if err != nil {
 		return nil, err
 	}
 	return &tx, nil
 }
 
-func (r *TransactionRepository) Update(tx *models.Transaction) error {
-	return r.db.Save(tx).Error
+// Update saves the transaction. Its attachments are added and removed on
+// their own, so the ones loaded with it are left alone. Its split lines are
+// replaced by Splits, unless that is nil. The loaded Category is left out
+// too, since saving it would put CategoryID back to the category's ID.
+func (r *TransactionRepository) Update(ctx context.Context, tx *models.Transaction) error {
+	return r.db.WithContext(ctx).Transaction(func(db *gorm.DB) error {
+		if err := db.Omit("Category", "Attachments", "Splits").Save(tx).Error; err != nil {
+			return err
+		}
+		if tx.Splits == nil {
+			return nil
+		}
+		if err := db.Where("transaction_id = ?", tx.ID).Delete(&models.SplitItem{}).Error; err != nil {
+			return err
+		}
+		for i := range tx.Splits {
+			line := &tx.Splits[i]
+			line.ID = 0
+			line.TransactionID = tx.ID
+			if err := db.Omit(clause.Associations).Create(line).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetSplits returns the lines of the split transaction with the given ID,
+// in the order they were entered
+func (r *TransactionRepository) GetSplits(ctx context.Context, transactionID uint) ([]models.SplitItem, error) {
+	var lines []models.SplitItem
+	err := r.db.WithContext(ctx).Preload("Category").
+		Where("transaction_id = ?", transactionID).
+		Order("id ASC").
+		Find(&lines).Error
+	return lines, err
+}
+
+// Delete removes the transactions with the given IDs at once, such as both
+// halves of a transfer
+func (r *TransactionRepository) Delete(ctx context.Context, ids ...uint) error {
+	return r.db.WithContext(ctx).Delete(&models.Transaction{}, ids).Error
+}
+
+func (r *TransactionRepository) Restore(ctx context.Context, ids ...uint) error {
+	return r.db.WithContext(ctx).Unscoped().Model(&models.Transaction{}).
+		Where("id IN ?", ids).
+		Update("deleted_at", nil).Error
 }
 
-func (r *TransactionRepository) Delete(id uint) error {
-	return r.db.Delete(&models.Transaction{}, id).Error
+// GetRefunds returns the refunds linked to the transaction with the given
+// ID, oldest first
+func (r *TransactionRepository) GetRefunds(ctx context.Context, originalID uint) ([]*models.Transaction, error) {
+	var refunds []*models.Transaction
+	err := r.db.WithContext(ctx).Where("refund_of_id = ? AND is_refund = ?", originalID, true).
+		Order("date ASC").
+		Find(&refunds).Error
+	return refunds, err
 }
 
-func (r *TransactionRepository) GetAll() ([]*models.Transaction, error) {
+// FindRefundable returns up to limit expenses that aren't refunds
+// themselves, newest first: those whose description contains search or,
+// given an amount, whose amount or USD amount is within half a cent of it.
+// A limit of 0 returns them all.
+func (r *TransactionRepository) FindRefundable(ctx context.Context, search string, amount *float64, limit int) ([]*models.Transaction, error) {
+	query := r.db.WithContext(ctx).Preload("Category").
+		Where("type = ? AND is_refund = ?", models.TransactionTypeExpense, false)
+	if amount != nil {
+		query = query.Where("ABS(amount - ?) < 0.005 OR ABS(amount_usd - ?) < 0.005", *amount, *amount)
+	} else if search != "" {
+		query = query.Where("description LIKE ?", fmt.Sprintf("%%%s%%", search))
+	}
+	if limit > 0 {
+		query = query.Limit(limit)
+	}
+
 	var transactions []*models.Transaction
-	err := r.db.Preload("Category").Order("date DESC").Find(&transactions).Error
+	err := query.Order("date DESC, id DESC").Find(&transactions).Error
 	return transactions, err
 }
 
-func (r *TransactionRepository) GetByDateRange(start, end time.Time) ([]*models.Transaction, error) {
+// GetCategory returns the category with the given ID
+func (r *TransactionRepository) GetCategory(ctx context.Context, categoryID uint) (*models.Category, error) {
+	var category models.Category
+	if err := r.db.WithContext(ctx).First(&category, categoryID).Error; err != nil {
+		return nil, err
+	}
+	return &category, nil
+}
+
+// GetAccount returns the account with the given ID
+func (r *TransactionRepository) GetAccount(ctx context.Context, accountID uint) (*models.Account, error) {
+	var account models.Account
+	if err := r.db.WithContext(ctx).First(&account, accountID).Error; err != nil {
+		return nil, err
+	}
+	return &account, nil
+}
+
+func (r *TransactionRepository) GetAll(ctx context.Context) ([]*models.Transaction, error) {
 	var transactions []*models.Transaction
-	err := r.db.Preload("Category").
+	err := r.db.WithContext(ctx).Preload("Category").Order("date DESC").Find(&transactions).Error
+	return transactions, err
+}
+
+func (r *TransactionRepository) GetByDateRange(ctx context.Context, start, end time.Time) ([]*models.Transaction, error) {
+	var transactions []*models.Transaction
+	err := r.db.WithContext(ctx).Preload("Category").
 		Where("date >= ? AND date <= ?", start, end).
 		Order("date DESC").
 		Find(&transactions).Error
 	return transactions, err
 }
 
-func (r *TransactionRepository) GetByCategory(categoryID uint) ([]*models.Transaction, error) {
+func (r *TransactionRepository) GetByCategory(ctx context.Context, categoryID uint) ([]*models.Transaction, error) {
 	var transactions []*models.Transaction
-	err := r.db.Preload("Category").
+	err := r.db.WithContext(ctx).Preload("Category").
 		Where("category_id = ?", categoryID).
 		Order("date DESC").
 		Find(&transactions).Error
 	return transactions, err
 }
 
-func (r *TransactionRepository) GetByFilter(filter *models.TransactionFilter) ([]*models.Transaction, error) {
-	query := r.db.Preload("Category")
+func (r *TransactionRepository) GetByFilter(ctx context.Context, filter *models.TransactionFilter) ([]*models.Transaction, error) {
+	query := r.db.WithContext(ctx).Preload("Category").Preload("RefundOf.Category").Preload("Account").Preload("Attachments").Preload("Splits.Category")
 
 	if filter.Type != "" {
 		query = query.Where("type = ?", filter.Type)
 	}
 
 	if filter.CategoryID != 0 {
-		query = query.Where("category_id = ?", filter.CategoryID)
+		// Split transactions are found by any of their lines' categories
+		query = query.Where("category_id = ? OR id IN (SELECT transaction_id FROM split_items WHERE category_id = ?)",
+			filter.CategoryID, filter.CategoryID)
 	}
 
-	if !filter.StartDate.IsZero() {
-		query = query.Where("date >= ?", filter.StartDate)
-	}
+	if filter.OutsideDates {
+		query = query.Where("date < ? OR date > ?", filter.StartDate, filter.EndDate)
+	} else {
+		if !filter.StartDate.IsZero() {
+			query = query.Where("date >= ?", filter.StartDate)
+		}
 
-	if !filter.EndDate.IsZero() {
-		query = query.Where("date <= ?", filter.EndDate)
+		if !filter.EndDate.IsZero() {
+			query = query.Where("date <= ?", filter.EndDate)
+		}
 	}
 
 	if filter.MinAmount > 0 {
@@ -99,44 +259,100 @@ func (r *TransactionRepository) GetByFilter(filter *models.TransactionFilter) ([
 	}
 
 	var transactions []*models.Transaction
-	err := query.Order("date DESC").Find(&transactions).Error
+	err := query.Order(sortOrder(filter)).Find(&transactions).Error
 	return transactions, err
 }
 
-func (r *TransactionRepository) GetSummary(start, end time.Time) (*models.TransactionSummary, error) {
+// sortOrder maps the filter's sort options onto a whitelisted ORDER BY
+// clause. Unknown values fall back to newest first.
+func sortOrder(filter *models.TransactionFilter) string {
+	dir := "DESC"
+	if filter.SortDir == models.SortAsc {
+		dir = "ASC"
+	}
+
+	switch filter.SortBy {
+	case models.SortByAmount:
+		return "amount_usd " + dir + ", date DESC"
+	case models.SortByCategory:
+		return "(SELECT name FROM categories WHERE categories.id = transactions.category_id) " + dir + ", date DESC"
+	case models.SortByDate:
+		return "date " + dir
+	default:
+		return "date DESC"
+	}
+}
+
+func (r *TransactionRepository) GetSummary(ctx context.Context, start, end time.Time) (*models.TransactionSummary, error) {
 	summary := &models.TransactionSummary{}
 
 	var incomeResult struct {
-		Total float64
-		Count int
+		Total     float64
+		Recurring float64
+		Count     int
 	}
-	r.db.Model(&models.Transaction{}).
-		Select("SUM(amount_usd) as total, COUNT(*) as count").
+	err := r.db.WithContext(ctx).Model(&models.Transaction{}).
+		Select("SUM(amount_usd) as total, "+recurringSumSQL("amount_usd")+" as recurring, COUNT(*) as count").
 		Where("type = ? AND date >= ? AND date <= ?", models.TransactionTypeIncome, start, end).
-		Scan(&incomeResult)
+		Scan(&incomeResult).Error
+	if err != nil {
+		return nil, err
+	}
 
-	var expenseResult struct {
-		Total float64
-		Count int
+	// Expenses are totalled by category, so that refunds outweighing a
+	// category's expenses are clamped there, as in GetCategorySummary,
+	// rather than taken off the other categories
+	var expenseResults []struct {
+		Total     float64
+		Recurring float64
+	}
+	err = r.db.WithContext(ctx).Table(categoryLinesSQL+" AS transactions").
+		Select("SUM("+netAmountSQL+") as total, "+recurringSumSQL(netAmountSQL)+" as recurring").
+		Where("transactions.type = ? AND transactions.date >= ? AND transactions.date <= ?", models.TransactionTypeExpense, start, end).
+		Where("transactions.deleted_at IS NULL").
+		Group("transactions.category_id").
+		Scan(&expenseResults).Error
+	if err != nil {
+		return nil, err
+	}
+	// Each category's total is clamped once and then split, the recurring
+	// part being at most the total, so the two parts add up to it
+	var expenseTotal, expenseRecurring float64
+	for _, result := range expenseResults {
+		total := math.Max(result.Total, 0)
+		expenseTotal += total
+		expenseRecurring += math.Min(math.Max(result.Recurring, 0), total)
 	}
-	r.db.Model(&models.Transaction{}).
-		Select("SUM(amount_usd) as total, COUNT(*) as count").
+	var expenseCount int64
+	err = r.db.WithContext(ctx).Model(&models.Transaction{}).
 		Where("type = ? AND date >= ? AND date <= ?", models.TransactionTypeExpense, start, end).
-		Scan(&expenseResult)
+		Count(&expenseCount).Error
+	if err != nil {
+		return nil, err
+	}
 
-	summary.TotalIncome = incomeResult.Total
-	summary.TotalExpenses = expenseResult.Total
-	summary.Count = incomeResult.Count + expenseResult.Count
+	// SQLite sums in floating point, so the totals are rounded back to cents
+	summary.TotalIncome = models.RoundUSD(incomeResult.Total)
+	summary.TotalExpenses = models.RoundUSD(expenseTotal)
+	summary.Count = incomeResult.Count + int(expenseCount)
+	summary.RecurringIncome = models.RoundUSD(incomeResult.Recurring)
+	summary.OneTimeIncome = models.RoundUSD(incomeResult.Total - incomeResult.Recurring)
+	summary.RecurringExpenses = models.RoundUSD(expenseRecurring)
+	summary.OneTimeExpenses = models.RoundUSD(summary.TotalExpenses - summary.RecurringExpenses)
 	summary.CalculateBalance()
 
 	return summary, nil
 }
 
-func (r *TransactionRepository) GetCategorySummary(start, end time.Time) ([]*models.CategoryWithTotal, error) {
+// GetCategorySummary totals each category's transactions in the period,
+// counting the lines of split transactions under their own categories.
+// Percentages are shares of the category's own type, so an expense category
+// shows its part of all expenses rather than of income and expenses combined.
+func (r *TransactionRepository) GetCategorySummary(ctx context.Context, start, end time.Time) ([]*models.CategoryWithTotal, error) {
 	var results []*models.CategoryWithTotal
 
-	err := r.db.Table("transactions").
-		Select("categories.*, SUM(transactions.amount_usd) as total, COUNT(transactions.id) as count").
+	err := r.db.WithContext(ctx).Table(categoryLinesSQL + " AS transactions").
+		Select("categories.*, SUM("+netAmountSQL+") as total, COUNT(transactions.id) as count").
 		Joins("JOIN categories ON categories.id = transactions.category_id").
 		Where("transactions.date >= ? AND transactions.date <= ?", start, end).
 		Where("transactions.deleted_at IS NULL").
@@ -148,33 +364,264 @@ func (r *TransactionRepository) GetCategorySummary(start, end time.Time) ([]*mod
 		return nil, err
 	}
 
-	var totalAmount float64
+	totalsByType := make(map[models.TransactionType]float64)
 	for _, result := range results {
-		totalAmount += result.Total
+		// Refunds exceeding a category's expenses should not show as a negative total
+		result.Total = models.RoundUSD(math.Max(result.Total, 0))
+		totalsByType[result.Type] += result.Total
 	}
 
 	for _, result := range results {
-		if totalAmount > 0 {
-			result.Percentage = (result.Total / totalAmount) * 100
+		if total := totalsByType[result.Type]; total > 0 {
+			result.Percentage = (result.Total / total) * 100
 		}
 	}
 
 	return results, nil
 }
 
-func (r *TransactionRepository) GetRecentTransactions(limit int) ([]*models.Transaction, error) {
+// GetDateRange returns the dates of the oldest and newest transactions.
+// Both are zero when there are no transactions.
+func (r *TransactionRepository) GetDateRange(ctx context.Context) (first, last time.Time, err error) {
+	var oldest, newest []time.Time
+	if err = r.db.WithContext(ctx).Model(&models.Transaction{}).Order("date ASC").Limit(1).Pluck("date", &oldest).Error; err != nil {
+		return first, last, err
+	}
+	if err = r.db.WithContext(ctx).Model(&models.Transaction{}).Order("date DESC").Limit(1).Pluck("date", &newest).Error; err != nil {
+		return first, last, err
+	}
+	if len(oldest) > 0 {
+		first = oldest[0]
+	}
+	if len(newest) > 0 {
+		last = newest[0]
+	}
+	return first, last, nil
+}
+
+func (r *TransactionRepository) GetRecentTransactions(ctx context.Context, limit int) ([]*models.Transaction, error) {
 	var transactions []*models.Transaction
-	err := r.db.Preload("Category").
+	err := r.db.WithContext(ctx).Preload("Category").Preload("Splits.Category").
 		Order("date DESC").
 		Limit(limit).
 		Find(&transactions).Error
 	return transactions, err
 }
 
-func (r *TransactionRepository) CountByCurrency(currency string) (int64, error) {
+// GetCurrencyStats counts and totals transactions per currency, most used
+// first. Only currencies with transactions are returned. Transfers are left
+// out, since each would count twice.
+func (r *TransactionRepository) GetCurrencyStats(ctx context.Context) ([]*models.CurrencyStat, error) {
+	var stats []*models.CurrencyStat
+	err := r.db.WithContext(ctx).Model(&models.Transaction{}).
+		Select("currency, COUNT(*) as count, SUM(amount) as total, SUM(amount_usd) as total_usd").
+		Where("type <> ?", models.TransactionTypeTransfer).
+		Group("currency").
+		Order("count DESC, currency ASC").
+		Scan(&stats).Error
+	return stats, err
+}
+
+// GetDailyTotals sums the expenses of each day in the period, refunds
+// netted, oldest first. Days are bucketed by local date here rather than in
+// SQL, where date() would group by the stored UTC time. Days without
+// expenses are left out.
+func (r *TransactionRepository) GetDailyTotals(ctx context.Context, start, end time.Time) ([]*models.DailyTotal, error) {
+	var rows []struct {
+		Date   time.Time
+		Amount float64
+	}
+	err := r.db.WithContext(ctx).Model(&models.Transaction{}).
+		Select("date, "+netAmountSQL+" as amount").
+		Where("type = ? AND date >= ? AND date <= ?", models.TransactionTypeExpense, start, end).
+		Order("date ASC").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	var totals []*models.DailyTotal
+	for _, row := range rows {
+		local := row.Date.In(time.Local)
+		day := time.Date(local.Year(), local.Month(), local.Day(), 0, 0, 0, 0, time.Local)
+		if n := len(totals); n > 0 && totals[n-1].Date.Equal(day) {
+			totals[n-1].Total += row.Amount
+			continue
+		}
+		totals = append(totals, &models.DailyTotal{Date: day, Total: row.Amount})
+	}
+	return totals, nil
+}
+
+// GetMonthlyTotals sums the income and expenses of each month in the
+// period, refunds netted, oldest first. Rows are told apart by comparing
+// their date with each local month's start rather than with strftime(),
+// which would group by the stored UTC time. Expenses are totalled by
+// category first to clamp refunds like GetSummary does. Months without
+// transactions are left out, as are transfers.
+func (r *TransactionRepository) GetMonthlyTotals(ctx context.Context, start, end time.Time) ([]*models.MonthlyTotal, error) {
+	local := start.In(time.Local)
+	var months []time.Time
+	var args []interface{}
+	monthSQL := "CASE"
+	for month := time.Date(local.Year(), local.Month(), 1, 0, 0, 0, 0, time.Local); !month.After(end); month = month.AddDate(0, 1, 0) {
+		monthSQL += " WHEN transactions.date < ? THEN ?"
+		args = append(args, month.AddDate(0, 1, 0), len(months))
+		months = append(months, month)
+	}
+	if len(months) == 0 {
+		return nil, nil
+	}
+	monthSQL += " END"
+
+	types := []models.TransactionType{models.TransactionTypeIncome, models.TransactionTypeExpense}
+	var rows []struct {
+		Month  int
+		Type   models.TransactionType
+		Amount float64
+	}
+	err := r.db.WithContext(ctx).Table(categoryLinesSQL+" AS transactions").
+		Select(monthSQL+" as month, transactions.type as type, SUM("+netAmountSQL+") as amount", args...).
+		Where("transactions.type IN ? AND transactions.date >= ? AND transactions.date <= ?", types, start, end).
+		Where("transactions.deleted_at IS NULL").
+		Group("month, transactions.type, transactions.category_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	var counts []struct {
+		Month int
+		Count int
+	}
+	err = r.db.WithContext(ctx).Model(&models.Transaction{}).
+		Select(monthSQL+" as month, COUNT(*) as count", args...).
+		Where("type IN ? AND date >= ? AND date <= ?", types, start, end).
+		Group("month").
+		Scan(&counts).Error
+	if err != nil {
+		return nil, err
+	}
+
+	byMonth := make([]*models.MonthlyTotal, len(months))
+	for _, count := range counts {
+		byMonth[count.Month] = &models.MonthlyTotal{Month: months[count.Month], Count: count.Count}
+	}
+	for _, row := range rows {
+		if row.Type == models.TransactionTypeIncome {
+			byMonth[row.Month].Income += row.Amount
+		} else {
+			byMonth[row.Month].Expenses += math.Max(row.Amount, 0)
+		}
+	}
+
+	// SQLite sums in floating point, so the totals are rounded back to cents
+	var totals []*models.MonthlyTotal
+	for _, total := range byMonth {
+		if total == nil {
+			continue
+		}
+		total.Income = models.RoundUSD(total.Income)
+		total.Expenses = models.RoundUSD(total.Expenses)
+		totals = append(totals, total)
+	}
+	return totals, nil
+}
+
+// GetLargestExpense returns the period's largest expense in USD that isn't
+// a refund, or nil when there is none
+func (r *TransactionRepository) GetLargestExpense(ctx context.Context, start, end time.Time) (*models.Transaction, error) {
+	var transactions []*models.Transaction
+	err := r.db.WithContext(ctx).Preload("Category").
+		Where("type = ? AND is_refund = ? AND date >= ? AND date <= ?", models.TransactionTypeExpense, false, start, end).
+		Order("amount_usd DESC, id ASC").
+		Limit(1).
+		Find(&transactions).Error
+	if err != nil || len(transactions) == 0 {
+		return nil, err
+	}
+	return transactions[0], nil
+}
+
+func (r *TransactionRepository) CountByCurrency(ctx context.Context, currency string) (int64, error) {
 	var count int64
-	err := r.db.Model(&models.Transaction{}).
+	err := r.db.WithContext(ctx).Model(&models.Transaction{}).
 		Where("currency = ?", currency).
 		Count(&count).Error
 	return count, err
-}
\ No newline at end of file
+}
+
+// GetAllocations returns the allocations of the income transaction with the
+// given ID, largest first
+func (r *TransactionRepository) GetAllocations(ctx context.Context, transactionID uint) ([]*models.IncomeAllocation, error) {
+	var allocations []*models.IncomeAllocation
+	err := r.db.WithContext(ctx).Where("transaction_id = ?", transactionID).
+		Order("amount DESC, id ASC").
+		Find(&allocations).Error
+	return allocations, err
+}
+
+// ReplaceAllocations swaps the allocations of the transaction with the given
+// ID for the ones given, in a single database transaction
+func (r *TransactionRepository) ReplaceAllocations(ctx context.Context, transactionID uint, allocations []*models.IncomeAllocation) error {
+	return r.db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		if err := tx.Where("transaction_id = ?", transactionID).Delete(&models.IncomeAllocation{}).Error; err != nil {
+			return err
+		}
+		for _, allocation := range allocations {
+			allocation.ID = 0
+			allocation.TransactionID = transactionID
+			if err := tx.Create(allocation).Error; err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// GetAttachments returns the attachments of the transaction with the given
+// ID, oldest first
+func (r *TransactionRepository) GetAttachments(ctx context.Context, transactionID uint) ([]*models.Attachment, error) {
+	var attachments []*models.Attachment
+	err := r.db.WithContext(ctx).Where("transaction_id = ?", transactionID).
+		Order("added_at ASC, id ASC").
+		Find(&attachments).Error
+	return attachments, err
+}
+
+func (r *TransactionRepository) AddAttachment(ctx context.Context, attachment *models.Attachment) error {
+	return r.db.WithContext(ctx).Create(attachment).Error
+}
+
+func (r *TransactionRepository) DeleteAttachment(ctx context.Context, id uint) error {
+	result := r.db.WithContext(ctx).Delete(&models.Attachment{}, id)
+	if result.Error != nil {
+		return result.Error
+	}
+	if result.RowsAffected == 0 {
+		return gorm.ErrRecordNotFound
+	}
+	return nil
+}
+
+// GetAllocationTotals totals the allocations of the income in the period by
+// name, largest first. Allocations are converted to USD at the rate of
+// their transaction, so they add up to its AmountUSD.
+func (r *TransactionRepository) GetAllocationTotals(ctx context.Context, start, end time.Time) ([]*models.AllocationTotal, error) {
+	var totals []*models.AllocationTotal
+	err := r.db.WithContext(ctx).Table("income_allocations").
+		Select("income_allocations.name as name, SUM(income_allocations.amount * transactions.amount_usd / transactions.amount) as amount_usd").
+		Joins("JOIN transactions ON transactions.id = income_allocations.transaction_id").
+		Where("transactions.type = ? AND transactions.date >= ? AND transactions.date <= ?", models.TransactionTypeIncome, start, end).
+		Where("transactions.deleted_at IS NULL").
+		Group("income_allocations.name").
+		Order("amount_usd DESC").
+		Scan(&totals).Error
+	if err != nil {
+		return nil, err
+	}
+
+	for _, total := range totals {
+		total.AmountUSD = models.RoundUSD(total.AmountUSD)
+	}
+	return totals, nil
+}
@@ -1,11 +1,17 @@
 package repository
 
 import (
+	"context"
+	"errors"
+	"path/filepath"
 	"testing"
 	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"gorm.io/driver/sqlite"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
 
 	"burnwise/internal/models"
 	test "burnwise/test/helpers"
@@ -23,11 +29,11 @@ func TestTransactionRepository_Create(t *testing.T) {
 		WithAmount(50.00).
 		Build()
 	
-	err := repo.Create(tx)
+	err := repo.Create(t.Context(), tx)
 	require.NoError(t, err)
 	assert.Greater(t, tx.ID, uint(0))
 	
-	found, err := repo.GetByID(tx.ID)
+	found, err := repo.GetByID(t.Context(), tx.ID)
 	require.NoError(t, err)
 	assert.Equal(t, tx.Description, found.Description)
 	assert.Equal(t, tx.Amount, found.Amount)
@@ -55,15 +61,15 @@ func TestTransactionRepository_GetByDateRange(t *testing.T) {
 		WithDescription("Tomorrow").
 		Build()
 	
-	require.NoError(t, repo.Create(yesterday))
-	require.NoError(t, repo.Create(today))
-	require.NoError(t, repo.Create(tomorrow))
+	require.NoError(t, repo.Create(t.Context(), yesterday))
+	require.NoError(t, repo.Create(t.Context(), today))
+	require.NoError(t, repo.Create(t.Context(), tomorrow))
 	
 	// Set specific times to ensure proper date boundaries
 	startOfYesterday := time.Now().AddDate(0, 0, -1).Truncate(24 * time.Hour)
 	endOfToday := time.Now().Truncate(24 * time.Hour).Add(24*time.Hour - time.Second)
 	
-	results, err := repo.GetByDateRange(startOfYesterday, endOfToday)
+	results, err := repo.GetByDateRange(t.Context(), startOfYesterday, endOfToday)
 	
 	require.NoError(t, err)
 	assert.Len(t, results, 2)
@@ -101,9 +107,9 @@ func TestTransactionRepository_GetByFilter(t *testing.T) {
 		WithDescription("Dinner at restaurant").
 		Build()
 	
-	require.NoError(t, repo.Create(income))
-	require.NoError(t, repo.Create(expense1))
-	require.NoError(t, repo.Create(expense2))
+	require.NoError(t, repo.Create(t.Context(), income))
+	require.NoError(t, repo.Create(t.Context(), expense1))
+	require.NoError(t, repo.Create(t.Context(), expense2))
 	
 	tests := []struct {
 		name      string
@@ -143,7 +149,7 @@ func TestTransactionRepository_GetByFilter(t *testing.T) {
 	
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			results, err := repo.GetByFilter(tt.filter)
+			results, err := repo.GetByFilter(t.Context(), tt.filter)
 			require.NoError(t, err)
 			assert.Len(t, results, tt.wantCount)
 		})
@@ -175,20 +181,161 @@ func TestTransactionRepository_GetSummary(t *testing.T) {
 		WithAmount(200).
 		Build()
 	
-	require.NoError(t, repo.Create(income))
-	require.NoError(t, repo.Create(expense1))
-	require.NoError(t, repo.Create(expense2))
+	require.NoError(t, repo.Create(t.Context(), income))
+	require.NoError(t, repo.Create(t.Context(), expense1))
+	require.NoError(t, repo.Create(t.Context(), expense2))
 	
 	start := time.Now().AddDate(0, 0, -7)
 	end := time.Now().AddDate(0, 0, 1)
 	
-	summary, err := repo.GetSummary(start, end)
+	summary, err := repo.GetSummary(t.Context(), start, end)
 	require.NoError(t, err)
 	
 	assert.Equal(t, 5000.0, summary.TotalIncome)
 	assert.Equal(t, 300.0, summary.TotalExpenses)
 	assert.Equal(t, 4700.0, summary.Balance)
 	assert.Equal(t, 3, summary.Count)
+	assert.InDelta(t, 94.0, summary.SavingsRate(), 0.01)
+	assert.InDelta(t, 16.67, summary.IncomeExpenseRatio(), 0.01)
+}
+
+func TestTransactionRepository_GetSummaryRecurringSplit(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := NewTransactionRepository(db)
+	
+	incomeCategory := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	expenseCategory := test.CreateTestCategory(t, db, "Housing", models.TransactionTypeExpense)
+	
+	rent := &models.RecurringTransaction{
+		Type:           models.TransactionTypeExpense,
+		Amount:         2100,
+		Currency:       "USD",
+		CategoryID:     expenseCategory.ID,
+		Description:    "Rent",
+		Frequency:      models.FrequencyMonthly,
+		FrequencyValue: 1,
+		StartDate:      time.Now().AddDate(0, -1, 0),
+		NextDueDate:    time.Now().AddDate(0, 1, 0),
+		IsActive:       true,
+	}
+	require.NoError(t, db.Create(rent).Error)
+	
+	transactions := []*models.Transaction{
+		fixtures.NewTransaction().WithType(models.TransactionTypeIncome).WithCategory(incomeCategory.ID).WithAmount(5000).WithRecurring(rent.ID).Build(),
+		fixtures.NewTransaction().WithType(models.TransactionTypeIncome).WithCategory(incomeCategory.ID).WithAmount(300).Build(),
+		fixtures.NewTransaction().WithCategory(expenseCategory.ID).WithAmount(2100).WithRecurring(rent.ID).Build(),
+		fixtures.NewTransaction().WithCategory(expenseCategory.ID).WithAmount(700).Build(),
+		fixtures.NewTransaction().WithCategory(expenseCategory.ID).WithAmount(60).AsRefund().Build(),
+	}
+	for _, tx := range transactions {
+		require.NoError(t, repo.Create(t.Context(), tx))
+	}
+	
+	summary, err := repo.GetSummary(t.Context(), time.Now().AddDate(0, 0, -7), time.Now().AddDate(0, 0, 1))
+	require.NoError(t, err)
+	
+	assert.Equal(t, 5000.0, summary.RecurringIncome)
+	assert.Equal(t, 300.0, summary.OneTimeIncome)
+	assert.Equal(t, 2100.0, summary.RecurringExpenses)
+	assert.Equal(t, 640.0, summary.OneTimeExpenses)
+	assert.Equal(t, 2740.0, summary.TotalExpenses)
+	
+	// Without recurring transactions everything is one-time
+	empty, err := repo.GetSummary(t.Context(), time.Now().AddDate(-1, 0, 0), time.Now().AddDate(-1, 0, 1))
+	require.NoError(t, err)
+	assert.Zero(t, empty.RecurringExpenses)
+	assert.Zero(t, empty.OneTimeExpenses)
+}
+
+func TestTransactionRepository_GetSummaryRecurringSplitOverRefund(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := NewTransactionRepository(db)
+	
+	housing := test.CreateTestCategory(t, db, "Housing", models.TransactionTypeExpense)
+	gym := test.CreateTestCategory(t, db, "Gym", models.TransactionTypeExpense)
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	rent := &models.RecurringTransaction{
+		Type:           models.TransactionTypeExpense,
+		Amount:         2100,
+		Currency:       "USD",
+		CategoryID:     housing.ID,
+		Description:    "Rent",
+		Frequency:      models.FrequencyMonthly,
+		FrequencyValue: 1,
+		StartDate:      time.Now().AddDate(0, -1, 0),
+		NextDueDate:    time.Now().AddDate(0, 1, 0),
+		IsActive:       true,
+	}
+	require.NoError(t, db.Create(rent).Error)
+	membership := *rent
+	membership.ID, membership.CategoryID, membership.Amount, membership.Description = 0, gym.ID, 50, "Membership"
+	require.NoError(t, db.Create(&membership).Error)
+	
+	transactions := []*models.Transaction{
+		// The deposit coming back outweighs the month's rent
+		fixtures.NewTransaction().WithCategory(housing.ID).WithAmount(2100).WithRecurring(rent.ID).Build(),
+		fixtures.NewTransaction().WithCategory(housing.ID).WithAmount(2500).AsRefund().Build(),
+		// A one-time refund taking part of a recurring fee back
+		fixtures.NewTransaction().WithCategory(gym.ID).WithAmount(50).WithRecurring(membership.ID).Build(),
+		fixtures.NewTransaction().WithCategory(gym.ID).WithAmount(20).AsRefund().Build(),
+		fixtures.NewTransaction().WithCategory(food.ID).WithAmount(100).Build(),
+	}
+	for _, tx := range transactions {
+		require.NoError(t, repo.Create(t.Context(), tx))
+	}
+	
+	summary, err := repo.GetSummary(t.Context(), time.Now().AddDate(0, 0, -7), time.Now().AddDate(0, 0, 1))
+	require.NoError(t, err)
+	
+	// Housing comes to nothing, of which nothing is recurring, and Gym's 30
+	// is all recurring
+	assert.Equal(t, 130.0, summary.TotalExpenses)
+	assert.Equal(t, 30.0, summary.RecurringExpenses)
+	assert.Equal(t, 100.0, summary.OneTimeExpenses)
+}
+
+func TestTransactionRepository_GetSummaryRounding(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := NewTransactionRepository(db)
+	
+	incomeCategory := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	expenseCategory := test.CreateTestCategory(t, db, "Coffee", models.TransactionTypeExpense)
+	
+	require.NoError(t, repo.Create(t.Context(), fixtures.NewTransaction().
+		WithType(models.TransactionTypeIncome).
+		WithCategory(incomeCategory.ID).
+		WithAmount(5000).
+		Build()))
+	for i := 0; i < 1000; i++ {
+		require.NoError(t, repo.Create(t.Context(), fixtures.NewTransaction().
+			WithCategory(expenseCategory.ID).
+			WithAmount(0.1).
+			Build()))
+	}
+	// Converted amounts are stored in cents
+	converted := fixtures.NewTransaction().
+		WithCategory(expenseCategory.ID).
+		WithAmount(0.3).
+		WithCurrency("AED").
+		WithAmountUSD(0.3 / 3.6725).
+		Build()
+	require.NoError(t, repo.Create(t.Context(), converted))
+	assert.Equal(t, 0.08, converted.AmountUSD)
+	
+	summary, err := repo.GetSummary(t.Context(), time.Now().AddDate(0, 0, -1), time.Now().AddDate(0, 0, 1))
+	require.NoError(t, err)
+	
+	assert.Equal(t, 100.08, summary.TotalExpenses)
+	assert.Equal(t, 100.08, summary.OneTimeExpenses)
+	assert.Equal(t, 4899.92, summary.Balance)
+	
+	categories, err := repo.GetCategorySummary(t.Context(), time.Now().AddDate(0, 0, -1), time.Now().AddDate(0, 0, 1))
+	require.NoError(t, err)
+	for _, category := range categories {
+		if category.Name == "Coffee" {
+			assert.Equal(t, 100.08, category.Total)
+		}
+	}
 }
 
 func TestTransactionRepository_GetCategorySummary(t *testing.T) {
@@ -198,17 +345,17 @@ func TestTransactionRepository_GetCategorySummary(t *testing.T) {
 	foodCategory := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
 	transportCategory := test.CreateTestCategory(t, db, "Transport", models.TransactionTypeExpense)
 	
-	require.NoError(t, repo.Create(fixtures.NewTransaction().
+	require.NoError(t, repo.Create(t.Context(), fixtures.NewTransaction().
 		WithCategory(foodCategory.ID).
 		WithAmount(100).
 		Build()))
 	
-	require.NoError(t, repo.Create(fixtures.NewTransaction().
+	require.NoError(t, repo.Create(t.Context(), fixtures.NewTransaction().
 		WithCategory(foodCategory.ID).
 		WithAmount(50).
 		Build()))
 	
-	require.NoError(t, repo.Create(fixtures.NewTransaction().
+	require.NoError(t, repo.Create(t.Context(), fixtures.NewTransaction().
 		WithCategory(transportCategory.ID).
 		WithAmount(75).
 		Build()))
@@ -216,7 +363,7 @@ func TestTransactionRepository_GetCategorySummary(t *testing.T) {
 	start := time.Now().AddDate(0, 0, -7)
 	end := time.Now().AddDate(0, 0, 1)
 	
-	summary, err := repo.GetCategorySummary(start, end)
+	summary, err := repo.GetCategorySummary(t.Context(), start, end)
 	require.NoError(t, err)
 	
 	assert.Len(t, summary, 2)
@@ -229,4 +376,332 @@ func TestTransactionRepository_GetCategorySummary(t *testing.T) {
 	assert.Equal(t, 75.0, summary[1].Total)
 	assert.Equal(t, 1, summary[1].Count)
 	assert.InDelta(t, 33.33, summary[1].Percentage, 0.01)
-}
\ No newline at end of file
+}
+
+func TestTransactionRepository_GetCategorySummaryPercentagesPerType(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := NewTransactionRepository(db)
+	
+	salaryCategory := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	foodCategory := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	transportCategory := test.CreateTestCategory(t, db, "Transport", models.TransactionTypeExpense)
+	
+	require.NoError(t, repo.Create(t.Context(), fixtures.NewTransaction().
+		WithCategory(salaryCategory.ID).
+		WithType(models.TransactionTypeIncome).
+		WithAmount(5000).
+		Build()))
+	
+	require.NoError(t, repo.Create(t.Context(), fixtures.NewTransaction().
+		WithCategory(foodCategory.ID).
+		WithAmount(150).
+		Build()))
+	
+	require.NoError(t, repo.Create(t.Context(), fixtures.NewTransaction().
+		WithCategory(transportCategory.ID).
+		WithAmount(75).
+		Build()))
+	
+	start := time.Now().AddDate(0, 0, -7)
+	end := time.Now().AddDate(0, 0, 1)
+	
+	summary, err := repo.GetCategorySummary(t.Context(), start, end)
+	require.NoError(t, err)
+	require.Len(t, summary, 3)
+	
+	byName := make(map[string]*models.CategoryWithTotal)
+	for _, cat := range summary {
+		byName[cat.Name] = cat
+	}
+	
+	// Income does not dilute the expense shares
+	assert.Equal(t, 100.0, byName["Salary"].Percentage)
+	assert.InDelta(t, 66.67, byName["Food"].Percentage, 0.01)
+	assert.InDelta(t, 33.33, byName["Transport"].Percentage, 0.01)
+}
+
+func TestTransactionRepository_RefundsNetAgainstCategory(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := NewTransactionRepository(db)
+	
+	foodCategory := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	giftsCategory := test.CreateTestCategory(t, db, "Gifts", models.TransactionTypeExpense)
+	
+	require.NoError(t, repo.Create(t.Context(), fixtures.NewTransaction().
+		WithCategory(foodCategory.ID).
+		WithAmount(100).
+		Build()))
+	
+	require.NoError(t, repo.Create(t.Context(), fixtures.NewTransaction().
+		WithCategory(foodCategory.ID).
+		WithAmount(30).
+		AsRefund().
+		Build()))
+	
+	// A refund larger than the category's spending in the period
+	require.NoError(t, repo.Create(t.Context(), fixtures.NewTransaction().
+		WithCategory(giftsCategory.ID).
+		WithAmount(40).
+		AsRefund().
+		Build()))
+	
+	start := time.Now().AddDate(0, 0, -7)
+	end := time.Now().AddDate(0, 0, 1)
+	
+	summary, err := repo.GetCategorySummary(t.Context(), start, end)
+	require.NoError(t, err)
+	
+	require.Len(t, summary, 2)
+	assert.Equal(t, "Food", summary[0].Name)
+	assert.Equal(t, 70.0, summary[0].Total)
+	assert.Equal(t, 100.0, summary[0].Percentage)
+	
+	assert.Equal(t, "Gifts", summary[1].Name)
+	assert.Equal(t, 0.0, summary[1].Total)
+	
+	totals, err := repo.GetSummary(t.Context(), start, end)
+	require.NoError(t, err)
+	
+	// The Gifts refund is clamped within Gifts, like in the category
+	// summary, rather than taken off Food
+	assert.Equal(t, 70.0, totals.TotalExpenses)
+	assert.Equal(t, 0.0, totals.TotalIncome)
+	assert.Equal(t, 3, totals.Count)
+	
+	monthly, err := repo.GetMonthlyTotals(t.Context(), start, end)
+	require.NoError(t, err)
+	var expenses float64
+	for _, month := range monthly {
+		expenses += month.Expenses
+	}
+	assert.Equal(t, 70.0, expenses)
+}
+
+func TestTransactionRepository_RefundMustBeExpense(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := NewTransactionRepository(db)
+	
+	category := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	
+	tx := fixtures.NewTransaction().
+		WithCategory(category.ID).
+		AsRefund().
+		WithType(models.TransactionTypeIncome).
+		Build()
+	
+	assert.Error(t, repo.Create(t.Context(), tx))
+}
+
+func TestTransactionRepository_GetByFilter_Sort(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := NewTransactionRepository(db)
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	books := test.CreateTestCategory(t, db, "Books", models.TransactionTypeExpense)
+	
+	now := time.Now()
+	require.NoError(t, repo.Create(t.Context(), fixtures.NewTransaction().
+		WithCategory(food.ID).WithAmount(50).WithDate(now.AddDate(0, 0, -2)).Build()))
+	require.NoError(t, repo.Create(t.Context(), fixtures.NewTransaction().
+		WithCategory(books.ID).WithAmount(20).WithDate(now.AddDate(0, 0, -1)).Build()))
+	require.NoError(t, repo.Create(t.Context(), fixtures.NewTransaction().
+		WithCategory(food.ID).WithAmount(80).WithDate(now).Build()))
+	
+	amounts := func(filter *models.TransactionFilter) []float64 {
+		transactions, err := repo.GetByFilter(t.Context(), filter)
+		require.NoError(t, err)
+		result := make([]float64, len(transactions))
+		for i, tx := range transactions {
+			result[i] = tx.Amount
+		}
+		return result
+	}
+	
+	assert.Equal(t, []float64{80, 20, 50}, amounts(&models.TransactionFilter{}))
+	assert.Equal(t, []float64{50, 20, 80}, amounts(&models.TransactionFilter{SortBy: models.SortByDate, SortDir: models.SortAsc}))
+	assert.Equal(t, []float64{80, 50, 20}, amounts(&models.TransactionFilter{SortBy: models.SortByAmount, SortDir: models.SortDesc}))
+	assert.Equal(t, []float64{20, 50, 80}, amounts(&models.TransactionFilter{SortBy: models.SortByAmount, SortDir: models.SortAsc}))
+	assert.Equal(t, []float64{20, 80, 50}, amounts(&models.TransactionFilter{SortBy: models.SortByCategory, SortDir: models.SortAsc}))
+	
+	// Unknown sort fields fall back to newest first
+	assert.Equal(t, []float64{80, 20, 50}, amounts(&models.TransactionFilter{SortBy: "amount; DROP TABLE transactions"}))
+}
+
+func TestTransactionRepository_GetDateRange(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := NewTransactionRepository(db)
+	
+	first, last, err := repo.GetDateRange(t.Context())
+	require.NoError(t, err)
+	assert.True(t, first.IsZero())
+	assert.True(t, last.IsZero())
+	
+	category := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	oldest := time.Date(2024, time.March, 5, 12, 0, 0, 0, time.Local)
+	newest := time.Date(2025, time.July, 20, 12, 0, 0, 0, time.Local)
+	
+	for _, date := range []time.Time{newest, oldest, oldest.AddDate(0, 2, 0)} {
+		require.NoError(t, repo.Create(t.Context(), fixtures.NewTransaction().
+			WithCategory(category.ID).
+			WithDate(date).
+			Build()))
+	}
+	
+	first, last, err = repo.GetDateRange(t.Context())
+	require.NoError(t, err)
+	assert.True(t, first.Equal(oldest))
+	assert.True(t, last.Equal(newest))
+}
+
+func TestTransactionRepository_GetMonthlyTotals(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := NewTransactionRepository(db)
+	
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	
+	create := func(builder *fixtures.TransactionBuilder, date time.Time) {
+		require.NoError(t, repo.Create(t.Context(), builder.WithDate(date).Build()))
+	}
+	create(fixtures.NewTransaction().WithType(models.TransactionTypeIncome).WithCategory(salary.ID).WithAmount(3000), time.Date(2025, time.January, 31, 23, 30, 0, 0, time.Local))
+	create(fixtures.NewTransaction().WithCategory(food.ID).WithAmount(120.10), time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local))
+	create(fixtures.NewTransaction().WithCategory(food.ID).WithAmount(20.20), time.Date(2025, time.January, 15, 12, 0, 0, 0, time.Local))
+	create(fixtures.NewTransaction().WithCategory(food.ID).WithAmount(40).AsRefund(), time.Date(2025, time.January, 20, 12, 0, 0, 0, time.Local))
+	// A refund outweighing the month's expenses
+	create(fixtures.NewTransaction().WithCategory(food.ID).WithAmount(15).AsRefund(), time.Date(2025, time.March, 3, 12, 0, 0, 0, time.Local))
+	// Outside the period
+	create(fixtures.NewTransaction().WithCategory(food.ID).WithAmount(999), time.Date(2024, time.December, 31, 23, 0, 0, 0, time.Local))
+	
+	totals, err := repo.GetMonthlyTotals(t.Context(), time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local), time.Date(2025, time.December, 31, 23, 59, 59, 0, time.Local))
+	require.NoError(t, err)
+	require.Len(t, totals, 2)
+	
+	assert.True(t, totals[0].Month.Equal(time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local)))
+	assert.Equal(t, 3000.0, totals[0].Income)
+	assert.Equal(t, 100.3, totals[0].Expenses)
+	assert.Equal(t, 2899.7, totals[0].Net())
+	assert.Equal(t, 4, totals[0].Count)
+	
+	assert.Equal(t, time.March, totals[1].Month.Month())
+	assert.Equal(t, 0.0, totals[1].Expenses)
+}
+
+func TestTransactionRepository_LockedDatabase(t *testing.T) {
+	// Two connections to one database, as with two instances running, in
+	// the default rollback journal mode where a writer locks out the other
+	dbPath := filepath.Join(t.TempDir(), "locked.db")
+	open := func(dsn string) *gorm.DB {
+		db, err := gorm.Open(sqlite.Open(dsn), &gorm.Config{Logger: logger.Default.LogMode(logger.Silent)})
+		require.NoError(t, err)
+		t.Cleanup(func() {
+			sqlDB, _ := db.DB()
+			sqlDB.Close()
+		})
+		return db
+	}
+	first := open(dbPath + "?_busy_timeout=50")
+	require.NoError(t, first.AutoMigrate(&models.Category{}, &models.Transaction{}, &models.Attachment{}, &models.SplitItem{}))
+	second := open(dbPath + "?_busy_timeout=50")
+	repo := NewTransactionRepository(first)
+	
+	category := test.CreateTestCategory(t, first, "Food", models.TransactionTypeExpense)
+	newTx := func() *models.Transaction {
+		return fixtures.NewTransaction().WithCategory(category.ID).Build()
+	}
+	
+	// While the other connection is writing, the write fails as busy
+	rollback := errors.New("rollback")
+	err := second.Transaction(func(tx *gorm.DB) error {
+		require.NoError(t, tx.Create(newTx()).Error)
+		err := repo.Create(t.Context(), newTx())
+		assert.ErrorIs(t, err, ErrDatabaseBusy)
+		assert.Equal(t, "database busy, retry", err.Error())
+		return rollback
+	})
+	require.ErrorIs(t, err, rollback)
+	
+	// Once it is done, the write goes through
+	created := newTx()
+	require.NoError(t, repo.Create(t.Context(), created))
+	
+	// Writes through a read-only connection are refused as such
+	readOnly := NewTransactionRepository(open("file:" + dbPath + "?mode=ro"))
+	assert.ErrorIs(t, readOnly.Create(t.Context(), newTx()), ErrReadOnly)
+	_, err = readOnly.GetByID(t.Context(), created.ID)
+	assert.NoError(t, err)
+}
+
+func TestTransactionRepository_Cancelled(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := NewTransactionRepository(db)
+	
+	category := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	var txs []*models.Transaction
+	for i := 0; i < 50; i++ {
+		txs = append(txs, fixtures.NewTransaction().WithCategory(category.ID).Build())
+	}
+	
+	// Cancelled after the first row, as when esc is pressed partway through
+	// an import: the rows already written are rolled back with it
+	ctx, cancel := context.WithCancel(t.Context())
+	defer cancel()
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:cancel", func(*gorm.DB) {
+		cancel()
+	}))
+	err := repo.CreateMany(ctx, txs)
+	assert.ErrorIs(t, err, context.Canceled)
+	require.NoError(t, db.Callback().Create().Remove("test:cancel"))
+	
+	count, err := repo.CountByCurrency(t.Context(), "USD")
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	
+	// A query started once cancelled, as when esc is pressed during an
+	// export, returns no rows
+	require.NoError(t, repo.CreateMany(t.Context(), txs))
+	ctx, cancel = context.WithCancel(t.Context())
+	require.NoError(t, db.Callback().Query().Before("gorm:query").Register("test:cancel", func(*gorm.DB) {
+		cancel()
+	}))
+	found, err := repo.GetByFilter(ctx, &models.TransactionFilter{})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Empty(t, found)
+}
+
+func TestTransactionRepository_CreateManyUnarchives(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := NewTransactionRepository(db)
+	categoryRepo := NewCategoryRepository(db)
+	
+	category := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	require.NoError(t, categoryRepo.SetArchived(t.Context(), category.ID, true))
+	txs := []*models.Transaction{
+		fixtures.NewTransaction().WithCategory(category.ID).Build(),
+		fixtures.NewTransaction().WithCategory(category.ID).Build(),
+	}
+	isArchived := func() bool {
+		found, err := categoryRepo.GetByID(t.Context(), category.ID)
+		require.NoError(t, err)
+		return found.IsArchived
+	}
+	
+	// A failed import leaves the category archived along with rolling
+	// back its rows
+	created := 0
+	require.NoError(t, db.Callback().Create().After("gorm:create").Register("test:fail", func(tx *gorm.DB) {
+		if tx.Statement.Table == "transactions" {
+			if created++; created == 2 {
+				tx.AddError(errors.New("disk full"))
+			}
+		}
+	}))
+	require.Error(t, repo.CreateMany(t.Context(), txs))
+	require.NoError(t, db.Callback().Create().Remove("test:fail"))
+	assert.True(t, isArchived())
+	
+	for _, tx := range txs {
+		tx.ID = 0
+	}
+	require.NoError(t, repo.CreateMany(t.Context(), txs))
+	assert.False(t, isArchived())
+}
@@ -0,0 +1,121 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"burnwise/internal/models"
+	"burnwise/internal/repository"
+)
+
+// AccountService manages the accounts transactions can optionally be
+// assigned to, such as cash or a credit card, and totals them
+type AccountService struct {
+	repo *repository.AccountRepository
+}
+
+func NewAccountService(repo *repository.AccountRepository) *AccountService {
+	return &AccountService{repo: repo}
+}
+
+func (s *AccountService) Create(ctx context.Context, account *models.Account) error {
+	if err := s.check(ctx, account); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	return s.repo.Create(ctx, account)
+}
+
+func (s *AccountService) Update(ctx context.Context, account *models.Account) error {
+	if err := s.check(ctx, account); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+	return s.repo.Update(ctx, account)
+}
+
+// check trims the account's name and makes sure it is valid and not used
+// by another account
+func (s *AccountService) check(ctx context.Context, account *models.Account) error {
+	account.Name = strings.TrimSpace(account.Name)
+	if err := account.Validate(); err != nil {
+		return err
+	}
+
+	if existing, _ := s.repo.GetByName(ctx, account.Name); existing != nil && existing.ID != account.ID {
+		return fmt.Errorf("an account named '%s' already exists", existing.Name)
+	}
+	return nil
+}
+
+// Delete removes an account. Its transactions are kept, without an account.
+// A transfer can't be without one, so an account with transfers is refused.
+func (s *AccountService) Delete(ctx context.Context, id uint) error {
+	if _, err := s.repo.GetByID(ctx, id); err != nil {
+		return fmt.Errorf("account not found: %w", err)
+	}
+
+	transfers, err := s.repo.CountTransfers(ctx, id)
+	if err != nil {
+		return err
+	}
+	if transfers > 0 {
+		return fmt.Errorf("cannot delete account with %d transfers", transfers)
+	}
+
+	return s.repo.Delete(ctx, id)
+}
+
+func (s *AccountService) GetByID(ctx context.Context, id uint) (*models.Account, error) {
+	return s.repo.GetByID(ctx, id)
+}
+
+// GetAll returns the accounts sorted by name
+func (s *AccountService) GetAll(ctx context.Context) ([]*models.Account, error) {
+	return s.repo.GetAll(ctx)
+}
+
+// GetBalances returns every account's balance as of now: its opening
+// balance plus all its income and less all its expenses up to now, in USD.
+// Transactions without an account come last when there are any.
+func (s *AccountService) GetBalances(ctx context.Context, now time.Time) ([]*models.AccountSummary, error) {
+	summaries, err := s.repo.GetSummaries(ctx, time.Time{}, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account totals: %w", err)
+	}
+	return summaries, nil
+}
+
+// GetSpending breaks the period's expenses down by account, largest first,
+// each with its share of the total. Accounts without expenses in the period
+// are left out. Without any accounts there is nothing to break down, so
+// nothing is returned.
+func (s *AccountService) GetSpending(ctx context.Context, start, end time.Time) ([]*models.AccountSummary, error) {
+	summaries, err := s.repo.GetSummaries(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get account totals: %w", err)
+	}
+
+	var spending []*models.AccountSummary
+	var total float64
+	hasAccounts := false
+	for _, summary := range summaries {
+		hasAccounts = hasAccounts || summary.Account != nil
+		if summary.Expenses > 0 {
+			spending = append(spending, summary)
+			total += summary.Expenses
+		}
+	}
+	if !hasAccounts {
+		return nil, nil
+	}
+
+	for _, summary := range spending {
+		summary.Percentage = summary.Expenses / total * 100
+	}
+	sort.SliceStable(spending, func(i, j int) bool {
+		return spending[i].Expenses > spending[j].Expenses
+	})
+	return spending, nil
+}
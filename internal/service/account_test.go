@@ -0,0 +1,138 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"burnwise/internal/models"
+	"burnwise/internal/repository"
+	"burnwise/test/fixtures"
+	test "burnwise/test/helpers"
+)
+
+func TestAccountService_Create(t *testing.T) {
+	db := test.SetupTestDB(t)
+	service := NewAccountService(repository.NewAccountRepository(db))
+
+	visa := &models.Account{Name: "  Visa ", Type: models.AccountTypeCredit}
+	require.NoError(t, service.Create(t.Context(), visa))
+	assert.Equal(t, "Visa", visa.Name)
+
+	err := service.Create(t.Context(), &models.Account{Name: "visa", Type: models.AccountTypeDebit})
+	assert.ErrorContains(t, err, "already exists")
+
+	err = service.Create(t.Context(), &models.Account{Name: "Wallet", Type: "piggy bank"})
+	assert.ErrorContains(t, err, "invalid account type")
+
+	err = service.Create(t.Context(), &models.Account{Name: " ", Type: models.AccountTypeCash})
+	assert.ErrorContains(t, err, "name is required")
+
+	// Renaming keeps the name check from tripping over the account itself
+	visa.Name = "VISA"
+	require.NoError(t, service.Update(t.Context(), visa))
+}
+
+func TestAccountService_BalancesAndSpending(t *testing.T) {
+	db := test.SetupTestDB(t)
+	accounts := NewAccountService(repository.NewAccountRepository(db))
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	txService := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	now := time.Date(2025, time.March, 15, 12, 0, 0, 0, time.Local)
+	march := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local)
+	endOfMarch := march.AddDate(0, 1, 0).Add(-time.Second)
+
+	create := func(tx *models.Transaction) *models.Transaction {
+		require.NoError(t, txService.Create(t.Context(), tx))
+		return tx
+	}
+
+	// Without accounts there's nothing to break spending down by
+	create(fixtures.NewTransaction().WithCategory(food.ID).WithAmount(10).WithDate(now).Build())
+	spending, err := accounts.GetSpending(t.Context(), march, endOfMarch)
+	require.NoError(t, err)
+	assert.Empty(t, spending)
+
+	checking := &models.Account{Name: "Checking", Type: models.AccountTypeDebit, OpeningBalance: 1000}
+	visa := &models.Account{Name: "Visa", Type: models.AccountTypeCredit}
+	cash := &models.Account{Name: "Cash", Type: models.AccountTypeCash}
+	for _, account := range []*models.Account{checking, visa, cash} {
+		require.NoError(t, accounts.Create(t.Context(), account))
+	}
+
+	create(fixtures.NewTransaction().WithType(models.TransactionTypeIncome).WithCategory(salary.ID).WithAmount(2000).WithDate(now).Build())
+	create(fixtures.NewTransaction().WithType(models.TransactionTypeIncome).WithCategory(salary.ID).WithAmount(500).WithDate(now).WithAccount(checking.ID).Build())
+	create(fixtures.NewTransaction().WithCategory(food.ID).WithAmount(120).WithDate(now).WithAccount(visa.ID).Build())
+	create(fixtures.NewTransaction().WithCategory(food.ID).WithAmount(30).WithDate(now).WithAccount(visa.ID).Build())
+	refund := create(fixtures.NewTransaction().WithCategory(food.ID).WithAmount(20).WithDate(now).AsRefund().WithAccount(visa.ID).Build())
+	create(fixtures.NewTransaction().WithCategory(food.ID).WithAmount(400).WithDate(now.AddDate(0, -1, 0)).WithAccount(checking.ID).Build())
+	// Not yet paid, so in this month's spending but not the balance
+	create(fixtures.NewTransaction().WithCategory(food.ID).WithAmount(99).WithDate(now.AddDate(0, 0, 1)).WithAccount(checking.ID).Build())
+
+	orphan := fixtures.NewTransaction().WithCategory(food.ID).WithAmount(1).WithDate(now).WithAccount(999).Build()
+	assert.ErrorContains(t, txService.Create(t.Context(), orphan), "account not found")
+
+	balances, err := accounts.GetBalances(t.Context(), now)
+	require.NoError(t, err)
+	require.Len(t, balances, 4)
+	byName := make(map[string]*models.AccountSummary)
+	for _, balance := range balances {
+		byName[balance.Name()] = balance
+	}
+	assert.Equal(t, models.NoAccountName, balances[3].Name(), "unassigned transactions come last")
+	assert.Equal(t, 1100.0, byName["Checking"].Balance())
+	assert.Equal(t, -130.0, byName["Visa"].Balance())
+	assert.Equal(t, 0.0, byName["Cash"].Balance())
+	assert.Equal(t, 0, byName["Cash"].Count)
+	assert.Equal(t, 1990.0, byName[models.NoAccountName].Balance())
+
+	spending, err = accounts.GetSpending(t.Context(), march, endOfMarch)
+	require.NoError(t, err)
+	require.Len(t, spending, 3)
+	assert.Equal(t, "Visa", spending[0].Name())
+	assert.Equal(t, 130.0, spending[0].Expenses)
+	assert.Equal(t, "Checking", spending[1].Name())
+	assert.Equal(t, 99.0, spending[1].Expenses)
+	assert.Equal(t, models.NoAccountName, spending[2].Name())
+	assert.InDelta(t, 130.0/239*100, spending[0].Percentage, 0.001)
+
+	// Deleting an account keeps its transactions, without it
+	require.NoError(t, accounts.Delete(t.Context(), visa.ID))
+	found, err := txService.GetByID(t.Context(), refund.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found.AccountID)
+	assert.Error(t, accounts.Delete(t.Context(), visa.ID))
+}
+
+func TestAccountService_DeleteWithTransfers(t *testing.T) {
+	db := test.SetupTestDB(t)
+	accounts := NewAccountService(repository.NewAccountRepository(db))
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	txService := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+
+	checking := &models.Account{Name: "Checking", Type: models.AccountTypeDebit}
+	savings := &models.Account{Name: "Savings", Type: models.AccountTypeDebit}
+	for _, account := range []*models.Account{checking, savings} {
+		require.NoError(t, accounts.Create(t.Context(), account))
+	}
+	out, in, err := txService.CreateTransfer(t.Context(), checking.ID, savings.ID, 300, "USD", time.Now(), "Rainy day fund")
+	require.NoError(t, err)
+
+	// Both halves of the transfer keep their account
+	assert.ErrorContains(t, accounts.Delete(t.Context(), savings.ID), "cannot delete account with 1 transfers")
+	found, err := txService.GetByID(t.Context(), in.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.AccountID)
+	assert.Equal(t, savings.ID, *found.AccountID)
+
+	// Once the transfer is gone the account can go too
+	require.NoError(t, txService.Delete(t.Context(), out.ID))
+	require.NoError(t, accounts.Delete(t.Context(), savings.ID))
+}
@@ -0,0 +1,198 @@
+package service
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// EvalAmount evaluates an amount typed as a simple arithmetic expression,
+// such as "12.50 + 3.99 * 2". It supports +, -, *, / (and the −, ×, ÷
+// signs), parentheses and unary minus, with the usual precedence. Numbers
+// may use a decimal comma, as in "84,60 / 3".
+func EvalAmount(s string) (float64, error) {
+	p := &amountParser{input: []rune(strings.TrimSpace(s))}
+	if len(p.input) == 0 {
+		return 0, fmt.Errorf("amount is required")
+	}
+
+	value, err := p.parseExpr()
+	if err != nil {
+		return 0, err
+	}
+	p.skipSpaces()
+	if p.pos < len(p.input) {
+		return 0, fmt.Errorf("unexpected %q", string(p.input[p.pos]))
+	}
+	if math.IsInf(value, 0) || math.IsNaN(value) {
+		return 0, fmt.Errorf("amount is too large")
+	}
+	return value, nil
+}
+
+// ParseAmount evaluates an amount like EvalAmount, rejecting results that
+// aren't positive, as amounts must be
+func ParseAmount(s string) (float64, error) {
+	value, err := EvalAmount(s)
+	if err != nil {
+		return 0, err
+	}
+	if value <= 0 {
+		return 0, fmt.Errorf("amount must be positive, got %s", strconv.FormatFloat(value, 'f', -1, 64))
+	}
+	return value, nil
+}
+
+// IsAmountExpression reports whether s is more than a plain number, so
+// forms know when an evaluated result is worth showing
+func IsAmountExpression(s string) bool {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return false
+	}
+	_, err := strconv.ParseFloat(s, 64)
+	return err != nil
+}
+
+type amountParser struct {
+	input []rune
+	pos   int
+}
+
+func (p *amountParser) skipSpaces() {
+	for p.pos < len(p.input) && p.input[p.pos] == ' ' {
+		p.pos++
+	}
+}
+
+// peek returns the next operator or digit, normalising the typographic
+// operator signs to their ASCII forms
+func (p *amountParser) peek() rune {
+	p.skipSpaces()
+	if p.pos >= len(p.input) {
+		return 0
+	}
+	switch r := p.input[p.pos]; r {
+	case '−':
+		return '-'
+	case '×', 'x':
+		return '*'
+	case '÷':
+		return '/'
+	default:
+		return r
+	}
+}
+
+// parseExpr handles addition and subtraction
+func (p *amountParser) parseExpr() (float64, error) {
+	value, err := p.parseTerm()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '+' && op != '-' {
+			return value, nil
+		}
+		p.pos++
+		rhs, err := p.parseTerm()
+		if err != nil {
+			return 0, err
+		}
+		if op == '+' {
+			value += rhs
+		} else {
+			value -= rhs
+		}
+	}
+}
+
+// parseTerm handles multiplication and division
+func (p *amountParser) parseTerm() (float64, error) {
+	value, err := p.parseFactor()
+	if err != nil {
+		return 0, err
+	}
+	for {
+		op := p.peek()
+		if op != '*' && op != '/' {
+			return value, nil
+		}
+		p.pos++
+		rhs, err := p.parseFactor()
+		if err != nil {
+			return 0, err
+		}
+		if op == '*' {
+			value *= rhs
+		} else {
+			if rhs == 0 {
+				return 0, fmt.Errorf("division by zero")
+			}
+			value /= rhs
+		}
+	}
+}
+
+// parseFactor handles numbers, parenthesised expressions and unary signs
+func (p *amountParser) parseFactor() (float64, error) {
+	switch p.peek() {
+	case 0:
+		return 0, fmt.Errorf("incomplete expression")
+	case '-':
+		p.pos++
+		value, err := p.parseFactor()
+		return -value, err
+	case '+':
+		p.pos++
+		return p.parseFactor()
+	case '(':
+		p.pos++
+		value, err := p.parseExpr()
+		if err != nil {
+			return 0, err
+		}
+		if p.peek() != ')' {
+			return 0, fmt.Errorf("missing closing parenthesis")
+		}
+		p.pos++
+		return value, nil
+	}
+
+	start := p.pos
+	for p.pos < len(p.input) && (p.input[p.pos] >= '0' && p.input[p.pos] <= '9' || p.input[p.pos] == '.' || p.input[p.pos] == ',') {
+		p.pos++
+	}
+	if start == p.pos {
+		return 0, fmt.Errorf("unexpected %q", string(p.input[p.pos]))
+	}
+	number := normalizeDecimal(string(p.input[start:p.pos]))
+	value, err := strconv.ParseFloat(number, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid number %q", string(p.input[start:p.pos]))
+	}
+	return value, nil
+}
+
+// normalizeDecimal turns a number written with commas into one
+// strconv.ParseFloat reads. With both separators the last one is the
+// decimal point ("1,250.00", "1.250,00"). A lone comma is a decimal comma
+// ("84,60", "1,5") unless exactly three digits follow it, as in "1,250",
+// and several commas separate thousands ("1,250,000").
+func normalizeDecimal(number string) string {
+	comma, dot := strings.LastIndex(number, ","), strings.LastIndex(number, ".")
+	switch {
+	case comma < 0:
+		return number
+	case dot > comma:
+		return strings.ReplaceAll(number, ",", "")
+	case dot >= 0:
+		return strings.ReplaceAll(strings.ReplaceAll(number, ".", ""), ",", ".")
+	case strings.Count(number, ",") > 1 || len(number)-comma-1 == 3:
+		return strings.ReplaceAll(number, ",", "")
+	default:
+		return strings.Replace(number, ",", ".", 1)
+	}
+}
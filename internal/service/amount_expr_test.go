@@ -0,0 +1,66 @@
+package service
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestEvalAmount(t *testing.T) {
+	for input, want := range map[string]float64{
+		"42":             42,
+		"84.60/3":        28.2,
+		"12+4.5*2":       21,
+		"(12 + 4.5) * 2": 33,
+		"10 − 2 × 3 ÷ 4": 8.5,
+		"-5 + 20":        15,
+		"1,250.00 + 50":  1300,
+		"1.250,50":       1250.5,
+		"1,250,000":      1250000,
+		"84,60 / 3":      28.2,
+		"12,5 * 2":       25,
+		"1,5":            1.5,
+		"1,250":          1250,
+		"1,2500":         1.25,
+	} {
+		got, err := EvalAmount(input)
+		require.NoError(t, err, input)
+		assert.InDelta(t, want, got, 1e-9, input)
+	}
+
+	t.Run("invalid expressions", func(t *testing.T) {
+		for input, want := range map[string]string{
+			"":          "amount is required",
+			"84.60/0":   "division by zero",
+			"10/(5-5)":  "division by zero",
+			"12+":       "incomplete expression",
+			"(12+3":     "missing closing parenthesis",
+			"12 apples": `unexpected "a"`,
+			"1.2.3":     `invalid number "1.2.3"`,
+			// Too large for a float64 once multiplied
+			"1" + strings.Repeat("0", 300) + " * 1" + strings.Repeat("0", 300): "amount is too large",
+		} {
+			_, err := EvalAmount(input)
+			assert.ErrorContains(t, err, want, input)
+		}
+	})
+}
+
+func TestParseAmount(t *testing.T) {
+	amount, err := ParseAmount("84,60/3")
+	require.NoError(t, err)
+	assert.InDelta(t, 28.2, amount, 1e-9)
+
+	// Amounts must come out positive
+	_, err = ParseAmount("5 - 12")
+	assert.ErrorContains(t, err, "amount must be positive, got -7")
+	_, err = ParseAmount("3 - 3")
+	assert.ErrorContains(t, err, "amount must be positive")
+
+	assert.True(t, IsAmountExpression("84,60"))
+	assert.True(t, IsAmountExpression("2*3"))
+	assert.False(t, IsAmountExpression("84.60"))
+	assert.False(t, IsAmountExpression(" "))
+}
@@ -1,7 +1,11 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"sort"
+	"strings"
 	"time"
 
 	"burnwise/internal/models"
@@ -9,8 +13,10 @@ import (
 )
 
 type BudgetService struct {
-	budgetRepo *repository.BudgetRepository
-	txRepo     *repository.TransactionRepository
+	budgetRepo       *repository.BudgetRepository
+	txRepo           *repository.TransactionRepository
+	undoService      *UndoService
+	recurringService *RecurringTransactionService
 }
 
 func NewBudgetService(budgetRepo *repository.BudgetRepository, txRepo *repository.TransactionRepository) *BudgetService {
@@ -20,63 +26,202 @@ func NewBudgetService(budgetRepo *repository.BudgetRepository, txRepo *repositor
 	}
 }
 
-func (s *BudgetService) Create(budget *models.Budget) error {
+func (s *BudgetService) SetUndoService(undoService *UndoService) {
+	s.undoService = undoService
+}
+
+// SetRecurringService enables projecting pending recurring expenses into
+// budget statuses. Without it projections equal what was spent.
+func (s *BudgetService) SetRecurringService(recurringService *RecurringTransactionService) {
+	s.recurringService = recurringService
+}
+
+func (s *BudgetService) Create(ctx context.Context, budget *models.Budget) error {
+	normalizeBudgetCategories(budget)
+
 	if err := budget.Validate(); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
-	existing, err := s.budgetRepo.GetActiveByCategoryAndPeriod(budget.CategoryID, budget.Period)
+	if err := s.checkExpenseCategories(ctx, budget); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	existing, err := s.findConflict(ctx, budget)
 	if err != nil {
 		return err
 	}
 
 	if existing != nil {
+		if budget.IsGroup() {
+			return fmt.Errorf("active budget already exists for these categories and period")
+		}
 		return fmt.Errorf("active budget already exists for this category and period")
 	}
 
-	return s.budgetRepo.Create(budget)
+	return s.budgetRepo.Create(ctx, budget)
 }
 
-func (s *BudgetService) Update(budget *models.Budget) error {
+func (s *BudgetService) Update(ctx context.Context, budget *models.Budget) error {
+	normalizeBudgetCategories(budget)
+
 	if err := budget.Validate(); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
-	existing, err := s.budgetRepo.GetActiveByCategoryAndPeriod(budget.CategoryID, budget.Period)
+	if err := s.checkExpenseCategories(ctx, budget); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	existing, err := s.findConflict(ctx, budget)
 	if err != nil {
 		return err
 	}
 
 	if existing != nil && existing.ID != budget.ID {
+		if budget.IsGroup() {
+			return fmt.Errorf("another active budget exists for these categories and period")
+		}
 		return fmt.Errorf("another active budget exists for this category and period")
 	}
 
-	return s.budgetRepo.Update(budget)
+	// Keep the old amount for the periods it applied to
+	old, err := s.budgetRepo.GetByID(ctx, budget.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get budget: %w", err)
+	}
+	if old.Amount != budget.Amount && !budget.IsPercentOfIncome() {
+		now := time.Now()
+		change := &models.BudgetAmountHistory{
+			BudgetID:      budget.ID,
+			OldAmount:     old.Amount,
+			NewAmount:     budget.Amount,
+			EffectiveFrom: budget.PeriodStartAt(now),
+			ChangedAt:     now,
+		}
+		return s.budgetRepo.UpdateWithAmountChange(ctx, budget, change)
+	}
+
+	return s.budgetRepo.Update(ctx, budget)
 }
 
-func (s *BudgetService) Delete(id uint) error {
-	_, err := s.budgetRepo.GetByID(id)
+// checkExpenseCategories makes sure every category the budget covers
+// exists and is an expense category. Budgets track spending, so one on an
+// income category would never see any.
+func (s *BudgetService) checkExpenseCategories(ctx context.Context, budget *models.Budget) error {
+	ids := budget.CategoryIDs()
+	categories, err := s.budgetRepo.GetCategories(ctx, ids)
+	if err != nil {
+		return fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	found := make(map[uint]bool, len(categories))
+	for _, category := range categories {
+		if category.Type != models.TransactionTypeExpense {
+			return fmt.Errorf("budgets can only track expense categories, but '%s' is an %s category", category.Name, category.Type)
+		}
+		found[category.ID] = true
+	}
+	for _, id := range ids {
+		if !found[id] {
+			return fmt.Errorf("category %d not found", id)
+		}
+	}
+	return nil
+}
+
+// normalizeBudgetCategories makes a group budget's first category its
+// primary CategoryID, and turns a one-category group into a plain budget
+func normalizeBudgetCategories(budget *models.Budget) {
+	switch len(budget.Categories) {
+	case 0:
+		return
+	case 1:
+		budget.CategoryID = budget.Categories[0].ID
+		budget.Categories = nil
+	default:
+		budget.CategoryID = budget.Categories[0].ID
+	}
+}
+
+// findConflict returns an active budget for the same period covering the
+// same categories. Single-category budgets only conflict with each other,
+// and group budgets only with groups spanning exactly the same categories.
+func (s *BudgetService) findConflict(ctx context.Context, budget *models.Budget) (*models.Budget, error) {
+	if !budget.IsGroup() {
+		return s.budgetRepo.GetActiveByCategoryAndPeriod(ctx, budget.CategoryID, budget.Period)
+	}
+
+	groups, err := s.budgetRepo.GetActiveGroupsByPeriod(ctx, budget.Period)
+	if err != nil {
+		return nil, err
+	}
+
+	wanted := make(map[uint]bool)
+	for _, id := range budget.CategoryIDs() {
+		wanted[id] = true
+	}
+
+	for _, group := range groups {
+		ids := group.CategoryIDs()
+		if len(ids) != len(wanted) {
+			continue
+		}
+		same := true
+		for _, id := range ids {
+			if !wanted[id] {
+				same = false
+				break
+			}
+		}
+		if same {
+			return group, nil
+		}
+	}
+
+	return nil, nil
+}
+
+func (s *BudgetService) Delete(ctx context.Context, id uint) error {
+	budget, err := s.budgetRepo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("budget not found: %w", err)
 	}
 
-	return s.budgetRepo.Delete(id)
+	if err := s.budgetRepo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	if s.undoService != nil {
+		s.undoService.Record(fmt.Sprintf("delete budget '%s'", budget.Name), func(ctx context.Context) error {
+			existing, err := s.findConflict(ctx, budget)
+			if err != nil {
+				return err
+			}
+			if existing != nil {
+				return fmt.Errorf("another active budget exists for this category and period")
+			}
+			return s.budgetRepo.Restore(ctx, id)
+		})
+	}
+
+	return nil
 }
 
-func (s *BudgetService) GetByID(id uint) (*models.Budget, error) {
-	return s.budgetRepo.GetByID(id)
+func (s *BudgetService) GetByID(ctx context.Context, id uint) (*models.Budget, error) {
+	return s.budgetRepo.GetByID(ctx, id)
 }
 
-func (s *BudgetService) GetAll() ([]*models.Budget, error) {
-	return s.budgetRepo.GetAll()
+func (s *BudgetService) GetAll(ctx context.Context) ([]*models.Budget, error) {
+	return s.budgetRepo.GetAll(ctx)
 }
 
-func (s *BudgetService) GetActive() ([]*models.Budget, error) {
-	return s.budgetRepo.GetActive()
+func (s *BudgetService) GetActive(ctx context.Context) ([]*models.Budget, error) {
+	return s.budgetRepo.GetActive(ctx)
 }
 
-func (s *BudgetService) GetStatus(budgetID uint) (*models.BudgetStatus, error) {
-	budget, err := s.budgetRepo.GetByID(budgetID)
+func (s *BudgetService) GetStatus(ctx context.Context, budgetID uint) (*models.BudgetStatus, error) {
+	budget, err := s.budgetRepo.GetByID(ctx, budgetID)
 	if err != nil {
 		return nil, err
 	}
@@ -84,7 +229,7 @@ func (s *BudgetService) GetStatus(budgetID uint) (*models.BudgetStatus, error) {
 	periodStart := budget.GetCurrentPeriodStart()
 	periodEnd := budget.GetCurrentPeriodEnd()
 
-	spent, err := s.budgetRepo.GetSpentAmount(budgetID, periodStart, periodEnd)
+	spent, err := s.budgetRepo.GetSpentAmount(ctx, budgetID, periodStart, periodEnd)
 	if err != nil {
 		return nil, err
 	}
@@ -93,17 +238,210 @@ func (s *BudgetService) GetStatus(budgetID uint) (*models.BudgetStatus, error) {
 		Budget: *budget,
 		Spent:  spent,
 	}
+	if err := s.applyIncomeShare(ctx, status); err != nil {
+		return nil, err
+	}
+	applyProration(status)
 	status.Calculate()
 
+	if err := s.setCommitted(ctx, status); err != nil {
+		return nil, err
+	}
 	return status, nil
 }
 
-func (s *BudgetService) GetAllStatuses() ([]*models.BudgetStatus, error) {
-	return s.budgetRepo.GetAllWithStatus()
+func (s *BudgetService) GetAllStatuses(ctx context.Context) ([]*models.BudgetStatus, error) {
+	statuses, err := s.budgetRepo.GetAllWithStatus(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := s.applyIncomeShare(ctx, statuses...); err != nil {
+		return nil, err
+	}
+	applyProration(statuses...)
+	for _, status := range statuses {
+		if status.Budget.IsPercentOfIncome() || status.Budget.ProrateFirstPeriod {
+			status.Calculate()
+		}
+	}
+
+	if err := s.setCommitted(ctx, statuses...); err != nil {
+		return nil, err
+	}
+	return statuses, nil
+}
+
+// applyIncomeShare sets the amount of each percent-of-income budget to its
+// share of this month's income so far
+func (s *BudgetService) applyIncomeShare(ctx context.Context, statuses ...*models.BudgetStatus) error {
+	var percent []*models.BudgetStatus
+	for _, status := range statuses {
+		if status.Budget.IsPercentOfIncome() {
+			percent = append(percent, status)
+		}
+	}
+	if len(percent) == 0 {
+		return nil
+	}
+
+	income, err := s.monthIncome(ctx, time.Now())
+	if err != nil {
+		return err
+	}
+	for _, status := range percent {
+		status.SetPeriodAmount(status.Budget.AmountFor(income))
+	}
+	return nil
+}
+
+// applyProration cuts the amount of each prorated budget still in its first
+// period down to the share of the period it covers
+func applyProration(statuses ...*models.BudgetStatus) {
+	for _, status := range statuses {
+		budget := &status.Budget
+		if budget.IsProratedIn(budget.GetCurrentPeriodStart()) {
+			status.SetPeriodAmount(budget.ProratedAmount(budget.Amount, budget.GetCurrentPeriodStart()))
+		}
+	}
+}
+
+// monthIncome returns the income of the month containing date, in USD
+func (s *BudgetService) monthIncome(ctx context.Context, date time.Time) (float64, error) {
+	start := time.Date(date.Year(), date.Month(), 1, 0, 0, 0, 0, date.Location())
+	summary, err := s.txRepo.GetSummary(ctx, start, start.AddDate(0, 1, 0).Add(-time.Second))
+	if err != nil {
+		return 0, fmt.Errorf("failed to get month's income: %w", err)
+	}
+	return summary.TotalIncome, nil
 }
 
-func (s *BudgetService) CheckOverspending(budgetID uint) (bool, float64, error) {
-	status, err := s.GetStatus(budgetID)
+// setCommitted fills in what the recurring expenses in each budget's
+// categories cost per period, given a recurring service
+func (s *BudgetService) setCommitted(ctx context.Context, statuses ...*models.BudgetStatus) error {
+	if s.recurringService == nil || len(statuses) == 0 {
+		return nil
+	}
+
+	committed, err := s.recurringService.GetMonthlyCommittedByCategory(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get committed recurring expenses: %w", err)
+	}
+
+	for _, status := range statuses {
+		var monthly float64
+		for _, categoryID := range status.Budget.CategoryIDs() {
+			monthly += committed[categoryID]
+		}
+		status.Committed = monthly
+		if status.Budget.Period == models.BudgetPeriodYearly {
+			status.Committed = monthly * 12
+		}
+	}
+	return nil
+}
+
+// GetStatusWithProjection returns the budget's status along with what the
+// current period is expected to end at, counting the recurring expenses in
+// its categories that are still due before the period ends
+func (s *BudgetService) GetStatusWithProjection(ctx context.Context, budgetID uint) (*models.BudgetProjection, error) {
+	status, err := s.GetStatus(ctx, budgetID)
+	if err != nil {
+		return nil, err
+	}
+	return s.project(ctx, status)
+}
+
+// GetAllStatusesWithProjection is GetAllStatuses with every status projected
+// like GetStatusWithProjection
+func (s *BudgetService) GetAllStatusesWithProjection(ctx context.Context) ([]*models.BudgetProjection, error) {
+	statuses, err := s.GetAllStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	projections := make([]*models.BudgetProjection, 0, len(statuses))
+	for _, status := range statuses {
+		projection, err := s.project(ctx, status)
+		if err != nil {
+			return nil, err
+		}
+		projections = append(projections, projection)
+	}
+	return projections, nil
+}
+
+func (s *BudgetService) project(ctx context.Context, status *models.BudgetStatus) (*models.BudgetProjection, error) {
+	projection := &models.BudgetProjection{BudgetStatus: *status}
+	if s.recurringService != nil {
+		pending, err := s.recurringService.GetPendingExpenses(ctx, 
+			status.Budget.CategoryIDs(),
+			status.Budget.GetCurrentPeriodStart(),
+			status.Budget.GetCurrentPeriodEnd())
+		if err != nil {
+			return nil, fmt.Errorf("failed to project budget '%s': %w", status.Budget.Name, err)
+		}
+		projection.PendingRecurring = pending
+	}
+	projection.Projected = projection.Spent + projection.PendingRecurring
+	projection.IsProjectedOver = projection.Projected > projection.Budget.Amount
+	return projection, nil
+}
+
+// monthlyLimitName names the overall monthly limit among daily allowances
+const monthlyLimitName = "Monthly limit"
+
+// GetDailyAllowance returns how much can be spent per day for the rest of
+// the period, (amount − spent) / days left, to stay within the overall
+// monthly limit when limit is set, followed by each of the budget statuses,
+// as returned by GetAllStatuses
+func (s *BudgetService) GetDailyAllowance(ctx context.Context, limit float64, statuses []*models.BudgetStatus) ([]*models.DailyAllowance, error) {
+	now := time.Now()
+	var allowances []*models.DailyAllowance
+	
+	if limit > 0 {
+		start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+		end := start.AddDate(0, 1, 0).Add(-time.Second)
+		summary, err := s.txRepo.GetSummary(ctx, start, end)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get month summary: %w", err)
+		}
+		allowance := newDailyAllowance(limit, summary.TotalExpenses, end, now)
+		allowance.Name = monthlyLimitName
+		allowances = append(allowances, allowance)
+	}
+	
+	for _, status := range statuses {
+		allowance := newDailyAllowance(status.Budget.Amount, status.Spent, status.Budget.GetCurrentPeriodEnd(), now)
+		allowance.BudgetID = status.Budget.ID
+		allowance.Name = status.Budget.Name
+		allowances = append(allowances, allowance)
+	}
+	
+	return allowances, nil
+}
+
+// newDailyAllowance spreads what is left of amount over the days from now
+// to end, both included
+func newDailyAllowance(amount, spent float64, end, now time.Time) *models.DailyAllowance {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	last := time.Date(end.Year(), end.Month(), end.Day(), 0, 0, 0, 0, now.Location())
+	// Rounded, as a day across a DST change is not 24 hours long
+	days := int(math.Round(last.Sub(today).Hours()/24)) + 1
+	if days < 1 {
+		days = 1
+	}
+	
+	return &models.DailyAllowance{
+		Amount:   amount,
+		Spent:    spent,
+		DaysLeft: days,
+		PerDay:   models.RoundUSD((amount - spent) / float64(days)),
+	}
+}
+
+func (s *BudgetService) CheckOverspending(ctx context.Context, budgetID uint) (bool, float64, error) {
+	status, err := s.GetStatus(ctx, budgetID)
 	if err != nil {
 		return false, 0, err
 	}
@@ -116,52 +454,312 @@ func (s *BudgetService) CheckOverspending(budgetID uint) (bool, float64, error)
 	return false, 0, nil
 }
 
-func (s *BudgetService) GetCategoryBudgetStatus(categoryID uint) (*models.BudgetStatus, error) {
-	monthlyBudget, _ := s.budgetRepo.GetActiveByCategoryAndPeriod(categoryID, models.BudgetPeriodMonthly)
-	yearlyBudget, _ := s.budgetRepo.GetActiveByCategoryAndPeriod(categoryID, models.BudgetPeriodYearly)
+// GetOverThreshold returns the active budgets with at least warn percent of
+// their amount spent this period, the most used up first
+func (s *BudgetService) GetOverThreshold(ctx context.Context, warn float64) ([]*models.BudgetStatus, error) {
+	statuses, err := s.GetAllStatuses(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	var over []*models.BudgetStatus
+	for _, status := range statuses {
+		if status.PercentUsed >= warn {
+			over = append(over, status)
+		}
+	}
+	sort.SliceStable(over, func(i, j int) bool { return over[i].PercentUsed > over[j].PercentUsed })
+	return over, nil
+}
+
+func (s *BudgetService) GetCategoryBudgetStatus(ctx context.Context, categoryID uint) (*models.BudgetStatus, error) {
+	monthlyBudget, _ := s.budgetRepo.GetActiveByCategoryAndPeriod(ctx, categoryID, models.BudgetPeriodMonthly)
+	yearlyBudget, _ := s.budgetRepo.GetActiveByCategoryAndPeriod(ctx, categoryID, models.BudgetPeriodYearly)
 
 	if monthlyBudget != nil {
-		return s.GetStatus(monthlyBudget.ID)
+		return s.GetStatus(ctx, monthlyBudget.ID)
 	}
 
 	if yearlyBudget != nil {
-		return s.GetStatus(yearlyBudget.ID)
+		return s.GetStatus(ctx, yearlyBudget.ID)
 	}
 
 	return nil, fmt.Errorf("no active budget found for category")
 }
 
-func (s *BudgetService) GetBudgetProgress() (map[uint]*models.BudgetStatus, error) {
-	statuses, err := s.GetAllStatuses()
+func (s *BudgetService) GetBudgetProgress(ctx context.Context) (map[uint]*models.BudgetStatus, error) {
+	statuses, err := s.GetAllStatuses(ctx)
 	if err != nil {
 		return nil, err
 	}
 
 	progressMap := make(map[uint]*models.BudgetStatus)
 	for _, status := range statuses {
+		// Group budgets are not tied to a single category
+		if status.Budget.IsGroup() {
+			continue
+		}
 		progressMap[status.Budget.CategoryID] = status
 	}
 
 	return progressMap, nil
 }
 
-func (s *BudgetService) CreateMonthlyBudgets(budgets map[uint]float64) error {
-	now := time.Now()
+// budgetProposalStep is what proposed budgets are rounded up to
+const budgetProposalStep = 10
+
+// ProposeMonthlyBudgets suggests a monthly budget for every expense category
+// with spending in the month before now, rounded up to the next
+// budgetProposalStep. Categories already covered by an active monthly
+// budget, on their own or in a group, are left out.
+func (s *BudgetService) ProposeMonthlyBudgets(ctx context.Context, now time.Time) ([]*models.BudgetProposal, error) {
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location()).AddDate(0, -1, 0)
+	end := start.AddDate(0, 1, 0).Add(-time.Second)
+
+	spending, err := s.txRepo.GetCategorySummary(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last month's spending: %w", err)
+	}
+
+	active, err := s.budgetRepo.GetActiveAt(ctx, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active budgets: %w", err)
+	}
+	budgeted := make(map[uint]bool)
+	for _, budget := range active {
+		if budget.Period != models.BudgetPeriodMonthly {
+			continue
+		}
+		for _, id := range budget.CategoryIDs() {
+			budgeted[id] = true
+		}
+	}
+
+	var proposals []*models.BudgetProposal
+	for _, category := range spending {
+		if category.Type != models.TransactionTypeExpense || category.Total <= 0 || budgeted[category.ID] {
+			continue
+		}
+		proposals = append(proposals, &models.BudgetProposal{
+			Category:  category.Category,
+			LastMonth: category.Total,
+			Amount:    math.Ceil(category.Total/budgetProposalStep) * budgetProposalStep,
+		})
+	}
+
+	return proposals, nil
+}
+
+const (
+	// suggestionPeriods is how many completed periods in a row a budget has
+	// to be over or well under before an adjustment is suggested
+	suggestionPeriods = 3
+	// suggestionUnderPercent is the share of a budget below which spending
+	// counts as well under it
+	suggestionUnderPercent = 60
+)
+
+// SuggestAdjustments looks at the last suggestionPeriods completed periods
+// of every active budget, each against the amount the budget had then. A
+// budget that was over its amount in each of them is suggested to be
+// raised, and one that stayed below suggestionUnderPercent of it to be
+// lowered, in both cases to the highest of those periods' spending rounded
+// up to the next budgetProposalStep, unless it already is that high or low.
+// Periods before the budget started don't count, so a budget needs that
+// much history first.
+func (s *BudgetService) SuggestAdjustments(ctx context.Context) ([]*models.BudgetSuggestion, error) {
+	budgets, err := s.budgetRepo.GetActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active budgets: %w", err)
+	}
+
+	var suggestions []*models.BudgetSuggestion
+	for _, budget := range budgets {
+		suggestion, err := s.suggestAdjustment(ctx, budget, time.Now())
+		if err != nil {
+			return nil, err
+		}
+		if suggestion != nil {
+			suggestions = append(suggestions, suggestion)
+		}
+	}
+	return suggestions, nil
+}
+
+func (s *BudgetService) suggestAdjustment(ctx context.Context, budget *models.Budget, now time.Time) (*models.BudgetSuggestion, error) {
+	// A percent-of-income budget already follows the income; a fixed
+	// amount to move it to would be no suggestion for it
+	if budget.IsPercentOfIncome() {
+		return nil, nil
+	}
+	
+	first := budget.AddPeriods(budget.PeriodStartAt(now), -suggestionPeriods)
+	if first.Before(budget.PeriodStartAt(budget.StartDate)) {
+		return nil, nil
+	}
+
+	spentPerPeriod, err := s.budgetRepo.GetSpentPerPeriod(ctx, budget.ID, first, suggestionPeriods)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spending for budget '%s': %w", budget.Name, err)
+	}
+	changes, err := s.budgetRepo.GetAmountHistory(ctx, budget.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get amount history of budget '%s': %w", budget.Name, err)
+	}
+
+	// Each period is measured against the amount it had
+	suggestion := &models.BudgetSuggestion{Budget: *budget, Spent: spentPerPeriod}
+	var over, under int
+	var highest float64
+	for i, spent := range spentPerPeriod {
+		amount := budget.AmountAt(changes, budget.AddPeriods(first, i))
+		highest = math.Max(highest, spent)
+		if spent > amount {
+			over++
+		} else if spent < amount*suggestionUnderPercent/100 {
+			under++
+		}
+	}
+
+	suggestion.Amount = math.Ceil(highest/budgetProposalStep) * budgetProposalStep
+	switch {
+	case over == suggestionPeriods && suggestion.Amount > budget.Amount:
+		suggestion.Over = true
+	case under == suggestionPeriods && suggestion.Amount > 0 && suggestion.Amount < budget.Amount:
+	default:
+		return nil, nil
+	}
+	return suggestion, nil
+}
+
+// GetHistory returns the amount the budget had against its spending in each
+// of its last periods periods, oldest first and ending with the current one,
+// which is marked as in progress. Periods before the budget started are
+// left out.
+func (s *BudgetService) GetHistory(ctx context.Context, budgetID uint, periods int) ([]*models.BudgetPeriodSpending, error) {
+	budget, err := s.budgetRepo.GetByID(ctx, budgetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget: %w", err)
+	}
+	return s.history(ctx, budget, periods, time.Now())
+}
+
+func (s *BudgetService) history(ctx context.Context, budget *models.Budget, periods int, now time.Time) ([]*models.BudgetPeriodSpending, error) {
+	current := budget.PeriodStartAt(now)
+	first := budget.AddPeriods(current, 1-periods)
+	started := budget.PeriodStartAt(budget.StartDate)
+	for periods > 1 && first.Before(started) {
+		first = budget.AddPeriods(first, 1)
+		periods--
+	}
+
+	spentPerPeriod, err := s.budgetRepo.GetSpentPerPeriod(ctx, budget.ID, first, periods)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get spending for budget '%s': %w", budget.Name, err)
+	}
+	changes, err := s.budgetRepo.GetAmountHistory(ctx, budget.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get amount history of budget '%s': %w", budget.Name, err)
+	}
+
+	history := make([]*models.BudgetPeriodSpending, periods)
+	for i, spent := range spentPerPeriod {
+		start := budget.AddPeriods(first, i)
+		budgeted := budget.AmountAt(changes, start)
+		if budget.IsPercentOfIncome() {
+			income, err := s.monthIncome(ctx, start)
+			if err != nil {
+				return nil, err
+			}
+			budgeted = budget.AmountFor(income)
+		}
+		budgeted = budget.ProratedAmount(budgeted, start)
+		history[i] = &models.BudgetPeriodSpending{
+			Start:      start,
+			End:        budget.AddPeriods(start, 1).Add(-time.Second),
+			Budgeted:   budgeted,
+			Spent:      spent,
+			InProgress: start.Equal(current),
+		}
+	}
+	return history, nil
+}
+
+// GetStatusesAt returns the status of every budget that ran in the period
+// containing date, as it stood for that period rather than the current one:
+// what was spent in it against what the budget allowed then. Statuses of
+// past periods have no committed recurring expenses.
+func (s *BudgetService) GetStatusesAt(ctx context.Context, date time.Time) ([]*models.BudgetStatus, error) {
+	budgets, err := s.budgetRepo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budgets: %w", err)
+	}
+
+	var statuses []*models.BudgetStatus
+	for _, budget := range budgets {
+		start := budget.PeriodStartAt(date)
+		if start.Before(budget.PeriodStartAt(budget.StartDate)) || (budget.EndDate != nil && budget.EndDate.Before(start)) {
+			continue
+		}
+
+		history, err := s.history(ctx, budget, 1, date)
+		if err != nil {
+			return nil, err
+		}
+		status := &models.BudgetStatus{Budget: *budget, Spent: history[0].Spent}
+		status.SetPeriodAmount(history[0].Budgeted)
+		status.Calculate()
+		statuses = append(statuses, status)
+	}
+	return statuses, nil
+}
+
+// CreateMonthlyBudgets creates a monthly budget named after each category,
+// starting in now's month. A category that fails doesn't stop the others:
+// the budgets that were created are returned along with an error naming
+// the rest.
+func (s *BudgetService) CreateMonthlyBudgets(ctx context.Context, budgets map[uint]float64, now time.Time) ([]*models.Budget, error) {
 	startDate := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 
-	for categoryID, amount := range budgets {
+	ids := make([]uint, 0, len(budgets))
+	for categoryID := range budgets {
+		ids = append(ids, categoryID)
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+
+	categories, err := s.budgetRepo.GetCategories(ctx, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+	names := make(map[uint]string, len(categories))
+	for _, category := range categories {
+		names[category.ID] = category.Name
+	}
+
+	var created []*models.Budget
+	var failures []string
+	for _, categoryID := range ids {
+		name, ok := names[categoryID]
+		if !ok {
+			name = fmt.Sprintf("category %d", categoryID)
+		}
 		budget := &models.Budget{
-			Name:       fmt.Sprintf("Monthly Budget - %s %d", now.Month().String(), now.Year()),
+			Name:       name,
 			CategoryID: categoryID,
-			Amount:     amount,
+			Amount:     budgets[categoryID],
 			Period:     models.BudgetPeriodMonthly,
 			StartDate:  startDate,
 		}
 
-		if err := s.Create(budget); err != nil {
-			return err
+		if err := s.Create(ctx, budget); err != nil {
+			failures = append(failures, fmt.Sprintf("%s: %v", name, err))
+			continue
 		}
+		created = append(created, budget)
 	}
 
-	return nil
+	if len(failures) > 0 {
+		return created, fmt.Errorf("failed to create %d of %d budgets: %s", len(failures), len(ids), strings.Join(failures, "; "))
+	}
+	return created, nil
 }
\ No newline at end of file
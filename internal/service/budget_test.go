@@ -1,6 +1,8 @@
 package service
 
 import (
+	"net/http"
+	"net/http/httptest"
 	"testing"
 	"time"
 
@@ -28,7 +30,7 @@ func TestBudgetService_Create(t *testing.T) {
 		StartDate:  time.Now(),
 	}
 	
-	err := service.Create(budget)
+	err := service.Create(t.Context(), budget)
 	require.NoError(t, err)
 	assert.Greater(t, budget.ID, uint(0))
 	
@@ -41,11 +43,51 @@ func TestBudgetService_Create(t *testing.T) {
 		StartDate:  time.Now(),
 	}
 	
-	err = service.Create(duplicate)
+	err = service.Create(t.Context(), duplicate)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "active budget already exists")
 }
 
+func TestBudgetService_RejectsIncomeCategories(t *testing.T) {
+	db := test.SetupTestDB(t)
+	service := NewBudgetService(repository.NewBudgetRepository(db), repository.NewTransactionRepository(db))
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	
+	budget := &models.Budget{
+		Name:       "Salary Budget",
+		CategoryID: salary.ID,
+		Amount:     500.00,
+		Period:     models.BudgetPeriodMonthly,
+		StartDate:  time.Now(),
+	}
+	err := service.Create(t.Context(), budget)
+	assert.ErrorContains(t, err, "budgets can only track expense categories, but 'Salary' is an income category")
+	
+	// Groups are checked category by category
+	group := &models.Budget{
+		Name:       "Mixed",
+		Amount:     500.00,
+		Period:     models.BudgetPeriodMonthly,
+		StartDate:  time.Now(),
+		Categories: []models.Category{*food, *salary},
+	}
+	err = service.Create(t.Context(), group)
+	assert.ErrorContains(t, err, "'Salary' is an income category")
+	
+	// Updates are checked too
+	budget.CategoryID = food.ID
+	require.NoError(t, service.Create(t.Context(), budget))
+	budget.CategoryID = salary.ID
+	err = service.Update(t.Context(), budget)
+	assert.ErrorContains(t, err, "expense categories")
+	
+	budget.CategoryID = 9999
+	err = service.Update(t.Context(), budget)
+	assert.ErrorContains(t, err, "category 9999 not found")
+}
+
 func TestBudgetService_GetStatus(t *testing.T) {
 	db := test.SetupTestDB(t)
 	budgetRepo := repository.NewBudgetRepository(db)
@@ -72,7 +114,7 @@ func TestBudgetService_GetStatus(t *testing.T) {
 	}
 	
 	// Get status
-	status, err := service.GetStatus(budget.ID)
+	status, err := service.GetStatus(t.Context(), budget.ID)
 	require.NoError(t, err)
 	
 	assert.Equal(t, 600.00, status.Spent)
@@ -81,6 +123,186 @@ func TestBudgetService_GetStatus(t *testing.T) {
 	assert.False(t, status.IsOverBudget)
 }
 
+func TestBudgetService_GetStatusWithProjection(t *testing.T) {
+	db := test.SetupTestDB(t)
+	budgetRepo := repository.NewBudgetRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+	recurringRepo := repository.NewRecurringTransactionRepository(db)
+	service := NewBudgetService(budgetRepo, txRepo)
+	
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	recurringService := NewRecurringTransactionService(recurringRepo, txRepo, NewCurrencyService(settingsService))
+	
+	category := test.CreateTestCategory(t, db, "Subscriptions", models.TransactionTypeExpense)
+	other := test.CreateTestCategory(t, db, "Rent", models.TransactionTypeExpense)
+	budget := test.CreateTestBudget(t, db, category.ID, 600.00)
+	test.CreateTestTransaction(t, db, 300.00, category.ID)
+	
+	periodStart := budget.GetCurrentPeriodStart()
+	lastDay := budget.GetCurrentPeriodEnd().Add(-time.Hour)
+	newRule := func(categoryID uint, amount float64, due time.Time) *models.RecurringTransaction {
+		rt := &models.RecurringTransaction{
+			Type:           models.TransactionTypeExpense,
+			Amount:         amount,
+			Currency:       "USD",
+			CategoryID:     categoryID,
+			Description:    "Rule",
+			Frequency:      models.FrequencyYearly,
+			FrequencyValue: 1,
+			StartDate:      periodStart,
+			NextDueDate:    due,
+			IsActive:       true,
+		}
+		require.NoError(t, recurringRepo.Create(t.Context(), rt))
+		return rt
+	}
+	newRule(category.ID, 200, lastDay)
+	newRule(category.ID, 75, lastDay.AddDate(0, 0, 2)) // due next period
+	newRule(other.ID, 1500, lastDay)                   // other category
+	skipped := newRule(category.ID, 40, lastDay)
+	require.NoError(t, recurringService.SkipOccurrence(t.Context(), skipped.ID, lastDay, "cancelled"))
+	
+	// Without the recurring service nothing is projected
+	projection, err := service.GetStatusWithProjection(t.Context(), budget.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 300.0, projection.Spent)
+	assert.Equal(t, 300.0, projection.Projected)
+	
+	service.SetRecurringService(recurringService)
+	projection, err = service.GetStatusWithProjection(t.Context(), budget.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 300.0, projection.Spent)
+	assert.Equal(t, 200.0, projection.PendingRecurring)
+	assert.Equal(t, 500.0, projection.Projected)
+	assert.False(t, projection.IsProjectedOver)
+	
+	newRule(category.ID, 150, lastDay)
+	projections, err := service.GetAllStatusesWithProjection(t.Context())
+	require.NoError(t, err)
+	require.Len(t, projections, 1)
+	assert.Equal(t, 650.0, projections[0].Projected)
+	assert.True(t, projections[0].IsProjectedOver)
+	assert.False(t, projections[0].IsOverBudget)
+}
+
+func TestBudgetService_GetStatusWithRefund(t *testing.T) {
+	db := test.SetupTestDB(t)
+	budgetRepo := repository.NewBudgetRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+	service := NewBudgetService(budgetRepo, txRepo)
+	
+	category := test.CreateTestCategory(t, db, "Shopping", models.TransactionTypeExpense)
+	budget := test.CreateTestBudget(t, db, category.ID, 500.00)
+	
+	test.CreateTestTransaction(t, db, 300.00, category.ID)
+	
+	refund := &models.Transaction{
+		Type:        models.TransactionTypeExpense,
+		IsRefund:    true,
+		Amount:      120.00,
+		Currency:    "USD",
+		AmountUSD:   120.00,
+		CategoryID:  category.ID,
+		Description: "Returned jacket",
+		Date:        time.Now(),
+	}
+	require.NoError(t, db.Create(refund).Error)
+	
+	status, err := service.GetStatus(t.Context(), budget.ID)
+	require.NoError(t, err)
+	
+	assert.Equal(t, 180.00, status.Spent)
+	assert.Equal(t, 320.00, status.Remaining)
+}
+
+func TestBudgetService_GroupBudget(t *testing.T) {
+	db := test.SetupTestDB(t)
+	budgetRepo := repository.NewBudgetRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+	service := NewBudgetService(budgetRepo, txRepo)
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	dining := test.CreateTestCategory(t, db, "Dining", models.TransactionTypeExpense)
+	transport := test.CreateTestCategory(t, db, "Transport", models.TransactionTypeExpense)
+	
+	group := &models.Budget{
+		Name:       "Eating",
+		Categories: []models.Category{*food, *dining},
+		Amount:     500.00,
+		Period:     models.BudgetPeriodMonthly,
+		StartDate:  time.Now().AddDate(0, 0, -1),
+	}
+	require.NoError(t, service.Create(t.Context(), group))
+	assert.Equal(t, food.ID, group.CategoryID)
+	
+	test.CreateTestTransaction(t, db, 100.00, food.ID)
+	test.CreateTestTransaction(t, db, 50.00, dining.ID)
+	test.CreateTestTransaction(t, db, 75.00, transport.ID)
+	
+	status, err := service.GetStatus(t.Context(), group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 150.00, status.Spent)
+	assert.True(t, status.Budget.IsGroup())
+	assert.Equal(t, "Food + Dining", status.Budget.CategoryLabel())
+	
+	// A single budget for a grouped category does not conflict with the group
+	single := &models.Budget{
+		Name:       "Food Only",
+		CategoryID: food.ID,
+		Amount:     200.00,
+		Period:     models.BudgetPeriodMonthly,
+		StartDate:  time.Now().AddDate(0, 0, -1),
+	}
+	require.NoError(t, service.Create(t.Context(), single))
+	
+	status, err = service.GetStatus(t.Context(), single.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 100.00, status.Spent)
+	
+	// The single-category duplicate check still applies
+	err = service.Create(t.Context(), &models.Budget{
+		Name:       "Food Again",
+		CategoryID: food.ID,
+		Amount:     300.00,
+		Period:     models.BudgetPeriodMonthly,
+		StartDate:  time.Now().AddDate(0, 0, -1),
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "active budget already exists for this category")
+	
+	// A group over the same categories is a duplicate regardless of order
+	err = service.Create(t.Context(), &models.Budget{
+		Name:       "Eating Again",
+		Categories: []models.Category{*dining, *food},
+		Amount:     600.00,
+		Period:     models.BudgetPeriodMonthly,
+		StartDate:  time.Now().AddDate(0, 0, -1),
+	})
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "active budget already exists for these categories")
+	
+	// A group over a different set is allowed
+	wider := &models.Budget{
+		Name:       "Everything",
+		Categories: []models.Category{*food, *dining, *transport},
+		Amount:     900.00,
+		Period:     models.BudgetPeriodMonthly,
+		StartDate:  time.Now().AddDate(0, 0, -1),
+	}
+	require.NoError(t, service.Create(t.Context(), wider))
+	
+	// Shrinking a group to one category turns it into a plain budget
+	wider.Categories = []models.Category{*transport}
+	require.NoError(t, service.Update(t.Context(), wider))
+	
+	reloaded, err := service.GetByID(t.Context(), wider.ID)
+	require.NoError(t, err)
+	assert.False(t, reloaded.IsGroup())
+	assert.Equal(t, transport.ID, reloaded.CategoryID)
+	assert.Empty(t, reloaded.Categories)
+}
+
 func TestBudgetService_CheckOverspending(t *testing.T) {
 	db := test.SetupTestDB(t)
 	budgetRepo := repository.NewBudgetRepository(db)
@@ -105,13 +327,45 @@ func TestBudgetService_CheckOverspending(t *testing.T) {
 	require.NoError(t, db.Create(tx).Error)
 	
 	// Check overspending
-	isOver, amount, err := service.CheckOverspending(budget.ID)
+	isOver, amount, err := service.CheckOverspending(t.Context(), budget.ID)
 	require.NoError(t, err)
 	
 	assert.True(t, isOver)
 	assert.Equal(t, 50.00, amount)
 }
 
+func TestBudgetService_GetOverThreshold(t *testing.T) {
+	db := test.SetupTestDB(t)
+	budgetRepo := repository.NewBudgetRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+	service := NewBudgetService(budgetRepo, txRepo)
+
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	transport := test.CreateTestCategory(t, db, "Transport", models.TransactionTypeExpense)
+	fun := test.CreateTestCategory(t, db, "Fun", models.TransactionTypeExpense)
+
+	test.CreateTestBudget(t, db, food.ID, 100.00)
+	test.CreateTestBudget(t, db, transport.ID, 100.00)
+	test.CreateTestBudget(t, db, fun.ID, 100.00)
+
+	test.CreateTestTransaction(t, db, 85.00, food.ID)
+	test.CreateTestTransaction(t, db, 120.00, transport.ID)
+	test.CreateTestTransaction(t, db, 40.00, fun.ID)
+
+	over, err := service.GetOverThreshold(t.Context(), 80)
+	require.NoError(t, err)
+	require.Len(t, over, 2)
+
+	// The most used up first
+	assert.Equal(t, transport.ID, over[0].Budget.CategoryID)
+	assert.Equal(t, models.AlertCritical, over[0].AlertLevel(80, 100))
+	assert.Equal(t, food.ID, over[1].Budget.CategoryID)
+	assert.Equal(t, models.AlertWarning, over[1].AlertLevel(80, 100))
+
+	// A higher critical threshold makes the blown budget only a warning
+	assert.Equal(t, models.AlertWarning, over[0].AlertLevel(80, 150))
+}
+
 func TestBudgetService_GetAllStatuses(t *testing.T) {
 	db := test.SetupTestDB(t)
 	budgetRepo := repository.NewBudgetRepository(db)
@@ -130,7 +384,7 @@ func TestBudgetService_GetAllStatuses(t *testing.T) {
 	test.CreateTestTransaction(t, db, 50.00, cat2.ID)
 	
 	// Get all statuses
-	statuses, err := service.GetAllStatuses()
+	statuses, err := service.GetAllStatuses(t.Context())
 	require.NoError(t, err)
 	
 	assert.Len(t, statuses, 2)
@@ -142,4 +396,536 @@ func TestBudgetService_GetAllStatuses(t *testing.T) {
 	// Check second budget status
 	assert.Equal(t, 50.00, statuses[1].Spent)
 	assert.InDelta(t, 16.67, statuses[1].PercentUsed, 0.01)
-}
\ No newline at end of file
+}
+
+func TestBudgetService_GetDailyAllowance(t *testing.T) {
+	db := test.SetupTestDB(t)
+	service := NewBudgetService(repository.NewBudgetRepository(db), repository.NewTransactionRepository(db))
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	fun := test.CreateTestCategory(t, db, "Fun", models.TransactionTypeExpense)
+	foodBudget := test.CreateTestBudget(t, db, food.ID, 600.00)
+	funBudget := test.CreateTestBudget(t, db, fun.ID, 50.00)
+	test.CreateTestTransaction(t, db, 120.00, food.ID)
+	test.CreateTestTransaction(t, db, 80.00, fun.ID)
+	
+	now := time.Now()
+	monthEnd := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, time.Local)
+	daysLeft := monthEnd.Day() - now.Day() + 1
+	
+	statuses, err := service.GetAllStatuses(t.Context())
+	require.NoError(t, err)
+	
+	// Without a monthly limit, only the budgets
+	allowances, err := service.GetDailyAllowance(t.Context(), 0, statuses)
+	require.NoError(t, err)
+	require.Len(t, allowances, 2)
+	
+	byBudget := make(map[uint]*models.DailyAllowance)
+	for _, allowance := range allowances {
+		byBudget[allowance.BudgetID] = allowance
+	}
+	require.Contains(t, byBudget, foodBudget.ID)
+	assert.Equal(t, daysLeft, byBudget[foodBudget.ID].DaysLeft)
+	assert.InDelta(t, 480.0/float64(daysLeft), byBudget[foodBudget.ID].PerDay, 0.005)
+	assert.False(t, byBudget[foodBudget.ID].IsOver())
+	
+	// Overspent budgets have a negative allowance
+	require.Contains(t, byBudget, funBudget.ID)
+	assert.InDelta(t, -30.0/float64(daysLeft), byBudget[funBudget.ID].PerDay, 0.005)
+	assert.True(t, byBudget[funBudget.ID].IsOver())
+	
+	// The monthly limit comes first, covering all expenses
+	allowances, err = service.GetDailyAllowance(t.Context(), 1000, statuses)
+	require.NoError(t, err)
+	require.Len(t, allowances, 3)
+	assert.Zero(t, allowances[0].BudgetID)
+	assert.Equal(t, 200.0, allowances[0].Spent)
+	assert.InDelta(t, 800.0/float64(daysLeft), allowances[0].PerDay, 0.005)
+}
+
+func TestNewDailyAllowance(t *testing.T) {
+	end := time.Date(2025, time.March, 31, 23, 59, 59, 0, time.Local)
+	
+	// Today counts, so ten days from the 22nd to the 31st
+	allowance := newDailyAllowance(500, 200, end, time.Date(2025, time.March, 22, 18, 0, 0, 0, time.Local))
+	assert.Equal(t, 10, allowance.DaysLeft)
+	assert.Equal(t, 30.0, allowance.PerDay)
+	
+	// On the last day, all that is left is for today
+	allowance = newDailyAllowance(500, 450, end, time.Date(2025, time.March, 31, 9, 0, 0, 0, time.Local))
+	assert.Equal(t, 1, allowance.DaysLeft)
+	assert.Equal(t, 50.0, allowance.PerDay)
+	
+	allowance = newDailyAllowance(100, 130, end, time.Date(2025, time.March, 29, 9, 0, 0, 0, time.Local))
+	assert.Equal(t, 3, allowance.DaysLeft)
+	assert.Equal(t, -10.0, allowance.PerDay)
+	assert.True(t, allowance.IsOver())
+}
+
+func TestBudgetService_ProposeAndCreateMonthlyBudgets(t *testing.T) {
+	db := test.SetupTestDB(t)
+	budgetRepo := repository.NewBudgetRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+	service := NewBudgetService(budgetRepo, txRepo)
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	transport := test.CreateTestCategory(t, db, "Transport", models.TransactionTypeExpense)
+	rent := test.CreateTestCategory(t, db, "Rent", models.TransactionTypeExpense)
+	gym := test.CreateTestCategory(t, db, "Gym", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	
+	now := time.Date(2025, time.April, 10, 12, 0, 0, 0, time.Local)
+	lastMonth := time.Date(2025, time.March, 15, 12, 0, 0, 0, time.Local)
+	spend := func(categoryID uint, amount float64, date time.Time) {
+		tx := test.CreateTestTransaction(t, db, amount, categoryID)
+		require.NoError(t, db.Model(tx).Update("date", date).Error)
+	}
+	spend(food.ID, 312.40, lastMonth)
+	spend(food.ID, 40, lastMonth)
+	spend(transport.ID, 80, lastMonth)
+	spend(rent.ID, 1200, lastMonth)
+	spend(gym.ID, 45, now) // this month, not last
+	salaryTx := test.CreateTestTransaction(t, db, 5000, salary.ID)
+	require.NoError(t, db.Model(salaryTx).Updates(map[string]interface{}{"type": models.TransactionTypeIncome, "date": lastMonth}).Error)
+	
+	// Rent already has a monthly budget
+	rentBudget := test.CreateTestBudget(t, db, rent.ID, 1200)
+	require.NoError(t, db.Model(rentBudget).Update("start_date", now.AddDate(0, -3, 0)).Error)
+	// and Transport only gets one after now
+	transportBudget := test.CreateTestBudget(t, db, transport.ID, 100)
+	require.NoError(t, db.Model(transportBudget).Update("start_date", now.AddDate(0, 2, 0)).Error)
+	
+	proposals, err := service.ProposeMonthlyBudgets(t.Context(), now)
+	require.NoError(t, err)
+	require.Len(t, proposals, 2)
+	
+	byName := make(map[string]*models.BudgetProposal)
+	for _, proposal := range proposals {
+		byName[proposal.Category.Name] = proposal
+	}
+	require.Contains(t, byName, "Food")
+	require.Contains(t, byName, "Transport")
+	assert.InDelta(t, 352.40, byName["Food"].LastMonth, 0.001)
+	assert.Equal(t, 360.0, byName["Food"].Amount)
+	assert.Equal(t, 80.0, byName["Transport"].Amount)
+	require.NoError(t, db.Delete(transportBudget).Error)
+	
+	t.Run("partial failure keeps the budgets that worked", func(t *testing.T) {
+		created, err := service.CreateMonthlyBudgets(t.Context(), map[uint]float64{
+			food.ID:      360,
+			transport.ID: 80,
+			rent.ID:      1000,
+		}, now)
+		require.Error(t, err)
+		assert.Contains(t, err.Error(), "failed to create 1 of 3 budgets")
+		assert.Contains(t, err.Error(), "Rent: active budget already exists")
+		
+		require.Len(t, created, 2)
+		assert.Equal(t, food.ID, created[0].CategoryID)
+		assert.Equal(t, 360.0, created[0].Amount)
+		assert.Equal(t, "Food", created[0].Name)
+		assert.Equal(t, transport.ID, created[1].CategoryID)
+		assert.Equal(t, "Transport", created[1].Name)
+		
+		// Both categories now have budgets, so nothing is left to propose
+		proposals, err := service.ProposeMonthlyBudgets(t.Context(), now)
+		require.NoError(t, err)
+		assert.Empty(t, proposals)
+	})
+}
+
+func TestBudgetService_SuggestAdjustments(t *testing.T) {
+	db := test.SetupTestDB(t)
+	budgetRepo := repository.NewBudgetRepository(db)
+	service := NewBudgetService(budgetRepo, repository.NewTransactionRepository(db))
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	gym := test.CreateTestCategory(t, db, "Gym", models.TransactionTypeExpense)
+	travel := test.CreateTestCategory(t, db, "Travel", models.TransactionTypeExpense)
+	fuel := test.CreateTestCategory(t, db, "Fuel", models.TransactionTypeExpense)
+	
+	thisMonth := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.Local)
+	spend := func(categoryID uint, monthsAgo int, amount float64) {
+		tx := test.CreateTestTransaction(t, db, amount, categoryID)
+		require.NoError(t, db.Model(tx).Update("date", thisMonth.AddDate(0, -monthsAgo, 14)).Error)
+	}
+	budget := func(categoryID uint, amount float64, monthsAgo int) *models.Budget {
+		b := test.CreateTestBudget(t, db, categoryID, amount)
+		require.NoError(t, db.Model(b).Update("start_date", thisMonth.AddDate(0, -monthsAgo, 0)).Error)
+		return b
+	}
+	
+	// Food was over all three months, Gym well under, Travel over only twice
+	// and Fuel over three times but budgeted only two months ago
+	budget(food.ID, 500, 6)
+	budget(gym.ID, 100, 6)
+	budget(travel.ID, 200, 6)
+	budget(fuel.ID, 50, 2)
+	for monthsAgo, amounts := range map[int][4]float64{
+		1: {620, 40, 250, 80},
+		2: {590, 45, 150, 80},
+		3: {641, 30, 260, 80},
+	} {
+		spend(food.ID, monthsAgo, amounts[0])
+		spend(gym.ID, monthsAgo, amounts[1])
+		spend(travel.ID, monthsAgo, amounts[2])
+		spend(fuel.ID, monthsAgo, amounts[3])
+	}
+	spend(gym.ID, 0, 95) // this month doesn't count yet
+	
+	suggestions, err := service.SuggestAdjustments(t.Context())
+	require.NoError(t, err)
+	require.Len(t, suggestions, 2)
+	
+	byCategory := make(map[uint]*models.BudgetSuggestion)
+	for _, suggestion := range suggestions {
+		byCategory[suggestion.Budget.CategoryID] = suggestion
+	}
+	require.Contains(t, byCategory, food.ID)
+	require.Contains(t, byCategory, gym.ID)
+	
+	raise := byCategory[food.ID]
+	assert.True(t, raise.Over)
+	assert.Equal(t, []float64{641, 590, 620}, raise.Spent)
+	assert.Equal(t, 650.0, raise.Amount)
+	assert.Equal(t, "Food over budget 3 months in a row — consider raising to $650", raise.Message())
+	
+	lower := byCategory[gym.ID]
+	assert.False(t, lower.Over)
+	assert.Equal(t, 50.0, lower.Amount)
+	assert.Equal(t, "Gym well under budget 3 months in a row — consider lowering to $50", lower.Message())
+}
+
+func TestBudgetService_SuggestAdjustmentsUsesPastAmounts(t *testing.T) {
+	db := test.SetupTestDB(t)
+	budgetRepo := repository.NewBudgetRepository(db)
+	service := NewBudgetService(budgetRepo, repository.NewTransactionRepository(db))
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	thisMonth := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.Local)
+	budget := test.CreateTestBudget(t, db, food.ID, 1000)
+	require.NoError(t, db.Model(budget).Update("start_date", thisMonth.AddDate(0, -6, 0)).Error)
+	for monthsAgo := 1; monthsAgo <= 3; monthsAgo++ {
+		tx := test.CreateTestTransaction(t, db, 500, food.ID)
+		require.NoError(t, db.Model(tx).Update("date", thisMonth.AddDate(0, -monthsAgo, 14)).Error)
+	}
+	
+	// Saving a new amount records the old one for the periods before
+	budget, err := service.GetByID(t.Context(), budget.ID)
+	require.NoError(t, err)
+	budget.Amount = 450
+	require.NoError(t, service.Update(t.Context(), budget))
+	changes, err := budgetRepo.GetAmountHistory(t.Context(), budget.ID)
+	require.NoError(t, err)
+	require.Len(t, changes, 1)
+	assert.Equal(t, 1000.0, changes[0].OldAmount)
+	assert.True(t, changes[0].EffectiveFrom.Equal(thisMonth))
+	
+	// As if it had been lowered a month earlier: over 450 last month, but
+	// well under 1000 the two before, which is no pattern to act on
+	require.NoError(t, db.Model(changes[0]).Update("effective_from", thisMonth.AddDate(0, -1, 0)).Error)
+	suggestions, err := service.SuggestAdjustments(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, suggestions)
+	
+	history, err := service.GetHistory(t.Context(), budget.ID, 4)
+	require.NoError(t, err)
+	budgeted := make([]float64, len(history))
+	for i, period := range history {
+		budgeted[i] = period.Budgeted
+	}
+	assert.Equal(t, []float64{1000, 1000, 450, 450}, budgeted)
+}
+
+func TestBudgetService_GetHistory(t *testing.T) {
+	db := test.SetupTestDB(t)
+	service := NewBudgetService(repository.NewBudgetRepository(db), repository.NewTransactionRepository(db))
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	thisMonth := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.Local)
+	budget := test.CreateTestBudget(t, db, food.ID, 500)
+	require.NoError(t, db.Model(budget).Update("start_date", thisMonth.AddDate(0, -8, 0)).Error)
+	for monthsAgo, amount := range map[int]float64{0: 120, 2: 640, 5: 300, 6: 999} {
+		tx := test.CreateTestTransaction(t, db, amount, food.ID)
+		require.NoError(t, db.Model(tx).Update("date", thisMonth.AddDate(0, -monthsAgo, 14)).Error)
+	}
+	
+	// The last 6 months, ending with this one
+	history, err := service.GetHistory(t.Context(), budget.ID, 6)
+	require.NoError(t, err)
+	require.Len(t, history, 6)
+	assert.Equal(t, thisMonth.AddDate(0, -5, 0), history[0].Start)
+	spent := make([]float64, len(history))
+	for i, period := range history {
+		spent[i] = period.Spent
+		assert.Equal(t, 500.0, period.Budgeted)
+		assert.Equal(t, i == 5, period.InProgress)
+	}
+	assert.Equal(t, []float64{300, 0, 0, 640, 0, 120}, spent)
+	assert.True(t, history[3].IsOver())
+	assert.False(t, history[5].IsOver())
+	
+	// Months before the budget started are left out
+	require.NoError(t, db.Model(budget).Update("start_date", thisMonth.AddDate(0, -2, 3)).Error)
+	history, err = service.GetHistory(t.Context(), budget.ID, 6)
+	require.NoError(t, err)
+	require.Len(t, history, 3)
+	assert.Equal(t, thisMonth.AddDate(0, -2, 0), history[0].Start)
+	assert.True(t, history[2].InProgress)
+}
+
+func TestBudgetService_CommittedRecurring(t *testing.T) {
+	db := test.SetupTestDB(t)
+	budgetRepo := repository.NewBudgetRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+	recurringRepo := repository.NewRecurringTransactionRepository(db)
+	service := NewBudgetService(budgetRepo, txRepo)
+	
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	recurringService := NewRecurringTransactionService(recurringRepo, txRepo, NewCurrencyService(settingsService))
+	
+	streaming := test.CreateTestCategory(t, db, "Streaming", models.TransactionTypeExpense)
+	software := test.CreateTestCategory(t, db, "Software", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	newRule := func(categoryID uint, txType models.TransactionType, amount float64, currency string, frequency models.RecurrenceFrequency) *models.RecurringTransaction {
+		rt := &models.RecurringTransaction{
+			Type:           txType,
+			Amount:         amount,
+			Currency:       currency,
+			CategoryID:     categoryID,
+			Description:    "Rule",
+			Frequency:      frequency,
+			FrequencyValue: 1,
+			StartDate:      time.Now().AddDate(0, -2, 0),
+			NextDueDate:    time.Now().AddDate(0, 0, 10),
+			IsActive:       true,
+		}
+		require.NoError(t, recurringRepo.Create(t.Context(), rt))
+		return rt
+	}
+	newRule(streaming.ID, models.TransactionTypeExpense, 15, "USD", models.FrequencyMonthly)
+	newRule(streaming.ID, models.TransactionTypeExpense, 367.25, "AED", models.FrequencyMonthly) // $100
+	newRule(software.ID, models.TransactionTypeExpense, 120, "USD", models.FrequencyYearly)
+	newRule(salary.ID, models.TransactionTypeIncome, 5000, "USD", models.FrequencyMonthly)
+	paused := newRule(software.ID, models.TransactionTypeExpense, 999, "USD", models.FrequencyMonthly)
+	require.NoError(t, recurringRepo.Deactivate(t.Context(), paused.ID))
+	
+	committed, err := recurringService.GetMonthlyCommittedByCategory(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, committed, 2)
+	assert.InDelta(t, 115.00, committed[streaming.ID], 0.01)
+	assert.InDelta(t, 10.00, committed[software.ID], 0.01)
+	
+	// Budgets get the recurring expenses of their period, and one that is
+	// smaller than them is over-committed
+	test.CreateTestBudget(t, db, streaming.ID, 100)
+	yearly := test.CreateTestBudget(t, db, software.ID, 500)
+	require.NoError(t, db.Model(yearly).Update("period", models.BudgetPeriodYearly).Error)
+	
+	statuses, err := service.GetAllStatuses(t.Context())
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	for _, status := range statuses {
+		assert.Zero(t, status.Committed, "needs the recurring service")
+	}
+	
+	service.SetRecurringService(recurringService)
+	statuses, err = service.GetAllStatuses(t.Context())
+	require.NoError(t, err)
+	byCategory := make(map[uint]*models.BudgetStatus)
+	for _, status := range statuses {
+		byCategory[status.Budget.CategoryID] = status
+	}
+	assert.InDelta(t, 115.00, byCategory[streaming.ID].Committed, 0.01)
+	assert.True(t, byCategory[streaming.ID].IsOverCommitted())
+	assert.InDelta(t, 120.00, byCategory[software.ID].Committed, 0.01)
+	assert.False(t, byCategory[software.ID].IsOverCommitted())
+}
+
+func TestBudgetService_PercentOfIncome(t *testing.T) {
+	db := test.SetupTestDB(t)
+	service := NewBudgetService(repository.NewBudgetRepository(db), repository.NewTransactionRepository(db))
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	thisMonth := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.Local)
+	
+	percent := 30.0
+	budget := &models.Budget{
+		Name:            "Food Share",
+		CategoryID:      food.ID,
+		PercentOfIncome: &percent,
+		Period:          models.BudgetPeriodMonthly,
+		StartDate:       thisMonth.AddDate(0, -1, 0),
+	}
+	require.NoError(t, service.Create(t.Context(), budget))
+	
+	// Without income yet, any spending is over the budget
+	spending := test.CreateTestTransaction(t, db, 450, food.ID)
+	require.NoError(t, db.Model(spending).Update("date", thisMonth.AddDate(0, 0, 1)).Error)
+	status, err := service.GetStatus(t.Context(), budget.ID)
+	require.NoError(t, err)
+	assert.Zero(t, status.Budget.Amount)
+	assert.Equal(t, 100.0, status.PercentUsed)
+	assert.True(t, status.IsOverBudget)
+	
+	// The amount follows this month's income
+	for monthsAgo, amount := range map[int]float64{0: 2000, 1: 1000} {
+		income := &models.Transaction{
+			Type:        models.TransactionTypeIncome,
+			Amount:      amount,
+			Currency:    "USD",
+			AmountUSD:   amount,
+			CategoryID:  salary.ID,
+			Description: "Invoice",
+			Date:        thisMonth.AddDate(0, -monthsAgo, 2),
+		}
+		require.NoError(t, db.Create(income).Error)
+	}
+	status, err = service.GetStatus(t.Context(), budget.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 600.0, status.Budget.Amount)
+	assert.Equal(t, 150.0, status.Remaining)
+	assert.False(t, status.IsOverBudget)
+	
+	statuses, err := service.GetAllStatuses(t.Context())
+	require.NoError(t, err)
+	require.Len(t, statuses, 1)
+	assert.Equal(t, 600.0, statuses[0].Budget.Amount)
+	assert.Equal(t, 75.0, statuses[0].PercentUsed)
+	
+	// Each month of the history is budgeted from that month's income
+	history, err := service.GetHistory(t.Context(), budget.ID, 2)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, 300.0, history[0].Budgeted)
+	assert.Equal(t, 600.0, history[1].Budgeted)
+	
+	// Percent-of-income budgets are monthly, up to 100%
+	yearly := &models.Budget{Name: "Yearly Share", CategoryID: food.ID, PercentOfIncome: &percent,
+		Period: models.BudgetPeriodYearly, StartDate: time.Now()}
+	assert.ErrorContains(t, service.Create(t.Context(), yearly), "must be monthly")
+	tooMuch := 120.0
+	budget.PercentOfIncome = &tooMuch
+	assert.ErrorContains(t, service.Update(t.Context(), budget), "between 0 and 100")
+}
+
+func TestBudgetService_ProratedFirstPeriod(t *testing.T) {
+	db := test.SetupTestDB(t)
+	service := NewBudgetService(repository.NewBudgetRepository(db), repository.NewTransactionRepository(db))
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	fun := test.CreateTestCategory(t, db, "Fun", models.TransactionTypeExpense)
+	now := time.Now()
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	daysInMonth := thisMonth.AddDate(0, 1, -1).Day()
+	
+	// Created today, the budget only gets the share of the month left
+	prorated := &models.Budget{
+		Name:               "Food",
+		CategoryID:         food.ID,
+		Amount:             600,
+		Period:             models.BudgetPeriodMonthly,
+		StartDate:          now,
+		ProrateFirstPeriod: true,
+	}
+	require.NoError(t, service.Create(t.Context(), prorated))
+	want := models.RoundUSD(600 * float64(daysInMonth-now.Day()+1) / float64(daysInMonth))
+	
+	status, err := service.GetStatus(t.Context(), prorated.ID)
+	require.NoError(t, err)
+	assert.Equal(t, want, status.Budget.Amount)
+	assert.Equal(t, want, status.Remaining)
+	
+	// Without the option, the whole amount applies as before
+	whole := &models.Budget{Name: "Fun", CategoryID: fun.ID, Amount: 600,
+		Period: models.BudgetPeriodMonthly, StartDate: now}
+	require.NoError(t, service.Create(t.Context(), whole))
+	
+	statuses, err := service.GetAllStatuses(t.Context())
+	require.NoError(t, err)
+	require.Len(t, statuses, 2)
+	for _, status := range statuses {
+		if status.Budget.ID == prorated.ID {
+			assert.Equal(t, want, status.Budget.Amount)
+		} else {
+			assert.Equal(t, 600.0, status.Budget.Amount)
+		}
+	}
+	
+	// Started on the 16th of April, 15 of its 30 days are budgeted; the
+	// months after get the whole amount
+	prorated.StartDate = time.Date(2025, time.April, 16, 9, 30, 0, 0, time.Local)
+	history, err := service.history(t.Context(), prorated, 3, time.Date(2025, time.May, 20, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, 300.0, history[0].Budgeted)
+	assert.Equal(t, 600.0, history[1].Budgeted)
+	assert.True(t, prorated.IsProratedIn(history[0].Start))
+	assert.False(t, prorated.IsProratedIn(history[1].Start))
+	
+	// Yearly budgets are prorated by the days left in the year
+	yearly := models.Budget{Amount: 365, Period: models.BudgetPeriodYearly, ProrateFirstPeriod: true,
+		StartDate: time.Date(2025, time.December, 1, 0, 0, 0, 0, time.Local)}
+	assert.Equal(t, 31.0, yearly.ProratedAmount(365, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local)))
+}
+
+func TestBudgetService_CommittedRecurringNeedsNoAPI(t *testing.T) {
+	db := test.SetupTestDB(t)
+	txRepo := repository.NewTransactionRepository(db)
+	recurringRepo := repository.NewRecurringTransactionRepository(db)
+	
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	currencyService := NewCurrencyService(settingsService)
+	requests := 0
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{"rates": {"EUR": 0.5, "GBP": 0.8}}`))
+	}))
+	defer server.Close()
+	currencyService.apiURL = server.URL
+	recurringService := NewRecurringTransactionService(recurringRepo, txRepo, currencyService)
+	
+	gym := test.CreateTestCategory(t, db, "Gym", models.TransactionTypeExpense)
+	newRule := func(amount float64, currency string) *models.RecurringTransaction {
+		rt := &models.RecurringTransaction{
+			Type:           models.TransactionTypeExpense,
+			Amount:         amount,
+			Currency:       currency,
+			CategoryID:     gym.ID,
+			Description:    "Membership",
+			Frequency:      models.FrequencyMonthly,
+			FrequencyValue: 1,
+			StartDate:      time.Now().AddDate(0, -2, 0),
+			NextDueDate:    time.Now().AddDate(0, 0, 10),
+			IsActive:       true,
+		}
+		require.NoError(t, recurringRepo.Create(t.Context(), rt))
+		return rt
+	}
+	
+	// A rule that has generated a transaction is converted at its rate
+	eur := newRule(40, "EUR")
+	generated := test.CreateTestTransaction(t, db, 40, gym.ID)
+	require.NoError(t, db.Model(generated).Updates(map[string]any{
+		"currency": "EUR", "amount_usd": 44, "recurring_transaction_id": eur.ID,
+	}).Error)
+	// One that hasn't, in a currency with no known rate, is left out
+	newRule(100, "GBP")
+	
+	committed, err := recurringService.GetMonthlyCommittedByCategory(t.Context())
+	require.NoError(t, err)
+	assert.InDelta(t, 44.00, committed[gym.ID], 0.01)
+	assert.Zero(t, requests)
+	
+	// Once the GBP rate is known it is used
+	_, err = currencyService.GetExchangeRate(t.Context(), "GBP")
+	require.NoError(t, err)
+	committed, err = recurringService.GetMonthlyCommittedByCategory(t.Context())
+	require.NoError(t, err)
+	assert.InDelta(t, 169.00, committed[gym.ID], 0.01)
+	assert.Equal(t, 1, requests)
+}
@@ -1,53 +1,104 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"sort"
+	"strings"
 	"time"
+	"unicode"
 
 	"burnwise/internal/models"
 	"burnwise/internal/repository"
 )
 
 type CategoryService struct {
-	repo *repository.CategoryRepository
+	repo            *repository.CategoryRepository
+	undoService     *UndoService
+	currencyService *CurrencyService
 }
 
 func NewCategoryService(repo *repository.CategoryRepository) *CategoryService {
 	return &CategoryService{repo: repo}
 }
 
-func (s *CategoryService) Create(category *models.Category) error {
+func (s *CategoryService) SetUndoService(undoService *UndoService) {
+	s.undoService = undoService
+}
+
+// SetCurrencyService enables checking that a category's default currency
+// is enabled. Without it any 3-letter code is accepted.
+func (s *CategoryService) SetCurrencyService(currencyService *CurrencyService) {
+	s.currencyService = currencyService
+}
+
+// checkDefaultCurrency normalizes the category's default currency and
+// makes sure it is one of the enabled currencies
+func (s *CategoryService) checkDefaultCurrency(category *models.Category) error {
+	category.DefaultCurrency = strings.ToUpper(strings.TrimSpace(category.DefaultCurrency))
+	if category.DefaultCurrency == "" || s.currencyService == nil {
+		return nil
+	}
+	if !s.currencyService.IsSupported(category.DefaultCurrency) {
+		return fmt.Errorf("default currency %s is not enabled", category.DefaultCurrency)
+	}
+	return nil
+}
+
+func (s *CategoryService) Create(ctx context.Context, category *models.Category) error {
+	if err := s.ValidateNew(ctx, category); err != nil {
+		return err
+	}
+	return s.repo.Create(ctx, category)
+}
+
+// ValidateNew checks the category could be created, without saving it
+func (s *CategoryService) ValidateNew(ctx context.Context, category *models.Category) error {
+	if err := s.checkDefaultCurrency(category); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
 	if err := category.Validate(); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
-	existing, _ := s.repo.FindByName(category.Name, category.Type)
+	existing, _ := s.repo.FindByName(ctx, category.Name, category.Type)
+	if existing != nil && existing.IsArchived {
+		return fmt.Errorf("category with name '%s' already exists for type %s but is archived; unarchive it instead", category.Name, category.Type)
+	}
 	if existing != nil {
 		return fmt.Errorf("category with name '%s' already exists for type %s", category.Name, category.Type)
 	}
-
-	return s.repo.Create(category)
+	return nil
 }
 
-func (s *CategoryService) Update(category *models.Category) error {
+func (s *CategoryService) Update(ctx context.Context, category *models.Category) error {
+	if err := s.checkDefaultCurrency(category); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
 	if err := category.Validate(); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
 	// Get the old category to track changes
-	oldCategory, err := s.repo.GetByID(category.ID)
+	oldCategory, err := s.repo.GetByID(ctx, category.ID)
 	if err != nil {
 		return fmt.Errorf("category not found: %w", err)
 	}
 
 	// Check for duplicate names
-	existing, _ := s.repo.FindByName(category.Name, category.Type)
+	existing, _ := s.repo.FindByName(ctx, category.Name, category.Type)
 	if existing != nil && existing.ID != category.ID {
 		return fmt.Errorf("category with name '%s' already exists for type %s", category.Name, category.Type)
 	}
 
+	// The order, archiving and pinning are only changed by MoveCategory,
+	// SetArchived and SetPinned
+	category.SortOrder = oldCategory.SortOrder
+	category.IsArchived = oldCategory.IsArchived
+	category.IsPinned = oldCategory.IsPinned
+
 	// Update the category
-	if err := s.repo.Update(category); err != nil {
+	if err := s.repo.Update(ctx, category); err != nil {
 		return err
 	}
 
@@ -71,7 +122,7 @@ func (s *CategoryService) Update(category *models.Category) error {
 			history.NewColor = category.Color
 		}
 
-		if err := s.repo.CreateHistory(history); err != nil {
+		if err := s.repo.CreateHistory(ctx, history); err != nil {
 			// Log error but don't fail the update
 			fmt.Printf("Warning: failed to record category history: %v\n", err)
 		}
@@ -80,8 +131,8 @@ func (s *CategoryService) Update(category *models.Category) error {
 	return nil
 }
 
-func (s *CategoryService) Delete(id uint) error {
-	category, err := s.repo.GetByID(id)
+func (s *CategoryService) Delete(ctx context.Context, id uint) error {
+	category, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("category not found: %w", err)
 	}
@@ -90,7 +141,7 @@ func (s *CategoryService) Delete(id uint) error {
 		return fmt.Errorf("cannot delete default category")
 	}
 
-	count, err := s.repo.GetUsageCount(id)
+	count, err := s.repo.GetUsageCount(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -99,91 +150,446 @@ func (s *CategoryService) Delete(id uint) error {
 		return fmt.Errorf("cannot delete category with %d transactions", count)
 	}
 
-	return s.repo.Delete(id)
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return err
+	}
+
+	if s.undoService != nil {
+		s.undoService.Record(fmt.Sprintf("delete category '%s'", category.Name), func(ctx context.Context) error {
+			return s.repo.Restore(ctx, id)
+		})
+	}
+
+	return nil
 }
 
-func (s *CategoryService) GetByID(id uint) (*models.Category, error) {
-	return s.repo.GetByID(id)
+func (s *CategoryService) GetByID(ctx context.Context, id uint) (*models.Category, error) {
+	return s.repo.GetByID(ctx, id)
 }
 
-func (s *CategoryService) GetAll() ([]*models.Category, error) {
-	return s.repo.GetAll()
+func (s *CategoryService) GetAll(ctx context.Context) ([]*models.Category, error) {
+	return s.repo.GetAll(ctx)
 }
 
-func (s *CategoryService) GetByType(txType models.TransactionType) ([]*models.Category, error) {
-	return s.repo.GetByType(txType)
+// GetByType returns the categories of a type that aren't archived
+func (s *CategoryService) GetByType(ctx context.Context, txType models.TransactionType) ([]*models.Category, error) {
+	return s.repo.GetByType(ctx, txType)
 }
 
-func (s *CategoryService) GetDefault() ([]*models.Category, error) {
-	return s.repo.GetDefault()
+// GetPickable returns the categories offered by the forms' pickers: those
+// of the given type, or of both types when empty, that aren't archived,
+// pinned ones first. The selected categories are kept even if archived, so
+// editing a record made before its category was archived doesn't change
+// its category.
+func (s *CategoryService) GetPickable(ctx context.Context, txType models.TransactionType, selectedIDs ...uint) ([]*models.Category, error) {
+	var categories []*models.Category
+	var err error
+	if txType == "" {
+		categories, err = s.repo.GetActive(ctx)
+	} else {
+		categories, err = s.repo.GetByType(ctx, txType)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	listed := make(map[uint]bool)
+	for _, category := range categories {
+		listed[category.ID] = true
+	}
+	for _, id := range selectedIDs {
+		if id == 0 || listed[id] {
+			continue
+		}
+		selected, err := s.repo.GetByID(ctx, id)
+		if err == nil && selected.IsArchived && (txType == "" || selected.Type == txType) {
+			categories = append(categories, selected)
+			listed[id] = true
+		}
+	}
+
+	sort.SliceStable(categories, func(i, j int) bool {
+		return categories[i].IsPinned && !categories[j].IsPinned
+	})
+	return categories, nil
 }
 
-func (s *CategoryService) GetWithTotals(start, end time.Time) ([]*models.CategoryWithTotal, error) {
-	return s.repo.GetWithTotals(start, end)
+// SetPinned pins a category to the top of the pickers, or unpins it
+func (s *CategoryService) SetPinned(ctx context.Context, id uint, pinned bool) error {
+	if err := s.repo.SetPinned(ctx, id, pinned); err != nil {
+		return fmt.Errorf("failed to pin category: %w", err)
+	}
+	return nil
 }
 
-func (s *CategoryService) GetCurrentMonthTotals() ([]*models.CategoryWithTotal, error) {
+// SetArchived archives a category, hiding it from the pickers, or brings
+// it back. Unlike deleting and merging, this is allowed for the defaults.
+func (s *CategoryService) SetArchived(ctx context.Context, id uint, archived bool) error {
+	if err := s.repo.SetArchived(ctx, id, archived); err != nil {
+		return fmt.Errorf("failed to archive category: %w", err)
+	}
+	return nil
+}
+
+func (s *CategoryService) GetDefault(ctx context.Context) ([]*models.Category, error) {
+	return s.repo.GetDefault(ctx)
+}
+
+func (s *CategoryService) GetWithTotals(ctx context.Context, start, end time.Time) ([]*models.CategoryWithTotal, error) {
+	return s.repo.GetWithTotals(ctx, start, end)
+}
+
+func (s *CategoryService) GetCurrentMonthTotals(ctx context.Context) ([]*models.CategoryWithTotal, error) {
 	now := time.Now()
 	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 	end := start.AddDate(0, 1, 0).Add(-time.Second)
 	
-	return s.repo.GetWithTotals(start, end)
+	return s.repo.GetWithTotals(ctx, start, end)
 }
 
-func (s *CategoryService) EnsureDefaultCategories() error {
+// EnsureDefaultCategories creates the default categories that don't exist
+// yet and returns the ones it created
+func (s *CategoryService) EnsureDefaultCategories(ctx context.Context) ([]*models.Category, error) {
 	defaults := models.GetDefaultCategories()
 	
+	var created []*models.Category
 	for _, defaultCat := range defaults {
-		existing, _ := s.repo.FindByName(defaultCat.Name, defaultCat.Type)
+		existing, _ := s.repo.FindByName(ctx, defaultCat.Name, defaultCat.Type)
 		if existing == nil {
 			category := defaultCat
-			if err := s.repo.Create(&category); err != nil {
-				return fmt.Errorf("failed to create default category %s: %w", category.Name, err)
+			if err := s.repo.Create(ctx, &category); err != nil {
+				return created, fmt.Errorf("failed to create default category %s: %w", category.Name, err)
 			}
+			created = append(created, &category)
 		}
 	}
 	
-	return nil
+	return created, nil
 }
 
-func (s *CategoryService) MergeCategories(sourceID, targetID uint) error {
-	if sourceID == targetID {
-		return fmt.Errorf("cannot merge a category with itself")
+// SeedOnFirstRun creates the default categories when the database has never
+// had any, and returns the ones it created. Once categories exist, even if
+// they were all deleted since, it does nothing.
+func (s *CategoryService) SeedOnFirstRun(ctx context.Context) ([]*models.Category, error) {
+	count, err := s.repo.CountAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count categories: %w", err)
 	}
+	if count > 0 {
+		return nil, nil
+	}
+	return s.EnsureDefaultCategories(ctx)
+}
 
-	// Verify source category
-	source, err := s.repo.GetByID(sourceID)
+// MoveCategory moves a category up (negative offset) or down among the
+// categories of its type and saves the resulting order, which lists and
+// pickers follow from then on. Moving past either end does nothing.
+func (s *CategoryService) MoveCategory(ctx context.Context, id uint, offset int) error {
+	category, err := s.repo.GetByID(ctx, id)
 	if err != nil {
-		return fmt.Errorf("source category not found: %w", err)
+		return fmt.Errorf("category not found: %w", err)
 	}
 
+	siblings, err := s.repo.GetAllByType(ctx, category.Type)
+	if err != nil {
+		return fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	index := -1
+	for i, sibling := range siblings {
+		if sibling.ID == id {
+			index = i
+			break
+		}
+	}
+	target := index + offset
+	if index < 0 || target < 0 || target >= len(siblings) {
+		return nil
+	}
+	siblings[index], siblings[target] = siblings[target], siblings[index]
+
+	ids := make([]uint, len(siblings))
+	for i, sibling := range siblings {
+		ids[i] = sibling.ID
+	}
+	if err := s.repo.SetSortOrder(ctx, ids); err != nil {
+		return fmt.Errorf("failed to save category order: %w", err)
+	}
+	return nil
+}
+
+func (s *CategoryService) MergeCategories(ctx context.Context, sourceID, targetID uint) error {
+	return s.MergeMany(ctx, []uint{sourceID}, targetID)
+}
+
+// MergeMany merges all source categories into the target in one transaction
+func (s *CategoryService) MergeMany(ctx context.Context, sourceIDs []uint, targetID uint) error {
 	// Verify target category
-	target, err := s.repo.GetByID(targetID)
+	target, err := s.repo.GetByID(ctx, targetID)
 	if err != nil {
 		return fmt.Errorf("target category not found: %w", err)
 	}
 
-	// Ensure both categories are of the same type
-	if source.Type != target.Type {
-		return fmt.Errorf("cannot merge categories of different types (%s -> %s)", source.Type, target.Type)
+	sources, err := s.mergeSources(ctx, sourceIDs, target)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint, len(sources))
+	for i, source := range sources {
+		ids[i] = source.ID
 	}
 
-	// Prevent merging default categories
-	if source.IsDefault {
-		return fmt.Errorf("cannot merge default category '%s'", source.Name)
+	if err := s.repo.MergeMany(ctx, ids, targetID); err != nil {
+		return err
 	}
 
-	return s.repo.MergeCategories(sourceID, targetID)
+	s.recordMerge(sources, target, func(ctx context.Context) error {
+		return s.unmerge(ctx, ids, targetID)
+	})
+	return nil
+}
+
+// MergeIntoNew creates the target category and merges all source
+// categories into it in one transaction, so the new category is only kept
+// once the merge went through. Undoing the merge removes it again, unless
+// it was used since.
+func (s *CategoryService) MergeIntoNew(ctx context.Context, sourceIDs []uint, target *models.Category) error {
+	if err := s.ValidateNew(ctx, target); err != nil {
+		return err
+	}
+
+	sources, err := s.mergeSources(ctx, sourceIDs, target)
+	if err != nil {
+		return err
+	}
+	ids := make([]uint, len(sources))
+	for i, source := range sources {
+		ids[i] = source.ID
+	}
+
+	if err := s.repo.MergeIntoNew(ctx, ids, target); err != nil {
+		return err
+	}
+
+	targetID := target.ID
+	s.recordMerge(sources, target, func(ctx context.Context) error {
+		if err := s.unmerge(ctx, ids, targetID); err != nil {
+			return err
+		}
+		// Kept when it was used since
+		count, err := s.repo.GetUsageCount(ctx, targetID)
+		if err != nil || count > 0 {
+			return err
+		}
+		return s.repo.Delete(ctx, targetID)
+	})
+	return nil
+}
+
+// mergeSources loads the categories to merge into the target, checking each
+// can be merged into it
+func (s *CategoryService) mergeSources(ctx context.Context, sourceIDs []uint, target *models.Category) ([]*models.Category, error) {
+	if len(sourceIDs) == 0 {
+		return nil, fmt.Errorf("no source categories selected")
+	}
+
+	sources := make([]*models.Category, 0, len(sourceIDs))
+	seen := make(map[uint]bool)
+	for _, sourceID := range sourceIDs {
+		if sourceID == target.ID {
+			return nil, fmt.Errorf("cannot merge a category with itself")
+		}
+		if seen[sourceID] {
+			continue
+		}
+		seen[sourceID] = true
+
+		// Verify source category
+		source, err := s.repo.GetByID(ctx, sourceID)
+		if err != nil {
+			return nil, fmt.Errorf("source category not found: %w", err)
+		}
+
+		// Ensure both categories are of the same type
+		if source.Type != target.Type {
+			return nil, fmt.Errorf("cannot merge categories of different types (%s -> %s)", source.Type, target.Type)
+		}
+
+		// Prevent merging default categories
+		if source.IsDefault {
+			return nil, fmt.Errorf("cannot merge default category '%s'", source.Name)
+		}
+
+		sources = append(sources, source)
+	}
+	return sources, nil
+}
+
+// recordMerge records the undo of a merge of the sources into the target
+func (s *CategoryService) recordMerge(sources []*models.Category, target *models.Category, undo func(ctx context.Context) error) {
+	if s.undoService == nil {
+		return
+	}
+	description := fmt.Sprintf("merge '%s' into '%s'", sources[0].Name, target.Name)
+	if len(sources) > 1 {
+		description = fmt.Sprintf("merge %d categories into '%s'", len(sources), target.Name)
+	}
+	s.undoService.Record(description, undo)
+}
+
+// unmerge moves everything merged from the sources back out of the target
+func (s *CategoryService) unmerge(ctx context.Context, sourceIDs []uint, targetID uint) error {
+	for _, sourceID := range sourceIDs {
+		if err := s.repo.UnmergeCategories(ctx, sourceID, targetID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// FindSimilarCategories groups categories of the same type whose names are
+// so alike they are probably duplicates, such as "Food" and "Foods" or
+// "Eating Out" and "eating-out", as candidates for merging. Names are
+// compared ignoring case, punctuation and plural endings, and are similar
+// within a small edit distance (see similarNames). Groups made only of
+// default categories are left out, as those can't be merged.
+func (s *CategoryService) FindSimilarCategories(ctx context.Context) ([][]*models.Category, error) {
+	categories, err := s.repo.GetAll(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get categories: %w", err)
+	}
+
+	// Link similar pairs into groups, each category pointing towards the
+	// first one of its group
+	names := make([]string, len(categories))
+	for i, category := range categories {
+		names[i] = normalizeCategoryName(category.Name)
+	}
+	parent := make([]int, len(categories))
+	for i := range parent {
+		parent[i] = i
+	}
+	var root func(i int) int
+	root = func(i int) int {
+		if parent[i] != i {
+			parent[i] = root(parent[i])
+		}
+		return parent[i]
+	}
+	for i := range categories {
+		for j := i + 1; j < len(categories); j++ {
+			if categories[i].Type == categories[j].Type && similarNames(names[i], names[j]) {
+				parent[root(j)] = root(i)
+			}
+		}
+	}
+
+	members := make(map[int][]*models.Category)
+	var roots []int
+	for i, category := range categories {
+		r := root(i)
+		if _, ok := members[r]; !ok {
+			roots = append(roots, r)
+		}
+		members[r] = append(members[r], category)
+	}
+
+	var groups [][]*models.Category
+	for _, r := range roots {
+		group := members[r]
+		if len(group) < 2 {
+			continue
+		}
+		mergeable := false
+		for _, category := range group {
+			mergeable = mergeable || !category.IsDefault
+		}
+		if mergeable {
+			groups = append(groups, group)
+		}
+	}
+	return groups, nil
+}
+
+// normalizeCategoryName lowercases a name and drops everything but letters
+// and digits, then a plural ending, so "Coffee Shops" becomes "coffeeshop"
+// and "Groceries" becomes "grocery"
+func normalizeCategoryName(name string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		}
+	}
+	normalized := b.String()
+
+	switch {
+	case strings.HasSuffix(normalized, "ies") && len(normalized) > 4:
+		return strings.TrimSuffix(normalized, "ies") + "y"
+	case strings.HasSuffix(normalized, "s") && !strings.HasSuffix(normalized, "ss") && len(normalized) > 3:
+		return strings.TrimSuffix(normalized, "s")
+	}
+	return normalized
+}
+
+// similarNames reports whether two normalized names are within an edit
+// distance of one, or of two when both are longer than six letters. Names
+// of up to three letters must match exactly, or "Car" and "Cat" would do.
+func similarNames(a, b string) bool {
+	shorter := min(len([]rune(a)), len([]rune(b)))
+	limit := 1
+	switch {
+	case shorter <= 3:
+		limit = 0
+	case shorter > 6:
+		limit = 2
+	}
+	return editDistance(a, b) <= limit
+}
+
+// editDistance is the Levenshtein distance between a and b: the fewest
+// single-letter insertions, deletions and substitutions turning one into
+// the other
+func editDistance(a, b string) int {
+	ra, rb := []rune(a), []rune(b)
+	previous := make([]int, len(rb)+1)
+	current := make([]int, len(rb)+1)
+	for j := range previous {
+		previous[j] = j
+	}
+	for i := 1; i <= len(ra); i++ {
+		current[0] = i
+		for j := 1; j <= len(rb); j++ {
+			cost := 1
+			if ra[i-1] == rb[j-1] {
+				cost = 0
+			}
+			current[j] = min(previous[j]+1, current[j-1]+1, previous[j-1]+cost)
+		}
+		previous, current = current, previous
+	}
+	return previous[len(rb)]
+}
+
+func (s *CategoryService) GetAllWithUsageCount(ctx context.Context) ([]*models.CategoryWithTotal, error) {
+	return s.repo.GetAllWithUsageCount(ctx)
+}
+
+func (s *CategoryService) GetHistory(ctx context.Context, categoryID uint) ([]*models.CategoryHistory, error) {
+	return s.repo.GetHistory(ctx, categoryID)
 }
 
-func (s *CategoryService) GetAllWithUsageCount() ([]*models.CategoryWithTotal, error) {
-	return s.repo.GetAllWithUsageCount()
+// GetHistoryLog returns the history of a category, oldest first, including
+// merges of other categories into it
+func (s *CategoryService) GetHistoryLog(ctx context.Context, categoryID uint) ([]*models.CategoryHistory, error) {
+	return s.repo.GetHistoryLog(ctx, categoryID)
 }
 
-func (s *CategoryService) GetHistory(categoryID uint) ([]*models.CategoryHistory, error) {
-	return s.repo.GetHistory(categoryID)
+// GetAllHistory returns the history of every category, oldest first
+func (s *CategoryService) GetAllHistory(ctx context.Context) ([]*models.CategoryHistory, error) {
+	return s.repo.GetAllHistory(ctx)
 }
 
-func (s *CategoryService) GetUsageCount(categoryID uint) (int64, error) {
-	return s.repo.GetUsageCount(categoryID)
+func (s *CategoryService) GetUsageCount(ctx context.Context, categoryID uint) (int64, error) {
+	return s.repo.GetUsageCount(ctx, categoryID)
 }
\ No newline at end of file
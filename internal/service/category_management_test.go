@@ -24,7 +24,7 @@ func TestCategoryService_Update_WithHistory(t *testing.T) {
 		Icon:  "🍕",
 		Color: "#FF5722",
 	}
-	err := service.Create(category)
+	err := service.Create(t.Context(), category)
 	require.NoError(t, err)
 
 	// Update the category
@@ -32,11 +32,11 @@ func TestCategoryService_Update_WithHistory(t *testing.T) {
 	category.Icon = "🍔"
 	category.Color = "#4CAF50"
 
-	err = service.Update(category)
+	err = service.Update(t.Context(), category)
 	require.NoError(t, err)
 
 	// Check that history was recorded
-	history, err := service.GetHistory(category.ID)
+	history, err := service.GetHistory(t.Context(), category.ID)
 	require.NoError(t, err)
 	assert.Len(t, history, 1)
 
@@ -69,7 +69,7 @@ func TestCategoryService_MergeCategories(t *testing.T) {
 		Icon:  "🍔",
 		Color: "#FF5722",
 	}
-	err = service.Create(sourceCategory)
+	err = service.Create(t.Context(), sourceCategory)
 	require.NoError(t, err)
 
 	targetCategory := &models.Category{
@@ -78,7 +78,7 @@ func TestCategoryService_MergeCategories(t *testing.T) {
 		Icon:  "🍽️",
 		Color: "#4CAF50",
 	}
-	err = service.Create(targetCategory)
+	err = service.Create(t.Context(), targetCategory)
 	require.NoError(t, err)
 
 	// Create transactions in source category
@@ -90,7 +90,7 @@ func TestCategoryService_MergeCategories(t *testing.T) {
 		Description: "McDonald's",
 		Date:        time.Now(),
 	}
-	err = txService.Create(tx1)
+	err = txService.Create(t.Context(), tx1)
 	require.NoError(t, err)
 
 	tx2 := &models.Transaction{
@@ -101,28 +101,28 @@ func TestCategoryService_MergeCategories(t *testing.T) {
 		Description: "Burger King",
 		Date:        time.Now(),
 	}
-	err = txService.Create(tx2)
+	err = txService.Create(t.Context(), tx2)
 	require.NoError(t, err)
 
 	// Perform merge
-	err = service.MergeCategories(sourceCategory.ID, targetCategory.ID)
+	err = service.MergeCategories(t.Context(), sourceCategory.ID, targetCategory.ID)
 	require.NoError(t, err)
 
 	// Verify source category is deleted
-	_, err = service.GetByID(sourceCategory.ID)
+	_, err = service.GetByID(t.Context(), sourceCategory.ID)
 	assert.Error(t, err)
 
 	// Verify transactions are moved to target category
-	tx1Updated, err := txRepo.GetByID(tx1.ID)
+	tx1Updated, err := txRepo.GetByID(t.Context(), tx1.ID)
 	require.NoError(t, err)
 	assert.Equal(t, targetCategory.ID, tx1Updated.CategoryID)
 
-	tx2Updated, err := txRepo.GetByID(tx2.ID)
+	tx2Updated, err := txRepo.GetByID(t.Context(), tx2.ID)
 	require.NoError(t, err)
 	assert.Equal(t, targetCategory.ID, tx2Updated.CategoryID)
 
 	// Verify history was recorded
-	history, err := service.GetHistory(sourceCategory.ID)
+	history, err := service.GetHistory(t.Context(), sourceCategory.ID)
 	require.NoError(t, err)
 	assert.Len(t, history, 1)
 
@@ -144,7 +144,7 @@ func TestCategoryService_MergeCategories_DifferentTypes(t *testing.T) {
 		Type: models.TransactionTypeIncome,
 		Icon: "💼",
 	}
-	err := service.Create(incomeCategory)
+	err := service.Create(t.Context(), incomeCategory)
 	require.NoError(t, err)
 
 	expenseCategory := &models.Category{
@@ -152,11 +152,11 @@ func TestCategoryService_MergeCategories_DifferentTypes(t *testing.T) {
 		Type: models.TransactionTypeExpense,
 		Icon: "🍔",
 	}
-	err = service.Create(expenseCategory)
+	err = service.Create(t.Context(), expenseCategory)
 	require.NoError(t, err)
 
 	// Attempt to merge different types
-	err = service.MergeCategories(incomeCategory.ID, expenseCategory.ID)
+	err = service.MergeCategories(t.Context(), incomeCategory.ID, expenseCategory.ID)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cannot merge categories of different types")
 }
@@ -173,7 +173,7 @@ func TestCategoryService_MergeCategories_DefaultCategory(t *testing.T) {
 		Icon:      "💼",
 		IsDefault: true,
 	}
-	err := repo.Create(defaultCategory)
+	err := repo.Create(t.Context(), defaultCategory)
 	require.NoError(t, err)
 
 	customCategory := &models.Category{
@@ -181,15 +181,264 @@ func TestCategoryService_MergeCategories_DefaultCategory(t *testing.T) {
 		Type: models.TransactionTypeIncome,
 		Icon: "💻",
 	}
-	err = service.Create(customCategory)
+	err = service.Create(t.Context(), customCategory)
 	require.NoError(t, err)
 
 	// Attempt to merge default category
-	err = service.MergeCategories(defaultCategory.ID, customCategory.ID)
+	err = service.MergeCategories(t.Context(), defaultCategory.ID, customCategory.ID)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cannot merge default category")
 }
 
+func TestCategoryService_MergeMany(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewCategoryRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+	service := NewCategoryService(repo)
+
+	fastFood := test.CreateTestCategory(t, db, "Fast Food", models.TransactionTypeExpense)
+	restaurants := test.CreateTestCategory(t, db, "Restaurants", models.TransactionTypeExpense)
+	coffee := test.CreateTestCategory(t, db, "Coffee", models.TransactionTypeExpense)
+
+	tx1 := test.CreateTestTransaction(t, db, 12.00, fastFood.ID)
+	tx2 := test.CreateTestTransaction(t, db, 60.00, restaurants.ID)
+	tx3 := test.CreateTestTransaction(t, db, 4.50, coffee.ID)
+
+	// Merge into a brand-new category
+	eatingOut := &models.Category{
+		Name: "Eating Out",
+		Type: models.TransactionTypeExpense,
+		Icon: "🍽️",
+	}
+	require.NoError(t, service.Create(t.Context(), eatingOut))
+
+	err := service.MergeMany(t.Context(), []uint{fastFood.ID, restaurants.ID, coffee.ID}, eatingOut.ID)
+	require.NoError(t, err)
+
+	for _, tx := range []*models.Transaction{tx1, tx2, tx3} {
+		updated, err := txRepo.GetByID(t.Context(), tx.ID)
+		require.NoError(t, err)
+		assert.Equal(t, eatingOut.ID, updated.CategoryID)
+	}
+
+	// One history record per source
+	for _, source := range []*models.Category{fastFood, restaurants, coffee} {
+		_, err := service.GetByID(t.Context(), source.ID)
+		assert.Error(t, err)
+
+		history, err := service.GetHistory(t.Context(), source.ID)
+		require.NoError(t, err)
+		require.Len(t, history, 1)
+		assert.Equal(t, models.CategoryActionMerged, history[0].Action)
+		assert.Equal(t, eatingOut.ID, *history[0].TargetCategoryID)
+		assert.Equal(t, 1, history[0].TransactionCount)
+	}
+}
+
+func TestCategoryService_MergeMany_RollsBackOnInvalidSource(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewCategoryRepository(db)
+	service := NewCategoryService(repo)
+
+	source := test.CreateTestCategory(t, db, "Coffee", models.TransactionTypeExpense)
+	income := test.CreateTestCategory(t, db, "Bonus", models.TransactionTypeIncome)
+	target := test.CreateTestCategory(t, db, "Eating Out", models.TransactionTypeExpense)
+
+	err := service.MergeMany(t.Context(), []uint{source.ID, income.ID}, target.ID)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "cannot merge categories of different types")
+
+	// Nothing was merged
+	_, err = service.GetByID(t.Context(), source.ID)
+	assert.NoError(t, err)
+}
+
+func TestCategoryService_MergeCategories_MovesBudgetsAndRecurring(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewCategoryRepository(db)
+	service := NewCategoryService(repo)
+	budgetRepo := repository.NewBudgetRepository(db)
+	recurringRepo := repository.NewRecurringTransactionRepository(db)
+
+	source := test.CreateTestCategory(t, db, "Coffee", models.TransactionTypeExpense)
+	target := test.CreateTestCategory(t, db, "Eating Out", models.TransactionTypeExpense)
+	other := test.CreateTestCategory(t, db, "Groceries", models.TransactionTypeExpense)
+
+	tx := test.CreateTestTransaction(t, db, 4.50, source.ID)
+	budget := test.CreateTestBudget(t, db, source.ID, 80)
+
+	// A group budget already covering the target only drops the source
+	group := &models.Budget{
+		Name:       "Food",
+		CategoryID: source.ID,
+		Amount:     500,
+		Period:     models.BudgetPeriodYearly,
+		StartDate:  time.Now().AddDate(0, -1, 0),
+		Categories: []models.Category{*source, *target, *other},
+	}
+	require.NoError(t, db.Create(group).Error)
+
+	recurring := &models.RecurringTransaction{
+		Type:        models.TransactionTypeExpense,
+		Amount:      30,
+		Currency:    "USD",
+		CategoryID:  source.ID,
+		Description: "Coffee subscription",
+		Frequency:   models.FrequencyMonthly,
+		StartDate:   time.Now(),
+		NextDueDate: time.Now().AddDate(0, 1, 0),
+		IsActive:    true,
+	}
+	require.NoError(t, recurringRepo.Create(t.Context(), recurring))
+
+	require.NoError(t, service.MergeCategories(t.Context(), source.ID, target.ID))
+
+	movedTx, err := repository.NewTransactionRepository(db).GetByID(t.Context(), tx.ID)
+	require.NoError(t, err)
+	assert.Equal(t, target.ID, movedTx.CategoryID)
+
+	movedBudget, err := budgetRepo.GetByID(t.Context(), budget.ID)
+	require.NoError(t, err)
+	assert.Equal(t, target.ID, movedBudget.CategoryID)
+	assert.Equal(t, "Eating Out", movedBudget.Category.Name)
+
+	movedGroup, err := budgetRepo.GetByID(t.Context(), group.ID)
+	require.NoError(t, err)
+	assert.Equal(t, target.ID, movedGroup.CategoryID)
+	assert.ElementsMatch(t, []uint{target.ID, other.ID}, movedGroup.CategoryIDs())
+
+	movedRecurring, err := recurringRepo.GetByID(t.Context(), recurring.ID)
+	require.NoError(t, err)
+	assert.Equal(t, target.ID, movedRecurring.CategoryID)
+	assert.Equal(t, "Eating Out", movedRecurring.Category.Name)
+
+	history, err := service.GetHistory(t.Context(), source.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, "Merged 'Coffee' into 'Eating Out' with 1 transactions, 2 budgets and 1 recurring transactions", history[0].Notes)
+}
+
+func TestCategoryService_MergeCategories_RecurringKeepsGenerating(t *testing.T) {
+	db := test.SetupTestDB(t)
+	service := NewCategoryService(repository.NewCategoryRepository(db))
+	recurringRepo := repository.NewRecurringTransactionRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	recurringService := NewRecurringTransactionService(recurringRepo, txRepo, NewCurrencyService(settingsService))
+
+	source := test.CreateTestCategory(t, db, "Gym", models.TransactionTypeExpense)
+	target := test.CreateTestCategory(t, db, "Sports", models.TransactionTypeExpense)
+	recurring := func(description string, txType models.TransactionType) *models.RecurringTransaction {
+		rt := &models.RecurringTransaction{
+			Type:        txType,
+			Amount:      40,
+			Currency:    "USD",
+			CategoryID:  source.ID,
+			Description: description,
+			Frequency:   models.FrequencyMonthly,
+			StartDate:   time.Now().AddDate(0, -1, 0),
+			NextDueDate: time.Now().AddDate(0, 0, -1),
+			IsActive:    true,
+		}
+		require.NoError(t, recurringRepo.Create(t.Context(), rt))
+		return rt
+	}
+	membership := recurring("Membership", models.TransactionTypeExpense)
+	deleted := recurring("Climbing pass", models.TransactionTypeExpense)
+	require.NoError(t, recurringRepo.Delete(t.Context(), deleted.ID))
+
+	// An income filed under the expense category blocks the merge
+	refund := recurring("Employer gym refund", models.TransactionTypeIncome)
+	err = service.MergeCategories(t.Context(), source.ID, target.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "1 recurring transactions in it are not of type expense")
+	unmoved, err := recurringRepo.GetByID(t.Context(), membership.ID)
+	require.NoError(t, err)
+	assert.Equal(t, source.ID, unmoved.CategoryID)
+
+	require.NoError(t, db.Unscoped().Delete(refund).Error)
+	require.NoError(t, service.MergeCategories(t.Context(), source.ID, target.ID))
+
+	// Occurrences due after the merge are generated into the target
+	generated, err := recurringService.ProcessDueTransactions(t.Context(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 1, generated)
+	transactions, err := recurringService.GetGeneratedTransactions(t.Context(), membership.ID)
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	assert.Equal(t, target.ID, transactions[0].CategoryID)
+
+	// A deleted one comes back in the target rather than the merged category
+	require.NoError(t, recurringRepo.Restore(t.Context(), deleted.ID))
+	restored, err := recurringRepo.GetByID(t.Context(), deleted.ID)
+	require.NoError(t, err)
+	assert.Equal(t, target.ID, restored.CategoryID)
+	assert.Equal(t, "Sports", restored.Category.Name)
+}
+
+func TestCategoryService_MergeCategories_BudgetConflict(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewCategoryRepository(db)
+	service := NewCategoryService(repo)
+
+	source := test.CreateTestCategory(t, db, "Coffee", models.TransactionTypeExpense)
+	target := test.CreateTestCategory(t, db, "Eating Out", models.TransactionTypeExpense)
+	tx := test.CreateTestTransaction(t, db, 4.50, source.ID)
+	test.CreateTestBudget(t, db, source.ID, 80)
+	test.CreateTestBudget(t, db, target.ID, 300)
+
+	err := service.MergeCategories(t.Context(), source.ID, target.ID)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "'Eating Out' and 'Coffee' both have an active monthly budget")
+
+	// Nothing was changed
+	_, err = service.GetByID(t.Context(), source.ID)
+	assert.NoError(t, err)
+	unchanged, err := repository.NewTransactionRepository(db).GetByID(t.Context(), tx.ID)
+	require.NoError(t, err)
+	assert.Equal(t, source.ID, unchanged.CategoryID)
+}
+
+func TestCategoryService_MergeIntoNew(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewCategoryRepository(db)
+	service := NewCategoryService(repo)
+	undoService := NewUndoService(10)
+	service.SetUndoService(undoService)
+
+	coffee := test.CreateTestCategory(t, db, "Coffee", models.TransactionTypeExpense)
+	lunch := test.CreateTestCategory(t, db, "Lunch", models.TransactionTypeExpense)
+	tx := test.CreateTestTransaction(t, db, 4.50, coffee.ID)
+	test.CreateTestBudget(t, db, coffee.ID, 80)
+	lunchBudget := test.CreateTestBudget(t, db, lunch.ID, 200)
+
+	// The sources' budgets clash once merged, so the new category isn't kept
+	err := service.MergeIntoNew(t.Context(), []uint{coffee.ID, lunch.ID},
+		&models.Category{Name: "Eating Out", Type: models.TransactionTypeExpense})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "both have an active monthly budget")
+	existing, _ := repo.FindByName(t.Context(), "Eating Out", models.TransactionTypeExpense)
+	assert.Nil(t, existing)
+	assert.Equal(t, 0, undoService.Len())
+
+	require.NoError(t, db.Delete(lunchBudget).Error)
+	eatingOut := &models.Category{Name: "Eating Out", Type: models.TransactionTypeExpense}
+	require.NoError(t, service.MergeIntoNew(t.Context(), []uint{coffee.ID, lunch.ID}, eatingOut))
+	merged, err := repository.NewTransactionRepository(db).GetByID(t.Context(), tx.ID)
+	require.NoError(t, err)
+	assert.Equal(t, eatingOut.ID, merged.CategoryID)
+
+	// Undoing the merge removes the new category again
+	_, err = undoService.Undo(t.Context())
+	require.NoError(t, err)
+	_, err = service.GetByID(t.Context(), eatingOut.ID)
+	assert.Error(t, err)
+	restored, err := repository.NewTransactionRepository(db).GetByID(t.Context(), tx.ID)
+	require.NoError(t, err)
+	assert.Equal(t, coffee.ID, restored.CategoryID)
+}
+
 func TestCategoryService_GetAllWithUsageCount(t *testing.T) {
 	db := test.SetupTestDB(t)
 	repo := repository.NewCategoryRepository(db)
@@ -208,7 +457,7 @@ func TestCategoryService_GetAllWithUsageCount(t *testing.T) {
 		Type: models.TransactionTypeExpense,
 		Icon: "🍔",
 	}
-	err = service.Create(category)
+	err = service.Create(t.Context(), category)
 	require.NoError(t, err)
 
 	// Create transactions
@@ -221,12 +470,12 @@ func TestCategoryService_GetAllWithUsageCount(t *testing.T) {
 			Description: "Test transaction",
 			Date:        time.Now(),
 		}
-		err = txService.Create(tx)
+		err = txService.Create(t.Context(), tx)
 		require.NoError(t, err)
 	}
 
 	// Get categories with usage count
-	categories, err := service.GetAllWithUsageCount()
+	categories, err := service.GetAllWithUsageCount(t.Context())
 	require.NoError(t, err)
 
 	// Find our test category
@@ -240,6 +489,109 @@ func TestCategoryService_GetAllWithUsageCount(t *testing.T) {
 
 	require.NotNil(t, testCategory)
 	assert.Equal(t, 3, testCategory.Count)
+	assert.InDelta(t, 30.00, testCategory.Total, 0.001)
+
+	// The total is in USD, nets refunds and leaves out deleted transactions
+	aed := &models.Transaction{
+		Type:        models.TransactionTypeExpense,
+		Amount:      367.25,
+		Currency:    "AED",
+		CategoryID:  category.ID,
+		Description: "Dinner",
+		Date:        time.Now(),
+	}
+	require.NoError(t, txService.Create(t.Context(), aed))
+	require.NoError(t, txService.Create(t.Context(), &models.Transaction{
+		Type:        models.TransactionTypeExpense,
+		IsRefund:    true,
+		Amount:      5.00,
+		Currency:    "USD",
+		CategoryID:  category.ID,
+		Description: "Returned item",
+		Date:        time.Now(),
+	}))
+	deleted := test.CreateTestTransaction(t, db, 1000.00, category.ID)
+	require.NoError(t, txService.Delete(t.Context(), deleted.ID))
+	unused := test.CreateTestCategory(t, db, "Unused", models.TransactionTypeExpense)
+
+	categories, err = service.GetAllWithUsageCount(t.Context())
+	require.NoError(t, err)
+	totals := make(map[uint]float64)
+	for _, cat := range categories {
+		totals[cat.ID] = cat.Total
+	}
+	assert.InDelta(t, 125.00, totals[category.ID], 0.001)
+	assert.Contains(t, totals, unused.ID)
+	assert.Zero(t, totals[unused.ID])
+
+	// A split transaction counts once under each of its lines' categories,
+	// which can't be deleted while it is there
+	household := test.CreateTestCategory(t, db, "Household", models.TransactionTypeExpense)
+	require.NoError(t, txService.Create(t.Context(), &models.Transaction{
+		Type:        models.TransactionTypeExpense,
+		Amount:      60.00,
+		Currency:    "USD",
+		CategoryID:  category.ID,
+		Description: "Supermarket",
+		Date:        time.Now(),
+		Splits: []models.SplitItem{
+			{CategoryID: category.ID, Amount: 20},
+			{CategoryID: household.ID, Amount: 25},
+			{CategoryID: household.ID, Amount: 15},
+		},
+	}))
+
+	categories, err = service.GetAllWithUsageCount(t.Context())
+	require.NoError(t, err)
+	for _, cat := range categories {
+		switch cat.ID {
+		case category.ID:
+			assert.Equal(t, 6, cat.Count)
+			assert.InDelta(t, 145.00, cat.Total, 0.001)
+		case household.ID:
+			assert.Equal(t, 1, cat.Count)
+			assert.InDelta(t, 40.00, cat.Total, 0.001)
+		}
+	}
+	count, err := service.GetUsageCount(t.Context(), household.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+	assert.ErrorContains(t, service.Delete(t.Context(), household.ID), "1 transactions")
+}
+
+func TestCategoryService_DeleteAfterSplitTransactionDeleted(t *testing.T) {
+	db := test.SetupTestDB(t)
+	service := NewCategoryService(repository.NewCategoryRepository(db))
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	txService := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+
+	// The category's only use was a line of a split transaction that was
+	// deleted long ago, whose lines are still stored
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	household := test.CreateTestCategory(t, db, "Household", models.TransactionTypeExpense)
+	receipt := &models.Transaction{
+		Type:        models.TransactionTypeExpense,
+		Amount:      50.00,
+		Currency:    "USD",
+		CategoryID:  food.ID,
+		Description: "Supermarket",
+		Date:        time.Now().AddDate(0, 0, -30),
+		Splits: []models.SplitItem{
+			{CategoryID: food.ID, Amount: 30},
+			{CategoryID: household.ID, Amount: 20},
+		},
+	}
+	require.NoError(t, txService.Create(t.Context(), receipt))
+	require.NoError(t, txService.Delete(t.Context(), receipt.ID))
+	var lines int64
+	require.NoError(t, db.Model(&models.SplitItem{}).Where("category_id = ?", household.ID).Count(&lines).Error)
+	require.Equal(t, int64(1), lines)
+
+	count, err := service.GetUsageCount(t.Context(), household.ID)
+	require.NoError(t, err)
+	assert.Zero(t, count)
+	require.NoError(t, service.Delete(t.Context(), household.ID))
 }
 
 func TestCategoryService_Delete_PreventWithTransactions(t *testing.T) {
@@ -260,7 +612,7 @@ func TestCategoryService_Delete_PreventWithTransactions(t *testing.T) {
 		Type: models.TransactionTypeExpense,
 		Icon: "📁",
 	}
-	err = service.Create(category)
+	err = service.Create(t.Context(), category)
 	require.NoError(t, err)
 
 	// Create a transaction
@@ -272,16 +624,16 @@ func TestCategoryService_Delete_PreventWithTransactions(t *testing.T) {
 		Description: "Test transaction",
 		Date:        time.Now(),
 	}
-	err = txService.Create(tx)
+	err = txService.Create(t.Context(), tx)
 	require.NoError(t, err)
 
 	// Attempt to delete category with transactions
-	err = service.Delete(category.ID)
+	err = service.Delete(t.Context(), category.ID)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cannot delete category with")
 
 	// Verify category still exists
-	_, err = service.GetByID(category.ID)
+	_, err = service.GetByID(t.Context(), category.ID)
 	assert.NoError(t, err)
 }
 
@@ -297,15 +649,63 @@ func TestCategoryService_Delete_PreventDefault(t *testing.T) {
 		Icon:      "🍔",
 		IsDefault: true,
 	}
-	err := repo.Create(defaultCategory)
+	err := repo.Create(t.Context(), defaultCategory)
 	require.NoError(t, err)
 
 	// Attempt to delete default category
-	err = service.Delete(defaultCategory.ID)
+	err = service.Delete(t.Context(), defaultCategory.ID)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cannot delete default category")
 
 	// Verify category still exists
-	_, err = service.GetByID(defaultCategory.ID)
+	_, err = service.GetByID(t.Context(), defaultCategory.ID)
 	assert.NoError(t, err)
-}
\ No newline at end of file
+}
+func TestCategoryService_FindSimilarCategories(t *testing.T) {
+	db := test.SetupTestDB(t)
+	service := NewCategoryService(repository.NewCategoryRepository(db))
+	
+	expense := func(name string) *models.Category {
+		return test.CreateTestCategory(t, db, name, models.TransactionTypeExpense)
+	}
+	food := expense("Food")
+	foods := expense("Foods")
+	groceries := expense("Groceries")
+	grocery := expense("Grocery")
+	eatingOut := expense("Eating Out")
+	eatingOutDashed := expense("eating-out")
+	expense("Car")
+	expense("Cat")
+	expense("Rent")
+	
+	// Same name, other type: not a duplicate
+	test.CreateTestCategory(t, db, "Food", models.TransactionTypeIncome)
+	
+	// A custom category like a default one is grouped with it, as it can be
+	// merged into the default
+	housing := &models.Category{Name: "Housing", Type: models.TransactionTypeExpense, IsDefault: true}
+	require.NoError(t, db.Create(housing).Error)
+	housingCustom := expense("Housng")
+	
+	groups, err := service.FindSimilarCategories(t.Context())
+	require.NoError(t, err)
+	
+	var names [][]string
+	for _, group := range groups {
+		var groupNames []string
+		for _, category := range group {
+			groupNames = append(groupNames, category.Name)
+		}
+		names = append(names, groupNames)
+	}
+	assert.ElementsMatch(t, [][]string{
+		{eatingOut.Name, eatingOutDashed.Name},
+		{food.Name, foods.Name},
+		{groceries.Name, grocery.Name},
+		{housing.Name, housingCustom.Name},
+	}, names)
+	
+	assert.True(t, similarNames(normalizeCategoryName("Subscriptions"), normalizeCategoryName("Subscription")))
+	assert.False(t, similarNames(normalizeCategoryName("Car"), normalizeCategoryName("Cat")))
+	assert.Equal(t, 3, editDistance("kitten", "sitting"))
+}
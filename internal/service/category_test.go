@@ -24,7 +24,7 @@ func TestCategoryService_Create(t *testing.T) {
 		Color: "#FF5722",
 	}
 	
-	err := service.Create(category)
+	err := service.Create(t.Context(), category)
 	require.NoError(t, err)
 	assert.Greater(t, category.ID, uint(0))
 	
@@ -35,11 +35,27 @@ func TestCategoryService_Create(t *testing.T) {
 		Icon: "🛒",
 	}
 	
-	err = service.Create(duplicate)
+	err = service.Create(t.Context(), duplicate)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "already exists")
 }
 
+func TestCategoryService_CreateIconLength(t *testing.T) {
+	db := test.SetupTestDB(t)
+	service := NewCategoryService(repository.NewCategoryRepository(db))
+	
+	// Icons are limited in runes, so emoji made of several code points fit
+	family := &models.Category{Name: "Family", Type: models.TransactionTypeExpense, Icon: "👨‍👩‍👧‍👦"}
+	require.NoError(t, service.Create(t.Context(), family))
+	saved, err := service.GetByID(t.Context(), family.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "👨‍👩‍👧‍👦", saved.Icon)
+	
+	err = service.Create(t.Context(), &models.Category{Name: "Long", Type: models.TransactionTypeExpense, Icon: "🍎🍐🍊🍋🍌🍉🍇🍓🍒"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "icon")
+}
+
 func TestCategoryService_Delete(t *testing.T) {
 	db := test.SetupTestDB(t)
 	repo := repository.NewCategoryRepository(db)
@@ -49,7 +65,7 @@ func TestCategoryService_Delete(t *testing.T) {
 	category := test.CreateTestCategory(t, db, "Test Category", models.TransactionTypeExpense)
 	
 	// Should delete successfully when no transactions
-	err := service.Delete(category.ID)
+	err := service.Delete(t.Context(), category.ID)
 	require.NoError(t, err)
 	
 	// Create another category with transaction
@@ -57,7 +73,7 @@ func TestCategoryService_Delete(t *testing.T) {
 	test.CreateTestTransaction(t, db, 100.00, category2.ID)
 	
 	// Should not delete when has transactions
-	err = service.Delete(category2.ID)
+	err = service.Delete(t.Context(), category2.ID)
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "cannot delete category with")
 }
@@ -94,7 +110,7 @@ func TestCategoryService_GetWithTotals(t *testing.T) {
 	require.NoError(t, db.Create(tx).Error)
 	
 	// Get with totals
-	totals, err := service.GetWithTotals(start, end)
+	totals, err := service.GetWithTotals(t.Context(), start, end)
 	require.NoError(t, err)
 	
 	// Find categories in results
@@ -131,21 +147,233 @@ func TestCategoryService_EnsureDefaultCategories(t *testing.T) {
 	service := NewCategoryService(repo)
 	
 	// Ensure defaults
-	err := service.EnsureDefaultCategories()
+	created, err := service.EnsureDefaultCategories(t.Context())
 	require.NoError(t, err)
+	assert.Len(t, created, len(models.GetDefaultCategories()))
 	
 	// Check they were created
-	categories, err := service.GetAll()
+	categories, err := service.GetAll(t.Context())
 	require.NoError(t, err)
 	
 	// Should have all default categories
 	assert.GreaterOrEqual(t, len(categories), len(models.GetDefaultCategories()))
 	
 	// Run again - should not duplicate
-	err = service.EnsureDefaultCategories()
+	created, err = service.EnsureDefaultCategories(t.Context())
 	require.NoError(t, err)
+	assert.Empty(t, created)
 	
-	categories2, err := service.GetAll()
+	categories2, err := service.GetAll(t.Context())
 	require.NoError(t, err)
 	assert.Equal(t, len(categories), len(categories2))
-}
\ No newline at end of file
+}
+
+func TestCategoryService_SeedOnFirstRun(t *testing.T) {
+	db := test.SetupTestDB(t)
+	service := NewCategoryService(repository.NewCategoryRepository(db))
+	
+	created, err := service.SeedOnFirstRun(t.Context())
+	require.NoError(t, err)
+	require.Len(t, created, len(models.GetDefaultCategories()))
+	
+	var income int
+	for _, category := range created {
+		assert.NotZero(t, category.ID)
+		if category.Type == models.TransactionTypeIncome {
+			income++
+		}
+	}
+	assert.Equal(t, len(models.DefaultIncomeCategories), income)
+	
+	// Not a first run any more
+	created, err = service.SeedOnFirstRun(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, created)
+	
+	// Categories deleted by the user are not brought back
+	other := test.SetupTestDB(t)
+	otherService := NewCategoryService(repository.NewCategoryRepository(other))
+	custom := test.CreateTestCategory(t, other, "Custom", models.TransactionTypeExpense)
+	require.NoError(t, other.Delete(custom).Error)
+	created, err = otherService.SeedOnFirstRun(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, created)
+}
+
+func TestCategoryService_MoveCategory(t *testing.T) {
+	db := test.SetupTestDB(t)
+	service := NewCategoryService(repository.NewCategoryRepository(db))
+	
+	create := func(name string, txType models.TransactionType) *models.Category {
+		category := &models.Category{Name: name, Type: txType}
+		require.NoError(t, service.Create(t.Context(), category))
+		return category
+	}
+	names := func(txType models.TransactionType) []string {
+		categories, err := service.GetByType(t.Context(), txType)
+		require.NoError(t, err)
+		var result []string
+		for _, category := range categories {
+			result = append(result, category.Name)
+		}
+		return result
+	}
+	
+	create("Alpha", models.TransactionTypeExpense)
+	create("Bravo", models.TransactionTypeExpense)
+	charlie := create("Charlie", models.TransactionTypeExpense)
+	create("Salary", models.TransactionTypeIncome)
+	
+	// Alphabetical until reordered
+	assert.Equal(t, []string{"Alpha", "Bravo", "Charlie"}, names(models.TransactionTypeExpense))
+	
+	require.NoError(t, service.MoveCategory(t.Context(), charlie.ID, -1))
+	require.NoError(t, service.MoveCategory(t.Context(), charlie.ID, -1))
+	assert.Equal(t, []string{"Charlie", "Alpha", "Bravo"}, names(models.TransactionTypeExpense))
+	
+	// Moving past the top does nothing
+	require.NoError(t, service.MoveCategory(t.Context(), charlie.ID, -1))
+	assert.Equal(t, []string{"Charlie", "Alpha", "Bravo"}, names(models.TransactionTypeExpense))
+	
+	// New categories go last and edits keep the order
+	create("Aardvark", models.TransactionTypeExpense)
+	charlie.Name = "Charlie's"
+	require.NoError(t, service.Update(t.Context(), charlie))
+	assert.Equal(t, []string{"Charlie's", "Alpha", "Bravo", "Aardvark"}, names(models.TransactionTypeExpense))
+	
+	// The order holds across all categories, by type
+	all, err := service.GetAll(t.Context())
+	require.NoError(t, err)
+	var allNames []string
+	for _, category := range all {
+		allNames = append(allNames, category.Name)
+	}
+	assert.Equal(t, []string{"Charlie's", "Alpha", "Bravo", "Aardvark", "Salary"}, allNames)
+	
+	// So do default categories created outside Create
+	created, err := service.EnsureDefaultCategories(t.Context())
+	require.NoError(t, err)
+	require.NotEmpty(t, created)
+	expenses := names(models.TransactionTypeExpense)
+	assert.Equal(t, []string{"Charlie's", "Alpha", "Bravo", "Aardvark"}, expenses[:4])
+	assert.Equal(t, models.DefaultExpenseCategories[0].Name, expenses[4])
+}
+
+func TestCategoryService_SetArchived(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewCategoryRepository(db)
+	service := NewCategoryService(repo)
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	tools := test.CreateTestCategory(t, db, "AI Tools", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	tools.IsDefault = true
+	require.NoError(t, db.Save(tools).Error)
+	
+	// Defaults can be archived
+	require.NoError(t, service.SetArchived(t.Context(), tools.ID, true))
+	
+	byType, err := service.GetByType(t.Context(), models.TransactionTypeExpense)
+	require.NoError(t, err)
+	require.Len(t, byType, 1)
+	assert.Equal(t, food.ID, byType[0].ID)
+	
+	pickable, err := service.GetPickable(t.Context(), "")
+	require.NoError(t, err)
+	assert.Len(t, pickable, 2)
+	
+	// Editing keeps an archived category selected, of the right type only
+	pickable, err = service.GetPickable(t.Context(), models.TransactionTypeExpense, tools.ID)
+	require.NoError(t, err)
+	require.Len(t, pickable, 2)
+	assert.Equal(t, tools.ID, pickable[1].ID)
+	pickable, err = service.GetPickable(t.Context(), models.TransactionTypeIncome, tools.ID)
+	require.NoError(t, err)
+	require.Len(t, pickable, 1)
+	assert.Equal(t, salary.ID, pickable[0].ID)
+	
+	// Still listed for reports, and editing doesn't unarchive it
+	all, err := service.GetAll(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, all, 3)
+	require.NoError(t, service.SetArchived(t.Context(), food.ID, true))
+	food.Name = "Groceries"
+	require.NoError(t, service.Update(t.Context(), food))
+	food, err = service.GetByID(t.Context(), food.ID)
+	require.NoError(t, err)
+	assert.True(t, food.IsArchived)
+	
+	// Its name can't be reused while archived
+	err = service.Create(t.Context(), &models.Category{Name: "AI Tools", Type: models.TransactionTypeExpense})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "archived")
+	
+	require.NoError(t, service.SetArchived(t.Context(), tools.ID, false))
+	history, err := service.GetHistoryLog(t.Context(), tools.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, models.CategoryActionArchived, history[0].Action)
+	assert.Equal(t, models.CategoryActionUnarchived, history[1].Action)
+}
+
+func TestCategoryService_SetPinned(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewCategoryRepository(db)
+	service := NewCategoryService(repo)
+	
+	coffee := test.CreateTestCategory(t, db, "Coffee", models.TransactionTypeExpense)
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	rent := test.CreateTestCategory(t, db, "Rent", models.TransactionTypeExpense)
+	
+	require.NoError(t, service.SetPinned(t.Context(), rent.ID, true))
+	require.NoError(t, service.SetPinned(t.Context(), food.ID, true))
+	
+	// Pinned categories come first, each group keeping its own order
+	pickable, err := service.GetPickable(t.Context(), models.TransactionTypeExpense)
+	require.NoError(t, err)
+	require.Len(t, pickable, 3)
+	assert.Equal(t, []uint{food.ID, rent.ID, coffee.ID}, []uint{pickable[0].ID, pickable[1].ID, pickable[2].ID})
+	
+	// Editing keeps the pin
+	rent.Name = "Housing"
+	require.NoError(t, service.Update(t.Context(), rent))
+	rent, err = service.GetByID(t.Context(), rent.ID)
+	require.NoError(t, err)
+	assert.True(t, rent.IsPinned)
+	
+	require.NoError(t, service.SetPinned(t.Context(), rent.ID, false))
+	pickable, err = service.GetPickable(t.Context(), models.TransactionTypeExpense)
+	require.NoError(t, err)
+	assert.Equal(t, food.ID, pickable[0].ID)
+	assert.Equal(t, coffee.ID, pickable[1].ID)
+	
+	assert.Error(t, service.SetPinned(t.Context(), 9999, true))
+}
+
+func TestCategoryService_DefaultCurrency(t *testing.T) {
+	db := test.SetupTestDB(t)
+	service := NewCategoryService(repository.NewCategoryRepository(db))
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service.SetCurrencyService(NewCurrencyService(settingsService))
+	require.NoError(t, settingsService.EnableCurrency("AED"))
+	
+	rent := &models.Category{Name: "AED Rent", Type: models.TransactionTypeExpense, DefaultCurrency: " aed "}
+	require.NoError(t, service.Create(t.Context(), rent))
+	saved, err := service.GetByID(t.Context(), rent.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "AED", saved.DefaultCurrency)
+	
+	// Only enabled currencies can be a default
+	saved.DefaultCurrency = "THB"
+	err = service.Update(t.Context(), saved)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "THB is not enabled")
+	
+	// Clearing it goes back to the settings' default
+	saved.DefaultCurrency = ""
+	require.NoError(t, service.Update(t.Context(), saved))
+	saved, err = service.GetByID(t.Context(), rent.ID)
+	require.NoError(t, err)
+	assert.Empty(t, saved.DefaultCurrency)
+}
@@ -1,11 +1,18 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
+	"log"
 	"net/http"
+	"os"
+	"sort"
 	"sync"
 	"time"
+	"unicode"
+
+	"burnwise/internal/models"
 )
 
 type exchangeRateResponse struct {
@@ -14,11 +21,28 @@ type exchangeRateResponse struct {
 	TimeLastUpdateUnix int64              `json:"time_last_update_unix"`
 }
 
+// exchangeRateURL is the API the rates are fetched from, in units per USD
+const exchangeRateURL = "https://api.exchangerate-api.com/v4/latest/USD"
+
+// rateRetryAfter is how long the API is left alone after a failed fetch,
+// so that every conversion while offline doesn't wait for it to time out
+const rateRetryAfter = 5 * time.Minute
+
 type CurrencyService struct {
 	cache          map[string]*rateCache
+	// fileRates are the rates loaded from the rates file, kept apart from
+	// the fetched ones so that a refresh doesn't replace them
+	fileRates      map[string]*rateCache
 	cacheMutex     sync.RWMutex
 	apiKey         string
+	apiURL         string
 	settingsService *SettingsService
+	logger         *log.Logger
+	// cachePath is the file fetched rates are kept in between runs, if any
+	cachePath      string
+	// failedAt and failure are when and why the last fetch failed
+	failedAt       time.Time
+	failure        error
 }
 
 type rateCache struct {
@@ -29,30 +53,58 @@ type rateCache struct {
 func NewCurrencyService(settingsService *SettingsService) *CurrencyService {
 	return &CurrencyService{
 		cache:           make(map[string]*rateCache),
+		fileRates:       make(map[string]*rateCache),
 		apiKey:          "free", // Using free tier
+		apiURL:          exchangeRateURL,
 		settingsService: settingsService,
 	}
 }
 
-func (s *CurrencyService) ConvertToUSD(amount float64, currency string) (float64, error) {
+// SetLogger enables logging when a stale rate is used because the API
+// can't be reached
+func (s *CurrencyService) SetLogger(logger *log.Logger) {
+	s.logger = logger
+}
+
+// ConvertToUSD converts amount from currency to USD. Converted amounts are
+// rounded to cents, see RoundUSD.
+func (s *CurrencyService) ConvertToUSD(ctx context.Context, amount float64, currency string) (float64, error) {
+	amountUSD, _, err := s.ConvertToUSDWithInfo(ctx, amount, currency)
+	return amountUSD, err
+}
+
+// ConvertToUSDWithInfo converts like ConvertToUSD and also tells where the
+// rate came from. USD needs no rate, so its source is empty.
+func (s *CurrencyService) ConvertToUSDWithInfo(ctx context.Context, amount float64, currency string) (float64, models.RateSource, error) {
 	if currency == "USD" {
-		return amount, nil
+		return amount, "", nil
 	}
 
-	rate, err := s.GetExchangeRate(currency)
+	rate, source, err := s.GetExchangeRateWithSource(ctx, currency)
 	if err != nil {
-		return 0, err
+		return 0, "", err
 	}
 
-	return amount / rate, nil
+	return s.RoundUSD(amount / rate), source, nil
+}
+
+// RoundUSD rounds a USD amount to cents with the rounding mode from the
+// settings. Converted amounts are rounded before they are stored, so that
+// totals add up to the cents shown for each transaction.
+func (s *CurrencyService) RoundUSD(amount float64) float64 {
+	var mode models.RoundingMode
+	if s.settingsService != nil {
+		mode = s.settingsService.GetRoundingMode()
+	}
+	return mode.Round(amount)
 }
 
-func (s *CurrencyService) ConvertFromUSD(amount float64, currency string) (float64, error) {
+func (s *CurrencyService) ConvertFromUSD(ctx context.Context, amount float64, currency string) (float64, error) {
 	if currency == "USD" {
 		return amount, nil
 	}
 
-	rate, err := s.GetExchangeRate(currency)
+	rate, err := s.GetExchangeRate(ctx, currency)
 	if err != nil {
 		return 0, err
 	}
@@ -60,24 +112,56 @@ func (s *CurrencyService) ConvertFromUSD(amount float64, currency string) (float
 	return amount * rate, nil
 }
 
-func (s *CurrencyService) GetExchangeRate(currency string) (float64, error) {
+func (s *CurrencyService) GetExchangeRate(ctx context.Context, currency string) (float64, error) {
+	rate, _, err := s.GetExchangeRateWithSource(ctx, currency)
+	return rate, err
+}
+
+// GetExchangeRateWithSource returns the units of currency per USD and where
+// that rate came from. When the API can't be reached, the last rate fetched
+// or loaded for the currency is used however old it is, reported as
+// models.RateSourceStale for an API rate; only without any rate does it
+// fail. A rate from the rates file comes before any fetched one while it
+// is less than an hour old, and before a stale one after that.
+func (s *CurrencyService) GetExchangeRateWithSource(ctx context.Context, currency string) (float64, models.RateSource, error) {
 	// Check for fixed rates in settings
 	if rate, exists := s.settingsService.GetFixedRate(currency); exists {
-		return rate, nil
+		return rate, models.RateSourceFixed, nil
 	}
 
+	preferFile := s.settingsService.PreferFileRates()
+
 	s.cacheMutex.RLock()
-	if cached, ok := s.cache[currency]; ok {
-		if time.Since(cached.timestamp) < time.Hour {
-			s.cacheMutex.RUnlock()
-			return cached.rate, nil
-		}
-	}
+	fileRate, fromFile := s.fileRates[currency]
+	cached, ok := s.cache[currency]
 	s.cacheMutex.RUnlock()
+	// File rates never expire when they are preferred over the API
+	if fromFile && (preferFile || time.Since(fileRate.timestamp) < time.Hour) {
+		return fileRate.rate, models.RateSourceFile, nil
+	}
 
-	rate, err := s.fetchExchangeRate(currency)
+	if preferFile {
+		return 0, "", fmt.Errorf("no exchange rate for %s in the rates file", currency)
+	}
+
+	if ok && time.Since(cached.timestamp) < time.Hour {
+		return cached.rate, models.RateSourceCache, nil
+	}
+
+	rate, err := s.fetchUnlessFailedRecently(ctx, currency)
 	if err != nil {
-		return 0, err
+		// A cancelled caller wants no rate at all, not a stale one
+		if !(ok || fromFile) || ctx.Err() != nil {
+			return 0, "", err
+		}
+		if fromFile {
+			return fileRate.rate, models.RateSourceFile, nil
+		}
+		if s.logger != nil {
+			s.logger.Printf("Warning: using the %s rate from %s ago: %v",
+				currency, time.Since(cached.timestamp).Round(time.Minute), err)
+		}
+		return cached.rate, models.RateSourceStale, nil
 	}
 
 	s.cacheMutex.Lock()
@@ -85,16 +169,192 @@ func (s *CurrencyService) GetExchangeRate(currency string) (float64, error) {
 		rate:      rate,
 		timestamp: time.Now(),
 	}
+	err = s.saveRatesCache()
 	s.cacheMutex.Unlock()
+	if err != nil && s.logger != nil {
+		s.logger.Printf("Warning: %v", err)
+	}
 
-	return rate, nil
+	return rate, models.RateSourceLive, nil
 }
 
-func (s *CurrencyService) fetchExchangeRate(currency string) (float64, error) {
-	url := fmt.Sprintf("https://api.exchangerate-api.com/v4/latest/USD")
-	
+// KnownRate returns the rate for the currency without contacting the API:
+// a fixed rate, or the last one fetched or loaded from the rates file,
+// however old it is
+func (s *CurrencyService) KnownRate(currency string) (float64, bool) {
+	if rate, exists := s.settingsService.GetFixedRate(currency); exists {
+		return rate, true
+	}
+
+	s.cacheMutex.RLock()
+	defer s.cacheMutex.RUnlock()
+	if fileRate, ok := s.fileRates[currency]; ok {
+		return fileRate.rate, true
+	}
+	if cached, ok := s.cache[currency]; ok {
+		return cached.rate, true
+	}
+	return 0, false
+}
+
+// fetchUnlessFailedRecently fetches the rate, unless a fetch failed less
+// than rateRetryAfter ago, in which case that failure is returned again
+// straight away
+func (s *CurrencyService) fetchUnlessFailedRecently(ctx context.Context, currency string) (float64, error) {
+	s.cacheMutex.RLock()
+	failedAt, failure := s.failedAt, s.failure
+	s.cacheMutex.RUnlock()
+	if failure != nil && time.Since(failedAt) < rateRetryAfter {
+		return 0, failure
+	}
+
+	rate, err := s.fetchExchangeRate(ctx, currency)
+	// A cancelled fetch says nothing about the API
+	if err != nil && ctx.Err() != nil {
+		return 0, err
+	}
+
+	s.cacheMutex.Lock()
+	if err != nil {
+		s.failedAt, s.failure = time.Now(), err
+	} else {
+		s.failure = nil
+	}
+	s.cacheMutex.Unlock()
+	return rate, err
+}
+
+// cachedRate is a fetched rate as kept in the rates cache file
+type cachedRate struct {
+	Rate      float64   `json:"rate"`
+	FetchedAt time.Time `json:"fetched_at"`
+}
+
+// SetRatesCachePath keeps the rates fetched from the API in a file, so the
+// last ones are still there to fall back on after a restart while offline.
+// Rates already in the file are loaded with the time they were fetched.
+func (s *CurrencyService) SetRatesCachePath(path string) error {
+	s.cacheMutex.Lock()
+	defer s.cacheMutex.Unlock()
+	s.cachePath = path
+
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read exchange rate cache: %w", err)
+	}
+
+	var rates map[string]cachedRate
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return fmt.Errorf("failed to parse exchange rate cache %s: %w", path, err)
+	}
+	for currency, cached := range rates {
+		if _, loaded := s.cache[currency]; loaded || cached.Rate <= 0 {
+			continue
+		}
+		s.cache[currency] = &rateCache{rate: cached.Rate, timestamp: cached.FetchedAt}
+	}
+	return nil
+}
+
+// saveRatesCache writes the fetched rates to the cache file, if there is
+// one; the caller must hold the lock
+func (s *CurrencyService) saveRatesCache() error {
+	if s.cachePath == "" {
+		return nil
+	}
+
+	rates := make(map[string]cachedRate)
+	for currency, cached := range s.cache {
+		rates[currency] = cachedRate{Rate: cached.rate, FetchedAt: cached.timestamp}
+	}
+	data, err := json.MarshalIndent(rates, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal exchange rate cache: %w", err)
+	}
+
+	tempPath := s.cachePath + ".tmp"
+	if err := os.WriteFile(tempPath, data, 0644); err != nil {
+		return fmt.Errorf("failed to write exchange rate cache: %w", err)
+	}
+	if err := os.Rename(tempPath, s.cachePath); err != nil {
+		os.Remove(tempPath)
+		return fmt.Errorf("failed to save exchange rate cache: %w", err)
+	}
+	return nil
+}
+
+// LoadRatesFromFile loads the rates from a JSON file mapping currency
+// codes to units per USD, e.g. {"EUR": 0.92, "GBP": 0.79}. The whole file is
+// validated before any rate is used. It returns the loaded currencies.
+func (s *CurrencyService) LoadRatesFromFile(path string) ([]string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read rates file: %w", err)
+	}
+
+	var rates map[string]float64
+	if err := json.Unmarshal(data, &rates); err != nil {
+		return nil, fmt.Errorf("failed to parse rates file: expected an object of currency codes to rates: %w", err)
+	}
+	if len(rates) == 0 {
+		return nil, fmt.Errorf("rates file %s has no rates", path)
+	}
+
+	currencies := make([]string, 0, len(rates))
+	for currency, rate := range rates {
+		if !isCurrencyCode(currency) {
+			return nil, fmt.Errorf("invalid currency code %q in rates file", currency)
+		}
+		if rate <= 0 {
+			return nil, fmt.Errorf("rate for %s must be positive, got %v", currency, rate)
+		}
+		if currency == "USD" {
+			if rate != 1 {
+				return nil, fmt.Errorf("rate for USD must be 1, got %v", rate)
+			}
+			continue
+		}
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+
+	now := time.Now()
+	s.cacheMutex.Lock()
+	for _, currency := range currencies {
+		s.fileRates[currency] = &rateCache{
+			rate:      rates[currency],
+			timestamp: now,
+		}
+	}
+	s.cacheMutex.Unlock()
+
+	return currencies, nil
+}
+
+// isCurrencyCode reports whether code looks like an ISO 4217 code
+func isCurrencyCode(code string) bool {
+	if len(code) != 3 {
+		return false
+	}
+	for _, r := range code {
+		if !unicode.IsUpper(r) || r > unicode.MaxASCII {
+			return false
+		}
+	}
+	return true
+}
+
+func (s *CurrencyService) fetchExchangeRate(ctx context.Context, currency string) (float64, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, s.apiURL, nil)
+	if err != nil {
+		return 0, fmt.Errorf("failed to create request: %w", err)
+	}
+
 	client := &http.Client{Timeout: 10 * time.Second}
-	resp, err := client.Get(url)
+	resp, err := client.Do(req)
 	if err != nil {
 		return 0, fmt.Errorf("failed to fetch exchange rate: %w", err)
 	}
@@ -124,6 +384,14 @@ func (s *CurrencyService) GetSupportedCurrencies() []string {
 	return s.settingsService.GetEnabledCurrencies()
 }
 
+// GetDefaultCurrency returns the currency new transactions start with
+func (s *CurrencyService) GetDefaultCurrency() string {
+	if currency := s.settingsService.GetDefaultCurrency(); currency != "" {
+		return currency
+	}
+	return "USD"
+}
+
 func (s *CurrencyService) IsSupported(currency string) bool {
 	return s.settingsService.IsCurrencyEnabled(currency)
 }
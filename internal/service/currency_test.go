@@ -1,7 +1,17 @@
 package service
 
 import (
+	"bytes"
+	"io"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
 	"testing"
+	"time"
+
+	"burnwise/internal/models"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -14,12 +24,12 @@ func TestCurrencyService_FixedRate(t *testing.T) {
 	service := NewCurrencyService(settingsService)
 	
 	// Test AED to USD (fixed rate)
-	usdAmount, err := service.ConvertToUSD(100.00, "AED")
+	usdAmount, err := service.ConvertToUSD(t.Context(), 100.00, "AED")
 	require.NoError(t, err)
 	assert.InDelta(t, 27.23, usdAmount, 0.01)
 	
 	// Test USD to AED
-	aedAmount, err := service.ConvertFromUSD(100.00, "AED")
+	aedAmount, err := service.ConvertFromUSD(t.Context(), 100.00, "AED")
 	require.NoError(t, err)
 	assert.InDelta(t, 367.25, aedAmount, 0.01)
 }
@@ -31,15 +41,47 @@ func TestCurrencyService_USDConversion(t *testing.T) {
 	service := NewCurrencyService(settingsService)
 	
 	// Test USD to USD (should return same amount)
-	amount, err := service.ConvertToUSD(100.00, "USD")
+	amount, err := service.ConvertToUSD(t.Context(), 100.00, "USD")
 	require.NoError(t, err)
 	assert.Equal(t, 100.00, amount)
 	
-	amount, err = service.ConvertFromUSD(100.00, "USD")
+	amount, err = service.ConvertFromUSD(t.Context(), 100.00, "USD")
 	require.NoError(t, err)
 	assert.Equal(t, 100.00, amount)
 }
 
+func TestCurrencyService_RoundingMode(t *testing.T) {
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, settingsService.SetFixedRate("EUR", 2))
+	service := NewCurrencyService(settingsService)
+	
+	convert := func(amount float64) float64 {
+		usd, err := service.ConvertToUSD(t.Context(), amount, "EUR")
+		require.NoError(t, err)
+		return usd
+	}
+	
+	// Half up by default
+	assert.Equal(t, models.RoundHalfUp, settingsService.GetRoundingMode())
+	assert.Equal(t, 0.13, convert(0.25))
+	assert.Equal(t, 0.18, convert(0.35))
+	assert.Equal(t, 27.23, convert(54.455))
+	
+	require.NoError(t, settingsService.SetRoundingMode(models.RoundHalfEven))
+	assert.Equal(t, 0.12, convert(0.25))
+	assert.Equal(t, 0.18, convert(0.35))
+	
+	require.NoError(t, settingsService.SetRoundingMode(models.RoundDown))
+	assert.Equal(t, 0.12, convert(0.25))
+	assert.Equal(t, 0.17, convert(0.35))
+	// 2.30 / 2 is 1.15, which float math would otherwise drop to 1.14
+	assert.Equal(t, 1.15, convert(2.30))
+	
+	assert.Error(t, settingsService.SetRoundingMode("up"))
+	assert.Equal(t, models.RoundDown, settingsService.GetRoundingMode())
+}
+
 func TestCurrencyService_SupportedCurrencies(t *testing.T) {
 	tempDir := t.TempDir()
 	settingsService, err := NewSettingsService(tempDir)
@@ -54,4 +96,218 @@ func TestCurrencyService_SupportedCurrencies(t *testing.T) {
 	assert.True(t, service.IsSupported("USD"))
 	assert.True(t, service.IsSupported("AED"))
 	assert.False(t, service.IsSupported("XXX"))
-}
\ No newline at end of file
+}
+
+func TestCurrencyService_LoadRatesFromFile(t *testing.T) {
+	tempDir := t.TempDir()
+	settingsService, err := NewSettingsService(tempDir)
+	require.NoError(t, err)
+	require.NoError(t, settingsService.Update(func(settings *models.Settings) error {
+		settings.Currencies.PreferFileRates = true
+		return nil
+	}))
+	service := NewCurrencyService(settingsService)
+
+	writeRates := func(content string) string {
+		path := filepath.Join(t.TempDir(), "rates.json")
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+		return path
+	}
+
+	loaded, err := service.LoadRatesFromFile(writeRates(`{"GBP": 0.8, "EUR": 0.9, "USD": 1}`))
+	require.NoError(t, err)
+	assert.Equal(t, []string{"EUR", "GBP"}, loaded)
+
+	usdAmount, err := service.ConvertToUSD(t.Context(), 90, "EUR")
+	require.NoError(t, err)
+	assert.InDelta(t, 100.0, usdAmount, 0.001)
+
+	// Fixed rates from the settings still win
+	usdAmount, err = service.ConvertToUSD(t.Context(), 100.00, "AED")
+	require.NoError(t, err)
+	assert.InDelta(t, 27.23, usdAmount, 0.01)
+
+	// Currencies missing from the file are not fetched
+	_, err = service.ConvertToUSD(t.Context(), 100, "JPY")
+	assert.ErrorContains(t, err, "no exchange rate for JPY in the rates file")
+
+	t.Run("invalid files load nothing", func(t *testing.T) {
+		for content, want := range map[string]string{
+			`["EUR", 0.9]`:              "failed to parse rates file",
+			`{}`:                        "has no rates",
+			`{"CHF": 0.85, "eur": 0.9}`: `invalid currency code "eur"`,
+			`{"CHF": 0.85, "SEK": -10}`: "rate for SEK must be positive",
+			`{"CHF": 0.85, "USD": 1.1}`: "rate for USD must be 1",
+		} {
+			_, err := service.LoadRatesFromFile(writeRates(content))
+			assert.ErrorContains(t, err, want, content)
+		}
+
+		_, err := service.ConvertToUSD(t.Context(), 100, "CHF")
+		assert.Error(t, err)
+	})
+
+	t.Run("missing file", func(t *testing.T) {
+		_, err := service.LoadRatesFromFile(filepath.Join(t.TempDir(), "missing.json"))
+		assert.ErrorContains(t, err, "failed to read rates file")
+	})
+}
+
+func TestCurrencyService_StaleRateWhenAPIFails(t *testing.T) {
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewCurrencyService(settingsService)
+	var logged bytes.Buffer
+	service.SetLogger(log.New(&logged, "", 0))
+	
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"rates": {"EUR": 0.9}}`))
+	}))
+	defer server.Close()
+	service.apiURL = server.URL
+	
+	rate, source, err := service.GetExchangeRateWithSource(t.Context(), "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, 0.9, rate)
+	assert.Equal(t, models.RateSourceLive, source)
+	
+	// Once the cached rate has expired and the API fails, it is still used
+	service.cache["EUR"].timestamp = time.Now().Add(-3 * time.Hour)
+	failing = true
+	rate, source, err = service.GetExchangeRateWithSource(t.Context(), "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, 0.9, rate)
+	assert.Equal(t, models.RateSourceStale, source)
+	assert.Contains(t, logged.String(), "Warning: using the EUR rate from 3h0m0s ago: API returned status 503")
+	
+	// Without any rate for the currency the failure is returned
+	_, _, err = service.GetExchangeRateWithSource(t.Context(), "GBP")
+	assert.Error(t, err)
+}
+
+func TestCurrencyService_FileRateKeptAfterRefresh(t *testing.T) {
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewCurrencyService(settingsService)
+	service.SetLogger(log.New(io.Discard, "", 0))
+
+	failing := false
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if failing {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"rates": {"EUR": 0.9}}`))
+	}))
+	defer server.Close()
+	service.apiURL = server.URL
+
+	path := filepath.Join(t.TempDir(), "rates.json")
+	require.NoError(t, os.WriteFile(path, []byte(`{"EUR": 0.95}`), 0644))
+	_, err = service.LoadRatesFromFile(path)
+	require.NoError(t, err)
+
+	// The file rate comes first while it is fresh
+	rate, source, err := service.GetExchangeRateWithSource(t.Context(), "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, 0.95, rate)
+	assert.Equal(t, models.RateSourceFile, source)
+
+	// Then the API's
+	service.fileRates["EUR"].timestamp = time.Now().Add(-2 * time.Hour)
+	rate, source, err = service.GetExchangeRateWithSource(t.Context(), "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, 0.9, rate)
+	assert.Equal(t, models.RateSourceLive, source)
+
+	// Once that is stale and the API fails, the file rate is still there
+	// and used ahead of it
+	service.cache["EUR"].timestamp = time.Now().Add(-3 * time.Hour)
+	failing = true
+	rate, source, err = service.GetExchangeRateWithSource(t.Context(), "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, 0.95, rate)
+	assert.Equal(t, models.RateSourceFile, source)
+
+	// And once preferred, it is used without the API
+	require.NoError(t, settingsService.Update(func(settings *models.Settings) error {
+		settings.Currencies.PreferFileRates = true
+		return nil
+	}))
+	rate, source, err = service.GetExchangeRateWithSource(t.Context(), "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, 0.95, rate)
+	assert.Equal(t, models.RateSourceFile, source)
+}
+
+func TestCurrencyService_BacksOffAfterFailedFetch(t *testing.T) {
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewCurrencyService(settingsService)
+	service.SetLogger(log.New(io.Discard, "", 0))
+
+	requests := 0
+	failing := true
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		if failing {
+			http.Error(w, "unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.Write([]byte(`{"rates": {"EUR": 0.9}}`))
+	}))
+	defer server.Close()
+	service.apiURL = server.URL
+
+	_, _, err = service.GetExchangeRateWithSource(t.Context(), "EUR")
+	require.Error(t, err)
+	assert.Equal(t, 1, requests)
+
+	// Within the retry window the API isn't asked again
+	failing = false
+	_, _, err = service.GetExchangeRateWithSource(t.Context(), "EUR")
+	require.Error(t, err)
+	assert.Equal(t, 1, requests)
+
+	// Once the window has passed it is
+	service.failedAt = time.Now().Add(-rateRetryAfter)
+	rate, source, err := service.GetExchangeRateWithSource(t.Context(), "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, 0.9, rate)
+	assert.Equal(t, models.RateSourceLive, source)
+	assert.Equal(t, 2, requests)
+}
+
+func TestCurrencyService_RatesCacheSurvivesRestart(t *testing.T) {
+	dataDir := t.TempDir()
+	settingsService, err := NewSettingsService(dataDir)
+	require.NoError(t, err)
+	cachePath := filepath.Join(dataDir, "rates_cache.json")
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"rates": {"EUR": 0.9}}`))
+	}))
+	service := NewCurrencyService(settingsService)
+	require.NoError(t, service.SetRatesCachePath(cachePath))
+	service.apiURL = server.URL
+	_, _, err = service.GetExchangeRateWithSource(t.Context(), "EUR")
+	require.NoError(t, err)
+	server.Close()
+
+	// A new service offline falls back on the saved rate
+	restarted := NewCurrencyService(settingsService)
+	restarted.SetLogger(log.New(io.Discard, "", 0))
+	require.NoError(t, restarted.SetRatesCachePath(cachePath))
+	restarted.apiURL = server.URL
+	restarted.cache["EUR"].timestamp = time.Now().Add(-3 * time.Hour)
+
+	rate, source, err := restarted.GetExchangeRateWithSource(t.Context(), "EUR")
+	require.NoError(t, err)
+	assert.Equal(t, 0.9, rate)
+	assert.Equal(t, models.RateSourceStale, source)
+}
@@ -1,16 +1,24 @@
 package service
 
 import (
+	"context"
 	"encoding/csv"
 	"fmt"
 	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
 	"time"
 
 	"burnwise/internal/models"
 )
 
 type ExportService struct {
-	txService *TransactionService
+	txService       *TransactionService
+	budgetService   *BudgetService
+	settingsService *SettingsService
+	categoryService *CategoryService
 }
 
 func NewExportService(txService *TransactionService) *ExportService {
@@ -19,8 +27,27 @@ func NewExportService(txService *TransactionService) *ExportService {
 	}
 }
 
-func (s *ExportService) ExportTransactionsCSV(writer io.Writer, filter *models.TransactionFilter) error {
-	transactions, err := s.txService.GetByFilter(filter)
+// SetBudgetService enables the budget section of the Markdown report
+func (s *ExportService) SetBudgetService(budgetService *BudgetService) {
+	s.budgetService = budgetService
+}
+
+// SetSettingsService enables the tag breakdown of the monthly CSV report,
+// using the tag pattern saved in the settings at the time of the export
+func (s *ExportService) SetSettingsService(settingsService *SettingsService) {
+	s.settingsService = settingsService
+}
+
+// SetCategoryService enables the category and category history exports
+func (s *ExportService) SetCategoryService(categoryService *CategoryService) {
+	s.categoryService = categoryService
+}
+
+// ExportTransactionsCSV writes the transactions matching filter as CSV. A
+// transfer is one row, with the account the money left in "Account" and the
+// one it went to in "To Account".
+func (s *ExportService) ExportTransactionsCSV(ctx context.Context, writer io.Writer, filter *models.TransactionFilter) error {
+	transactions, err := s.txService.GetByFilter(ctx, filter)
 	if err != nil {
 		return fmt.Errorf("failed to get transactions: %w", err)
 	}
@@ -37,21 +64,59 @@ func (s *ExportService) ExportTransactionsCSV(writer io.Writer, filter *models.T
 		"Amount",
 		"Currency",
 		"Amount (USD)",
+		"Rate Source",
+		"Attachments",
+		"Account",
+		"To Account",
 	}
 	if err := csvWriter.Write(header); err != nil {
 		return fmt.Errorf("failed to write header: %w", err)
 	}
 
+	listed := make(map[uint]*models.Transaction, len(transactions))
+	for _, tx := range transactions {
+		listed[tx.ID] = tx
+	}
+
 	// Write transactions
 	for _, tx := range transactions {
+		account, toAccount := accountName(tx.Account), ""
+		if tx.Type == models.TransactionTypeTransfer && tx.TransferPairID != nil {
+			// The transfer is written from its out half, looking up the
+			// other half when the filter left it out
+			pair, ok := listed[*tx.TransferPairID]
+			if ok && tx.IsTransferIn {
+				continue
+			}
+			if !ok {
+				pair, err = s.txService.GetByID(ctx, *tx.TransferPairID)
+				if err != nil {
+					return fmt.Errorf("failed to get the other half of transfer #%d: %w", tx.ID, err)
+				}
+			}
+			if tx.IsTransferIn {
+				account, toAccount = accountName(pair.Account), account
+			} else {
+				toAccount = accountName(pair.Account)
+			}
+		}
+
+		paths := make([]string, len(tx.Attachments))
+		for i, attachment := range tx.Attachments {
+			paths[i] = attachment.Path
+		}
 		record := []string{
 			tx.Date.Format("2006-01-02"),
-			string(tx.Type),
+			tx.DisplayType(),
 			tx.Category.Name,
 			tx.Description,
-			fmt.Sprintf("%.2f", tx.Amount),
+			fmt.Sprintf("%.*f", models.CurrencyDecimals(tx.Currency), tx.Amount),
 			tx.Currency,
 			fmt.Sprintf("%.2f", tx.AmountUSD),
+			string(tx.RateSource),
+			strings.Join(paths, ";"),
+			account,
+			toAccount,
 		}
 		if err := csvWriter.Write(record); err != nil {
 			return fmt.Errorf("failed to write record: %w", err)
@@ -61,8 +126,63 @@ func (s *ExportService) ExportTransactionsCSV(writer io.Writer, filter *models.T
 	return nil
 }
 
-func (s *ExportService) ExportMonthlyReportCSV(writer io.Writer, year int, month time.Month) error {
-	summary, err := s.txService.GetMonthSummary(year, month)
+// autoExportPrefix starts the names of the files AutoExportMonth writes, so
+// that pruning leaves other files in the directory alone
+const autoExportPrefix = "burnwise-auto-"
+
+// AutoExportMonth writes the transactions of now's month to a timestamped
+// CSV file in dir, creating dir if needed, then removes all but the newest
+// keep auto-exports there. It returns the path of the new file.
+func (s *ExportService) AutoExportMonth(ctx context.Context, dir string, keep int, now time.Time) (string, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create export directory: %w", err)
+	}
+
+	path := filepath.Join(dir, autoExportPrefix+now.Format("20060102-150405")+".csv")
+	file, err := os.Create(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to create export file: %w", err)
+	}
+
+	monthStart := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	filter := &models.TransactionFilter{
+		StartDate: monthStart,
+		EndDate:   monthStart.AddDate(0, 1, 0).Add(-time.Second),
+	}
+	err = s.ExportTransactionsCSV(ctx, file, filter)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		os.Remove(path)
+		return "", err
+	}
+
+	return path, pruneAutoExports(dir, keep)
+}
+
+// pruneAutoExports removes the oldest auto-exports in dir beyond keep. The
+// timestamp in their names sorts them oldest first.
+func pruneAutoExports(dir string, keep int) error {
+	paths, err := filepath.Glob(filepath.Join(dir, autoExportPrefix+"*.csv"))
+	if err != nil {
+		return fmt.Errorf("failed to list old exports: %w", err)
+	}
+	if len(paths) <= keep {
+		return nil
+	}
+
+	sort.Strings(paths)
+	for _, path := range paths[:len(paths)-keep] {
+		if err := os.Remove(path); err != nil {
+			return fmt.Errorf("failed to remove old export: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *ExportService) ExportMonthlyReportCSV(ctx context.Context, writer io.Writer, year int, month time.Month) error {
+	summary, err := s.txService.GetMonthSummary(ctx, year, month)
 	if err != nil {
 		return fmt.Errorf("failed to get month summary: %w", err)
 	}
@@ -70,7 +190,7 @@ func (s *ExportService) ExportMonthlyReportCSV(writer io.Writer, year int, month
 	start := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
 	end := start.AddDate(0, 1, 0).Add(-time.Second)
 	
-	categoryTotals, err := s.txService.GetCategorySummary(start, end)
+	categoryTotals, err := s.txService.GetCategoryStats(ctx, start, end)
 	if err != nil {
 		return fmt.Errorf("failed to get category summary: %w", err)
 	}
@@ -93,9 +213,21 @@ func (s *ExportService) ExportMonthlyReportCSV(writer io.Writer, year int, month
 	if err := csvWriter.Write([]string{"Total Income", fmt.Sprintf("%.2f", summary.TotalIncome)}); err != nil {
 		return err
 	}
+	if err := csvWriter.Write([]string{"Recurring Income", fmt.Sprintf("%.2f", summary.RecurringIncome)}); err != nil {
+		return err
+	}
+	if err := csvWriter.Write([]string{"One-time Income", fmt.Sprintf("%.2f", summary.OneTimeIncome)}); err != nil {
+		return err
+	}
 	if err := csvWriter.Write([]string{"Total Expenses", fmt.Sprintf("%.2f", summary.TotalExpenses)}); err != nil {
 		return err
 	}
+	if err := csvWriter.Write([]string{"Fixed Costs (Recurring)", fmt.Sprintf("%.2f", summary.RecurringExpenses)}); err != nil {
+		return err
+	}
+	if err := csvWriter.Write([]string{"Variable Costs (One-time)", fmt.Sprintf("%.2f", summary.OneTimeExpenses)}); err != nil {
+		return err
+	}
 	if err := csvWriter.Write([]string{"Balance", fmt.Sprintf("%.2f", summary.Balance)}); err != nil {
 		return err
 	}
@@ -107,7 +239,7 @@ func (s *ExportService) ExportMonthlyReportCSV(writer io.Writer, year int, month
 	if err := csvWriter.Write([]string{"Category Breakdown"}); err != nil {
 		return err
 	}
-	if err := csvWriter.Write([]string{"Category", "Type", "Total", "Count", "Percentage"}); err != nil {
+	if err := csvWriter.Write([]string{"Category", "Type", "Total", "Count", "Percent of Type", "Average", "Median"}); err != nil {
 		return err
 	}
 
@@ -118,6 +250,41 @@ func (s *ExportService) ExportMonthlyReportCSV(writer io.Writer, year int, month
 			fmt.Sprintf("%.2f", cat.Total),
 			fmt.Sprintf("%d", cat.Count),
 			fmt.Sprintf("%.1f%%", cat.Percentage),
+			fmt.Sprintf("%.2f", cat.Average),
+			fmt.Sprintf("%.2f", cat.Median),
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return err
+		}
+	}
+
+	if s.settingsService == nil {
+		return nil
+	}
+	pattern := s.settingsService.Get().UI.TagPattern
+	if pattern == "" {
+		return nil
+	}
+
+	tagTotals, err := s.txService.GetTagTotals(ctx, start, end, pattern)
+	if err != nil {
+		return fmt.Errorf("failed to get tag breakdown: %w", err)
+	}
+
+	if err := csvWriter.Write([]string{""}); err != nil {
+		return err
+	}
+	if err := csvWriter.Write([]string{"Tag Breakdown"}); err != nil {
+		return err
+	}
+	if err := csvWriter.Write([]string{"Tag", "Total", "Count"}); err != nil {
+		return err
+	}
+	for _, tag := range tagTotals {
+		record := []string{
+			tag.Tag,
+			fmt.Sprintf("%.2f", tag.Total),
+			fmt.Sprintf("%d", tag.Count),
 		}
 		if err := csvWriter.Write(record); err != nil {
 			return err
@@ -127,8 +294,74 @@ func (s *ExportService) ExportMonthlyReportCSV(writer io.Writer, year int, month
 	return nil
 }
 
-func (s *ExportService) ExportBudgetStatusCSV(writer io.Writer, budgetService *BudgetService) error {
-	statuses, err := budgetService.GetAllStatuses()
+// ExportYearlyReportCSV writes the income, expenses and net of each month
+// of the year, the year's totals and monthly averages, its biggest expense
+// categories and its biggest single expense. Months after the one
+// containing now are left blank rather than zero.
+func (s *ExportService) ExportYearlyReportCSV(ctx context.Context, writer io.Writer, year int, now time.Time) error {
+	report, err := s.txService.GetYearlyReport(ctx, year, now)
+	if err != nil {
+		return fmt.Errorf("failed to get yearly report: %w", err)
+	}
+
+	csvWriter := csv.NewWriter(writer)
+	defer csvWriter.Flush()
+
+	amount := func(value float64) string {
+		return fmt.Sprintf("%.2f", value)
+	}
+
+	rows := [][]string{
+		{fmt.Sprintf("Yearly Report - %d", year)},
+		{""},
+		{"Month", "Income", "Expenses", "Net", "Count"},
+	}
+	for i, month := range report.Months {
+		name := time.Month(i + 1).String()
+		if month == nil {
+			rows = append(rows, []string{name, "", "", "", ""})
+			continue
+		}
+		rows = append(rows, []string{name, amount(month.Income), amount(month.Expenses), amount(month.Net()), fmt.Sprintf("%d", month.Count)})
+	}
+	rows = append(rows, []string{"Total", amount(report.Total.TotalIncome), amount(report.Total.TotalExpenses), amount(report.Total.Balance), fmt.Sprintf("%d", report.Total.Count)})
+	if elapsed := report.MonthsElapsed(); elapsed > 0 {
+		rows = append(rows, []string{"Average/Month",
+			amount(report.Total.TotalIncome / float64(elapsed)),
+			amount(report.AverageExpenses()),
+			amount(report.Total.Balance / float64(elapsed)),
+			""})
+	}
+
+	rows = append(rows,
+		[]string{""},
+		[]string{"Top Expense Categories"},
+		[]string{"Category", "Total", "Count", "Percent of Expenses"},
+	)
+	for _, cat := range report.TopCategories {
+		rows = append(rows, []string{cat.Name, amount(cat.Total), fmt.Sprintf("%d", cat.Count), fmt.Sprintf("%.1f%%", cat.Percentage)})
+	}
+
+	if tx := report.Largest; tx != nil {
+		rows = append(rows,
+			[]string{""},
+			[]string{"Largest Expense"},
+			[]string{"Date", "Description", "Category", "Amount", "Currency", "Amount (USD)"},
+			[]string{tx.Date.Format("2006-01-02"), tx.Description, tx.Category.Name,
+				fmt.Sprintf("%.*f", models.CurrencyDecimals(tx.Currency), tx.Amount), tx.Currency, amount(tx.AmountUSD)},
+		)
+	}
+
+	for _, row := range rows {
+		if err := csvWriter.Write(row); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+	return nil
+}
+
+func (s *ExportService) ExportBudgetStatusCSV(ctx context.Context, writer io.Writer, budgetService *BudgetService) error {
+	statuses, err := budgetService.GetAllStatuses(ctx)
 	if err != nil {
 		return fmt.Errorf("failed to get budget statuses: %w", err)
 	}
@@ -146,6 +379,7 @@ func (s *ExportService) ExportBudgetStatusCSV(writer io.Writer, budgetService *B
 		"Remaining",
 		"Percent Used",
 		"Status",
+		"Notes",
 	}
 	if err := csvWriter.Write(header); err != nil {
 		return fmt.Errorf("failed to write header: %w", err)
@@ -158,15 +392,21 @@ func (s *ExportService) ExportBudgetStatusCSV(writer io.Writer, budgetService *B
 			statusText = "OVER BUDGET"
 		}
 
+		category := status.Budget.Category.Name
+		if status.Budget.IsGroup() {
+			category = status.Budget.CategoryLabel()
+		}
+
 		record := []string{
 			status.Budget.Name,
-			status.Budget.Category.Name,
+			category,
 			string(status.Budget.Period),
 			fmt.Sprintf("%.2f", status.Budget.Amount),
 			fmt.Sprintf("%.2f", status.Spent),
 			fmt.Sprintf("%.2f", status.Remaining),
 			fmt.Sprintf("%.1f%%", status.PercentUsed),
 			statusText,
+			status.Budget.Notes,
 		}
 		if err := csvWriter.Write(record); err != nil {
 			return fmt.Errorf("failed to write record: %w", err)
@@ -174,4 +414,295 @@ func (s *ExportService) ExportBudgetStatusCSV(writer io.Writer, budgetService *B
 	}
 
 	return nil
-}
\ No newline at end of file
+}
+
+// ExportCategoryHistoryCSV writes the history of one category, oldest first,
+// including the merges of other categories into it
+func (s *ExportService) ExportCategoryHistoryCSV(ctx context.Context, writer io.Writer, categoryID uint) error {
+	if s.categoryService == nil {
+		return fmt.Errorf("category history export is not available")
+	}
+	history, err := s.categoryService.GetHistoryLog(ctx, categoryID)
+	if err != nil {
+		return fmt.Errorf("failed to get category history: %w", err)
+	}
+	return writeCategoryHistoryCSV(writer, history)
+}
+
+// ExportAllCategoryHistoryCSV writes the history of every category, oldest first
+func (s *ExportService) ExportAllCategoryHistoryCSV(ctx context.Context, writer io.Writer) error {
+	if s.categoryService == nil {
+		return fmt.Errorf("category history export is not available")
+	}
+	history, err := s.categoryService.GetAllHistory(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get category history: %w", err)
+	}
+	return writeCategoryHistoryCSV(writer, history)
+}
+
+// ExportCategoriesCSV writes an audit of the categories in two sections:
+// every current category with its lifetime total in USD, then the history
+// of all categories, oldest first. Dates use the date format from the
+// settings, when a settings service is set.
+func (s *ExportService) ExportCategoriesCSV(ctx context.Context, writer io.Writer) error {
+	if s.categoryService == nil {
+		return fmt.Errorf("category export is not available")
+	}
+	categories, err := s.categoryService.GetAllWithUsageCount(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get categories: %w", err)
+	}
+	history, err := s.categoryService.GetAllHistory(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get category history: %w", err)
+	}
+
+	dateFormat := "2006-01-02"
+	if s.settingsService != nil {
+		if format := s.settingsService.Get().UI.DateFormat; models.IsDateLayout(format) {
+			dateFormat = format
+		}
+	}
+
+	csvWriter := csv.NewWriter(writer)
+	defer csvWriter.Flush()
+
+	rows := [][]string{
+		{"Categories"},
+		{"Name", "Type", "Icon", "Default", "Created", "Transaction Count", "Lifetime Total (USD)"},
+	}
+	for _, category := range categories {
+		rows = append(rows, []string{
+			category.Name,
+			string(category.Type),
+			category.Icon,
+			fmt.Sprintf("%t", category.IsDefault),
+			category.CreatedAt.Format(dateFormat),
+			fmt.Sprintf("%d", category.Count),
+			fmt.Sprintf("%.2f", category.Total),
+		})
+	}
+
+	rows = append(rows,
+		[]string{""},
+		[]string{"Category History"},
+		[]string{"Date", "Category", "Action", "Old Name", "New Name", "Old Icon", "New Icon",
+			"Old Color", "New Color", "Merged Into", "Transaction Count", "Notes"},
+	)
+	for _, entry := range history {
+		category, target := "", ""
+		if entry.Category != nil {
+			category = entry.Category.Name
+		}
+		if entry.TargetCategory != nil {
+			target = entry.TargetCategory.Name
+		}
+		rows = append(rows, []string{
+			entry.CreatedAt.Format(dateFormat + " 15:04"),
+			category,
+			string(entry.Action),
+			entry.OldName,
+			entry.NewName,
+			entry.OldIcon,
+			entry.NewIcon,
+			entry.OldColor,
+			entry.NewColor,
+			target,
+			fmt.Sprintf("%d", entry.TransactionCount),
+			entry.Notes,
+		})
+	}
+
+	for _, row := range rows {
+		if err := csvWriter.Write(row); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+	return nil
+}
+
+func writeCategoryHistoryCSV(writer io.Writer, history []*models.CategoryHistory) error {
+	csvWriter := csv.NewWriter(writer)
+	defer csvWriter.Flush()
+
+	header := []string{
+		"Timestamp",
+		"Category ID",
+		"Category",
+		"Action",
+		"Old Name",
+		"New Name",
+		"Old Icon",
+		"New Icon",
+		"Old Color",
+		"New Color",
+		"Target Category ID",
+		"Target Category",
+		"Transaction Count",
+		"Notes",
+	}
+	if err := csvWriter.Write(header); err != nil {
+		return fmt.Errorf("failed to write header: %w", err)
+	}
+
+	for _, entry := range history {
+		category := ""
+		if entry.Category != nil {
+			category = entry.Category.Name
+		}
+		targetID, target := "", ""
+		if entry.TargetCategoryID != nil {
+			targetID = fmt.Sprintf("%d", *entry.TargetCategoryID)
+		}
+		if entry.TargetCategory != nil {
+			target = entry.TargetCategory.Name
+		}
+
+		record := []string{
+			entry.CreatedAt.Format("2006-01-02 15:04:05"),
+			fmt.Sprintf("%d", entry.CategoryID),
+			category,
+			string(entry.Action),
+			entry.OldName,
+			entry.NewName,
+			entry.OldIcon,
+			entry.NewIcon,
+			entry.OldColor,
+			entry.NewColor,
+			targetID,
+			target,
+			fmt.Sprintf("%d", entry.TransactionCount),
+			entry.Notes,
+		}
+		if err := csvWriter.Write(record); err != nil {
+			return fmt.Errorf("failed to write record: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// GenerateMonthlyReportMarkdown renders the month's summary, category
+// breakdown and budget status as Markdown. Budgets show their period
+// containing the month and are only included when a budget service is set.
+func (s *ExportService) GenerateMonthlyReportMarkdown(ctx context.Context, year int, month time.Month) (string, error) {
+	summary, err := s.txService.GetMonthSummary(ctx, year, month)
+	if err != nil {
+		return "", fmt.Errorf("failed to get month summary: %w", err)
+	}
+
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0).Add(-time.Second)
+
+	categoryTotals, err := s.txService.GetCategorySummary(ctx, start, end)
+	if err != nil {
+		return "", fmt.Errorf("failed to get category summary: %w", err)
+	}
+
+	var statuses []*models.BudgetStatus
+	if s.budgetService != nil {
+		statuses, err = s.budgetService.GetStatusesAt(ctx, start)
+		if err != nil {
+			return "", fmt.Errorf("failed to get budget statuses: %w", err)
+		}
+	}
+
+	// Categories whose single-category budget is exceeded get flagged
+	overBudget := make(map[uint]bool)
+	for _, status := range statuses {
+		if status.IsOverBudget && !status.Budget.IsGroup() {
+			overBudget[status.Budget.CategoryID] = true
+		}
+	}
+
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# 📊 Monthly Report: %s %d\n\n", month.String(), year)
+
+	b.WriteString("## Summary\n\n")
+	if summary.Count == 0 {
+		b.WriteString("_No transactions this month._\n\n")
+	} else {
+		b.WriteString("| | Amount |\n|---|---:|\n")
+		fmt.Fprintf(&b, "| Income | $%.2f |\n", summary.TotalIncome)
+		fmt.Fprintf(&b, "| Expenses | $%.2f |\n", summary.TotalExpenses)
+		fmt.Fprintf(&b, "| **Balance** | **$%.2f** |\n", summary.Balance)
+		if summary.TotalIncome > 0 {
+			fmt.Fprintf(&b, "| Savings rate | %.1f%% |\n", summary.SavingsRate())
+		}
+		fmt.Fprintf(&b, "\n%d transactions.\n\n", summary.Count)
+	}
+
+	var expenses, income []*models.CategoryWithTotal
+	for _, cat := range categoryTotals {
+		if cat.Type == models.TransactionTypeIncome {
+			income = append(income, cat)
+		} else {
+			expenses = append(expenses, cat)
+		}
+	}
+
+	if len(expenses) > 0 {
+		b.WriteString("## Spending by Category\n\n")
+		b.WriteString("| Category | Total | Count | Share |\n|---|---:|---:|---:|\n")
+		for _, cat := range expenses {
+			name := markdownEscape(strings.TrimSpace(cat.Icon + " " + cat.Name))
+			if overBudget[cat.ID] {
+				name += " 🚨"
+			}
+			fmt.Fprintf(&b, "| %s | $%.2f | %d | %.1f%% |\n", name, cat.Total, cat.Count, cat.Percentage)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(income) > 0 {
+		b.WriteString("## Income by Category\n\n")
+		b.WriteString("| Category | Total | Count |\n|---|---:|---:|\n")
+		for _, cat := range income {
+			name := markdownEscape(strings.TrimSpace(cat.Icon + " " + cat.Name))
+			fmt.Fprintf(&b, "| %s | $%.2f | %d |\n", name, cat.Total, cat.Count)
+		}
+		b.WriteString("\n")
+	}
+
+	if len(statuses) > 0 {
+		b.WriteString("## Budgets\n\n")
+		b.WriteString("| | Budget | Category | Period | Spent | Limit | Used |\n|---|---|---|---|---:|---:|---:|\n")
+		for _, status := range statuses {
+			marker := "✅"
+			if status.IsOverBudget {
+				marker = "🚨"
+			} else if status.PercentUsed > models.BudgetWarnPercent {
+				marker = "⚠️"
+			}
+			fmt.Fprintf(&b, "| %s | %s | %s | %s | $%.2f | $%.2f | %.0f%% |\n",
+				marker,
+				markdownEscape(status.Budget.Name),
+				markdownEscape(status.Budget.CategoryLabel()),
+				status.Budget.Period,
+				status.Spent,
+				status.Budget.Amount,
+				status.PercentUsed,
+			)
+		}
+		b.WriteString("\n")
+	}
+
+	fmt.Fprintf(&b, "_Generated by BurnWise on %s._\n", time.Now().Format("2006-01-02"))
+
+	return b.String(), nil
+}
+
+// accountName returns the account's name, or nothing without one
+func accountName(account *models.Account) string {
+	if account == nil {
+		return ""
+	}
+	return account.Name
+}
+
+// markdownEscape keeps user-entered text from breaking table cells
+func markdownEscape(s string) string {
+	return strings.ReplaceAll(s, "|", "\\|")
+}
@@ -3,6 +3,9 @@ package service
 import (
 	"bytes"
 	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"testing"
 	"time"
@@ -12,6 +15,7 @@ import (
 
 	"burnwise/internal/models"
 	"burnwise/internal/repository"
+	"burnwise/test/fixtures"
 	test "burnwise/test/helpers"
 )
 
@@ -38,7 +42,7 @@ func TestExportService_ExportTransactionsCSV(t *testing.T) {
 		Description: "Groceries",
 		Date:        time.Now(),
 	}
-	require.NoError(t, txService.Create(tx1))
+	require.NoError(t, txService.Create(t.Context(), tx1))
 	
 	tx2 := &models.Transaction{
 		Type:        models.TransactionTypeExpense,
@@ -48,11 +52,19 @@ func TestExportService_ExportTransactionsCSV(t *testing.T) {
 		Description: "Restaurant",
 		Date:        time.Now(),
 	}
-	require.NoError(t, txService.Create(tx2))
+	require.NoError(t, txService.Create(t.Context(), tx2))
+	
+	receipts := make([]string, 2)
+	for i := range receipts {
+		receipts[i] = filepath.Join(tempDir, fmt.Sprintf("receipt-%d.pdf", i+1))
+		require.NoError(t, os.WriteFile(receipts[i], []byte("%PDF"), 0o600))
+		_, err = txService.AddAttachment(t.Context(), tx1.ID, receipts[i])
+		require.NoError(t, err)
+	}
 	
 	// Export to buffer
 	var buf bytes.Buffer
-	err = exportService.ExportTransactionsCSV(&buf, &models.TransactionFilter{})
+	err = exportService.ExportTransactionsCSV(t.Context(), &buf, &models.TransactionFilter{})
 	require.NoError(t, err)
 	
 	// Parse CSV
@@ -62,7 +74,7 @@ func TestExportService_ExportTransactionsCSV(t *testing.T) {
 	
 	// Check header
 	assert.Len(t, records, 3) // header + 2 transactions
-	assert.Equal(t, []string{"Date", "Type", "Category", "Description", "Amount", "Currency", "Amount (USD)"}, records[0])
+	assert.Equal(t, []string{"Date", "Type", "Category", "Description", "Amount", "Currency", "Amount (USD)", "Rate Source", "Attachments", "Account", "To Account"}, records[0])
 	
 	// Check both transactions are present (order may vary)
 	var groceriesFound, restaurantFound bool
@@ -75,17 +87,102 @@ func TestExportService_ExportTransactionsCSV(t *testing.T) {
 			assert.Equal(t, "50.00", records[i][4])
 			assert.Equal(t, "USD", records[i][5])
 			assert.Equal(t, "50.00", records[i][6])
+			assert.Empty(t, records[i][7]) // USD needs no rate
+			assert.Equal(t, receipts[0]+";"+receipts[1], records[i][8])
 		} else if records[i][3] == "Restaurant" {
 			restaurantFound = true
 			assert.Equal(t, "100.00", records[i][4])
 			assert.Equal(t, "AED", records[i][5])
 			assert.Contains(t, records[i][6], "27.2") // Converted amount
+			assert.Equal(t, "fixed", records[i][7])
+			assert.Empty(t, records[i][8])
 		}
 	}
 	assert.True(t, groceriesFound, "Groceries transaction not found")
 	assert.True(t, restaurantFound, "Restaurant transaction not found")
 }
 
+func TestExportService_ExportTransactionsCSVTransfers(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	txService := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	accounts := NewAccountService(repository.NewAccountRepository(db))
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	checking := &models.Account{Name: "Checking", Type: models.AccountTypeDebit}
+	savings := &models.Account{Name: "Savings", Type: models.AccountTypeSavings}
+	for _, account := range []*models.Account{checking, savings} {
+		require.NoError(t, accounts.Create(t.Context(), account))
+	}
+	date := time.Date(2025, time.March, 15, 0, 0, 0, 0, time.Local)
+	require.NoError(t, txService.Create(t.Context(), &models.Transaction{Type: models.TransactionTypeExpense, Amount: 40, Currency: "USD",
+		CategoryID: food.ID, Description: "Groceries", Date: date, AccountID: &checking.ID}))
+	_, _, err = txService.CreateTransfer(t.Context(), checking.ID, savings.ID, 300, "USD", date, "Rainy day fund")
+	require.NoError(t, err)
+	
+	var buf bytes.Buffer
+	require.NoError(t, NewExportService(txService).ExportTransactionsCSV(t.Context(), &buf, &models.TransactionFilter{}))
+	records, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	require.NoError(t, err)
+	
+	// The transfer is one row saying where the money went
+	require.Len(t, records, 3)
+	rows := make(map[string][]string)
+	for _, record := range records[1:] {
+		rows[record[1]] = record
+	}
+	assert.Equal(t, []string{"Groceries", "40.00", "Checking", ""},
+		[]string{rows["expense"][3], rows["expense"][4], rows["expense"][9], rows["expense"][10]})
+	assert.Equal(t, []string{"Rainy day fund", "300.00", "Checking", "Savings"},
+		[]string{rows["transfer"][3], rows["transfer"][4], rows["transfer"][9], rows["transfer"][10]})
+	
+	// Importing it back leaves the transfer out, saying why
+	importService := NewImportService(txService, repository.NewCategoryRepository(db))
+	transactions, err := importService.ParseCSV(t.Context(), &buf)
+	require.NoError(t, err)
+	_, problems, err := importService.Import(t.Context(), transactions)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+	assert.Equal(t, "type", problems[0].Field)
+	assert.Contains(t, problems[0].Message, "transfers aren't imported")
+}
+
+func TestExportService_ExportTransactionsCSVCurrencyDecimals(t *testing.T) {
+	db := test.SetupTestDB(t)
+	txRepo := repository.NewTransactionRepository(db)
+	
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	exportService := NewExportService(NewTransactionService(txRepo, NewCurrencyService(settingsService)))
+	
+	category := test.CreateTestCategory(t, db, "Travel", models.TransactionTypeExpense)
+	amounts := map[string]float64{"JPY": 1500, "BHD": 12.345, "AED": 36.7}
+	for currency, amount := range amounts {
+		tx := fixtures.NewTransaction().
+			WithCategory(category.ID).
+			WithAmount(amount).
+			WithCurrency(currency).
+			WithAmountUSD(10.004).
+			WithDescription(currency).
+			Build()
+		require.NoError(t, txRepo.Create(t.Context(), tx))
+	}
+	
+	var buf bytes.Buffer
+	require.NoError(t, exportService.ExportTransactionsCSV(t.Context(), &buf, &models.TransactionFilter{}))
+	records, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	
+	// Amounts use their currency's decimals, USD amounts are stored in cents
+	want := map[string]string{"JPY": "1500", "BHD": "12.345", "AED": "36.70"}
+	for _, record := range records[1:] {
+		assert.Equal(t, want[record[3]], record[4], record[3])
+		assert.Equal(t, "10.00", record[6])
+	}
+}
+
 func TestExportService_ExportMonthlyReportCSV(t *testing.T) {
 	db := test.SetupTestDB(t)
 	txRepo := repository.NewTransactionRepository(db)
@@ -111,7 +208,7 @@ func TestExportService_ExportMonthlyReportCSV(t *testing.T) {
 		Description: "Monthly salary",
 		Date:        time.Now(),
 	}
-	require.NoError(t, txService.Create(income))
+	require.NoError(t, txService.Create(t.Context(), income))
 	
 	// Create expenses
 	expense := &models.Transaction{
@@ -122,11 +219,11 @@ func TestExportService_ExportMonthlyReportCSV(t *testing.T) {
 		Description: "Groceries",
 		Date:        time.Now(),
 	}
-	require.NoError(t, txService.Create(expense))
+	require.NoError(t, txService.Create(t.Context(), expense))
 	
 	// Export report
 	var buf bytes.Buffer
-	err = exportService.ExportMonthlyReportCSV(&buf, time.Now().Year(), time.Now().Month())
+	err = exportService.ExportMonthlyReportCSV(t.Context(), &buf, time.Now().Year(), time.Now().Month())
 	require.NoError(t, err)
 	
 	// Check output contains expected data
@@ -134,10 +231,76 @@ func TestExportService_ExportMonthlyReportCSV(t *testing.T) {
 	assert.Contains(t, output, "Monthly Report")
 	assert.Contains(t, output, "Total Income,5000.00")
 	assert.Contains(t, output, "Total Expenses,100.00")
+	assert.Contains(t, output, "Fixed Costs (Recurring),0.00")
+	assert.Contains(t, output, "Variable Costs (One-time),100.00")
 	assert.Contains(t, output, "Balance,4900.00")
 	assert.Contains(t, output, "Category Breakdown")
-	assert.Contains(t, output, "Salary")
-	assert.Contains(t, output, "Food")
+	assert.Contains(t, output, "Category,Type,Total,Count,Percent of Type,Average,Median")
+	assert.Contains(t, output, "Salary,income,5000.00,1,100.0%")
+	assert.Contains(t, output, "Food,expense,100.00,1,100.0%")
+	assert.NotContains(t, output, "Tag Breakdown")
+	
+	// With a tag pattern saved, expenses are also totalled per tag
+	require.NoError(t, settingsService.Update(func(s *models.Settings) error {
+		s.UI.TagPattern = `^\[(\w+)\]`
+		return nil
+	}))
+	exportService.SetSettingsService(settingsService)
+	tagged := &models.Transaction{
+		Type:        models.TransactionTypeExpense,
+		Amount:      40.00,
+		Currency:    "USD",
+		CategoryID:  expenseCategory.ID,
+		Description: "[ACME] Team lunch",
+		Date:        time.Now(),
+	}
+	require.NoError(t, txService.Create(t.Context(), tagged))
+	
+	buf.Reset()
+	require.NoError(t, exportService.ExportMonthlyReportCSV(t.Context(), &buf, time.Now().Year(), time.Now().Month()))
+	output = buf.String()
+	assert.Contains(t, output, "Tag Breakdown\nTag,Total,Count\nACME,40.00,1\n(untagged),100.00,1\n")
+}
+
+func TestExportService_ExportYearlyReportCSV(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	txService := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	exportService := NewExportService(txService)
+	
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	create := func(txType models.TransactionType, categoryID uint, amount float64, description string, date time.Time) {
+		require.NoError(t, txService.Create(t.Context(), &models.Transaction{
+			Type:        txType,
+			Amount:      amount,
+			Currency:    "USD",
+			CategoryID:  categoryID,
+			Description: description,
+			Date:        date,
+		}))
+	}
+	create(models.TransactionTypeIncome, salary.ID, 3000, "Paycheck", time.Date(2025, time.January, 1, 9, 0, 0, 0, time.Local))
+	create(models.TransactionTypeExpense, food.ID, 250, "Groceries", time.Date(2025, time.January, 5, 9, 0, 0, 0, time.Local))
+	create(models.TransactionTypeExpense, food.ID, 150, "Dinner out", time.Date(2025, time.February, 14, 20, 0, 0, 0, time.Local))
+	
+	var buf bytes.Buffer
+	require.NoError(t, exportService.ExportYearlyReportCSV(t.Context(), &buf, 2025, time.Date(2025, time.March, 10, 0, 0, 0, 0, time.Local)))
+	output := buf.String()
+	
+	assert.Contains(t, output, "Yearly Report - 2025\n")
+	assert.Contains(t, output, "Month,Income,Expenses,Net,Count\n")
+	assert.Contains(t, output, "January,3000.00,250.00,2750.00,2\n")
+	assert.Contains(t, output, "February,0.00,150.00,-150.00,1\n")
+	assert.Contains(t, output, "March,0.00,0.00,0.00,0\n")
+	// Months that haven't started are blank, and left out of the averages
+	assert.Contains(t, output, "April,,,,\n")
+	assert.Contains(t, output, "December,,,,\n")
+	assert.Contains(t, output, "Total,3000.00,400.00,2600.00,3\n")
+	assert.Contains(t, output, "Average/Month,1000.00,133.33,866.67,\n")
+	assert.Contains(t, output, "Top Expense Categories\nCategory,Total,Count,Percent of Expenses\nFood,400.00,2,100.0%\n")
+	assert.Contains(t, output, "Largest Expense\nDate,Description,Category,Amount,Currency,Amount (USD)\n2025-01-05,Groceries,Food,250.00,USD,250.00\n")
 }
 
 func TestExportService_ExportBudgetStatusCSV(t *testing.T) {
@@ -156,7 +319,9 @@ func TestExportService_ExportBudgetStatusCSV(t *testing.T) {
 	
 	// Create test data
 	category := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
-	test.CreateTestBudget(t, db, category.ID, 500.00)
+	budget := test.CreateTestBudget(t, db, category.ID, 500.00)
+	budget.Notes = "Agreed with partner, 2024-05"
+	require.NoError(t, budgetRepo.Update(t.Context(), budget))
 	
 	// Create transaction
 	tx := &models.Transaction{
@@ -167,11 +332,11 @@ func TestExportService_ExportBudgetStatusCSV(t *testing.T) {
 		Description: "Groceries",
 		Date:        time.Now(),
 	}
-	require.NoError(t, txService.Create(tx))
+	require.NoError(t, txService.Create(t.Context(), tx))
 	
 	// Export budget status
 	var buf bytes.Buffer
-	err = exportService.ExportBudgetStatusCSV(&buf, budgetService)
+	err = exportService.ExportBudgetStatusCSV(t.Context(), &buf, budgetService)
 	require.NoError(t, err)
 	
 	// Parse CSV
@@ -189,4 +354,225 @@ func TestExportService_ExportBudgetStatusCSV(t *testing.T) {
 	assert.Equal(t, "400.00", records[1][5])
 	assert.Contains(t, records[1][6], "20.0%")
 	assert.Equal(t, "OK", records[1][7])
-}
\ No newline at end of file
+	assert.Equal(t, "Agreed with partner, 2024-05", records[1][8])
+}
+
+func TestExportService_GenerateMonthlyReportMarkdown(t *testing.T) {
+	db := test.SetupTestDB(t)
+	budgetRepo := repository.NewBudgetRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+	
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	currencyService := NewCurrencyService(settingsService)
+	
+	txService := NewTransactionService(txRepo, currencyService)
+	budgetService := NewBudgetService(budgetRepo, txRepo)
+	exportService := NewExportService(txService)
+	exportService.SetBudgetService(budgetService)
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	budget := test.CreateTestBudget(t, db, food.ID, 50.00)
+	require.NoError(t, db.Model(budget).Update("start_date", time.Now().AddDate(0, -3, 0)).Error)
+	
+	require.NoError(t, txService.Create(t.Context(), &models.Transaction{
+		Type:        models.TransactionTypeIncome,
+		Amount:      1000.00,
+		Currency:    "USD",
+		CategoryID:  salary.ID,
+		Description: "Pay",
+		Date:        time.Now(),
+	}))
+	require.NoError(t, txService.Create(t.Context(), &models.Transaction{
+		Type:        models.TransactionTypeExpense,
+		Amount:      80.00,
+		Currency:    "USD",
+		CategoryID:  food.ID,
+		Description: "Groceries",
+		Date:        time.Now(),
+	}))
+	
+	now := time.Now()
+	report, err := exportService.GenerateMonthlyReportMarkdown(t.Context(), now.Year(), now.Month())
+	require.NoError(t, err)
+	
+	assert.Contains(t, report, fmt.Sprintf("# 📊 Monthly Report: %s %d", now.Month(), now.Year()))
+	assert.Contains(t, report, "| Income | $1000.00 |")
+	assert.Contains(t, report, "| Expenses | $80.00 |")
+	assert.Contains(t, report, "## Spending by Category")
+	assert.Contains(t, report, "Food 🚨 | $80.00 | 1 |")
+	assert.Contains(t, report, "## Income by Category")
+	assert.Contains(t, report, "## Budgets")
+	assert.Contains(t, report, "| 🚨 | Test Budget |")
+	
+	// A past month's budgets show that month's spending
+	lastMonth := time.Date(now.Year(), now.Month(), 1, 12, 0, 0, 0, time.Local).AddDate(0, -1, 0)
+	require.NoError(t, txService.Create(t.Context(), &models.Transaction{
+		Type:        models.TransactionTypeExpense,
+		Amount:      30.00,
+		Currency:    "USD",
+		CategoryID:  food.ID,
+		Description: "Groceries",
+		Date:        lastMonth,
+	}))
+	report, err = exportService.GenerateMonthlyReportMarkdown(t.Context(), lastMonth.Year(), lastMonth.Month())
+	require.NoError(t, err)
+	assert.Contains(t, report, "| ✅ | Test Budget | 💰 Food | monthly | $30.00 | $50.00 | 60% |")
+	assert.NotContains(t, report, "🚨")
+	
+	// Without a budget service the budget section is left out
+	report, err = NewExportService(txService).GenerateMonthlyReportMarkdown(t.Context(), now.Year(), now.Month())
+	require.NoError(t, err)
+	assert.NotContains(t, report, "## Budgets")
+	assert.NotContains(t, report, "🚨")
+}
+
+func TestExportService_ExportCategoryHistoryCSV(t *testing.T) {
+	db := test.SetupTestDB(t)
+	txRepo := repository.NewTransactionRepository(db)
+	
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	categoryService := NewCategoryService(repository.NewCategoryRepository(db))
+	exportService := NewExportService(NewTransactionService(txRepo, NewCurrencyService(settingsService)))
+	
+	var buf bytes.Buffer
+	assert.Error(t, exportService.ExportAllCategoryHistoryCSV(t.Context(), &buf), "needs a category service")
+	exportService.SetCategoryService(categoryService)
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	snacks := test.CreateTestCategory(t, db, "Snacks", models.TransactionTypeExpense)
+	other := test.CreateTestCategory(t, db, "Other", models.TransactionTypeExpense)
+	test.CreateTestTransaction(t, db, 5.00, snacks.ID)
+	
+	food.Name = "Groceries"
+	require.NoError(t, categoryService.Update(t.Context(), food))
+	require.NoError(t, categoryService.MergeCategories(t.Context(), snacks.ID, food.ID))
+	other.Color = "#123456"
+	require.NoError(t, categoryService.Update(t.Context(), other))
+	
+	buf.Reset()
+	require.NoError(t, exportService.ExportAllCategoryHistoryCSV(t.Context(), &buf))
+	records, err := csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 4)
+	assert.Equal(t, "Timestamp", records[0][0])
+	assert.Equal(t, []string{"Groceries", "edited", "Food", "Groceries"}, records[1][2:6])
+	
+	// The merged category is deleted but still named, as is the target
+	merge := records[2]
+	assert.Equal(t, fmt.Sprint(snacks.ID), merge[1])
+	assert.Equal(t, "Snacks", merge[2])
+	assert.Equal(t, "merged", merge[3])
+	assert.Equal(t, fmt.Sprint(food.ID), merge[10])
+	assert.Equal(t, "Groceries", merge[11])
+	assert.Equal(t, "1", merge[12])
+	assert.Contains(t, merge[13], "Merged 'Snacks' into 'Groceries'")
+	assert.Equal(t, "#123456", records[3][9])
+	
+	// A single category's history includes the merges into it
+	buf.Reset()
+	require.NoError(t, exportService.ExportCategoryHistoryCSV(t.Context(), &buf, food.ID))
+	records, err = csv.NewReader(strings.NewReader(buf.String())).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 3)
+	assert.Equal(t, "edited", records[1][3])
+	assert.Equal(t, "merged", records[2][3])
+}
+
+func TestExportService_ExportCategoriesCSV(t *testing.T) {
+	db := test.SetupTestDB(t)
+	txRepo := repository.NewTransactionRepository(db)
+	
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, settingsService.Update(func(settings *models.Settings) error {
+		settings.UI.DateFormat = "02/01/2006"
+		return nil
+	}))
+	categoryService := NewCategoryService(repository.NewCategoryRepository(db))
+	exportService := NewExportService(NewTransactionService(txRepo, NewCurrencyService(settingsService)))
+	exportService.SetSettingsService(settingsService)
+	
+	var buf bytes.Buffer
+	assert.Error(t, exportService.ExportCategoriesCSV(t.Context(), &buf), "needs a category service")
+	exportService.SetCategoryService(categoryService)
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	snacks := test.CreateTestCategory(t, db, "Snacks", models.TransactionTypeExpense)
+	test.CreateTestTransaction(t, db, 5.00, snacks.ID)
+	test.CreateTestTransaction(t, db, 20.00, food.ID)
+	
+	food.Name = "Groceries"
+	require.NoError(t, categoryService.Update(t.Context(), food))
+	require.NoError(t, categoryService.MergeCategories(t.Context(), snacks.ID, food.ID))
+	
+	require.NoError(t, exportService.ExportCategoriesCSV(t.Context(), &buf))
+	reader := csv.NewReader(strings.NewReader(buf.String()))
+	reader.FieldsPerRecord = -1
+	records, err := reader.ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 7) // the blank line between the sections is skipped
+	
+	// The current categories, the merged one gone, with lifetime totals
+	assert.Equal(t, []string{"Categories"}, records[0])
+	assert.Equal(t, "Lifetime Total (USD)", records[1][6])
+	assert.Equal(t, []string{"Groceries", "expense"}, records[2][:2])
+	assert.Equal(t, "false", records[2][3])
+	assert.Equal(t, food.CreatedAt.Format("02/01/2006"), records[2][4])
+	assert.Equal(t, []string{"2", "25.00"}, records[2][5:])
+	
+	// Then the history, oldest first, dated in the configured format
+	assert.Equal(t, []string{"Category History"}, records[3])
+	assert.Equal(t, "Date", records[4][0])
+	assert.Equal(t, []string{"Groceries", "edited", "Food", "Groceries"}, records[5][1:5])
+	assert.Equal(t, []string{"Snacks", "merged"}, records[6][1:3])
+	assert.Equal(t, "Groceries", records[6][9])
+	_, err = time.ParseInLocation("02/01/2006 15:04", records[6][0], time.Local)
+	assert.NoError(t, err)
+}
+
+func TestExportService_AutoExportMonth(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	exportService := NewExportService(NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService)))
+	
+	category := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	test.CreateTestTransaction(t, db, 12.50, category.ID)
+	old := test.CreateTestTransaction(t, db, 99.00, category.ID)
+	require.NoError(t, db.Model(old).Update("date", time.Now().AddDate(0, -2, 0)).Error)
+	
+	// Only this month's transactions are exported, to a new directory
+	dir := filepath.Join(t.TempDir(), "exports")
+	require.NoError(t, os.MkdirAll(dir, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(dir, "notes.csv"), []byte("keep me"), 0644))
+	now := time.Now()
+	path, err := exportService.AutoExportMonth(t.Context(), dir, 2, now)
+	require.NoError(t, err)
+	assert.Equal(t, filepath.Join(dir, "burnwise-auto-"+now.Format("20060102-150405")+".csv"), path)
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	records, err := csv.NewReader(bytes.NewReader(data)).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, records, 2)
+	assert.Equal(t, "12.50", records[1][4])
+	
+	// Only the newest two are kept, and other files are left alone
+	for i := 1; i <= 2; i++ {
+		_, err := exportService.AutoExportMonth(t.Context(), dir, 2, now.Add(time.Duration(i)*time.Minute))
+		require.NoError(t, err)
+	}
+	files, err := filepath.Glob(filepath.Join(dir, "*.csv"))
+	require.NoError(t, err)
+	assert.Len(t, files, 3)
+	assert.NoFileExists(t, path)
+	assert.FileExists(t, filepath.Join(dir, "notes.csv"))
+
+	// The directory is taken from the data directory unless absolute
+	dataDir := t.TempDir()
+	assert.Equal(t, filepath.Join(dataDir, "exports"), models.AutoExportSettings{}.Dir(dataDir))
+	assert.Equal(t, filepath.Join(dataDir, "snapshots"), models.AutoExportSettings{Directory: "snapshots"}.Dir(dataDir))
+	assert.Equal(t, dir, models.AutoExportSettings{Directory: dir}.Dir(dataDir))
+}
@@ -0,0 +1,76 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"burnwise/internal/models"
+)
+
+// ThisMonthFilter lists the transactions of the month containing now
+func ThisMonthFilter(now time.Time) *models.TransactionFilter {
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	return &models.TransactionFilter{
+		StartDate: start,
+		EndDate:   start.AddDate(0, 1, 0).Add(-time.Second),
+	}
+}
+
+// LastMonthFilter lists the transactions of the month before the one
+// containing now
+func LastMonthFilter(now time.Time) *models.TransactionFilter {
+	start := time.Date(now.Year(), now.Month()-1, 1, 0, 0, 0, 0, now.Location())
+	return &models.TransactionFilter{
+		StartDate: start,
+		EndDate:   start.AddDate(0, 1, 0).Add(-time.Second),
+	}
+}
+
+// ThisYearFilter lists the transactions of the year containing now
+func ThisYearFilter(now time.Time) *models.TransactionFilter {
+	start := time.Date(now.Year(), time.January, 1, 0, 0, 0, 0, now.Location())
+	return &models.TransactionFilter{
+		StartDate: start,
+		EndDate:   start.AddDate(1, 0, 0).Add(-time.Second),
+	}
+}
+
+// PresetFilter returns the filter for a preset on the date of now. The
+// uncategorized preset lists the default "Other" expense category, of
+// every date, and the unusual dates preset the transactions dated outside
+// models.SaneDateWindow, so typos such as 2035 for 2025 can be fixed.
+func (s *CategoryService) PresetFilter(ctx context.Context, preset models.FilterPreset, now time.Time) (*models.TransactionFilter, error) {
+	switch preset {
+	case models.PresetThisMonth:
+		return ThisMonthFilter(now), nil
+	case models.PresetLastMonth:
+		return LastMonthFilter(now), nil
+	case models.PresetThisYear:
+		return ThisYearFilter(now), nil
+	case models.PresetUncategorized:
+		other, err := s.GetOther(ctx)
+		if err != nil {
+			return nil, err
+		}
+		return &models.TransactionFilter{CategoryID: other.ID}, nil
+	case models.PresetUnusualDates:
+		earliest, latest := models.SaneDateWindow(now)
+		return &models.TransactionFilter{StartDate: earliest, EndDate: latest, OutsideDates: true}, nil
+	}
+	return nil, fmt.Errorf("unknown filter preset %q", preset)
+}
+
+// GetOther returns the default "Other" expense category
+func (s *CategoryService) GetOther(ctx context.Context) (*models.Category, error) {
+	defaults, err := s.repo.GetDefault(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get default categories: %w", err)
+	}
+	for _, category := range defaults {
+		if category.Type == models.TransactionTypeExpense && category.Name == models.OtherCategoryName {
+			return category, nil
+		}
+	}
+	return nil, fmt.Errorf("there is no default %q expense category", models.OtherCategoryName)
+}
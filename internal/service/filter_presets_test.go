@@ -0,0 +1,126 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"burnwise/internal/models"
+	"burnwise/internal/repository"
+	test "burnwise/test/helpers"
+)
+
+func TestFilterPresets(t *testing.T) {
+	day := func(year int, month time.Month, d, hour, min int) time.Time {
+		return time.Date(year, month, d, hour, min, 0, 0, time.Local)
+	}
+
+	tests := []struct {
+		name       string
+		filter     func(time.Time) *models.TransactionFilter
+		now        time.Time
+		start, end time.Time
+	}{
+		{"this month", ThisMonthFilter, day(2025, time.March, 31, 23, 59), day(2025, time.March, 1, 0, 0), day(2025, time.March, 31, 23, 59).Add(59 * time.Second)},
+		{"this month on the 1st", ThisMonthFilter, day(2025, time.April, 1, 0, 0), day(2025, time.April, 1, 0, 0), day(2025, time.April, 30, 23, 59).Add(59 * time.Second)},
+		{"last month", LastMonthFilter, day(2025, time.March, 31, 12, 0), day(2025, time.February, 1, 0, 0), day(2025, time.February, 28, 23, 59).Add(59 * time.Second)},
+		{"last month in January", LastMonthFilter, day(2025, time.January, 1, 0, 0), day(2024, time.December, 1, 0, 0), day(2024, time.December, 31, 23, 59).Add(59 * time.Second)},
+		{"this year", ThisYearFilter, day(2024, time.December, 31, 23, 59), day(2024, time.January, 1, 0, 0), day(2024, time.December, 31, 23, 59).Add(59 * time.Second)},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			filter := tt.filter(tt.now)
+			assert.True(t, filter.StartDate.Equal(tt.start), "start %v", filter.StartDate)
+			assert.True(t, filter.EndDate.Equal(tt.end), "end %v", filter.EndDate)
+		})
+	}
+}
+
+func TestCategoryService_PresetFilter(t *testing.T) {
+	db := test.SetupTestDB(t)
+	service := NewCategoryService(repository.NewCategoryRepository(db))
+	now := time.Date(2025, time.March, 15, 12, 0, 0, 0, time.Local)
+
+	_, err := service.PresetFilter(t.Context(), models.PresetUncategorized, now)
+	assert.Error(t, err, "no Other category yet")
+
+	test.SeedDefaultCategories(t, db)
+	other, err := service.GetOther(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, models.OtherCategoryName, other.Name)
+	assert.Equal(t, models.TransactionTypeExpense, other.Type)
+
+	// A preset replaces the period and category but keeps the search and sort
+	filter := &models.TransactionFilter{
+		CategoryID: 99,
+		StartDate:  time.Date(2020, time.May, 1, 0, 0, 0, 0, time.Local),
+		EndDate:    time.Date(2020, time.May, 2, 0, 0, 0, 0, time.Local),
+		Search:     "coffee",
+		SortBy:     models.SortByAmount,
+		SortDir:    models.SortAsc,
+	}
+	preset, err := service.PresetFilter(t.Context(), models.PresetUncategorized, now)
+	require.NoError(t, err)
+	filter.ApplyPreset(preset)
+	assert.Equal(t, other.ID, filter.CategoryID)
+	assert.True(t, filter.StartDate.IsZero())
+	assert.True(t, filter.EndDate.IsZero())
+	assert.Equal(t, "coffee", filter.Search)
+	assert.Equal(t, models.SortByAmount, filter.SortBy)
+
+	preset, err = service.PresetFilter(t.Context(), models.PresetLastMonth, now)
+	require.NoError(t, err)
+	filter.ApplyPreset(preset)
+	assert.Zero(t, filter.CategoryID)
+	assert.Equal(t, time.February, filter.StartDate.Month())
+	assert.Equal(t, "coffee", filter.Search)
+
+	_, err = service.PresetFilter(t.Context(), "Next decade", now)
+	assert.Error(t, err)
+}
+
+func TestUnusualDates(t *testing.T) {
+	db := test.SetupTestDB(t)
+	service := NewCategoryService(repository.NewCategoryRepository(db))
+	txRepo := repository.NewTransactionRepository(db)
+	now := time.Date(2025, time.March, 15, 12, 0, 0, 0, time.Local)
+
+	// Tomorrow is fine, the day after is not; nor is more than 5 years back
+	assert.Empty(t, models.DateWarning(time.Date(2025, time.March, 16, 23, 0, 0, 0, time.Local), now))
+	assert.Equal(t, "Date is 2025-03-17, in the future", models.DateWarning(time.Date(2025, time.March, 17, 0, 0, 0, 0, time.Local), now))
+	assert.Equal(t, "Date is 2035-06-01, in the future", models.DateWarning(time.Date(2035, time.June, 1, 0, 0, 0, 0, time.Local), now))
+	assert.Empty(t, models.DateWarning(time.Date(2020, time.March, 15, 0, 0, 0, 0, time.Local), now))
+	assert.Equal(t, "Date is 2020-03-14, more than 5 years ago", models.DateWarning(time.Date(2020, time.March, 14, 0, 0, 0, 0, time.Local), now))
+
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	for _, date := range []time.Time{
+		time.Date(2035, time.June, 1, 0, 0, 0, 0, time.Local),
+		time.Date(2025, time.March, 10, 0, 0, 0, 0, time.Local),
+		time.Date(2015, time.March, 1, 0, 0, 0, 0, time.Local),
+	} {
+		tx := test.CreateTestTransaction(t, db, 10, food.ID)
+		require.NoError(t, db.Model(tx).Update("date", date).Error)
+	}
+
+	// The preset lists only the transactions outside the window
+	filter := &models.TransactionFilter{SortBy: models.SortByDate, SortDir: models.SortDesc}
+	preset, err := service.PresetFilter(t.Context(), models.PresetUnusualDates, now)
+	require.NoError(t, err)
+	filter.ApplyPreset(preset)
+	found, err := txRepo.GetByFilter(t.Context(), filter)
+	require.NoError(t, err)
+	require.Len(t, found, 2)
+	assert.Equal(t, 2035, found[0].Date.Year())
+	assert.Equal(t, 2015, found[1].Date.Year())
+
+	// Other presets look between their dates again
+	preset, err = service.PresetFilter(t.Context(), models.PresetThisMonth, now)
+	require.NoError(t, err)
+	filter.ApplyPreset(preset)
+	found, err = txRepo.GetByFilter(t.Context(), filter)
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.Equal(t, 10, found[0].Date.Day())
+}
@@ -0,0 +1,496 @@
+package service
+
+import (
+	"context"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"math"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"gorm.io/gorm"
+
+	"burnwise/internal/models"
+	"burnwise/internal/repository"
+)
+
+// ImportError describes one problem with one imported row
+type ImportError struct {
+	Row     int    `json:"row"` // index into the imported transactions
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+func (e ImportError) Error() string {
+	return fmt.Sprintf("row %d: %s: %s", e.Row+1, e.Field, e.Message)
+}
+
+type ImportService struct {
+	txService    *TransactionService
+	categoryRepo *repository.CategoryRepository
+}
+
+func NewImportService(txService *TransactionService, categoryRepo *repository.CategoryRepository) *ImportService {
+	return &ImportService{
+		txService:    txService,
+		categoryRepo: categoryRepo,
+	}
+}
+
+// ParseCSV reads transactions in the format written by
+// ExportTransactionsCSV. Columns are matched by header name, so their order
+// doesn't matter and the "Amount (USD)" column is ignored. Values that can't
+// be parsed are left zero for ValidateTransactions to report; an unknown
+// category keeps its name in Category with a zero CategoryID. Transfer rows
+// are read too, but ValidateTransactions turns them down: accounts aren't
+// imported, so neither are the transfers between them.
+func (s *ImportService) ParseCSV(ctx context.Context, r io.Reader) ([]*models.Transaction, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read header: %w", err)
+	}
+
+	columns := make(map[string]int)
+	for i, name := range header {
+		columns[strings.ToLower(strings.TrimSpace(name))] = i
+	}
+	for _, required := range []string{"date", "type", "category", "amount"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing %q column", required)
+		}
+	}
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var transactions []*models.Transaction
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to read row %d: %w", len(transactions)+1, err)
+		}
+
+		tx := &models.Transaction{
+			Type:        models.TransactionType(strings.ToLower(field(record, "type"))),
+			Description: field(record, "description"),
+			Currency:    strings.ToUpper(field(record, "currency")),
+		}
+		if tx.Type == "refund" {
+			tx.Type = models.TransactionTypeExpense
+			tx.IsRefund = true
+		}
+		if tx.Currency == "" {
+			tx.Currency = "USD"
+		}
+
+		if date, err := time.ParseInLocation("2006-01-02", field(record, "date"), time.Local); err == nil {
+			tx.Date = date
+		}
+
+		// Unparseable amounts stay zero and fail validation
+		if amount, err := strconv.ParseFloat(field(record, "amount"), 64); err == nil {
+			tx.Amount = amount
+		}
+
+		name := field(record, "category")
+		category, err := s.categoryRepo.FindByName(ctx, name, tx.Type)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, fmt.Errorf("failed to look up category %q: %w", name, err)
+		}
+		if category != nil {
+			tx.CategoryID = category.ID
+			tx.Category = *category
+		} else {
+			tx.Category = models.Category{Name: name}
+		}
+
+		transactions = append(transactions, tx)
+	}
+
+	return transactions, nil
+}
+
+// ValidateTransactions checks every transaction and returns all problems
+// found, in row order. A row can have several problems.
+func (s *ImportService) ValidateTransactions(ctx context.Context, transactions []*models.Transaction) []ImportError {
+	var problems []ImportError
+	categories := make(map[uint]*models.Category)
+
+	for row, tx := range transactions {
+		add := func(field, format string, args ...interface{}) {
+			problems = append(problems, ImportError{Row: row, Field: field, Message: fmt.Sprintf(format, args...)})
+		}
+
+		if tx.Date.IsZero() {
+			add("date", "missing or invalid date, expected YYYY-MM-DD")
+		}
+
+		validType := tx.Type == models.TransactionTypeIncome || tx.Type == models.TransactionTypeExpense
+		if tx.Type == models.TransactionTypeTransfer {
+			add("type", "transfers aren't imported; add them between accounts with 't' instead")
+			continue
+		} else if !validType {
+			add("type", "unknown type %q, expected income, expense or refund", tx.Type)
+		}
+
+		if tx.Amount < 0 {
+			add("amount", "negative amount %.2f; use the refund type for money coming back", tx.Amount)
+		} else if tx.Amount == 0 {
+			add("amount", "missing or invalid amount")
+		}
+
+		if len(tx.Currency) != 3 {
+			add("currency", "currency %q must be a 3-letter ISO code", tx.Currency)
+		}
+
+		if tx.CategoryID == 0 {
+			if tx.Category.Name == "" {
+				add("category", "category is required")
+			} else if validType {
+				add("category", "unknown %s category %q", tx.Type, tx.Category.Name)
+			}
+			continue
+		}
+
+		category, ok := categories[tx.CategoryID]
+		if !ok {
+			found, err := s.categoryRepo.GetByID(ctx, tx.CategoryID)
+			if err != nil {
+				found = nil
+			}
+			categories[tx.CategoryID] = found
+			category = found
+		}
+		if category == nil {
+			add("category", "category #%d does not exist", tx.CategoryID)
+		} else if validType && category.Type != tx.Type {
+			add("category", "category %q is for %s, not %s", category.Name, category.Type, tx.Type)
+		}
+	}
+
+	return problems
+}
+
+// DateWarnings returns the rows dated outside models.SaneDateWindow, such
+// as 2035 for 2025. They can still be imported, but are worth a look first.
+func (s *ImportService) DateWarnings(transactions []*models.Transaction, now time.Time) []ImportError {
+	var warnings []ImportError
+	for row, tx := range transactions {
+		if tx.Date.IsZero() {
+			continue
+		}
+		if warning := models.DateWarning(tx.Date, now); warning != "" {
+			warnings = append(warnings, ImportError{Row: row, Field: "date", Message: warning})
+		}
+	}
+	return warnings
+}
+
+// ArchivedCategories returns the archived categories the transactions use,
+// which Import unarchives
+func (s *ImportService) ArchivedCategories(ctx context.Context, transactions []*models.Transaction) ([]*models.Category, error) {
+	var archived []*models.Category
+	seen := make(map[uint]bool)
+	for _, tx := range transactions {
+		if tx.CategoryID == 0 || seen[tx.CategoryID] {
+			continue
+		}
+		seen[tx.CategoryID] = true
+
+		category, err := s.categoryRepo.GetByID(ctx, tx.CategoryID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("failed to look up category #%d: %w", tx.CategoryID, err)
+		}
+		if category.IsArchived {
+			archived = append(archived, category)
+		}
+	}
+	return archived, nil
+}
+
+// Import validates the transactions and stores only the rows without
+// problems, all at once. Archived categories that get new transactions are
+// unarchived in the same database transaction, as they are evidently in use
+// again. It returns how many
+// rows were imported and the problems with the rows that were skipped.
+func (s *ImportService) Import(ctx context.Context, transactions []*models.Transaction) (int, []ImportError, error) {
+	return s.importRows(ctx, transactions, nil)
+}
+
+// importRows validates and imports the transactions, skipping the rows with
+// parse problems too. A field that couldn't be read isn't reported again as
+// missing.
+func (s *ImportService) importRows(ctx context.Context, transactions []*models.Transaction, parseProblems []ImportError) (int, []ImportError, error) {
+	unread := make(map[ImportError]bool)
+	for _, problem := range parseProblems {
+		unread[ImportError{Row: problem.Row, Field: problem.Field}] = true
+	}
+	problems := append([]ImportError(nil), parseProblems...)
+	for _, problem := range s.ValidateTransactions(ctx, transactions) {
+		if !unread[ImportError{Row: problem.Row, Field: problem.Field}] {
+			problems = append(problems, problem)
+		}
+	}
+	sort.SliceStable(problems, func(i, j int) bool { return problems[i].Row < problems[j].Row })
+
+	invalid := make(map[int]bool)
+	for _, problem := range problems {
+		invalid[problem.Row] = true
+	}
+
+	var valid []*models.Transaction
+	for row, tx := range transactions {
+		if !invalid[row] {
+			valid = append(valid, tx)
+		}
+	}
+
+	if len(valid) == 0 {
+		return 0, problems, nil
+	}
+
+	// Archived categories are unarchived along with the import
+	if err := s.txService.ImportTransactions(ctx, valid); err != nil {
+		return 0, problems, err
+	}
+
+	return len(valid), problems, nil
+}
+
+// delimitedFields are the fields ParseDelimited can map columns to
+var delimitedFields = []string{"date", "type", "category", "description", "amount", "currency"}
+
+// delimitedDateLayouts are the date formats ParseDelimited accepts, tried in
+// order. Dates with slashes are read month first, as US spreadsheets write
+// them.
+var delimitedDateLayouts = []string{
+	"2006-01-02",
+	"2006-01-02 15:04:05",
+	"2006-01-02T15:04:05Z07:00",
+	"2006/01/02",
+	"2006.01.02",
+	"02.01.2006",
+	"2.1.2006",
+	"01/02/2006",
+	"1/2/2006",
+	"Jan 2, 2006",
+	"January 2, 2006",
+	"2 Jan 2006",
+	"2 January 2006",
+}
+
+// ParseDelimited reads transactions from data separated by delimiter, as
+// pasted from a spreadsheet with tabs between the cells. columnMap gives the
+// 0-based column of each field (date, type, category, description, amount,
+// currency); date, category and amount are required. A nil columnMap
+// matches columns by their header names instead, like ParseCSV.
+//
+// Pasted data is messy, so dates are read in several formats and amounts may
+// have currency symbols, thousands separators, a decimal comma or
+// parentheses. Without a type column every row is an expense and the sign
+// of its amount is dropped. Values that can't be read are returned as
+// problems with their row, which is still returned so rows keep their
+// numbers; blank lines are skipped.
+func (s *ImportService) ParseDelimited(ctx context.Context, r io.Reader, delimiter rune, hasHeader bool, columnMap map[string]int) ([]*models.Transaction, []ImportError, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = delimiter
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+	reader.TrimLeadingSpace = delimiter != ' '
+
+	if hasHeader {
+		header, err := reader.Read()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read header: %w", err)
+		}
+		if columnMap == nil {
+			columnMap = make(map[string]int)
+			for i, name := range header {
+				columnMap[strings.ToLower(strings.TrimSpace(name))] = i
+			}
+		}
+	} else if columnMap == nil {
+		return nil, nil, fmt.Errorf("a column mapping is required without a header row")
+	}
+
+	columns, err := checkColumnMap(columnMap, hasHeader)
+	if err != nil {
+		return nil, nil, err
+	}
+	_, hasType := columns["type"]
+
+	field := func(record []string, name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+		return strings.TrimSpace(record[i])
+	}
+
+	var transactions []*models.Transaction
+	var problems []ImportError
+	for {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to read row %d: %w", len(transactions)+1, err)
+		}
+		if strings.TrimSpace(strings.Join(record, "")) == "" {
+			continue
+		}
+
+		row := len(transactions)
+		add := func(field, format string, args ...interface{}) {
+			problems = append(problems, ImportError{Row: row, Field: field, Message: fmt.Sprintf(format, args...)})
+		}
+
+		tx := &models.Transaction{
+			Type:        models.TransactionTypeExpense,
+			Description: field(record, "description"),
+			Currency:    strings.ToUpper(field(record, "currency")),
+		}
+		if hasType {
+			tx.Type = models.TransactionType(strings.ToLower(field(record, "type")))
+			if tx.Type == "refund" {
+				tx.Type = models.TransactionTypeExpense
+				tx.IsRefund = true
+			}
+		}
+		if tx.Currency == "" {
+			tx.Currency = "USD"
+		}
+
+		if value := field(record, "date"); value != "" {
+			if date, ok := parseLooseDate(value); ok {
+				tx.Date = date
+			} else {
+				add("date", "can't read date %q", value)
+			}
+		}
+
+		if value := field(record, "amount"); value != "" {
+			amount, err := parseLooseAmount(value)
+			switch {
+			case err != nil:
+				add("amount", "can't read amount %q", value)
+			case hasType:
+				tx.Amount = amount
+			default:
+				tx.Amount = math.Abs(amount)
+			}
+		}
+
+		name := field(record, "category")
+		category, err := s.categoryRepo.FindByName(ctx, name, tx.Type)
+		if err != nil && !errors.Is(err, gorm.ErrRecordNotFound) {
+			return nil, nil, fmt.Errorf("failed to look up category %q: %w", name, err)
+		}
+		if category != nil {
+			tx.CategoryID = category.ID
+			tx.Category = *category
+		} else {
+			tx.Category = models.Category{Name: name}
+		}
+
+		transactions = append(transactions, tx)
+	}
+
+	return transactions, problems, nil
+}
+
+// ImportDelimited parses delimited data with ParseDelimited and imports the
+// rows that can be read and pass validation, like Import. The problems
+// returned include the values that couldn't be read.
+func (s *ImportService) ImportDelimited(ctx context.Context, r io.Reader, delimiter rune, hasHeader bool, columnMap map[string]int) (int, []ImportError, error) {
+	transactions, parseProblems, err := s.ParseDelimited(ctx, r, delimiter, hasHeader, columnMap)
+	if err != nil {
+		return 0, nil, err
+	}
+	return s.importRows(ctx, transactions, parseProblems)
+}
+
+// checkColumnMap checks a column mapping has the required fields and only
+// known ones. Columns named in a header that aren't fields are left out.
+func checkColumnMap(columnMap map[string]int, fromHeader bool) (map[string]int, error) {
+	columns := make(map[string]int)
+	for name, i := range columnMap {
+		name = strings.ToLower(strings.TrimSpace(name))
+		if !slices.Contains(delimitedFields, name) {
+			if fromHeader {
+				continue
+			}
+			return nil, fmt.Errorf("unknown field %q, expected one of %s", name, strings.Join(delimitedFields, ", "))
+		}
+		if i < 0 {
+			return nil, fmt.Errorf("invalid column %d for %q", i, name)
+		}
+		columns[name] = i
+	}
+	for _, required := range []string{"date", "category", "amount"} {
+		if _, ok := columns[required]; !ok {
+			return nil, fmt.Errorf("missing %q column", required)
+		}
+	}
+	return columns, nil
+}
+
+// parseLooseDate reads a date in any of delimitedDateLayouts
+func parseLooseDate(value string) (time.Time, bool) {
+	for _, layout := range delimitedDateLayouts {
+		if date, err := time.ParseInLocation(layout, value, time.Local); err == nil {
+			return time.Date(date.Year(), date.Month(), date.Day(), 0, 0, 0, 0, time.Local), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// parseLooseAmount reads an amount as spreadsheets show it, such as
+// "$1,234.50", "1.234,50 €", "-12" or "(12.00)", with the separators read
+// as amount expressions read them (see normalizeDecimal).
+func parseLooseAmount(value string) (float64, error) {
+	value = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsSpace(r), unicode.Is(unicode.Sc, r), r == '\'':
+			return -1
+		}
+		return r
+	}, value)
+
+	negative := false
+	if strings.HasPrefix(value, "(") && strings.HasSuffix(value, ")") {
+		negative = true
+		value = value[1 : len(value)-1]
+	}
+
+	amount, err := strconv.ParseFloat(normalizeDecimal(value), 64)
+	if err != nil {
+		return 0, err
+	}
+	if negative {
+		amount = -amount
+	}
+	return amount, nil
+}
@@ -0,0 +1,284 @@
+package service
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"burnwise/internal/models"
+	"burnwise/internal/repository"
+	test "burnwise/test/helpers"
+)
+
+func setupImportService(t *testing.T) (*ImportService, *TransactionService, *repository.TransactionRepository) {
+	t.Helper()
+
+	db := test.SetupTestDB(t)
+	txRepo := repository.NewTransactionRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	txService := NewTransactionService(txRepo, NewCurrencyService(settingsService))
+
+	test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+
+	return NewImportService(txService, categoryRepo), txService, txRepo
+}
+
+func TestImportService_ValidateTransactions(t *testing.T) {
+	importService, _, _ := setupImportService(t)
+
+	csvData := `Date,Type,Category,Description,Amount,Currency
+2025-03-01,expense,Food,Groceries,42.50,USD
+2025-13-40,expense,Food,Bad date,10,USD
+2025-03-02,expense,Takeout,Unknown category,12,USD
+2025-03-03,expense,Food,Negative,-5,USD
+2025-03-04,income,Salary,Pay,3000,USD
+2025-03-05,refund,Food,Returned item,8,USD
+`
+	transactions, err := importService.ParseCSV(t.Context(), strings.NewReader(csvData))
+	require.NoError(t, err)
+	require.Len(t, transactions, 6)
+	assert.True(t, transactions[5].IsRefund)
+
+	problems := importService.ValidateTransactions(t.Context(), transactions)
+	require.Len(t, problems, 3)
+
+	assert.Equal(t, 1, problems[0].Row)
+	assert.Equal(t, "date", problems[0].Field)
+
+	assert.Equal(t, 2, problems[1].Row)
+	assert.Equal(t, "category", problems[1].Field)
+	assert.Contains(t, problems[1].Message, `"Takeout"`)
+
+	assert.Equal(t, 3, problems[2].Row)
+	assert.Equal(t, "amount", problems[2].Field)
+	assert.Contains(t, problems[2].Error(), "row 4: amount: negative amount")
+}
+
+func TestImportService_DateWarnings(t *testing.T) {
+	importService, _, _ := setupImportService(t)
+
+	csvData := `Date,Type,Category,Description,Amount,Currency
+2025-03-01,expense,Food,Groceries,42.50,USD
+2035-03-02,expense,Food,Typo,10,USD
+bad,expense,Food,Bad date,10,USD
+`
+	transactions, err := importService.ParseCSV(t.Context(), strings.NewReader(csvData))
+	require.NoError(t, err)
+
+	// Unusual dates are only warned about; invalid ones are problems
+	now := time.Date(2025, time.March, 15, 0, 0, 0, 0, time.Local)
+	warnings := importService.DateWarnings(transactions, now)
+	require.Len(t, warnings, 1)
+	assert.Equal(t, 1, warnings[0].Row)
+	assert.Equal(t, "row 2: date: Date is 2035-03-02, in the future", warnings[0].Error())
+	assert.Len(t, importService.ValidateTransactions(t.Context(), transactions), 1)
+}
+
+func TestImportService_ImportSkipsInvalidRows(t *testing.T) {
+	importService, _, txRepo := setupImportService(t)
+
+	csvData := `Date,Type,Category,Description,Amount,Currency
+2025-03-01,expense,Food,Groceries,42.50,USD
+not-a-date,expense,Food,Bad date,10,USD
+2025-03-04,income,Salary,Pay,3000,USD
+`
+	transactions, err := importService.ParseCSV(t.Context(), strings.NewReader(csvData))
+	require.NoError(t, err)
+
+	imported, problems, err := importService.Import(t.Context(), transactions)
+	require.NoError(t, err)
+	assert.Equal(t, 2, imported)
+	require.Len(t, problems, 1)
+	assert.Equal(t, 1, problems[0].Row)
+
+	stored, err := txRepo.GetAll(t.Context())
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	for _, tx := range stored {
+		assert.NotEqual(t, "Bad date", tx.Description)
+		assert.Equal(t, tx.Amount, tx.AmountUSD)
+	}
+}
+
+func TestImportService_ImportUnarchivesCategories(t *testing.T) {
+	importService, _, txRepo := setupImportService(t)
+	categoryRepo := importService.categoryRepo
+
+	food, err := categoryRepo.FindByName(t.Context(), "Food", models.TransactionTypeExpense)
+	require.NoError(t, err)
+	require.NoError(t, categoryRepo.SetArchived(t.Context(), food.ID, true))
+
+	transactions, err := importService.ParseCSV(t.Context(), strings.NewReader(`Date,Type,Category,Description,Amount,Currency
+2025-03-01,expense,Food,Groceries,42.50,USD
+`))
+	require.NoError(t, err)
+	require.Empty(t, importService.ValidateTransactions(t.Context(), transactions), "archived categories can be imported into")
+
+	archived, err := importService.ArchivedCategories(t.Context(), transactions)
+	require.NoError(t, err)
+	require.Len(t, archived, 1)
+	assert.Equal(t, "Food", archived[0].Name)
+
+	imported, _, err := importService.Import(t.Context(), transactions)
+	require.NoError(t, err)
+	assert.Equal(t, 1, imported)
+
+	food, err = categoryRepo.GetByID(t.Context(), food.ID)
+	require.NoError(t, err)
+	assert.False(t, food.IsArchived)
+	all, err := txRepo.GetAll(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, all, 1)
+}
+
+func TestImportService_ParseCSVRequiresColumns(t *testing.T) {
+	importService, _, _ := setupImportService(t)
+
+	_, err := importService.ParseCSV(t.Context(), strings.NewReader("Date,Description,Amount\n2025-03-01,Lunch,12\n"))
+	assert.ErrorContains(t, err, `missing "type" column`)
+}
+
+func TestImportService_RoundTripsExport(t *testing.T) {
+	importService, txService, txRepo := setupImportService(t)
+
+	food, err := importService.categoryRepo.FindByName(t.Context(), "Food", models.TransactionTypeExpense)
+	require.NoError(t, err)
+	require.NoError(t, txService.Create(t.Context(), &models.Transaction{
+		Type:        models.TransactionTypeExpense,
+		Amount:      19.99,
+		Currency:    "USD",
+		CategoryID:  food.ID,
+		Description: "Pizza, large",
+		Date:        time.Date(2025, 3, 1, 0, 0, 0, 0, time.Local),
+	}))
+
+	var buf bytes.Buffer
+	require.NoError(t, NewExportService(txService).ExportTransactionsCSV(t.Context(), &buf, &models.TransactionFilter{}))
+
+	transactions, err := importService.ParseCSV(t.Context(), &buf)
+	require.NoError(t, err)
+	assert.Empty(t, importService.ValidateTransactions(t.Context(), transactions))
+
+	imported, _, err := importService.Import(t.Context(), transactions)
+	require.NoError(t, err)
+	assert.Equal(t, 1, imported)
+
+	stored, err := txRepo.GetAll(t.Context())
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.Equal(t, "Pizza, large", stored[1].Description)
+}
+
+func TestTransactionService_ImportTransactionsIsAllOrNothing(t *testing.T) {
+	importService, txService, txRepo := setupImportService(t)
+
+	food, err := importService.categoryRepo.FindByName(t.Context(), "Food", models.TransactionTypeExpense)
+	require.NoError(t, err)
+
+	transactions := []*models.Transaction{
+		{Type: models.TransactionTypeExpense, Amount: 10, Currency: "USD", CategoryID: food.ID, Date: time.Now()},
+		{Type: models.TransactionTypeExpense, Amount: -1, Currency: "USD", CategoryID: food.ID, Date: time.Now()},
+	}
+
+	err = txService.ImportTransactions(t.Context(), transactions)
+	assert.Error(t, err)
+
+	stored, err := txRepo.GetAll(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+}
+
+func TestImportService_ImportDelimited(t *testing.T) {
+	importService, _, txRepo := setupImportService(t)
+
+	// Pasted from a spreadsheet: tab-separated, columns in its own order,
+	// no type column and amounts as the sheet shows them
+	pasted := "Amount\tWhat\tWhen\tCategory\n" +
+		"$1,234.50\tNew laptop\t03/15/2025\tFood\n" +
+		"\n" +
+		"-12,50\tLunch\t2025-03-16\tFood\n" +
+		"(8.00)\tCoffee\t17.03.2025\tFood\n" +
+		"abc\tBroken\t2025-03-18\tFood\n" +
+		"5\tBad date\tsoon\tFood\n" +
+		"7\tTakeout\t2025-03-19\tTakeout\n"
+
+	columnMap := map[string]int{"amount": 0, "description": 1, "date": 2, "category": 3}
+	imported, problems, err := importService.ImportDelimited(t.Context(), strings.NewReader(pasted), '\t', true, columnMap)
+	require.NoError(t, err)
+	assert.Equal(t, 3, imported)
+
+	require.Len(t, problems, 3)
+	assert.Equal(t, ImportError{Row: 3, Field: "amount", Message: `can't read amount "abc"`}, problems[0])
+	assert.Equal(t, ImportError{Row: 4, Field: "date", Message: `can't read date "soon"`}, problems[1])
+	assert.Equal(t, 5, problems[2].Row)
+	assert.Equal(t, "category", problems[2].Field)
+
+	stored, err := txRepo.GetAll(t.Context())
+	require.NoError(t, err)
+	amounts := make(map[string]float64)
+	for _, tx := range stored {
+		assert.Equal(t, models.TransactionTypeExpense, tx.Type)
+		amounts[tx.Description] = tx.Amount
+	}
+	assert.Equal(t, map[string]float64{"New laptop": 1234.50, "Lunch": 12.50, "Coffee": 8}, amounts)
+}
+
+func TestImportService_ParseDelimited(t *testing.T) {
+	importService, _, _ := setupImportService(t)
+
+	t.Run("matches header names without a mapping", func(t *testing.T) {
+		data := "date;type;category;amount;currency\n2025-03-01;income;Salary;3.000,00;eur\n2025-03-02;refund;Food;4;USD\n"
+		transactions, problems, err := importService.ParseDelimited(t.Context(), strings.NewReader(data), ';', true, nil)
+		require.NoError(t, err)
+		assert.Empty(t, problems)
+		require.Len(t, transactions, 2)
+		assert.Equal(t, models.TransactionTypeIncome, transactions[0].Type)
+		assert.Equal(t, 3000.0, transactions[0].Amount)
+		assert.Equal(t, "EUR", transactions[0].Currency)
+		assert.NotZero(t, transactions[0].CategoryID)
+		assert.True(t, transactions[1].IsRefund)
+	})
+
+	t.Run("needs a mapping without a header", func(t *testing.T) {
+		_, _, err := importService.ParseDelimited(t.Context(), strings.NewReader("2025-03-01\tFood\t4\n"), '\t', false, nil)
+		assert.Error(t, err)
+	})
+
+	t.Run("rejects incomplete or unknown mappings", func(t *testing.T) {
+		_, _, err := importService.ParseDelimited(t.Context(), strings.NewReader(""), '\t', false, map[string]int{"date": 0, "amount": 1})
+		assert.ErrorContains(t, err, `missing "category" column`)
+
+		_, _, err = importService.ParseDelimited(t.Context(), strings.NewReader(""), '\t', false, map[string]int{"date": 0, "amount": 1, "category": 2, "payee": 3})
+		assert.ErrorContains(t, err, `unknown field "payee"`)
+	})
+}
+
+func TestParseLooseAmount(t *testing.T) {
+	tests := map[string]float64{
+		"12":         12,
+		"$1,234.50":  1234.50,
+		"1.234,50 €": 1234.50,
+		"12,5":       12.5,
+		"1,234":      1234,
+		"1,234,567":  1234567,
+		"1'234.50":   1234.50,
+		"(8.00)":     -8,
+		"-£3.20":     -3.20,
+	}
+	for input, want := range tests {
+		got, err := parseLooseAmount(input)
+		require.NoError(t, err, input)
+		assert.InDelta(t, want, got, 0.001, input)
+	}
+
+	_, err := parseLooseAmount("abc")
+	assert.Error(t, err)
+}
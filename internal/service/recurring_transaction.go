@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"burnwise/internal/models"
@@ -12,6 +14,7 @@ type RecurringTransactionService struct {
 	repo            *repository.RecurringTransactionRepository
 	transactionRepo *repository.TransactionRepository
 	currencyService *CurrencyService
+	undoService     *UndoService
 }
 
 func NewRecurringTransactionService(
@@ -26,127 +29,435 @@ func NewRecurringTransactionService(
 	}
 }
 
-// Create creates a new recurring transaction
-func (s *RecurringTransactionService) Create(rt *models.RecurringTransaction) error {
+// SetUndoService enables recording deletes for undo
+func (s *RecurringTransactionService) SetUndoService(undoService *UndoService) {
+	s.undoService = undoService
+}
+
+// DuplicateRecurringError is returned by Create when an active recurring
+// transaction already looks the same as the new one
+type DuplicateRecurringError struct {
+	Existing *models.RecurringTransaction
+}
+
+func (e *DuplicateRecurringError) Error() string {
+	return fmt.Sprintf("possible duplicate of '%s'", e.Existing.Description)
+}
+
+// Create creates a new recurring transaction. If an active one has the same
+// description, category, amount and schedule, it returns a
+// *DuplicateRecurringError instead; CreateDuplicate saves it regardless.
+func (s *RecurringTransactionService) Create(ctx context.Context, rt *models.RecurringTransaction) error {
+	return s.create(ctx, rt, true)
+}
+
+// CreateDuplicate creates a recurring transaction without checking for
+// duplicates, once the user has confirmed it is not one
+func (s *RecurringTransactionService) CreateDuplicate(ctx context.Context, rt *models.RecurringTransaction) error {
+	return s.create(ctx, rt, false)
+}
+
+func (s *RecurringTransactionService) create(ctx context.Context, rt *models.RecurringTransaction, checkDuplicate bool) error {
 	if err := rt.Validate(); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
+	if checkDuplicate {
+		existing, err := s.findDuplicate(ctx, rt)
+		if err != nil {
+			return err
+		}
+		if existing != nil {
+			return &DuplicateRecurringError{Existing: existing}
+		}
+	}
+
 	// Set initial next due date if not set
 	if rt.NextDueDate.IsZero() {
 		rt.NextDueDate = rt.StartDate
 	}
 
-	return s.repo.Create(rt)
+	// The amount entered is today's, so only later anniversaries raise it
+	rt.IncreasesApplied = rt.AnniversariesBy(time.Now())
+
+	return s.repo.Create(ctx, rt)
 }
 
 // Update updates a recurring transaction
-func (s *RecurringTransactionService) Update(rt *models.RecurringTransaction) error {
+func (s *RecurringTransactionService) Update(ctx context.Context, rt *models.RecurringTransaction) error {
 	if err := rt.Validate(); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
-	existing, err := s.repo.GetByID(rt.ID)
+	existing, err := s.repo.GetByID(ctx, rt.ID)
 	if err != nil {
 		return fmt.Errorf("recurring transaction not found: %w", err)
 	}
 
 	// If frequency changed, recalculate next due date
 	if existing.Frequency != rt.Frequency || existing.FrequencyValue != rt.FrequencyValue {
-		if rt.LastProcessed != nil {
-			rt.NextDueDate = rt.CalculateNextDueDate(*rt.LastProcessed)
-		} else {
-			rt.NextDueDate = rt.CalculateNextDueDate(rt.StartDate)
+		rt.NextDueDate = nextDueAfterChange(rt, time.Now())
+	}
+
+	// Turning on the annual increase, or moving the anniversary, starts it
+	// from today's amount rather than catching up on past years
+	if existing.AnnualIncreasePercent == 0 || !existing.StartDate.Equal(rt.StartDate) {
+		rt.IncreasesApplied = rt.AnniversariesBy(time.Now())
+	}
+
+	// A new amount in the same currency is a price change worth keeping;
+	// switching currency is not
+	if existing.Amount != rt.Amount && existing.Currency == rt.Currency {
+		return s.repo.UpdateWithPriceChange(ctx, rt, &models.RecurringTransactionPriceHistory{
+			RecurringTransactionID: rt.ID,
+			OldAmount:              existing.Amount,
+			NewAmount:              rt.Amount,
+			Currency:               rt.Currency,
+			ChangedAt:              time.Now(),
+		})
+	}
+
+	return s.repo.Update(ctx, rt)
+}
+
+// findDuplicate returns an active recurring transaction with the same
+// description, ignoring case and spacing, category, amount and schedule
+func (s *RecurringTransactionService) findDuplicate(ctx context.Context, rt *models.RecurringTransaction) (*models.RecurringTransaction, error) {
+	active, err := s.repo.GetActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check for duplicates: %w", err)
+	}
+
+	description := normalizeDescription(rt.Description)
+	for _, existing := range active {
+		if existing.CategoryID == rt.CategoryID &&
+			existing.Amount == rt.Amount &&
+			existing.Currency == rt.Currency &&
+			existing.Frequency == rt.Frequency &&
+			existing.FrequencyValue == rt.FrequencyValue &&
+			normalizeDescription(existing.Description) == description {
+			return existing, nil
 		}
 	}
+	return nil, nil
+}
 
-	return s.repo.Update(rt)
+// normalizeDescription lowercases a description and collapses its spacing
+func normalizeDescription(description string) string {
+	return strings.ToLower(strings.Join(strings.Fields(description), " "))
 }
 
-// Delete deletes a recurring transaction
-func (s *RecurringTransactionService) Delete(id uint) error {
-	// Check if any transactions have been generated
-	count, err := s.repo.CountGeneratedTransactions(id)
+// nextDueAfterChange works out the next due date after a schedule change.
+// It rolls forward from the later of the last processing and the start date
+// until it reaches today, so an old rule never backfills on the next run.
+func nextDueAfterChange(rt *models.RecurringTransaction, now time.Time) time.Time {
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	next := rt.StartDate
+	if rt.LastProcessed != nil && rt.LastProcessed.After(rt.StartDate) {
+		next = rt.CalculateNextDueDate(*rt.LastProcessed)
+	}
+	for next.Before(today) {
+		next = rt.CalculateNextDueDate(next)
+	}
+
+	return next
+}
+
+// Delete deletes a recurring transaction, or deactivates it when
+// transactions have been generated from it so their history stays
+func (s *RecurringTransactionService) Delete(ctx context.Context, id uint) error {
+	rt, _, reverse, err := s.delete(ctx, id)
 	if err != nil {
 		return err
 	}
+	if s.undoService != nil && reverse != nil {
+		s.undoService.Record(fmt.Sprintf("delete recurring transaction '%s'", rt.Description), reverse)
+	}
+	return nil
+}
+
+// delete deletes or deactivates a recurring transaction, returning which
+// it did and how to reverse it, or nil when there is nothing to reverse
+func (s *RecurringTransactionService) delete(ctx context.Context, id uint) (*models.RecurringTransaction, BulkOutcome, func(ctx context.Context) error, error) {
+	rt, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, BulkFailed, nil, fmt.Errorf("recurring transaction not found: %w", err)
+	}
+
+	// Check if any transactions have been generated
+	count, err := s.repo.CountGeneratedTransactions(ctx, id)
+	if err != nil {
+		return rt, BulkFailed, nil, err
+	}
 
 	if count > 0 {
 		// Deactivate instead of delete if transactions exist
-		return s.repo.Deactivate(id)
+		if err := s.repo.Deactivate(ctx, id); err != nil {
+			return rt, BulkFailed, nil, err
+		}
+		if !rt.IsActive {
+			return rt, BulkDeactivated, nil, nil
+		}
+		return rt, BulkDeactivated, func(ctx context.Context) error {
+			return s.repo.Activate(ctx, id)
+		}, nil
+	}
+
+	if err := s.repo.Delete(ctx, id); err != nil {
+		return rt, BulkFailed, nil, err
+	}
+	return rt, BulkDeleted, func(ctx context.Context) error {
+		return s.repo.Restore(ctx, id)
+	}, nil
+}
+
+// BulkOutcome is what a bulk action does to one recurring transaction
+type BulkOutcome string
+
+const (
+	BulkDeleted BulkOutcome = "deleted"
+	// BulkDeactivated is deleting one that has generated transactions,
+	// which deactivates it instead
+	BulkDeactivated BulkOutcome = "deactivated"
+	BulkPaused      BulkOutcome = "paused"
+	// BulkUnchanged is pausing one that is paused already
+	BulkUnchanged BulkOutcome = "unchanged"
+	BulkFailed    BulkOutcome = "failed"
+)
+
+// BulkResult is the outcome of a bulk action for one recurring transaction
+type BulkResult struct {
+	ID          uint
+	Description string
+	Outcome     BulkOutcome
+	Err         error
+}
+
+// CountOutcomes counts the results by outcome
+func CountOutcomes(results []BulkResult) map[BulkOutcome]int {
+	counts := make(map[BulkOutcome]int)
+	for _, result := range results {
+		counts[result.Outcome]++
+	}
+	return counts
+}
+
+// PlanDeleteMany returns what DeleteMany would do to each of the recurring
+// transactions without changing them, for confirming it first
+func (s *RecurringTransactionService) PlanDeleteMany(ctx context.Context, ids []uint) ([]BulkResult, error) {
+	results := make([]BulkResult, 0, len(ids))
+	for _, id := range ids {
+		rt, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("recurring transaction not found: %w", err)
+		}
+		count, err := s.repo.CountGeneratedTransactions(ctx, id)
+		if err != nil {
+			return nil, err
+		}
+
+		outcome := BulkDeleted
+		if count > 0 {
+			outcome = BulkDeactivated
+		}
+		results = append(results, BulkResult{ID: id, Description: rt.Description, Outcome: outcome})
+	}
+	return results, nil
+}
+
+// DeleteMany deletes each of the recurring transactions like Delete, those
+// with generated transactions being deactivated instead. One failing
+// doesn't stop the others; its result holds the error. A single undo
+// brings back all of them.
+func (s *RecurringTransactionService) DeleteMany(ctx context.Context, ids []uint) []BulkResult {
+	results := make([]BulkResult, 0, len(ids))
+	var reverses []func(ctx context.Context) error
+	for _, id := range ids {
+		rt, outcome, reverse, err := s.delete(ctx, id)
+		result := BulkResult{ID: id, Outcome: outcome, Err: err}
+		if rt != nil {
+			result.Description = rt.Description
+		}
+		results = append(results, result)
+		if reverse != nil {
+			reverses = append(reverses, reverse)
+		}
 	}
 
-	return s.repo.Delete(id)
+	if s.undoService != nil && len(reverses) > 0 {
+		s.undoService.Record(fmt.Sprintf("delete %d recurring transactions", len(reverses)), func(ctx context.Context) error {
+			for _, reverse := range reverses {
+				if err := reverse(ctx); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+	}
+	return results
 }
 
 // GetByID retrieves a recurring transaction by ID
-func (s *RecurringTransactionService) GetByID(id uint) (*models.RecurringTransaction, error) {
-	return s.repo.GetByID(id)
+func (s *RecurringTransactionService) GetByID(ctx context.Context, id uint) (*models.RecurringTransaction, error) {
+	return s.repo.GetByID(ctx, id)
 }
 
 // GetAll retrieves all recurring transactions
-func (s *RecurringTransactionService) GetAll() ([]*models.RecurringTransaction, error) {
-	return s.repo.GetAll()
+func (s *RecurringTransactionService) GetAll(ctx context.Context) ([]*models.RecurringTransaction, error) {
+	return s.repo.GetAll(ctx)
 }
 
 // GetActive retrieves all active recurring transactions
-func (s *RecurringTransactionService) GetActive() ([]*models.RecurringTransaction, error) {
-	return s.repo.GetActive()
+func (s *RecurringTransactionService) GetActive(ctx context.Context) ([]*models.RecurringTransaction, error) {
+	return s.repo.GetActive(ctx)
 }
 
 // GetDue retrieves all recurring transactions due by a specific date
-func (s *RecurringTransactionService) GetDue(asOf time.Time) ([]*models.RecurringTransaction, error) {
-	return s.repo.GetDue(asOf)
+func (s *RecurringTransactionService) GetDue(ctx context.Context, asOf time.Time) ([]*models.RecurringTransaction, error) {
+	return s.repo.GetDue(ctx, asOf)
 }
 
 // ProcessDueTransactions processes all due recurring transactions
-func (s *RecurringTransactionService) ProcessDueTransactions(asOf time.Time) (int, error) {
-	dueTransactions, err := s.repo.GetDue(asOf)
+func (s *RecurringTransactionService) ProcessDueTransactions(ctx context.Context, asOf time.Time) (int, error) {
+	dueTransactions, err := s.repo.GetDue(ctx, asOf)
 	if err != nil {
 		return 0, fmt.Errorf("failed to get due transactions: %w", err)
 	}
 
 	processed := 0
 	for _, rt := range dueTransactions {
-		// Process all due dates up to asOf
-		for rt.IsDue(asOf) {
-			if err := s.processRecurringTransaction(rt, rt.NextDueDate); err != nil {
-				// Log error but continue processing others
-				fmt.Printf("Error processing recurring transaction %d: %v\n", rt.ID, err)
-				break
+		processed += s.processSchedule(ctx, rt, asOf)
+	}
+
+	return processed, nil
+}
+
+// ProcessOne processes the due occurrences of a single recurring transaction
+// up to asOf, leaving every other schedule untouched
+func (s *RecurringTransactionService) ProcessOne(ctx context.Context, id uint, asOf time.Time) (int, error) {
+	rt, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return 0, fmt.Errorf("recurring transaction not found: %w", err)
+	}
+
+	return s.processSchedule(ctx, rt, asOf), nil
+}
+
+// processSchedule generates every occurrence of rt due up to asOf and saves
+// the advanced schedule. It returns how many occurrences were processed.
+func (s *RecurringTransactionService) processSchedule(ctx context.Context, rt *models.RecurringTransaction, asOf time.Time) int {
+	processed := 0
+
+	// Process all due dates up to asOf
+	for rt.IsDue(asOf) {
+		if err := s.processRecurringTransaction(ctx, rt, rt.NextDueDate); err != nil {
+			// Log error but continue processing others
+			fmt.Printf("Error processing recurring transaction %d: %v\n", rt.ID, err)
+			break
+		}
+		processed++
+
+		// Update next due date
+		rt.NextDueDate = rt.CalculateNextDueDate(rt.NextDueDate)
+		
+		// Check if we should deactivate
+		if rt.ShouldDeactivate(asOf) {
+			rt.IsActive = false
+			break
+		}
+	}
+
+	// Update the recurring transaction
+	if err := s.repo.Update(ctx, rt); err != nil {
+		fmt.Printf("Error updating recurring transaction %d: %v\n", rt.ID, err)
+	}
+
+	return processed
+}
+
+// CountDueOccurrences returns how many occurrences of a recurring transaction
+// are due up to asOf, counting skipped ones
+func (s *RecurringTransactionService) CountDueOccurrences(ctx context.Context, id uint, asOf time.Time) (int, error) {
+	rt, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return 0, fmt.Errorf("recurring transaction not found: %w", err)
+	}
+
+	count := 0
+	for rt.IsDue(asOf) {
+		count++
+		rt.NextDueDate = rt.CalculateNextDueDate(rt.NextDueDate)
+		if rt.ShouldDeactivate(asOf) {
+			break
+		}
+	}
+
+	return count, nil
+}
+
+// SkipPastOccurrences advances the next due date past asOf without
+// generating anything, so past occurrences are never backfilled
+func (s *RecurringTransactionService) SkipPastOccurrences(ctx context.Context, id uint, asOf time.Time) error {
+	rt, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("recurring transaction not found: %w", err)
+	}
+
+	if rt.NextDueDate.After(asOf) {
+		return nil
+	}
+	for !rt.NextDueDate.After(asOf) {
+		rt.NextDueDate = rt.CalculateNextDueDate(rt.NextDueDate)
+	}
+
+	return s.repo.UpdateNextDueDate(ctx, id, rt.NextDueDate)
+}
+
+// PreviewDueTransactions returns the transactions ProcessDueTransactions
+// would generate up to asOf, without writing anything
+func (s *RecurringTransactionService) PreviewDueTransactions(ctx context.Context, asOf time.Time) ([]*models.Transaction, error) {
+	dueTransactions, err := s.repo.GetDue(ctx, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get due transactions: %w", err)
+	}
+
+	var preview []*models.Transaction
+	for _, rt := range dueTransactions {
+		// Work on a copy so the schedule is not advanced
+		planned := *rt
+		for planned.IsDue(asOf) {
+			tx, err := s.buildOccurrence(ctx, &planned, planned.NextDueDate)
+			if err != nil {
+				return nil, fmt.Errorf("failed to preview recurring transaction %d: %w", rt.ID, err)
+			}
+			if tx != nil {
+				tx.Category = rt.Category
+				preview = append(preview, tx)
 			}
-			processed++
 
-			// Update next due date
-			rt.NextDueDate = rt.CalculateNextDueDate(rt.NextDueDate)
-			
-			// Check if we should deactivate
-			if rt.ShouldDeactivate(asOf) {
-				rt.IsActive = false
+			planned.NextDueDate = planned.CalculateNextDueDate(planned.NextDueDate)
+			if planned.ShouldDeactivate(asOf) {
 				break
 			}
 		}
-
-		// Update the recurring transaction
-		if err := s.repo.Update(rt); err != nil {
-			fmt.Printf("Error updating recurring transaction %d: %v\n", rt.ID, err)
-		}
 	}
 
-	return processed, nil
+	return preview, nil
 }
 
-// processRecurringTransaction processes a single occurrence of a recurring transaction
-func (s *RecurringTransactionService) processRecurringTransaction(rt *models.RecurringTransaction, dueDate time.Time) error {
+// buildOccurrence generates the transaction for a single occurrence, applying
+// any skip or modification. It returns nil for skipped occurrences.
+func (s *RecurringTransactionService) buildOccurrence(ctx context.Context, rt *models.RecurringTransaction, dueDate time.Time) (*models.Transaction, error) {
 	// Check if this occurrence has been modified or skipped
-	occurrence, err := s.repo.GetOccurrence(rt.ID, dueDate)
+	occurrence, err := s.repo.GetOccurrence(ctx, rt.ID, dueDate)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
 	if occurrence != nil && occurrence.Action == models.OccurrenceActionSkip {
 		// Skip this occurrence
-		return nil
+		return nil, nil
 	}
 
 	// Generate transaction
@@ -163,14 +474,32 @@ func (s *RecurringTransactionService) processRecurringTransaction(rt *models.Rec
 	}
 
 	// Convert to USD
-	amountUSD, err := s.currencyService.ConvertToUSD(tx.Amount, tx.Currency)
+	amountUSD, source, err := s.currencyService.ConvertToUSDWithInfo(ctx, tx.Amount, tx.Currency)
 	if err != nil {
-		return fmt.Errorf("failed to convert currency: %w", err)
+		return nil, fmt.Errorf("failed to convert currency: %w", err)
 	}
 	tx.AmountUSD = amountUSD
+	tx.RateSource = source
+
+	return tx, nil
+}
+
+// processRecurringTransaction processes a single occurrence of a recurring transaction
+func (s *RecurringTransactionService) processRecurringTransaction(ctx context.Context, rt *models.RecurringTransaction, dueDate time.Time) error {
+	if err := s.applyAnnualIncreases(ctx, rt, dueDate); err != nil {
+		return err
+	}
+
+	tx, err := s.buildOccurrence(ctx, rt, dueDate)
+	if err != nil {
+		return err
+	}
+	if tx == nil {
+		return nil
+	}
 
 	// Create the transaction
-	if err := s.transactionRepo.Create(tx); err != nil {
+	if err := s.transactionRepo.Create(ctx, tx); err != nil {
 		return fmt.Errorf("failed to create transaction: %w", err)
 	}
 
@@ -181,8 +510,38 @@ func (s *RecurringTransactionService) processRecurringTransaction(rt *models.Rec
 	return nil
 }
 
+// applyAnnualIncreases raises the amount for each anniversary of the start
+// date up to dueDate that it doesn't reflect yet, saving every new amount
+// to the price history as of its anniversary. Skipped occurrences still
+// go through here, so a skip never loses a year's increase.
+func (s *RecurringTransactionService) applyAnnualIncreases(ctx context.Context, rt *models.RecurringTransaction, dueDate time.Time) error {
+	if rt.AnnualIncreasePercent == 0 {
+		return nil
+	}
+
+	for rt.IncreasesApplied < rt.AnniversariesBy(dueDate) {
+		anniversary := rt.StartDate.AddDate(rt.IncreasesApplied+1, 0, 0)
+		oldAmount := rt.Amount
+		rt.Amount = rt.AmountOn(anniversary)
+		rt.IncreasesApplied++
+
+		err := s.repo.UpdateWithPriceChange(ctx, rt, &models.RecurringTransactionPriceHistory{
+			RecurringTransactionID: rt.ID,
+			OldAmount:              oldAmount,
+			NewAmount:              rt.Amount,
+			Currency:               rt.Currency,
+			ChangedAt:              anniversary,
+		})
+		if err != nil {
+			return fmt.Errorf("failed to apply annual increase: %w", err)
+		}
+	}
+
+	return nil
+}
+
 // SkipOccurrence skips a specific occurrence of a recurring transaction
-func (s *RecurringTransactionService) SkipOccurrence(recurringTransactionID uint, date time.Time, reason string) error {
+func (s *RecurringTransactionService) SkipOccurrence(ctx context.Context, recurringTransactionID uint, date time.Time, reason string) error {
 	occurrence := &models.RecurringTransactionOccurrence{
 		RecurringTransactionID: recurringTransactionID,
 		OccurrenceDate:         date,
@@ -190,11 +549,11 @@ func (s *RecurringTransactionService) SkipOccurrence(recurringTransactionID uint
 		SkipReason:             &reason,
 	}
 
-	return s.repo.CreateOccurrence(occurrence)
+	return s.repo.CreateOccurrence(ctx, occurrence)
 }
 
 // ModifyOccurrence modifies a specific occurrence of a recurring transaction
-func (s *RecurringTransactionService) ModifyOccurrence(
+func (s *RecurringTransactionService) ModifyOccurrence(ctx context.Context, 
 	recurringTransactionID uint,
 	date time.Time,
 	amount *float64,
@@ -208,17 +567,41 @@ func (s *RecurringTransactionService) ModifyOccurrence(
 		ModifiedDescription:    description,
 	}
 
-	return s.repo.CreateOccurrence(occurrence)
+	return s.repo.CreateOccurrence(ctx, occurrence)
 }
 
 // Pause pauses a recurring transaction
-func (s *RecurringTransactionService) Pause(id uint) error {
-	return s.repo.Deactivate(id)
+func (s *RecurringTransactionService) Pause(ctx context.Context, id uint) error {
+	return s.repo.Deactivate(ctx, id)
+}
+
+// PauseMany pauses each of the recurring transactions, leaving those
+// paused already unchanged. One failing doesn't stop the others; its result
+// holds the error.
+func (s *RecurringTransactionService) PauseMany(ctx context.Context, ids []uint) []BulkResult {
+	results := make([]BulkResult, 0, len(ids))
+	for _, id := range ids {
+		rt, err := s.repo.GetByID(ctx, id)
+		if err != nil {
+			results = append(results, BulkResult{ID: id, Outcome: BulkFailed, Err: fmt.Errorf("recurring transaction not found: %w", err)})
+			continue
+		}
+
+		result := BulkResult{ID: id, Description: rt.Description, Outcome: BulkUnchanged}
+		if rt.IsActive {
+			result.Outcome = BulkPaused
+			if err := s.repo.Deactivate(ctx, id); err != nil {
+				result.Outcome, result.Err = BulkFailed, err
+			}
+		}
+		results = append(results, result)
+	}
+	return results
 }
 
 // Resume resumes a recurring transaction
-func (s *RecurringTransactionService) Resume(id uint) error {
-	rt, err := s.repo.GetByID(id)
+func (s *RecurringTransactionService) Resume(ctx context.Context, id uint) error {
+	rt, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return err
 	}
@@ -231,24 +614,155 @@ func (s *RecurringTransactionService) Resume(id uint) error {
 		for rt.NextDueDate.Before(now) {
 			rt.NextDueDate = rt.CalculateNextDueDate(rt.NextDueDate)
 		}
-		if err := s.repo.UpdateNextDueDate(id, rt.NextDueDate); err != nil {
+		if err := s.repo.UpdateNextDueDate(ctx, id, rt.NextDueDate); err != nil {
 			return err
 		}
 	}
 
-	return s.repo.Activate(id)
+	return s.repo.Activate(ctx, id)
 }
 
 // GetGeneratedTransactions retrieves all transactions generated from a recurring transaction
-func (s *RecurringTransactionService) GetGeneratedTransactions(recurringTransactionID uint) ([]*models.Transaction, error) {
-	return s.repo.GetGeneratedTransactions(recurringTransactionID)
+func (s *RecurringTransactionService) GetGeneratedTransactions(ctx context.Context, recurringTransactionID uint) ([]*models.Transaction, error) {
+	return s.repo.GetGeneratedTransactions(ctx, recurringTransactionID)
+}
+
+// GetPriceHistory returns the amount changes of a recurring transaction,
+// oldest first
+func (s *RecurringTransactionService) GetPriceHistory(ctx context.Context, id uint) ([]*models.RecurringTransactionPriceHistory, error) {
+	history, err := s.repo.GetPriceHistory(ctx, id)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get price history: %w", err)
+	}
+	return history, nil
+}
+
+// GetTotalGenerated returns the total USD amount and number of transactions
+// generated from a recurring transaction so far
+func (s *RecurringTransactionService) GetTotalGenerated(ctx context.Context, id uint) (float64, int, error) {
+	transactions, err := s.repo.GetGeneratedTransactions(ctx, id)
+	if err != nil {
+		return 0, 0, fmt.Errorf("failed to get generated transactions: %w", err)
+	}
+
+	var total float64
+	for _, tx := range transactions {
+		total += tx.AmountUSD
+	}
+
+	return total, len(transactions), nil
+}
+
+// GetGeneratedCounts returns how many transactions each recurring
+// transaction has generated, keyed by its ID, counted in one query so a
+// long list doesn't need one per item
+func (s *RecurringTransactionService) GetGeneratedCounts(ctx context.Context) (map[uint]int64, error) {
+	counts, err := s.repo.CountGeneratedByRecurring(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to count generated transactions: %w", err)
+	}
+	return counts, nil
+}
+
+// maxOccurrenceLookahead bounds how many skipped occurrences GetNextIncome
+// steps over for one schedule
+const maxOccurrenceLookahead = 366
+
+// GetNextIncome returns the earliest upcoming occurrence across all active
+// recurring incomes, stepping over skipped occurrences. It returns nil when
+// no recurring income is scheduled.
+func (s *RecurringTransactionService) GetNextIncome(ctx context.Context) (*models.NextIncome, error) {
+	active, err := s.repo.GetActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recurring transactions: %w", err)
+	}
+
+	var next *models.NextIncome
+	for _, rt := range active {
+		if rt.Type != models.TransactionTypeIncome {
+			continue
+		}
+
+		// Work on a copy so the schedule is not advanced
+		planned := *rt
+		for i := 0; i < maxOccurrenceLookahead; i++ {
+			date := planned.NextDueDate
+			if planned.EndDate != nil && date.After(*planned.EndDate) {
+				break
+			}
+			if next != nil && !date.Before(next.Date) {
+				break
+			}
+
+			tx, err := s.buildOccurrence(ctx, &planned, date)
+			if err != nil {
+				return nil, fmt.Errorf("failed to get next occurrence of '%s': %w", rt.Description, err)
+			}
+			if tx != nil {
+				next = &models.NextIncome{
+					Recurring:   rt,
+					Date:        date,
+					Description: tx.Description,
+					AmountUSD:   tx.AmountUSD,
+				}
+				break
+			}
+			planned.NextDueDate = planned.CalculateNextDueDate(date)
+		}
+	}
+
+	return next, nil
+}
+
+// GetPendingExpenses totals, in USD, the occurrences of active recurring
+// expenses in the given categories that fall due between start and end but
+// have not been generated yet. Skipped occurrences are left out and
+// modified ones count at their new amount.
+func (s *RecurringTransactionService) GetPendingExpenses(ctx context.Context, categoryIDs []uint, start, end time.Time) (float64, error) {
+	active, err := s.repo.GetActive(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get recurring transactions: %w", err)
+	}
+
+	inCategories := make(map[uint]bool, len(categoryIDs))
+	for _, id := range categoryIDs {
+		inCategories[id] = true
+	}
+
+	total := 0.0
+	for _, rt := range active {
+		if rt.Type != models.TransactionTypeExpense || !inCategories[rt.CategoryID] {
+			continue
+		}
+
+		// Work on a copy so the schedule is not advanced
+		planned := *rt
+		for i := 0; i < maxOccurrenceLookahead; i++ {
+			date := planned.NextDueDate
+			if date.After(end) || (planned.EndDate != nil && date.After(*planned.EndDate)) {
+				break
+			}
+			if !date.Before(start) {
+				tx, err := s.buildOccurrence(ctx, &planned, date)
+				if err != nil {
+					return 0, fmt.Errorf("failed to project '%s': %w", rt.Description, err)
+				}
+				if tx != nil {
+					total += tx.AmountUSD
+				}
+			}
+			planned.NextDueDate = planned.CalculateNextDueDate(date)
+		}
+	}
+
+	return total, nil
 }
 
 // GetUpcoming retrieves upcoming occurrences for the next n days
-func (s *RecurringTransactionService) GetUpcoming(days int) ([]*models.RecurringTransaction, error) {
+func (s *RecurringTransactionService) GetUpcoming(ctx context.Context, days int) ([]*models.RecurringTransaction, error) {
 	endDate := time.Now().AddDate(0, 0, days)
 	
-	active, err := s.repo.GetActive()
+	active, err := s.repo.GetActive(ctx)
 	if err != nil {
 		return nil, err
 	}
@@ -263,16 +777,121 @@ func (s *RecurringTransactionService) GetUpcoming(days int) ([]*models.Recurring
 	return upcoming, nil
 }
 
-// GetExpiring retrieves recurring transactions expiring soon
-func (s *RecurringTransactionService) GetExpiring(days int) ([]*models.RecurringTransaction, error) {
-	start := time.Now()
-	end := start.AddDate(0, 0, days)
-	return s.repo.GetExpiring(start, end)
+// EndingSoonDays is how many days ahead the dashboard warns about active
+// recurring transactions reaching their end date
+const EndingSoonDays = 14
+
+// GetExpiring retrieves recurring transactions expiring from today to days
+// ahead, including those ending earlier today
+func (s *RecurringTransactionService) GetExpiring(ctx context.Context, days int) ([]*models.RecurringTransaction, error) {
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+	end := start.AddDate(0, 0, days+1).Add(-time.Second)
+	return s.repo.GetExpiring(ctx, start, end)
+}
+
+// SetEndDate moves the end date of a recurring transaction, e.g. to extend
+// one that is about to end, or removes it when endDate is nil so the
+// transaction keeps going. A new end date must be after today and the
+// start date.
+func (s *RecurringTransactionService) SetEndDate(ctx context.Context, id uint, endDate *time.Time, now time.Time) error {
+	rt, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return fmt.Errorf("recurring transaction not found: %w", err)
+	}
+
+	if endDate != nil {
+		today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+		if !endDate.After(today) {
+			return fmt.Errorf("validation failed: the end date must be after today")
+		}
+		if !endDate.After(rt.StartDate) {
+			return fmt.Errorf("validation failed: end date must be after start date")
+		}
+	}
+
+	return s.repo.UpdateEndDate(ctx, id, endDate)
+}
+
+// GetMonthlyCommittedByCategory returns what the active recurring expenses
+// of each category cost per month in USD, by category ID. Ones that have
+// ended are left out, as are ones in a currency with no rate known without
+// asking the exchange rate API.
+func (s *RecurringTransactionService) GetMonthlyCommittedByCategory(ctx context.Context) (map[uint]float64, error) {
+	items, err := s.repo.GetActive(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get active recurring transactions: %w", err)
+	}
+
+	rates, err := s.repo.GetLatestGeneratedRates(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recurring transaction rates: %w", err)
+	}
+
+	now := time.Now()
+	committed := make(map[uint]float64)
+	for _, rt := range items {
+		if rt.Type != models.TransactionTypeExpense || rt.ShouldDeactivate(now) {
+			continue
+		}
+		if amount, ok := s.knownMonthlyAmountUSD(rt, rates); ok {
+			committed[rt.CategoryID] += amount
+		}
+	}
+	return committed, nil
+}
+
+// knownMonthlyAmountUSD converts the monthly amount of the recurring
+// transaction at the rate of the last transaction it generated, or at a
+// rate the currency service already knows, so that budget statuses never
+// wait for the exchange rate API. It fails without either.
+func (s *RecurringTransactionService) knownMonthlyAmountUSD(rt *models.RecurringTransaction, rates map[uint]float64) (float64, bool) {
+	amount := rt.MonthlyAmount()
+	if rt.Currency == "USD" {
+		return amount, true
+	}
+	if rate, ok := rates[rt.ID]; ok {
+		return amount * rate, true
+	}
+	if rate, ok := s.currencyService.KnownRate(rt.Currency); ok && rate > 0 {
+		return amount / rate, true
+	}
+	return 0, false
+}
+
+// GetGeneratedSince returns the transactions generated from recurring
+// transactions since the given time, such as by the catch-up on startup
+func (s *RecurringTransactionService) GetGeneratedSince(ctx context.Context, since time.Time) ([]*models.Transaction, error) {
+	return s.repo.GetGeneratedSince(ctx, since)
+}
+
+// MonthlyAmountUSD is what the recurring transaction costs or pays per month
+// in USD, whether or not it is paused
+func (s *RecurringTransactionService) MonthlyAmountUSD(ctx context.Context, rt *models.RecurringTransaction) float64 {
+	return monthlyAmountUSD(ctx, s.currencyService, rt)
+}
+
+// GetMonthlyAmountsUSD returns MonthlyAmountUSD for each of the recurring
+// transactions by ID, converting each one once so views can show them
+// without converting again on every render
+func (s *RecurringTransactionService) GetMonthlyAmountsUSD(ctx context.Context, items []*models.RecurringTransaction) map[uint]float64 {
+	amounts := make(map[uint]float64, len(items))
+	for _, rt := range items {
+		amounts[rt.ID] = s.MonthlyAmountUSD(ctx, rt)
+	}
+	return amounts
+}
+
+// GetMonthlyTotals adds up the monthly amounts of the recurring
+// transactions by type. Paused transactions are left out, as they are of
+// every projection.
+func (s *RecurringTransactionService) GetMonthlyTotals(ctx context.Context, items []*models.RecurringTransaction) *models.RecurringMonthlyTotals {
+	return models.SumMonthlyAmounts(items, s.GetMonthlyAmountsUSD(ctx, items))
 }
 
 // CalculateProjectedAmount calculates the projected amount for a period
-func (s *RecurringTransactionService) CalculateProjectedAmount(startDate, endDate time.Time) (float64, error) {
-	active, err := s.repo.GetActive()
+func (s *RecurringTransactionService) CalculateProjectedAmount(ctx context.Context, startDate, endDate time.Time) (float64, error) {
+	active, err := s.repo.GetActive(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -284,8 +903,10 @@ func (s *RecurringTransactionService) CalculateProjectedAmount(startDate, endDat
 			continue
 		}
 
-		// Calculate occurrences in the period
+		// Add up the occurrences in the period, each with the annual
+		// increases due by its date
 		occurrences := 0
+		amount := 0.0
 		currentDate := rt.NextDueDate
 		
 		// If next due date is before start, advance to start
@@ -297,18 +918,18 @@ func (s *RecurringTransactionService) CalculateProjectedAmount(startDate, endDat
 		for !currentDate.After(endDate) {
 			if rt.EndDate == nil || !currentDate.After(*rt.EndDate) {
 				occurrences++
+				amount += rt.AmountOn(currentDate)
 			}
 			currentDate = rt.CalculateNextDueDate(currentDate)
 		}
 
 		if occurrences > 0 {
 			// Convert to USD for aggregation
-			amountUSD, err := s.currencyService.ConvertToUSD(rt.Amount, rt.Currency)
+			projectedAmount, err := s.currencyService.ConvertToUSD(ctx, amount, rt.Currency)
 			if err != nil {
 				return 0, fmt.Errorf("failed to convert currency: %w", err)
 			}
 
-			projectedAmount := amountUSD * float64(occurrences)
 			if rt.Type == models.TransactionTypeIncome {
 				totalUSD += projectedAmount
 			} else {
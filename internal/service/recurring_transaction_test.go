@@ -40,12 +40,277 @@ func TestRecurringTransactionService_Create(t *testing.T) {
 		IsActive:       true,
 	}
 
-	err = service.Create(rt)
+	err = service.Create(t.Context(), rt)
 	require.NoError(t, err)
 	assert.NotZero(t, rt.ID)
 	assert.Equal(t, rt.StartDate, rt.NextDueDate)
 }
 
+func TestRecurringTransactionService_CreateDuplicate(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewRecurringTransactionService(repository.NewRecurringTransactionRepository(db), repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	
+	category := test.CreateTestCategory(t, db, "Cloud Services", models.TransactionTypeExpense)
+	newRule := func(description string, amount float64) *models.RecurringTransaction {
+		return &models.RecurringTransaction{
+			Type:           models.TransactionTypeExpense,
+			Amount:         amount,
+			Currency:       "USD",
+			CategoryID:     category.ID,
+			Description:    description,
+			Frequency:      models.FrequencyMonthly,
+			FrequencyValue: 1,
+			StartDate:      time.Now(),
+			IsActive:       true,
+		}
+	}
+	
+	original := newRule("GitHub Copilot", 10)
+	require.NoError(t, service.Create(t.Context(), original))
+	
+	// Case and spacing don't make a rule different
+	err = service.Create(t.Context(), newRule("  github   copilot ", 10))
+	var duplicate *DuplicateRecurringError
+	require.ErrorAs(t, err, &duplicate)
+	assert.Equal(t, original.ID, duplicate.Existing.ID)
+	assert.Contains(t, err.Error(), "GitHub Copilot")
+	
+	// A different amount is not a duplicate
+	require.NoError(t, service.Create(t.Context(), newRule("GitHub Copilot", 19)))
+	
+	// Confirmed duplicates are saved
+	confirmed := newRule("GitHub Copilot", 10)
+	require.NoError(t, service.CreateDuplicate(t.Context(), confirmed))
+	assert.NotZero(t, confirmed.ID)
+	
+	// Paused rules are not checked
+	require.NoError(t, service.Pause(t.Context(), original.ID))
+	require.NoError(t, service.Pause(t.Context(), confirmed.ID))
+	require.NoError(t, service.Create(t.Context(), newRule("GitHub Copilot", 10)))
+}
+
+func TestRecurringTransactionService_CreateValidation(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewRecurringTransactionService(repository.NewRecurringTransactionRepository(db), repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	
+	category := test.CreateTestCategory(t, db, "Gym", models.TransactionTypeExpense)
+	
+	tests := []struct {
+		name           string
+		description    string
+		frequency      models.RecurrenceFrequency
+		frequencyValue int
+		wantErr        string
+	}{
+		{"whitespace description", "   ", models.FrequencyMonthly, 1, "only whitespace"},
+		{"daily at cap", "Daily", models.FrequencyDaily, 365, ""},
+		{"daily above cap", "Daily", models.FrequencyDaily, 366, "at most 365"},
+		{"weekly above cap", "Weekly", models.FrequencyWeekly, 53, "at most 52"},
+		{"monthly above cap", "Monthly", models.FrequencyMonthly, 13, "at most 12"},
+		{"yearly above cap", "Yearly", models.FrequencyYearly, 11, "at most 10"},
+	}
+	
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := service.Create(t.Context(), &models.RecurringTransaction{
+				Type:           models.TransactionTypeExpense,
+				Amount:         30,
+				Currency:       "USD",
+				CategoryID:     category.ID,
+				Description:    tt.description,
+				Frequency:      tt.frequency,
+				FrequencyValue: tt.frequencyValue,
+				StartDate:      time.Now(),
+				IsActive:       true,
+			})
+			if tt.wantErr == "" {
+				assert.NoError(t, err)
+				return
+			}
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.wantErr)
+		})
+	}
+}
+
+func TestRecurringTransactionService_UpdateFrequencyDoesNotBackfill(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewRecurringTransactionRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewRecurringTransactionService(repo, txRepo, NewCurrencyService(settingsService))
+
+	category := test.CreateTestCategory(t, db, "Gym", models.TransactionTypeExpense)
+
+	// An old monthly rule that has never been processed
+	start := time.Now().AddDate(0, -5, 0)
+	rt := &models.RecurringTransaction{
+		Type:           models.TransactionTypeExpense,
+		Amount:         40.00,
+		Currency:       "USD",
+		CategoryID:     category.ID,
+		Description:    "Membership",
+		Frequency:      models.FrequencyMonthly,
+		FrequencyValue: 1,
+		StartDate:      start,
+		IsActive:       true,
+	}
+	require.NoError(t, service.Create(t.Context(), rt))
+
+	rt.Frequency = models.FrequencyWeekly
+	require.NoError(t, service.Update(t.Context(), rt))
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	updated, err := repo.GetByID(t.Context(), rt.ID)
+	require.NoError(t, err)
+	assert.False(t, updated.NextDueDate.Before(today), "next due date %s is before today", updated.NextDueDate)
+	assert.True(t, updated.NextDueDate.Before(today.AddDate(0, 0, 7)))
+
+	// At most today's occurrence is generated, not months of weekly ones
+	processed, err := service.ProcessDueTransactions(t.Context(), now)
+	require.NoError(t, err)
+	assert.LessOrEqual(t, processed, 1)
+}
+
+func TestRecurringTransactionService_GetPriceHistory(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewRecurringTransactionService(repository.NewRecurringTransactionRepository(db), repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	
+	category := test.CreateTestCategory(t, db, "Streaming", models.TransactionTypeExpense)
+	rt := &models.RecurringTransaction{
+		Type:           models.TransactionTypeExpense,
+		Amount:         9.99,
+		Currency:       "USD",
+		CategoryID:     category.ID,
+		Description:    "Netflix",
+		Frequency:      models.FrequencyMonthly,
+		FrequencyValue: 1,
+		StartDate:      time.Now(),
+		IsActive:       true,
+	}
+	require.NoError(t, service.Create(t.Context(), rt))
+	
+	history, err := service.GetPriceHistory(t.Context(), rt.ID)
+	require.NoError(t, err)
+	assert.Empty(t, history)
+	
+	rt.Amount = 12.99
+	require.NoError(t, service.Update(t.Context(), rt))
+	
+	// Edits that keep the amount are not price changes
+	rt.Description = "Netflix Standard"
+	require.NoError(t, service.Update(t.Context(), rt))
+	
+	rt.Amount = 15.49
+	require.NoError(t, service.Update(t.Context(), rt))
+	
+	// Neither is switching currency
+	rt.Currency = "EUR"
+	rt.Amount = 14.99
+	require.NoError(t, service.Update(t.Context(), rt))
+	
+	history, err = service.GetPriceHistory(t.Context(), rt.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 2)
+	assert.Equal(t, 9.99, history[0].OldAmount)
+	assert.Equal(t, 12.99, history[0].NewAmount)
+	assert.Equal(t, "USD", history[0].Currency)
+	assert.Equal(t, 12.99, history[1].OldAmount)
+	assert.Equal(t, 15.49, history[1].NewAmount)
+	assert.False(t, history[1].ChangedAt.Before(history[0].ChangedAt))
+}
+
+func TestRecurringTransactionService_AnnualIncrease(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewRecurringTransactionService(repository.NewRecurringTransactionRepository(db), repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	
+	// Rent that started 11 months ago, rising 3% a year
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local).AddDate(0, -11, 0)
+	anniversary := start.AddDate(1, 0, 0)
+	category := test.CreateTestCategory(t, db, "Rent", models.TransactionTypeExpense)
+	rt := &models.RecurringTransaction{
+		Type:                  models.TransactionTypeExpense,
+		Amount:                1000,
+		Currency:              "USD",
+		CategoryID:            category.ID,
+		Description:           "Rent",
+		Frequency:             models.FrequencyMonthly,
+		FrequencyValue:        1,
+		StartDate:             start,
+		IsActive:              true,
+		AnnualIncreasePercent: 3,
+	}
+	require.NoError(t, service.Create(t.Context(), rt))
+	
+	// The projection already counts the increase from the anniversary on
+	projected, err := service.CalculateProjectedAmount(t.Context(), anniversary, anniversary.AddDate(0, 1, 1))
+	require.NoError(t, err)
+	assert.Equal(t, -2060.0, projected)
+	
+	processed, err := service.ProcessOne(t.Context(), rt.ID, anniversary.AddDate(0, 1, 1))
+	require.NoError(t, err)
+	require.Equal(t, 14, processed)
+	
+	generated, err := service.GetGeneratedTransactions(t.Context(), rt.ID)
+	require.NoError(t, err)
+	require.Len(t, generated, 14)
+	for _, tx := range generated {
+		want := 1000.0
+		if !tx.Date.Before(anniversary) {
+			want = 1030
+		}
+		assert.Equal(t, want, tx.Amount, tx.Date.Format("2006-01-02"))
+	}
+	
+	// The new amount is kept, with the change dated on the anniversary
+	updated, err := service.GetByID(t.Context(), rt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1030.0, updated.Amount)
+	assert.Equal(t, 1, updated.IncreasesApplied)
+	
+	history, err := service.GetPriceHistory(t.Context(), rt.ID)
+	require.NoError(t, err)
+	require.Len(t, history, 1)
+	assert.Equal(t, 1000.0, history[0].OldAmount)
+	assert.Equal(t, 1030.0, history[0].NewAmount)
+	assert.True(t, anniversary.Equal(history[0].ChangedAt))
+	
+	// Turning the increase on for an old rule doesn't catch up on past years
+	old := &models.RecurringTransaction{
+		Type:           models.TransactionTypeExpense,
+		Amount:         50,
+		Currency:       "USD",
+		CategoryID:     category.ID,
+		Description:    "Storage unit",
+		Frequency:      models.FrequencyMonthly,
+		FrequencyValue: 1,
+		StartDate:      start.AddDate(-3, 0, 0),
+		NextDueDate:    time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local),
+		IsActive:       true,
+	}
+	require.NoError(t, service.CreateDuplicate(t.Context(), old))
+	old.AnnualIncreasePercent = 10
+	require.NoError(t, service.Update(t.Context(), old))
+	assert.Equal(t, 50.0, old.AmountOn(old.NextDueDate))
+	assert.Equal(t, 55.0, old.AmountOn(old.StartDate.AddDate(old.IncreasesApplied+1, 0, 0)))
+	
+	rt.AnnualIncreasePercent = 101
+	assert.ErrorContains(t, service.Update(t.Context(), rt), "annual increase must be between 0 and 100 percent")
+}
+
 func TestRecurringTransactionService_ProcessDueTransactions(t *testing.T) {
 	db := test.SetupTestDB(t)
 	repo := repository.NewRecurringTransactionRepository(db)
@@ -76,16 +341,16 @@ func TestRecurringTransactionService_ProcessDueTransactions(t *testing.T) {
 		IsActive:       true,
 	}
 
-	err = repo.Create(rt)
+	err = repo.Create(t.Context(), rt)
 	require.NoError(t, err)
 
 	// Process due transactions
-	processed, err := service.ProcessDueTransactions(today)
+	processed, err := service.ProcessDueTransactions(t.Context(), today)
 	require.NoError(t, err)
 	assert.Equal(t, 1, processed)
 
 	// Verify transaction was created
-	transactions, err := txRepo.GetAll()
+	transactions, err := txRepo.GetAll(t.Context())
 	require.NoError(t, err)
 	assert.Len(t, transactions, 1)
 
@@ -98,11 +363,252 @@ func TestRecurringTransactionService_ProcessDueTransactions(t *testing.T) {
 	assert.Equal(t, rt.ID, *tx.RecurringTransactionID)
 
 	// Verify next due date was updated
-	updatedRT, err := repo.GetByID(rt.ID)
+	updatedRT, err := repo.GetByID(t.Context(), rt.ID)
 	require.NoError(t, err)
 	assert.True(t, updatedRT.NextDueDate.After(today))
 }
 
+func TestRecurringTransactionService_PreviewDueTransactions(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewRecurringTransactionRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+	
+	tempDir := t.TempDir()
+	settingsService, err := NewSettingsService(tempDir)
+	require.NoError(t, err)
+	currencyService := NewCurrencyService(settingsService)
+	
+	service := NewRecurringTransactionService(repo, txRepo, currencyService)
+	
+	category := test.CreateTestCategory(t, db, "Subscriptions", models.TransactionTypeExpense)
+
+	// Three monthly occurrences have been missed
+	start := time.Now().AddDate(0, -2, 0)
+	rt := &models.RecurringTransaction{
+		Type:           models.TransactionTypeExpense,
+		Amount:         10.00,
+		Currency:       "USD",
+		CategoryID:     category.ID,
+		Description:    "Streaming",
+		Frequency:      models.FrequencyMonthly,
+		FrequencyValue: 1,
+		StartDate:      start,
+		NextDueDate:    start,
+		IsActive:       true,
+	}
+	require.NoError(t, repo.Create(t.Context(), rt))
+
+	// Skipped occurrences are not previewed
+	require.NoError(t, service.SkipOccurrence(t.Context(), rt.ID, start.AddDate(0, 1, 0), "paused"))
+
+	preview, err := service.PreviewDueTransactions(t.Context(), time.Now())
+	require.NoError(t, err)
+	assert.Len(t, preview, 2)
+	assert.Equal(t, "Subscriptions", preview[0].Category.Name)
+
+	// Nothing was written and the schedule did not move
+	transactions, err := txRepo.GetAll(t.Context())
+	require.NoError(t, err)
+	assert.Empty(t, transactions)
+
+	unchanged, err := repo.GetByID(t.Context(), rt.ID)
+	require.NoError(t, err)
+	assert.True(t, unchanged.NextDueDate.Equal(start))
+}
+
+func TestRecurringTransactionService_ProcessOneAndSkipPast(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewRecurringTransactionRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewRecurringTransactionService(repo, txRepo, NewCurrencyService(settingsService))
+
+	category := test.CreateTestCategory(t, db, "Software", models.TransactionTypeExpense)
+
+	now := time.Now()
+	newRule := func(description string) *models.RecurringTransaction {
+		rt := &models.RecurringTransaction{
+			Type:           models.TransactionTypeExpense,
+			Amount:         20.00,
+			Currency:       "USD",
+			CategoryID:     category.ID,
+			Description:    description,
+			Frequency:      models.FrequencyMonthly,
+			FrequencyValue: 1,
+			StartDate:      now.AddDate(0, -2, 0),
+			IsActive:       true,
+		}
+		require.NoError(t, service.Create(t.Context(), rt))
+		return rt
+	}
+
+	generated := newRule("Editor licence")
+	skipped := newRule("Hosting")
+
+	count, err := service.CountDueOccurrences(t.Context(), generated.ID, now)
+	require.NoError(t, err)
+	assert.Equal(t, 3, count)
+
+	// Only the requested rule is processed
+	processed, err := service.ProcessOne(t.Context(), generated.ID, now)
+	require.NoError(t, err)
+	assert.Equal(t, 3, processed)
+
+	transactions, err := txRepo.GetAll(t.Context())
+	require.NoError(t, err)
+	require.Len(t, transactions, 3)
+	for _, tx := range transactions {
+		assert.Equal(t, "Editor licence", tx.Description)
+	}
+
+	// Skipping moves the schedule past now without generating anything
+	require.NoError(t, service.SkipPastOccurrences(t.Context(), skipped.ID, now))
+
+	updated, err := repo.GetByID(t.Context(), skipped.ID)
+	require.NoError(t, err)
+	assert.True(t, updated.NextDueDate.After(now))
+
+	processed, err = service.ProcessDueTransactions(t.Context(), now)
+	require.NoError(t, err)
+	assert.Zero(t, processed)
+
+	transactions, err = txRepo.GetAll(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, transactions, 3)
+}
+
+func TestRecurringTransactionService_GetTotalGenerated(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewRecurringTransactionRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewRecurringTransactionService(repo, txRepo, NewCurrencyService(settingsService))
+
+	category := test.CreateTestCategory(t, db, "Subscriptions", models.TransactionTypeExpense)
+
+	start := time.Now().AddDate(0, -3, 0)
+	rt := &models.RecurringTransaction{
+		Type:           models.TransactionTypeExpense,
+		Amount:         15.00,
+		Currency:       "USD",
+		CategoryID:     category.ID,
+		Description:    "Music streaming",
+		Frequency:      models.FrequencyMonthly,
+		FrequencyValue: 1,
+		StartDate:      start,
+		NextDueDate:    start,
+		IsActive:       true,
+	}
+	require.NoError(t, repo.Create(t.Context(), rt))
+
+	total, count, err := service.GetTotalGenerated(t.Context(), rt.ID)
+	require.NoError(t, err)
+	assert.Zero(t, total)
+	assert.Zero(t, count)
+
+	// A modified occurrence counts at its actual amount
+	modified := 20.00
+	require.NoError(t, service.ModifyOccurrence(t.Context(), rt.ID, start.AddDate(0, 1, 0), &modified, nil))
+
+	processed, err := service.ProcessDueTransactions(t.Context(), time.Now())
+	require.NoError(t, err)
+	assert.Equal(t, 4, processed)
+
+	total, count, err = service.GetTotalGenerated(t.Context(), rt.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 4, count)
+	assert.InDelta(t, 65.00, total, 0.001)
+
+	// Counted for every recurring transaction at once, leaving out the
+	// ones that haven't generated anything and manual transactions
+	idle := &models.RecurringTransaction{
+		Type:           models.TransactionTypeExpense,
+		Amount:         9.00,
+		Currency:       "USD",
+		CategoryID:     category.ID,
+		Description:    "Video streaming",
+		Frequency:      models.FrequencyMonthly,
+		FrequencyValue: 1,
+		StartDate:      time.Now().AddDate(0, 1, 0),
+		NextDueDate:    time.Now().AddDate(0, 1, 0),
+		IsActive:       true,
+	}
+	require.NoError(t, repo.Create(t.Context(), idle))
+	test.CreateTestTransaction(t, db, 12.00, category.ID)
+
+	counts, err := service.GetGeneratedCounts(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, map[uint]int64{rt.ID: 4}, counts)
+}
+
+func TestRecurringTransactionService_GetNextIncome(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewRecurringTransactionRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewRecurringTransactionService(repo, txRepo, NewCurrencyService(settingsService))
+
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	rent := test.CreateTestCategory(t, db, "Rent", models.TransactionTypeExpense)
+
+	// Nothing scheduled yet
+	next, err := service.GetNextIncome(t.Context())
+	require.NoError(t, err)
+	assert.Nil(t, next)
+
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	create := func(txType models.TransactionType, categoryID uint, description string, amount float64, dueIn int) *models.RecurringTransaction {
+		rt := &models.RecurringTransaction{
+			Type:           txType,
+			Amount:         amount,
+			Currency:       "USD",
+			CategoryID:     categoryID,
+			Description:    description,
+			Frequency:      models.FrequencyMonthly,
+			FrequencyValue: 1,
+			StartDate:      today,
+			NextDueDate:    today.AddDate(0, 0, dueIn),
+			IsActive:       true,
+		}
+		require.NoError(t, repo.Create(t.Context(), rt))
+		return rt
+	}
+
+	// Expenses are ignored even when they come first
+	create(models.TransactionTypeExpense, rent.ID, "Rent", 1500, 1)
+	paycheck := create(models.TransactionTypeIncome, salary.ID, "Salary", 5000, 9)
+	create(models.TransactionTypeIncome, salary.ID, "Dividends", 200, 20)
+
+	next, err = service.GetNextIncome(t.Context())
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.Equal(t, "Salary", next.Description)
+	assert.Equal(t, 5000.0, next.AmountUSD)
+	assert.WithinDuration(t, paycheck.NextDueDate, next.Date, 0)
+
+	// A skipped paycheck moves the next income to the following occurrence,
+	// after the dividends
+	require.NoError(t, service.SkipOccurrence(t.Context(), paycheck.ID, paycheck.NextDueDate, "unpaid leave"))
+
+	next, err = service.GetNextIncome(t.Context())
+	require.NoError(t, err)
+	require.NotNil(t, next)
+	assert.Equal(t, "Dividends", next.Description)
+	assert.WithinDuration(t, today.AddDate(0, 0, 20), next.Date, 0)
+
+	// The schedule itself is not advanced
+	stored, err := repo.GetByID(t.Context(), paycheck.ID)
+	require.NoError(t, err)
+	assert.WithinDuration(t, paycheck.NextDueDate, stored.NextDueDate, 0)
+}
+
 func TestRecurringTransactionService_SkipOccurrence(t *testing.T) {
 	db := test.SetupTestDB(t)
 	repo := repository.NewRecurringTransactionRepository(db)
@@ -133,20 +639,20 @@ func TestRecurringTransactionService_SkipOccurrence(t *testing.T) {
 		IsActive:       true,
 	}
 
-	err = repo.Create(rt)
+	err = repo.Create(t.Context(), rt)
 	require.NoError(t, err)
 
 	// Skip today's occurrence
-	err = service.SkipOccurrence(rt.ID, today, "Cancelled this month")
+	err = service.SkipOccurrence(t.Context(), rt.ID, today, "Cancelled this month")
 	require.NoError(t, err)
 
 	// Process due transactions
-	processed, err := service.ProcessDueTransactions(today)
+	processed, err := service.ProcessDueTransactions(t.Context(), today)
 	require.NoError(t, err)
 	assert.Equal(t, 1, processed) // Processed but skipped
 
 	// Verify no transaction was created (because it was skipped)
-	transactions, err := txRepo.GetAll()
+	transactions, err := txRepo.GetAll(t.Context())
 	require.NoError(t, err)
 	assert.Len(t, transactions, 0)
 }
@@ -181,22 +687,22 @@ func TestRecurringTransactionService_ModifyOccurrence(t *testing.T) {
 		IsActive:       true,
 	}
 
-	err = repo.Create(rt)
+	err = repo.Create(t.Context(), rt)
 	require.NoError(t, err)
 
 	// Modify today's occurrence
 	modifiedAmount := 5500.00
 	modifiedDesc := "Monthly salary + bonus"
-	err = service.ModifyOccurrence(rt.ID, today, &modifiedAmount, &modifiedDesc)
+	err = service.ModifyOccurrence(t.Context(), rt.ID, today, &modifiedAmount, &modifiedDesc)
 	require.NoError(t, err)
 
 	// Process due transactions
-	processed, err := service.ProcessDueTransactions(today)
+	processed, err := service.ProcessDueTransactions(t.Context(), today)
 	require.NoError(t, err)
 	assert.Equal(t, 1, processed)
 
 	// Verify modified transaction was created
-	transactions, err := txRepo.GetAll()
+	transactions, err := txRepo.GetAll(t.Context())
 	require.NoError(t, err)
 	require.Len(t, transactions, 1)
 
@@ -237,29 +743,110 @@ func TestRecurringTransactionService_PauseResume(t *testing.T) {
 	// Verify the model has the correct amount before creating
 	assert.Equal(t, 200.00, rt.Amount)
 	
-	err = service.Create(rt)
+	err = service.Create(t.Context(), rt)
 	require.NoError(t, err, "Failed to create recurring transaction")
 	assert.True(t, rt.IsActive)
 
 	// Pause
-	err = service.Pause(rt.ID)
+	err = service.Pause(t.Context(), rt.ID)
 	require.NoError(t, err)
 
 	// Verify paused
-	paused, err := repo.GetByID(rt.ID)
+	paused, err := repo.GetByID(t.Context(), rt.ID)
 	require.NoError(t, err)
 	assert.False(t, paused.IsActive)
 
 	// Resume
-	err = service.Resume(rt.ID)
+	err = service.Resume(t.Context(), rt.ID)
 	require.NoError(t, err)
 
 	// Verify resumed
-	resumed, err := repo.GetByID(rt.ID)
+	resumed, err := repo.GetByID(t.Context(), rt.ID)
 	require.NoError(t, err)
 	assert.True(t, resumed.IsActive)
 }
 
+func TestRecurringTransactionService_BulkDeleteAndPause(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewRecurringTransactionRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewRecurringTransactionService(repo, txRepo, NewCurrencyService(settingsService))
+	undoService := NewUndoService(DefaultUndoLimit)
+	service.SetUndoService(undoService)
+
+	category := test.CreateTestCategory(t, db, "Subscriptions", models.TransactionTypeExpense)
+	create := func(description string, start time.Time) *models.RecurringTransaction {
+		rt := &models.RecurringTransaction{
+			Type:           models.TransactionTypeExpense,
+			Amount:         10.00,
+			Currency:       "USD",
+			CategoryID:     category.ID,
+			Description:    description,
+			Frequency:      models.FrequencyMonthly,
+			FrequencyValue: 1,
+			StartDate:      start,
+			NextDueDate:    start,
+			IsActive:       true,
+		}
+		require.NoError(t, repo.Create(t.Context(), rt))
+		return rt
+	}
+
+	// One trial has been charged, the other two never were
+	charged := create("Charged trial", time.Now().AddDate(0, -1, 0))
+	_, err = service.ProcessDueTransactions(t.Context(), time.Now())
+	require.NoError(t, err)
+	trialA := create("Trial A", time.Now().AddDate(0, 1, 0))
+	trialB := create("Trial B", time.Now().AddDate(0, 1, 0))
+	ids := []uint{charged.ID, trialA.ID, trialB.ID}
+
+	// Pausing leaves the ones paused already as they are
+	require.NoError(t, service.Pause(t.Context(), trialB.ID))
+	results := service.PauseMany(t.Context(), ids)
+	assert.Equal(t, map[BulkOutcome]int{BulkPaused: 2, BulkUnchanged: 1}, CountOutcomes(results))
+	require.NoError(t, service.Resume(t.Context(), charged.ID))
+
+	// The plan says what deleting will do, without doing it
+	plan, err := service.PlanDeleteMany(t.Context(), ids)
+	require.NoError(t, err)
+	assert.Equal(t, []BulkResult{
+		{ID: charged.ID, Description: "Charged trial", Outcome: BulkDeactivated},
+		{ID: trialA.ID, Description: "Trial A", Outcome: BulkDeleted},
+		{ID: trialB.ID, Description: "Trial B", Outcome: BulkDeleted},
+	}, plan)
+	_, err = repo.GetByID(t.Context(), trialA.ID)
+	require.NoError(t, err)
+
+	results = service.DeleteMany(t.Context(), append(ids, 999))
+	require.Len(t, results, 4)
+	for i, result := range plan {
+		assert.Equal(t, result, results[i])
+	}
+	assert.Equal(t, BulkFailed, results[3].Outcome)
+	assert.Error(t, results[3].Err)
+
+	deactivated, err := repo.GetByID(t.Context(), charged.ID)
+	require.NoError(t, err)
+	assert.False(t, deactivated.IsActive)
+	_, err = repo.GetByID(t.Context(), trialA.ID)
+	assert.Error(t, err)
+
+	// One undo brings them all back
+	_, err = undoService.Undo(t.Context())
+	require.NoError(t, err)
+	assert.Nil(t, undoService.Peek())
+	restored, err := repo.GetByID(t.Context(), charged.ID)
+	require.NoError(t, err)
+	assert.True(t, restored.IsActive)
+	for _, id := range []uint{trialA.ID, trialB.ID} {
+		_, err = repo.GetByID(t.Context(), id)
+		assert.NoError(t, err)
+	}
+}
+
 func TestRecurringTransactionService_EndDateHandling(t *testing.T) {
 	db := test.SetupTestDB(t)
 	repo := repository.NewRecurringTransactionRepository(db)
@@ -292,20 +879,113 @@ func TestRecurringTransactionService_EndDateHandling(t *testing.T) {
 		IsActive:       true,
 	}
 
-	err = repo.Create(rt)
+	err = repo.Create(t.Context(), rt)
 	require.NoError(t, err)
 
 	// Process due transactions
-	processed, err := service.ProcessDueTransactions(today)
+	processed, err := service.ProcessDueTransactions(t.Context(), today)
 	require.NoError(t, err)
 	assert.Equal(t, 0, processed) // Should not process as it's past end date
 
 	// Verify no transaction was created
-	transactions, err := txRepo.GetAll()
+	transactions, err := txRepo.GetAll(t.Context())
 	require.NoError(t, err)
 	assert.Len(t, transactions, 0)
 }
 
+func TestRecurringTransactionService_SetEndDate(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewRecurringTransactionRepository(db)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewRecurringTransactionService(repo, repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+
+	category := test.CreateTestCategory(t, db, "Fitness", models.TransactionTypeExpense)
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	endsSoon := today.AddDate(0, 0, 5)
+	gym := &models.RecurringTransaction{
+		Type:           models.TransactionTypeExpense,
+		Amount:         45,
+		Currency:       "USD",
+		CategoryID:     category.ID,
+		Description:    "Gym membership",
+		Frequency:      models.FrequencyMonthly,
+		FrequencyValue: 1,
+		StartDate:      today.AddDate(-1, 0, 0),
+		EndDate:        &endsSoon,
+		NextDueDate:    today.AddDate(0, 0, 3),
+		IsActive:       true,
+	}
+	require.NoError(t, repo.Create(t.Context(), gym))
+
+	expiring, err := service.GetExpiring(t.Context(), EndingSoonDays)
+	require.NoError(t, err)
+	require.Len(t, expiring, 1)
+	assert.Equal(t, 5, expiring[0].DaysUntilEnd(now))
+
+	// Extending it moves it out of the warning window
+	extended := endsSoon.AddDate(1, 0, 0)
+	require.NoError(t, service.SetEndDate(t.Context(), gym.ID, &extended, now))
+	found, err := service.GetByID(t.Context(), gym.ID)
+	require.NoError(t, err)
+	require.NotNil(t, found.EndDate)
+	assert.True(t, found.EndDate.Equal(extended))
+	expiring, err = service.GetExpiring(t.Context(), EndingSoonDays)
+	require.NoError(t, err)
+	assert.Empty(t, expiring)
+
+	// ...and continuing removes the end date altogether
+	require.NoError(t, service.SetEndDate(t.Context(), gym.ID, nil, now))
+	found, err = service.GetByID(t.Context(), gym.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found.EndDate)
+
+	assert.ErrorContains(t, service.SetEndDate(t.Context(), gym.ID, &today, now), "after today")
+	longAgo := today.AddDate(-2, 0, 0)
+	assert.ErrorContains(t, service.SetEndDate(t.Context(), gym.ID, &longAgo, longAgo.AddDate(0, 0, -1)), "after start date")
+	assert.ErrorContains(t, service.SetEndDate(t.Context(), 999, nil, now), "not found")
+}
+
+func TestRecurringTransactionService_GetExpiringIncludesToday(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewRecurringTransactionRepository(db)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewRecurringTransactionService(repo, repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+
+	category := test.CreateTestCategory(t, db, "Fitness", models.TransactionTypeExpense)
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	ends := map[string]time.Time{
+		"Gym":       today,                               // earlier today
+		"Streaming": today.AddDate(0, 0, EndingSoonDays), // the last day warned about
+		"Magazine":  today.AddDate(0, 0, EndingSoonDays+1),
+	}
+	for description, end := range ends {
+		require.NoError(t, repo.Create(t.Context(), &models.RecurringTransaction{
+			Type:           models.TransactionTypeExpense,
+			Amount:         45,
+			Currency:       "USD",
+			CategoryID:     category.ID,
+			Description:    description,
+			Frequency:      models.FrequencyMonthly,
+			FrequencyValue: 1,
+			StartDate:      today.AddDate(-1, 0, 0),
+			EndDate:        &end,
+			NextDueDate:    today.AddDate(0, 1, 0),
+			IsActive:       true,
+		}))
+	}
+
+	expiring, err := service.GetExpiring(t.Context(), EndingSoonDays)
+	require.NoError(t, err)
+	require.Len(t, expiring, 2)
+	assert.Equal(t, "Gym", expiring[0].Description)
+	assert.Equal(t, 0, expiring[0].DaysUntilEnd(now))
+	assert.Equal(t, "Streaming", expiring[1].Description)
+}
+
 func TestRecurringTransactionService_GetUpcoming(t *testing.T) {
 	db := test.SetupTestDB(t)
 	repo := repository.NewRecurringTransactionRepository(db)
@@ -337,7 +1017,7 @@ func TestRecurringTransactionService_GetUpcoming(t *testing.T) {
 		NextDueDate:    today.AddDate(0, 0, 5),
 		IsActive:       true,
 	}
-	err = repo.Create(rt1)
+	err = repo.Create(t.Context(), rt1)
 	require.NoError(t, err)
 
 	// Due in 10 days
@@ -353,7 +1033,7 @@ func TestRecurringTransactionService_GetUpcoming(t *testing.T) {
 		NextDueDate:    today.AddDate(0, 0, 10),
 		IsActive:       true,
 	}
-	err = repo.Create(rt2)
+	err = repo.Create(t.Context(), rt2)
 	require.NoError(t, err)
 
 	// Due in 20 days (outside range)
@@ -369,11 +1049,11 @@ func TestRecurringTransactionService_GetUpcoming(t *testing.T) {
 		NextDueDate:    today.AddDate(0, 0, 20),
 		IsActive:       true,
 	}
-	err = repo.Create(rt3)
+	err = repo.Create(t.Context(), rt3)
 	require.NoError(t, err)
 
 	// Get upcoming in next 14 days
-	upcoming, err := service.GetUpcoming(14)
+	upcoming, err := service.GetUpcoming(t.Context(), 14)
 	require.NoError(t, err)
 	assert.Len(t, upcoming, 2)
 }
@@ -407,7 +1087,7 @@ func TestRecurringTransactionService_CalculateProjectedAmount(t *testing.T) {
 		NextDueDate:    time.Now(),
 		IsActive:       true,
 	}
-	err = repo.Create(income)
+	err = repo.Create(t.Context(), income)
 	require.NoError(t, err)
 
 	// Create monthly expense
@@ -423,16 +1103,76 @@ func TestRecurringTransactionService_CalculateProjectedAmount(t *testing.T) {
 		NextDueDate:    time.Now(),
 		IsActive:       true,
 	}
-	err = repo.Create(expense)
+	err = repo.Create(t.Context(), expense)
 	require.NoError(t, err)
 
 	// Calculate projection for next 3 months
 	startDate := time.Now()
 	endDate := startDate.AddDate(0, 3, 0)
 
-	projected, err := service.CalculateProjectedAmount(startDate, endDate)
+	projected, err := service.CalculateProjectedAmount(t.Context(), startDate, endDate)
 	require.NoError(t, err)
 
 	// Should be (5000 - 1500) * 3 = 10500
 	assert.Equal(t, 10500.0, projected)
-}
\ No newline at end of file
+}
+func TestRecurringTransactionService_GetMonthlyTotalsSkipsPaused(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewRecurringTransactionRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+	
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	currencyService := NewCurrencyService(settingsService)
+	service := NewRecurringTransactionService(repo, txRepo, currencyService)
+	txService := NewTransactionService(txRepo, currencyService)
+	txService.SetRecurringRepo(repo)
+	
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	bills := test.CreateTestCategory(t, db, "Bills", models.TransactionTypeExpense)
+	create := func(txType models.TransactionType, categoryID uint, amount float64, frequency models.RecurrenceFrequency, paused bool) *models.RecurringTransaction {
+		rt := &models.RecurringTransaction{
+			Type:           txType,
+			Amount:         amount,
+			Currency:       "USD",
+			CategoryID:     categoryID,
+			Description:    string(frequency),
+			Frequency:      frequency,
+			FrequencyValue: 1,
+			StartDate:      time.Now().AddDate(0, -1, 0),
+			NextDueDate:    time.Now().AddDate(0, 0, 7),
+			IsActive:       true,
+		}
+		require.NoError(t, repo.Create(t.Context(), rt))
+		if paused {
+			require.NoError(t, service.Pause(t.Context(), rt.ID))
+			rt.IsActive = false
+		}
+		return rt
+	}
+	
+	items := []*models.RecurringTransaction{
+		create(models.TransactionTypeIncome, salary.ID, 5000, models.FrequencyMonthly, false),
+		create(models.TransactionTypeIncome, salary.ID, 1000, models.FrequencyMonthly, true),
+		create(models.TransactionTypeExpense, bills.ID, 100, models.FrequencyWeekly, false),
+		create(models.TransactionTypeExpense, bills.ID, 1200, models.FrequencyYearly, true),
+	}
+	
+	totals := service.GetMonthlyTotals(t.Context(), items)
+	assert.InDelta(t, 5000.0, totals.Income, 0.001)
+	assert.InDelta(t, 433.0, totals.Expenses, 0.001)
+	assert.InDelta(t, 4567.0, totals.Net, 0.001)
+	
+	// A paused item still has a would-be monthly cost to show
+	assert.InDelta(t, 100.0, service.MonthlyAmountUSD(t.Context(), items[3]), 0.001)
+	
+	// Converted once for the list, the amounts add up to the same totals
+	amounts := service.GetMonthlyAmountsUSD(t.Context(), items)
+	assert.InDelta(t, 100.0, amounts[items[3].ID], 0.001)
+	assert.Equal(t, totals, models.SumMonthlyAmounts(items, amounts))
+	
+	// The dashboard's projected burn follows the same rule
+	burnRate, err := txService.GetCurrentMonthBurnRate(t.Context())
+	require.NoError(t, err)
+	assert.InDelta(t, totals.Expenses, burnRate.ProjectedMonthly, 0.001)
+}
@@ -1,7 +1,9 @@
 package service
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -10,10 +12,14 @@ import (
 	"burnwise/internal/models"
 )
 
+// ErrSettingsReadOnly is returned for a change to settings opened read-only
+var ErrSettingsReadOnly = errors.New("settings are read-only while another Burnwise is running")
+
 // SettingsService manages application settings
 type SettingsService struct {
 	settings     *models.Settings
 	settingsPath string
+	readOnly     bool
 	mu           sync.RWMutex
 }
 
@@ -40,6 +46,29 @@ func NewSettingsService(dataDir string) (*SettingsService, error) {
 	return s, nil
 }
 
+// NewReadOnlySettingsService loads the settings without ever writing them,
+// for an instance that leaves the data directory to another Burnwise.
+// Without a settings file the defaults are used; changes fail with
+// ErrSettingsReadOnly.
+func NewReadOnlySettingsService(dataDir string) (*SettingsService, error) {
+	s := &SettingsService{
+		settingsPath: filepath.Join(dataDir, "settings.json"),
+		readOnly:     true,
+	}
+	if err := s.Load(); err != nil {
+		if !os.IsNotExist(err) {
+			return nil, fmt.Errorf("failed to load settings: %w", err)
+		}
+		s.settings = models.DefaultSettings()
+	}
+	return s, nil
+}
+
+// IsReadOnly reports whether the settings can't be changed
+func (s *SettingsService) IsReadOnly() bool {
+	return s.readOnly
+}
+
 // Load reads settings from file
 func (s *SettingsService) Load() error {
 	s.mu.Lock()
@@ -63,7 +92,14 @@ func (s *SettingsService) Load() error {
 func (s *SettingsService) Save() error {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
+	return s.save()
+}
 
+// save writes settings to file; the caller must hold the lock
+func (s *SettingsService) save() error {
+	if s.readOnly {
+		return ErrSettingsReadOnly
+	}
 	data, err := json.MarshalIndent(s.settings, "", "  ")
 	if err != nil {
 		return fmt.Errorf("failed to marshal settings: %w", err)
@@ -102,7 +138,36 @@ func (s *SettingsService) Update(fn func(*models.Settings) error) error {
 	}
 
 	// Save to file
-	return s.Save()
+	return s.save()
+}
+
+// UpdateUI validates and saves the UI preferences
+func (s *SettingsService) UpdateUI(ui models.UISettings) error {
+	if err := ui.Validate(); err != nil {
+		return err
+	}
+
+	return s.Update(func(settings *models.Settings) error {
+		settings.UI = ui
+		return nil
+	})
+}
+
+// UpdatePreferences validates and saves the UI preferences together with
+// the default currency, which must be enabled
+func (s *SettingsService) UpdatePreferences(ui models.UISettings, defaultCurrency string) error {
+	if err := ui.Validate(); err != nil {
+		return err
+	}
+
+	return s.Update(func(settings *models.Settings) error {
+		if !settings.ValidateCurrency(defaultCurrency) {
+			return fmt.Errorf("default currency %s is not enabled", defaultCurrency)
+		}
+		settings.UI = ui
+		settings.Currencies.Default = defaultCurrency
+		return nil
+	})
 }
 
 // GetEnabledCurrencies returns list of enabled currencies
@@ -139,9 +204,9 @@ func (s *SettingsService) EnableCurrency(currency string) error {
 }
 
 // DisableCurrency removes a currency from the enabled list
-func (s *SettingsService) DisableCurrency(currency string, transactionService *TransactionService) error {
+func (s *SettingsService) DisableCurrency(ctx context.Context, currency string, transactionService *TransactionService) error {
 	// First check if any transactions use this currency
-	count, err := transactionService.CountByCurrency(currency)
+	count, err := transactionService.CountByCurrency(ctx, currency)
 	if err != nil {
 		return fmt.Errorf("failed to check currency usage: %w", err)
 	}
@@ -167,6 +232,54 @@ func (s *SettingsService) SetDefaultCurrency(currency string) error {
 	})
 }
 
+// GetMonthlyLimit returns the overall monthly spending limit in USD, or
+// zero when none is set
+func (s *SettingsService) GetMonthlyLimit() float64 {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings.MonthlyLimit
+}
+
+// SetMonthlyLimit sets the overall monthly spending limit in USD. Zero
+// removes it.
+func (s *SettingsService) SetMonthlyLimit(limit float64) error {
+	if limit < 0 {
+		return fmt.Errorf("monthly limit cannot be negative")
+	}
+	
+	return s.Update(func(settings *models.Settings) error {
+		settings.MonthlyLimit = limit
+		return nil
+	})
+}
+
+// PreferFileRates reports whether rates loaded from a file are used instead
+// of fetching them from the API
+func (s *SettingsService) PreferFileRates() bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings.Currencies.PreferFileRates
+}
+
+// GetRoundingMode returns how converted USD amounts are rounded
+func (s *SettingsService) GetRoundingMode() models.RoundingMode {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.settings.Currencies.Rounding
+}
+
+// SetRoundingMode changes how converted USD amounts are rounded. Amounts
+// already stored are left as they are.
+func (s *SettingsService) SetRoundingMode(mode models.RoundingMode) error {
+	if !models.IsKnownRoundingMode(mode) {
+		return fmt.Errorf("unknown rounding mode %q", mode)
+	}
+	return s.Update(func(settings *models.Settings) error {
+		settings.Currencies.Rounding = mode
+		return nil
+	})
+}
+
 // GetFixedRate returns the fixed exchange rate for a currency if it exists
 func (s *SettingsService) GetFixedRate(currency string) (float64, bool) {
 	s.mu.RLock()
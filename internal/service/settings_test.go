@@ -4,6 +4,7 @@ import (
 	"os"
 	"path/filepath"
 	"testing"
+	"time"
 
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -68,7 +69,7 @@ func TestSettingsService(t *testing.T) {
 	})
 
 	t.Run("Enable and disable currencies", func(t *testing.T) {
-		service, err := NewSettingsService(tempDir)
+		service, err := NewSettingsService(t.TempDir())
 		require.NoError(t, err)
 
 		// Enable a new currency
@@ -88,7 +89,7 @@ func TestSettingsService(t *testing.T) {
 	})
 
 	t.Run("Cannot disable default currency", func(t *testing.T) {
-		service, err := NewSettingsService(tempDir)
+		service, err := NewSettingsService(t.TempDir())
 		require.NoError(t, err)
 
 		// Create mock transaction service
@@ -98,14 +99,14 @@ func TestSettingsService(t *testing.T) {
 		txService := NewTransactionService(txRepo, currencyService)
 
 		// Try to disable default currency
-		err = service.DisableCurrency("USD", txService)
+		err = service.DisableCurrency(t.Context(), "USD", txService)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "failed to disable currency USD")
 		assert.True(t, service.IsCurrencyEnabled("USD"))
 	})
 
 	t.Run("Cannot disable currency with transactions", func(t *testing.T) {
-		service, err := NewSettingsService(tempDir)
+		service, err := NewSettingsService(t.TempDir())
 		require.NoError(t, err)
 
 		// Create mock transaction service with EUR transaction
@@ -120,9 +121,12 @@ func TestSettingsService(t *testing.T) {
 			Name: "Test",
 			Type: models.TransactionTypeExpense,
 		}
-		err = categoryRepo.Create(category)
+		err = categoryRepo.Create(t.Context(), category)
 		require.NoError(t, err)
 
+		// Use a fixed EUR rate so the test does not depend on the exchange rate API
+		require.NoError(t, service.SetFixedRate("EUR", 0.92))
+
 		// Create EUR transaction
 		tx := &models.Transaction{
 			Type:        models.TransactionTypeExpense,
@@ -130,19 +134,20 @@ func TestSettingsService(t *testing.T) {
 			Currency:    "EUR",
 			CategoryID:  category.ID,
 			Description: "Test transaction",
+			Date:        time.Now(),
 		}
-		err = txService.Create(tx)
+		err = txService.Create(t.Context(), tx)
 		require.NoError(t, err)
 
 		// Try to disable EUR
-		err = service.DisableCurrency("EUR", txService)
+		err = service.DisableCurrency(t.Context(), "EUR", txService)
 		assert.Error(t, err)
 		assert.Contains(t, err.Error(), "cannot disable currency EUR")
 		assert.True(t, service.IsCurrencyEnabled("EUR"))
 	})
 
 	t.Run("Set default currency", func(t *testing.T) {
-		service, err := NewSettingsService(tempDir)
+		service, err := NewSettingsService(t.TempDir())
 		require.NoError(t, err)
 
 		// Set new default (must be enabled)
@@ -157,7 +162,7 @@ func TestSettingsService(t *testing.T) {
 	})
 
 	t.Run("Fixed exchange rates", func(t *testing.T) {
-		service, err := NewSettingsService(tempDir)
+		service, err := NewSettingsService(t.TempDir())
 		require.NoError(t, err)
 
 		// Check default AED rate
@@ -181,8 +186,115 @@ func TestSettingsService(t *testing.T) {
 		assert.False(t, exists)
 	})
 
+	t.Run("Update UI preferences", func(t *testing.T) {
+		dir := t.TempDir()
+		service, err := NewSettingsService(dir)
+		require.NoError(t, err)
+
+		ui := models.UISettings{DateFormat: "02 Jan 2006", DecimalPlaces: 4, Theme: "ocean"}
+		require.NoError(t, service.UpdateUI(ui))
+		assert.Equal(t, ui, service.Get().UI)
+
+		// Saved preferences survive a reload
+		reloaded, err := NewSettingsService(dir)
+		require.NoError(t, err)
+		assert.Equal(t, ui, reloaded.Get().UI)
+
+		// Invalid values are rejected and leave the settings unchanged
+		err = service.UpdateUI(models.UISettings{DateFormat: "2006-01-02", DecimalPlaces: 7, Theme: "default"})
+		assert.ErrorContains(t, err, "decimal places must be between 0 and 6")
+
+		err = service.UpdateUI(models.UISettings{DateFormat: "2006-01-02", DecimalPlaces: 2, Theme: "neon"})
+		assert.ErrorContains(t, err, "unknown theme")
+
+		err = service.UpdateUI(models.UISettings{DateFormat: "2006-01-02", DecimalPlaces: 2, Theme: "default", TagPattern: `^\[(\w+`})
+		assert.ErrorContains(t, err, "invalid tag pattern")
+
+		err = service.UpdateUI(models.UISettings{DateFormat: "2006-01", DecimalPlaces: 2, Theme: "default"})
+		assert.ErrorContains(t, err, "not a Go date layout")
+
+		err = service.UpdateUI(models.UISettings{DateFormat: "yyyy-mm-dd", DecimalPlaces: 2, Theme: "default"})
+		assert.ErrorContains(t, err, "not a Go date layout")
+
+		assert.Equal(t, ui, service.Get().UI)
+	})
+
+	t.Run("Update preferences with the default currency", func(t *testing.T) {
+		dir := t.TempDir()
+		service, err := NewSettingsService(dir)
+		require.NoError(t, err)
+
+		ui := models.UISettings{DateFormat: "Jan 2, 2006", DecimalPlaces: 2, Theme: "forest"}
+		require.NoError(t, service.UpdatePreferences(ui, "EUR"))
+
+		reloaded, err := NewSettingsService(dir)
+		require.NoError(t, err)
+		assert.Equal(t, ui, reloaded.Get().UI)
+		assert.Equal(t, "EUR", reloaded.GetDefaultCurrency())
+
+		// A currency that isn't enabled saves nothing, UI preferences included
+		err = service.UpdatePreferences(models.UISettings{DateFormat: "2006-01-02", DecimalPlaces: 2, Theme: "default"}, "JPY")
+		assert.ErrorContains(t, err, "default currency JPY is not enabled")
+		assert.Equal(t, ui, service.Get().UI)
+		assert.Equal(t, "EUR", service.GetDefaultCurrency())
+	})
+
+	t.Run("Monthly limit", func(t *testing.T) {
+		dir := t.TempDir()
+		service, err := NewSettingsService(dir)
+		require.NoError(t, err)
+		assert.Zero(t, service.GetMonthlyLimit())
+
+		require.NoError(t, service.SetMonthlyLimit(2500))
+		reloaded, err := NewSettingsService(dir)
+		require.NoError(t, err)
+		assert.Equal(t, 2500.0, reloaded.GetMonthlyLimit())
+
+		assert.ErrorContains(t, service.SetMonthlyLimit(-1), "cannot be negative")
+		assert.Equal(t, 2500.0, service.GetMonthlyLimit())
+	})
+
+	t.Run("Dashboard layout", func(t *testing.T) {
+		dir := t.TempDir()
+		service, err := NewSettingsService(dir)
+		require.NoError(t, err)
+
+		// Without saved preferences every widget is shown in the default order
+		layout := service.Get().UI.Dashboard.Layout()
+		require.Len(t, layout, len(models.DashboardWidgets))
+		for i, widget := range layout {
+			assert.Equal(t, models.DashboardWidgets[i], widget.Name)
+			assert.True(t, widget.Enabled)
+		}
+
+		ui := service.Get().UI
+		ui.Dashboard.Widgets = []models.DashboardWidget{
+			{Name: models.WidgetTransactions, Enabled: true},
+			{Name: models.WidgetBudgets, Enabled: false},
+		}
+		require.NoError(t, service.UpdateUI(ui))
+
+		reloaded, err := NewSettingsService(dir)
+		require.NoError(t, err)
+		layout = reloaded.Get().UI.Dashboard.Layout()
+
+		// Widgets left out of the settings are appended, shown
+		assert.Equal(t, []models.DashboardWidget{
+			{Name: models.WidgetTransactions, Enabled: true},
+			{Name: models.WidgetBudgets, Enabled: false},
+			{Name: models.WidgetBurnRate, Enabled: true},
+			{Name: models.WidgetNextIncome, Enabled: true},
+			{Name: models.WidgetSummary, Enabled: true},
+			{Name: models.WidgetLimit, Enabled: true},
+			{Name: models.WidgetPace, Enabled: true},
+		}, layout)
+
+		ui.Dashboard.Widgets = []models.DashboardWidget{{Name: "weather", Enabled: true}}
+		assert.ErrorContains(t, service.UpdateUI(ui), `unknown dashboard widget "weather"`)
+	})
+
 	t.Run("Concurrent access safety", func(t *testing.T) {
-		service, err := NewSettingsService(tempDir)
+		service, err := NewSettingsService(t.TempDir())
 		require.NoError(t, err)
 
 		// Run concurrent operations
@@ -214,4 +326,26 @@ func TestSettingsService(t *testing.T) {
 			<-done
 		}
 	})
-}
\ No newline at end of file
+}
+func TestSettingsService_ReadOnly(t *testing.T) {
+	// Without a settings file the defaults are used and nothing is written
+	tempDir := t.TempDir()
+	service, err := NewReadOnlySettingsService(tempDir)
+	require.NoError(t, err)
+	assert.True(t, service.IsReadOnly())
+	assert.Equal(t, "USD", service.Get().Currencies.Default)
+	assert.NoFileExists(t, filepath.Join(tempDir, "settings.json"))
+
+	// With one it is loaded but never saved
+	writable, err := NewSettingsService(tempDir)
+	require.NoError(t, err)
+	require.NoError(t, writable.SetMonthlyLimit(2500))
+	service, err = NewReadOnlySettingsService(tempDir)
+	require.NoError(t, err)
+	assert.Equal(t, 2500.0, service.Get().MonthlyLimit)
+
+	assert.ErrorIs(t, service.SetMonthlyLimit(3000), ErrSettingsReadOnly)
+	reloaded, err := NewSettingsService(tempDir)
+	require.NoError(t, err)
+	assert.Equal(t, 2500.0, reloaded.Get().MonthlyLimit)
+}
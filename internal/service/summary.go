@@ -0,0 +1,57 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"burnwise/internal/models"
+)
+
+// DigestExpiringDays is how many days ahead the startup digest looks for recurring
+// transactions that end
+const DigestExpiringDays = 30
+
+// SummaryService gathers what needs attention across budgets and recurring
+// transactions
+type SummaryService struct {
+	budgetService    *BudgetService
+	recurringService *RecurringTransactionService
+}
+
+func NewSummaryService(budgetService *BudgetService, recurringService *RecurringTransactionService) *SummaryService {
+	return &SummaryService{
+		budgetService:    budgetService,
+		recurringService: recurringService,
+	}
+}
+
+// GetStartupDigest lists the budgets over their amount this period, the
+// recurring transactions ending within DigestExpiringDays, and the
+// transactions generated from recurring ones since generatedSince, which is
+// when the catch-up on startup began
+func (s *SummaryService) GetStartupDigest(ctx context.Context, generatedSince time.Time) (*models.StartupDigest, error) {
+	digest := &models.StartupDigest{}
+
+	statuses, err := s.budgetService.GetAllStatuses(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get budget statuses: %w", err)
+	}
+	for _, status := range statuses {
+		if status.IsOverBudget {
+			digest.OverBudget = append(digest.OverBudget, status)
+		}
+	}
+
+	digest.Expiring, err = s.recurringService.GetExpiring(ctx, DigestExpiringDays)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expiring recurring transactions: %w", err)
+	}
+
+	digest.Generated, err = s.recurringService.GetGeneratedSince(ctx, generatedSince)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get generated transactions: %w", err)
+	}
+
+	return digest, nil
+}
@@ -0,0 +1,80 @@
+package service
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"burnwise/internal/models"
+	"burnwise/internal/repository"
+	test "burnwise/test/helpers"
+)
+
+func TestSummaryService_GetStartupDigest(t *testing.T) {
+	db := test.SetupTestDB(t)
+	txRepo := repository.NewTransactionRepository(db)
+	recurringRepo := repository.NewRecurringTransactionRepository(db)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	budgetService := NewBudgetService(repository.NewBudgetRepository(db), txRepo)
+	recurringService := NewRecurringTransactionService(recurringRepo, txRepo, NewCurrencyService(settingsService))
+	service := NewSummaryService(budgetService, recurringService)
+
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	rent := test.CreateTestCategory(t, db, "Rent", models.TransactionTypeExpense)
+
+	// Nothing needs attention yet
+	digest, err := service.GetStartupDigest(t.Context(), time.Now())
+	require.NoError(t, err)
+	assert.True(t, digest.IsEmpty())
+
+	// Food is over its budget, Rent within it
+	test.CreateTestBudget(t, db, food.ID, 100)
+	test.CreateTestBudget(t, db, rent.ID, 2000)
+	test.CreateTestTransaction(t, db, 150, food.ID)
+
+	// A lease ends in three weeks and one in two months; the rent that was
+	// due is generated by the catch-up
+	newRecurring := func(description string, endsIn int) *models.RecurringTransaction {
+		end := time.Now().AddDate(0, 0, endsIn)
+		rt := &models.RecurringTransaction{
+			Type:        models.TransactionTypeExpense,
+			Amount:      900,
+			Currency:    "USD",
+			CategoryID:  rent.ID,
+			Description: description,
+			Frequency:   models.FrequencyMonthly,
+			StartDate:   time.Now().AddDate(0, -1, 0),
+			EndDate:     &end,
+			IsActive:    true,
+		}
+		require.NoError(t, recurringService.Create(t.Context(), rt))
+		return rt
+	}
+	parking := newRecurring("Parking", 60)
+	lease := newRecurring("Studio lease", 21)
+	require.NoError(t, db.Model(parking).UpdateColumn("next_due_date", time.Now().AddDate(0, 0, 5)).Error)
+	require.NoError(t, db.Model(lease).UpdateColumn("next_due_date", time.Now().AddDate(0, 0, -1)).Error)
+
+	startedAt := time.Now()
+	_, err = recurringService.ProcessDueTransactions(t.Context(), time.Now())
+	require.NoError(t, err)
+
+	digest, err = service.GetStartupDigest(t.Context(), startedAt)
+	require.NoError(t, err)
+	assert.False(t, digest.IsEmpty())
+	require.Len(t, digest.OverBudget, 1)
+	assert.Equal(t, food.ID, digest.OverBudget[0].Budget.CategoryID)
+	require.Len(t, digest.Expiring, 1)
+	assert.Equal(t, "Studio lease", digest.Expiring[0].Description)
+	require.Len(t, digest.Generated, 1)
+	assert.Equal(t, "Studio lease", digest.Generated[0].Description)
+	assert.Equal(t, "Rent", digest.Generated[0].Category.Name)
+
+	// Transactions generated before the app started aren't listed again
+	digest, err = service.GetStartupDigest(t.Context(), time.Now().Add(time.Second))
+	require.NoError(t, err)
+	assert.Empty(t, digest.Generated)
+}
@@ -1,7 +1,15 @@
 package service
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
 	"time"
 
 	"burnwise/internal/models"
@@ -12,6 +20,7 @@ type TransactionService struct {
 	repo            *repository.TransactionRepository
 	currencyService *CurrencyService
 	recurringRepo   *repository.RecurringTransactionRepository
+	undoService     *UndoService
 }
 
 func NewTransactionService(repo *repository.TransactionRepository, currencyService *CurrencyService) *TransactionService {
@@ -25,119 +34,1350 @@ func (s *TransactionService) SetRecurringRepo(recurringRepo *repository.Recurrin
 	s.recurringRepo = recurringRepo
 }
 
-func (s *TransactionService) Create(tx *models.Transaction) error {
+func (s *TransactionService) SetUndoService(undoService *UndoService) {
+	s.undoService = undoService
+}
+
+func (s *TransactionService) Create(ctx context.Context, tx *models.Transaction) error {
 	if err := tx.Validate(); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
-	if tx.Currency != "USD" {
-		amountUSD, err := s.currencyService.ConvertToUSD(tx.Amount, tx.Currency)
+	if tx.Type == models.TransactionTypeTransfer {
+		return fmt.Errorf("validation failed: transfers are made between two accounts with CreateTransfer")
+	}
+
+	if err := s.checkCategoryType(ctx, tx); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := s.setAmountUSD(ctx, tx); err != nil {
+		return fmt.Errorf("failed to convert currency: %w", err)
+	}
+
+	if err := s.checkSplits(ctx, tx); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := s.checkRefundOf(ctx, tx); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := s.checkAccount(ctx, tx); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	return s.repo.Create(ctx, tx)
+}
+
+func (s *TransactionService) Update(ctx context.Context, tx *models.Transaction) error {
+	if err := tx.Validate(); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if tx.Type == models.TransactionTypeTransfer {
+		return fmt.Errorf("validation failed: transfers can't be edited; delete the transfer and make it again")
+	}
+
+	if err := s.checkCategoryType(ctx, tx); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := s.setAmountUSD(ctx, tx); err != nil {
+		return fmt.Errorf("failed to convert currency: %w", err)
+	}
+
+	if err := s.checkSplits(ctx, tx); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := s.checkRefundOf(ctx, tx); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := s.checkRefunded(ctx, tx); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := s.checkAccount(ctx, tx); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	if err := s.checkAllocated(ctx, tx); err != nil {
+		return fmt.Errorf("validation failed: %w", err)
+	}
+
+	return s.repo.Update(ctx, tx)
+}
+
+// checkCategoryType makes sure income and expense transactions are filed
+// under a category of the same type, e.g. after the type was changed
+// while editing. Refunds are expenses and use expense categories.
+func (s *TransactionService) checkCategoryType(ctx context.Context, tx *models.Transaction) error {
+	if tx.Type != models.TransactionTypeIncome && tx.Type != models.TransactionTypeExpense {
+		return nil
+	}
+
+	category, err := s.repo.GetCategory(ctx, tx.CategoryID)
+	if err != nil {
+		return fmt.Errorf("category not found: %w", err)
+	}
+	if category.Type != tx.Type {
+		return fmt.Errorf("category '%s' is for %s, not %s transactions", category.Name, category.Type, tx.Type)
+	}
+	return nil
+}
+
+// checkSplits makes sure the lines of a split transaction, if any, are
+// filed under categories of the transaction's type and add up to its
+// amount, then gives each line its share of the USD amount. The
+// transaction's own category is set to the first line's, which is what it
+// is listed under should the splits not be loaded. Each line's Category is
+// cleared, since saving would otherwise write whatever it held.
+func (s *TransactionService) checkSplits(ctx context.Context, tx *models.Transaction) error {
+	if len(tx.Splits) == 0 {
+		return nil
+	}
+	if tx.Type == models.TransactionTypeTransfer {
+		return fmt.Errorf("transfers can't be split")
+	}
+	if len(tx.Splits) < models.MinSplitLines {
+		return fmt.Errorf("a split transaction needs at least %d lines", models.MinSplitLines)
+	}
+
+	for i := range tx.Splits {
+		line := &tx.Splits[i]
+		if err := line.Validate(); err != nil {
+			return err
+		}
+		category, err := s.repo.GetCategory(ctx, line.CategoryID)
 		if err != nil {
-			return fmt.Errorf("failed to convert currency: %w", err)
+			return fmt.Errorf("category not found: %w", err)
 		}
-		tx.AmountUSD = amountUSD
-	} else {
-		tx.AmountUSD = tx.Amount
+		if category.Type != tx.Type {
+			return fmt.Errorf("category '%s' is for %s, not %s transactions", category.Name, category.Type, tx.Type)
+		}
+		line.Category = models.Category{}
+	}
+
+	if err := models.CheckSplitTotal(tx.Amount, tx.Currency, tx.Splits); err != nil {
+		return err
 	}
 
-	return s.repo.Create(tx)
+	tx.CategoryID = tx.Splits[0].CategoryID
+	models.SpreadUSD(tx.Amount, tx.AmountUSD, tx.Splits)
+	return nil
 }
 
-func (s *TransactionService) Update(tx *models.Transaction) error {
-	if err := tx.Validate(); err != nil {
+// checkAccount makes sure the account a transaction is assigned to, if
+// any, exists. Account is cleared, like RefundOf, since saving would
+// otherwise assign the transaction to whatever Account held.
+func (s *TransactionService) checkAccount(ctx context.Context, tx *models.Transaction) error {
+	tx.Account = nil
+	if tx.AccountID == nil {
+		return nil
+	}
+
+	if _, err := s.repo.GetAccount(ctx, *tx.AccountID); err != nil {
+		return fmt.Errorf("account not found: %w", err)
+	}
+	return nil
+}
+
+// checkRefundOf makes sure a linked refund gives money back for an expense
+// that isn't a refund itself, and that the refunds linked to it don't add
+// up to more than it cost. RefundOf is set to match RefundOfID, since
+// saving would otherwise link the refund to whatever RefundOf held.
+func (s *TransactionService) checkRefundOf(ctx context.Context, tx *models.Transaction) error {
+	tx.RefundOf = nil
+	if tx.RefundOfID == nil {
+		return nil
+	}
+
+	original, err := s.repo.GetByID(ctx, *tx.RefundOfID)
+	if err != nil {
+		return fmt.Errorf("original transaction not found: %w", err)
+	}
+	if original.Type != models.TransactionTypeExpense || original.IsRefund {
+		return fmt.Errorf("'%s' is not an expense, so it can't be refunded", original.Description)
+	}
+
+	refunded, err := s.refundedExcept(ctx, original, tx.ID)
+	if err != nil {
+		return err
+	}
+	refunded += refundInCurrencyOf(original, tx)
+
+	// Allow for rounding to the currency's smallest unit
+	decimals := models.CurrencyDecimals(original.Currency)
+	if refunded > original.Amount+0.5*math.Pow10(-decimals) {
+		return fmt.Errorf("refunds of '%s' would total %.*f %s, more than the original %.*f %s",
+			original.Description, decimals, refunded, original.Currency, decimals, original.Amount, original.Currency)
+	}
+	tx.RefundOf = original
+	return nil
+}
+
+// checkRefunded makes sure an edit leaves a refunded transaction as an
+// expense of at least the amount already refunded
+func (s *TransactionService) checkRefunded(ctx context.Context, tx *models.Transaction) error {
+	if tx.ID == 0 {
+		return nil
+	}
+	refunds, err := s.repo.GetRefunds(ctx, tx.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get refunds: %w", err)
+	}
+	if len(refunds) == 0 {
+		return nil
+	}
+
+	if tx.Type != models.TransactionTypeExpense || tx.IsRefund {
+		return fmt.Errorf("'%s' has refunds linked to it; unlink them before changing its type", tx.Description)
+	}
+	var refunded float64
+	for _, refund := range refunds {
+		refunded += refundInCurrencyOf(tx, refund)
+	}
+
+	// Allow for rounding to the currency's smallest unit
+	decimals := models.CurrencyDecimals(tx.Currency)
+	if refunded > tx.Amount+0.5*math.Pow10(-decimals) {
+		return fmt.Errorf("'%s' has already been refunded %.*f %s, more than %.*f %s",
+			tx.Description, decimals, refunded, tx.Currency, decimals, tx.Amount, tx.Currency)
+	}
+	return nil
+}
+
+// checkAllocated makes sure an edit leaves an allocated transaction as
+// income of at least the amount allocated from it
+func (s *TransactionService) checkAllocated(ctx context.Context, tx *models.Transaction) error {
+	allocations, err := s.repo.GetAllocations(ctx, tx.ID)
+	if err != nil {
+		return fmt.Errorf("failed to get allocations: %w", err)
+	}
+	if len(allocations) == 0 {
+		return nil
+	}
+
+	if tx.Type != models.TransactionTypeIncome {
+		return fmt.Errorf("'%s' has income allocations; remove them before changing its type", tx.Description)
+	}
+	return checkAllocationTotal(tx, allocations)
+}
+
+// checkAllocationTotal makes sure the allocations don't add up to more than
+// the transaction, allowing for rounding to the currency's smallest unit
+func checkAllocationTotal(tx *models.Transaction, allocations []*models.IncomeAllocation) error {
+	var allocated float64
+	for _, allocation := range allocations {
+		allocated += allocation.Amount
+	}
+
+	decimals := models.CurrencyDecimals(tx.Currency)
+	if allocated > tx.Amount+0.5*math.Pow10(-decimals) {
+		return fmt.Errorf("allocations total %.*f %s, more than the %.*f %s of '%s'",
+			decimals, allocated, tx.Currency, decimals, tx.Amount, tx.Currency, tx.Description)
+	}
+	return nil
+}
+
+// GetAllocations returns how the income transaction with the given ID is
+// allocated, largest first
+func (s *TransactionService) GetAllocations(ctx context.Context, transactionID uint) ([]*models.IncomeAllocation, error) {
+	return s.repo.GetAllocations(ctx, transactionID)
+}
+
+// SetAllocations replaces how an income transaction, such as a paycheck, is
+// allocated, e.g. 1,000 to Savings and 1,200 to Taxes. Amounts are in the
+// transaction's currency and may add up to less than it, the rest being
+// unallocated. Allocations with the same name are combined; passing none
+// removes them all.
+func (s *TransactionService) SetAllocations(ctx context.Context, transactionID uint, allocations []*models.IncomeAllocation) error {
+	tx, err := s.repo.GetByID(ctx, transactionID)
+	if err != nil {
+		return fmt.Errorf("transaction not found: %w", err)
+	}
+	if tx.Type != models.TransactionTypeIncome {
+		return fmt.Errorf("validation failed: only income can be allocated, not %s transactions", tx.DisplayType())
+	}
+
+	var combined []*models.IncomeAllocation
+	byName := make(map[string]*models.IncomeAllocation)
+	for _, allocation := range allocations {
+		if err := allocation.Validate(); err != nil {
+			return fmt.Errorf("validation failed: %w", err)
+		}
+		name := strings.TrimSpace(allocation.Name)
+		key := strings.ToLower(name)
+		if existing, ok := byName[key]; ok {
+			existing.Amount += allocation.Amount
+			continue
+		}
+		byName[key] = &models.IncomeAllocation{Name: name, Amount: allocation.Amount}
+		combined = append(combined, byName[key])
+	}
+
+	if err := checkAllocationTotal(tx, combined); err != nil {
 		return fmt.Errorf("validation failed: %w", err)
 	}
 
-	if tx.Currency != "USD" {
-		amountUSD, err := s.currencyService.ConvertToUSD(tx.Amount, tx.Currency)
+	return s.repo.ReplaceAllocations(ctx, transactionID, combined)
+}
+
+// GetAttachments returns the files attached to the transaction with the
+// given ID, oldest first
+func (s *TransactionService) GetAttachments(ctx context.Context, transactionID uint) ([]*models.Attachment, error) {
+	return s.repo.GetAttachments(ctx, transactionID)
+}
+
+// AddAttachment attaches the file at path, such as a receipt, to a
+// transaction. The path may be quoted or start with ~, as when pasted or
+// dropped into a terminal, and is stored absolute. The file must exist
+// now, but it isn't copied, so moving or deleting it later only leaves the
+// path behind.
+func (s *TransactionService) AddAttachment(ctx context.Context, transactionID uint, path string) (*models.Attachment, error) {
+	attachment := &models.Attachment{TransactionID: transactionID, Path: path, AddedAt: time.Now()}
+	if err := attachment.Validate(); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+
+	resolved, err := resolveAttachmentPath(path)
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	attachment.Path = resolved
+
+	if _, err := s.repo.GetByID(ctx, transactionID); err != nil {
+		return nil, fmt.Errorf("transaction not found: %w", err)
+	}
+	existing, err := s.repo.GetAttachments(ctx, transactionID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get attachments: %w", err)
+	}
+	for _, other := range existing {
+		if other.Path == resolved {
+			return nil, fmt.Errorf("validation failed: %s is already attached", resolved)
+		}
+	}
+
+	if err := s.repo.AddAttachment(ctx, attachment); err != nil {
+		return nil, err
+	}
+	return attachment, nil
+}
+
+// RemoveAttachment detaches a file from its transaction. The file itself
+// is left alone.
+func (s *TransactionService) RemoveAttachment(ctx context.Context, id uint) error {
+	if err := s.repo.DeleteAttachment(ctx, id); err != nil {
+		return fmt.Errorf("attachment not found: %w", err)
+	}
+	return nil
+}
+
+// resolveAttachmentPath cleans up a typed or pasted path and makes it
+// absolute, making sure it names an existing file
+func resolveAttachmentPath(path string) (string, error) {
+	path = strings.TrimSpace(path)
+	if len(path) >= 2 && (path[0] == '"' || path[0] == '\'') && path[len(path)-1] == path[0] {
+		path = path[1 : len(path)-1]
+	}
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		home, err := os.UserHomeDir()
 		if err != nil {
-			return fmt.Errorf("failed to convert currency: %w", err)
+			return "", fmt.Errorf("failed to find the home directory: %w", err)
 		}
-		tx.AmountUSD = amountUSD
-	} else {
+		path = filepath.Join(home, path[1:])
+	}
+
+	info, err := os.Stat(path)
+	if err != nil && strings.Contains(path, `\ `) {
+		// Terminals escape spaces in dropped paths
+		path = strings.ReplaceAll(path, `\ `, " ")
+		info, err = os.Stat(path)
+	}
+	if err != nil {
+		return "", fmt.Errorf("no file at %s", path)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("%s is a directory, not a file", path)
+	}
+
+	return filepath.Abs(path)
+}
+
+// GetAllocationSummary breaks the income of the period down by where it was
+// allocated, in USD
+func (s *TransactionService) GetAllocationSummary(ctx context.Context, start, end time.Time) (*models.AllocationSummary, error) {
+	summary, err := s.repo.GetSummary(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get summary: %w", err)
+	}
+
+	totals, err := s.repo.GetAllocationTotals(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get allocation totals: %w", err)
+	}
+
+	return &models.AllocationSummary{TotalIncome: summary.TotalIncome, Allocations: totals}, nil
+}
+
+// GetRefunded returns how much of original has been refunded by the refunds
+// linked to it, in original's currency
+func (s *TransactionService) GetRefunded(ctx context.Context, original *models.Transaction) (float64, error) {
+	return s.refundedExcept(ctx, original, 0)
+}
+
+func (s *TransactionService) refundedExcept(ctx context.Context, original *models.Transaction, exceptID uint) (float64, error) {
+	refunds, err := s.repo.GetRefunds(ctx, original.ID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get refunds: %w", err)
+	}
+
+	var total float64
+	for _, refund := range refunds {
+		if refund.ID != exceptID {
+			total += refundInCurrencyOf(original, refund)
+		}
+	}
+	return total, nil
+}
+
+// refundInCurrencyOf is the refund's amount in the original's currency. A
+// refund in another currency is converted at the original's own rate, so a
+// full refund matches the original whatever the rate does in between.
+func refundInCurrencyOf(original, refund *models.Transaction) float64 {
+	if refund.Currency == original.Currency || original.AmountUSD == 0 {
+		return refund.Amount
+	}
+	return refund.AmountUSD * original.Amount / original.AmountUSD
+}
+
+// FindRefundable returns up to limit expenses a refund could be linked to,
+// newest first: those whose description contains query or, when query is a
+// number, whose amount is that number. Refunds themselves are left out.
+func (s *TransactionService) FindRefundable(ctx context.Context, query string, limit int) ([]*models.Transaction, error) {
+	query = strings.TrimSpace(query)
+	var amount *float64
+	if value, err := strconv.ParseFloat(query, 64); err == nil {
+		amount = &value
+	}
+
+	matches, err := s.repo.FindRefundable(ctx, query, amount, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to search transactions: %w", err)
+	}
+	return matches, nil
+}
+
+// setAmountUSD fills in the USD amount from the exchange rate, unless it
+// was entered by hand for a foreign-currency transaction, e.g. to match
+// what the bank charged including fees. The rate's source is recorded
+// with it.
+func (s *TransactionService) setAmountUSD(ctx context.Context, tx *models.Transaction) error {
+	if tx.Currency == "USD" {
+		tx.ManualUSD = false
 		tx.AmountUSD = tx.Amount
+		tx.RateSource = ""
+		return nil
+	}
+	if tx.ManualUSD {
+		tx.AmountUSD = s.currencyService.RoundUSD(tx.AmountUSD)
+		tx.RateSource = models.RateSourceManual
+		return nil
 	}
 
-	return s.repo.Update(tx)
+	amountUSD, source, err := s.currencyService.ConvertToUSDWithInfo(ctx, tx.Amount, tx.Currency)
+	if err != nil {
+		return err
+	}
+	tx.AmountUSD = amountUSD
+	tx.RateSource = source
+	return nil
 }
 
-func (s *TransactionService) Delete(id uint) error {
-	_, err := s.repo.GetByID(id)
+// CreateTransfer moves amount from one account to another. It is stored
+// as a pair of linked transfer transactions, one taking the money out of
+// fromAccount and one putting it into toAccount, which count towards the
+// accounts' balances but never as income or expenses. The outgoing and
+// incoming halves are returned in that order.
+func (s *TransactionService) CreateTransfer(ctx context.Context, fromAccount, toAccount uint, amount float64, currency string, date time.Time, description string) (*models.Transaction, *models.Transaction, error) {
+	if fromAccount == toAccount {
+		return nil, nil, fmt.Errorf("validation failed: can't transfer from an account to itself")
+	}
+
+	out := &models.Transaction{
+		Type:        models.TransactionTypeTransfer,
+		Amount:      amount,
+		Currency:    currency,
+		Description: description,
+		Date:        date,
+		AccountID:   &fromAccount,
+	}
+	in := *out
+	in.AccountID = &toAccount
+	in.IsTransferIn = true
+
+	for _, tx := range []*models.Transaction{out, &in} {
+		if err := tx.Validate(); err != nil {
+			return nil, nil, fmt.Errorf("validation failed: %w", err)
+		}
+		if err := s.checkAccount(ctx, tx); err != nil {
+			return nil, nil, fmt.Errorf("validation failed: %w", err)
+		}
+	}
+
+	if err := s.setAmountUSD(ctx, out); err != nil {
+		return nil, nil, fmt.Errorf("failed to convert currency: %w", err)
+	}
+	in.AmountUSD = out.AmountUSD
+	in.RateSource = out.RateSource
+
+	if err := s.repo.CreateTransfer(ctx, out, &in); err != nil {
+		return nil, nil, err
+	}
+	return out, &in, nil
+}
+
+// Delete removes a transaction, along with the other half of a transfer
+func (s *TransactionService) Delete(ctx context.Context, id uint) error {
+	tx, err := s.repo.GetByID(ctx, id)
 	if err != nil {
 		return fmt.Errorf("transaction not found: %w", err)
 	}
 
-	return s.repo.Delete(id)
+	ids := []uint{id}
+	if tx.TransferPairID != nil {
+		ids = append(ids, *tx.TransferPairID)
+	}
+	if err := s.repo.Delete(ctx, ids...); err != nil {
+		return err
+	}
+
+	if s.undoService != nil {
+		s.undoService.Record(fmt.Sprintf("delete transaction '%s'", tx.Description), func(ctx context.Context) error {
+			return s.repo.Restore(ctx, ids...)
+		})
+	}
+
+	return nil
 }
 
-func (s *TransactionService) GetByID(id uint) (*models.Transaction, error) {
-	return s.repo.GetByID(id)
+func (s *TransactionService) GetByID(ctx context.Context, id uint) (*models.Transaction, error) {
+	return s.repo.GetByID(ctx, id)
 }
 
-func (s *TransactionService) GetAll() ([]*models.Transaction, error) {
-	return s.repo.GetAll()
+func (s *TransactionService) GetAll(ctx context.Context) ([]*models.Transaction, error) {
+	return s.repo.GetAll(ctx)
 }
 
-func (s *TransactionService) GetByDateRange(start, end time.Time) ([]*models.Transaction, error) {
-	return s.repo.GetByDateRange(start, end)
+func (s *TransactionService) GetByDateRange(ctx context.Context, start, end time.Time) ([]*models.Transaction, error) {
+	return s.repo.GetByDateRange(ctx, start, end)
 }
 
-func (s *TransactionService) GetByFilter(filter *models.TransactionFilter) ([]*models.Transaction, error) {
-	return s.repo.GetByFilter(filter)
+func (s *TransactionService) GetByFilter(ctx context.Context, filter *models.TransactionFilter) ([]*models.Transaction, error) {
+	return s.repo.GetByFilter(ctx, filter)
 }
 
-func (s *TransactionService) GetCurrentMonthSummary() (*models.TransactionSummary, error) {
+func (s *TransactionService) GetCurrentMonthSummary(ctx context.Context) (*models.TransactionSummary, error) {
 	now := time.Now()
 	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 	end := start.AddDate(0, 1, 0).Add(-time.Second)
 	
-	return s.repo.GetSummary(start, end)
+	return s.repo.GetSummary(ctx, start, end)
+}
+
+// GetMonthlyLimitStatus compares this month's expenses so far with the
+// overall monthly limit. It returns nil when no limit is set.
+func (s *TransactionService) GetMonthlyLimitStatus(ctx context.Context, limit float64) (*models.SpendingLimitStatus, error) {
+	if limit <= 0 {
+		return nil, nil
+	}
+	
+	summary, err := s.GetCurrentMonthSummary(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get month summary: %w", err)
+	}
+	
+	status := &models.SpendingLimitStatus{Limit: limit, Spent: summary.TotalExpenses}
+	status.Calculate()
+	return status, nil
+}
+
+// GetSpendingVelocity compares this month's expenses up to today with an
+// even pace toward the monthly limit. Without a limit it compares this
+// month's expenses up to today in the categories of the monthly budgets
+// with their total instead, each category counted once: a budget whose
+// categories are all in a group budget is covered by the group's amount.
+// A prorated budget in its first month paces from its start date. It
+// returns nil when there is neither.
+func (s *TransactionService) GetSpendingVelocity(ctx context.Context, limit float64, budgets []*models.BudgetStatus, now time.Time) (*models.SpendingVelocity, error) {
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	endOfToday := time.Date(now.Year(), now.Month(), now.Day()+1, 0, 0, 0, 0, now.Location()).Add(-time.Second)
+	if limit > 0 {
+		summary, err := s.repo.GetSummary(ctx, start, endOfToday)
+		if err != nil {
+			return nil, fmt.Errorf("failed to get month summary: %w", err)
+		}
+		return models.NewSpendingVelocity(summary.TotalExpenses, limit, now), nil
+	}
+
+	var monthly []*models.BudgetStatus
+	for _, status := range budgets {
+		if status.Budget.Period == models.BudgetPeriodMonthly {
+			monthly = append(monthly, status)
+		}
+	}
+	// Groups first, so the budgets within them are left out
+	sort.SliceStable(monthly, func(i, j int) bool {
+		return monthly[i].Budget.IsGroup() && !monthly[j].Budget.IsGroup()
+	})
+
+	covered := make(map[uint]bool)
+	var target, expected float64
+	daysInMonth := time.Date(now.Year(), now.Month()+1, 0, 0, 0, 0, 0, now.Location()).Day()
+	for _, status := range monthly {
+		added := false
+		for _, id := range status.Budget.CategoryIDs() {
+			if !covered[id] {
+				covered[id] = true
+				added = true
+			}
+		}
+		if !added {
+			continue
+		}
+
+		// The amount is spread over the days the budget runs this month
+		first := 1
+		if status.Budget.IsProratedIn(start) {
+			first = status.Budget.StartDate.In(now.Location()).Day()
+		}
+		if elapsed := now.Day() - first + 1; elapsed > 0 {
+			expected += status.Budget.Amount * float64(elapsed) / float64(daysInMonth-first+1)
+		}
+		target += status.Budget.Amount
+	}
+	if target <= 0 {
+		return nil, nil
+	}
+
+	categories, err := s.repo.GetCategorySummary(ctx, start, endOfToday)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get month spending: %w", err)
+	}
+	var spent float64
+	for _, category := range categories {
+		if category.Type == models.TransactionTypeExpense && covered[category.ID] {
+			spent += category.Total
+		}
+	}
+	return models.NewSpendingVelocityExpecting(models.RoundUSD(spent), target, expected, now), nil
 }
 
-func (s *TransactionService) GetMonthSummary(year int, month time.Month) (*models.TransactionSummary, error) {
+func (s *TransactionService) GetMonthSummary(ctx context.Context, year int, month time.Month) (*models.TransactionSummary, error) {
 	start := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
 	end := start.AddDate(0, 1, 0).Add(-time.Second)
 	
-	return s.repo.GetSummary(start, end)
+	return s.repo.GetSummary(ctx, start, end)
+}
+
+// GetSummary totals the transactions between start and end, inclusive
+func (s *TransactionService) GetSummary(ctx context.Context, start, end time.Time) (*models.TransactionSummary, error) {
+	return s.repo.GetSummary(ctx, start, end)
 }
 
-func (s *TransactionService) GetYearSummary(year int) (*models.TransactionSummary, error) {
+func (s *TransactionService) GetYearSummary(ctx context.Context, year int) (*models.TransactionSummary, error) {
 	start := time.Date(year, 1, 1, 0, 0, 0, 0, time.Local)
 	end := start.AddDate(1, 0, 0).Add(-time.Second)
 	
-	return s.repo.GetSummary(start, end)
+	return s.repo.GetSummary(ctx, start, end)
 }
 
-func (s *TransactionService) GetCategorySummary(start, end time.Time) ([]*models.CategoryWithTotal, error) {
-	return s.repo.GetCategorySummary(start, end)
+func (s *TransactionService) GetCategorySummary(ctx context.Context, start, end time.Time) ([]*models.CategoryWithTotal, error) {
+	return s.repo.GetCategorySummary(ctx, start, end)
 }
 
-func (s *TransactionService) GetCurrentMonthCategorySummary() ([]*models.CategoryWithTotal, error) {
+// GetCategoryStats returns the category summary for the period with the
+// average and median transaction amount of each category filled in.
+// Refunds count towards totals but are left out of average and median,
+// which describe individual purchases.
+func (s *TransactionService) GetCategoryStats(ctx context.Context, start, end time.Time) ([]*models.CategoryWithTotal, error) {
+	summary, err := s.repo.GetCategorySummary(ctx, start, end)
+	if err != nil {
+		return nil, err
+	}
+	
+	transactions, err := s.repo.GetByFilter(ctx, &models.TransactionFilter{
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		return nil, err
+	}
+	
+	amounts := make(map[uint][]float64)
+	for _, tx := range transactions {
+		if tx.IsRefund {
+			continue
+		}
+		if tx.IsSplit() {
+			for _, line := range tx.Splits {
+				amounts[line.CategoryID] = append(amounts[line.CategoryID], line.AmountUSD)
+			}
+			continue
+		}
+		amounts[tx.CategoryID] = append(amounts[tx.CategoryID], tx.AmountUSD)
+	}
+	
+	for _, cat := range summary {
+		values := amounts[cat.ID]
+		if len(values) == 0 {
+			continue
+		}
+		
+		var sum float64
+		for _, v := range values {
+			sum += v
+		}
+		cat.Average = sum / float64(len(values))
+		cat.Median = median(values)
+	}
+	
+	return summary, nil
+}
+
+// median returns the middle value of values, averaging the two middle
+// values when the count is even. values is sorted in place.
+func median(values []float64) float64 {
+	if len(values) == 0 {
+		return 0
+	}
+	
+	sort.Float64s(values)
+	mid := len(values) / 2
+	if len(values)%2 == 0 {
+		return (values[mid-1] + values[mid]) / 2
+	}
+	return values[mid]
+}
+
+func (s *TransactionService) GetCurrentMonthCategorySummary(ctx context.Context) ([]*models.CategoryWithTotal, error) {
 	now := time.Now()
 	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 	end := start.AddDate(0, 1, 0).Add(-time.Second)
 	
-	return s.repo.GetCategorySummary(start, end)
+	return s.repo.GetCategorySummary(ctx, start, end)
+}
+
+// GetCategoryTrends returns each category's total for the month containing
+// now and for the month before, keyed by category ID. Categories without
+// transactions in either month are left out.
+func (s *TransactionService) GetCategoryTrends(ctx context.Context, now time.Time) (map[uint]*models.CategoryTrend, error) {
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	end := start.AddDate(0, 1, 0).Add(-time.Second)
+	
+	current, err := s.repo.GetCategorySummary(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get this month's totals: %w", err)
+	}
+	previous, err := s.repo.GetCategorySummary(ctx, start.AddDate(0, -1, 0), start.Add(-time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last month's totals: %w", err)
+	}
+	
+	trends := make(map[uint]*models.CategoryTrend)
+	trendFor := func(id uint) *models.CategoryTrend {
+		if trends[id] == nil {
+			trends[id] = &models.CategoryTrend{}
+		}
+		return trends[id]
+	}
+	for _, cat := range current {
+		trendFor(cat.ID).CurrentMonth = cat.Total
+	}
+	for _, cat := range previous {
+		trendFor(cat.ID).PreviousMonth = cat.Total
+	}
+	
+	return trends, nil
+}
+
+// GetYearOverYear compares the month with the same month of the year
+// before, overall and for each category with transactions in either. The
+// categories are ordered by this year's total, then last year's.
+func (s *TransactionService) GetYearOverYear(ctx context.Context, year int, month time.Month) (*models.YearOverYear, error) {
+	start := time.Date(year, month, 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0).Add(-time.Second)
+	prevStart := start.AddDate(-1, 0, 0)
+	prevEnd := prevStart.AddDate(0, 1, 0).Add(-time.Second)
+	
+	current, err := s.repo.GetSummary(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get this year's summary: %w", err)
+	}
+	previous, err := s.repo.GetSummary(ctx, prevStart, prevEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last year's summary: %w", err)
+	}
+	
+	currentTotals, err := s.repo.GetCategorySummary(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get this year's totals: %w", err)
+	}
+	previousTotals, err := s.repo.GetCategorySummary(ctx, prevStart, prevEnd)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get last year's totals: %w", err)
+	}
+	
+	byID := make(map[uint]*models.CategoryComparison)
+	var categories []*models.CategoryComparison
+	comparisonFor := func(cat *models.CategoryWithTotal) *models.CategoryComparison {
+		if byID[cat.ID] == nil {
+			byID[cat.ID] = &models.CategoryComparison{
+				Category: cat.Category,
+				IsNew:    cat.CreatedAt.After(prevEnd),
+			}
+			categories = append(categories, byID[cat.ID])
+		}
+		return byID[cat.ID]
+	}
+	for _, cat := range currentTotals {
+		comparisonFor(cat).Current = cat.Total
+	}
+	for _, cat := range previousTotals {
+		comparisonFor(cat).Previous = cat.Total
+	}
+	
+	sort.SliceStable(categories, func(i, j int) bool {
+		if categories[i].Current != categories[j].Current {
+			return categories[i].Current > categories[j].Current
+		}
+		return categories[i].Previous > categories[j].Previous
+	})
+	
+	return &models.YearOverYear{
+		Current:    current,
+		Previous:   previous,
+		Categories: categories,
+	}, nil
+}
+
+// yearlyTopCategories is how many expense categories the yearly report lists
+const yearlyTopCategories = 3
+
+// GetYearlyReport sums up the year month by month, along with its biggest
+// expense categories and single expense. Months after the one containing
+// now are left nil.
+func (s *TransactionService) GetYearlyReport(ctx context.Context, year int, now time.Time) (*models.YearlyReport, error) {
+	start := time.Date(year, 1, 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(1, 0, 0).Add(-time.Second)
+	
+	report := &models.YearlyReport{Year: year}
+	
+	totals, err := s.repo.GetMonthlyTotals(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monthly totals: %w", err)
+	}
+	thisMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	for i := range report.Months {
+		month := start.AddDate(0, i, 0)
+		if month.After(thisMonth) {
+			break
+		}
+		report.Months[i] = &models.MonthlyTotal{Month: month}
+	}
+	for _, total := range totals {
+		report.Months[total.Month.Month()-1] = total
+	}
+	
+	report.Total, err = s.repo.GetSummary(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get year summary: %w", err)
+	}
+	
+	categories, err := s.repo.GetCategorySummary(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get category totals: %w", err)
+	}
+	for _, cat := range categories {
+		if len(report.TopCategories) == yearlyTopCategories {
+			break
+		}
+		if cat.Type == models.TransactionTypeExpense && cat.Total > 0 {
+			report.TopCategories = append(report.TopCategories, cat)
+		}
+	}
+	
+	report.Largest, err = s.repo.GetLargestExpense(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get largest expense: %w", err)
+	}
+	
+	return report, nil
+}
+
+// suggestionSampleSize bounds how many recent transactions SuggestCategory
+// looks through
+const suggestionSampleSize = 1000
+
+// minSuggestionLength is the shortest description that gets a suggestion
+const minSuggestionLength = 3
+
+// SuggestCategory returns the category most often used for recent
+// transactions of txType whose description contains, or is the start of,
+// the given one (ignoring case). Ties go to the most recently used
+// category. It returns nil when nothing matches.
+func (s *TransactionService) SuggestCategory(ctx context.Context, description string, txType models.TransactionType) (*models.Category, error) {
+	query := strings.ToLower(strings.TrimSpace(description))
+	if len([]rune(query)) < minSuggestionLength {
+		return nil, nil
+	}
+	
+	recent, err := s.repo.GetRecentTransactions(ctx, suggestionSampleSize)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recent transactions: %w", err)
+	}
+	
+	counts := make(map[uint]int)
+	var best *models.Category
+	for _, tx := range recent {
+		past := strings.ToLower(strings.TrimSpace(tx.Description))
+		if tx.Type != txType || len([]rune(past)) < minSuggestionLength || tx.Category.ID == 0 {
+			continue
+		}
+		if !strings.Contains(past, query) && !strings.HasPrefix(query, past) {
+			continue
+		}
+		
+		counts[tx.CategoryID]++
+		// Transactions come newest first, so only a strictly higher count
+		// replaces the current best
+		if best == nil || counts[tx.CategoryID] > counts[best.ID] {
+			category := tx.Category
+			best = &category
+		}
+	}
+	
+	return best, nil
+}
+
+// ParseQuickEntry reads a one-line entry such as "Food 12.50 coffee": a
+// category name, an amount and an optional description. The category is
+// matched among categories ignoring case, by its full name or else by a
+// prefix only one of them has, and decides whether the transaction is an
+// income or an expense. The name ends at the first field that reads as an
+// amount with EvalAmount; the last such field is the amount, so in
+// "Food 2 coffees 7.50" it is 7.50 and the description "2 coffees". The
+// amount is in the default currency and the date is now. The transaction is
+// not saved.
+func (s *TransactionService) ParseQuickEntry(line string, categories []*models.Category) (*models.Transaction, error) {
+	fields := strings.Fields(line)
+	nameEnd, amountIndex := -1, -1
+	var amount float64
+	for i, field := range fields {
+		value, err := EvalAmount(strings.TrimPrefix(field, "$"))
+		if err == nil && value > 0 {
+			if nameEnd < 0 {
+				nameEnd = i
+			}
+			amountIndex, amount = i, value
+		}
+	}
+	if amountIndex < 0 {
+		return nil, fmt.Errorf("no amount found, expected e.g. \"Food 12.50 coffee\"")
+	}
+	if nameEnd == 0 {
+		return nil, fmt.Errorf("start with a category, e.g. \"Food 12.50 coffee\"")
+	}
+
+	var description []string
+	for i, field := range fields[nameEnd:] {
+		if nameEnd+i != amountIndex {
+			description = append(description, field)
+		}
+	}
+
+	category, err := matchCategory(strings.Join(fields[:nameEnd], " "), categories)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.Transaction{
+		Type:        category.Type,
+		Amount:      amount,
+		Currency:    s.currencyService.GetDefaultCurrency(),
+		CategoryID:  category.ID,
+		Category:    *category,
+		Description: strings.Join(description, " "),
+		Date:        time.Now(),
+	}, nil
+}
+
+// ParseAllocations reads how to allocate an income transaction from a line
+// such as "Taxes 1200, Savings 20%": comma-separated names, each followed by
+// an amount in the transaction's currency or a percentage of it. An empty
+// line means no allocations. The allocations are not saved.
+func ParseAllocations(line string, tx *models.Transaction) ([]*models.IncomeAllocation, error) {
+	var allocations []*models.IncomeAllocation
+	for _, part := range strings.Split(line, ",") {
+		fields := strings.Fields(part)
+		if len(fields) == 0 {
+			continue
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("%q needs a name and an amount, e.g. \"Savings 1000\"", strings.TrimSpace(part))
+		}
+
+		last := fields[len(fields)-1]
+		var amount float64
+		if percent, ok := strings.CutSuffix(last, "%"); ok {
+			value, err := strconv.ParseFloat(percent, 64)
+			if err != nil || value <= 0 || value > 100 {
+				return nil, fmt.Errorf("invalid percentage %q", last)
+			}
+			scale := math.Pow10(models.CurrencyDecimals(tx.Currency))
+			amount = math.Round(tx.Amount*value/100*scale) / scale
+		} else {
+			value, err := strconv.ParseFloat(strings.TrimPrefix(last, "$"), 64)
+			if err != nil || value <= 0 {
+				return nil, fmt.Errorf("invalid amount %q", last)
+			}
+			amount = value
+		}
+
+		allocations = append(allocations, &models.IncomeAllocation{
+			Name:   strings.Join(fields[:len(fields)-1], " "),
+			Amount: amount,
+		})
+	}
+	return allocations, nil
+}
+
+// SplitAmounts works out the amounts of a split transaction's lines from
+// what was typed for each, such as "12.50" or "3 * 4.99", rounded to the
+// currency's smallest unit. The last line may be left empty to take what
+// the others leave of amount, so it also absorbs their rounding: 10 split
+// as "10/3", "10/3" and "" gives 3.33, 3.33 and 3.34.
+func SplitAmounts(amount float64, currency string, exprs []string) ([]float64, error) {
+	scale := math.Pow10(models.CurrencyDecimals(currency))
+	amounts := make([]float64, len(exprs))
+	others := make([]models.SplitItem, 0, len(exprs))
+	for i, expr := range exprs {
+		if strings.TrimSpace(expr) == "" && i == len(exprs)-1 {
+			amounts[i] = models.SplitRemainder(amount, currency, others)
+			if amounts[i] <= 0 {
+				return nil, fmt.Errorf("the other lines already take the whole %.*f %s", models.CurrencyDecimals(currency), amount, currency)
+			}
+			break
+		}
+		value, err := EvalAmount(expr)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: invalid amount: %w", i+1, err)
+		}
+		value = math.Round(value*scale) / scale
+		if value <= 0 {
+			return nil, fmt.Errorf("line %d: amount must be positive", i+1)
+		}
+		amounts[i] = value
+		others = append(others, models.SplitItem{Amount: value})
+	}
+	return amounts, nil
+}
+
+// matchCategory finds the category named name, ignoring case, or else the
+// only one whose name starts with it
+func matchCategory(name string, categories []*models.Category) (*models.Category, error) {
+	query := strings.ToLower(name)
+	var matches []*models.Category
+	for _, category := range categories {
+		lower := strings.ToLower(category.Name)
+		if lower == query {
+			return category, nil
+		}
+		if strings.HasPrefix(lower, query) {
+			matches = append(matches, category)
+		}
+	}
+
+	switch len(matches) {
+	case 0:
+		return nil, fmt.Errorf("unknown category %q", name)
+	case 1:
+		return matches[0], nil
+	default:
+		names := make([]string, len(matches))
+		for i, match := range matches {
+			names[i] = match.Name
+		}
+		return nil, fmt.Errorf("%q could be %s", name, strings.Join(names, ", "))
+	}
+}
+
+// ParseRelativeDate reads a date typed as YYYY-MM-DD or as a shortcut
+// relative to now: "today", "yesterday", or a number of days, weeks,
+// months or years ago such as "-3d", "-2w", "-1m" or "-1y". A month or
+// year ago on a day that month doesn't have is its last day. The result is
+// the start of that day.
+func ParseRelativeDate(input string, now time.Time) (time.Time, error) {
+	value := strings.ToLower(strings.TrimSpace(input))
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, now.Location())
+
+	switch value {
+	case "":
+		return time.Time{}, fmt.Errorf("date is required")
+	case "today":
+		return today, nil
+	case "yesterday":
+		return today.AddDate(0, 0, -1), nil
+	}
+
+	if strings.HasPrefix(value, "-") && len(value) > 2 {
+		n, err := strconv.Atoi(value[1 : len(value)-1])
+		if err == nil && n >= 0 {
+			switch value[len(value)-1] {
+			case 'd':
+				return today.AddDate(0, 0, -n), nil
+			case 'w':
+				return today.AddDate(0, 0, -7*n), nil
+			case 'm':
+				return monthsBefore(today, n), nil
+			case 'y':
+				return monthsBefore(today, 12*n), nil
+			}
+		}
+	}
+
+	date, err := time.ParseInLocation("2006-01-02", value, now.Location())
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, use YYYY-MM-DD, today, yesterday or e.g. -7d, -2w, -1m", input)
+	}
+	return date, nil
+}
+
+// monthsBefore returns the same day n months before date, or the last day
+// of that month when it is shorter, e.g. February 28th a month before
+// March 31st
+func monthsBefore(date time.Time, n int) time.Time {
+	first := time.Date(date.Year(), date.Month()-time.Month(n), 1, 0, 0, 0, 0, date.Location())
+	lastDay := first.AddDate(0, 1, -1).Day()
+	return first.AddDate(0, 0, min(date.Day(), lastDay)-1)
 }
 
-func (s *TransactionService) GetRecentTransactions(limit int) ([]*models.Transaction, error) {
-	return s.repo.GetRecentTransactions(limit)
+// GetDateRange returns the dates of the oldest and newest transactions
+func (s *TransactionService) GetDateRange(ctx context.Context) (time.Time, time.Time, error) {
+	return s.repo.GetDateRange(ctx)
 }
 
-func (s *TransactionService) ImportTransactions(transactions []*models.Transaction) error {
+func (s *TransactionService) GetRecentTransactions(ctx context.Context, limit int) ([]*models.Transaction, error) {
+	return s.repo.GetRecentTransactions(ctx, limit)
+}
+
+// ImportTransactions stores all transactions or, if any of them fails
+// validation or conversion, none of them
+func (s *TransactionService) ImportTransactions(ctx context.Context, transactions []*models.Transaction) error {
 	for _, tx := range transactions {
-		if err := s.Create(tx); err != nil {
-			return fmt.Errorf("failed to import transaction: %w", err)
+		if err := tx.Validate(); err != nil {
+			return fmt.Errorf("failed to import transaction: validation failed: %w", err)
+		}
+
+		if err := s.setAmountUSD(ctx, tx); err != nil {
+			return fmt.Errorf("failed to import transaction: failed to convert currency: %w", err)
 		}
 	}
+
+	if err := s.repo.CreateMany(ctx, transactions); err != nil {
+		return fmt.Errorf("failed to import transactions: %w", err)
+	}
 	return nil
 }
 
-func (s *TransactionService) CountByCurrency(currency string) (int64, error) {
-	return s.repo.CountByCurrency(currency)
+func (s *TransactionService) CountByCurrency(ctx context.Context, currency string) (int64, error) {
+	return s.repo.CountByCurrency(ctx, currency)
+}
+
+// GetCurrencyBreakdown returns the transaction count and volume of every
+// currency that has transactions, most used first
+func (s *TransactionService) GetCurrencyBreakdown(ctx context.Context) ([]*models.CurrencyStat, error) {
+	stats, err := s.repo.GetCurrencyStats(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get currency breakdown: %w", err)
+	}
+	return stats, nil
+}
+
+// GetTopDescriptions groups the period's expenses by description, refunds
+// netted, and returns up to limit of them ordered by total, largest first,
+// and then by count, or with models.DescriptionsByCount the other way
+// round. Descriptions are compared trimmed and case-folded with runs of
+// spaces collapsed, so "Starbucks" and "starbucks " are one place.
+// Expenses without a description are left out.
+func (s *TransactionService) GetTopDescriptions(ctx context.Context, start, end time.Time, limit int, order models.DescriptionOrder) ([]*models.DescriptionStat, error) {
+	transactions, err := s.repo.GetByFilter(ctx, &models.TransactionFilter{
+		Type:      models.TransactionTypeExpense,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	byKey := make(map[string]*models.DescriptionStat)
+	var stats []*models.DescriptionStat
+	latest := make(map[string]time.Time)
+	for _, tx := range transactions {
+		description := strings.Join(strings.Fields(tx.Description), " ")
+		if description == "" {
+			continue
+		}
+		key := strings.ToLower(description)
+		stat := byKey[key]
+		if stat == nil {
+			stat = &models.DescriptionStat{}
+			byKey[key] = stat
+			stats = append(stats, stat)
+		}
+		if !tx.Date.Before(latest[key]) {
+			stat.Description = description
+			latest[key] = tx.Date
+		}
+
+		amount := tx.AmountUSD
+		if tx.IsRefund {
+			amount = -amount
+		}
+		stat.Total += amount
+		stat.Count++
+	}
+
+	sort.SliceStable(stats, func(i, j int) bool {
+		if order == models.DescriptionsByCount && stats[i].Count != stats[j].Count {
+			return stats[i].Count > stats[j].Count
+		}
+		if stats[i].Total != stats[j].Total {
+			return stats[i].Total > stats[j].Total
+		}
+		return stats[i].Count > stats[j].Count
+	})
+	if limit > 0 && len(stats) > limit {
+		stats = stats[:limit]
+	}
+	return stats, nil
+}
+
+// GetTagTotals groups the period's expenses by the tag pattern's match in
+// their description, refunds netted: by the first capture group, or the
+// whole match when the pattern has none. Expenses without a match are
+// totalled as models.UntaggedLabel, which comes last; the tags are ordered
+// by total, largest first.
+func (s *TransactionService) GetTagTotals(ctx context.Context, start, end time.Time, pattern string) ([]*models.TagTotal, error) {
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("invalid tag pattern: %w", err)
+	}
+
+	transactions, err := s.repo.GetByFilter(ctx, &models.TransactionFilter{
+		Type:      models.TransactionTypeExpense,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	byTag := make(map[string]*models.TagTotal)
+	var tags []*models.TagTotal
+	untagged := &models.TagTotal{Tag: models.UntaggedLabel}
+	for _, tx := range transactions {
+		total := untagged
+		if match := re.FindStringSubmatch(tx.Description); match != nil {
+			tag := match[0]
+			if len(match) > 1 {
+				tag = match[1]
+			}
+			if tag = strings.TrimSpace(tag); tag != "" {
+				if byTag[tag] == nil {
+					byTag[tag] = &models.TagTotal{Tag: tag}
+					tags = append(tags, byTag[tag])
+				}
+				total = byTag[tag]
+			}
+		}
+
+		amount := tx.AmountUSD
+		if tx.IsRefund {
+			amount = -amount
+		}
+		total.Total += amount
+		total.Count++
+	}
+
+	sort.SliceStable(tags, func(i, j int) bool {
+		if tags[i].Total != tags[j].Total {
+			return tags[i].Total > tags[j].Total
+		}
+		return tags[i].Tag < tags[j].Tag
+	})
+	if untagged.Count > 0 {
+		tags = append(tags, untagged)
+	}
+	return tags, nil
+}
+
+// GetAmountHistogram counts the period's expenses, refunds excluded, by the
+// size of their USD amount, in the ranges between the ascending bounds in
+// buckets. The counts are keyed by models.AmountBucketLabels, and ranges
+// without expenses count zero.
+func (s *TransactionService) GetAmountHistogram(ctx context.Context, start, end time.Time, buckets []float64) (map[string]int, error) {
+	if len(buckets) == 0 {
+		return nil, fmt.Errorf("at least one bucket bound is required")
+	}
+	for i, bound := range buckets {
+		if bound <= 0 || (i > 0 && bound <= buckets[i-1]) {
+			return nil, fmt.Errorf("bucket bounds must be positive and ascending")
+		}
+	}
+
+	transactions, err := s.repo.GetByFilter(ctx, &models.TransactionFilter{
+		Type:      models.TransactionTypeExpense,
+		StartDate: start,
+		EndDate:   end,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transactions: %w", err)
+	}
+
+	labels := models.AmountBucketLabels(buckets)
+	histogram := make(map[string]int, len(labels))
+	for _, label := range labels {
+		histogram[label] = 0
+	}
+	for _, tx := range transactions {
+		if tx.IsRefund {
+			continue
+		}
+		// The first bound above the amount is the range's upper end
+		bucket := sort.SearchFloat64s(buckets, tx.AmountUSD)
+		if bucket < len(buckets) && buckets[bucket] == tx.AmountUSD {
+			bucket++
+		}
+		histogram[labels[bucket]]++
+	}
+	return histogram, nil
 }
 
-func (s *TransactionService) GetCurrentMonthBurnRate() (*models.BurnRateSummary, error) {
+// GetDailyTotals returns the expenses of each day in the period that has
+// any, oldest first
+func (s *TransactionService) GetDailyTotals(ctx context.Context, start, end time.Time) ([]*models.DailyTotal, error) {
+	totals, err := s.repo.GetDailyTotals(ctx, start, end)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get daily totals: %w", err)
+	}
+	return totals, nil
+}
+
+func (s *TransactionService) GetCurrentMonthBurnRate(ctx context.Context) (*models.BurnRateSummary, error) {
 	now := time.Now()
 	startOfMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
 	endOfMonth := startOfMonth.AddDate(0, 1, 0).Add(-time.Second)
@@ -149,7 +1389,7 @@ func (s *TransactionService) GetCurrentMonthBurnRate() (*models.BurnRateSummary,
 		EndDate:   endOfMonth,
 	}
 	
-	transactions, err := s.repo.GetByFilter(&filter)
+	transactions, err := s.repo.GetByFilter(ctx, &filter)
 	if err != nil {
 		return nil, fmt.Errorf("failed to get transactions: %w", err)
 	}
@@ -159,25 +1399,27 @@ func (s *TransactionService) GetCurrentMonthBurnRate() (*models.BurnRateSummary,
 	
 	for _, tx := range transactions {
 		if tx.RecurringTransactionID != nil {
-			burnRate.RecurringExpenses += tx.AmountUSD
+			burnRate.RecurringExpenses += tx.NetAmountUSD()
 			burnRate.RecurringCount++
 		} else {
-			burnRate.OneTimeExpenses += tx.AmountUSD
+			burnRate.OneTimeExpenses += tx.NetAmountUSD()
 			burnRate.OneTimeCount++
 		}
 	}
 	
+	// Refunds can outweigh one-time spending but never make the burn negative
+	burnRate.OneTimeExpenses = math.Max(burnRate.OneTimeExpenses, 0)
 	burnRate.TotalBurn = burnRate.RecurringExpenses + burnRate.OneTimeExpenses
 	
 	// Calculate projections based on active recurring transactions
 	if s.recurringRepo != nil {
-		activeRecurring, err := s.recurringRepo.GetActive()
+		activeRecurring, err := s.recurringRepo.GetActive(ctx)
 		if err == nil {
 			monthlyProjection := 0.0
 			for _, recurring := range activeRecurring {
 				if recurring.Type == models.TransactionTypeExpense {
 					// Convert to monthly amount based on frequency
-					monthlyAmount := s.calculateMonthlyAmount(recurring)
+					monthlyAmount := monthlyAmountUSD(ctx, s.currencyService, recurring)
 					monthlyProjection += monthlyAmount
 				}
 			}
@@ -193,26 +1435,14 @@ func (s *TransactionService) GetCurrentMonthBurnRate() (*models.BurnRateSummary,
 	return burnRate, nil
 }
 
-func (s *TransactionService) calculateMonthlyAmount(recurring *models.RecurringTransaction) float64 {
-	amount := recurring.Amount
-	if recurring.Currency != "USD" {
-		// Convert to USD if needed
-		if amountUSD, err := s.currencyService.ConvertToUSD(amount, recurring.Currency); err == nil {
+// monthlyAmountUSD is the recurring transaction's monthly amount in USD,
+// falling back to its own currency when there is no rate for it
+func monthlyAmountUSD(ctx context.Context, currencyService *CurrencyService, rt *models.RecurringTransaction) float64 {
+	amount := rt.MonthlyAmount()
+	if rt.Currency != "USD" {
+		if amountUSD, err := currencyService.ConvertToUSD(ctx, amount, rt.Currency); err == nil {
 			amount = amountUSD
 		}
 	}
-	
-	// Convert to monthly based on frequency
-	switch recurring.Frequency {
-	case models.FrequencyDaily:
-		return amount * 30.44 / float64(recurring.FrequencyValue) // Average days per month
-	case models.FrequencyWeekly:
-		return amount * 4.33 / float64(recurring.FrequencyValue) // Average weeks per month
-	case models.FrequencyMonthly:
-		return amount / float64(recurring.FrequencyValue)
-	case models.FrequencyYearly:
-		return amount / (12 * float64(recurring.FrequencyValue))
-	default:
-		return amount
-	}
+	return amount
 }
\ No newline at end of file
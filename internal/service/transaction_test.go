@@ -1,6 +1,9 @@
 package service
 
 import (
+	"os"
+	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -34,10 +37,11 @@ func TestTransactionService_Create(t *testing.T) {
 		Date:        time.Now(),
 	}
 	
-	err = service.Create(tx)
+	err = service.Create(t.Context(), tx)
 	require.NoError(t, err)
 	assert.Greater(t, tx.ID, uint(0))
 	assert.Equal(t, tx.Amount, tx.AmountUSD)
+	assert.Empty(t, tx.RateSource)
 }
 
 func TestTransactionService_CreateWithCurrency(t *testing.T) {
@@ -62,10 +66,122 @@ func TestTransactionService_CreateWithCurrency(t *testing.T) {
 		Date:        time.Now(),
 	}
 	
-	err = service.Create(tx)
+	err = service.Create(t.Context(), tx)
 	require.NoError(t, err)
 	assert.Greater(t, tx.ID, uint(0))
 	assert.InDelta(t, 27.23, tx.AmountUSD, 0.01)
+	
+	saved, err := service.GetByID(t.Context(), tx.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.RateSourceFixed, saved.RateSource)
+	
+	// Switching to USD leaves no rate behind
+	saved.Currency = "USD"
+	require.NoError(t, service.Update(t.Context(), saved))
+	assert.Empty(t, saved.RateSource)
+}
+
+func TestTransactionService_CreateWithManualUSD(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewTransactionRepository(db)
+	
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repo, NewCurrencyService(settingsService))
+	
+	category := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	
+	tx := &models.Transaction{
+		Type:        models.TransactionTypeExpense,
+		Amount:      100.00,
+		Currency:    "AED",
+		AmountUSD:   28.10,
+		ManualUSD:   true,
+		CategoryID:  category.ID,
+		Description: "Card payment with fees",
+		Date:        time.Now(),
+	}
+	require.NoError(t, service.Create(t.Context(), tx))
+	
+	saved, err := service.GetByID(t.Context(), tx.ID)
+	require.NoError(t, err)
+	assert.True(t, saved.ManualUSD)
+	assert.Equal(t, 28.10, saved.AmountUSD)
+	assert.Equal(t, models.RateSourceManual, saved.RateSource)
+	
+	t.Run("kept on update", func(t *testing.T) {
+		saved.Amount = 110.00
+		require.NoError(t, service.Update(t.Context(), saved))
+		assert.Equal(t, 28.10, saved.AmountUSD)
+	})
+	
+	t.Run("converted again when turned off", func(t *testing.T) {
+		saved.ManualUSD = false
+		require.NoError(t, service.Update(t.Context(), saved))
+		assert.InDelta(t, 29.95, saved.AmountUSD, 0.01)
+		assert.Equal(t, models.RateSourceFixed, saved.RateSource)
+	})
+	
+	t.Run("must be positive", func(t *testing.T) {
+		invalid := *tx
+		invalid.ID = 0
+		invalid.AmountUSD = 0
+		err := service.Create(t.Context(), &invalid)
+		assert.ErrorContains(t, err, "USD amount must be positive")
+	})
+	
+	t.Run("ignored for USD", func(t *testing.T) {
+		usd := &models.Transaction{
+			Type:       models.TransactionTypeExpense,
+			Amount:     40.00,
+			Currency:   "USD",
+			AmountUSD:  45.00,
+			ManualUSD:  true,
+			CategoryID: category.ID,
+			Date:       time.Now(),
+		}
+		require.NoError(t, service.Create(t.Context(), usd))
+		assert.False(t, usd.ManualUSD)
+		assert.Equal(t, 40.00, usd.AmountUSD)
+	})
+}
+
+func TestTransactionService_CategoryMustMatchType(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewTransactionRepository(db)
+	
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repo, NewCurrencyService(settingsService))
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	
+	tx := &models.Transaction{
+		Type:       models.TransactionTypeIncome,
+		Amount:     50.00,
+		Currency:   "USD",
+		CategoryID: food.ID,
+		Date:       time.Now(),
+	}
+	err = service.Create(t.Context(), tx)
+	assert.ErrorContains(t, err, "category 'Food' is for expense, not income transactions")
+	
+	tx.Type = models.TransactionTypeExpense
+	require.NoError(t, service.Create(t.Context(), tx))
+	
+	// Changing the type while editing needs a category of the new type
+	tx.Type = models.TransactionTypeIncome
+	err = service.Update(t.Context(), tx)
+	assert.ErrorContains(t, err, "not income transactions")
+	
+	tx.CategoryID = salary.ID
+	require.NoError(t, service.Update(t.Context(), tx))
+	
+	saved, err := service.GetByID(t.Context(), tx.ID)
+	require.NoError(t, err)
+	assert.Equal(t, models.TransactionTypeIncome, saved.Type)
+	assert.Equal(t, "Salary", saved.Category.Name)
 }
 
 func TestTransactionService_GetCurrentMonthSummary(t *testing.T) {
@@ -91,7 +207,7 @@ func TestTransactionService_GetCurrentMonthSummary(t *testing.T) {
 		Description: "Monthly salary",
 		Date:        time.Now(),
 	}
-	require.NoError(t, service.Create(income))
+	require.NoError(t, service.Create(t.Context(), income))
 	
 	// Create expense transactions
 	expense1 := &models.Transaction{
@@ -102,7 +218,7 @@ func TestTransactionService_GetCurrentMonthSummary(t *testing.T) {
 		Description: "Groceries",
 		Date:        time.Now(),
 	}
-	require.NoError(t, service.Create(expense1))
+	require.NoError(t, service.Create(t.Context(), expense1))
 	
 	expense2 := &models.Transaction{
 		Type:        models.TransactionTypeExpense,
@@ -112,10 +228,10 @@ func TestTransactionService_GetCurrentMonthSummary(t *testing.T) {
 		Description: "Lunch",
 		Date:        time.Now(),
 	}
-	require.NoError(t, service.Create(expense2))
+	require.NoError(t, service.Create(t.Context(), expense2))
 	
 	// Get summary
-	summary, err := service.GetCurrentMonthSummary()
+	summary, err := service.GetCurrentMonthSummary(t.Context())
 	require.NoError(t, err)
 	
 	assert.Equal(t, 5000.0, summary.TotalIncome)
@@ -124,6 +240,795 @@ func TestTransactionService_GetCurrentMonthSummary(t *testing.T) {
 	assert.Equal(t, 3, summary.Count)
 }
 
+func TestTransactionService_GetCategoryStats(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewTransactionRepository(db)
+	
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repo, NewCurrencyService(settingsService))
+	
+	food := test.CreateTestCategory(t, db, "Eating Out", models.TransactionTypeExpense)
+	rent := test.CreateTestCategory(t, db, "Rent", models.TransactionTypeExpense)
+	
+	for _, amount := range []float64{10, 20, 30, 100} {
+		test.CreateTestTransaction(t, db, amount, food.ID)
+	}
+	test.CreateTestTransaction(t, db, 1200, rent.ID)
+	
+	// Refunds reduce the total but are not purchases
+	refund := test.CreateTestTransaction(t, db, 5, food.ID)
+	require.NoError(t, db.Model(refund).Update("is_refund", true).Error)
+	
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, now.Location())
+	end := start.AddDate(0, 1, 0).Add(-time.Second)
+	
+	stats, err := service.GetCategoryStats(t.Context(), start, end)
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	
+	byName := make(map[string]*models.CategoryWithTotal)
+	for _, cat := range stats {
+		byName[cat.Name] = cat
+	}
+	
+	assert.Equal(t, 155.0, byName["Eating Out"].Total)
+	assert.Equal(t, 5, byName["Eating Out"].Count)
+	assert.Equal(t, 40.0, byName["Eating Out"].Average)
+	assert.Equal(t, 25.0, byName["Eating Out"].Median)
+	
+	assert.Equal(t, 1200.0, byName["Rent"].Average)
+	assert.Equal(t, 1200.0, byName["Rent"].Median)
+}
+
+func TestTransactionService_GetCategoryTrends(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewTransactionRepository(db)
+	
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repo, NewCurrencyService(settingsService))
+	
+	food := test.CreateTestCategory(t, db, "Eating Out", models.TransactionTypeExpense)
+	gym := test.CreateTestCategory(t, db, "Gym", models.TransactionTypeExpense)
+	books := test.CreateTestCategory(t, db, "Books", models.TransactionTypeExpense)
+	
+	now := time.Date(2025, time.March, 15, 12, 0, 0, 0, time.Local)
+	create := func(categoryID uint, amount float64, date time.Time) {
+		tx := test.CreateTestTransaction(t, db, amount, categoryID)
+		require.NoError(t, db.Model(tx).Update("date", date).Error)
+	}
+	create(food.ID, 120, now)
+	create(food.ID, 100, time.Date(2025, time.February, 28, 20, 0, 0, 0, time.Local))
+	create(gym.ID, 40, time.Date(2025, time.February, 1, 0, 0, 0, 0, time.Local))
+	create(books.ID, 25, time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local))
+	// Outside both months
+	create(food.ID, 500, time.Date(2025, time.January, 31, 12, 0, 0, 0, time.Local))
+	
+	trends, err := service.GetCategoryTrends(t.Context(), now)
+	require.NoError(t, err)
+	require.Len(t, trends, 3)
+	
+	assert.Equal(t, 120.0, trends[food.ID].CurrentMonth)
+	assert.Equal(t, 100.0, trends[food.ID].PreviousMonth)
+	assert.InDelta(t, 20.0, trends[food.ID].Change(), 0.001)
+	
+	assert.Equal(t, 0.0, trends[gym.ID].CurrentMonth)
+	assert.Equal(t, -100.0, trends[gym.ID].Change())
+	
+	// Nothing to compare against last month
+	assert.Equal(t, 25.0, trends[books.ID].CurrentMonth)
+	assert.Equal(t, 0.0, trends[books.ID].Change())
+}
+
+func TestTransactionService_RoundsConvertedAmounts(t *testing.T) {
+	db := test.SetupTestDB(t)
+	txRepo := repository.NewTransactionRepository(db)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	require.NoError(t, settingsService.SetFixedRate("EUR", 3))
+	require.NoError(t, settingsService.SetRoundingMode(models.RoundDown))
+	currencyService := NewCurrencyService(settingsService)
+	service := NewTransactionService(txRepo, currencyService)
+	recurringService := NewRecurringTransactionService(repository.NewRecurringTransactionRepository(db), txRepo, currencyService)
+	
+	category := test.CreateTestCategory(t, db, "Coffee", models.TransactionTypeExpense)
+	now := time.Now()
+	
+	// 10 EUR is 3.333… USD, stored as 3.33
+	var created []*models.Transaction
+	for i := 0; i < 3; i++ {
+		tx := &models.Transaction{
+			Type:        models.TransactionTypeExpense,
+			Amount:      10,
+			Currency:    "EUR",
+			CategoryID:  category.ID,
+			Description: "Flat white",
+			Date:        now,
+		}
+		require.NoError(t, service.Create(t.Context(), tx))
+		assert.Equal(t, 3.33, tx.AmountUSD)
+		created = append(created, tx)
+	}
+	
+	// 5 EUR is 1.666…, which rounds down to 1.66 rather than half up to 1.67
+	created[0].Amount = 5
+	require.NoError(t, service.Update(t.Context(), created[0]))
+	stored, err := txRepo.GetByID(t.Context(), created[0].ID)
+	require.NoError(t, err)
+	assert.Equal(t, 1.66, stored.AmountUSD)
+	
+	// So is a USD amount entered by hand
+	created[1].ManualUSD = true
+	created[1].AmountUSD = 3.339
+	require.NoError(t, service.Update(t.Context(), created[1]))
+	stored, err = txRepo.GetByID(t.Context(), created[1].ID)
+	require.NoError(t, err)
+	assert.Equal(t, 3.33, stored.AmountUSD)
+	
+	// And the transactions generated from a recurring one
+	rt := &models.RecurringTransaction{
+		Type:           models.TransactionTypeExpense,
+		Amount:         20,
+		Currency:       "EUR",
+		CategoryID:     category.ID,
+		Description:    "Coffee beans",
+		Frequency:      models.FrequencyMonthly,
+		FrequencyValue: 1,
+		StartDate:      now.AddDate(0, -1, 0),
+		NextDueDate:    now,
+		IsActive:       true,
+	}
+	require.NoError(t, repository.NewRecurringTransactionRepository(db).Create(t.Context(), rt))
+	processed, err := recurringService.ProcessDueTransactions(t.Context(), now)
+	require.NoError(t, err)
+	require.Equal(t, 1, processed)
+	
+	// The month's total is the sum of the stored cents, with no drift
+	summary, err := service.GetMonthSummary(t.Context(), now.Year(), now.Month())
+	require.NoError(t, err)
+	assert.Equal(t, 14.98, summary.TotalExpenses)
+}
+
+func TestTransactionService_GetYearOverYear(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	
+	food := test.CreateTestCategory(t, db, "Groceries", models.TransactionTypeExpense)
+	gym := test.CreateTestCategory(t, db, "Gym", models.TransactionTypeExpense)
+	pets := test.CreateTestCategory(t, db, "Pets", models.TransactionTypeExpense)
+	longAgo := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.Local)
+	require.NoError(t, db.Model(&models.Category{}).Where("id IN ?", []uint{food.ID, gym.ID}).Update("created_at", longAgo).Error)
+	require.NoError(t, db.Model(pets).Update("created_at", time.Date(2024, time.June, 1, 0, 0, 0, 0, time.Local)).Error)
+	
+	create := func(categoryID uint, amount float64, date time.Time) {
+		tx := test.CreateTestTransaction(t, db, amount, categoryID)
+		require.NoError(t, db.Model(tx).Update("date", date).Error)
+	}
+	create(food.ID, 300, time.Date(2025, time.March, 10, 12, 0, 0, 0, time.Local))
+	create(food.ID, 250, time.Date(2024, time.March, 31, 20, 0, 0, 0, time.Local))
+	create(gym.ID, 40, time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local))
+	create(pets.ID, 60, time.Date(2025, time.March, 2, 12, 0, 0, 0, time.Local))
+	// Outside both months
+	create(food.ID, 900, time.Date(2025, time.February, 28, 12, 0, 0, 0, time.Local))
+	create(food.ID, 900, time.Date(2024, time.April, 1, 12, 0, 0, 0, time.Local))
+	
+	yoy, err := service.GetYearOverYear(t.Context(), 2025, time.March)
+	require.NoError(t, err)
+	
+	assert.Equal(t, 360.0, yoy.Current.TotalExpenses)
+	assert.Equal(t, 290.0, yoy.Previous.TotalExpenses)
+	
+	require.Len(t, yoy.Categories, 3)
+	assert.Equal(t, food.ID, yoy.Categories[0].ID)
+	assert.Equal(t, 50.0, yoy.Categories[0].Delta())
+	assert.InDelta(t, 20.0, yoy.Categories[0].Change(), 0.001)
+	assert.False(t, yoy.Categories[0].IsNew)
+	
+	// Created since last March, so there is nothing to compare against
+	assert.Equal(t, pets.ID, yoy.Categories[1].ID)
+	assert.True(t, yoy.Categories[1].IsNew)
+	assert.Equal(t, 0.0, yoy.Categories[1].Change())
+	
+	assert.Equal(t, gym.ID, yoy.Categories[2].ID)
+	assert.Equal(t, -40.0, yoy.Categories[2].Delta())
+	assert.Equal(t, -100.0, yoy.Categories[2].Change())
+}
+
+func TestTransactionService_GetYearlyReport(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	rent := test.CreateTestCategory(t, db, "Rent", models.TransactionTypeExpense)
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	travel := test.CreateTestCategory(t, db, "Travel", models.TransactionTypeExpense)
+	fun := test.CreateTestCategory(t, db, "Fun", models.TransactionTypeExpense)
+	
+	create := func(categoryID uint, amount float64, date time.Time) *models.Transaction {
+		tx := test.CreateTestTransaction(t, db, amount, categoryID)
+		require.NoError(t, db.Model(tx).Update("date", date).Error)
+		return tx
+	}
+	for month := time.January; month <= time.March; month++ {
+		paycheck := create(salary.ID, 4000, time.Date(2025, month, 1, 9, 0, 0, 0, time.Local))
+		require.NoError(t, db.Model(paycheck).Update("type", models.TransactionTypeIncome).Error)
+		create(rent.ID, 1500, time.Date(2025, month, 2, 9, 0, 0, 0, time.Local))
+	}
+	create(food.ID, 300, time.Date(2025, time.February, 10, 12, 0, 0, 0, time.Local))
+	flight := create(travel.ID, 2000, time.Date(2025, time.March, 12, 12, 0, 0, 0, time.Local))
+	create(fun.ID, 50, time.Date(2025, time.March, 13, 12, 0, 0, 0, time.Local))
+	// Another year
+	create(travel.ID, 5000, time.Date(2024, time.December, 24, 12, 0, 0, 0, time.Local))
+	
+	report, err := service.GetYearlyReport(t.Context(), 2025, time.Date(2025, time.April, 20, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	
+	// January to April have started, April without transactions yet
+	assert.Equal(t, 4, report.MonthsElapsed())
+	require.NotNil(t, report.Months[3])
+	assert.Equal(t, 0.0, report.Months[3].Expenses)
+	assert.Nil(t, report.Months[4])
+	assert.Nil(t, report.Months[11])
+	
+	assert.Equal(t, 1800.0, report.Months[1].Expenses)
+	assert.Equal(t, 2200.0, report.Months[1].Net())
+	assert.Equal(t, 12000.0, report.Total.TotalIncome)
+	assert.Equal(t, 6850.0, report.Total.TotalExpenses)
+	assert.Equal(t, 1712.5, report.AverageExpenses())
+	
+	require.Len(t, report.TopCategories, 3)
+	assert.Equal(t, []string{"Rent", "Travel", "Food"},
+		[]string{report.TopCategories[0].Name, report.TopCategories[1].Name, report.TopCategories[2].Name})
+	
+	require.NotNil(t, report.Largest)
+	assert.Equal(t, flight.ID, report.Largest.ID)
+	
+	// A past year has all twelve months
+	report, err = service.GetYearlyReport(t.Context(), 2024, time.Date(2025, time.April, 20, 0, 0, 0, 0, time.Local))
+	require.NoError(t, err)
+	assert.Equal(t, 12, report.MonthsElapsed())
+}
+
+func TestTransactionService_SuggestCategory(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	
+	streaming := test.CreateTestCategory(t, db, "Streaming", models.TransactionTypeExpense)
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	
+	create := func(categoryID uint, description string, daysAgo int) {
+		require.NoError(t, service.Create(t.Context(), &models.Transaction{
+			Type:        models.TransactionTypeExpense,
+			Amount:      10,
+			Currency:    "USD",
+			CategoryID:  categoryID,
+			Description: description,
+			Date:        time.Now().AddDate(0, 0, -daysAgo),
+		}))
+	}
+	create(streaming.ID, "Netflix", 40)
+	create(streaming.ID, "Netflix subscription", 10)
+	create(food.ID, "Snacks for Netflix night", 1)
+	create(food.ID, "Groceries", 2)
+	
+	tests := []struct {
+		description string
+		want        string
+	}{
+		{"netflix", "Streaming"},       // most frequent category wins
+		{"  NETFLIX  ", "Streaming"},   // case and spacing are ignored
+		{"Netflix March", "Streaming"}, // past description is a prefix
+		{"snacks", "Food"},
+		{"groc", "Food"},
+		{"Spotify", ""},
+		{"ne", ""}, // too short to suggest anything
+	}
+	for _, tt := range tests {
+		suggestion, err := service.SuggestCategory(t.Context(), tt.description, models.TransactionTypeExpense)
+		require.NoError(t, err)
+		if tt.want == "" {
+			assert.Nil(t, suggestion, tt.description)
+			continue
+		}
+		require.NotNil(t, suggestion, tt.description)
+		assert.Equal(t, tt.want, suggestion.Name, tt.description)
+	}
+	
+	// On a tie the most recently used category is suggested
+	create(food.ID, "Netflix party pizza", 0)
+	suggestion, err := service.SuggestCategory(t.Context(), "netflix", models.TransactionTypeExpense)
+	require.NoError(t, err)
+	require.NotNil(t, suggestion)
+	assert.Equal(t, "Food", suggestion.Name)
+
+	// Only transactions of the same type count
+	refunds := test.CreateTestCategory(t, db, "Refunds", models.TransactionTypeIncome)
+	require.NoError(t, service.Create(t.Context(), &models.Transaction{
+		Type:        models.TransactionTypeIncome,
+		Amount:      10,
+		Currency:    "USD",
+		CategoryID:  refunds.ID,
+		Description: "Netflix refund",
+		Date:        time.Now(),
+	}))
+	suggestion, err = service.SuggestCategory(t.Context(), "netflix", models.TransactionTypeIncome)
+	require.NoError(t, err)
+	require.NotNil(t, suggestion)
+	assert.Equal(t, "Refunds", suggestion.Name)
+	suggestion, err = service.SuggestCategory(t.Context(), "groceries", models.TransactionTypeIncome)
+	require.NoError(t, err)
+	assert.Nil(t, suggestion)
+}
+
+func TestTransactionService_GetCurrencyBreakdown(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	
+	category := test.CreateTestCategory(t, db, "Travel", models.TransactionTypeExpense)
+	create := func(amount float64, currency string) *models.Transaction {
+		tx := &models.Transaction{
+			Type:       models.TransactionTypeExpense,
+			Amount:     amount,
+			Currency:   currency,
+			CategoryID: category.ID,
+			Date:       time.Now(),
+		}
+		require.NoError(t, service.Create(t.Context(), tx))
+		return tx
+	}
+	create(36.725, "AED")
+	create(73.45, "AED")
+	create(20, "USD")
+	deleted := create(500, "USD")
+	require.NoError(t, service.Delete(t.Context(), deleted.ID))
+	
+	stats, err := service.GetCurrencyBreakdown(t.Context())
+	require.NoError(t, err)
+	require.Len(t, stats, 2)
+	
+	assert.Equal(t, "AED", stats[0].Currency)
+	assert.Equal(t, 2, stats[0].Count)
+	assert.InDelta(t, 110.175, stats[0].Total, 0.001)
+	assert.InDelta(t, 30.0, stats[0].TotalUSD, 0.001)
+	
+	// Deleted transactions are not counted
+	assert.Equal(t, "USD", stats[1].Currency)
+	assert.Equal(t, 1, stats[1].Count)
+	assert.Equal(t, 20.0, stats[1].Total)
+	assert.Equal(t, 20.0, stats[1].TotalUSD)
+}
+
+func TestTransactionService_GetTagTotals(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	now := time.Now()
+	create := func(txType models.TransactionType, categoryID uint, description string, amount float64, refund bool) {
+		require.NoError(t, service.Create(t.Context(), &models.Transaction{
+			Type:        txType,
+			Amount:      amount,
+			Currency:    "USD",
+			CategoryID:  categoryID,
+			Description: description,
+			IsRefund:    refund,
+			Date:        now,
+		}))
+	}
+	create(models.TransactionTypeExpense, food.ID, "[ACME] Client lunch", 60, false)
+	create(models.TransactionTypeExpense, food.ID, "[ACME] Coffee", 15, false)
+	create(models.TransactionTypeExpense, food.ID, "[ACME] Coffee refund", 5, true)
+	create(models.TransactionTypeExpense, food.ID, "[Initech] Dinner", 80, false)
+	create(models.TransactionTypeExpense, food.ID, "Groceries", 40, false)
+	create(models.TransactionTypeIncome, salary.ID, "[ACME] Invoice", 1000, false)
+	
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0).Add(-time.Second)
+	
+	tags, err := service.GetTagTotals(t.Context(), start, end, `^\[(\w+)\]`)
+	require.NoError(t, err)
+	require.Len(t, tags, 3)
+	
+	// Largest first, refunds netted, income left out and untagged last
+	assert.Equal(t, "Initech", tags[0].Tag)
+	assert.Equal(t, 80.0, tags[0].Total)
+	assert.Equal(t, "ACME", tags[1].Tag)
+	assert.Equal(t, 70.0, tags[1].Total)
+	assert.Equal(t, 3, tags[1].Count)
+	assert.Equal(t, models.UntaggedLabel, tags[2].Tag)
+	assert.Equal(t, 40.0, tags[2].Total)
+	
+	// Without a capture group the whole match is the tag
+	tags, err = service.GetTagTotals(t.Context(), start, end, `(?i)coffee`)
+	require.NoError(t, err)
+	require.Len(t, tags, 2)
+	assert.Equal(t, "Coffee", tags[0].Tag)
+	assert.Equal(t, 10.0, tags[0].Total)
+	assert.Equal(t, models.UntaggedLabel, tags[1].Tag)
+	
+	_, err = service.GetTagTotals(t.Context(), start, end, `^\[(\w+`)
+	assert.ErrorContains(t, err, "invalid tag pattern")
+}
+
+func TestTransactionService_GetTopDescriptions(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	start := time.Date(time.Now().Year(), time.Now().Month(), 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0).Add(-time.Second)
+	create := func(txType models.TransactionType, categoryID uint, description string, amount float64, refund bool, hour int) {
+		require.NoError(t, service.Create(t.Context(), &models.Transaction{
+			Type:        txType,
+			Amount:      amount,
+			Currency:    "USD",
+			CategoryID:  categoryID,
+			Description: description,
+			IsRefund:    refund,
+			Date:        start.Add(time.Duration(hour) * time.Hour),
+		}))
+	}
+	create(models.TransactionTypeExpense, food.ID, "starbucks", 30, false, 1)
+	create(models.TransactionTypeExpense, food.ID, "  Starbucks ", 30, false, 2)
+	create(models.TransactionTypeExpense, food.ID, "Starbucks", 35, false, 3)
+	create(models.TransactionTypeExpense, food.ID, "Starbucks", 5, true, 4)
+	create(models.TransactionTypeExpense, food.ID, "Whole  Foods", 90, false, 5)
+	create(models.TransactionTypeExpense, food.ID, "Bakery", 8, false, 6)
+	create(models.TransactionTypeExpense, food.ID, "", 500, false, 7)
+	create(models.TransactionTypeIncome, salary.ID, "Payroll", 1000, false, 8)
+	
+	stats, err := service.GetTopDescriptions(t.Context(), start, end, 0, models.DescriptionsByTotal)
+	require.NoError(t, err)
+	require.Len(t, stats, 3)
+	
+	// Spellings merged under the latest one, refunds netted, and income and
+	// blank descriptions left out
+	assert.Equal(t, "Starbucks", stats[0].Description)
+	assert.Equal(t, 4, stats[0].Count)
+	assert.Equal(t, 90.0, stats[0].Total)
+	assert.Equal(t, "Whole Foods", stats[1].Description)
+	assert.Equal(t, 1, stats[1].Count)
+	assert.Equal(t, "Bakery", stats[2].Description)
+	
+	// Equal totals go to the more frequent, and the limit keeps the top
+	stats, err = service.GetTopDescriptions(t.Context(), start, end, 1, models.DescriptionsByTotal)
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, "Starbucks", stats[0].Description)
+
+	// By count, equal counts go to the larger total
+	create(models.TransactionTypeExpense, food.ID, "Bakery", 8, false, 9)
+	create(models.TransactionTypeExpense, food.ID, "Bakery", 8, false, 10)
+	create(models.TransactionTypeExpense, food.ID, "Bakery", 8, false, 11)
+	create(models.TransactionTypeExpense, food.ID, "Bakery", 8, false, 12)
+	create(models.TransactionTypeExpense, food.ID, "Corner shop", 95, false, 13)
+	stats, err = service.GetTopDescriptions(t.Context(), start, end, 0, models.DescriptionsByCount)
+	require.NoError(t, err)
+	require.Len(t, stats, 4)
+	assert.Equal(t, "Bakery", stats[0].Description)
+	assert.Equal(t, 5, stats[0].Count)
+	assert.Equal(t, "Starbucks", stats[1].Description)
+	assert.Equal(t, "Corner shop", stats[2].Description)
+	assert.Equal(t, "Whole Foods", stats[3].Description)
+}
+
+func TestTransactionService_ParseQuickEntry(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	
+	categories := []*models.Category{
+		{ID: 1, Name: "Food", Type: models.TransactionTypeExpense},
+		{ID: 2, Name: "Food Delivery", Type: models.TransactionTypeExpense},
+		{ID: 3, Name: "Freelance", Type: models.TransactionTypeIncome},
+		{ID: 4, Name: "Other Income", Type: models.TransactionTypeIncome},
+	}
+	
+	tx, err := service.ParseQuickEntry("food 12.50 morning coffee", categories)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), tx.CategoryID)
+	assert.Equal(t, models.TransactionTypeExpense, tx.Type)
+	assert.Equal(t, 12.50, tx.Amount)
+	assert.Equal(t, "USD", tx.Currency)
+	assert.Equal(t, "morning coffee", tx.Description)
+	
+	// Names can span several words or be abbreviated, and decide the type
+	tx, err = service.ParseQuickEntry("Other Income $40", categories)
+	require.NoError(t, err)
+	assert.Equal(t, uint(4), tx.CategoryID)
+	assert.Equal(t, models.TransactionTypeIncome, tx.Type)
+	assert.Empty(t, tx.Description)
+	tx, err = service.ParseQuickEntry("free 300 logo design", categories)
+	require.NoError(t, err)
+	assert.Equal(t, uint(3), tx.CategoryID)
+	
+	// The amount is the last field that reads as one, which may be a sum
+	// or use a decimal comma
+	tx, err = service.ParseQuickEntry("Food 2 coffees 7,50", categories)
+	require.NoError(t, err)
+	assert.Equal(t, uint(1), tx.CategoryID)
+	assert.Equal(t, 7.50, tx.Amount)
+	assert.Equal(t, "2 coffees", tx.Description)
+	tx, err = service.ParseQuickEntry("Food Delivery 12+3.5 pizza", categories)
+	require.NoError(t, err)
+	assert.Equal(t, uint(2), tx.CategoryID)
+	assert.Equal(t, 15.5, tx.Amount)
+	assert.Equal(t, "pizza", tx.Description)
+	
+	for line, problem := range map[string]string{
+		"Food coffee":   "no amount",
+		"12.50 coffee":  "start with a category",
+		"Travel 80":     "unknown category",
+		"F 10":          "could be Food, Food Delivery, Freelance",
+	} {
+		_, err := service.ParseQuickEntry(line, categories)
+		require.Error(t, err, line)
+		assert.Contains(t, err.Error(), problem, line)
+	}
+}
+
+func TestTransactionService_GetAmountHistogram(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	now := time.Now()
+	create := func(txType models.TransactionType, categoryID uint, amount float64, refund bool) {
+		require.NoError(t, service.Create(t.Context(), &models.Transaction{
+			Type:       txType,
+			Amount:     amount,
+			Currency:   "USD",
+			CategoryID: categoryID,
+			IsRefund:   refund,
+			Date:       now,
+		}))
+	}
+	for _, amount := range []float64{3, 4.99, 9.99, 10, 49.99, 250} {
+		create(models.TransactionTypeExpense, food.ID, amount, false)
+	}
+	create(models.TransactionTypeExpense, food.ID, 5, true)
+	create(models.TransactionTypeIncome, salary.ID, 1000, false)
+	
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0).Add(-time.Second)
+	
+	histogram, err := service.GetAmountHistogram(t.Context(), start, end, models.DefaultAmountBuckets)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"<$10": 3, "$10–50": 2, "$50–200": 0, "$200+": 1}, histogram)
+	
+	histogram, err = service.GetAmountHistogram(t.Context(), start, end, []float64{2.5})
+	require.NoError(t, err)
+	assert.Equal(t, map[string]int{"<$2.5": 0, "$2.5+": 6}, histogram)
+	
+	_, err = service.GetAmountHistogram(t.Context(), start, end, []float64{50, 10})
+	assert.Error(t, err)
+	_, err = service.GetAmountHistogram(t.Context(), start, end, nil)
+	assert.Error(t, err)
+}
+
+func TestTransactionService_GetDailyTotals(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	start := time.Date(2024, time.March, 1, 0, 0, 0, 0, time.Local)
+	create := func(date time.Time, txType models.TransactionType, categoryID uint, amount float64, refund bool) {
+		tx := &models.Transaction{
+			Type:       txType,
+			Amount:     amount,
+			Currency:   "USD",
+			CategoryID: categoryID,
+			IsRefund:   refund,
+			Date:       date,
+		}
+		require.NoError(t, service.Create(t.Context(), tx))
+	}
+	create(start.Add(49*time.Hour), models.TransactionTypeExpense, food.ID, 20, false)
+	create(start.Add(71*time.Hour), models.TransactionTypeExpense, food.ID, 15, false)
+	create(start.Add(60*time.Hour), models.TransactionTypeExpense, food.ID, 5, true)
+	create(start.Add(57*time.Hour), models.TransactionTypeIncome, salary.ID, 1000, false)
+	create(start.AddDate(0, 0, 9).Add(8*time.Hour), models.TransactionTypeExpense, food.ID, 42, false)
+	create(start.AddDate(0, 1, 0).Add(8*time.Hour), models.TransactionTypeExpense, food.ID, 99, false)
+	
+	totals, err := service.GetDailyTotals(t.Context(), start, start.AddDate(0, 1, 0).Add(-time.Second))
+	require.NoError(t, err)
+	require.Len(t, totals, 2)
+	
+	// Early and late transactions land on the same local day, refunds are
+	// netted and income is left out
+	assert.WithinDuration(t, time.Date(2024, time.March, 3, 0, 0, 0, 0, time.Local), totals[0].Date, 0)
+	assert.Equal(t, 30.0, totals[0].Total)
+	assert.WithinDuration(t, time.Date(2024, time.March, 10, 0, 0, 0, 0, time.Local), totals[1].Date, 0)
+	assert.Equal(t, 42.0, totals[1].Total)
+}
+
+func TestParseRelativeDate(t *testing.T) {
+	now := time.Date(2025, time.March, 31, 15, 30, 0, 0, time.Local)
+	day := func(year int, month time.Month, d int) time.Time {
+		return time.Date(year, month, d, 0, 0, 0, 0, time.Local)
+	}
+	
+	cases := map[string]time.Time{
+		"2025-01-15": day(2025, time.January, 15),
+		"today":      day(2025, time.March, 31),
+		" Yesterday": day(2025, time.March, 30),
+		"-0d":        day(2025, time.March, 31),
+		"-10d":       day(2025, time.March, 21),
+		"-2w":        day(2025, time.March, 17),
+		"-1m":        day(2025, time.February, 28), // February has no 31st
+		"-2m":        day(2025, time.January, 31),
+		"-13m":       day(2024, time.February, 29),
+		"-1y":        day(2024, time.March, 31),
+	}
+	for input, want := range cases {
+		got, err := ParseRelativeDate(input, now)
+		require.NoError(t, err, input)
+		assert.True(t, want.Equal(got), "%s: got %s, want %s", input, got, want)
+	}
+	
+	for _, input := range []string{"", "tomorrow", "-d", "-3x", "+3d", "-1.5w", "2025-02-30"} {
+		_, err := ParseRelativeDate(input, now)
+		assert.Error(t, err, input)
+	}
+	
+	// A year before a leap day is the 28th
+	got, err := ParseRelativeDate("-1y", day(2024, time.February, 29))
+	require.NoError(t, err)
+	assert.True(t, day(2023, time.February, 28).Equal(got), "got %s", got)
+}
+
+func TestTransactionService_GetMonthlyLimitStatus(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	require.NoError(t, service.Create(t.Context(), &models.Transaction{
+		Type:       models.TransactionTypeIncome,
+		Amount:     2000,
+		Currency:   "USD",
+		CategoryID: salary.ID,
+		Date:       time.Now(),
+	}))
+	test.CreateTestTransaction(t, db, 300, food.ID)
+	
+	// Without a limit there is nothing to track
+	status, err := service.GetMonthlyLimitStatus(t.Context(), 0)
+	require.NoError(t, err)
+	assert.Nil(t, status)
+	
+	// Income doesn't count against the limit
+	status, err = service.GetMonthlyLimitStatus(t.Context(), 1000)
+	require.NoError(t, err)
+	assert.Equal(t, 300.0, status.Spent)
+	assert.Equal(t, 700.0, status.Remaining)
+	assert.Equal(t, 0, status.Level())
+	
+	test.CreateTestTransaction(t, db, 550, food.ID)
+	status, err = service.GetMonthlyLimitStatus(t.Context(), 1000)
+	require.NoError(t, err)
+	assert.True(t, status.IsNearLimit)
+	assert.Equal(t, 1, status.Level())
+	
+	test.CreateTestTransaction(t, db, 200, food.ID)
+	status, err = service.GetMonthlyLimitStatus(t.Context(), 1000)
+	require.NoError(t, err)
+	assert.True(t, status.IsOverLimit)
+	assert.False(t, status.IsNearLimit)
+	assert.Equal(t, -50.0, status.Remaining)
+	assert.Equal(t, 2, status.Level())
+}
+
+func TestTransactionService_GetSpendingVelocity(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	now := time.Date(2025, 4, 12, 15, 0, 0, 0, time.Local)
+	for _, tx := range []struct {
+		amount float64
+		day    int
+	}{{200, 2}, {145, 12}, {500, 20}} {
+		require.NoError(t, service.Create(t.Context(), &models.Transaction{
+			Type:       models.TransactionTypeExpense,
+			Amount:     tx.amount,
+			Currency:   "USD",
+			CategoryID: food.ID,
+			Date:       time.Date(2025, 4, tx.day, 0, 0, 0, 0, time.Local),
+		}))
+	}
+
+	// 12 of 30 days into April, $1,000 a month would be $400 by now; later
+	// transactions don't count yet
+	velocity, err := service.GetSpendingVelocity(t.Context(), 1000, nil, now)
+	require.NoError(t, err)
+	assert.Equal(t, 345.0, velocity.Spent)
+	assert.InDelta(t, 400.0, velocity.Expected, 0.001)
+	assert.Equal(t, 12, velocity.Day)
+	assert.Equal(t, 30, velocity.DaysInMonth)
+	assert.InDelta(t, -13.75, velocity.Pace, 0.001)
+	assert.True(t, velocity.IsBehind())
+	assert.Equal(t, "You're 14% behind pace", velocity.Status())
+
+	velocity, err = service.GetSpendingVelocity(t.Context(), 750, nil, now)
+	require.NoError(t, err)
+	assert.True(t, velocity.IsAhead())
+	assert.Equal(t, "You're 15% ahead of pace", velocity.Status())
+
+	velocity, err = service.GetSpendingVelocity(t.Context(), 860, nil, now)
+	require.NoError(t, err)
+	assert.Equal(t, "You're on pace", velocity.Status())
+
+	// Without a limit the monthly budgets set the pace, counting the
+	// spending in each of their categories once
+	fun := test.CreateTestCategory(t, db, "Fun", models.TransactionTypeExpense)
+	travel := test.CreateTestCategory(t, db, "Travel", models.TransactionTypeExpense)
+	require.NoError(t, service.Create(t.Context(), &models.Transaction{
+		Type:       models.TransactionTypeExpense,
+		Amount:     55,
+		Currency:   "USD",
+		CategoryID: fun.ID,
+		Date:       time.Date(2025, 4, 5, 0, 0, 0, 0, time.Local),
+	}))
+	budgets := []*models.BudgetStatus{
+		{Budget: models.Budget{CategoryID: food.ID, Amount: 300, Period: models.BudgetPeriodMonthly}, Spent: 845},
+		{Budget: models.Budget{CategoryID: food.ID, Amount: 1000, Period: models.BudgetPeriodMonthly,
+			Categories: []models.Category{*food, *fun}}, Spent: 900},
+		{Budget: models.Budget{CategoryID: fun.ID, Amount: 100, Period: models.BudgetPeriodMonthly}, Spent: 55},
+		{Budget: models.Budget{CategoryID: food.ID, Amount: 5000, Period: models.BudgetPeriodYearly}, Spent: 900},
+	}
+	velocity, err = service.GetSpendingVelocity(t.Context(), 0, budgets, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1000.0, velocity.Target)
+	assert.Equal(t, 400.0, velocity.Spent)
+	assert.Equal(t, "You're on pace", velocity.Status())
+
+	// A budget prorated from the 11th, allowing $200 of its $300 in April,
+	// expects two days' worth by the 12th
+	budgets = append(budgets, &models.BudgetStatus{Budget: models.Budget{
+		CategoryID:         travel.ID,
+		Amount:             200,
+		Period:             models.BudgetPeriodMonthly,
+		StartDate:          time.Date(2025, 4, 11, 0, 0, 0, 0, time.Local),
+		ProrateFirstPeriod: true,
+	}})
+	velocity, err = service.GetSpendingVelocity(t.Context(), 0, budgets, now)
+	require.NoError(t, err)
+	assert.Equal(t, 1200.0, velocity.Target)
+	assert.InDelta(t, 420.0, velocity.Expected, 0.001)
+
+	velocity, err = service.GetSpendingVelocity(t.Context(), 0, nil, now)
+	require.NoError(t, err)
+	assert.Nil(t, velocity)
+}
+
 func TestTransactionService_GetCurrentMonthBurnRate(t *testing.T) {
 	db := test.SetupTestDB(t)
 	txRepo := repository.NewTransactionRepository(db)
@@ -153,7 +1058,7 @@ func TestTransactionService_GetCurrentMonthBurnRate(t *testing.T) {
 		NextDueDate:    time.Now(),
 		IsActive:       true,
 	}
-	require.NoError(t, recurringRepo.Create(recurring))
+	require.NoError(t, recurringRepo.Create(t.Context(), recurring))
 	
 	// Create transactions for current month
 	now := time.Now()
@@ -168,7 +1073,7 @@ func TestTransactionService_GetCurrentMonthBurnRate(t *testing.T) {
 		Description: "Groceries",
 		Date:        now,
 	}
-	require.NoError(t, txRepo.Create(tx1))
+	require.NoError(t, txRepo.Create(t.Context(), tx1))
 	
 	// Recurring expense
 	tx2 := &models.Transaction{
@@ -181,10 +1086,10 @@ func TestTransactionService_GetCurrentMonthBurnRate(t *testing.T) {
 		Date:                   now,
 		RecurringTransactionID: &recurring.ID,
 	}
-	require.NoError(t, txRepo.Create(tx2))
+	require.NoError(t, txRepo.Create(t.Context(), tx2))
 	
 	// Get burn rate
-	burnRate, err := service.GetCurrentMonthBurnRate()
+	burnRate, err := service.GetCurrentMonthBurnRate(t.Context())
 	require.NoError(t, err)
 	require.NotNil(t, burnRate)
 	
@@ -196,4 +1101,629 @@ func TestTransactionService_GetCurrentMonthBurnRate(t *testing.T) {
 	assert.Equal(t, 1750.00, burnRate.TotalBurn)
 	assert.Equal(t, 1500.00, burnRate.ProjectedMonthly)
 	assert.Equal(t, 18000.00, burnRate.ProjectedYearly)
-}
\ No newline at end of file
+}
+
+func TestTransactionService_GetCurrentMonthBurnRateExcludesIncome(t *testing.T) {
+	db := test.SetupTestDB(t)
+	txRepo := repository.NewTransactionRepository(db)
+	recurringRepo := repository.NewRecurringTransactionRepository(db)
+	
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(txRepo, NewCurrencyService(settingsService))
+	service.SetRecurringRepo(recurringRepo)
+	
+	living := test.CreateTestCategory(t, db, "Living", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	
+	for _, rt := range []*models.RecurringTransaction{
+		{Type: models.TransactionTypeExpense, Amount: 1500, CategoryID: living.ID, Description: "Rent"},
+		{Type: models.TransactionTypeIncome, Amount: 5000, CategoryID: salary.ID, Description: "Salary"},
+	} {
+		rt.Currency = "USD"
+		rt.Frequency = models.FrequencyMonthly
+		rt.FrequencyValue = 1
+		rt.StartDate = time.Now().AddDate(0, -1, 0)
+		rt.NextDueDate = time.Now()
+		rt.IsActive = true
+		require.NoError(t, recurringRepo.Create(t.Context(), rt))
+		
+		require.NoError(t, txRepo.Create(t.Context(), &models.Transaction{
+			Type:                   rt.Type,
+			Amount:                 rt.Amount,
+			Currency:               "USD",
+			AmountUSD:              rt.Amount,
+			CategoryID:             rt.CategoryID,
+			Description:            rt.Description,
+			Date:                   time.Now(),
+			RecurringTransactionID: &rt.ID,
+		}))
+	}
+	
+	burnRate, err := service.GetCurrentMonthBurnRate(t.Context())
+	require.NoError(t, err)
+	
+	// The recurring salary counts neither as burn nor towards the projection
+	assert.Equal(t, 1500.00, burnRate.RecurringExpenses)
+	assert.Equal(t, 1, burnRate.RecurringCount)
+	assert.Equal(t, 1500.00, burnRate.TotalBurn)
+	assert.Equal(t, 1500.00, burnRate.ProjectedMonthly)
+	assert.Equal(t, 18000.00, burnRate.ProjectedYearly)
+}
+
+func TestTransactionService_DeletedTransactionsAreIgnored(t *testing.T) {
+	db := test.SetupTestDB(t)
+	txRepo := repository.NewTransactionRepository(db)
+	categoryRepo := repository.NewCategoryRepository(db)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(txRepo, NewCurrencyService(settingsService))
+	categoryService := NewCategoryService(categoryRepo)
+	budgetService := NewBudgetService(repository.NewBudgetRepository(db), txRepo)
+	
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	travel := test.CreateTestCategory(t, db, "Travel", models.TransactionTypeExpense)
+	budget := test.CreateTestBudget(t, db, travel.ID, 500)
+	test.CreateTestTransaction(t, db, 20, food.ID)
+	
+	// The only Travel transaction, in the only AED amount, is deleted
+	trip := &models.Transaction{
+		Type:       models.TransactionTypeExpense,
+		Amount:     300,
+		Currency:   "AED",
+		CategoryID: travel.ID,
+		Date:       time.Now(),
+	}
+	require.NoError(t, service.Create(t.Context(), trip))
+	require.NoError(t, service.Delete(t.Context(), trip.ID))
+	
+	now := time.Now()
+	start := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	end := start.AddDate(0, 1, 0).Add(-time.Second)
+	
+	summary, err := service.GetCategorySummary(t.Context(), start, end)
+	require.NoError(t, err)
+	require.Len(t, summary, 1)
+	assert.Equal(t, "Food", summary[0].Name)
+	
+	withTotals, err := categoryService.GetWithTotals(t.Context(), start, end)
+	require.NoError(t, err)
+	usage, err := categoryService.GetAllWithUsageCount(t.Context())
+	require.NoError(t, err)
+	for _, categories := range [][]*models.CategoryWithTotal{withTotals, usage} {
+		for _, category := range categories {
+			if category.ID == travel.ID {
+				assert.Zero(t, category.Count)
+				assert.Zero(t, category.Total)
+			}
+		}
+	}
+	
+	status, err := budgetService.GetStatus(t.Context(), budget.ID)
+	require.NoError(t, err)
+	assert.Zero(t, status.Spent)
+	
+	stats, err := service.GetCurrencyBreakdown(t.Context())
+	require.NoError(t, err)
+	require.Len(t, stats, 1)
+	assert.Equal(t, "USD", stats[0].Currency)
+	
+	daily, err := service.GetDailyTotals(t.Context(), start, end)
+	require.NoError(t, err)
+	require.Len(t, daily, 1)
+	assert.Equal(t, 20.0, daily[0].Total)
+	
+	// Neither the category nor the currency is held back by it
+	require.NoError(t, settingsService.DisableCurrency(t.Context(), "AED", service))
+	require.NoError(t, budgetService.Delete(t.Context(), budget.ID))
+	require.NoError(t, categoryService.Delete(t.Context(), travel.ID))
+}
+
+func TestTransactionService_LinkedRefunds(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+	
+	shopping := test.CreateTestCategory(t, db, "Shopping", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	original := &models.Transaction{
+		Type:        models.TransactionTypeExpense,
+		Amount:      367.25,
+		Currency:    "AED",
+		CategoryID:  shopping.ID,
+		Description: "Jacket",
+		Date:        time.Now(),
+	}
+	require.NoError(t, service.Create(t.Context(), original))
+	
+	refund := func(amount float64, currency string) *models.Transaction {
+		return &models.Transaction{
+			Type:        models.TransactionTypeExpense,
+			IsRefund:    true,
+			RefundOfID:  &original.ID,
+			Amount:      amount,
+			Currency:    currency,
+			CategoryID:  shopping.ID,
+			Description: "Jacket refund",
+			Date:        time.Now(),
+		}
+	}
+	
+	// A partial refund nets against expenses without adding income
+	first := refund(100, "AED")
+	require.NoError(t, service.Create(t.Context(), first))
+	summary, err := service.GetCurrentMonthSummary(t.Context())
+	require.NoError(t, err)
+	assert.InDelta(t, 72.77, summary.TotalExpenses, 0.01)
+	assert.Equal(t, 0.0, summary.TotalIncome)
+	
+	saved, err := service.GetByID(t.Context(), first.ID)
+	require.NoError(t, err)
+	require.NotNil(t, saved.RefundOf)
+	assert.Equal(t, "Jacket", saved.RefundOf.Description)
+	
+	// A refund in USD is compared at the original's rate: $50 is 183.625
+	// AED, so with the first refund it still fits, and the rest doesn't
+	require.NoError(t, service.Create(t.Context(), refund(50, "USD")))
+	refunded, err := service.GetRefunded(t.Context(), original)
+	require.NoError(t, err)
+	assert.InDelta(t, 283.63, refunded, 0.01)
+	
+	err = service.Create(t.Context(), refund(90, "AED"))
+	assert.ErrorContains(t, err, "would total 373.62 AED, more than the original 367.25 AED")
+	require.NoError(t, service.Create(t.Context(), refund(83.62, "AED")))
+	
+	// Editing a refund doesn't count it twice
+	first.Amount = 99
+	require.NoError(t, service.Update(t.Context(), first))
+	
+	t.Run("only refunds of expenses", func(t *testing.T) {
+		notRefund := refund(10, "AED")
+		notRefund.IsRefund = false
+		assert.ErrorContains(t, service.Create(t.Context(), notRefund), "only refunds can be linked")
+		
+		ofRefund := refund(1, "AED")
+		ofRefund.RefundOfID = &first.ID
+		assert.ErrorContains(t, service.Create(t.Context(), ofRefund), "'Jacket refund' is not an expense")
+		
+		income := &models.Transaction{
+			Type:        models.TransactionTypeIncome,
+			Amount:      1000,
+			Currency:    "USD",
+			CategoryID:  salary.ID,
+			Description: "Pay",
+			Date:        time.Now(),
+		}
+		require.NoError(t, service.Create(t.Context(), income))
+		ofIncome := refund(1, "AED")
+		ofIncome.RefundOfID = &income.ID
+		assert.ErrorContains(t, service.Create(t.Context(), ofIncome), "'Pay' is not an expense")
+	})
+	
+	t.Run("find refundable", func(t *testing.T) {
+		matches, err := service.FindRefundable(t.Context(), "jack", 5)
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		assert.Equal(t, original.ID, matches[0].ID)
+		
+		matches, err = service.FindRefundable(t.Context(), "367.25", 5)
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		assert.Equal(t, original.ID, matches[0].ID)
+		
+		// By the USD amount too, and no more than the limit, newest first
+		later := &models.Transaction{
+			Type:        models.TransactionTypeExpense,
+			Amount:      100,
+			Currency:    "USD",
+			CategoryID:  shopping.ID,
+			Description: "Jacket lining",
+			Date:        time.Now().Add(time.Hour),
+		}
+		require.NoError(t, service.Create(t.Context(), later))
+		matches, err = service.FindRefundable(t.Context(), "100", 5)
+		require.NoError(t, err)
+		require.Len(t, matches, 2)
+		assert.Equal(t, later.ID, matches[0].ID)
+		matches, err = service.FindRefundable(t.Context(), "jacket", 1)
+		require.NoError(t, err)
+		require.Len(t, matches, 1)
+		assert.Equal(t, later.ID, matches[0].ID)
+	})
+	
+	t.Run("original not below its refunds", func(t *testing.T) {
+		edited := *original
+		edited.Amount = 300
+		assert.ErrorContains(t, service.Update(t.Context(), &edited),
+			"'Jacket' has already been refunded 366.24 AED, more than 300.00 AED")
+		
+		edited = *original
+		edited.Type = models.TransactionTypeIncome
+		edited.CategoryID = salary.ID
+		assert.ErrorContains(t, service.Update(t.Context(), &edited), "has refunds linked to it")
+		
+		edited = *original
+		edited.Amount = 366.25
+		require.NoError(t, service.Update(t.Context(), &edited))
+	})
+}
+
+func TestTransactionService_IncomeAllocations(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewTransactionRepository(db)
+	
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repo, NewCurrencyService(settingsService))
+	
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	
+	paycheck := &models.Transaction{
+		Type:        models.TransactionTypeIncome,
+		Amount:      5000,
+		Currency:    "USD",
+		CategoryID:  salary.ID,
+		Description: "Paycheck",
+		Date:        time.Now(),
+	}
+	require.NoError(t, service.Create(t.Context(), paycheck))
+	
+	// Percentages are of the transaction, and repeated names are combined
+	allocations, err := ParseAllocations("Taxes 1200, Savings 10%, savings 500", paycheck)
+	require.NoError(t, err)
+	require.NoError(t, service.SetAllocations(t.Context(), paycheck.ID, allocations))
+	
+	stored, err := service.GetAllocations(t.Context(), paycheck.ID)
+	require.NoError(t, err)
+	require.Len(t, stored, 2)
+	assert.Equal(t, "Taxes", stored[0].Name)
+	assert.Equal(t, 1200.0, stored[0].Amount)
+	assert.Equal(t, "Savings", stored[1].Name)
+	assert.Equal(t, 1000.0, stored[1].Amount)
+	
+	// A second paycheck in AED is reported at its own rate
+	bonus := &models.Transaction{
+		Type:        models.TransactionTypeIncome,
+		Amount:      3672.50,
+		Currency:    "AED",
+		CategoryID:  salary.ID,
+		Description: "Bonus",
+		Date:        time.Now(),
+	}
+	require.NoError(t, service.Create(t.Context(), bonus))
+	require.NoError(t, service.SetAllocations(t.Context(), bonus.ID, []*models.IncomeAllocation{{Name: "Savings", Amount: 1836.25}}))
+	
+	start := time.Now().AddDate(0, 0, -1)
+	end := time.Now().AddDate(0, 0, 1)
+	summary, err := service.GetAllocationSummary(t.Context(), start, end)
+	require.NoError(t, err)
+	assert.Equal(t, 6000.0, summary.TotalIncome)
+	require.Len(t, summary.Allocations, 2)
+	assert.Equal(t, "Savings", summary.Allocations[0].Name)
+	assert.InDelta(t, 1500.0, summary.Allocations[0].AmountUSD, 0.01)
+	assert.Equal(t, 1200.0, summary.Allocations[1].AmountUSD)
+	assert.InDelta(t, 3300.0, summary.Unallocated(), 0.01)
+	
+	// Allocations can't exceed the income, nor be made from an expense
+	err = service.SetAllocations(t.Context(), paycheck.ID, []*models.IncomeAllocation{{Name: "Savings", Amount: 5000.01}})
+	assert.Error(t, err)
+	
+	lunch := test.CreateTestTransaction(t, db, 20, food.ID)
+	err = service.SetAllocations(t.Context(), lunch.ID, []*models.IncomeAllocation{{Name: "Savings", Amount: 10}})
+	assert.Error(t, err)
+	
+	// Nor can the income be cut below what is allocated from it
+	paycheck.Amount = 2000
+	assert.Error(t, service.Update(t.Context(), paycheck))
+	
+	// Deleted income drops out of the summary
+	require.NoError(t, service.Delete(t.Context(), bonus.ID))
+	summary, err = service.GetAllocationSummary(t.Context(), start, end)
+	require.NoError(t, err)
+	require.Len(t, summary.Allocations, 2)
+	assert.Equal(t, 1200.0, summary.Allocations[0].AmountUSD)
+	assert.Equal(t, 1000.0, summary.Allocations[1].AmountUSD)
+	
+	// Clearing them removes them all
+	none, err := ParseAllocations("  ", paycheck)
+	require.NoError(t, err)
+	require.NoError(t, service.SetAllocations(t.Context(), paycheck.ID, none))
+	stored, err = service.GetAllocations(t.Context(), paycheck.ID)
+	require.NoError(t, err)
+	assert.Empty(t, stored)
+	
+	_, err = ParseAllocations("Savings", paycheck)
+	assert.Error(t, err)
+	_, err = ParseAllocations("Savings 120%", paycheck)
+	assert.Error(t, err)
+}
+
+func TestTransactionService_CreateTransfer(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	repo := repository.NewTransactionRepository(db)
+	service := NewTransactionService(repo, NewCurrencyService(settingsService))
+	undo := NewUndoService(DefaultUndoLimit)
+	service.SetUndoService(undo)
+	accounts := NewAccountService(repository.NewAccountRepository(db))
+
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	checking := &models.Account{Name: "Checking", Type: models.AccountTypeDebit, OpeningBalance: 1000}
+	savings := &models.Account{Name: "Savings", Type: models.AccountTypeSavings}
+	for _, account := range []*models.Account{checking, savings} {
+		require.NoError(t, accounts.Create(t.Context(), account))
+	}
+
+	now := time.Date(2025, time.March, 15, 12, 0, 0, 0, time.Local)
+	march := time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local)
+	endOfMarch := march.AddDate(0, 1, 0).Add(-time.Second)
+	require.NoError(t, service.Create(t.Context(), &models.Transaction{Type: models.TransactionTypeIncome, Amount: 500, Currency: "USD",
+		CategoryID: salary.ID, Date: now, AccountID: &checking.ID}))
+	require.NoError(t, service.Create(t.Context(), &models.Transaction{Type: models.TransactionTypeExpense, Amount: 40, Currency: "USD",
+		CategoryID: food.ID, Date: now, AccountID: &checking.ID}))
+
+	out, in, err := service.CreateTransfer(t.Context(), checking.ID, savings.ID, 300, "USD", now, "Rainy day fund")
+	require.NoError(t, err)
+	require.NotNil(t, out.TransferPairID)
+	assert.Equal(t, in.ID, *out.TransferPairID)
+	assert.Equal(t, out.ID, *in.TransferPairID)
+	assert.True(t, in.IsTransferIn)
+	assert.False(t, out.IsTransferIn)
+
+	// Moving money around is neither income nor spending
+	summary, err := service.GetSummary(t.Context(), march, endOfMarch)
+	require.NoError(t, err)
+	assert.Equal(t, 500.0, summary.TotalIncome)
+	assert.Equal(t, 40.0, summary.TotalExpenses)
+	assert.Equal(t, 2, summary.Count)
+
+	monthly, err := repo.GetMonthlyTotals(t.Context(), march, endOfMarch)
+	require.NoError(t, err)
+	require.Len(t, monthly, 1)
+	assert.Equal(t, 500.0, monthly[0].Income)
+	assert.Equal(t, 40.0, monthly[0].Expenses)
+	assert.Equal(t, 2, monthly[0].Count)
+
+	categories, err := service.GetCategorySummary(t.Context(), march, endOfMarch)
+	require.NoError(t, err)
+	assert.Len(t, categories, 2)
+
+	currencies, err := service.GetCurrencyBreakdown(t.Context())
+	require.NoError(t, err)
+	require.Len(t, currencies, 1)
+	assert.Equal(t, 540.0, currencies[0].TotalUSD)
+
+	spending, err := accounts.GetSpending(t.Context(), march, endOfMarch)
+	require.NoError(t, err)
+	require.Len(t, spending, 1)
+	assert.Equal(t, 40.0, spending[0].Expenses)
+
+	// ...but it does move the balances
+	balances, err := accounts.GetBalances(t.Context(), now)
+	require.NoError(t, err)
+	require.Len(t, balances, 2)
+	assert.Equal(t, "Checking", balances[0].Name())
+	assert.Equal(t, 1160.0, balances[0].Balance())
+	assert.Equal(t, 300.0, balances[1].Balance())
+
+	_, _, err = service.CreateTransfer(t.Context(), checking.ID, checking.ID, 10, "USD", now, "")
+	assert.ErrorContains(t, err, "to itself")
+	_, _, err = service.CreateTransfer(t.Context(), checking.ID, 999, 10, "USD", now, "")
+	assert.ErrorContains(t, err, "account not found")
+	_, _, err = service.CreateTransfer(t.Context(), checking.ID, savings.ID, 0, "USD", now, "")
+	assert.ErrorContains(t, err, "amount must be positive")
+
+	// A transfer is only ever made or removed as a whole
+	assert.ErrorContains(t, service.Update(t.Context(), out), "can't be edited")
+	assert.ErrorContains(t, service.Create(t.Context(), &models.Transaction{Type: models.TransactionTypeTransfer, Amount: 10,
+		Currency: "USD", Date: now, AccountID: &checking.ID}), "between two accounts")
+
+	require.NoError(t, service.Delete(t.Context(), in.ID))
+	_, err = service.GetByID(t.Context(), out.ID)
+	assert.Error(t, err)
+	balances, err = accounts.GetBalances(t.Context(), now)
+	require.NoError(t, err)
+	assert.Equal(t, 1460.0, balances[0].Balance())
+
+	_, err = undo.Undo(t.Context())
+	require.NoError(t, err)
+	restored, err := service.GetByID(t.Context(), out.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "⇄ Transfer", restored.CategoryLabel())
+	assert.True(t, restored.IsOutflow())
+}
+
+func TestTransactionService_Attachments(t *testing.T) {
+	db := test.SetupTestDB(t)
+	dir := t.TempDir()
+	settingsService, err := NewSettingsService(dir)
+	require.NoError(t, err)
+	service := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	tx := test.CreateTestTransaction(t, db, 12.5, food.ID)
+	receipt := filepath.Join(dir, "coffee receipt.pdf")
+	require.NoError(t, os.WriteFile(receipt, []byte("%PDF"), 0o600))
+
+	// Pasted paths may come quoted, or with their spaces escaped
+	attachment, err := service.AddAttachment(t.Context(), tx.ID, ` "`+receipt+`" `)
+	require.NoError(t, err)
+	assert.Equal(t, receipt, attachment.Path)
+	assert.False(t, attachment.AddedAt.IsZero())
+
+	_, err = service.AddAttachment(t.Context(), tx.ID, strings.ReplaceAll(receipt, " ", `\ `))
+	assert.ErrorContains(t, err, "already attached")
+	_, err = service.AddAttachment(t.Context(), tx.ID, filepath.Join(dir, "missing.pdf"))
+	assert.ErrorContains(t, err, "no file at")
+	_, err = service.AddAttachment(t.Context(), tx.ID, dir)
+	assert.ErrorContains(t, err, "is a directory")
+	_, err = service.AddAttachment(t.Context(), tx.ID, " ")
+	assert.ErrorContains(t, err, "path is required")
+	_, err = service.AddAttachment(t.Context(), 999, receipt)
+	assert.ErrorContains(t, err, "transaction not found")
+
+	// The file may disappear later without affecting the transaction
+	require.NoError(t, os.Remove(receipt))
+	found, err := service.GetByID(t.Context(), tx.ID)
+	require.NoError(t, err)
+	require.Len(t, found.Attachments, 1)
+	assert.Equal(t, receipt, found.Attachments[0].Path)
+
+	// Saving a transaction loaded with its attachments leaves them as they
+	// are, even when one was removed since
+	require.NoError(t, service.RemoveAttachment(t.Context(), attachment.ID))
+	found.Description = "Flat white"
+	require.NoError(t, service.Update(t.Context(), found))
+	attachments, err := service.GetAttachments(t.Context(), tx.ID)
+	require.NoError(t, err)
+	assert.Empty(t, attachments)
+
+	assert.ErrorContains(t, service.RemoveAttachment(t.Context(), attachment.ID), "attachment not found")
+}
+
+func TestTransactionService_SplitTransactions(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewTransactionRepository(db)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repo, NewCurrencyService(settingsService))
+
+	groceries := test.CreateTestCategory(t, db, "Groceries", models.TransactionTypeExpense)
+	household := test.CreateTestCategory(t, db, "Household", models.TransactionTypeExpense)
+	salary := test.CreateTestCategory(t, db, "Salary", models.TransactionTypeIncome)
+	budget := test.CreateTestBudget(t, db, household.ID, 100)
+
+	receipt := &models.Transaction{
+		Type:        models.TransactionTypeExpense,
+		Amount:      50,
+		Currency:    "USD",
+		CategoryID:  groceries.ID,
+		Description: "Supermarket",
+		Date:        time.Now(),
+		Splits: []models.SplitItem{
+			{CategoryID: groceries.ID, Amount: 42.10},
+			{CategoryID: household.ID, Amount: 7.90},
+		},
+	}
+	require.NoError(t, service.Create(t.Context(), receipt))
+
+	// Category totals and budgets count the lines, not the receipt
+	start := time.Now().AddDate(0, 0, -1)
+	end := time.Now().AddDate(0, 0, 1)
+	summary, err := service.GetCategorySummary(t.Context(), start, end)
+	require.NoError(t, err)
+	require.Len(t, summary, 2)
+	assert.Equal(t, "Groceries", summary[0].Name)
+	assert.InDelta(t, 42.10, summary[0].Total, 0.001)
+	assert.Equal(t, "Household", summary[1].Name)
+	assert.InDelta(t, 7.90, summary[1].Total, 0.001)
+
+	status, err := NewBudgetService(repository.NewBudgetRepository(db), repo).GetStatus(t.Context(), budget.ID)
+	require.NoError(t, err)
+	assert.InDelta(t, 7.90, status.Spent, 0.001)
+
+	totals, err := service.GetSummary(t.Context(), start, end)
+	require.NoError(t, err)
+	assert.Equal(t, 50.0, totals.TotalExpenses)
+
+	// The list finds it under either category and loads its lines
+	found, err := service.GetByFilter(t.Context(), &models.TransactionFilter{CategoryID: household.ID})
+	require.NoError(t, err)
+	require.Len(t, found, 1)
+	assert.True(t, found[0].IsSplit())
+	assert.Equal(t, "Household", found[0].Splits[1].Category.Name)
+
+	// Lines must add up to the amount and suit the transaction's type
+	bad := *receipt
+	bad.ID = 0
+	bad.Splits = []models.SplitItem{{CategoryID: groceries.ID, Amount: 40}, {CategoryID: household.ID, Amount: 7.90}}
+	assert.ErrorContains(t, service.Create(t.Context(), &bad), "split lines total 47.90 USD")
+	bad.Splits = []models.SplitItem{{CategoryID: groceries.ID, Amount: 42.10}, {CategoryID: salary.ID, Amount: 7.90}}
+	assert.ErrorContains(t, service.Create(t.Context(), &bad), "is for income")
+	bad.Splits = []models.SplitItem{{CategoryID: groceries.ID, Amount: 50}}
+	assert.ErrorContains(t, service.Create(t.Context(), &bad), "at least 2 lines")
+
+	// Editing replaces the lines, and an empty list removes them
+	stored, err := service.GetByID(t.Context(), receipt.ID)
+	require.NoError(t, err)
+	stored.Splits = []models.SplitItem{
+		{CategoryID: household.ID, Amount: 20},
+		{CategoryID: groceries.ID, Amount: 30},
+	}
+	require.NoError(t, service.Update(t.Context(), stored))
+	stored, err = service.GetByID(t.Context(), receipt.ID)
+	require.NoError(t, err)
+	require.Len(t, stored.Splits, 2)
+	assert.Equal(t, household.ID, stored.CategoryID)
+	assert.Equal(t, 20.0, stored.Splits[0].AmountUSD)
+
+	stored.Splits = []models.SplitItem{}
+	require.NoError(t, service.Update(t.Context(), stored))
+	stored, err = service.GetByID(t.Context(), receipt.ID)
+	require.NoError(t, err)
+	assert.False(t, stored.IsSplit())
+	summary, err = service.GetCategorySummary(t.Context(), start, end)
+	require.NoError(t, err)
+	require.Len(t, summary, 1)
+	assert.Equal(t, "Household", summary[0].Name)
+	assert.Equal(t, 50.0, summary[0].Total)
+}
+
+func TestTransactionService_SplitRounding(t *testing.T) {
+	db := test.SetupTestDB(t)
+	settingsService, err := NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+	service := NewTransactionService(repository.NewTransactionRepository(db), NewCurrencyService(settingsService))
+
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	drinks := test.CreateTestCategory(t, db, "Drinks", models.TransactionTypeExpense)
+	fun := test.CreateTestCategory(t, db, "Fun", models.TransactionTypeExpense)
+
+	// The last line left empty takes the rest, absorbing the cent the
+	// others lost to rounding
+	amounts, err := SplitAmounts(10, "USD", []string{"10/3", "10 / 3", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{3.33, 3.33, 3.34}, amounts)
+
+	// Currencies without cents round to whole units
+	amounts, err = SplitAmounts(1000, "JPY", []string{"333.4", ""})
+	require.NoError(t, err)
+	assert.Equal(t, []float64{333, 667}, amounts)
+
+	_, err = SplitAmounts(10, "USD", []string{"6", "4", ""})
+	assert.ErrorContains(t, err, "already take the whole")
+	_, err = SplitAmounts(10, "USD", []string{"", "4"})
+	assert.ErrorContains(t, err, "line 1")
+
+	// The USD amount is shared out to the cent, the last line taking what
+	// is left so the lines add up to it exactly
+	dinner := &models.Transaction{
+		Type:       models.TransactionTypeExpense,
+		Amount:     30,
+		Currency:   "EUR",
+		ManualUSD:  true,
+		AmountUSD:  33.34,
+		CategoryID: food.ID,
+		Date:       time.Now(),
+		Splits: []models.SplitItem{
+			{CategoryID: food.ID, Amount: 10},
+			{CategoryID: drinks.ID, Amount: 10},
+			{CategoryID: fun.ID, Amount: 10},
+		},
+	}
+	require.NoError(t, service.Create(t.Context(), dinner))
+	assert.Equal(t, 11.11, dinner.Splits[0].AmountUSD)
+	assert.Equal(t, 11.11, dinner.Splits[1].AmountUSD)
+	assert.Equal(t, 11.12, dinner.Splits[2].AmountUSD)
+
+	// Typed lines are checked as shown, rounded to cents, so lines that
+	// only add up before rounding are turned down
+	assert.NoError(t, models.CheckSplitTotal(10, "USD", []models.SplitItem{{Amount: 3.33}, {Amount: 3.33}, {Amount: 3.34}}))
+	assert.NoError(t, models.CheckSplitTotal(10, "USD", []models.SplitItem{{Amount: 4.999}, {Amount: 5.001}}))
+	assert.Error(t, models.CheckSplitTotal(10, "USD", []models.SplitItem{{Amount: 3.333}, {Amount: 3.333}, {Amount: 3.334}}))
+	assert.Error(t, models.CheckSplitTotal(10, "USD", []models.SplitItem{{Amount: 3.33}, {Amount: 3.33}, {Amount: 3.33}}))
+}
@@ -0,0 +1,91 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// DefaultUndoLimit is the number of destructive actions kept for undo
+const DefaultUndoLimit = 20
+
+// UndoAction describes a destructive action that can be reversed
+type UndoAction struct {
+	Description string
+	CreatedAt   time.Time
+
+	reverse func(ctx context.Context) error
+}
+
+// UndoService keeps an in-session stack of recent destructive actions.
+// Actions are not persisted across restarts.
+type UndoService struct {
+	actions []*UndoAction
+	limit   int
+	mu      sync.Mutex
+}
+
+// NewUndoService creates a new undo service keeping at most limit actions
+func NewUndoService(limit int) *UndoService {
+	if limit < 1 {
+		limit = DefaultUndoLimit
+	}
+	return &UndoService{
+		limit: limit,
+	}
+}
+
+// Record pushes a reversible action onto the stack, dropping the oldest
+// action once the limit is reached
+func (s *UndoService) Record(description string, reverse func(ctx context.Context) error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.actions = append(s.actions, &UndoAction{
+		Description: description,
+		CreatedAt:   time.Now(),
+		reverse:     reverse,
+	})
+
+	if len(s.actions) > s.limit {
+		s.actions = s.actions[len(s.actions)-s.limit:]
+	}
+}
+
+// Peek returns the most recent action without removing it
+func (s *UndoService) Peek() *UndoAction {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if len(s.actions) == 0 {
+		return nil
+	}
+	return s.actions[len(s.actions)-1]
+}
+
+// Undo pops the most recent action and reverses it. The action is dropped
+// from the stack even when reversing fails so it cannot block older entries.
+func (s *UndoService) Undo(ctx context.Context) (*UndoAction, error) {
+	s.mu.Lock()
+	if len(s.actions) == 0 {
+		s.mu.Unlock()
+		return nil, fmt.Errorf("nothing to undo")
+	}
+	action := s.actions[len(s.actions)-1]
+	s.actions = s.actions[:len(s.actions)-1]
+	s.mu.Unlock()
+
+	if err := action.reverse(ctx); err != nil {
+		return action, fmt.Errorf("failed to undo %s: %w", action.Description, err)
+	}
+
+	return action, nil
+}
+
+// Len returns the number of actions that can be undone
+func (s *UndoService) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.actions)
+}
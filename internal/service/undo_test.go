@@ -0,0 +1,201 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"burnwise/internal/models"
+	"burnwise/internal/repository"
+	test "burnwise/test/helpers"
+)
+
+func TestUndoService_Limit(t *testing.T) {
+	undo := NewUndoService(2)
+
+	undo.Record("first", func(ctx context.Context) error { return nil })
+	undo.Record("second", func(ctx context.Context) error { return nil })
+	undo.Record("third", func(ctx context.Context) error { return nil })
+
+	assert.Equal(t, 2, undo.Len())
+	assert.Equal(t, "third", undo.Peek().Description)
+
+	action, err := undo.Undo(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "third", action.Description)
+
+	action, err = undo.Undo(t.Context())
+	require.NoError(t, err)
+	assert.Equal(t, "second", action.Description)
+
+	_, err = undo.Undo(t.Context())
+	assert.Error(t, err)
+	assert.Nil(t, undo.Peek())
+}
+
+func TestUndoService_FailedUndoIsDropped(t *testing.T) {
+	undo := NewUndoService(DefaultUndoLimit)
+
+	undo.Record("broken", func(ctx context.Context) error { return errors.New("boom") })
+
+	_, err := undo.Undo(t.Context())
+	assert.Error(t, err)
+	assert.Equal(t, 0, undo.Len())
+}
+
+func TestUndoService_RestoreDeletedTransaction(t *testing.T) {
+	db := test.SetupTestDB(t)
+	txRepo := repository.NewTransactionRepository(db)
+
+	tempDir := t.TempDir()
+	settingsService, err := NewSettingsService(tempDir)
+	require.NoError(t, err)
+	currencyService := NewCurrencyService(settingsService)
+
+	undo := NewUndoService(DefaultUndoLimit)
+	txService := NewTransactionService(txRepo, currencyService)
+	txService.SetUndoService(undo)
+
+	category := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	tx := test.CreateTestTransaction(t, db, 42.00, category.ID)
+
+	require.NoError(t, txService.Delete(t.Context(), tx.ID))
+	_, err = txService.GetByID(t.Context(), tx.ID)
+	assert.Error(t, err)
+
+	_, err = undo.Undo(t.Context())
+	require.NoError(t, err)
+
+	restored, err := txService.GetByID(t.Context(), tx.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 42.00, restored.Amount)
+}
+
+func TestUndoService_UnmergeCategories(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewCategoryRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+
+	undo := NewUndoService(DefaultUndoLimit)
+	service := NewCategoryService(repo)
+	service.SetUndoService(undo)
+
+	source := test.CreateTestCategory(t, db, "Coffee", models.TransactionTypeExpense)
+	target := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+
+	moved := test.CreateTestTransaction(t, db, 5.00, source.ID)
+	existing := test.CreateTestTransaction(t, db, 20.00, target.ID)
+
+	require.NoError(t, service.MergeCategories(t.Context(), source.ID, target.ID))
+
+	_, err := undo.Undo(t.Context())
+	require.NoError(t, err)
+
+	restored, err := service.GetByID(t.Context(), source.ID)
+	require.NoError(t, err)
+	assert.Equal(t, "Coffee", restored.Name)
+
+	movedTx, err := txRepo.GetByID(t.Context(), moved.ID)
+	require.NoError(t, err)
+	assert.Equal(t, source.ID, movedTx.CategoryID)
+
+	existingTx, err := txRepo.GetByID(t.Context(), existing.ID)
+	require.NoError(t, err)
+	assert.Equal(t, target.ID, existingTx.CategoryID)
+
+	history, err := service.GetHistory(t.Context(), source.ID)
+	require.NoError(t, err)
+	require.NotEmpty(t, history)
+	assert.Equal(t, models.CategoryActionUnmerged, history[0].Action)
+}
+
+func TestUndoService_UnmergeMovesEverythingBack(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewCategoryRepository(db)
+	recurringRepo := repository.NewRecurringTransactionRepository(db)
+
+	undo := NewUndoService(DefaultUndoLimit)
+	service := NewCategoryService(repo)
+	service.SetUndoService(undo)
+
+	source := test.CreateTestCategory(t, db, "Coffee", models.TransactionTypeExpense)
+	target := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	other := test.CreateTestCategory(t, db, "Snacks", models.TransactionTypeExpense)
+
+	// A budget of its own, a group budget with another category and one
+	// that already covers the target
+	budget := test.CreateTestBudget(t, db, source.ID, 50)
+	linked := test.CreateTestBudget(t, db, other.ID, 80)
+	require.NoError(t, db.Create(&models.BudgetCategory{BudgetID: linked.ID, CategoryID: other.ID}).Error)
+	require.NoError(t, db.Create(&models.BudgetCategory{BudgetID: linked.ID, CategoryID: source.ID}).Error)
+	both := test.CreateTestBudget(t, db, target.ID, 300)
+	require.NoError(t, db.Create(&models.BudgetCategory{BudgetID: both.ID, CategoryID: target.ID}).Error)
+	require.NoError(t, db.Create(&models.BudgetCategory{BudgetID: both.ID, CategoryID: source.ID}).Error)
+
+	rule := &models.RecurringTransaction{
+		Type:           models.TransactionTypeExpense,
+		Amount:         4,
+		Currency:       "USD",
+		CategoryID:     source.ID,
+		Description:    "Beans",
+		Frequency:      models.FrequencyWeekly,
+		FrequencyValue: 1,
+		StartDate:      time.Now(),
+		NextDueDate:    time.Now().AddDate(0, 0, 7),
+		IsActive:       true,
+	}
+	require.NoError(t, recurringRepo.Create(t.Context(), rule))
+	shopping := test.CreateTestTransaction(t, db, 30, other.ID)
+	line := &models.SplitItem{TransactionID: shopping.ID, CategoryID: source.ID, Amount: 10, AmountUSD: 10}
+	require.NoError(t, db.Create(line).Error)
+	targetLine := &models.SplitItem{TransactionID: shopping.ID, CategoryID: target.ID, Amount: 20, AmountUSD: 20}
+	require.NoError(t, db.Create(targetLine).Error)
+
+	require.NoError(t, service.MergeCategories(t.Context(), source.ID, target.ID))
+	_, err := undo.Undo(t.Context())
+	require.NoError(t, err)
+
+	categoryOf := func(model any, id uint) uint {
+		var categoryID uint
+		require.NoError(t, db.Unscoped().Model(model).Where("id = ?", id).Pluck("category_id", &categoryID).Error)
+		return categoryID
+	}
+	assert.Equal(t, source.ID, categoryOf(&models.Budget{}, budget.ID))
+	assert.Equal(t, source.ID, categoryOf(&models.RecurringTransaction{}, rule.ID))
+	assert.Equal(t, source.ID, categoryOf(&models.SplitItem{}, line.ID))
+	assert.Equal(t, target.ID, categoryOf(&models.SplitItem{}, targetLine.ID))
+
+	linksOf := func(budgetID uint) []uint {
+		var ids []uint
+		require.NoError(t, db.Model(&models.BudgetCategory{}).Where("budget_id = ?", budgetID).Order("category_id").Pluck("category_id", &ids).Error)
+		return ids
+	}
+	assert.Equal(t, []uint{source.ID, other.ID}, linksOf(linked.ID))
+	assert.Equal(t, []uint{source.ID, target.ID}, linksOf(both.ID))
+}
+
+func TestUndoService_RestoreDeletedBudget(t *testing.T) {
+	db := test.SetupTestDB(t)
+	budgetRepo := repository.NewBudgetRepository(db)
+	txRepo := repository.NewTransactionRepository(db)
+
+	undo := NewUndoService(DefaultUndoLimit)
+	service := NewBudgetService(budgetRepo, txRepo)
+	service.SetUndoService(undo)
+
+	category := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	budget := test.CreateTestBudget(t, db, category.ID, 500.00)
+
+	require.NoError(t, service.Delete(t.Context(), budget.ID))
+
+	_, err := undo.Undo(t.Context())
+	require.NoError(t, err)
+
+	restored, err := service.GetByID(t.Context(), budget.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 500.00, restored.Amount)
+}
@@ -1,12 +1,19 @@
 package ui
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
+	"time"
 
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"burnwise/internal/models"
 	"burnwise/internal/service"
+	"burnwise/internal/ui/styles"
 	"burnwise/internal/ui/views"
 )
 
@@ -16,6 +23,7 @@ const (
 	viewDashboard view = iota
 	viewTransactions
 	viewTransactionForm
+	viewTransactionDetail
 	viewBudgets
 	viewBudgetForm
 	viewReports
@@ -23,12 +31,24 @@ const (
 	viewRecurring
 	viewRecurringForm
 	viewCurrencySettings
+	viewSettings
+	viewAccounts
+	viewWelcome
+)
+
+// Below minWidth by minHeight the views can't be laid out, so a placeholder
+// asking for the recommended size is shown instead. Narrower terminals than
+// the recommended size still work, with the views dropping what doesn't fit.
+const (
+	minWidth          = 40
+	minHeight         = 12
+	recommendedWidth  = 80
+	recommendedHeight = 24
 )
 
 type App struct {
 	currentView     view
-	width           int
-	height          int
+	size            tea.WindowSizeMsg // the last window size, given to each view it shows
 	
 	txService              *service.TransactionService
 	categoryService        *service.CategoryService
@@ -36,21 +56,53 @@ type App struct {
 	currencyService        *service.CurrencyService
 	settingsService        *service.SettingsService
 	recurringService       *service.RecurringTransactionService
+	undoService            *service.UndoService
+	accountService         *service.AccountService
+	exportService          *service.ExportService
+	exportDir              string
 	
-	dashboard        *views.Dashboard
-	transactionList  *views.TransactionList
-	transactionForm  *views.TransactionForm
-	budgetList       *views.BudgetList
-	budgetForm       *views.BudgetForm
-	reports          *views.Reports
-	categoryList     *views.CategoryListModel
-	recurringList    *views.RecurringListModel
-	recurringForm    *views.RecurringFormModel
-	currencySettings *views.CurrencySettings
+	dashboard         *views.Dashboard
+	transactionList   *views.TransactionList
+	transactionForm   *views.TransactionForm
+	transactionDetail *views.TransactionDetail
+	budgetList        *views.BudgetList
+	budgetForm        *views.BudgetForm
+	reports           *views.Reports
+	categoryList      *views.CategoryListModel
+	recurringList     *views.RecurringListModel
+	recurringForm     *views.RecurringFormModel
+	currencySettings  *views.CurrencySettings
+	settingsView      *views.SettingsView
+	accountList       *views.AccountList
+	welcome           *views.Welcome
+	digest            *views.StartupDigest // shown over the dashboard until a key is pressed
+	palette           *views.CommandPalette
+	paletteOpen       bool
+	statusBar         *views.StatusBar
 	
+	pendingUndo     *service.UndoAction
+	cancelExport    context.CancelFunc // set while a palette export runs; esc calls it
 	err             error
 }
 
+// viewNames are the names the status bar shows for each view
+var viewNames = map[view]string{
+	viewDashboard:         "Dashboard",
+	viewTransactions:      "Transactions",
+	viewTransactionForm:   "Transaction",
+	viewTransactionDetail: "Transaction",
+	viewBudgets:           "Budgets",
+	viewBudgetForm:        "Budget",
+	viewReports:           "Reports",
+	viewCategories:        "Categories",
+	viewRecurring:         "Recurring",
+	viewRecurringForm:     "Recurring",
+	viewCurrencySettings:  "Currencies",
+	viewSettings:          "Settings",
+	viewAccounts:          "Accounts",
+	viewWelcome:           "Welcome",
+}
+
 func NewApp(
 	txService *service.TransactionService,
 	categoryService *service.CategoryService,
@@ -58,6 +110,7 @@ func NewApp(
 	currencyService *service.CurrencyService,
 	settingsService *service.SettingsService,
 	recurringService *service.RecurringTransactionService,
+	undoService *service.UndoService,
 ) *App {
 	return &App{
 		currentView:      viewDashboard,
@@ -67,19 +120,52 @@ func NewApp(
 		currencyService:  currencyService,
 		settingsService:  settingsService,
 		recurringService: recurringService,
+		undoService:      undoService,
+		statusBar:        views.NewStatusBar(),
 	}
 }
 
+// SetExportService enables the export commands in the command palette.
+// Exported files are written to dir.
+func (a *App) SetExportService(exportService *service.ExportService, dir string) {
+	a.exportService = exportService
+	a.exportDir = dir
+}
+
+// SetAccountService enables accounts: the accounts view, picking an account
+// in the transaction form and the spending by account report
+func (a *App) SetAccountService(accountService *service.AccountService) {
+	a.accountService = accountService
+}
+
+// SetWelcome starts the app on a welcome screen summing up the default
+// categories created on this first run
+func (a *App) SetWelcome(created []*models.Category) {
+	a.welcome = views.NewWelcome(created)
+}
+
+// SetStartupDigest shows what needs attention over the dashboard when the
+// app starts
+func (a *App) SetStartupDigest(digest *models.StartupDigest) {
+	a.digest = views.NewStartupDigest(digest)
+}
+
+// SetReadOnly marks the database as opened read-only in the status bar
+func (a *App) SetReadOnly(readOnly bool) {
+	a.statusBar.SetReadOnly(readOnly)
+}
+
 func (a *App) Init() tea.Cmd {
-	a.dashboard = views.NewDashboard(a.txService, a.budgetService)
-	a.transactionList = views.NewTransactionList(a.txService, a.categoryService)
-	a.transactionForm = views.NewTransactionForm(a.txService, a.categoryService, a.currencyService)
-	a.budgetList = views.NewBudgetList(a.budgetService, a.categoryService)
-	a.budgetForm = views.NewBudgetForm(a.budgetService, a.categoryService)
-	a.reports = views.NewReports(a.txService, a.categoryService, a.budgetService)
-	a.categoryList = views.NewCategoryListModel(a.categoryService)
-	a.recurringList = views.NewRecurringListModel(a.recurringService, a.categoryService)
-	a.currencySettings = views.NewCurrencySettings(a.settingsService, a.currencyService, a.txService)
+	a.applyUISettings(a.settingsService.Get().UI)
+	a.buildViews()
+	a.settingsView = views.NewSettingsView(a.settingsService)
+	a.palette = views.NewCommandPalette(a.paletteCommands())
+	a.refreshStatus()
+	
+	if a.welcome != nil {
+		a.currentView = viewWelcome
+		return tea.Batch(a.welcome.Init(), tea.EnterAltScreen)
+	}
 	
 	return tea.Batch(
 		a.dashboard.Init(),
@@ -87,95 +173,356 @@ func (a *App) Init() tea.Cmd {
 	)
 }
 
+// buildViews creates the dashboard and drops the other views, which
+// ensureView creates again on their next visit. This runs again when the UI
+// settings change, since some views capture theme colors when created.
+func (a *App) buildViews() {
+	previous := a.dashboard
+	a.dashboard = views.NewDashboard(a.txService, a.budgetService)
+	if previous != nil {
+		a.dashboard.KeepLimitLevel(previous)
+	}
+	a.dashboard.SetLayout(a.settingsService.Get().UI.Dashboard.Layout())
+	a.dashboard.SetMonthlyLimit(a.settingsService.GetMonthlyLimit())
+	a.dashboard.SetRecurringService(a.recurringService)
+	a.dashboard.SetCategoryService(a.categoryService)
+	a.transactionList = nil
+	a.transactionForm = nil
+	a.transactionDetail = nil
+	a.budgetList = nil
+	a.budgetForm = nil
+	a.reports = nil
+	a.categoryList = nil
+	a.recurringList = nil
+	a.currencySettings = nil
+	a.accountList = nil
+}
+
+// ensureView returns the view shown for v, creating it on its first visit
+func (a *App) ensureView(v view) views.Sizable {
+	switch v {
+	case viewTransactions:
+		if a.transactionList == nil {
+			a.transactionList = views.NewTransactionList(a.txService, a.categoryService)
+		}
+		return a.transactionList
+	case viewTransactionForm:
+		if a.transactionForm == nil {
+			a.transactionForm = views.NewTransactionForm(a.txService, a.categoryService, a.currencyService)
+			if a.accountService != nil {
+				a.transactionForm.SetAccountService(a.accountService)
+			}
+		}
+		return a.transactionForm
+	case viewTransactionDetail:
+		if a.transactionDetail == nil {
+			a.transactionDetail = views.NewTransactionDetail(a.recurringService, a.txService)
+		}
+		return a.transactionDetail
+	case viewBudgets:
+		if a.budgetList == nil {
+			a.budgetList = views.NewBudgetList(a.budgetService, a.categoryService)
+		}
+		return a.budgetList
+	case viewBudgetForm:
+		if a.budgetForm == nil {
+			a.budgetForm = views.NewBudgetForm(a.budgetService, a.categoryService)
+		}
+		return a.budgetForm
+	case viewReports:
+		if a.reports == nil {
+			a.reports = views.NewReports(a.txService, a.categoryService, a.budgetService)
+			a.reports.SetTagPattern(a.settingsService.Get().UI.TagPattern)
+			if a.exportService != nil {
+				a.reports.SetExportService(a.exportService, a.exportDir)
+			}
+			if a.accountService != nil {
+				a.reports.SetAccountService(a.accountService)
+			}
+		}
+		return a.reports
+	case viewCategories:
+		if a.categoryList == nil {
+			a.categoryList = views.NewCategoryListModel(a.categoryService)
+			a.categoryList.SetTransactionService(a.txService)
+		}
+		return a.categoryList
+	case viewRecurring:
+		if a.recurringList == nil {
+			a.recurringList = views.NewRecurringListModel(a.recurringService, a.categoryService)
+		}
+		return a.recurringList
+	case viewRecurringForm:
+		if a.recurringForm == nil {
+			a.recurringForm = views.NewRecurringFormModel(a.recurringService, a.categoryService, nil)
+		}
+		return a.recurringForm
+	case viewCurrencySettings:
+		if a.currencySettings == nil {
+			a.currencySettings = views.NewCurrencySettings(a.settingsService, a.currencyService, a.txService)
+		}
+		return a.currencySettings
+	case viewSettings:
+		return a.settingsView
+	case viewAccounts:
+		if a.accountList == nil {
+			a.accountList = views.NewAccountList(a.accountService)
+			a.accountList.SetTransactionService(a.txService)
+		}
+		return a.accountList
+	case viewWelcome:
+		return a.welcome
+	default:
+		return a.dashboard
+	}
+}
+
+// show makes v the current view and sizes it to the window. Callers
+// prepare the view, if needed, and then return its Init command.
+func (a *App) show(v view) {
+	a.currentView = v
+	a.ensureView(v).SetSize(a.size.Width, a.viewHeight())
+	a.refreshStatus()
+}
+
+// viewHeight is the height left for the views below the status bar
+func (a *App) viewHeight() int {
+	return a.size.Height - 1
+}
+
+// refreshStatus updates the status bar for the current view, counting the
+// recurring items due by the end of today
+func (a *App) refreshStatus() {
+	now := time.Now()
+	endOfDay := time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location())
+	var dueToday int
+	if due, err := a.recurringService.GetDue(context.Background(), endOfDay); err == nil {
+		dueToday = len(due)
+	}
+	a.statusBar.SetContext(viewNames[a.currentView], a.settingsService.GetDefaultCurrency(), dueToday)
+}
+
+func (a *App) applyUISettings(ui models.UISettings) {
+	styles.ApplyPreferences(ui.DateFormat, ui.DecimalPlaces, ui.Theme)
+}
+
 func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	var cmd tea.Cmd
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		a.width = msg.Width
-		a.height = msg.Height
-		a.updateViewSizes()
+		// Other views are sized when they are next shown
+		a.size = msg
+		a.statusBar.SetWidth(msg.Width)
+		a.ensureView(a.currentView).SetSize(msg.Width, a.viewHeight())
+		return a, nil
+		
+	case views.StatusMsg:
+		// Whatever the message reports may have changed what's due
+		a.refreshStatus()
+		return a, a.statusBar.Show(msg)
+		
+	case exportDoneMsg:
+		a.cancelExport = nil
+		switch {
+		case errors.Is(msg.err, context.Canceled):
+			return a, a.statusBar.Show(views.StatusMsg{Text: "Export cancelled"})
+		case msg.err != nil:
+			a.err = msg.err
+			return a, nil
+		}
+		return a, a.statusBar.Show(views.StatusMsg{Text: "Exported to " + msg.path})
 
 	case tea.KeyMsg:
-		if a.currentView == viewDashboard || a.currentView == viewTransactions || 
-		   a.currentView == viewBudgets || a.currentView == viewReports || 
-		   a.currentView == viewCategories || a.currentView == viewRecurring {
+		if a.digest != nil && a.currentView == viewDashboard {
+			a.digest = nil
+			if msg.String() == "ctrl+c" {
+				return a, tea.Quit
+			}
+			return a, nil
+		}
+		if a.pendingUndo != nil {
+			return a, a.handleUndoConfirm(msg)
+		}
+		if a.cancelExport != nil && msg.String() == "esc" {
+			a.cancelExport()
+			return a, nil
+		}
+		if a.paletteOpen {
+			a.palette, cmd = a.palette.Update(msg)
+			return a, cmd
+		}
+
+		// ctrl+p opens the palette from anywhere, ":" only outside forms
+		if msg.String() == "ctrl+p" {
+			a.paletteOpen = true
+			return a, a.palette.Open()
+		}
+		
+		if (a.currentView == viewDashboard && !a.dashboard.IsQuickAdding() && !a.dashboard.IsExtending()) || (a.currentView == viewTransactions && !a.transactionList.IsJumping() && !a.transactionList.IsAttaching()) || 
+		   (a.currentView == viewBudgets && !a.budgetList.IsConfirming() && !a.budgetList.IsBootstrapping() && !a.budgetList.IsShowingHistory()) || 
+		   (a.currentView == viewReports && !a.reports.IsExporting() && !a.reports.IsShowingCalendar() && !a.reports.IsPickingRange()) || 
+		   (a.currentView == viewCategories && !a.categoryList.IsEditing()) ||
+		   (a.currentView == viewRecurring && !a.recurringList.IsShowingHistory() && !a.recurringList.IsEditing() && !a.recurringList.IsMarking() && !a.recurringList.IsConfirming()) {
 			switch msg.String() {
 			case "q", "ctrl+c":
 				return a, tea.Quit
+			case ":":
+				a.paletteOpen = true
+				return a, a.palette.Open()
 			case "n":
 				if a.currentView == viewDashboard || a.currentView == viewTransactions {
-					a.currentView = viewTransactionForm
+					a.show(viewTransactionForm)
 					a.transactionForm.Reset()
 					return a, a.transactionForm.Init()
 				} else if a.currentView == viewBudgets {
-					a.currentView = viewBudgetForm
+					a.show(viewBudgetForm)
 					a.budgetForm.Reset()
 					return a, a.budgetForm.Init()
 				} else if a.currentView == viewRecurring {
-					a.currentView = viewRecurringForm
 					a.recurringForm = views.NewRecurringFormModel(a.recurringService, a.categoryService, nil)
+					a.show(viewRecurringForm)
 					return a, a.recurringForm.Init()
 				}
 			case "t":
-				a.currentView = viewTransactions
+				// Reports use 't' to return to the current month
+				if a.currentView == viewReports {
+					break
+				}
+				a.show(viewTransactions)
+				a.transactionList.ClearDateFilter()
 				return a, a.transactionList.Init()
 			case "b":
-				a.currentView = viewBudgets
+				a.show(viewBudgets)
 				return a, a.budgetList.Init()
 			case "r":
-				a.currentView = viewReports
+				a.show(viewReports)
 				return a, a.reports.Init()
 			case "c":
-				a.currentView = viewCategories
+				a.show(viewCategories)
 				return a, a.categoryList.Init()
 			case "u":
-				a.currentView = viewCurrencySettings
+				a.show(viewCurrencySettings)
 				return a, a.currencySettings.Init()
+			case "g":
+				// The recurring list uses 'g' to change its grouping, and
+				// the transaction list to go to a month
+				if a.currentView == viewRecurring || a.currentView == viewTransactions {
+					break
+				}
+				a.show(viewSettings)
+				return a, a.settingsView.Init()
 			case "s":
-				a.currentView = viewRecurring
+				a.show(viewRecurring)
 				return a, a.recurringList.Init()
+			case "A":
+				// The transaction list uses 'A' to attach a file
+				if a.accountService == nil || a.currentView == viewTransactions {
+					break
+				}
+				a.show(viewAccounts)
+				return a, a.accountList.Init()
+			case "U", "ctrl+z":
+				if a.undoService == nil {
+					break
+				}
+				a.err = nil
+				a.pendingUndo = a.undoService.Peek()
+				if a.pendingUndo == nil {
+					return a, a.statusBar.Show(views.StatusMsg{Text: "Nothing to undo"})
+				}
+				return a, nil
 			case "esc":
-				a.currentView = viewDashboard
+				a.show(viewDashboard)
 				return a, a.dashboard.Init()
 			}
 		}
 
 	case views.TransactionSavedMsg:
-		a.currentView = viewDashboard
+		a.show(viewDashboard)
 		return a, a.dashboard.Init()
 		
+	case views.QuickAddFullFormMsg:
+		a.show(viewTransactionForm)
+		if msg.Transaction != nil {
+			a.transactionForm.Prefill(msg.Transaction)
+		} else {
+			a.transactionForm.Reset()
+		}
+		return a, a.transactionForm.Init()
+		
 	case views.TransactionCancelledMsg:
-		if a.transactionList.HasTransactions() {
-			a.currentView = viewTransactions
+		if a.transactionList != nil && a.transactionList.HasTransactions() {
+			a.show(viewTransactions)
 			return a, a.transactionList.Init()
 		} else {
-			a.currentView = viewDashboard
+			a.show(viewDashboard)
 			return a, a.dashboard.Init()
 		}
 		
 	case views.TransactionEditMsg:
-		a.currentView = viewTransactionForm
+		a.show(viewTransactionForm)
 		a.transactionForm.SetTransaction(msg.Transaction)
 		return a, a.transactionForm.Init()
 		
+	case views.ShowDayTransactionsMsg:
+		a.show(viewTransactions)
+		a.transactionList.SetDateFilter(msg.Date)
+		return a, a.transactionList.Init()
+		
+	case views.TransactionDetailMsg:
+		a.show(viewTransactionDetail)
+		return a, a.transactionDetail.SetTransaction(msg.Transaction)
+		
+	case views.TransactionDetailClosedMsg:
+		a.show(viewTransactions)
+		return a, a.transactionList.Init()
+		
+	case views.RecurringRuleViewMsg:
+		a.recurringForm = views.NewRecurringFormModel(a.recurringService, a.categoryService, msg.Rule)
+		a.show(viewRecurringForm)
+		return a, a.recurringForm.Init()
+		
 	case views.BudgetSavedMsg:
-		a.currentView = viewBudgets
+		a.show(viewBudgets)
 		return a, a.budgetList.Init()
 		
 	case views.BudgetCancelledMsg:
-		a.currentView = viewBudgets
+		a.show(viewBudgets)
 		return a, a.budgetList.Init()
 		
 	case views.BudgetEditMsg:
-		a.currentView = viewBudgetForm
+		a.show(viewBudgetForm)
 		a.budgetForm.SetBudget(msg.Budget)
 		return a, a.budgetForm.Init()
 		
 	case views.BackToDashboardMsg:
-		a.currentView = viewDashboard
+		a.show(viewDashboard)
+		return a, a.dashboard.Init()
+		
+	case views.WelcomeClosedMsg:
+		a.welcome = nil
+		a.show(viewDashboard)
 		return a, a.dashboard.Init()
+		
+	case views.PaletteClosedMsg:
+		a.paletteOpen = false
+		return a, nil
+		
+	case views.PaletteSelectedMsg:
+		a.paletteOpen = false
+		return a, a.runCommand(msg.ID)
+		
+	case views.SettingsSavedMsg:
+		a.applyUISettings(msg.UI)
+		a.buildViews()
+		a.refreshStatus()
+		return a, nil
+	}
+	
+	if a.statusBar.Update(msg) {
+		return a, nil
 	}
 
 	switch a.currentView {
@@ -185,6 +532,8 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		a.transactionList, cmd = a.transactionList.Update(msg)
 	case viewTransactionForm:
 		a.transactionForm, cmd = a.transactionForm.Update(msg)
+	case viewTransactionDetail:
+		a.transactionDetail, cmd = a.transactionDetail.Update(msg)
 	case viewBudgets:
 		a.budgetList, cmd = a.budgetList.Update(msg)
 	case viewBudgetForm:
@@ -199,11 +548,6 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		var model tea.Model
 		model, cmd = a.recurringList.Update(msg)
 		a.recurringList = model.(*views.RecurringListModel)
-		// Handle navigation back to dashboard on ESC/Q
-		if msg, ok := msg.(tea.KeyMsg); ok && (msg.String() == "esc" || msg.String() == "q") {
-			a.currentView = viewDashboard
-			return a, a.dashboard.Init()
-		}
 	case viewRecurringForm:
 		if a.recurringForm != nil {
 			var model tea.Model
@@ -211,12 +555,18 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			a.recurringForm = model.(*views.RecurringFormModel)
 			
 			if a.recurringForm.IsCompleted() || a.recurringForm.IsCancelled() {
-				a.currentView = viewRecurring
+				a.show(viewRecurring)
 				return a, a.recurringList.Init()
 			}
 		}
 	case viewCurrencySettings:
 		a.currencySettings, cmd = a.currencySettings.Update(msg)
+	case viewSettings:
+		a.settingsView, cmd = a.settingsView.Update(msg)
+	case viewAccounts:
+		a.accountList, cmd = a.accountList.Update(msg)
+	case viewWelcome:
+		a.welcome, cmd = a.welcome.Update(msg)
 	}
 
 	cmds = append(cmds, cmd)
@@ -224,9 +574,13 @@ func (a *App) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (a *App) View() string {
-	if a.width == 0 || a.height == 0 {
+	if a.size.Width == 0 || a.size.Height == 0 {
 		return "Loading..."
 	}
+	if a.size.Width < minWidth || a.size.Height < minHeight {
+		return lipgloss.Place(a.size.Width, a.size.Height, lipgloss.Center, lipgloss.Center,
+			styles.WarningStyle.Render(fmt.Sprintf("Terminal too small (need %dx%d)", recommendedWidth, recommendedHeight)))
+	}
 
 	var content string
 	
@@ -237,6 +591,8 @@ func (a *App) View() string {
 		content = a.transactionList.View()
 	case viewTransactionForm:
 		content = a.transactionForm.View()
+	case viewTransactionDetail:
+		content = a.transactionDetail.View()
 	case viewBudgets:
 		content = a.budgetList.View()
 	case viewBudgetForm:
@@ -253,6 +609,24 @@ func (a *App) View() string {
 		}
 	case viewCurrencySettings:
 		content = a.currencySettings.View()
+	case viewSettings:
+		content = a.settingsView.View()
+	case viewAccounts:
+		content = a.accountList.View()
+	case viewWelcome:
+		content = a.welcome.View()
+	}
+
+	if a.digest != nil && a.currentView == viewDashboard {
+		content = lipgloss.Place(a.size.Width, a.viewHeight(), lipgloss.Center, lipgloss.Center, a.digest.View())
+	}
+
+	if a.paletteOpen {
+		content = lipgloss.Place(a.size.Width, a.viewHeight(), lipgloss.Center, lipgloss.Center, a.palette.View())
+	}
+
+	if a.pendingUndo != nil {
+		content += "\n" + styles.WarningStyle.Render(fmt.Sprintf("⚠️  Undo %s? (y/n)", a.pendingUndo.Description))
 	}
 
 	if a.err != nil {
@@ -263,37 +637,177 @@ func (a *App) View() string {
 		content += "\n" + errorStyle.Render(fmt.Sprintf("Error: %v", a.err))
 	}
 
-	return content
+	// The status bar stays on the bottom line however tall the view is
+	return lipgloss.PlaceVertical(a.viewHeight(), lipgloss.Top, content) + "\n" + a.statusBar.View()
 }
 
-func (a *App) updateViewSizes() {
-	if a.dashboard != nil {
-		a.dashboard.SetSize(a.width, a.height)
+func (a *App) handleUndoConfirm(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "y", "Y":
+		a.pendingUndo = nil
+		action, err := a.undoService.Undo(context.Background())
+		if err != nil {
+			a.err = err
+			return nil
+		}
+		return tea.Batch(a.reloadCurrentView(), a.statusBar.Show(views.StatusMsg{Text: fmt.Sprintf("Undid %s", action.Description)}))
+	case "n", "N", "esc":
+		a.pendingUndo = nil
 	}
-	if a.transactionList != nil {
-		a.transactionList.SetSize(a.width, a.height)
+	return nil
+}
+
+// paletteCommands lists the actions offered by the command palette
+func (a *App) paletteCommands() []views.PaletteCommand {
+	commands := []views.PaletteCommand{
+		{ID: "new-transaction", Title: "New transaction", Key: "n"},
+		{ID: "dashboard", Title: "Go to dashboard", Key: "esc"},
+		{ID: "transactions", Title: "Go to transactions", Key: "t"},
+		{ID: "budgets", Title: "Go to budgets", Key: "b"},
+		{ID: "new-budget", Title: "Add budget", Key: "b, n"},
+		{ID: "reports", Title: "Go to reports", Key: "r"},
+		{ID: "categories", Title: "Go to categories", Key: "c"},
+		{ID: "recurring", Title: "Go to recurring transactions", Key: "s"},
+		{ID: "new-recurring", Title: "New recurring transaction", Key: "s, n"},
+		{ID: "currencies", Title: "Currency settings", Key: "u"},
+		{ID: "settings", Title: "Settings", Key: "g"},
 	}
-	if a.transactionForm != nil {
-		a.transactionForm.SetSize(a.width, a.height)
+	if a.accountService != nil {
+		commands = append(commands, views.PaletteCommand{ID: "accounts", Title: "Go to accounts", Key: "A"})
 	}
-	if a.budgetList != nil {
-		a.budgetList.SetSize(a.width, a.height)
+	if a.exportService != nil {
+		commands = append(commands,
+			views.PaletteCommand{ID: "export-csv", Title: "Export transactions to CSV"},
+			views.PaletteCommand{ID: "export-report", Title: "Export this month's report to Markdown"},
+		)
 	}
-	if a.budgetForm != nil {
-		a.budgetForm.SetSize(a.width, a.height)
+	if a.undoService != nil {
+		commands = append(commands, views.PaletteCommand{ID: "undo", Title: "Undo last action", Key: "U"})
 	}
-	if a.reports != nil {
-		a.reports.SetSize(a.width, a.height)
+	return append(commands, views.PaletteCommand{ID: "quit", Title: "Quit", Key: "q"})
+}
+
+// runCommand performs the palette command with the given ID
+func (a *App) runCommand(id string) tea.Cmd {
+	a.err = nil
+
+	switch id {
+	case "new-transaction":
+		a.show(viewTransactionForm)
+		a.transactionForm.Reset()
+		return a.transactionForm.Init()
+	case "dashboard":
+		a.show(viewDashboard)
+		return a.dashboard.Init()
+	case "transactions":
+		a.show(viewTransactions)
+		a.transactionList.ClearDateFilter()
+		return a.transactionList.Init()
+	case "budgets":
+		a.show(viewBudgets)
+		return a.budgetList.Init()
+	case "new-budget":
+		a.show(viewBudgetForm)
+		a.budgetForm.Reset()
+		return a.budgetForm.Init()
+	case "reports":
+		a.show(viewReports)
+		return a.reports.Init()
+	case "categories":
+		a.show(viewCategories)
+		return a.categoryList.Init()
+	case "recurring":
+		a.show(viewRecurring)
+		return a.recurringList.Init()
+	case "new-recurring":
+		a.recurringForm = views.NewRecurringFormModel(a.recurringService, a.categoryService, nil)
+		a.show(viewRecurringForm)
+		return a.recurringForm.Init()
+	case "currencies":
+		a.show(viewCurrencySettings)
+		return a.currencySettings.Init()
+	case "settings":
+		a.show(viewSettings)
+		return a.settingsView.Init()
+	case "accounts":
+		a.show(viewAccounts)
+		return a.accountList.Init()
+	case "export-csv":
+		return a.exportFile("transactions", ".csv", func(ctx context.Context, f *os.File) error {
+			return a.exportService.ExportTransactionsCSV(ctx, f, &models.TransactionFilter{})
+		})
+	case "export-report":
+		now := time.Now()
+		return a.exportFile("report", ".md", func(ctx context.Context, f *os.File) error {
+			report, err := a.exportService.GenerateMonthlyReportMarkdown(ctx, now.Year(), now.Month())
+			if err != nil {
+				return err
+			}
+			_, err = f.WriteString(report)
+			return err
+		})
+	case "undo":
+		a.pendingUndo = a.undoService.Peek()
+		if a.pendingUndo == nil {
+			return a.statusBar.Show(views.StatusMsg{Text: "Nothing to undo"})
+		}
+	case "quit":
+		return tea.Quit
 	}
-	if a.categoryList != nil {
-		model, _ := a.categoryList.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
-		a.categoryList = model.(*views.CategoryListModel)
+	return nil
+}
+
+type exportDoneMsg struct {
+	path string
+	err  error
+}
+
+// exportFile writes an export to a new timestamped file in the export
+// directory in the background and reports where it went. Until it is done,
+// esc cancels it and removes the partly written file.
+func (a *App) exportFile(name, ext string, write func(context.Context, *os.File) error) tea.Cmd {
+	if a.cancelExport != nil {
+		return a.statusBar.Show(views.StatusMsg{Text: "An export is already running", IsError: true})
 	}
-	if a.recurringList != nil {
-		model, _ := a.recurringList.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
-		a.recurringList = model.(*views.RecurringListModel)
+	path := filepath.Join(a.exportDir, fmt.Sprintf("burnwise-%s-%s%s", name, time.Now().Format("20060102-150405"), ext))
+
+	ctx, cancel := context.WithCancel(context.Background())
+	a.cancelExport = cancel
+	export := func() tea.Msg {
+		defer cancel()
+
+		file, err := os.Create(path)
+		if err != nil {
+			return exportDoneMsg{err: fmt.Errorf("failed to create export file: %w", err)}
+		}
+		defer file.Close()
+
+		if err := write(ctx, file); err != nil {
+			if ctx.Err() != nil {
+				file.Close()
+				os.Remove(path)
+			}
+			return exportDoneMsg{err: fmt.Errorf("export failed: %w", err)}
+		}
+		return exportDoneMsg{path: path}
 	}
-	if a.currencySettings != nil {
-		a.currencySettings, _ = a.currencySettings.Update(tea.WindowSizeMsg{Width: a.width, Height: a.height})
+	return tea.Batch(a.statusBar.Show(views.StatusMsg{Text: "Exporting... (esc to cancel)"}), export)
+}
+
+// reloadCurrentView refreshes the data shown in the active view
+func (a *App) reloadCurrentView() tea.Cmd {
+	switch a.currentView {
+	case viewTransactions:
+		return a.transactionList.Init()
+	case viewBudgets:
+		return a.budgetList.Init()
+	case viewReports:
+		return a.reports.Init()
+	case viewCategories:
+		return a.categoryList.Init()
+	case viewRecurring:
+		return a.recurringList.Init()
+	default:
+		return a.dashboard.Init()
 	}
-}
\ No newline at end of file
+}
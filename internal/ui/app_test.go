@@ -0,0 +1,495 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"burnwise/internal/models"
+	"burnwise/internal/repository"
+	"burnwise/internal/service"
+	"burnwise/internal/ui/views"
+	test "burnwise/test/helpers"
+)
+
+func newTestApp(t *testing.T) *App {
+	t.Helper()
+
+	db := test.SetupTestDB(t)
+	test.SeedDefaultCategories(t, db)
+	settingsService, err := service.NewSettingsService(t.TempDir())
+	require.NoError(t, err)
+
+	txRepo := repository.NewTransactionRepository(db)
+	budgetRepo := repository.NewBudgetRepository(db)
+	recurringRepo := repository.NewRecurringTransactionRepository(db)
+	currencyService := service.NewCurrencyService(settingsService)
+	txService := service.NewTransactionService(txRepo, currencyService)
+	categoryService := service.NewCategoryService(repository.NewCategoryRepository(db))
+	budgetService := service.NewBudgetService(budgetRepo, txRepo)
+	recurringService := service.NewRecurringTransactionService(recurringRepo, txRepo, currencyService)
+	accountService := service.NewAccountService(repository.NewAccountRepository(db))
+
+	// Enough data for the bars, tables and lists to have something to draw
+	food := test.CreateTestCategory(t, db, "Food", models.TransactionTypeExpense)
+	tx := test.CreateTestTransaction(t, db, 42.5, food.ID)
+	require.NoError(t, db.Model(tx).Update("description", "A rather long description that won't fit").Error)
+	test.CreateTestBudget(t, db, food.ID, 30)
+	visa := &models.Account{Name: "Visa", Type: models.AccountTypeCredit}
+	require.NoError(t, accountService.Create(t.Context(), visa))
+	require.NoError(t, db.Model(tx).Update("account_id", visa.ID).Error)
+
+	app := NewApp(txService, categoryService, budgetService, currencyService, settingsService, recurringService, service.NewUndoService(service.DefaultUndoLimit))
+	app.SetAccountService(accountService)
+	return app
+}
+
+// load runs a view's loading command and hands its messages back to the
+// app, without following up on the commands those return
+func load(a *App, cmd tea.Cmd) {
+	if cmd == nil {
+		return
+	}
+	msg := cmd()
+	if batch, ok := msg.(tea.BatchMsg); ok {
+		for _, cmd := range batch {
+			load(a, cmd)
+		}
+		return
+	}
+	a.Update(msg)
+}
+
+func TestApp_ViewOnNarrowTerminal(t *testing.T) {
+	a := newTestApp(t)
+	load(a, a.Init())
+	a.Update(tea.WindowSizeMsg{Width: 40, Height: 24})
+
+	for v := viewDashboard; v < viewWelcome; v++ {
+		t.Run(viewNames[v], func(t *testing.T) {
+			a.show(v)
+			if initer, ok := a.ensureView(v).(interface{ Init() tea.Cmd }); ok {
+				load(a, initer.Init())
+			}
+
+			assert.NotPanics(t, func() {
+				assert.NotContains(t, a.View(), "Terminal too small")
+			})
+		})
+	}
+}
+
+func TestApp_ViewBelowMinimumSize(t *testing.T) {
+	a := newTestApp(t)
+	load(a, a.Init())
+	a.Update(tea.WindowSizeMsg{Width: 30, Height: 24})
+
+	assert.True(t, strings.Contains(a.View(), "Terminal too small (need 80x24)"))
+}
+
+func TestApp_AttachFromTransactionList(t *testing.T) {
+	a := newTestApp(t)
+	load(a, a.Init())
+	a.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	a.show(viewTransactions)
+	load(a, a.transactionList.Init())
+
+	// 'A' attaches a file here rather than going to the accounts
+	a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("A")})
+	assert.Equal(t, viewTransactions, a.currentView)
+	require.True(t, a.transactionList.IsAttaching())
+
+	receipt := filepath.Join(t.TempDir(), "receipt.pdf")
+	require.NoError(t, os.WriteFile(receipt, []byte("%PDF"), 0o600))
+	a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(receipt)})
+	_, cmd := a.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	load(a, cmd)
+
+	assert.False(t, a.transactionList.IsAttaching())
+	assert.Contains(t, a.View(), "📎")
+}
+
+func TestApp_ExtendRecurringEndingSoon(t *testing.T) {
+	a := newTestApp(t)
+	categories, err := a.categoryService.GetAll(t.Context())
+	require.NoError(t, err)
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	endsSoon := today.AddDate(0, 0, 5)
+	gym := &models.RecurringTransaction{
+		Type:           models.TransactionTypeExpense,
+		Amount:         45,
+		Currency:       "USD",
+		CategoryID:     categories[0].ID,
+		Description:    "Gym membership",
+		Frequency:      models.FrequencyMonthly,
+		FrequencyValue: 1,
+		StartDate:      today.AddDate(-1, 0, 0),
+		EndDate:        &endsSoon,
+		NextDueDate:    today.AddDate(0, 0, 3),
+		IsActive:       true,
+	}
+	require.NoError(t, a.recurringService.Create(t.Context(), gym))
+
+	load(a, a.Init())
+	a.Update(tea.WindowSizeMsg{Width: 120, Height: 50})
+	a.show(viewDashboard)
+	load(a, a.dashboard.Init())
+	assert.Contains(t, a.View(), "Your Gym membership recurring entry ends in 5 days")
+
+	// 'E' offers a year more, and an empty date keeps it going for good
+	a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")})
+	require.True(t, a.dashboard.IsExtending())
+	for range 10 {
+		a.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+	}
+	_, cmd := a.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	load(a, cmd)
+
+	assert.False(t, a.dashboard.IsExtending())
+	found, err := a.recurringService.GetByID(t.Context(), gym.ID)
+	require.NoError(t, err)
+	assert.Nil(t, found.EndDate)
+	assert.NotContains(t, a.View(), "Gym membership recurring entry")
+}
+
+func TestApp_EditProratedBudgetKeepsAmount(t *testing.T) {
+	a := newTestApp(t)
+	budgets, err := a.budgetService.GetAll(t.Context())
+	require.NoError(t, err)
+	require.Len(t, budgets, 1)
+	now := time.Now()
+	budget := budgets[0]
+	budget.Amount = 300
+	budget.StartDate = time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	budget.ProrateFirstPeriod = true
+	require.NoError(t, a.budgetService.Update(t.Context(), budget))
+
+	load(a, a.Init())
+	a.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	a.show(viewBudgets)
+	load(a, a.budgetList.Init())
+	if now.Day() > 1 {
+		status, err := a.budgetService.GetStatus(t.Context(), budget.ID)
+		require.NoError(t, err)
+		require.Less(t, status.Budget.Amount, 300.0)
+	}
+
+	// Saving the edit form unchanged keeps the full amount, not the
+	// first period's share of it
+	_, cmd := a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("e")})
+	load(a, cmd)
+	require.Equal(t, viewBudgetForm, a.currentView)
+	for range 5 {
+		a.Update(tea.KeyMsg{Type: tea.KeyTab})
+	}
+	_, cmd = a.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	load(a, cmd)
+
+	saved, err := a.budgetService.GetByID(t.Context(), budget.ID)
+	require.NoError(t, err)
+	assert.Equal(t, 300.0, saved.Amount)
+}
+
+func TestApp_RecurringSelectionKeepsKeys(t *testing.T) {
+	a := newTestApp(t)
+	categories, err := a.categoryService.GetAll(t.Context())
+	require.NoError(t, err)
+	today := time.Now().Truncate(24 * time.Hour)
+	for _, description := range []string{"Gym membership", "Streaming"} {
+		require.NoError(t, a.recurringService.Create(t.Context(), &models.RecurringTransaction{
+			Type:           models.TransactionTypeExpense,
+			Amount:         10,
+			Currency:       "USD",
+			CategoryID:     categories[0].ID,
+			Description:    description,
+			Frequency:      models.FrequencyMonthly,
+			FrequencyValue: 1,
+			StartDate:      today,
+			NextDueDate:    today.AddDate(0, 1, 0),
+			IsActive:       true,
+		}))
+	}
+
+	load(a, a.Init())
+	a.Update(tea.WindowSizeMsg{Width: 120, Height: 50})
+	a.show(viewRecurring)
+	load(a, a.recurringList.Init())
+	press := func(key string) {
+		msg := tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(key)}
+		switch key {
+		case "esc":
+			msg = tea.KeyMsg{Type: tea.KeyEsc}
+		case " ":
+			msg = tea.KeyMsg{Type: tea.KeySpace, Runes: []rune(" ")}
+		}
+		a.Update(msg)
+	}
+
+	// esc clears the selection before it leaves the view
+	press(" ")
+	require.True(t, a.recurringList.IsMarking())
+	press("esc")
+	assert.False(t, a.recurringList.IsMarking())
+	assert.Equal(t, viewRecurring, a.currentView)
+
+	// n declines deleting the selection instead of opening the form
+	press(" ")
+	press("d")
+	require.True(t, a.recurringList.IsConfirming())
+	press("n")
+	assert.False(t, a.recurringList.IsConfirming())
+	assert.Equal(t, viewRecurring, a.currentView)
+	rules, err := a.recurringService.GetAll(t.Context())
+	require.NoError(t, err)
+	assert.Len(t, rules, 2)
+
+	press("esc")
+	press("esc")
+	assert.Equal(t, viewDashboard, a.currentView)
+}
+
+func TestApp_ExtendChosenRecurringEndingSoon(t *testing.T) {
+	a := newTestApp(t)
+	categories, err := a.categoryService.GetAll(t.Context())
+	require.NoError(t, err)
+	now := time.Now()
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.Local)
+	var rules []*models.RecurringTransaction
+	for i, description := range []string{"Gym membership", "Streaming"} {
+		endsSoon := today.AddDate(0, 0, 3+i)
+		rule := &models.RecurringTransaction{
+			Type:           models.TransactionTypeExpense,
+			Amount:         10,
+			Currency:       "USD",
+			CategoryID:     categories[0].ID,
+			Description:    description,
+			Frequency:      models.FrequencyMonthly,
+			FrequencyValue: 1,
+			StartDate:      today.AddDate(-1, 0, 0),
+			EndDate:        &endsSoon,
+			NextDueDate:    today.AddDate(0, 0, 1),
+			IsActive:       true,
+		}
+		require.NoError(t, a.recurringService.Create(t.Context(), rule))
+		rules = append(rules, rule)
+	}
+
+	load(a, a.Init())
+	a.Update(tea.WindowSizeMsg{Width: 120, Height: 50})
+	a.show(viewDashboard)
+	load(a, a.dashboard.Init())
+	assert.Contains(t, a.View(), "extend or keep Gym membership going")
+
+	// Down picks the second rule, which 'E' then extends
+	a.Update(tea.KeyMsg{Type: tea.KeyDown})
+	assert.Contains(t, a.View(), "extend or keep Streaming going")
+	a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("E")})
+	require.True(t, a.dashboard.IsExtending())
+	_, cmd := a.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	load(a, cmd)
+
+	gym, err := a.recurringService.GetByID(t.Context(), rules[0].ID)
+	require.NoError(t, err)
+	assert.True(t, gym.EndDate.Equal(*rules[0].EndDate))
+	streaming, err := a.recurringService.GetByID(t.Context(), rules[1].ID)
+	require.NoError(t, err)
+	assert.True(t, streaming.EndDate.Equal(rules[1].EndDate.AddDate(1, 0, 0)))
+}
+
+func TestApp_MonthlyLimitCheckedAfterSettingsSave(t *testing.T) {
+	a := newTestApp(t)
+	require.NoError(t, a.settingsService.SetMonthlyLimit(1000))
+	a.Update(views.SettingsSavedMsg{UI: a.settingsService.Get().UI})
+	load(a, a.Init())
+	a.Update(tea.WindowSizeMsg{Width: 120, Height: 50})
+	a.show(viewDashboard)
+	load(a, a.dashboard.Init())
+	assert.NotContains(t, a.View(), "Monthly spending limit exceeded")
+
+	// Lowering the limit below this month's $42.50 warns on the next load
+	require.NoError(t, a.settingsService.SetMonthlyLimit(40))
+	a.Update(views.SettingsSavedMsg{UI: a.settingsService.Get().UI})
+	load(a, a.dashboard.Init())
+	assert.Contains(t, a.View(), "Monthly spending limit exceeded")
+}
+
+func TestApp_PinCategoryInBackground(t *testing.T) {
+	a := newTestApp(t)
+	load(a, a.Init())
+	a.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	a.show(viewCategories)
+	load(a, a.ensureView(viewCategories).(interface{ Init() tea.Cmd }).Init())
+
+	pinned := func() int {
+		categories, err := a.categoryService.GetAll(t.Context())
+		require.NoError(t, err)
+		count := 0
+		for _, category := range categories {
+			if category.IsPinned {
+				count++
+			}
+		}
+		return count
+	}
+
+	// 'p' leaves saving to its command rather than blocking the update
+	_, cmd := a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("p")})
+	require.NotNil(t, cmd)
+	assert.Zero(t, pinned())
+	load(a, cmd)
+	assert.Equal(t, 1, pinned())
+	assert.Contains(t, a.View(), "to the top of the forms")
+}
+
+func TestApp_ReportExport(t *testing.T) {
+	a := newTestApp(t)
+	dir := t.TempDir()
+	a.SetExportService(service.NewExportService(a.txService), dir)
+	load(a, a.Init())
+	a.Update(tea.WindowSizeMsg{Width: 120, Height: 50})
+	a.show(viewReports)
+	load(a, a.ensureView(viewReports).(interface{ Init() tea.Cmd }).Init())
+
+	a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	_, export := a.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	require.NotNil(t, export)
+
+	// Keys other than esc wait for the export
+	_, cmd := a.Update(tea.KeyMsg{Type: tea.KeyLeft})
+	assert.Nil(t, cmd)
+	load(a, export)
+	now := time.Now()
+	assert.Contains(t, a.View(), fmt.Sprintf("Exported %s %d to %s", now.Month(), now.Year(), dir))
+	files, err := filepath.Glob(filepath.Join(dir, "*.csv"))
+	require.NoError(t, err)
+	assert.Len(t, files, 1)
+}
+
+func TestApp_RemoveAttachment(t *testing.T) {
+	a := newTestApp(t)
+	transactions, err := a.txService.GetAll(t.Context())
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	dir := t.TempDir()
+	for _, name := range []string{"receipt.pdf", "invoice.pdf"} {
+		path := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(path, []byte("%PDF"), 0644))
+		_, err := a.txService.AddAttachment(t.Context(), transactions[0].ID, path)
+		require.NoError(t, err)
+	}
+	require.NoError(t, os.Remove(filepath.Join(dir, "receipt.pdf")))
+	tx, err := a.txService.GetByID(t.Context(), transactions[0].ID)
+	require.NoError(t, err)
+
+	load(a, a.Init())
+	a.Update(tea.WindowSizeMsg{Width: 120, Height: 50})
+	a.ensureView(viewTransactionDetail)
+	load(a, func() tea.Msg { return views.TransactionDetailMsg{Transaction: tx} })
+	// Only the deleted file is marked, however long the paths wrap
+	assert.Equal(t, 1, strings.Count(a.View(), "(missing)"))
+
+	// Down picks the second attachment; declining keeps it
+	a.Update(tea.KeyMsg{Type: tea.KeyDown})
+	a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	assert.Contains(t, a.View(), "Remove attachment invoice.pdf?")
+	a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	assert.NotContains(t, a.View(), "Remove attachment")
+
+	a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("x")})
+	_, cmd := a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	load(a, cmd)
+	assert.Contains(t, a.View(), "Removed attachment invoice.pdf")
+	assert.NotContains(t, a.View(), "[↑/↓] choose")
+	attachments, err := a.txService.GetAttachments(t.Context(), tx.ID)
+	require.NoError(t, err)
+	require.Len(t, attachments, 1)
+	assert.Equal(t, filepath.Join(dir, "receipt.pdf"), attachments[0].Path)
+	// The file itself is kept
+	assert.FileExists(t, filepath.Join(dir, "invoice.pdf"))
+}
+
+func TestApp_ReportDateRange(t *testing.T) {
+	a := newTestApp(t)
+	load(a, a.Init())
+	a.Update(tea.WindowSizeMsg{Width: 120, Height: 50})
+	a.show(viewReports)
+	load(a, a.ensureView(viewReports).(interface{ Init() tea.Cmd }).Init())
+
+	typeDate := func(value string) {
+		for range 10 {
+			a.Update(tea.KeyMsg{Type: tea.KeyBackspace})
+		}
+		a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(value)})
+	}
+
+	a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	require.True(t, a.reports.IsPickingRange())
+	assert.Contains(t, a.View(), "Date range")
+
+	// A range ending before it starts is refused
+	typeDate("2025-01-20")
+	a.Update(tea.KeyMsg{Type: tea.KeyTab})
+	typeDate("2025-01-10")
+	_, cmd := a.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	assert.Nil(t, cmd)
+	assert.True(t, a.reports.IsPickingRange())
+	assert.Contains(t, a.View(), "the range ends before it starts")
+
+	a.Update(tea.KeyMsg{Type: tea.KeyTab})
+	typeDate("2025-01-10")
+	a.Update(tea.KeyMsg{Type: tea.KeyTab})
+	typeDate("2025-01-20")
+	_, cmd = a.Update(tea.KeyMsg{Type: tea.KeyEnter})
+	load(a, cmd)
+	assert.False(t, a.reports.IsPickingRange())
+	assert.Contains(t, a.View(), "(11 days)")
+
+	// Shifting keeps the length, and esc leaves the prompt as it was
+	_, cmd = a.Update(tea.KeyMsg{Type: tea.KeyRight})
+	load(a, cmd)
+	a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("d")})
+	assert.Contains(t, a.View(), "2025-01-21")
+	assert.Contains(t, a.View(), "2025-01-31")
+	a.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	assert.False(t, a.reports.IsPickingRange())
+	assert.Contains(t, a.View(), "(11 days)")
+}
+
+func TestApp_YearlyReportLoadsWhenShown(t *testing.T) {
+	a := newTestApp(t)
+	load(a, a.Init())
+	a.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	a.show(viewReports)
+	load(a, a.ensureView(viewReports).(interface{ Init() tea.Cmd }).Init())
+
+	// The report is only loaded once 'Y' shows it
+	_, cmd := a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Y")})
+	require.NotNil(t, cmd)
+	assert.Contains(t, a.View(), "Loading yearly report")
+	load(a, cmd)
+	assert.Contains(t, a.View(), "Annual Report")
+}
+
+func TestApp_YearOverYearLoadsWhenShown(t *testing.T) {
+	a := newTestApp(t)
+	load(a, a.Init())
+	a.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	a.show(viewReports)
+	load(a, a.ensureView(viewReports).(interface{ Init() tea.Cmd }).Init())
+
+	// The comparison is only loaded once 'y' shows it
+	_, cmd := a.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})
+	require.NotNil(t, cmd)
+	assert.Contains(t, a.View(), "Loading comparison")
+	load(a, cmd)
+	now := time.Now()
+	assert.Contains(t, a.View(), fmt.Sprintf("%s %d vs %s %d", now.Month(), now.Year(), now.Month(), now.Year()-1))
+}
@@ -6,26 +6,113 @@ import (
 	"time"
 	
 	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/models"
 )
 
 var (
-	Primary   = lipgloss.Color("#00BCD4")
-	Secondary = lipgloss.Color("#FF5722")
-	Success   = lipgloss.Color("#4CAF50")
-	Warning   = lipgloss.Color("#FF9800")
-	Error     = lipgloss.Color("#F44336")
-	Muted     = lipgloss.Color("#9E9E9E")
+	Primary   lipgloss.Color
+	Secondary lipgloss.Color
+	Success   lipgloss.Color
+	Warning   lipgloss.Color
+	Error     lipgloss.Color
+	Muted     lipgloss.Color
 	
-	Income    = lipgloss.Color("#4CAF50")
-	Expense   = lipgloss.Color("#F44336")
+	Income    lipgloss.Color
+	Expense   lipgloss.Color
 	
 	// Color constants for easier access
-	PrimaryColor   = "#00BCD4"
-	SecondaryColor = "#FF5722"
+	PrimaryColor   string
+	SecondaryColor string
 	
 	// Timeout for messages
 	MessageTimeout = 3 * time.Second
 	
+	// Display preferences, set from the UI settings
+	DateFormat    = "2006-01-02"
+	DecimalPlaces = 2
+	
+	// Styles, rebuilt whenever the theme changes
+	TitleStyle            lipgloss.Style
+	HeaderStyle           lipgloss.Style
+	IncomeStyle           lipgloss.Style
+	ExpenseStyle          lipgloss.Style
+	BalanceStyle          lipgloss.Style
+	SelectedStyle         lipgloss.Style
+	FormLabelStyle        lipgloss.Style
+	FormInputStyle        lipgloss.Style
+	FormInputFocusedStyle lipgloss.Style
+	ButtonStyle           lipgloss.Style
+	ButtonInactiveStyle   lipgloss.Style
+	ErrorStyle            lipgloss.Style
+	SuccessStyle          lipgloss.Style
+	WarningStyle          lipgloss.Style
+	HelpStyle             lipgloss.Style
+	TableHeaderStyle      lipgloss.Style
+	ProgressBarStyle      lipgloss.Style
+	ProgressBarEmptyStyle lipgloss.Style
+	LabelStyle            lipgloss.Style
+	FocusedStyle          lipgloss.Style
+	OptionStyle           lipgloss.Style
+	ButtonFocusedStyle    lipgloss.Style
+	AppStyle              lipgloss.Style
+)
+
+// palette is the set of colors a theme is built from
+type palette struct {
+	primary   string
+	secondary string
+	success   string
+	warning   string
+	err       string
+	muted     string
+	income    string
+	expense   string
+}
+
+// themes maps each theme name in models.Themes to its colors
+var themes = map[string]palette{
+	"default": {"#00BCD4", "#FF5722", "#4CAF50", "#FF9800", "#F44336", "#9E9E9E", "#4CAF50", "#F44336"},
+	"ocean":   {"#2196F3", "#00BCD4", "#26A69A", "#FFB300", "#EF5350", "#78909C", "#26A69A", "#EF5350"},
+	"forest":  {"#43A047", "#8D6E63", "#66BB6A", "#FFA726", "#E53935", "#9E9E9E", "#66BB6A", "#E53935"},
+}
+
+func init() {
+	ApplyTheme("default")
+}
+
+// ApplyTheme switches the colors and rebuilds every style. Unknown themes
+// fall back to the default.
+func ApplyTheme(name string) {
+	p, ok := themes[name]
+	if !ok {
+		p = themes["default"]
+	}
+	
+	Primary = lipgloss.Color(p.primary)
+	Secondary = lipgloss.Color(p.secondary)
+	Success = lipgloss.Color(p.success)
+	Warning = lipgloss.Color(p.warning)
+	Error = lipgloss.Color(p.err)
+	Muted = lipgloss.Color(p.muted)
+	Income = lipgloss.Color(p.income)
+	Expense = lipgloss.Color(p.expense)
+	PrimaryColor = p.primary
+	SecondaryColor = p.secondary
+	
+	buildStyles()
+}
+
+// ApplyPreferences applies the UI settings to all views
+func ApplyPreferences(dateFormat string, decimalPlaces int, theme string) {
+	if dateFormat != "" {
+		DateFormat = dateFormat
+	}
+	DecimalPlaces = decimalPlaces
+	ApplyTheme(theme)
+}
+
+func buildStyles() {
 	TitleStyle = lipgloss.NewStyle().
 		Bold(true).
 		Foreground(Primary).
@@ -121,7 +208,12 @@ var (
 	
 	AppStyle = lipgloss.NewStyle().
 		Padding(1, 2)
-)
+}
+
+// FormatDate formats t with the configured date format
+func FormatDate(t time.Time) string {
+	return t.Format(DateFormat)
+}
 
 func FormatAmount(amount float64, currency string) string {
 	prefix := ""
@@ -140,7 +232,18 @@ func FormatAmount(amount float64, currency string) string {
 }
 
 func FormatNumber(n float64) string {
-	return lipgloss.NewStyle().Render(fmt.Sprintf("%.2f", n))
+	return lipgloss.NewStyle().Render(fmt.Sprintf("%.*f", DecimalPlaces, n))
+}
+
+// FormatNumberIn formats an amount in currency. Currencies whose minor unit
+// isn't two decimal places, such as JPY or BHD, always use their own;
+// others follow the configured decimal places.
+func FormatNumberIn(n float64, currency string) string {
+	decimals := models.CurrencyDecimals(currency)
+	if decimals == 2 {
+		decimals = DecimalPlaces
+	}
+	return lipgloss.NewStyle().Render(fmt.Sprintf("%.*f", decimals, n))
 }
 
 func ProgressBar(percent float64, width int) string {
@@ -151,6 +254,8 @@ func ProgressBar(percent float64, width int) string {
 		percent = 0
 	}
 	
+	// Narrow terminals can leave no room for the bar at all
+	width = max(0, width)
 	filled := int(float64(width) * percent / 100)
 	empty := width - filled
 	
@@ -166,4 +271,34 @@ func ProgressBar(percent float64, width int) string {
 	}
 	
 	return lipgloss.NewStyle().Foreground(color).Render(bar)
-}
\ No newline at end of file
+}
+
+// CategoryColor returns a category's stored color, falling back to the
+// theme's primary color when none is set. lipgloss degrades it to the
+// closest color the terminal supports.
+func CategoryColor(hex string) lipgloss.Color {
+	if len(hex) != 7 || hex[0] != '#' {
+		return Primary
+	}
+	return lipgloss.Color(hex)
+}
+
+// CategoryProgressBar renders a progress bar filled in a category's color.
+// Bars over 100% are drawn in the error color so overspending stands out.
+func CategoryProgressBar(percent float64, width int, hex string) string {
+	color := CategoryColor(hex)
+	if percent > 100 {
+		color = Error
+		percent = 100
+	}
+	if percent < 0 {
+		percent = 0
+	}
+
+	width = max(0, width)
+	filled := int(float64(width) * percent / 100)
+	empty := width - filled
+
+	return lipgloss.NewStyle().Foreground(color).Render(strings.Repeat("█", filled)) +
+		ProgressBarEmptyStyle.Render(strings.Repeat("░", empty))
+}
@@ -0,0 +1,482 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/models"
+	"burnwise/internal/service"
+	"burnwise/internal/ui/styles"
+)
+
+// Focus order of the account form
+const (
+	accountFieldName = iota
+	accountFieldType
+	accountFieldOpening
+	accountFieldCount
+)
+
+// AccountList shows each account's balance, and adds, edits and deletes
+// accounts with a small form below the list. Money is moved between
+// accounts with a transfer form the same way.
+type AccountList struct {
+	width          int
+	height         int
+	accountService *service.AccountService
+	txService      *service.TransactionService
+
+	balances []*models.AccountSummary
+	cursor   int
+	loading  bool
+	err      error
+
+	// The form, for a new account when editingAccount is nil
+	editing        bool
+	editingAccount *models.Account
+	nameInput      textinput.Model
+	accountType    models.AccountType
+	openingInput   textinput.Model
+	formFocus      int
+	formErr        error
+
+	confirmingDelete bool
+
+	// The transfer form, moving money out of transferFrom into the account
+	// at transferTo among the others
+	transferring   bool
+	transferFrom   *models.Account
+	transferTo     int
+	transferAmount textinput.Model
+	transferErr    error
+}
+
+type accountBalancesLoadedMsg struct {
+	balances []*models.AccountSummary
+	err      error
+}
+
+func NewAccountList(accountService *service.AccountService) *AccountList {
+	nameInput := textinput.New()
+	nameInput.Placeholder = "e.g. Visa, Wallet"
+	nameInput.CharLimit = 100
+	nameInput.Width = 24
+
+	openingInput := textinput.New()
+	openingInput.Placeholder = "0.00"
+	openingInput.CharLimit = 16
+	openingInput.Width = 12
+
+	transferAmount := textinput.New()
+	transferAmount.Placeholder = "0.00"
+	transferAmount.CharLimit = 16
+	transferAmount.Width = 12
+
+	return &AccountList{
+		accountService: accountService,
+		nameInput:      nameInput,
+		openingInput:   openingInput,
+		transferAmount: transferAmount,
+	}
+}
+
+// SetTransactionService enables transfers between accounts
+func (a *AccountList) SetTransactionService(txService *service.TransactionService) {
+	a.txService = txService
+}
+
+func (a *AccountList) Init() tea.Cmd {
+	a.loading = true
+	return a.loadBalances
+}
+
+func (a *AccountList) loadBalances() tea.Msg {
+	balances, err := a.accountService.GetBalances(context.Background(), time.Now())
+	return accountBalancesLoadedMsg{balances: balances, err: err}
+}
+
+// IsEditing reports whether a form or a delete confirmation is open, in
+// which case keys go to it
+func (a *AccountList) IsEditing() bool {
+	return a.editing || a.confirmingDelete || a.transferring
+}
+
+func (a *AccountList) Update(msg tea.Msg) (*AccountList, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		a.SetSize(msg.Width, msg.Height)
+
+	case accountBalancesLoadedMsg:
+		a.loading = false
+		a.balances = msg.balances
+		a.err = msg.err
+		a.cursor = min(a.cursor, max(0, len(a.accounts())-1))
+
+	case tea.KeyMsg:
+		if a.confirmingDelete {
+			return a, a.updateDeleteConfirm(msg)
+		}
+		if a.editing {
+			return a, a.updateForm(msg)
+		}
+		if a.transferring {
+			return a, a.updateTransfer(msg)
+		}
+
+		switch msg.String() {
+		case "esc", "q":
+			return a, func() tea.Msg { return BackToDashboardMsg{} }
+		case "up", "k":
+			if a.cursor > 0 {
+				a.cursor--
+			}
+		case "down", "j":
+			if a.cursor < len(a.accounts())-1 {
+				a.cursor++
+			}
+		case "n":
+			return a, a.openForm(nil)
+		case "e", "enter":
+			if account := a.selected(); account != nil {
+				return a, a.openForm(account)
+			}
+		case "d":
+			if a.selected() != nil {
+				a.confirmingDelete = true
+			}
+		case "t":
+			if account := a.selected(); account != nil && a.txService != nil && len(a.accounts()) > 1 {
+				return a, a.openTransfer(account)
+			}
+		}
+	}
+
+	return a, nil
+}
+
+// accounts are the listed balances that belong to an account, which come
+// before the one for transactions without an account
+func (a *AccountList) accounts() []*models.AccountSummary {
+	var accounts []*models.AccountSummary
+	for _, balance := range a.balances {
+		if balance.Account != nil {
+			accounts = append(accounts, balance)
+		}
+	}
+	return accounts
+}
+
+func (a *AccountList) selected() *models.Account {
+	accounts := a.accounts()
+	if a.cursor >= len(accounts) {
+		return nil
+	}
+	return accounts[a.cursor].Account
+}
+
+// openForm starts adding an account, or editing account when it is given
+func (a *AccountList) openForm(account *models.Account) tea.Cmd {
+	a.editing = true
+	a.editingAccount = account
+	a.formErr = nil
+	a.formFocus = accountFieldName
+	a.nameInput.SetValue("")
+	a.accountType = models.AccountTypes[0]
+	a.openingInput.SetValue("")
+	if account != nil {
+		a.nameInput.SetValue(account.Name)
+		a.accountType = account.Type
+		if account.OpeningBalance != 0 {
+			a.openingInput.SetValue(strconv.FormatFloat(account.OpeningBalance, 'f', 2, 64))
+		}
+	}
+	a.openingInput.Blur()
+	return a.nameInput.Focus()
+}
+
+// updateForm handles a key while the form is open: tab moves between the
+// fields, ↑/↓ change the type, enter saves and esc cancels
+func (a *AccountList) updateForm(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		a.editing = false
+		return nil
+	case "enter":
+		return a.saveForm()
+	case "tab", "shift+tab":
+		step := 1
+		if msg.String() == "shift+tab" {
+			step = accountFieldCount - 1
+		}
+		a.formFocus = (a.formFocus + step) % accountFieldCount
+		a.nameInput.Blur()
+		a.openingInput.Blur()
+		switch a.formFocus {
+		case accountFieldName:
+			return a.nameInput.Focus()
+		case accountFieldOpening:
+			return a.openingInput.Focus()
+		}
+		return nil
+	case "up", "down":
+		if a.formFocus == accountFieldType {
+			a.cycleType(msg.String() == "up")
+			return nil
+		}
+	}
+
+	var cmd tea.Cmd
+	switch a.formFocus {
+	case accountFieldName:
+		a.nameInput, cmd = a.nameInput.Update(msg)
+	case accountFieldOpening:
+		a.openingInput, cmd = a.openingInput.Update(msg)
+	}
+	return cmd
+}
+
+func (a *AccountList) cycleType(reverse bool) {
+	current := 0
+	for i, accountType := range models.AccountTypes {
+		if accountType == a.accountType {
+			current = i
+		}
+	}
+	step := 1
+	if reverse {
+		step = len(models.AccountTypes) - 1
+	}
+	a.accountType = models.AccountTypes[(current+step)%len(models.AccountTypes)]
+}
+
+func (a *AccountList) saveForm() tea.Cmd {
+	var opening float64
+	if value := strings.TrimPrefix(strings.TrimSpace(a.openingInput.Value()), "$"); value != "" {
+		parsed, err := strconv.ParseFloat(value, 64)
+		if err != nil {
+			a.formErr = fmt.Errorf("invalid opening balance %q", a.openingInput.Value())
+			return nil
+		}
+		opening = models.RoundUSD(parsed)
+	}
+
+	account := &models.Account{}
+	if a.editingAccount != nil {
+		copied := *a.editingAccount
+		account = &copied
+	}
+	account.Name = a.nameInput.Value()
+	account.Type = a.accountType
+	account.OpeningBalance = opening
+
+	var err error
+	if account.ID == 0 {
+		err = a.accountService.Create(context.Background(), account)
+	} else {
+		err = a.accountService.Update(context.Background(), account)
+	}
+	if err != nil {
+		a.formErr = err
+		return nil
+	}
+
+	a.editing = false
+	return tea.Batch(a.loadBalances, statusInfo("Saved account "+account.Name))
+}
+
+// openTransfer starts moving money out of account
+func (a *AccountList) openTransfer(account *models.Account) tea.Cmd {
+	a.transferring = true
+	a.transferFrom = account
+	a.transferTo = 0
+	a.transferErr = nil
+	a.transferAmount.SetValue("")
+	return a.transferAmount.Focus()
+}
+
+// transferTargets are the accounts money can be moved to
+func (a *AccountList) transferTargets() []*models.Account {
+	var targets []*models.Account
+	for _, balance := range a.accounts() {
+		if balance.Account.ID != a.transferFrom.ID {
+			targets = append(targets, balance.Account)
+		}
+	}
+	return targets
+}
+
+// updateTransfer handles a key while the transfer form is open: ↑/↓ change
+// the account the money goes to, enter moves it and esc cancels
+func (a *AccountList) updateTransfer(msg tea.KeyMsg) tea.Cmd {
+	targets := a.transferTargets()
+	switch msg.String() {
+	case "esc":
+		a.transferring = false
+		return nil
+	case "up":
+		a.transferTo = (a.transferTo + len(targets) - 1) % len(targets)
+		return nil
+	case "down":
+		a.transferTo = (a.transferTo + 1) % len(targets)
+		return nil
+	case "enter":
+		return a.saveTransfer(targets[a.transferTo])
+	}
+
+	var cmd tea.Cmd
+	a.transferAmount, cmd = a.transferAmount.Update(msg)
+	return cmd
+}
+
+func (a *AccountList) saveTransfer(to *models.Account) tea.Cmd {
+	value := strings.TrimPrefix(strings.TrimSpace(a.transferAmount.Value()), "$")
+	amount, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		a.transferErr = fmt.Errorf("invalid amount %q", a.transferAmount.Value())
+		return nil
+	}
+
+	_, _, err = a.txService.CreateTransfer(context.Background(), a.transferFrom.ID, to.ID,
+		models.RoundUSD(amount), "USD", time.Now(), "Transfer to "+to.Name)
+	if err != nil {
+		a.transferErr = err
+		return nil
+	}
+
+	a.transferring = false
+	return tea.Batch(a.loadBalances, statusInfo(fmt.Sprintf("Moved %s from %s to %s",
+		styles.FormatAmount(amount, "$"), a.transferFrom.Name, to.Name)))
+}
+
+func (a *AccountList) updateDeleteConfirm(msg tea.KeyMsg) tea.Cmd {
+	a.confirmingDelete = false
+	account := a.selected()
+	if account == nil || (msg.String() != "y" && msg.String() != "Y") {
+		return nil
+	}
+	if err := a.accountService.Delete(context.Background(), account.ID); err != nil {
+		return statusError(err)
+	}
+	return tea.Batch(a.loadBalances, statusInfo("Deleted account "+account.Name))
+}
+
+func (a *AccountList) View() string {
+	title := styles.TitleStyle.Render("🏦 Accounts")
+
+	if a.loading {
+		return lipgloss.JoinVertical(lipgloss.Left, title, "", "Loading accounts...")
+	}
+	if a.err != nil {
+		return lipgloss.JoinVertical(lipgloss.Left, title, "", styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", a.err)))
+	}
+
+	muted := lipgloss.NewStyle().Foreground(styles.Muted)
+	var rows []string
+	if len(a.accounts()) == 0 {
+		rows = append(rows, muted.Render("No accounts yet. Transactions don't need one; add one with 'n' to track where money goes."))
+	} else {
+		rows = append(rows, muted.Render(fmt.Sprintf("  %-22s %-8s %6s %14s", "Account", "Type", "Txns", "Balance")))
+	}
+	for i, balance := range a.balances {
+		style := lipgloss.NewStyle()
+		if balance.Balance() < 0 {
+			style = styles.ExpenseStyle
+		}
+		accountType := ""
+		if balance.Account != nil {
+			accountType = string(balance.Account.Type)
+		}
+		row := fmt.Sprintf("%-22s %-8s %6d %14s", truncateText(balance.Name(), 22), accountType, balance.Count,
+			style.Render(styles.FormatAmount(balance.Balance(), "$")))
+
+		switch {
+		case balance.Account == nil:
+			row = muted.Render("  " + row)
+		case i == a.cursor && !a.editing && !a.transferring:
+			row = styles.SelectedStyle.Render("> " + row)
+		default:
+			row = "  " + row
+		}
+		rows = append(rows, row)
+	}
+
+	sections := []string{title, "", strings.Join(rows, "\n")}
+	if a.editing {
+		sections = append(sections, "", a.renderForm())
+	}
+	if a.transferring {
+		sections = append(sections, "", a.renderTransfer())
+	}
+	if a.confirmingDelete {
+		if account := a.selected(); account != nil {
+			sections = append(sections, "", styles.WarningStyle.Render(
+				fmt.Sprintf("Delete %s? Its transactions are kept without an account. (y/n)", account.Name)))
+		}
+	}
+
+	help := "[n]ew  [e]dit  [d]elete  [esc]back"
+	if a.txService != nil && len(a.accounts()) > 1 {
+		help = "[n]ew  [e]dit  [d]elete  [t]ransfer  [esc]back"
+	}
+	if a.editing {
+		help = "[tab]next field  [↑/↓]type  [enter]save  [esc]cancel"
+	}
+	if a.transferring {
+		help = "[↑/↓]to account  [enter]transfer  [esc]cancel"
+	}
+	sections = append(sections, "", styles.HelpStyle.Width(a.width).Render(help))
+
+	return lipgloss.JoinVertical(lipgloss.Left, sections...)
+}
+
+func (a *AccountList) renderForm() string {
+	title := "New account"
+	if a.editingAccount != nil {
+		title = "Edit " + a.editingAccount.Name
+	}
+
+	field := func(index int, label, value string) string {
+		if index == a.formFocus {
+			value = styles.FormInputFocusedStyle.Render(value)
+		} else {
+			value = styles.FormInputStyle.Render(value)
+		}
+		return lipgloss.JoinHorizontal(lipgloss.Top, styles.FormLabelStyle.Render(label), value)
+	}
+
+	rows := []string{
+		lipgloss.NewStyle().Bold(true).Render(title),
+		field(accountFieldName, "Name:", a.nameInput.View()),
+		field(accountFieldType, "Type:", string(a.accountType)),
+		field(accountFieldOpening, "Opening $:", a.openingInput.View()),
+	}
+	if a.formErr != nil {
+		rows = append(rows, styles.ErrorStyle.Render(a.formErr.Error()))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+func (a *AccountList) renderTransfer() string {
+	to := a.transferTargets()[a.transferTo]
+	rows := []string{
+		lipgloss.NewStyle().Bold(true).Render("Transfer from " + a.transferFrom.Name),
+		lipgloss.JoinHorizontal(lipgloss.Top, styles.FormLabelStyle.Render("To:"), styles.FormInputStyle.Render(to.Name)),
+		lipgloss.JoinHorizontal(lipgloss.Top, styles.FormLabelStyle.Render("Amount $:"), styles.FormInputFocusedStyle.Render(a.transferAmount.View())),
+	}
+	if a.transferErr != nil {
+		rows = append(rows, styles.ErrorStyle.Render(a.transferErr.Error()))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+func (a *AccountList) SetSize(width, height int) {
+	a.width = width
+	a.height = height
+}
@@ -0,0 +1,23 @@
+package views
+
+import (
+	"strconv"
+
+	"github.com/charmbracelet/bubbles/textinput"
+
+	"burnwise/internal/service"
+)
+
+// resolveAmount replaces an arithmetic expression typed into an amount
+// field with its value, rounded to decimals places, once the field is left.
+// Input that doesn't evaluate is left as typed for saving to report.
+func resolveAmount(input *textinput.Model, decimals int) {
+	if !service.IsAmountExpression(input.Value()) {
+		return
+	}
+	value, err := service.EvalAmount(input.Value())
+	if err != nil {
+		return
+	}
+	input.SetValue(strconv.FormatFloat(value, 'f', decimals, 64))
+}
@@ -0,0 +1,37 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestResolveAmount(t *testing.T) {
+	tests := []struct {
+		input    string
+		decimals int
+		want     string
+	}{
+		{"12.50 + 3.99 * 2", 2, "20.48"},
+		{"100 / 3", 3, "33.333"},
+		{"1500 / 4", 0, "375"},
+		{"1,5", 2, "1.50"},
+		{"84,60 / 3", 2, "28.20"},
+		{"1,250", 2, "1250.00"},
+		{"1,250.00 + 50", 2, "1300.00"},
+		{"1.234,5", 2, "1234.50"},
+		// Plain numbers and expressions that don't evaluate stay as typed
+		{"42", 2, "42"},
+		{"42.5", 2, "42.5"},
+		{"12 +", 2, "12 +"},
+		{"10 / 0", 2, "10 / 0"},
+		{"", 2, ""},
+	}
+	for _, tt := range tests {
+		input := textinput.New()
+		input.SetValue(tt.input)
+		resolveAmount(&input, tt.decimals)
+		assert.Equal(t, tt.want, input.Value(), tt.input)
+	}
+}
@@ -1,8 +1,12 @@
 package views
 
 import (
+	"context"
 	"fmt"
+	"math"
+	"sort"
 	"strconv"
+	"strings"
 	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -23,12 +27,31 @@ type BudgetForm struct {
 	editingBudget   *models.Budget
 	name            textinput.Model
 	amount          textinput.Model
+	notes           textinput.Model
+	percentMode     bool // the amount is a percent of the month's income
 	period          models.BudgetPeriod
+	prorate         bool // the first period only gets its share of the amount
 	categoryID      uint
+	selectedIDs     map[uint]bool // categories of a group budget
 	
 	categories      []*models.Category
 	focusIndex      int
 	err             error
+	
+	discard discardGuard[budgetFormValues]
+}
+
+// budgetFormValues are the form's editable values, compared with those it
+// was opened with to tell whether esc would lose anything
+type budgetFormValues struct {
+	name       string
+	amount     string
+	percent    bool
+	notes      string
+	period     models.BudgetPeriod
+	prorate    bool
+	categoryID uint
+	group      string
 }
 
 type BudgetSavedMsg struct{}
@@ -40,16 +63,25 @@ func NewBudgetForm(budgetService *service.BudgetService, categoryService *servic
 	name.Focus()
 	
 	amount := textinput.New()
-	amount.Placeholder = "0.00"
+	amount.Placeholder = "0.00 or 1200 / 3"
+	
+	notes := textinput.New()
+	notes.Placeholder = "Optional, e.g. agreed with partner 2024-05"
+	notes.CharLimit = 500
+	notes.Width = 30
 	
-	return &BudgetForm{
+	b := &BudgetForm{
 		budgetService:   budgetService,
 		categoryService: categoryService,
 		name:            name,
 		amount:          amount,
+		notes:           notes,
 		period:          models.BudgetPeriodMonthly,
+		selectedIDs:     make(map[uint]bool),
 		focusIndex:      0,
 	}
+	b.discard.reset(b.values())
+	return b
 }
 
 func (b *BudgetForm) Init() tea.Cmd {
@@ -64,17 +96,32 @@ func (b *BudgetForm) Update(msg tea.Msg) (*BudgetForm, tea.Cmd) {
 	
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if b.discard.confirming {
+			if b.discard.answer(msg.String()) {
+				return b, func() tea.Msg { return BudgetCancelledMsg{} }
+			}
+			return b, nil
+		}
+		
 		switch msg.String() {
 		case "esc":
+			if !b.discard.canLeave(b.values()) {
+				return b, nil
+			}
 			return b, func() tea.Msg { return BudgetCancelledMsg{} }
 		case "tab", "shift+tab":
 			b.nextFocus(msg.String() == "shift+tab")
 		case "enter":
-			if b.focusIndex == 4 { // Save button
+			if b.focusIndex == 5 { // Save button
 				return b, b.save
-			} else if b.focusIndex == 5 { // Cancel button
+			} else if b.focusIndex == 6 { // Cancel button
 				return b, func() tea.Msg { return BudgetCancelledMsg{} }
 			}
+		case "%":
+			if b.focusIndex == 1 { // Amount field
+				b.togglePercentMode()
+				return b, nil
+			}
 		case "p":
 			if b.focusIndex == 2 { // Period field
 				if b.period == models.BudgetPeriodMonthly {
@@ -83,10 +130,22 @@ func (b *BudgetForm) Update(msg tea.Msg) (*BudgetForm, tea.Cmd) {
 					b.period = models.BudgetPeriodMonthly
 				}
 			}
+		case "r":
+			if b.focusIndex == 2 { // Period field
+				b.prorate = !b.prorate
+			}
 		case "up", "down":
 			if b.focusIndex == 3 { // Category field
 				b.cycleCategory(msg.String() == "up")
 			}
+		case " ":
+			if b.focusIndex == 3 && b.categoryID > 0 { // Category field
+				if b.selectedIDs[b.categoryID] {
+					delete(b.selectedIDs, b.categoryID)
+				} else {
+					b.selectedIDs[b.categoryID] = true
+				}
+			}
 		}
 		
 	case categoriesLoadedMsg:
@@ -102,6 +161,8 @@ func (b *BudgetForm) Update(msg tea.Msg) (*BudgetForm, tea.Cmd) {
 		
 		if len(b.categories) > 0 && b.categoryID == 0 {
 			b.categoryID = b.categories[0].ID
+			// A new form's default category is not a change
+			b.discard.initial.categoryID = b.categoryID
 		}
 	}
 	
@@ -112,13 +173,16 @@ func (b *BudgetForm) Update(msg tea.Msg) (*BudgetForm, tea.Cmd) {
 	b.amount, cmd = b.amount.Update(msg)
 	cmds = append(cmds, cmd)
 	
+	b.notes, cmd = b.notes.Update(msg)
+	cmds = append(cmds, cmd)
+	
 	return b, tea.Batch(cmds...)
 }
 
 func (b *BudgetForm) View() string {
 	title := "Create Budget"
 	if b.editingBudget != nil {
-		title = "Edit Budget"
+		title = "Edit Budget: " + truncateText(b.editingBudget.Name, 30)
 	}
 	title = styles.TitleStyle.Render(title)
 	
@@ -131,17 +195,39 @@ func (b *BudgetForm) View() string {
 	}
 	
 	amountLabel := styles.FormLabelStyle.Render("Amount:")
+	if b.percentMode {
+		amountLabel = styles.FormLabelStyle.Render("% of income:")
+	}
 	amountInput := b.amount.View()
 	if b.focusIndex == 1 {
 		amountInput = styles.FormInputFocusedStyle.Render(amountInput)
 	} else {
 		amountInput = styles.FormInputStyle.Render(amountInput)
 	}
+	amountHint := ""
+	if b.focusIndex == 1 {
+		mode := "percent of income"
+		if b.percentMode {
+			mode = "fixed amount"
+		}
+		amountHint = lipgloss.NewStyle().Foreground(styles.Muted).Render("'%' for " + mode)
+	}
 	
 	periodLabel := styles.FormLabelStyle.Render("Period:")
 	periodValue := string(b.period)
+	if b.prorate {
+		periodValue += ", first prorated"
+	}
 	if b.focusIndex == 2 {
-		periodValue = styles.SelectedStyle.Render(periodValue + " (press 'p' to toggle)")
+		periodValue = styles.SelectedStyle.Render(periodValue + " ('p' toggle)")
+	}
+	periodHint := ""
+	if b.focusIndex == 2 {
+		mode := "prorate the first period from today"
+		if b.prorate {
+			mode = "budget the whole first period"
+		}
+		periodHint = lipgloss.NewStyle().Foreground(styles.Muted).Render("'r' to " + mode)
 	}
 	
 	categoryLabel := styles.FormLabelStyle.Render("Category:")
@@ -153,19 +239,40 @@ func (b *BudgetForm) View() string {
 				break
 			}
 		}
+		if b.selectedIDs[b.categoryID] {
+			categoryValue = "[x] " + categoryValue
+		}
 	}
 	if b.focusIndex == 3 {
-		categoryValue = styles.SelectedStyle.Render(categoryValue + " (↑/↓)")
+		categoryValue = styles.SelectedStyle.Render(categoryValue + " (↑/↓, space to group)")
+	}
+	
+	groupLabel := styles.FormLabelStyle.Render("Group:")
+	groupValue := lipgloss.NewStyle().Foreground(styles.Muted).Render("none")
+	if selected := b.selectedCategories(); len(selected) > 0 {
+		names := make([]string, len(selected))
+		for i, cat := range selected {
+			names[i] = cat.Name
+		}
+		groupValue = strings.Join(names, " + ")
+	}
+	
+	notesLabel := styles.FormLabelStyle.Render("Notes:")
+	notesInput := b.notes.View()
+	if b.focusIndex == 4 {
+		notesInput = styles.FormInputFocusedStyle.Render(notesInput)
+	} else {
+		notesInput = styles.FormInputStyle.Render(notesInput)
 	}
 	
 	saveButton := "[Save]"
 	cancelButton := "[Cancel]"
-	if b.focusIndex == 4 {
+	if b.focusIndex == 5 {
 		saveButton = styles.ButtonStyle.Render(saveButton)
 	} else {
 		saveButton = styles.ButtonInactiveStyle.Render(saveButton)
 	}
-	if b.focusIndex == 5 {
+	if b.focusIndex == 6 {
 		cancelButton = styles.ButtonStyle.Render(cancelButton)
 	} else {
 		cancelButton = styles.ButtonInactiveStyle.Render(cancelButton)
@@ -182,8 +289,12 @@ func (b *BudgetForm) View() string {
 		lipgloss.Left,
 		lipgloss.JoinHorizontal(lipgloss.Top, nameLabel, nameInput),
 		lipgloss.JoinHorizontal(lipgloss.Top, amountLabel, amountInput),
+		amountHint,
 		lipgloss.JoinHorizontal(lipgloss.Top, periodLabel, periodValue),
+		periodHint,
 		lipgloss.JoinHorizontal(lipgloss.Top, categoryLabel, categoryValue),
+		lipgloss.JoinHorizontal(lipgloss.Top, groupLabel, groupValue),
+		lipgloss.JoinHorizontal(lipgloss.Top, notesLabel, notesInput),
 		"",
 		buttons,
 	)
@@ -191,6 +302,9 @@ func (b *BudgetForm) View() string {
 	if b.err != nil {
 		form += "\n\n" + styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", b.err))
 	}
+	if discard := b.discard.view(); discard != "" {
+		form += "\n\n" + discard
+	}
 	
 	box := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -211,43 +325,106 @@ func (b *BudgetForm) Reset() {
 	b.editingBudget = nil
 	b.name.SetValue("")
 	b.amount.SetValue("")
+	b.notes.SetValue("")
+	b.percentMode = false
+	b.amount.Placeholder = "0.00 or 1200 / 3"
 	b.period = models.BudgetPeriodMonthly
+	b.prorate = false
 	b.categoryID = 0
+	b.selectedIDs = make(map[uint]bool)
 	b.focusIndex = 0
 	b.err = nil
+	b.discard.reset(b.values())
 }
 
 func (b *BudgetForm) SetBudget(budget *models.Budget) {
 	b.editingBudget = budget
 	b.name.SetValue(budget.Name)
 	b.amount.SetValue(fmt.Sprintf("%.2f", budget.Amount))
+	b.percentMode = budget.IsPercentOfIncome()
+	b.amount.Placeholder = "0.00 or 1200 / 3"
+	if b.percentMode {
+		b.amount.SetValue(strconv.FormatFloat(*budget.PercentOfIncome, 'f', -1, 64))
+		b.amount.Placeholder = "e.g. 30"
+	}
+	b.notes.SetValue(budget.Notes)
 	b.period = budget.Period
+	b.prorate = budget.ProrateFirstPeriod
 	b.categoryID = budget.CategoryID
+	b.selectedIDs = make(map[uint]bool)
+	if budget.IsGroup() {
+		for _, cat := range budget.Categories {
+			b.selectedIDs[cat.ID] = true
+		}
+	}
 	b.focusIndex = 0
 	b.err = nil
+	b.discard.reset(b.values())
+}
+
+func (b *BudgetForm) values() budgetFormValues {
+	ids := make([]uint, 0, len(b.selectedIDs))
+	for id, selected := range b.selectedIDs {
+		if selected {
+			ids = append(ids, id)
+		}
+	}
+	sort.Slice(ids, func(i, j int) bool { return ids[i] < ids[j] })
+	
+	return budgetFormValues{
+		name:       b.name.Value(),
+		amount:     b.amount.Value(),
+		percent:    b.percentMode,
+		notes:      b.notes.Value(),
+		period:     b.period,
+		prorate:    b.prorate,
+		categoryID: b.categoryID,
+		group:      fmt.Sprint(ids),
+	}
 }
 
 func (b *BudgetForm) nextFocus(reverse bool) {
+	// Show what an expression typed into the amount comes to
+	if b.focusIndex == 1 {
+		resolveAmount(&b.amount, 2)
+	}
+	
 	if reverse {
 		b.focusIndex--
 		if b.focusIndex < 0 {
-			b.focusIndex = 5
+			b.focusIndex = 6
 		}
 	} else {
 		b.focusIndex++
-		if b.focusIndex > 5 {
+		if b.focusIndex > 6 {
 			b.focusIndex = 0
 		}
 	}
 	
 	b.name.Blur()
 	b.amount.Blur()
+	b.notes.Blur()
 	
 	switch b.focusIndex {
 	case 0:
 		b.name.Focus()
 	case 1:
 		b.amount.Focus()
+	case 4:
+		b.notes.Focus()
+	}
+}
+
+// togglePercentMode switches the amount between a fixed amount and a
+// percent of the month's income. Percent-of-income budgets are monthly.
+func (b *BudgetForm) togglePercentMode() {
+	b.percentMode = !b.percentMode
+	b.amount.SetValue("")
+	if b.percentMode {
+		b.amount.Placeholder = "e.g. 30"
+		b.period = models.BudgetPeriodMonthly
+	} else {
+		b.amount.Placeholder = "0.00 or 1200 / 3"
 	}
 }
 
@@ -279,12 +456,30 @@ func (b *BudgetForm) cycleCategory(reverse bool) {
 	b.categoryID = b.categories[currentIdx].ID
 }
 
+// selectedCategories returns the categories picked for a group budget, in
+// list order
+func (b *BudgetForm) selectedCategories() []models.Category {
+	var selected []models.Category
+	for _, cat := range b.categories {
+		if b.selectedIDs[cat.ID] {
+			selected = append(selected, *cat)
+		}
+	}
+	return selected
+}
+
 func (b *BudgetForm) save() tea.Msg {
-	amount, err := strconv.ParseFloat(b.amount.Value(), 64)
+	amount, err := service.ParseAmount(strings.TrimSuffix(strings.TrimSpace(b.amount.Value()), "%"))
 	if err != nil {
-		b.err = fmt.Errorf("invalid amount")
+		b.err = fmt.Errorf("invalid amount: %w", err)
 		return nil
 	}
+	amount = math.Round(amount*100) / 100
+	var percent *float64
+	if b.percentMode {
+		percent = &amount
+		amount = 0
+	}
 	
 	name := b.name.Value()
 	if name == "" {
@@ -295,24 +490,32 @@ func (b *BudgetForm) save() tea.Msg {
 		// Update existing budget
 		b.editingBudget.Name = name
 		b.editingBudget.Amount = amount
+		b.editingBudget.PercentOfIncome = percent
 		b.editingBudget.Period = b.period
+		b.editingBudget.ProrateFirstPeriod = b.prorate
 		b.editingBudget.CategoryID = b.categoryID
+		b.editingBudget.Categories = b.selectedCategories()
+		b.editingBudget.Notes = strings.TrimSpace(b.notes.Value())
 		
-		if err := b.budgetService.Update(b.editingBudget); err != nil {
+		if err := b.budgetService.Update(context.Background(), b.editingBudget); err != nil {
 			b.err = err
 			return nil
 		}
 	} else {
 		// Create new budget
 		budget := &models.Budget{
-			Name:       name,
-			Amount:     amount,
-			Period:     b.period,
-			CategoryID: b.categoryID,
-			StartDate:  time.Now(),
+			Name:               name,
+			Amount:             amount,
+			PercentOfIncome:    percent,
+			Period:             b.period,
+			ProrateFirstPeriod: b.prorate,
+			CategoryID:         b.categoryID,
+			Categories:         b.selectedCategories(),
+			Notes:              strings.TrimSpace(b.notes.Value()),
+			StartDate:          time.Now(),
 		}
 		
-		if err := b.budgetService.Create(budget); err != nil {
+		if err := b.budgetService.Create(context.Background(), budget); err != nil {
 			b.err = err
 			return nil
 		}
@@ -322,6 +525,10 @@ func (b *BudgetForm) save() tea.Msg {
 }
 
 func (b *BudgetForm) loadCategories() tea.Msg {
-	categories, _ := b.categoryService.GetAll()
+	selected := []uint{b.categoryID}
+	for id := range b.selectedIDs {
+		selected = append(selected, id)
+	}
+	categories, _ := b.categoryService.GetPickable(context.Background(), models.TransactionTypeExpense, selected...)
 	return categoriesLoadedMsg{categories: categories}
 }
\ No newline at end of file
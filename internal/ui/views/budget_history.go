@@ -0,0 +1,108 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/models"
+	"burnwise/internal/ui/styles"
+)
+
+// budgetHistoryPeriods is how many periods the history shows, including the
+// current one
+const budgetHistoryPeriods = 6
+
+type budgetHistoryMsg struct {
+	budget  models.Budget
+	periods []*models.BudgetPeriodSpending
+	err     error
+}
+
+func (b *BudgetList) loadHistory(budget models.Budget) tea.Cmd {
+	return func() tea.Msg {
+		periods, err := b.budgetService.GetHistory(context.Background(), budget.ID, budgetHistoryPeriods)
+		return budgetHistoryMsg{budget: budget, periods: periods, err: err}
+	}
+}
+
+// IsShowingHistory reports whether a budget's history is open, in which
+// case esc closes it rather than leaving the list
+func (b *BudgetList) IsShowingHistory() bool {
+	return b.history != nil
+}
+
+// renderHistory shows the budgeted amount against the spending of each
+// period as a pair of bars, the spending in red where it went over
+func (b *BudgetList) renderHistory() string {
+	budget := b.history.budget
+
+	var content strings.Builder
+	content.WriteString(styles.TitleStyle.Render(fmt.Sprintf("📊 Budget vs actual: %s (%s)", budget.Name, budget.Period)))
+	content.WriteString("\n\n")
+
+	if b.history.err != nil {
+		content.WriteString(styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", b.history.err)))
+		content.WriteString("\n\n")
+	}
+
+	var highest float64
+	for _, period := range b.history.periods {
+		highest = math.Max(highest, math.Max(period.Budgeted, period.Spent))
+	}
+	barWidth := b.width - 50
+	if barWidth > 40 {
+		barWidth = 40
+	}
+	if barWidth < 10 {
+		barWidth = 10
+	}
+	bar := func(amount float64) int {
+		if highest == 0 {
+			return 0
+		}
+		return int(math.Round(amount / highest * float64(barWidth)))
+	}
+
+	muted := lipgloss.NewStyle().Foreground(styles.Muted)
+	var overCount int
+	for _, period := range b.history.periods {
+		label := period.Start.Format("Jan 2006")
+		if budget.Period == models.BudgetPeriodYearly {
+			label = period.Start.Format("2006")
+		}
+
+		spentStyle := styles.SuccessStyle
+		note := ""
+		if period.IsOver() {
+			overCount++
+			spentStyle = styles.ErrorStyle
+			note = styles.ErrorStyle.Render(fmt.Sprintf("  over by $%s", styles.FormatNumber(period.Spent-period.Budgeted)))
+		}
+		if period.InProgress {
+			note += muted.Render("  (in progress)")
+		}
+
+		budgetedBar := strings.Repeat("░", bar(period.Budgeted))
+		spentBar := strings.Repeat("█", bar(period.Spent))
+		content.WriteString(fmt.Sprintf("%-9s budget %s %s\n", label,
+			muted.Render(budgetedBar+strings.Repeat(" ", barWidth-lipgloss.Width(budgetedBar))),
+			muted.Render("$"+styles.FormatNumber(period.Budgeted))))
+		content.WriteString(fmt.Sprintf("%-9s spent  %s %s%s\n\n", "",
+			spentStyle.Render(spentBar+strings.Repeat(" ", barWidth-lipgloss.Width(spentBar))),
+			"$"+styles.FormatNumber(period.Spent), note))
+	}
+
+	if len(b.history.periods) > 0 {
+		content.WriteString(lipgloss.NewStyle().Bold(true).Render(
+			fmt.Sprintf("Over budget in %d of %d periods", overCount, len(b.history.periods))))
+		content.WriteString("\n\n")
+	}
+	content.WriteString(styles.HelpStyle.Render("esc/v: back to budgets"))
+
+	return content.String()
+}
@@ -1,10 +1,14 @@
 package views
 
 import (
+	"context"
 	"fmt"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
@@ -19,10 +23,27 @@ type BudgetList struct {
 	budgetService   *service.BudgetService
 	categoryService *service.CategoryService
 	
-	budgets         []*models.BudgetStatus
+	budgets         []*models.BudgetProjection
+	suggestions     map[uint]*models.BudgetSuggestion // by budget ID
 	table           table.Model
+	confirmDelete   *models.Budget
 	loading         bool
 	err             error
+	history         *budgetHistoryMsg // of the budget whose history is open
+	
+	// Bootstrapping budgets from last month's spending
+	bootstrap       []*bootstrapLine
+	bootstrapCursor int
+	bootstrapErr    error
+	bootstrapResult *budgetsBootstrappedMsg
+}
+
+// bootstrapLine is one proposed budget, which can be left out or have its
+// amount adjusted before the budgets are created
+type bootstrapLine struct {
+	proposal *models.BudgetProposal
+	selected bool
+	amount   textinput.Model
 }
 
 type budgetDeletedMsg struct{}
@@ -30,10 +51,13 @@ type BudgetEditMsg struct{ Budget *models.Budget }
 
 func NewBudgetList(budgetService *service.BudgetService, categoryService *service.CategoryService) *BudgetList {
 	columns := []table.Column{
-		{Title: "Category", Width: 20},
+		{Title: "Name", Width: 20},
+		{Title: "Category", Width: 18},
 		{Title: "Period", Width: 10},
 		{Title: "Budget", Width: 12},
 		{Title: "Spent", Width: 12},
+		{Title: "Projected", Width: 12},
+		{Title: "Committed", Width: 12},
 		{Title: "Remaining", Width: 12},
 		{Title: "Progress", Width: 20},
 		{Title: "Status", Width: 10},
@@ -77,13 +101,38 @@ func (b *BudgetList) Update(msg tea.Msg) (*BudgetList, tea.Cmd) {
 		b.SetSize(msg.Width, msg.Height)
 		
 	case tea.KeyMsg:
+		b.bootstrapResult = nil
+		if b.bootstrap != nil {
+			return b, b.updateBootstrap(msg)
+		}
+		
+		if b.history != nil {
+			switch msg.String() {
+			case "esc", "v", "q", "backspace":
+				b.history = nil
+			}
+			return b, nil
+		}
+		
+		if b.confirmDelete != nil {
+			switch msg.String() {
+			case "y", "Y":
+				id := b.confirmDelete.ID
+				b.confirmDelete = nil
+				return b, b.deleteBudget(id)
+			case "n", "N", "esc":
+				b.confirmDelete = nil
+			}
+			return b, nil
+		}
+		
 		switch msg.String() {
 		case "e":
 			if len(b.budgets) > 0 {
 				idx := b.table.Cursor()
 				if idx < len(b.budgets) {
 					return b, func() tea.Msg { 
-						return BudgetEditMsg{Budget: &b.budgets[idx].Budget}
+						return BudgetEditMsg{Budget: b.budgets[idx].SavedBudget()}
 					}
 				}
 			}
@@ -91,15 +140,52 @@ func (b *BudgetList) Update(msg tea.Msg) (*BudgetList, tea.Cmd) {
 			if len(b.budgets) > 0 {
 				idx := b.table.Cursor()
 				if idx < len(b.budgets) {
-					return b, b.deleteBudget(b.budgets[idx].Budget.ID)
+					b.confirmDelete = &b.budgets[idx].Budget
+					return b, nil
 				}
 			}
+		case "v":
+			if idx := b.table.Cursor(); idx < len(b.budgets) {
+				return b, b.loadHistory(b.budgets[idx].Budget)
+			}
+		case "B":
+			return b, b.loadProposals
+		case "a":
+			if suggestion := b.selectedSuggestion(); suggestion != nil {
+				return b, b.applySuggestion(suggestion)
+			}
 		}
 		
+	case budgetProposalsLoadedMsg:
+		if msg.err != nil {
+			b.bootstrapResult = &budgetsBootstrappedMsg{err: msg.err}
+			return b, nil
+		}
+		if len(msg.proposals) == 0 {
+			b.bootstrapResult = &budgetsBootstrappedMsg{
+				err: fmt.Errorf("nothing to propose: no expense category without a monthly budget had spending last month"),
+			}
+			return b, nil
+		}
+		b.startBootstrap(msg.proposals)
+		return b, textinput.Blink
+		
+	case budgetHistoryMsg:
+		b.history = &msg
+		return b, nil
+		
+	case budgetsBootstrappedMsg:
+		b.bootstrapResult = &msg
+		return b, b.loadBudgets
+		
 	case budgetsLoadedMsg:
 		b.loading = false
 		b.budgets = msg.budgets
 		b.err = msg.err
+		b.suggestions = make(map[uint]*models.BudgetSuggestion)
+		for _, suggestion := range msg.suggestions {
+			b.suggestions[suggestion.Budget.ID] = suggestion
+		}
 		b.updateTable()
 		
 	case budgetDeletedMsg:
@@ -119,16 +205,45 @@ func (b *BudgetList) View() string {
 		return styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", b.err))
 	}
 	
+	if b.history != nil {
+		return b.renderHistory()
+	}
+	
 	header := b.renderHeader()
 	
 	var content string
-	if len(b.budgets) == 0 {
+	if b.bootstrap != nil {
+		content = b.renderBootstrap()
+	} else if len(b.budgets) == 0 {
 		content = lipgloss.NewStyle().
 			Foreground(styles.Muted).
 			Padding(2).
-			Render("No budgets found. Press 'n' to create a budget.")
+			Render("No budgets found. Press 'n' to create a budget, or 'B' to start from last month's spending.")
 	} else {
 		content = b.table.View()
+		
+		if b.confirmDelete != nil {
+			content += "\n" + styles.WarningStyle.Render(fmt.Sprintf("⚠️  Delete budget '%s' (%s)? (y/n)",
+				b.confirmDelete.Name, b.confirmDelete.CategoryLabel()))
+		} else if idx := b.table.Cursor(); idx < len(b.budgets) {
+			content += "\n" + b.renderProjection(b.budgets[idx])
+			if status := b.budgets[idx]; status.IsOverCommitted() {
+				content += "\n" + styles.WarningStyle.Render(fmt.Sprintf(
+					"⚠️  Recurring expenses alone commit $%s per %s, more than the $%s budget",
+					styles.FormatNumber(status.Committed), periodUnit(status.Budget.Period), styles.FormatNumber(status.Budget.Amount)))
+			}
+			if suggestion := b.selectedSuggestion(); suggestion != nil {
+				content += "\n" + lipgloss.NewStyle().Foreground(styles.Primary).Render(
+					"💡 "+suggestion.Message()+" (press 'a' to apply)")
+			}
+			if notes := b.budgets[idx].Budget.Notes; notes != "" {
+				content += "\n" + lipgloss.NewStyle().Foreground(styles.Muted).Render("📝 "+notes)
+			}
+		}
+	}
+	
+	if result := b.renderBootstrapResult(); result != "" {
+		content += "\n" + result
 	}
 	
 	help := b.renderHelp()
@@ -146,10 +261,42 @@ func (b *BudgetList) View() string {
 func (b *BudgetList) SetSize(width, height int) {
 	b.width = width
 	b.height = height
-	b.table.SetHeight(height - 10)
+	b.table.SetHeight(max(1, height-10))
 	b.table.SetWidth(width)
 }
 
+// periodUnit names one budget period, e.g. "month"
+func periodUnit(period models.BudgetPeriod) string {
+	if period == models.BudgetPeriodYearly {
+		return "year"
+	}
+	return "month"
+}
+
+// renderProjection sums up where the selected budget is heading once its
+// pending recurring expenses are charged
+func (b *BudgetList) renderProjection(status *models.BudgetProjection) string {
+	line := fmt.Sprintf("Spent $%s / Projected $%s of $%s",
+		styles.FormatNumber(status.Spent),
+		styles.FormatNumber(status.Projected),
+		styles.FormatNumber(status.Budget.Amount))
+	if percent := status.Budget.PercentOfIncome; percent != nil {
+		line += fmt.Sprintf(" (%g%% of this month's income)", *percent)
+	}
+	if status.Budget.IsProratedIn(status.Budget.GetCurrentPeriodStart()) {
+		line += fmt.Sprintf(" (prorated from %s)", styles.FormatDate(status.Budget.StartDate))
+	}
+	if status.PendingRecurring > 0 {
+		line += fmt.Sprintf(" (incl. $%s recurring still due)", styles.FormatNumber(status.PendingRecurring))
+	}
+	
+	style := lipgloss.NewStyle().Foreground(styles.Muted)
+	if status.IsProjectedOver {
+		style = styles.WarningStyle
+	}
+	return style.Render(line)
+}
+
 func (b *BudgetList) renderHeader() string {
 	title := styles.TitleStyle.Render("📊 Budget Management")
 	
@@ -173,37 +320,50 @@ func (b *BudgetList) renderHeader() string {
 }
 
 func (b *BudgetList) renderHelp() string {
+	if b.bootstrap != nil {
+		return styles.HelpStyle.Render("[↑/↓]move  [space]include/exclude  [0-9]adjust amount  [enter]create selected  [esc]cancel")
+	}
+	
 	help := []string{
 		"[n]ew",
 		"[e]dit",
 		"[d]elete",
+		"[a]pply suggestion",
+		"[v]history",
+		"[B]ootstrap from last month",
 		"[esc]back",
 	}
 	
-	return styles.HelpStyle.Render(strings.Join(help, "  "))
+	return styles.HelpStyle.Width(b.width).Render(strings.Join(help, "  "))
 }
 
 func (b *BudgetList) updateTable() {
 	rows := []table.Row{}
 	
 	for _, status := range b.budgets {
-		category := fmt.Sprintf("%s %s", status.Budget.Category.Icon, status.Budget.Category.Name)
+		name := truncateText(status.Budget.Name, 20)
+		category := truncateText(status.Budget.CategoryLabel(), 18)
 		period := string(status.Budget.Period)
 		budget := fmt.Sprintf("$%.2f", status.Budget.Amount)
+		if status.Budget.IsPercentOfIncome() {
+			period = fmt.Sprintf("%g%% inc.", *status.Budget.PercentOfIncome)
+		}
 		spent := fmt.Sprintf("$%.2f", status.Spent)
+		projected := fmt.Sprintf("$%.2f", status.Projected)
+		committed := fmt.Sprintf("$%.2f", status.Committed)
+		if status.IsOverCommitted() {
+			committed = styles.WarningStyle.Render(committed + " ⚠")
+		}
 		remaining := fmt.Sprintf("$%.2f", status.Remaining)
 		
 		// Progress bar
 		progress := ""
 		barWidth := 15
-		filled := int(float64(barWidth) * status.PercentUsed / 100)
-		if filled > barWidth {
-			filled = barWidth
-		}
+		filled := min(max(0, int(float64(barWidth)*status.PercentUsed/100)), barWidth)
 		empty := barWidth - filled
 		
 		progressColor := styles.Success
-		if status.PercentUsed > 80 {
+		if status.PercentUsed > models.BudgetWarnPercent {
 			progressColor = styles.Warning
 		}
 		if status.PercentUsed > 100 {
@@ -217,26 +377,218 @@ func (b *BudgetList) updateTable() {
 		statusText := "OK"
 		if status.IsOverBudget {
 			statusText = "OVER"
+		} else if status.IsProjectedOver {
+			statusText = "AT RISK"
+		}
+		if b.suggestions[status.Budget.ID] != nil {
+			statusText += " 💡"
 		}
 		
-		row := table.Row{category, period, budget, spent, remaining, progress, statusText}
+		row := table.Row{name, category, period, budget, spent, projected, committed, remaining, progress, statusText}
 		rows = append(rows, row)
 	}
 	
 	b.table.SetRows(rows)
 }
 
+// IsConfirming reports whether a delete is awaiting y/n, in which case the
+// list needs those keys rather than the global shortcuts
+func (b *BudgetList) IsConfirming() bool {
+	return b.confirmDelete != nil
+}
+
+// IsBootstrapping reports whether proposed budgets are being reviewed, in
+// which case the list needs every key for editing them
+func (b *BudgetList) IsBootstrapping() bool {
+	return b.bootstrap != nil
+}
+
+// startBootstrap lists the proposed budgets, all selected, for review
+func (b *BudgetList) startBootstrap(proposals []*models.BudgetProposal) {
+	b.bootstrap = make([]*bootstrapLine, len(proposals))
+	for i, proposal := range proposals {
+		amount := textinput.New()
+		amount.CharLimit = 12
+		amount.Width = 10
+		amount.Prompt = "$"
+		amount.SetValue(strconv.FormatFloat(proposal.Amount, 'f', -1, 64))
+		b.bootstrap[i] = &bootstrapLine{proposal: proposal, selected: true, amount: amount}
+	}
+	b.bootstrapCursor = 0
+	b.bootstrapErr = nil
+	b.bootstrap[0].amount.Focus()
+}
+
+func (b *BudgetList) updateBootstrap(msg tea.KeyMsg) tea.Cmd {
+	line := b.bootstrap[b.bootstrapCursor]
+	
+	switch msg.String() {
+	case "esc":
+		b.bootstrap = nil
+		return nil
+	case "up", "down":
+		line.amount.Blur()
+		if msg.String() == "up" {
+			b.bootstrapCursor = (b.bootstrapCursor - 1 + len(b.bootstrap)) % len(b.bootstrap)
+		} else {
+			b.bootstrapCursor = (b.bootstrapCursor + 1) % len(b.bootstrap)
+		}
+		return b.bootstrap[b.bootstrapCursor].amount.Focus()
+	case " ":
+		line.selected = !line.selected
+		return nil
+	case "enter":
+		return b.createBootstrapBudgets()
+	}
+	
+	var cmd tea.Cmd
+	line.amount, cmd = line.amount.Update(msg)
+	return cmd
+}
+
+// createBootstrapBudgets checks the selected amounts and creates the
+// budgets in one go
+func (b *BudgetList) createBootstrapBudgets() tea.Cmd {
+	amounts := make(map[uint]float64)
+	names := make(map[uint]string)
+	for _, line := range b.bootstrap {
+		if !line.selected {
+			continue
+		}
+		amount, err := strconv.ParseFloat(strings.TrimSpace(line.amount.Value()), 64)
+		if err != nil || amount <= 0 {
+			b.bootstrapErr = fmt.Errorf("enter a positive amount for %s", line.proposal.Category.Name)
+			return nil
+		}
+		amounts[line.proposal.Category.ID] = amount
+		names[line.proposal.Category.ID] = line.proposal.Category.Name
+	}
+	if len(amounts) == 0 {
+		b.bootstrapErr = fmt.Errorf("select at least one category, or press esc to cancel")
+		return nil
+	}
+	
+	b.bootstrap = nil
+	return func() tea.Msg {
+		created, err := b.budgetService.CreateMonthlyBudgets(context.Background(), amounts, time.Now())
+		createdNames := make([]string, len(created))
+		for i, budget := range created {
+			createdNames[i] = names[budget.CategoryID]
+		}
+		return budgetsBootstrappedMsg{created: createdNames, err: err}
+	}
+}
+
+func (b *BudgetList) renderBootstrap() string {
+	var rows []string
+	rows = append(rows, styles.TitleStyle.Render("Monthly budgets from last month's spending"), "")
+	
+	var total float64
+	var count int
+	for i, line := range b.bootstrap {
+		cursor := "  "
+		if i == b.bootstrapCursor {
+			cursor = "> "
+		}
+		check := "[ ]"
+		if line.selected {
+			check = "[x]"
+			if amount, err := strconv.ParseFloat(strings.TrimSpace(line.amount.Value()), 64); err == nil {
+				total += amount
+				count++
+			}
+		}
+		category := fmt.Sprintf("%s %s", line.proposal.Category.Icon, line.proposal.Category.Name)
+		row := fmt.Sprintf("%s%s %-22s last month $%-10.2f budget ",
+			cursor, check, truncateText(category, 22), line.proposal.LastMonth)
+		row += line.amount.View()
+		if !line.selected {
+			row = lipgloss.NewStyle().Foreground(styles.Muted).Render(row)
+		}
+		rows = append(rows, row)
+	}
+	
+	rows = append(rows, "", fmt.Sprintf("Total: $%.2f a month for %d categories", total, count))
+	if b.bootstrapErr != nil {
+		rows = append(rows, styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", b.bootstrapErr)))
+	}
+	
+	return lipgloss.JoinVertical(lipgloss.Left, rows...)
+}
+
+// renderBootstrapResult reports which budgets were created and which
+// failed, until the next key press
+func (b *BudgetList) renderBootstrapResult() string {
+	if b.bootstrapResult == nil {
+		return ""
+	}
+	
+	var lines []string
+	if created := b.bootstrapResult.created; len(created) > 0 {
+		lines = append(lines, styles.SuccessStyle.Render(fmt.Sprintf("✓ Created %d monthly budgets: %s", len(created), strings.Join(created, ", "))))
+	}
+	if err := b.bootstrapResult.err; err != nil {
+		lines = append(lines, styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", err)))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+func (b *BudgetList) loadProposals() tea.Msg {
+	proposals, err := b.budgetService.ProposeMonthlyBudgets(context.Background(), time.Now())
+	return budgetProposalsLoadedMsg{proposals: proposals, err: err}
+}
+
+// truncateText shortens s to at most width runes, marking the cut with an
+// ellipsis
+func truncateText(s string, width int) string {
+	runes := []rune(s)
+	if len(runes) <= width {
+		return s
+	}
+	if width <= 1 {
+		return string(runes[:width])
+	}
+	return string(runes[:width-1]) + "…"
+}
+
 func (b *BudgetList) loadBudgets() tea.Msg {
-	budgets, err := b.budgetService.GetAllStatuses()
+	budgets, err := b.budgetService.GetAllStatusesWithProjection(context.Background())
+	if err != nil {
+		return budgetsLoadedMsg{err: err}
+	}
+	suggestions, err := b.budgetService.SuggestAdjustments(context.Background())
 	return budgetsLoadedMsg{
-		budgets: budgets,
-		err:     err,
+		budgets:     budgets,
+		suggestions: suggestions,
+		err:         err,
+	}
+}
+
+// selectedSuggestion is the suggested adjustment for the budget under the
+// cursor, if there is one
+func (b *BudgetList) selectedSuggestion() *models.BudgetSuggestion {
+	idx := b.table.Cursor()
+	if idx < 0 || idx >= len(b.budgets) {
+		return nil
+	}
+	return b.suggestions[b.budgets[idx].Budget.ID]
+}
+
+// applySuggestion saves the suggested amount as the budget's new amount
+func (b *BudgetList) applySuggestion(suggestion *models.BudgetSuggestion) tea.Cmd {
+	return func() tea.Msg {
+		budget := suggestion.Budget
+		budget.Amount = suggestion.Amount
+		if err := b.budgetService.Update(context.Background(), &budget); err != nil {
+			return statusError(err)()
+		}
+		return b.loadBudgets()
 	}
 }
 
 func (b *BudgetList) deleteBudget(id uint) tea.Cmd {
 	return func() tea.Msg {
-		if err := b.budgetService.Delete(id); err != nil {
+		if err := b.budgetService.Delete(context.Background(), id); err != nil {
 			return errMsg{err}
 		}
 		return budgetDeletedMsg{}
@@ -244,6 +596,19 @@ func (b *BudgetList) deleteBudget(id uint) tea.Cmd {
 }
 
 type budgetsLoadedMsg struct {
-	budgets []*models.BudgetStatus
+	budgets     []*models.BudgetProjection
+	suggestions []*models.BudgetSuggestion
+	err         error
+}
+
+type budgetProposalsLoadedMsg struct {
+	proposals []*models.BudgetProposal
+	err       error
+}
+
+// budgetsBootstrappedMsg names the categories that got a budget, and the
+// error for any that didn't
+type budgetsBootstrappedMsg struct {
+	created []string
 	err     error
 }
\ No newline at end of file
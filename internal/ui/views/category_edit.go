@@ -1,6 +1,7 @@
 package views
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -18,14 +19,29 @@ type CategoryEditModel struct {
 	isEditing       bool
 	
 	nameInput     textinput.Model
-	iconInput     textinput.Model
+	iconPicker    IconPicker
 	colorInput    textinput.Model
+	currencyInput textinput.Model
 	typeSelected  models.TransactionType
+	typeLocked    bool
+	validateOnly  bool // the category is checked but saved by the caller
 	
 	focusIndex int
 	completed  bool
 	cancelled  bool
 	errorMsg   string
+	
+	discard discardGuard[categoryEditValues]
+}
+
+// categoryEditValues are the form's editable values, compared with those
+// it was opened with to tell whether esc would lose anything
+type categoryEditValues struct {
+	name     string
+	icon     string
+	color    string
+	currency string
+	txType   models.TransactionType
 }
 
 func NewCategoryEditModel(categoryService *service.CategoryService, category *models.Category) *CategoryEditModel {
@@ -47,27 +63,53 @@ func NewCategoryEditModel(categoryService *service.CategoryService, category *mo
 	nameInput.Width = 30
 	nameInput.SetValue(category.Name)
 
-	iconInput := textinput.New()
-	iconInput.Placeholder = "Icon (emoji)"
-	iconInput.CharLimit = 4
-	iconInput.Width = 10
-	iconInput.SetValue(category.Icon)
-
 	colorInput := textinput.New()
 	colorInput.Placeholder = "#FF5722"
 	colorInput.CharLimit = 7
 	colorInput.Width = 10
 	colorInput.SetValue(category.Color)
 
-	return &CategoryEditModel{
+	currencyInput := textinput.New()
+	currencyInput.Placeholder = "none (e.g. AED)"
+	currencyInput.CharLimit = 3
+	currencyInput.Width = 16
+	currencyInput.SetValue(category.DefaultCurrency)
+
+	m := &CategoryEditModel{
 		categoryService: categoryService,
 		category:        category,
 		isEditing:       isEditing,
 		nameInput:       nameInput,
-		iconInput:       iconInput,
+		iconPicker:      NewIconPicker(category.Icon),
 		colorInput:      colorInput,
+		currencyInput:   currencyInput,
 		typeSelected:    category.Type,
 	}
+	m.discard.reset(m.values())
+	return m
+}
+
+func (m *CategoryEditModel) values() categoryEditValues {
+	return categoryEditValues{
+		name:     m.nameInput.Value(),
+		icon:     m.iconPicker.Value(),
+		color:    m.colorInput.Value(),
+		currency: m.currencyInput.Value(),
+		txType:   m.typeSelected,
+	}
+}
+
+// newCategoryCreateModelForType returns a create form whose type cannot be
+// changed, used when a new category is created as a merge target. The
+// category is only checked, as it is created along with the merge.
+func newCategoryCreateModelForType(categoryService *service.CategoryService, txType models.TransactionType) *CategoryEditModel {
+	m := NewCategoryEditModel(categoryService, nil)
+	m.category.Type = txType
+	m.typeSelected = txType
+	m.typeLocked = true
+	m.validateOnly = true
+	m.discard.reset(m.values())
+	return m
 }
 
 func (m *CategoryEditModel) Init() tea.Cmd {
@@ -85,15 +127,29 @@ func (m *CategoryEditModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		return m, nil
 		
 	case tea.KeyMsg:
+		if m.discard.confirming {
+			if m.discard.answer(msg.String()) {
+				m.cancelled = true
+			}
+			return m, nil
+		}
+		
 		switch msg.String() {
 		case "esc":
+			if !m.discard.canLeave(m.values()) {
+				return m, nil
+			}
 			m.cancelled = true
 			return m, nil
 			
 		case "enter":
-			if m.focusIndex == 3 { // Save button focused
+			if m.focusIndex == 4 || m.focusIndex == 5 { // Last field or Save button
 				return m, m.save()
 			}
+			if m.focusIndex == 6 { // Cancel button
+				m.cancelled = true
+				return m, nil
+			}
 			m.nextField()
 			
 		case "tab":
@@ -106,11 +162,11 @@ func (m *CategoryEditModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, m.save()
 			
 		case "1":
-			if m.focusIndex == 1 { // Type selection
+			if m.focusIndex == 1 && !m.typeLocked { // Type selection
 				m.typeSelected = models.TransactionTypeIncome
 			}
 		case "2":
-			if m.focusIndex == 1 { // Type selection
+			if m.focusIndex == 1 && !m.typeLocked { // Type selection
 				m.typeSelected = models.TransactionTypeExpense
 			}
 		}
@@ -122,9 +178,11 @@ func (m *CategoryEditModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case 0:
 		m.nameInput, cmd = m.nameInput.Update(msg)
 	case 2:
-		m.iconInput, cmd = m.iconInput.Update(msg)
+		m.iconPicker, cmd = m.iconPicker.Update(msg)
 	case 3:
 		m.colorInput, cmd = m.colorInput.Update(msg)
+	case 4:
+		m.currencyInput, cmd = m.currencyInput.Update(msg)
 	}
 
 	return m, cmd
@@ -146,7 +204,7 @@ func (m *CategoryEditModel) View() string {
 	b.WriteString("\n")
 
 	// Type selection (only for new categories)
-	if !m.isEditing {
+	if !m.isEditing && !m.typeLocked {
 		typeStyle := styles.LabelStyle
 		if m.focusIndex == 1 {
 			typeStyle = styles.FocusedStyle
@@ -172,22 +230,26 @@ func (m *CategoryEditModel) View() string {
 		b.WriteString("\n\n")
 	}
 
-	// Icon input
-	b.WriteString(m.renderField("Icon:", m.iconInput.View(), 2))
+	// Icon picker
+	b.WriteString(m.renderField("Icon:", m.iconPicker.View(m.focusIndex == 2), 2))
 	b.WriteString("\n")
 
 	// Color input
 	b.WriteString(m.renderField("Color:", m.colorInput.View(), 3))
 	b.WriteString("\n")
 
+	// Default currency, picked in the forms when the category is selected
+	b.WriteString(m.renderField("Default currency:", m.currencyInput.View(), 4))
+	b.WriteString("\n")
+
 	// Action buttons
-	if m.focusIndex == 4 {
+	if m.focusIndex == 5 {
 		b.WriteString(styles.ButtonFocusedStyle.Render("[ Save ]"))
 	} else {
 		b.WriteString(styles.ButtonStyle.Render("[ Save ]"))
 	}
 	b.WriteString("  ")
-	if m.focusIndex == 5 {
+	if m.focusIndex == 6 {
 		b.WriteString(styles.ButtonFocusedStyle.Render("[ Cancel ]"))
 	} else {
 		b.WriteString(styles.ButtonStyle.Render("[ Cancel ]"))
@@ -199,9 +261,14 @@ func (m *CategoryEditModel) View() string {
 		b.WriteString(styles.ErrorStyle.Render("❌ " + m.errorMsg))
 	}
 
+	if discard := m.discard.view(); discard != "" {
+		b.WriteString("\n\n")
+		b.WriteString(discard)
+	}
+
 	// Help
 	b.WriteString("\n\n")
-	b.WriteString(styles.HelpStyle.Render("Tab: next field • Shift+Tab: prev field • Enter: save • Esc: cancel"))
+	b.WriteString(styles.HelpStyle.Render("Tab: next field • Shift+Tab: prev field • ←/→: pick icon • Enter: save • Esc: cancel"))
 
 	return styles.AppStyle.Render(b.String())
 }
@@ -216,24 +283,17 @@ func (m *CategoryEditModel) renderField(label, input string, index int) string {
 }
 
 func (m *CategoryEditModel) nextField() {
-	maxIndex := 5
-	if !m.isEditing {
-		maxIndex = 5 // Include type selection
-	} else {
-		maxIndex = 4 // Skip type selection for editing
-	}
+	maxIndex := 6
 	
 	m.focusIndex = (m.focusIndex + 1) % (maxIndex + 1)
+	if m.isEditing && m.focusIndex == 1 {
+		m.focusIndex = 2 // Skip type selection when editing
+	}
 	m.updateFocus()
 }
 
 func (m *CategoryEditModel) prevField() {
-	maxIndex := 5
-	if !m.isEditing {
-		maxIndex = 5
-	} else {
-		maxIndex = 4
-	}
+	maxIndex := 6
 	
 	if m.focusIndex == 0 {
 		m.focusIndex = maxIndex
@@ -250,16 +310,19 @@ func (m *CategoryEditModel) prevField() {
 
 func (m *CategoryEditModel) updateFocus() {
 	m.nameInput.Blur()
-	m.iconInput.Blur()
+	m.iconPicker.Blur()
 	m.colorInput.Blur()
+	m.currencyInput.Blur()
 
 	switch m.focusIndex {
 	case 0:
 		m.nameInput.Focus()
 	case 2:
-		m.iconInput.Focus()
+		m.iconPicker.Focus()
 	case 3:
 		m.colorInput.Focus()
+	case 4:
+		m.currencyInput.Focus()
 	}
 }
 
@@ -271,7 +334,7 @@ func (m *CategoryEditModel) save() tea.Cmd {
 			return categoryEditErrorMsg{error: fmt.Errorf("category name is required")}
 		}
 
-		icon := strings.TrimSpace(m.iconInput.Value())
+		icon := m.iconPicker.Value()
 		if icon == "" {
 			icon = "📁"
 		}
@@ -286,12 +349,15 @@ func (m *CategoryEditModel) save() tea.Cmd {
 		m.category.Type = m.typeSelected
 		m.category.Icon = icon
 		m.category.Color = color
+		m.category.DefaultCurrency = m.currencyInput.Value()
 
 		var err error
 		if m.isEditing {
-			err = m.categoryService.Update(m.category)
+			err = m.categoryService.Update(context.Background(), m.category)
+		} else if m.validateOnly {
+			err = m.categoryService.ValidateNew(context.Background(), m.category)
 		} else {
-			err = m.categoryService.Create(m.category)
+			err = m.categoryService.Create(context.Background(), m.category)
 		}
 
 		if err != nil {
@@ -1,7 +1,10 @@
 package views
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"strings"
 	"time"
 
@@ -23,24 +26,37 @@ const (
 	categoryListModeCreate
 	categoryListModeMerge
 	categoryListModeConfirmDelete
+	categoryListModeSuggestions
 )
 
 type CategoryListModel struct {
 	categoryService *service.CategoryService
+	txService       *service.TransactionService
 	list            list.Model
 	categories      []*models.CategoryWithTotal
+	trends          map[uint]*models.CategoryTrend
 	mode            categoryListMode
 	selectedItem    *categoryItem
 	editForm        *CategoryEditModel
 	createForm      *CategoryEditModel
 	mergeForm       *CategoryMergeModel
+	mergeSelection  map[uint]bool
 	confirmDelete   string
-	errorMsg        string
-	successMsg      string
+	width           int
+	height          int
+	
+	// The category to keep selected once the list reloads after a move
+	movedID uint
+	
+	// Groups of categories with similar names, suggested for merging
+	similar          [][]*models.Category
+	suggestionCursor int
 }
 
 type categoryItem struct {
 	category *models.CategoryWithTotal
+	trend    *models.CategoryTrend
+	selected bool
 }
 
 func (i categoryItem) Title() string {
@@ -53,17 +69,56 @@ func (i categoryItem) Title() string {
 	if i.category.IsDefault {
 		status = " (default)"
 	}
+	if i.category.IsArchived {
+		status += " (archived)"
+	}
+	if i.category.IsPinned {
+		status += " 📌"
+	}
+	
+	marker := ""
+	if i.selected {
+		marker = "[x] "
+	}
 	
-	return fmt.Sprintf("%s %s%s", icon, i.category.Name, status)
+	return fmt.Sprintf("%s%s %s%s", marker, icon, i.category.Name, status)
 }
 
 func (i categoryItem) Description() string {
-	txCount := "No transactions"
-	if i.category.Count > 0 {
-		txCount = fmt.Sprintf("%d transactions", i.category.Count)
+	description := fmt.Sprintf("%s · %s", i.category.Type, usageText(i.category))
+	if i.trend != nil {
+		description += " · " + i.trendText()
+	}
+	if i.category.Color != "" {
+		swatch := lipgloss.NewStyle().Foreground(styles.CategoryColor(i.category.Color)).Render("■■")
+		description += " · " + i.category.Color + " " + swatch
+	}
+	return description
+}
+
+// usageText sums up how much a category is used, e.g. "14 transactions ·
+// $312.50 total"
+func usageText(category *models.CategoryWithTotal) string {
+	if category.Count == 0 {
+		return "No transactions"
+	}
+	return fmt.Sprintf("%d transactions · $%s total", category.Count, styles.FormatNumber(category.Total))
+}
+
+// trendText shows this month's total with an arrow comparing it to last
+// month, e.g. "$120.00 this month ↑ 18%"
+func (i categoryItem) trendText() string {
+	text := styles.FormatAmount(i.trend.CurrentMonth, "$") + " this month"
+	switch {
+	case i.trend.PreviousMonth == 0:
+		return text + " (new)"
+	case math.Abs(i.trend.Change()) < 0.5:
+		return text + " →"
+	case i.trend.Change() > 0:
+		return text + fmt.Sprintf(" ↑ %.0f%%", i.trend.Change())
+	default:
+		return text + fmt.Sprintf(" ↓ %.0f%%", -i.trend.Change())
 	}
-	
-	return fmt.Sprintf("%s · %s", i.category.Type, txCount)
 }
 
 func (i categoryItem) FilterValue() string {
@@ -90,8 +145,13 @@ func NewCategoryListModel(categoryService *service.CategoryService) *CategoryLis
 		return []key.Binding{
 			key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new")),
 			key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit")),
+			key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "select for merge")),
 			key.NewBinding(key.WithKeys("m"), key.WithHelp("m", "merge")),
+			key.NewBinding(key.WithKeys("f"), key.WithHelp("f", "similar")),
 			key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
+			key.NewBinding(key.WithKeys("a"), key.WithHelp("a", "archive")),
+			key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pin")),
+			key.NewBinding(key.WithKeys("shift+up", "shift+down"), key.WithHelp("shift+↑/↓", "move")),
 			key.NewBinding(key.WithKeys("h"), key.WithHelp("h", "history")),
 			key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
 		}
@@ -101,14 +161,27 @@ func NewCategoryListModel(categoryService *service.CategoryService) *CategoryLis
 		categoryService: categoryService,
 		list:            l,
 		mode:            categoryListModeView,
+		mergeSelection:  make(map[uint]bool),
 	}
 }
 
+// SetTransactionService enables showing each category's spending this
+// month and its trend against last month
+func (m *CategoryListModel) SetTransactionService(txService *service.TransactionService) {
+	m.txService = txService
+}
+
 func (m *CategoryListModel) Init() tea.Cmd {
 	return m.loadCategories()
 }
 
 func (m *CategoryListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Resize even while a form is open, as the forms below consume every
+	// message they are given
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.SetSize(msg.Width, msg.Height)
+	}
+
 	// Handle mode-specific updates
 	switch m.mode {
 	case categoryListModeEdit:
@@ -118,8 +191,7 @@ func (m *CategoryListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			
 			if m.editForm.completed {
 				m.mode = categoryListModeView
-				m.successMsg = "Category updated successfully"
-				return m, tea.Batch(m.loadCategories(), m.clearMessages())
+				return m, tea.Batch(m.loadCategories(), statusInfo("Category updated successfully"))
 			} else if m.editForm.cancelled {
 				m.mode = categoryListModeView
 				m.editForm = nil
@@ -134,8 +206,7 @@ func (m *CategoryListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			
 			if m.createForm.completed {
 				m.mode = categoryListModeView
-				m.successMsg = "Category created successfully"
-				return m, tea.Batch(m.loadCategories(), m.clearMessages())
+				return m, tea.Batch(m.loadCategories(), statusInfo("Category created successfully"))
 			} else if m.createForm.cancelled {
 				m.mode = categoryListModeView
 				m.createForm = nil
@@ -150,8 +221,8 @@ func (m *CategoryListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			
 			if m.mergeForm.completed {
 				m.mode = categoryListModeView
-				m.successMsg = "Categories merged successfully"
-				return m, tea.Batch(m.loadCategories(), m.clearMessages())
+				m.mergeSelection = make(map[uint]bool)
+				return m, tea.Batch(m.loadCategories(), statusInfo("Categories merged successfully"))
 			} else if m.mergeForm.cancelled {
 				m.mode = categoryListModeView
 				m.mergeForm = nil
@@ -159,22 +230,28 @@ func (m *CategoryListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, cmd
 		}
 		
+	case categoryListModeSuggestions:
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			return m, m.updateSuggestions(msg)
+		}
+		
 	case categoryListModeConfirmDelete:
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
 			switch msg.String() {
 			case "y", "Y":
+				var status tea.Cmd
 				if m.selectedItem != nil {
-					err := m.categoryService.Delete(m.selectedItem.category.ID)
+					err := m.categoryService.Delete(context.Background(), m.selectedItem.category.ID)
 					if err != nil {
-						m.errorMsg = err.Error()
+						status = statusError(err)
 					} else {
-						m.successMsg = "Category deleted successfully"
+						status = statusInfo("Category deleted successfully")
 					}
 				}
 				m.mode = categoryListModeView
 				m.confirmDelete = ""
-				return m, tea.Batch(m.loadCategories(), m.clearMessages())
+				return m, tea.Batch(m.loadCategories(), status)
 			case "n", "N", "esc":
 				m.mode = categoryListModeView
 				m.confirmDelete = ""
@@ -200,8 +277,7 @@ func (m *CategoryListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				// Edit selected category
 				if item, ok := m.list.SelectedItem().(categoryItem); ok {
 					if item.category.IsDefault {
-						m.errorMsg = "Cannot edit default categories"
-						return m, m.clearMessages()
+						return m, statusError(errors.New("Cannot edit default categories"))
 					}
 					// Convert CategoryWithTotal to Category for editing
 					cat := &models.Category{
@@ -216,62 +292,124 @@ func (m *CategoryListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.mode = categoryListModeEdit
 					return m, m.editForm.Init()
 				}
+			case " ":
+				// Toggle category in the merge selection
+				if item, ok := m.list.SelectedItem().(categoryItem); ok {
+					if item.category.IsDefault {
+						return m, statusError(errors.New("Cannot merge default categories"))
+					}
+					item.selected = !item.selected
+					if item.selected {
+						m.mergeSelection[item.category.ID] = true
+					} else {
+						delete(m.mergeSelection, item.category.ID)
+					}
+					return m, m.list.SetItem(m.list.Index(), item)
+				}
 			case "m":
-				// Merge categories
+				// Merge the selected categories, or the highlighted one
+				if len(m.mergeSelection) > 0 {
+					sources, err := m.selectedMergeSources()
+					if err != nil {
+						return m, statusError(err)
+					}
+					m.mergeForm = NewCategoryMergeModel(m.categoryService, sources)
+					m.mergeForm.SetSize(m.width, m.height)
+					m.mode = categoryListModeMerge
+					return m, m.mergeForm.Init()
+				}
 				if item, ok := m.list.SelectedItem().(categoryItem); ok {
 					if item.category.IsDefault {
-						m.errorMsg = "Cannot merge default categories"
-						return m, m.clearMessages()
+						return m, statusError(errors.New("Cannot merge default categories"))
 					}
 					if item.category.Count == 0 {
-						m.errorMsg = "Category has no transactions to merge"
-						return m, m.clearMessages()
+						return m, statusError(errors.New("Category has no transactions to merge"))
 					}
-					m.mergeForm = NewCategoryMergeModel(m.categoryService, item.category)
+					m.mergeForm = NewCategoryMergeModel(m.categoryService, []*models.CategoryWithTotal{item.category})
+					m.mergeForm.SetSize(m.width, m.height)
 					m.selectedItem = &item
 					m.mode = categoryListModeMerge
 					return m, m.mergeForm.Init()
 				}
+			case "f":
+				// Review the merges suggested for similar categories
+				return m, m.openSuggestions()
 			case "d":
 				// Delete category
 				if item, ok := m.list.SelectedItem().(categoryItem); ok {
 					if item.category.IsDefault {
-						m.errorMsg = "Cannot delete default categories"
-						return m, m.clearMessages()
+						return m, statusError(errors.New("Cannot delete default categories"))
 					}
 					if item.category.Count > 0 {
-						m.errorMsg = fmt.Sprintf("Cannot delete category with %d transactions. Use merge instead.", item.category.Count)
-						return m, m.clearMessages()
+						return m, statusError(fmt.Errorf("Cannot delete category with %d transactions. Use merge instead.", item.category.Count))
 					}
 					m.selectedItem = &item
 					m.confirmDelete = fmt.Sprintf("Delete category '%s'? (y/n)", item.category.Name)
 					m.mode = categoryListModeConfirmDelete
 				}
+			case "a":
+				// Archive or unarchive, which unlike deleting is also
+				// allowed for the default categories
+				if item, ok := m.list.SelectedItem().(categoryItem); ok {
+					archived := !item.category.IsArchived
+					status := statusInfo(fmt.Sprintf("Unarchived '%s'", item.category.Name))
+					if archived {
+						status = statusInfo(fmt.Sprintf("Archived '%s'; it no longer shows in the forms", item.category.Name))
+					}
+					if err := m.categoryService.SetArchived(context.Background(), item.category.ID, archived); err != nil {
+						status = statusError(err)
+					}
+					return m, tea.Batch(m.loadCategories(), status)
+				}
+			case "p":
+				// Pin or unpin, listing the category first in the forms
+				if item, ok := m.list.SelectedItem().(categoryItem); ok {
+					return m, m.setPinned(item.category.ID, item.category.Name, !item.category.IsPinned)
+				}
+			case "shift+up", "shift+down":
+				// Move the category within its type; the positions in a
+				// filtered list wouldn't say where it ends up
+				if m.list.FilterState() != list.Unfiltered {
+					break
+				}
+				if item, ok := m.list.SelectedItem().(categoryItem); ok {
+					offset := 1
+					if msg.String() == "shift+up" {
+						offset = -1
+					}
+					m.movedID = item.category.ID
+					return m, m.moveCategory(item.category.ID, offset)
+				}
 			case "h":
 				// View history (TODO: implement history view)
 				if item, ok := m.list.SelectedItem().(categoryItem); ok {
-					m.errorMsg = fmt.Sprintf("History view not yet implemented for '%s'", item.category.Name)
-					return m, m.clearMessages()
+					return m, statusError(fmt.Errorf("History view not yet implemented for '%s'", item.category.Name))
 				}
 			}
 		}
 	
 	case categoryManagementLoadedMsg:
 		m.categories = msg.categories
+		m.trends = msg.trends
+		m.similar = msg.similar
 		items := make([]list.Item, len(m.categories))
 		for i, cat := range m.categories {
-			items[i] = categoryItem{category: cat}
+			items[i] = categoryItem{category: cat, trend: m.trends[cat.ID], selected: m.mergeSelection[cat.ID]}
 		}
 		m.list.SetItems(items)
+		if m.movedID != 0 {
+			for i, cat := range m.categories {
+				if cat.ID == m.movedID {
+					m.list.Select(i)
+				}
+			}
+			m.movedID = 0
+		}
 		return m, nil
 		
-	case clearMessagesMsg:
-		m.handleClearMessages()
-		return m, nil
-		
-	case tea.WindowSizeMsg:
-		h, v := styles.AppStyle.GetFrameSize()
-		m.list.SetSize(msg.Width-h, msg.Height-v-4)
+	case errMsg:
+		m.movedID = 0
+		return m, statusError(msg.error)
 	}
 
 	var cmd tea.Cmd
@@ -289,49 +427,113 @@ func (m *CategoryListModel) View() string {
 	if m.mode == categoryListModeMerge && m.mergeForm != nil {
 		return m.mergeForm.View()
 	}
+	if m.mode == categoryListModeSuggestions {
+		return m.renderSuggestions()
+	}
 	
 	var content strings.Builder
 	content.WriteString(m.list.View())
 	
-	// Show messages
-	if m.errorMsg != "" {
-		content.WriteString("\n" + styles.ErrorStyle.Render("❌ "+m.errorMsg))
-	}
-	if m.successMsg != "" {
-		content.WriteString("\n" + styles.SuccessStyle.Render("✅ "+m.successMsg))
-	}
 	if m.confirmDelete != "" {
 		content.WriteString("\n" + styles.WarningStyle.Render("⚠️  "+m.confirmDelete))
 	}
+	if len(m.mergeSelection) > 0 {
+		content.WriteString("\n" + styles.HelpStyle.Render(fmt.Sprintf("%d selected for merge · press m to merge", len(m.mergeSelection))))
+	} else if len(m.similar) > 0 {
+		content.WriteString("\n" + styles.WarningStyle.Render(fmt.Sprintf("💡 %d group(s) of categories with similar names · press f to review merges", len(m.similar))))
+	}
 	
 	return styles.AppStyle.Render(content.String())
 }
 
+// selectedMergeSources returns the categories selected with space, which
+// must all share one type
+func (m *CategoryListModel) selectedMergeSources() ([]*models.CategoryWithTotal, error) {
+	var sources []*models.CategoryWithTotal
+	for _, cat := range m.categories {
+		if !m.mergeSelection[cat.ID] {
+			continue
+		}
+		if len(sources) > 0 && cat.Type != sources[0].Type {
+			return nil, fmt.Errorf("cannot merge income and expense categories together")
+		}
+		sources = append(sources, cat)
+	}
+	if len(sources) == 0 {
+		return nil, fmt.Errorf("no categories selected")
+	}
+	return sources, nil
+}
+
 // Messages
 type categoryManagementLoadedMsg struct {
 	categories []*models.CategoryWithTotal
+	trends     map[uint]*models.CategoryTrend
+	similar    [][]*models.Category
 }
 
 // Commands
 func (m *CategoryListModel) loadCategories() tea.Cmd {
 	return func() tea.Msg {
-		categories, err := m.categoryService.GetAllWithUsageCount()
+		categories, err := m.categoryService.GetAllWithUsageCount(context.Background())
 		if err != nil {
 			return errMsg{err}
 		}
-		return categoryManagementLoadedMsg{categories: categories}
+		
+		var trends map[uint]*models.CategoryTrend
+		if m.txService != nil {
+			trends, err = m.txService.GetCategoryTrends(context.Background(), time.Now())
+			if err != nil {
+				return errMsg{err}
+			}
+		}
+		similar, err := m.categoryService.FindSimilarCategories(context.Background())
+		if err != nil {
+			return errMsg{err}
+		}
+		return categoryManagementLoadedMsg{categories: categories, trends: trends, similar: similar}
+	}
+}
+
+// setPinned pins or unpins a category, then reloads the list and says so
+func (m *CategoryListModel) setPinned(id uint, name string, pinned bool) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.categoryService.SetPinned(context.Background(), id, pinned); err != nil {
+			return tea.Batch(m.loadCategories(), statusError(err))()
+		}
+		status := statusInfo(fmt.Sprintf("Unpinned '%s'", name))
+		if pinned {
+			status = statusInfo(fmt.Sprintf("Pinned '%s' to the top of the forms", name))
+		}
+		return tea.Batch(m.loadCategories(), status)()
 	}
 }
 
-func (m *CategoryListModel) clearMessages() tea.Cmd {
-	return tea.Tick(styles.MessageTimeout, func(time.Time) tea.Msg {
-		return clearMessagesMsg{}
-	})
+// moveCategory moves a category up or down and reloads the list
+func (m *CategoryListModel) moveCategory(id uint, offset int) tea.Cmd {
+	return func() tea.Msg {
+		if err := m.categoryService.MoveCategory(context.Background(), id, offset); err != nil {
+			return errMsg{err}
+		}
+		return m.loadCategories()()
+	}
 }
 
-type clearMessagesMsg struct{}
+// IsEditing reports whether a create, edit or merge form is open, in which
+// case the form needs every key rather than the global shortcuts
+func (m *CategoryListModel) IsEditing() bool {
+	return m.mode == categoryListModeEdit || m.mode == categoryListModeCreate || m.mode == categoryListModeMerge ||
+		m.mode == categoryListModeSuggestions
+}
 
-func (m *CategoryListModel) handleClearMessages() {
-	m.errorMsg = ""
-	m.successMsg = ""
-}
\ No newline at end of file
+// SetSize fits the list to the window, leaving room for the messages and
+// help below it. An open merge form is resized with it.
+func (m *CategoryListModel) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	h, v := styles.AppStyle.GetFrameSize()
+	m.list.SetSize(width-h, height-v-4)
+	if m.mergeForm != nil {
+		m.mergeForm.SetSize(width, height)
+	}
+}
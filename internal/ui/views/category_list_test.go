@@ -0,0 +1,30 @@
+package views
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"burnwise/internal/models"
+	"burnwise/internal/repository"
+	"burnwise/internal/service"
+	test "burnwise/test/helpers"
+)
+
+func TestCategoryList_ResizedWhileFormIsOpen(t *testing.T) {
+	db := test.SetupTestDB(t)
+	test.CreateTestCategory(t, db, "Groceries", models.TransactionTypeExpense)
+	m := NewCategoryListModel(service.NewCategoryService(repository.NewCategoryRepository(db)))
+	m.Update(m.Init()())
+
+	// The window size arrives while the create form is open, which used
+	// to leave the list without any rows once the form was closed
+	m.Update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	require.True(t, m.IsEditing())
+	m.Update(tea.WindowSizeMsg{Width: 120, Height: 40})
+	m.Update(tea.KeyMsg{Type: tea.KeyEsc})
+	require.False(t, m.IsEditing())
+	assert.Contains(t, m.View(), "Groceries")
+}
@@ -1,6 +1,7 @@
 package views
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -15,16 +16,26 @@ import (
 
 type CategoryMergeModel struct {
 	categoryService *service.CategoryService
-	sourceCategory  *models.CategoryWithTotal
+	sourceCategories []*models.CategoryWithTotal
 	targetList      list.Model
 	targetCategories []*models.CategoryWithTotal
+	createForm      *CategoryEditModel
 	completed       bool
 	cancelled       bool
 	errorMsg        string
 	confirmMerge    bool
 	selectedTarget  *models.CategoryWithTotal
+	newTarget       *models.Category // created by the merge, if set
+	suggestedTarget uint // highlighted once the targets are loaded, if set
 }
 
+// createTargetItem is the list entry that creates a new target category
+type createTargetItem struct{}
+
+func (i createTargetItem) Title() string       { return "➕ Create new category" }
+func (i createTargetItem) Description() string { return "Merge into a brand-new category" }
+func (i createTargetItem) FilterValue() string { return "" }
+
 type mergeTargetItem struct {
 	category *models.CategoryWithTotal
 }
@@ -44,19 +55,14 @@ func (i mergeTargetItem) Title() string {
 }
 
 func (i mergeTargetItem) Description() string {
-	txCount := "No transactions"
-	if i.category.Count > 0 {
-		txCount = fmt.Sprintf("%d transactions", i.category.Count)
-	}
-	
-	return fmt.Sprintf("%s · %s", i.category.Type, txCount)
+	return fmt.Sprintf("%s · %s", i.category.Type, usageText(i.category))
 }
 
 func (i mergeTargetItem) FilterValue() string {
 	return i.category.Name
 }
 
-func NewCategoryMergeModel(categoryService *service.CategoryService, sourceCategory *models.CategoryWithTotal) *CategoryMergeModel {
+func NewCategoryMergeModel(categoryService *service.CategoryService, sourceCategories []*models.CategoryWithTotal) *CategoryMergeModel {
 	delegate := list.NewDefaultDelegate()
 	delegate.Styles.SelectedTitle = delegate.Styles.SelectedTitle.Copy().
 		Foreground(lipgloss.Color(styles.PrimaryColor)).
@@ -66,23 +72,36 @@ func NewCategoryMergeModel(categoryService *service.CategoryService, sourceCateg
 		BorderForeground(lipgloss.Color(styles.PrimaryColor))
 
 	l := list.New([]list.Item{}, delegate, 0, 0)
-	l.Title = fmt.Sprintf("Select Target Category for '%s'", sourceCategory.Name)
+	l.Title = fmt.Sprintf("Select Target Category for '%s'", sourceCategories[0].Name)
+	if len(sourceCategories) > 1 {
+		l.Title = fmt.Sprintf("Select Target Category for %d categories", len(sourceCategories))
+	}
 	l.SetShowStatusBar(true)
 	l.SetFilteringEnabled(true)
 	l.KeyMap.Quit.SetEnabled(false)
 
 	return &CategoryMergeModel{
-		categoryService: categoryService,
-		sourceCategory:  sourceCategory,
-		targetList:      l,
+		categoryService:  categoryService,
+		sourceCategories: sourceCategories,
+		targetList:       l,
 	}
 }
 
+// SuggestTarget highlights the category in the target list, e.g. when a
+// merge of similar categories is suggested, so enter picks it
+func (m *CategoryMergeModel) SuggestTarget(categoryID uint) {
+	m.suggestedTarget = categoryID
+}
+
 func (m *CategoryMergeModel) Init() tea.Cmd {
 	return m.loadTargetCategories()
 }
 
 func (m *CategoryMergeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	if m.createForm != nil {
+		return m.updateCreateForm(msg)
+	}
+	
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
 		if m.confirmMerge {
@@ -92,6 +111,7 @@ func (m *CategoryMergeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			case "n", "N", "esc":
 				m.confirmMerge = false
 				m.selectedTarget = nil
+				m.newTarget = nil
 			}
 			return m, nil
 		}
@@ -102,7 +122,11 @@ func (m *CategoryMergeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 			
 		case "enter":
-			if item, ok := m.targetList.SelectedItem().(mergeTargetItem); ok {
+			switch item := m.targetList.SelectedItem().(type) {
+			case createTargetItem:
+				m.createForm = newCategoryCreateModelForType(m.categoryService, m.sourceCategories[0].Type)
+				return m, m.createForm.Init()
+			case mergeTargetItem:
 				m.selectedTarget = item.category
 				m.confirmMerge = true
 			}
@@ -110,16 +134,21 @@ func (m *CategoryMergeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		
 	case targetCategoriesLoadedMsg:
 		m.targetCategories = msg.categories
-		items := make([]list.Item, 0)
+		items := []list.Item{createTargetItem{}}
 		
-		// Only include categories of the same type, excluding the source category
+		// Only include categories of the same type, excluding the source categories
 		for _, cat := range m.targetCategories {
-			if cat.Type == m.sourceCategory.Type && cat.ID != m.sourceCategory.ID {
+			if cat.Type == m.sourceCategories[0].Type && !m.isSource(cat.ID) {
 				items = append(items, mergeTargetItem{category: cat})
 			}
 		}
 		
 		m.targetList.SetItems(items)
+		for i, item := range items {
+			if target, ok := item.(mergeTargetItem); ok && target.category.ID == m.suggestedTarget {
+				m.targetList.Select(i)
+			}
+		}
 		return m, nil
 		
 	case categoryMergeSuccessMsg:
@@ -130,11 +159,11 @@ func (m *CategoryMergeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.errorMsg = msg.error.Error()
 		m.confirmMerge = false
 		m.selectedTarget = nil
+		m.newTarget = nil
 		return m, nil
 		
 	case tea.WindowSizeMsg:
-		h, v := styles.AppStyle.GetFrameSize()
-		m.targetList.SetSize(msg.Width-h, msg.Height-v-8)
+		m.SetSize(msg.Width, msg.Height)
 	}
 
 	var cmd tea.Cmd
@@ -142,23 +171,84 @@ func (m *CategoryMergeModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, cmd
 }
 
+func (m *CategoryMergeModel) updateCreateForm(msg tea.Msg) (tea.Model, tea.Cmd) {
+	newForm, cmd := m.createForm.Update(msg)
+	m.createForm = newForm.(*CategoryEditModel)
+	
+	if m.createForm.completed {
+		// The new category becomes the merge target, created only once the
+		// merge is confirmed
+		m.newTarget = m.createForm.category
+		m.selectedTarget = &models.CategoryWithTotal{Category: *m.newTarget}
+		m.confirmMerge = true
+		m.createForm = nil
+		return m, nil
+	} else if m.createForm.cancelled {
+		m.createForm = nil
+	}
+	return m, cmd
+}
+
+func (m *CategoryMergeModel) isSource(categoryID uint) bool {
+	for _, source := range m.sourceCategories {
+		if source.ID == categoryID {
+			return true
+		}
+	}
+	return false
+}
+
+func (m *CategoryMergeModel) sourceTransactionCount() int {
+	count := 0
+	for _, source := range m.sourceCategories {
+		count += source.Count
+	}
+	return count
+}
+
+// sourceTotal is the lifetime total in USD of the transactions being moved
+func (m *CategoryMergeModel) sourceTotal() float64 {
+	var total float64
+	for _, source := range m.sourceCategories {
+		total += source.Total
+	}
+	return total
+}
+
+func (m *CategoryMergeModel) sourceNames() string {
+	names := make([]string, len(m.sourceCategories))
+	for i, source := range m.sourceCategories {
+		names[i] = fmt.Sprintf("'%s'", source.Name)
+	}
+	return strings.Join(names, ", ")
+}
+
 func (m *CategoryMergeModel) View() string {
+	if m.createForm != nil {
+		return m.createForm.View()
+	}
+	
 	var b strings.Builder
 	
 	b.WriteString(styles.TitleStyle.Render("Merge Categories"))
 	b.WriteString("\n\n")
 
 	// Source category info
-	sourceIcon := m.sourceCategory.Icon
-	if sourceIcon == "" {
-		sourceIcon = "📁"
+	label := "Source Category:"
+	if len(m.sourceCategories) > 1 {
+		label = "Source Categories:"
+	}
+	b.WriteString(styles.LabelStyle.Render(label))
+	b.WriteString("\n")
+	for _, source := range m.sourceCategories {
+		sourceIcon := source.Icon
+		if sourceIcon == "" {
+			sourceIcon = "📁"
+		}
+		b.WriteString(fmt.Sprintf("  %s %s (%s, %s)\n", 
+			sourceIcon, source.Name, source.Type, usageText(source)))
 	}
-	
-	b.WriteString(styles.LabelStyle.Render("Source Category:"))
 	b.WriteString("\n")
-	b.WriteString(fmt.Sprintf("  %s %s (%s, %d transactions)", 
-		sourceIcon, m.sourceCategory.Name, m.sourceCategory.Type, m.sourceCategory.Count))
-	b.WriteString("\n\n")
 
 	if m.confirmMerge && m.selectedTarget != nil {
 		// Confirmation dialog
@@ -169,12 +259,12 @@ func (m *CategoryMergeModel) View() string {
 		
 		b.WriteString(styles.WarningStyle.Render("⚠️  CONFIRM MERGE"))
 		b.WriteString("\n\n")
-		b.WriteString(fmt.Sprintf("Merge '%s' into '%s'?", m.sourceCategory.Name, m.selectedTarget.Name))
+		b.WriteString(fmt.Sprintf("Merge %s into '%s'?", m.sourceNames(), m.selectedTarget.Name))
 		b.WriteString("\n")
-		b.WriteString(fmt.Sprintf("This will move %d transactions from '%s' to '%s'", 
-			m.sourceCategory.Count, m.sourceCategory.Name, m.selectedTarget.Name))
+		b.WriteString(fmt.Sprintf("This will move %d transactions totalling $%s from %s to '%s'", 
+			m.sourceTransactionCount(), styles.FormatNumber(m.sourceTotal()), m.sourceNames(), m.selectedTarget.Name))
 		b.WriteString("\n")
-		b.WriteString(styles.ErrorStyle.Render("This action cannot be undone!"))
+		b.WriteString(styles.WarningStyle.Render("Press U on the dashboard to undo this merge."))
 		b.WriteString("\n\n")
 		b.WriteString("Continue? (y/n)")
 		
@@ -208,7 +298,7 @@ func (m *CategoryMergeModel) View() string {
 // Commands
 func (m *CategoryMergeModel) loadTargetCategories() tea.Cmd {
 	return func() tea.Msg {
-		categories, err := m.categoryService.GetAllWithUsageCount()
+		categories, err := m.categoryService.GetAllWithUsageCount(context.Background())
 		if err != nil {
 			return categoryMergeErrorMsg{error: err}
 		}
@@ -222,7 +312,19 @@ func (m *CategoryMergeModel) performMerge() tea.Cmd {
 			return categoryMergeErrorMsg{error: fmt.Errorf("no target category selected")}
 		}
 		
-		err := m.categoryService.MergeCategories(m.sourceCategory.ID, m.selectedTarget.ID)
+		sourceIDs := make([]uint, len(m.sourceCategories))
+		for i, source := range m.sourceCategories {
+			sourceIDs[i] = source.ID
+		}
+		
+		var err error
+		if m.newTarget != nil {
+			// A copy, so a failed attempt doesn't leave an ID behind
+			target := *m.newTarget
+			err = m.categoryService.MergeIntoNew(context.Background(), sourceIDs, &target)
+		} else {
+			err = m.categoryService.MergeMany(context.Background(), sourceIDs, m.selectedTarget.ID)
+		}
 		if err != nil {
 			return categoryMergeErrorMsg{error: err}
 		}
@@ -240,4 +342,10 @@ type categoryMergeSuccessMsg struct{}
 
 type categoryMergeErrorMsg struct {
 	error error
-}
\ No newline at end of file
+}
+
+// SetSize fits the target list to the window below the merge summary
+func (m *CategoryMergeModel) SetSize(width, height int) {
+	h, v := styles.AppStyle.GetFrameSize()
+	m.targetList.SetSize(width-h, height-v-8)
+}
@@ -0,0 +1,56 @@
+package views
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"burnwise/internal/models"
+	"burnwise/internal/repository"
+	"burnwise/internal/service"
+	test "burnwise/test/helpers"
+)
+
+func TestCategoryMerge_NewTargetOnlyCreatedOnConfirm(t *testing.T) {
+	db := test.SetupTestDB(t)
+	repo := repository.NewCategoryRepository(db)
+	categoryService := service.NewCategoryService(repo)
+	coffee := test.CreateTestCategory(t, db, "Coffee", models.TransactionTypeExpense)
+	test.CreateTestTransaction(t, db, 4.50, coffee.ID)
+
+	m := NewCategoryMergeModel(categoryService, []*models.CategoryWithTotal{{Category: *coffee}})
+	update := func(msg tea.Msg) tea.Cmd {
+		_, cmd := m.Update(msg)
+		return cmd
+	}
+	update(m.Init()())
+	createTarget := func() {
+		update(tea.KeyMsg{Type: tea.KeyEnter}) // "Create new category"
+		require.NotNil(t, m.createForm)
+		update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("Eating Out")})
+		update(update(tea.KeyMsg{Type: tea.KeyCtrlS})())
+		require.True(t, m.confirmMerge)
+	}
+	eatingOut := func() *models.Category {
+		category, _ := repo.FindByName(t.Context(), "Eating Out", models.TransactionTypeExpense)
+		return category
+	}
+
+	// Declining the merge leaves no new category behind
+	createTarget()
+	assert.Nil(t, eatingOut())
+	update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("n")})
+	assert.False(t, m.confirmMerge)
+	assert.Nil(t, eatingOut())
+
+	createTarget()
+	update(update(tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune("y")})())
+	require.True(t, m.completed)
+	target := eatingOut()
+	require.NotNil(t, target)
+	count, err := categoryService.GetUsageCount(t.Context(), target.ID)
+	require.NoError(t, err)
+	assert.Equal(t, int64(1), count)
+}
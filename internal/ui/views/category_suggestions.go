@@ -0,0 +1,112 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/models"
+	"burnwise/internal/ui/styles"
+)
+
+// openSuggestions lists the groups of similar categories, if there are any
+func (m *CategoryListModel) openSuggestions() tea.Cmd {
+	if len(m.similar) == 0 {
+		return statusInfo("No similar categories found")
+	}
+	m.suggestionCursor = 0
+	m.mode = categoryListModeSuggestions
+	return nil
+}
+
+// updateSuggestions handles a key while the suggestions are open: ↑/↓ move
+// between the groups, enter opens the merge of the one selected and esc
+// goes back to the list
+func (m *CategoryListModel) updateSuggestions(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc", "q", "f":
+		m.mode = categoryListModeView
+	case "up", "k":
+		if m.suggestionCursor > 0 {
+			m.suggestionCursor--
+		}
+	case "down", "j":
+		if m.suggestionCursor < len(m.similar)-1 {
+			m.suggestionCursor++
+		}
+	case "enter":
+		sources, target := m.suggestedMerge(m.similar[m.suggestionCursor])
+		if len(sources) == 0 || target == nil {
+			return statusError(fmt.Errorf("these categories can't be merged"))
+		}
+		m.mergeForm = NewCategoryMergeModel(m.categoryService, sources)
+		m.mergeForm.SuggestTarget(target.ID)
+		m.mergeForm.SetSize(m.width, m.height)
+		m.mode = categoryListModeMerge
+		return m.mergeForm.Init()
+	}
+	return nil
+}
+
+// suggestedMerge picks which category of a similar group to keep: a default
+// one if there is one, as those can't be merged away, or else the one with
+// the most transactions. The others are merged into it.
+func (m *CategoryListModel) suggestedMerge(group []*models.Category) (sources []*models.CategoryWithTotal, target *models.CategoryWithTotal) {
+	byID := make(map[uint]*models.CategoryWithTotal, len(m.categories))
+	for _, category := range m.categories {
+		byID[category.ID] = category
+	}
+
+	var members []*models.CategoryWithTotal
+	for _, category := range group {
+		if member, ok := byID[category.ID]; ok {
+			members = append(members, member)
+		}
+	}
+	for _, member := range members {
+		if target == nil || (member.IsDefault && !target.IsDefault) ||
+			(member.IsDefault == target.IsDefault && member.Count > target.Count) {
+			target = member
+		}
+	}
+	for _, member := range members {
+		if member != target && !member.IsDefault {
+			sources = append(sources, member)
+		}
+	}
+	return sources, target
+}
+
+// renderSuggestions lists each group of similar categories with what
+// merging it would keep, e.g. "Foods → Food (12 transactions)"
+func (m *CategoryListModel) renderSuggestions() string {
+	var content strings.Builder
+	content.WriteString(styles.TitleStyle.Render("🔍 Similar categories"))
+	content.WriteString("\n\n")
+
+	muted := lipgloss.NewStyle().Foreground(styles.Muted)
+	for i, group := range m.similar {
+		sources, target := m.suggestedMerge(group)
+		if target == nil {
+			continue
+		}
+		names := make([]string, len(sources))
+		for j, source := range sources {
+			names[j] = source.Name
+		}
+		line := fmt.Sprintf("%s → %s", strings.Join(names, ", "), target.Name)
+		detail := muted.Render(fmt.Sprintf("  %s · %s", target.Type, usageText(target)))
+		if i == m.suggestionCursor {
+			line = styles.SelectedStyle.Render("▸ " + line)
+		} else {
+			line = "  " + line
+		}
+		content.WriteString(line + detail + "\n")
+	}
+
+	content.WriteString("\n")
+	content.WriteString(styles.HelpStyle.Render("[↑/↓]move  [enter]merge  [esc]back"))
+	return styles.AppStyle.Render(content.String())
+}
@@ -0,0 +1,182 @@
+package views
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+	"github.com/sahilm/fuzzy"
+
+	"burnwise/internal/ui/styles"
+)
+
+// paletteMaxResults caps how many matching commands are listed at once
+const paletteMaxResults = 10
+
+// PaletteCommand is one action offered by the command palette
+type PaletteCommand struct {
+	ID    string
+	Title string
+	Key   string // shortcut shown next to the title, if any
+}
+
+// PaletteSelectedMsg is sent when a command is chosen from the palette
+type PaletteSelectedMsg struct{ ID string }
+
+// PaletteClosedMsg is sent when the palette is dismissed without a choice
+type PaletteClosedMsg struct{}
+
+// paletteCommands adapts the commands for fuzzy matching on their titles
+type paletteCommands []PaletteCommand
+
+func (c paletteCommands) String(i int) string { return c[i].Title }
+func (c paletteCommands) Len() int            { return len(c) }
+
+// CommandPalette lists every action in the app and fuzzy-filters them as
+// the user types
+type CommandPalette struct {
+	commands paletteCommands
+	query    textinput.Model
+	matches  fuzzy.Matches
+	cursor   int
+}
+
+func NewCommandPalette(commands []PaletteCommand) *CommandPalette {
+	query := textinput.New()
+	query.Placeholder = "Type a command..."
+	query.Prompt = ": "
+	query.CharLimit = 50
+	query.Width = 40
+
+	p := &CommandPalette{
+		commands: commands,
+		query:    query,
+	}
+	p.filter()
+	return p
+}
+
+// Open clears the previous search and focuses the input
+func (p *CommandPalette) Open() tea.Cmd {
+	p.query.SetValue("")
+	p.cursor = 0
+	p.filter()
+	return p.query.Focus()
+}
+
+func (p *CommandPalette) Update(msg tea.Msg) (*CommandPalette, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "esc", "ctrl+c":
+			return p, func() tea.Msg { return PaletteClosedMsg{} }
+		case "enter":
+			if len(p.matches) == 0 {
+				return p, nil
+			}
+			id := p.commands[p.matches[p.cursor].Index].ID
+			return p, func() tea.Msg { return PaletteSelectedMsg{ID: id} }
+		case "up", "ctrl+p", "ctrl+k":
+			if p.cursor > 0 {
+				p.cursor--
+			}
+			return p, nil
+		case "down", "ctrl+n", "ctrl+j", "tab":
+			if p.cursor < len(p.matches)-1 {
+				p.cursor++
+			}
+			return p, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	previous := p.query.Value()
+	p.query, cmd = p.query.Update(msg)
+	if p.query.Value() != previous {
+		p.cursor = 0
+		p.filter()
+	}
+	return p, cmd
+}
+
+// filter matches the query against the command titles. An empty query
+// lists every command in its original order.
+func (p *CommandPalette) filter() {
+	query := strings.TrimSpace(p.query.Value())
+	if query == "" {
+		p.matches = make(fuzzy.Matches, len(p.commands))
+		for i, command := range p.commands {
+			p.matches[i] = fuzzy.Match{Str: command.Title, Index: i}
+		}
+		return
+	}
+	p.matches = fuzzy.FindFrom(query, p.commands)
+}
+
+func (p *CommandPalette) View() string {
+	var b strings.Builder
+	b.WriteString(styles.TitleStyle.Render("Command Palette"))
+	b.WriteString("\n")
+	b.WriteString(p.query.View())
+	b.WriteString("\n\n")
+
+	if len(p.matches) == 0 {
+		b.WriteString(styles.HelpStyle.Render("No matching commands"))
+	}
+
+	// Keep the cursor visible when there are more matches than rows
+	start := 0
+	if p.cursor >= paletteMaxResults {
+		start = p.cursor - paletteMaxResults + 1
+	}
+	for i := start; i < len(p.matches) && i < start+paletteMaxResults; i++ {
+		match := p.matches[i]
+		command := p.commands[match.Index]
+
+		line := highlightMatches(command.Title, match.MatchedIndexes, i == p.cursor)
+		line = lipgloss.NewStyle().Width(36).Render(line)
+		if command.Key != "" {
+			line += styles.HelpStyle.Render(command.Key)
+		}
+		if i == p.cursor {
+			line = "› " + line
+		} else {
+			line = "  " + line
+		}
+		b.WriteString(line + "\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(styles.HelpStyle.Render("↑/↓ select • enter run • esc close"))
+
+	return lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Primary).
+		Padding(1, 2).
+		Render(b.String())
+}
+
+// highlightMatches renders the characters matched by the fuzzy search in
+// bold. matched holds byte offsets into title.
+func highlightMatches(title string, matched []int, selected bool) string {
+	base := lipgloss.NewStyle()
+	if selected {
+		base = base.Foreground(styles.Primary)
+	}
+	hit := base.Copy().Bold(true).Underline(true)
+
+	isMatch := make(map[int]bool, len(matched))
+	for _, i := range matched {
+		isMatch[i] = true
+	}
+
+	var b strings.Builder
+	for i, r := range title {
+		if isMatch[i] {
+			b.WriteString(hit.Render(string(r)))
+		} else {
+			b.WriteString(base.Render(string(r)))
+		}
+	}
+	return b.String()
+}
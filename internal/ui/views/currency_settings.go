@@ -1,6 +1,7 @@
 package views
 
 import (
+	"context"
 	"fmt"
 	"strings"
 
@@ -9,6 +10,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"burnwise/internal/models"
 	"burnwise/internal/service"
 	"burnwise/internal/ui/styles"
 )
@@ -16,6 +18,7 @@ import (
 type currencyItem struct {
 	code    string
 	enabled bool
+	stat    *models.CurrencyStat
 }
 
 func (i currencyItem) FilterValue() string { return i.code }
@@ -29,15 +32,35 @@ func (i currencyItem) Title() string {
 }
 
 func (i currencyItem) Description() string {
-	if i.enabled {
-		return "Enabled"
+	if !i.enabled {
+		if i.stat != nil {
+			return "Disabled · " + i.usage()
+		}
+		return "Disabled"
 	}
-	return "Disabled"
+	return "Enabled · " + i.usage()
+}
+
+// usage describes how many transactions use the currency and their volume
+func (i currencyItem) usage() string {
+	if i.stat == nil {
+		return "no transactions"
+	}
+	noun := "transactions"
+	if i.stat.Count == 1 {
+		noun = "transaction"
+	}
+	usage := fmt.Sprintf("%d %s, %s %s", i.stat.Count, noun, styles.FormatNumberIn(i.stat.Total, i.code), i.code)
+	if i.code != "USD" {
+		usage += fmt.Sprintf(" ($%s)", styles.FormatNumber(i.stat.TotalUSD))
+	}
+	return usage
 }
 
 type CurrencySettings struct {
 	list            list.Model
 	currencies      []currencyItem
+	stats           map[string]*models.CurrencyStat
 	settingsService *service.SettingsService
 	currencyService *service.CurrencyService
 	txService       *service.TransactionService
@@ -120,15 +143,31 @@ func NewCurrencySettings(settingsService *service.SettingsService, currencyServi
 }
 
 func (m *CurrencySettings) Init() tea.Cmd {
-	return nil
+	return m.loadBreakdown
+}
+
+func (m *CurrencySettings) loadBreakdown() tea.Msg {
+	stats, err := m.txService.GetCurrencyBreakdown(context.Background())
+	return currencyBreakdownLoadedMsg{stats: stats, err: err}
 }
 
 func (m *CurrencySettings) Update(msg tea.Msg) (*CurrencySettings, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.WindowSizeMsg:
-		m.width = msg.Width
-		m.height = msg.Height
-		m.list.SetSize(msg.Width, msg.Height-2)
+		m.SetSize(msg.Width, msg.Height)
+
+	case currencyBreakdownLoadedMsg:
+		if msg.err != nil {
+			m.err = msg.err
+			m.message = fmt.Sprintf("Error loading currency usage: %v", msg.err)
+			return m, nil
+		}
+		m.stats = make(map[string]*models.CurrencyStat, len(msg.stats))
+		for _, stat := range msg.stats {
+			m.stats[stat.Currency] = stat
+		}
+		m.updateCurrencyList()
+		return m, nil
 
 	case tea.KeyMsg:
 		// Clear message on any key press
@@ -145,17 +184,19 @@ func (m *CurrencySettings) Update(msg tea.Msg) (*CurrencySettings, tea.Cmd) {
 				// Toggle currency
 				if i.enabled {
 					// Try to disable
-					count, err := m.txService.CountByCurrency(i.code)
+					count, err := m.txService.CountByCurrency(context.Background(), i.code)
 					if err != nil {
 						m.err = err
 						m.message = fmt.Sprintf("Error checking currency usage: %v", err)
+					} else if count > 0 && i.stat != nil {
+						m.message = fmt.Sprintf("Cannot disable %s: used by %s", i.code, i.usage())
 					} else if count > 0 {
 						m.message = fmt.Sprintf("Cannot disable %s: %d transactions use this currency", i.code, count)
 					} else if i.code == m.settingsService.GetDefaultCurrency() {
 						m.message = fmt.Sprintf("Cannot disable default currency %s", i.code)
 					} else {
 						// Disable the currency
-						if err := m.settingsService.DisableCurrency(i.code, m.txService); err != nil {
+						if err := m.settingsService.DisableCurrency(context.Background(), i.code, m.txService); err != nil {
 							m.err = err
 							m.message = fmt.Sprintf("Failed to disable currency: %v", err)
 						} else {
@@ -194,6 +235,7 @@ func (m *CurrencySettings) updateCurrencyList() {
 	// Update currency items
 	for i := range m.currencies {
 		m.currencies[i].enabled = enabledMap[m.currencies[i].code]
+		m.currencies[i].stat = m.stats[m.currencies[i].code]
 	}
 
 	// Update list items
@@ -236,4 +278,15 @@ func (m *CurrencySettings) View() string {
 	return b.String()
 }
 
-type BackToDashboardMsg struct{}
\ No newline at end of file
+type BackToDashboardMsg struct{}
+
+type currencyBreakdownLoadedMsg struct {
+	stats []*models.CurrencyStat
+	err   error
+}
+
+func (m *CurrencySettings) SetSize(width, height int) {
+	m.width = width
+	m.height = height
+	m.list.SetSize(width, height-2)
+}
@@ -1,10 +1,12 @@
 package views
 
 import (
+	"context"
 	"fmt"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
@@ -17,13 +19,42 @@ type Dashboard struct {
 	width    int
 	height   int
 	
-	txService     *service.TransactionService
-	budgetService *service.BudgetService
+	txService        *service.TransactionService
+	budgetService    *service.BudgetService
+	recurringService *service.RecurringTransactionService
+	categoryService  *service.CategoryService
+	
+	// Quick add bar, opened with 'a': the line being typed, the error with
+	// the last attempt and what was added last
+	quickAdd      textinput.Model
+	quickAdding   bool
+	quickAddErr   error
+	quickAddAdded string
+	
+	// The overall monthly limit, its level when last loaded and the warning
+	// shown when a reload finds it crossed a threshold
+	monthlyLimit  float64
+	limitLevel    int
+	limitLoaded   bool
+	limitAlert    string
+	
+	// Changing the end date of the recurring transaction under the cursor
+	// among those ending soon, opened with 'E'
+	endingCursor  int
+	extending     bool
+	extendInput   textinput.Model
+	extendErr     error
 	
 	summary      *models.TransactionSummary
 	burnRate     *models.BurnRateSummary
+	nextIncome   *models.NextIncome
 	transactions []*models.Transaction
 	budgets      []*models.BudgetStatus
+	limit        *models.SpendingLimitStatus
+	velocity     *models.SpendingVelocity
+	allowances   []*models.DailyAllowance
+	ending       []*models.RecurringTransaction
+	widgets      []models.DashboardWidget
 	
 	loading      bool
 	err          error
@@ -33,23 +64,120 @@ func NewDashboard(txService *service.TransactionService, budgetService *service.
 	return &Dashboard{
 		txService:     txService,
 		budgetService: budgetService,
+		widgets:       models.DashboardSettings{}.Layout(),
 		loading:       true,
 	}
 }
 
+// SetLayout sets which widgets the dashboard shows, in order
+func (d *Dashboard) SetLayout(widgets []models.DashboardWidget) {
+	d.widgets = widgets
+}
+
+// SetRecurringService enables the next income widget and the warning
+// about recurring transactions ending soon
+func (d *Dashboard) SetRecurringService(recurringService *service.RecurringTransactionService) {
+	d.recurringService = recurringService
+}
+
+// SetMonthlyLimit sets the overall monthly spending limit in USD that the
+// limit widget tracks. Zero hides the widget.
+func (d *Dashboard) SetMonthlyLimit(limit float64) {
+	d.monthlyLimit = limit
+}
+
+// KeepLimitLevel carries the monthly limit's level over from the dashboard
+// this one replaces, so that a limit saved lower than what was spent warns
+// on the next load
+func (d *Dashboard) KeepLimitLevel(previous *Dashboard) {
+	d.limitLevel = previous.limitLevel
+	d.limitLoaded = previous.limitLoaded
+}
+
+// SetCategoryService enables the quick add bar
+func (d *Dashboard) SetCategoryService(categoryService *service.CategoryService) {
+	d.categoryService = categoryService
+}
+
+// IsQuickAdding reports whether the quick add bar is taking input, in which
+// case keys should not be treated as navigation shortcuts
+func (d *Dashboard) IsQuickAdding() bool {
+	return d.quickAdding
+}
+
+// IsExtending reports whether a new end date for a recurring transaction is
+// being typed, in which case keys should not be treated as navigation
+// shortcuts
+func (d *Dashboard) IsExtending() bool {
+	return d.extending
+}
+
+// QuickAddFullFormMsg asks for the full transaction form, prefilled with
+// what the quick add bar understood, or empty when it understood nothing
+type QuickAddFullFormMsg struct{ Transaction *models.Transaction }
+
+type quickAddedMsg struct {
+	tx  *models.Transaction
+	err error
+}
+
 func (d *Dashboard) Init() tea.Cmd {
 	return d.loadData
 }
 
 func (d *Dashboard) Update(msg tea.Msg) (*Dashboard, tea.Cmd) {
 	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if d.quickAdding {
+			return d, d.updateQuickAdd(msg)
+		}
+		if d.extending {
+			return d, d.updateExtend(msg)
+		}
+		d.quickAddAdded = ""
+		d.limitAlert = ""
+		if msg.String() == "a" && d.categoryService != nil {
+			d.quickAdd = textinput.New()
+			d.quickAdd.Placeholder = "Food 12.50 coffee"
+			d.quickAdd.Width = 40
+			d.quickAdding = true
+			d.quickAddErr = nil
+			return d, d.quickAdd.Focus()
+		}
+		if msg.String() == "E" && len(d.ending) > 0 {
+			return d, d.openExtend()
+		}
+		d.moveEndingCursor(msg.String())
+		
+	case quickAddedMsg:
+		if msg.err != nil {
+			d.quickAddErr = msg.err
+			return d, nil
+		}
+		d.quickAdding = false
+		d.quickAddAdded = fmt.Sprintf("Added %s %s %s", msg.tx.Category.Name,
+			styles.FormatNumberIn(msg.tx.Amount, msg.tx.Currency), msg.tx.Currency)
+		if msg.tx.Description != "" {
+			d.quickAddAdded += " · " + msg.tx.Description
+		}
+		return d, d.loadData
+		
 	case dashboardDataMsg:
 		d.loading = false
 		d.summary = msg.summary
 		d.burnRate = msg.burnRate
+		d.nextIncome = msg.nextIncome
 		d.transactions = msg.transactions
 		d.budgets = msg.budgets
+		d.limit = msg.limit
+		d.velocity = msg.velocity
+		d.allowances = msg.allowances
+		d.ending = msg.ending
+		if d.endingCursor >= d.endingListed() {
+			d.endingCursor = 0
+		}
 		d.err = msg.err
+		d.checkLimit()
 	}
 	
 	return d, nil
@@ -64,27 +192,27 @@ func (d *Dashboard) View() string {
 		return styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", d.err))
 	}
 	
-	header := d.renderHeader()
-	burnRate := d.renderBurnRate()
-	summary := d.renderSummary()
-	transactions := d.renderRecentTransactions()
-	budgets := d.renderBudgetOverview()
-	help := d.renderHelp()
+	sections := []string{d.renderHeader(), ""}
+	if ending := d.renderEnding(); ending != "" {
+		sections = append(sections, ending, "")
+	}
+	for _, widget := range d.widgets {
+		if !widget.Enabled {
+			continue
+		}
+		if rendered := d.renderWidget(widget.Name); rendered != "" {
+			sections = append(sections, rendered, "")
+		}
+	}
+	if quickAdd := d.renderQuickAdd(); quickAdd != "" {
+		sections = append(sections, quickAdd, "")
+	}
+	if d.limitAlert != "" {
+		sections = append(sections, styles.WarningStyle.Render("⚠️  "+d.limitAlert), "")
+	}
+	sections = append(sections, d.renderHelp())
 	
-	content := lipgloss.JoinVertical(
-		lipgloss.Left,
-		header,
-		"",
-		burnRate,
-		"",
-		summary,
-		"",
-		budgets,
-		"",
-		transactions,
-		"",
-		help,
-	)
+	content := lipgloss.JoinVertical(lipgloss.Left, sections...)
 	
 	return lipgloss.NewStyle().
 		Width(d.width).
@@ -92,6 +220,101 @@ func (d *Dashboard) View() string {
 		Render(content)
 }
 
+// updateQuickAdd handles a key while the quick add bar is open: enter adds
+// the transaction, tab opens the full form instead and esc closes the bar
+func (d *Dashboard) updateQuickAdd(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		d.quickAdding = false
+		return nil
+	case "enter":
+		line := d.quickAdd.Value()
+		return func() tea.Msg {
+			tx, err := d.parseQuickAdd(line)
+			if err == nil {
+				err = d.txService.Create(context.Background(), tx)
+			}
+			return quickAddedMsg{tx: tx, err: err}
+		}
+	case "tab":
+		d.quickAdding = false
+		tx, err := d.parseQuickAdd(d.quickAdd.Value())
+		if err != nil {
+			tx = nil
+		}
+		return func() tea.Msg { return QuickAddFullFormMsg{Transaction: tx} }
+	}
+	
+	var cmd tea.Cmd
+	d.quickAdd, cmd = d.quickAdd.Update(msg)
+	return cmd
+}
+
+func (d *Dashboard) parseQuickAdd(line string) (*models.Transaction, error) {
+	categories, err := d.categoryService.GetPickable(context.Background(), "")
+	if err != nil {
+		return nil, err
+	}
+	return d.txService.ParseQuickEntry(line, categories)
+}
+
+func (d *Dashboard) renderQuickAdd() string {
+	if d.quickAdding {
+		bar := lipgloss.JoinHorizontal(lipgloss.Top, styles.FormLabelStyle.Render("Quick add:"), d.quickAdd.View())
+		hint := styles.HelpStyle.Render("category amount [description] · [enter]add  [tab]full form  [esc]cancel")
+		if d.quickAddErr != nil {
+			hint = styles.ErrorStyle.Render(d.quickAddErr.Error()) + "\n" + hint
+		}
+		return lipgloss.JoinVertical(lipgloss.Left, bar, hint)
+	}
+	if d.quickAddAdded != "" {
+		return styles.SuccessStyle.Render("✅ " + d.quickAddAdded)
+	}
+	return ""
+}
+
+// checkLimit warns when the monthly limit is found nearly used up or
+// exceeded where the previous load found it below that. The first load only
+// sets the starting level.
+func (d *Dashboard) checkLimit() {
+	level := 0
+	if d.limit != nil {
+		level = d.limit.Level()
+	}
+	if d.limitLoaded && level > d.limitLevel {
+		if d.limit.IsOverLimit {
+			d.limitAlert = fmt.Sprintf("Monthly spending limit exceeded: $%s of $%s",
+				styles.FormatNumber(d.limit.Spent), styles.FormatNumber(d.limit.Limit))
+		} else {
+			d.limitAlert = fmt.Sprintf("%.0f%% of the monthly spending limit used: $%s of $%s",
+				d.limit.PercentUsed, styles.FormatNumber(d.limit.Spent), styles.FormatNumber(d.limit.Limit))
+		}
+	}
+	d.limitLevel = level
+	d.limitLoaded = d.err == nil
+}
+
+func (d *Dashboard) renderWidget(name string) string {
+	switch name {
+	case models.WidgetBurnRate:
+		return d.renderBurnRate()
+	case models.WidgetNextIncome:
+		return d.renderNextIncome()
+	case models.WidgetSummary:
+		return d.renderSummary()
+	case models.WidgetLimit:
+		return d.renderLimit()
+	case models.WidgetPace:
+		return d.renderPace()
+	case models.WidgetBudgets:
+		return d.renderBudgetOverview()
+	case models.WidgetTransactions:
+		return d.renderRecentTransactions()
+	default:
+		return ""
+	}
+}
+
 func (d *Dashboard) SetSize(width, height int) {
 	d.width = width
 	d.height = height
@@ -129,7 +352,7 @@ func (d *Dashboard) renderBurnRate() string {
 	
 	titleLine := title + lipgloss.NewStyle().
 		Foreground(styles.Primary).
-		Render(strings.Repeat("━", d.width-lipgloss.Width(title)-4))
+		Render(strings.Repeat("━", max(0, d.width-lipgloss.Width(title)-4)))
 	
 	// Burn rate details
 	recurringLine := fmt.Sprintf("Recurring:   %s (%d active)",
@@ -171,6 +394,44 @@ func (d *Dashboard) renderBurnRate() string {
 	return lipgloss.JoinVertical(lipgloss.Left, lines...)
 }
 
+// renderNextIncome shows how long until the next recurring income and what
+// has been spent this month in the meantime. It is hidden when no
+// recurring income is set up.
+func (d *Dashboard) renderNextIncome() string {
+	if d.nextIncome == nil {
+		return ""
+	}
+	
+	var when string
+	switch days := models.CalendarDaysBetween(time.Now(), d.nextIncome.Date); {
+	case days <= 0:
+		when = "today"
+	case days == 1:
+		when = "tomorrow"
+	default:
+		when = fmt.Sprintf("in %d days", days)
+	}
+	
+	name := d.nextIncome.Description
+	if name == "" {
+		name = d.nextIncome.Recurring.Category.Name
+	}
+	
+	line := fmt.Sprintf("Next income: %s %s (%s)",
+		lipgloss.NewStyle().Foreground(styles.Income).Render(styles.FormatAmount(d.nextIncome.AmountUSD, "$")),
+		when,
+		name)
+	
+	lines := []string{lipgloss.NewStyle().Bold(true).Render(line)}
+	if d.summary != nil {
+		lines = append(lines, lipgloss.NewStyle().
+			Foreground(styles.Muted).
+			Render(fmt.Sprintf("Spent so far this month: %s", styles.FormatAmount(d.summary.TotalExpenses, "$"))))
+	}
+	
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
 func (d *Dashboard) renderSummary() string {
 	if d.summary == nil {
 		return ""
@@ -184,14 +445,21 @@ func (d *Dashboard) renderSummary() string {
 	
 	titleLine := title + lipgloss.NewStyle().
 		Foreground(styles.Primary).
-		Render(strings.Repeat("━", d.width-lipgloss.Width(title)-4))
+		Render(strings.Repeat("━", max(0, d.width-lipgloss.Width(title)-4)))
 	
 	incomeBar := d.renderProgressBar("Income", d.summary.TotalIncome, d.summary.TotalIncome, styles.Income)
 	expenseBar := d.renderProgressBar("Expenses", d.summary.TotalExpenses, d.summary.TotalIncome, styles.Expense)
+	if d.summary.TotalExpenses > 0 {
+		expenseBar += "\n" + lipgloss.NewStyle().
+			Foreground(styles.Muted).
+			Render(fmt.Sprintf("Fixed costs: $%s · Variable: $%s",
+				styles.FormatNumber(d.summary.RecurringExpenses),
+				styles.FormatNumber(d.summary.OneTimeExpenses)))
+	}
 	
 	divider := lipgloss.NewStyle().
 		Foreground(styles.Primary).
-		Render(strings.Repeat("━", d.width-4))
+		Render(strings.Repeat("━", max(0, d.width-4)))
 	
 	balance := lipgloss.NewStyle().
 		Bold(true).
@@ -207,6 +475,150 @@ func (d *Dashboard) renderSummary() string {
 	)
 }
 
+// renderLimit shows this month's expenses against the overall monthly
+// limit, colored like budgets as it fills up. It is hidden without a limit.
+func (d *Dashboard) renderLimit() string {
+	if d.limit == nil {
+		return ""
+	}
+	
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(styles.Primary).
+		Render("━━━ MONTHLY LIMIT ")
+	
+	titleLine := title + lipgloss.NewStyle().
+		Foreground(styles.Primary).
+		Render(strings.Repeat("━", max(0, d.width-lipgloss.Width(title)-4)))
+	
+	color := styles.Success
+	if d.limit.IsNearLimit {
+		color = styles.Warning
+	}
+	if d.limit.IsOverLimit {
+		color = styles.Error
+	}
+	
+	barWidth := d.width - 30 - 8 - 6
+	if barWidth < 10 {
+		barWidth = 10
+	}
+	filled := min(max(0, int(float64(barWidth)*d.limit.PercentUsed/100)), barWidth)
+	bar := lipgloss.NewStyle().Foreground(color).Render(
+		strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled))
+	
+	spent := lipgloss.NewStyle().
+		Width(30).
+		Render(fmt.Sprintf("$%s / $%s", styles.FormatNumber(d.limit.Spent), styles.FormatNumber(d.limit.Limit)))
+	
+	percent := lipgloss.NewStyle().
+		Width(6).
+		Align(lipgloss.Right).
+		Foreground(color).
+		Render(fmt.Sprintf("%.0f%%", d.limit.PercentUsed))
+	
+	remaining := fmt.Sprintf("$%s left", styles.FormatNumber(d.limit.Remaining))
+	if d.limit.IsOverLimit {
+		remaining = fmt.Sprintf("$%s over", styles.FormatNumber(-d.limit.Remaining))
+	}
+	remaining = lipgloss.NewStyle().Foreground(styles.Muted).Render(remaining)
+	if allowance := d.allowanceFor(0); allowance != nil {
+		days := fmt.Sprintf(" for the %d days left", allowance.DaysLeft)
+		if allowance.DaysLeft == 1 {
+			days = " for today"
+		}
+		remaining += lipgloss.NewStyle().Foreground(styles.Muted).Render(" · ") +
+			renderAllowance(allowance) +
+			lipgloss.NewStyle().Foreground(styles.Muted).Render(days)
+	}
+	
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleLine,
+		lipgloss.JoinHorizontal(lipgloss.Center, spent, bar, "  ", percent),
+		remaining,
+	)
+}
+
+// renderPace shows this month's spending as a gauge against the monthly
+// limit, or the monthly budgets without one, with a mark where an even pace
+// would be by today. It is hidden when there is neither.
+func (d *Dashboard) renderPace() string {
+	if d.velocity == nil {
+		return ""
+	}
+	
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Foreground(styles.Primary).
+		Render("━━━ SPENDING PACE ")
+	
+	titleLine := title + lipgloss.NewStyle().
+		Foreground(styles.Primary).
+		Render(strings.Repeat("━", max(0, d.width-lipgloss.Width(title)-4)))
+	
+	color := styles.Success
+	if d.velocity.IsAhead() {
+		color = styles.Warning
+	}
+	if d.velocity.Spent > d.velocity.Target {
+		color = styles.Error
+	}
+	
+	barWidth := d.width - 30 - 8 - 6
+	if barWidth < 10 {
+		barWidth = 10
+	}
+	filled := min(max(0, int(float64(barWidth)*d.velocity.Spent/d.velocity.Target)), barWidth)
+	mark := min(int(float64(barWidth)*d.velocity.Expected/d.velocity.Target), barWidth-1)
+	var bar strings.Builder
+	for i := 0; i < barWidth; i++ {
+		switch {
+		case i == mark:
+			bar.WriteString(lipgloss.NewStyle().Foreground(styles.Primary).Render("│"))
+		case i < filled:
+			bar.WriteString(lipgloss.NewStyle().Foreground(color).Render("█"))
+		default:
+			bar.WriteString(lipgloss.NewStyle().Foreground(color).Render("░"))
+		}
+	}
+	
+	spent := lipgloss.NewStyle().
+		Width(30).
+		Render(fmt.Sprintf("$%s / $%s", styles.FormatNumber(d.velocity.Spent), styles.FormatNumber(d.velocity.Target)))
+	
+	status := lipgloss.NewStyle().Bold(true).Foreground(color).Render(d.velocity.Status())
+	detail := lipgloss.NewStyle().Foreground(styles.Muted).Render(fmt.Sprintf(
+		" · $%s expected by day %d of %d", styles.FormatNumber(d.velocity.Expected), d.velocity.Day, d.velocity.DaysInMonth))
+	
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		titleLine,
+		lipgloss.JoinHorizontal(lipgloss.Center, spent, bar.String()),
+		status+detail,
+	)
+}
+
+// allowanceFor returns the daily allowance of the budget with the given ID,
+// or of the monthly limit for zero, or nil when there is none
+func (d *Dashboard) allowanceFor(budgetID uint) *models.DailyAllowance {
+	for _, allowance := range d.allowances {
+		if allowance.BudgetID == budgetID {
+			return allowance
+		}
+	}
+	return nil
+}
+
+// renderAllowance shows what can be spent per day, in red once the budget
+// is overspent and the allowance negative
+func renderAllowance(allowance *models.DailyAllowance) string {
+	if allowance.IsOver() {
+		return styles.ErrorStyle.Render(fmt.Sprintf("-$%s/day", styles.FormatNumber(-allowance.PerDay)))
+	}
+	return styles.SuccessStyle.Render(fmt.Sprintf("$%s/day", styles.FormatNumber(allowance.PerDay)))
+}
+
 func (d *Dashboard) renderProgressBar(label string, value, max float64, color lipgloss.Color) string {
 	if max == 0 {
 		max = 1
@@ -265,7 +677,7 @@ func (d *Dashboard) renderRecentTransactions() string {
 		lipgloss.NewStyle().Width(12).Align(lipgloss.Right).Render("Amount"),
 	)
 	
-	divider := strings.Repeat("─", d.width-4)
+	divider := strings.Repeat("─", max(0, d.width-4))
 	
 	var rows []string
 	for i, tx := range d.transactions {
@@ -274,21 +686,21 @@ func (d *Dashboard) renderRecentTransactions() string {
 		}
 		
 		date := tx.Date.Format("01/02")
-		category := fmt.Sprintf("%s %s", tx.Category.Icon, tx.Category.Name)
+		category := tx.CategoryLabel()
 		description := tx.Description
 		if len(description) > 28 {
 			description = description[:28] + "..."
 		}
 		
 		amount := styles.FormatAmount(tx.Amount, "$")
-		if tx.Type == models.TransactionTypeExpense {
+		if tx.IsOutflow() {
 			amount = styles.FormatAmount(-tx.Amount, "$")
 		}
 		
 		row := lipgloss.JoinHorizontal(
 			lipgloss.Top,
 			lipgloss.NewStyle().Width(12).Render(date),
-			lipgloss.NewStyle().Width(20).Render(category),
+			lipgloss.NewStyle().Width(20).Foreground(styles.CategoryColor(tx.Category.Color)).Render(category),
 			lipgloss.NewStyle().Width(30).Render(description),
 			lipgloss.NewStyle().Width(12).Align(lipgloss.Right).Render(amount),
 		)
@@ -320,19 +732,26 @@ func (d *Dashboard) renderBudgetOverview() string {
 			continue
 		}
 		
-		category := fmt.Sprintf("%s %s", status.Budget.Category.Icon, status.Budget.Category.Name)
+		category := status.Budget.CategoryLabel()
 		
 		barWidth := 20
-		bar := styles.ProgressBar(status.PercentUsed, barWidth)
+		bar := styles.CategoryProgressBar(status.PercentUsed, barWidth, status.Budget.Category.Color)
 		
 		spent := fmt.Sprintf("$%.0f/$%.0f", status.Spent, status.Budget.Amount)
 		
+		perDay := ""
+		if allowance := d.allowanceFor(status.Budget.ID); allowance != nil {
+			perDay = renderAllowance(allowance)
+		}
+		
 		row := lipgloss.JoinHorizontal(
 			lipgloss.Top,
 			lipgloss.NewStyle().Width(25).Render(category),
 			bar,
 			"  ",
 			lipgloss.NewStyle().Width(15).Align(lipgloss.Right).Render(spent),
+			"  ",
+			lipgloss.NewStyle().Width(14).Align(lipgloss.Right).Render(perDay),
 		)
 		
 		rows = append(rows, row)
@@ -350,53 +769,97 @@ func (d *Dashboard) renderBudgetOverview() string {
 }
 
 func (d *Dashboard) renderHelp() string {
-	help := []string{
-		"[n]ew",
+	help := []string{"[n]ew"}
+	if d.categoryService != nil {
+		help = append(help, "[a] quick add")
+	}
+	help = append(help,
 		"[t]ransactions",
 		"[b]udgets",
 		"[r]eports",
 		"[c]ategories",
 		"[s] Recurring",
 		"c[u]rrencies",
+		"settin[g]s",
+		"[U]ndo",
+		"[:]commands",
 		"[q]uit",
-	}
+	)
 	
 	return styles.HelpStyle.Render(strings.Join(help, "  "))
 }
 
 func (d *Dashboard) loadData() tea.Msg {
-	summary, err := d.txService.GetCurrentMonthSummary()
+	summary, err := d.txService.GetCurrentMonthSummary(context.Background())
+	if err != nil {
+		return dashboardDataMsg{err: err}
+	}
+	
+	burnRate, err := d.txService.GetCurrentMonthBurnRate(context.Background())
+	if err != nil {
+		return dashboardDataMsg{err: err}
+	}
+	
+	transactions, err := d.txService.GetRecentTransactions(context.Background(), 10)
+	if err != nil {
+		return dashboardDataMsg{err: err}
+	}
+	
+	budgets, err := d.budgetService.GetAllStatuses(context.Background())
 	if err != nil {
 		return dashboardDataMsg{err: err}
 	}
 	
-	burnRate, err := d.txService.GetCurrentMonthBurnRate()
+	limit, err := d.txService.GetMonthlyLimitStatus(context.Background(), d.monthlyLimit)
 	if err != nil {
 		return dashboardDataMsg{err: err}
 	}
 	
-	transactions, err := d.txService.GetRecentTransactions(10)
+	velocity, err := d.txService.GetSpendingVelocity(context.Background(), d.monthlyLimit, budgets, time.Now())
 	if err != nil {
 		return dashboardDataMsg{err: err}
 	}
 	
-	budgets, err := d.budgetService.GetAllStatuses()
+	allowances, err := d.budgetService.GetDailyAllowance(context.Background(), d.monthlyLimit, budgets)
 	if err != nil {
 		return dashboardDataMsg{err: err}
 	}
 	
+	var nextIncome *models.NextIncome
+	var ending []*models.RecurringTransaction
+	if d.recurringService != nil {
+		nextIncome, err = d.recurringService.GetNextIncome(context.Background())
+		if err != nil {
+			return dashboardDataMsg{err: err}
+		}
+		ending, err = d.recurringService.GetExpiring(context.Background(), service.EndingSoonDays)
+		if err != nil {
+			return dashboardDataMsg{err: err}
+		}
+	}
+	
 	return dashboardDataMsg{
 		summary:      summary,
 		burnRate:     burnRate,
+		nextIncome:   nextIncome,
 		transactions: transactions,
 		budgets:      budgets,
+		limit:        limit,
+		velocity:     velocity,
+		allowances:   allowances,
+		ending:       ending,
 	}
 }
 
 type dashboardDataMsg struct {
 	summary      *models.TransactionSummary
 	burnRate     *models.BurnRateSummary
+	nextIncome   *models.NextIncome
 	transactions []*models.Transaction
 	budgets      []*models.BudgetStatus
+	limit        *models.SpendingLimitStatus
+	velocity     *models.SpendingVelocity
+	allowances   []*models.DailyAllowance
+	ending       []*models.RecurringTransaction
 	err          error
 }
\ No newline at end of file
@@ -0,0 +1,149 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"burnwise/internal/models"
+	"burnwise/internal/ui/styles"
+)
+
+// endingListLimit caps the recurring transactions the dashboard warns about
+const endingListLimit = 3
+
+// endsIn says when a recurring transaction ends, e.g. "ends in 5 days"
+func endsIn(rt *models.RecurringTransaction, now time.Time) string {
+	switch days := rt.DaysUntilEnd(now); days {
+	case 0:
+		return "ends today"
+	case 1:
+		return "ends tomorrow"
+	default:
+		return fmt.Sprintf("ends in %d days", days)
+	}
+}
+
+// renderEnding warns about the active recurring transactions reaching their
+// end date soon, so income and expense projections don't drop unnoticed
+func (d *Dashboard) renderEnding() string {
+	if len(d.ending) == 0 {
+		return ""
+	}
+
+	now := time.Now()
+	listed := d.endingListed()
+	selected := d.selectedEnding()
+	var lines []string
+	for i, rt := range d.ending[:listed] {
+		line := fmt.Sprintf("⏳ Your %s recurring entry %s (%s)",
+			rt.Description, endsIn(rt, now), styles.FormatDate(*rt.EndDate))
+		// The cursor is only marked when there is a choice
+		if listed > 1 {
+			marker := "  "
+			if i == d.endingCursor {
+				marker = "▸ "
+			}
+			line = marker + line
+		}
+		lines = append(lines, styles.WarningStyle.Render(line))
+	}
+	if len(d.ending) > listed {
+		lines = append(lines, styles.HelpStyle.Render(fmt.Sprintf("   … and %d more in the recurring list", len(d.ending)-listed)))
+	}
+
+	if d.extending {
+		bar := styles.FormLabelStyle.Render("New end date:") + styles.FormInputFocusedStyle.Render(d.extendInput.View())
+		hint := styles.HelpStyle.Render(fmt.Sprintf("for %s · YYYY-MM-DD, or empty to keep it going · [enter]save  [esc]cancel", selected.Description))
+		if d.extendErr != nil {
+			hint = styles.ErrorStyle.Render(d.extendErr.Error()) + "\n" + hint
+		}
+		lines = append(lines, bar, hint)
+	} else {
+		keys := "[E]"
+		if listed > 1 {
+			keys = "[↑/↓] choose  [E]"
+		}
+		lines = append(lines, styles.HelpStyle.Render(fmt.Sprintf("   %s extend or keep %s going", keys, selected.Description)))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// endingListed returns how many of the recurring transactions ending soon
+// are listed
+func (d *Dashboard) endingListed() int {
+	return min(len(d.ending), endingListLimit)
+}
+
+// selectedEnding returns the listed recurring transaction under the cursor
+func (d *Dashboard) selectedEnding() *models.RecurringTransaction {
+	return d.ending[d.endingCursor]
+}
+
+// moveEndingCursor moves the cursor over the listed recurring transactions
+// ending soon with the up and down keys
+func (d *Dashboard) moveEndingCursor(key string) {
+	switch key {
+	case "up":
+		if d.endingCursor > 0 {
+			d.endingCursor--
+		}
+	case "down":
+		if d.endingCursor < d.endingListed()-1 {
+			d.endingCursor++
+		}
+	}
+}
+
+// openExtend asks for a new end date for the recurring transaction under
+// the cursor, a year after the current one unless changed
+func (d *Dashboard) openExtend() tea.Cmd {
+	d.extendInput = textinput.New()
+	d.extendInput.Placeholder = "YYYY-MM-DD"
+	d.extendInput.CharLimit = 10
+	d.extendInput.Width = 12
+	d.extendInput.SetValue(d.selectedEnding().EndDate.AddDate(1, 0, 0).Format("2006-01-02"))
+	d.extending = true
+	d.extendErr = nil
+	return d.extendInput.Focus()
+}
+
+// updateExtend handles a key while the new end date is being typed: enter
+// saves it, or removes the end date when left empty, and esc cancels
+func (d *Dashboard) updateExtend(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		d.extending = false
+		return nil
+	case "enter":
+		rt := d.selectedEnding()
+		var endDate *time.Time
+		if value := strings.TrimSpace(d.extendInput.Value()); value != "" {
+			date, err := time.ParseInLocation("2006-01-02", value, time.Local)
+			if err != nil {
+				d.extendErr = fmt.Errorf("invalid date %q, use YYYY-MM-DD", value)
+				return nil
+			}
+			endDate = &date
+		}
+		if err := d.recurringService.SetEndDate(context.Background(), rt.ID, endDate, time.Now()); err != nil {
+			d.extendErr = err
+			return nil
+		}
+
+		d.extending = false
+		status := fmt.Sprintf("%s now continues with no end date", rt.Description)
+		if endDate != nil {
+			status = fmt.Sprintf("%s now ends %s", rt.Description, styles.FormatDate(*endDate))
+		}
+		return tea.Batch(d.loadData, statusInfo(status))
+	}
+
+	var cmd tea.Cmd
+	d.extendInput, cmd = d.extendInput.Update(msg)
+	return cmd
+}
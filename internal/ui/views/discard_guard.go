@@ -0,0 +1,53 @@
+package views
+
+import "burnwise/internal/ui/styles"
+
+// discardGuard asks before a form is left with esc once something in it was
+// changed. V is the form's editable values, compared with those it was
+// opened with.
+type discardGuard[V comparable] struct {
+	initial    V
+	confirming bool
+}
+
+// reset takes values as the form's unchanged state
+func (g *discardGuard[V]) reset(values V) {
+	g.initial = values
+	g.confirming = false
+}
+
+// isDirty reports whether values differ from those the form was opened with
+func (g *discardGuard[V]) isDirty(values V) bool {
+	return values != g.initial
+}
+
+// canLeave reports whether esc may leave the form with values, otherwise
+// asking whether to discard them
+func (g *discardGuard[V]) canLeave(values V) bool {
+	if g.isDirty(values) {
+		g.confirming = true
+		return false
+	}
+	return true
+}
+
+// answer handles a key while asking whether to discard the changes,
+// reporting whether the form is to be left
+func (g *discardGuard[V]) answer(key string) bool {
+	switch key {
+	case "y", "Y":
+		g.confirming = false
+		return true
+	case "n", "N", "esc":
+		g.confirming = false
+	}
+	return false
+}
+
+// view returns the question while it is asked
+func (g *discardGuard[V]) view() string {
+	if !g.confirming {
+		return ""
+	}
+	return styles.WarningStyle.Render("Discard changes? (y/n)")
+}
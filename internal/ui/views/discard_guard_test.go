@@ -0,0 +1,28 @@
+package views
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDiscardGuard(t *testing.T) {
+	var guard discardGuard[string]
+	guard.reset("Food")
+
+	// Unchanged, esc leaves straight away
+	assert.True(t, guard.canLeave("Food"))
+	assert.Empty(t, guard.view())
+
+	// Changed, it asks first, and "n" keeps the form open
+	assert.False(t, guard.canLeave("Groceries"))
+	assert.Contains(t, guard.view(), "Discard changes?")
+	assert.False(t, guard.answer("x"))
+	assert.True(t, guard.confirming)
+	assert.False(t, guard.answer("n"))
+	assert.False(t, guard.confirming)
+
+	assert.False(t, guard.canLeave("Groceries"))
+	assert.True(t, guard.answer("y"))
+	assert.Empty(t, guard.view())
+}
@@ -0,0 +1,146 @@
+package views
+
+import (
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/models"
+	"burnwise/internal/ui/styles"
+)
+
+// pickerIcons are the icons offered by IconPicker, covering the default
+// categories and the usual income and spending
+var pickerIcons = []string{
+	"📁", "💼", "💻", "📈", "💰", "🏦", "💳", "💸", "🪙", "🧾",
+	"🏠", "💡", "🛒", "🍽️", "🍔", "☕", "🚗", "⛽", "🚌", "✈️",
+	"🏖️", "🏥", "💊", "🏋️", "👕", "💇", "🎁", "🎬", "🎮", "🎵",
+	"📚", "🎓", "👶", "🐶", "📱", "🌐", "☁️", "🤖", "🔧", "👤",
+	"🛡️", "📊",
+}
+
+// iconPickerVisible is how many icons the row shows at once
+const iconPickerVisible = 12
+
+// IconPicker picks an icon from a row of common ones with ←/→, scrolling
+// as needed. Past the last icon, "other" takes any icon as free text. The
+// value is a plain string either way.
+type IconPicker struct {
+	index  int // into pickerIcons; len(pickerIcons) is "other"
+	custom textinput.Model
+}
+
+// NewIconPicker selects value in the row, or under "other" when it isn't
+// one of the offered icons
+func NewIconPicker(value string) IconPicker {
+	custom := textinput.New()
+	custom.Placeholder = "any emoji"
+	custom.CharLimit = models.MaxIconRunes
+	custom.Width = 10
+
+	p := IconPicker{index: len(pickerIcons), custom: custom}
+	for i, icon := range pickerIcons {
+		if icon == value {
+			p.index = i
+			return p
+		}
+	}
+	p.custom.SetValue(value)
+	return p
+}
+
+func (p IconPicker) isOther() bool {
+	return p.index == len(pickerIcons)
+}
+
+// Value is the selected icon, or the text typed under "other"
+func (p IconPicker) Value() string {
+	if p.isOther() {
+		return strings.TrimSpace(p.custom.Value())
+	}
+	return pickerIcons[p.index]
+}
+
+// Focus and Blur show and hide the cursor of the "other" text input
+func (p *IconPicker) Focus() tea.Cmd {
+	return p.custom.Focus()
+}
+
+func (p *IconPicker) Blur() {
+	p.custom.Blur()
+}
+
+// Update moves through the row with ←/→. Under "other" the keys go to the
+// text input, except ← at the start of the text, which goes back to the row.
+func (p IconPicker) Update(msg tea.Msg) (IconPicker, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "left":
+			if !p.isOther() || p.custom.Position() == 0 {
+				if p.index > 0 {
+					p.index--
+				}
+				return p, nil
+			}
+		case "right":
+			if !p.isOther() {
+				p.index++
+				return p, nil
+			}
+		}
+	}
+
+	if !p.isOther() {
+		return p, nil
+	}
+	var cmd tea.Cmd
+	p.custom, cmd = p.custom.Update(msg)
+	return p, cmd
+}
+
+// View renders the visible part of the row around the selection, with
+// arrows where it scrolls, followed by "other"
+func (p IconPicker) View(focused bool) string {
+	start := 0
+	if p.index >= iconPickerVisible {
+		start = p.index - iconPickerVisible + 1
+	}
+	end := start + iconPickerVisible
+	if end > len(pickerIcons) {
+		end = len(pickerIcons)
+	}
+
+	muted := lipgloss.NewStyle().Foreground(styles.Muted)
+	var b strings.Builder
+	if start > 0 {
+		b.WriteString(muted.Render("‹ "))
+	} else {
+		b.WriteString("  ")
+	}
+	for i := start; i < end; i++ {
+		if i == p.index {
+			b.WriteString(p.highlight("["+pickerIcons[i]+"]", focused))
+		} else {
+			b.WriteString(" " + pickerIcons[i] + " ")
+		}
+	}
+	if end < len(pickerIcons) {
+		b.WriteString(muted.Render(" ›"))
+	}
+
+	other := " other "
+	if p.isOther() {
+		other = p.highlight("[other: ", focused) + p.custom.View() + p.highlight("]", focused)
+	}
+	b.WriteString(" " + other)
+	return b.String()
+}
+
+func (p IconPicker) highlight(s string, focused bool) string {
+	if focused {
+		return styles.SelectedStyle.Render(s)
+	}
+	return lipgloss.NewStyle().Bold(true).Render(s)
+}
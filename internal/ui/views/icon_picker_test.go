@@ -0,0 +1,53 @@
+package views
+
+import (
+	"testing"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestIconPicker(t *testing.T) {
+	press := func(p IconPicker, keys ...tea.KeyMsg) IconPicker {
+		for _, key := range keys {
+			p, _ = p.Update(key)
+		}
+		return p
+	}
+	left := tea.KeyMsg{Type: tea.KeyLeft}
+	right := tea.KeyMsg{Type: tea.KeyRight}
+
+	// An offered icon is selected in the row, anything else under "other"
+	assert.Equal(t, "☕", NewIconPicker("☕").Value())
+	assert.False(t, NewIconPicker("☕").isOther())
+	assert.True(t, NewIconPicker("🦄").isOther())
+	assert.Equal(t, "🦄", NewIconPicker("🦄").Value())
+
+	// ←/→ move along the row, stopping at its start
+	p := NewIconPicker(pickerIcons[0])
+	p = press(p, left)
+	assert.Equal(t, pickerIcons[0], p.Value())
+	p = press(p, right, right)
+	assert.Equal(t, pickerIcons[2], p.Value())
+
+	// The row scrolls to keep the selection in view
+	assert.NotContains(t, p.View(false), "‹")
+	p = NewIconPicker(pickerIcons[len(pickerIcons)-1])
+	assert.Contains(t, p.View(false), "‹")
+	assert.Contains(t, p.View(false), "["+pickerIcons[len(pickerIcons)-1]+"]")
+
+	// Past the last icon is "other", which takes typed text
+	p = press(p, right)
+	assert.True(t, p.isOther())
+	assert.Empty(t, p.Value())
+	p.Focus()
+	p = press(p, right, tea.KeyMsg{Type: tea.KeyRunes, Runes: []rune(" 🦄 ")})
+	assert.True(t, p.isOther())
+	assert.Equal(t, "🦄", p.Value())
+
+	// ← only leaves "other" from the start of the text
+	p = press(p, left)
+	assert.True(t, p.isOther())
+	p = press(p, tea.KeyMsg{Type: tea.KeyHome}, left)
+	assert.Equal(t, pickerIcons[len(pickerIcons)-1], p.Value())
+}
@@ -1,7 +1,10 @@
 package views
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"math"
 	"strconv"
 	"strings"
 	"time"
@@ -26,6 +29,7 @@ type RecurringFormModel struct {
 	frequencyValueInput textinput.Model
 	startDateInput     textinput.Model
 	endDateInput       textinput.Model
+	increaseInput      textinput.Model
 	
 	// Selections
 	typeSelected       models.TransactionType
@@ -35,10 +39,40 @@ type RecurringFormModel struct {
 	categories         []*models.Category
 	currencies         []string
 	
+	// The currency used when the category has no default of its own, and
+	// whether the currency was picked by hand so categories leave it alone
+	defaultCurrency    string
+	currencyChanged    bool
+	
 	focusIndex int
 	completed  bool
 	cancelled  bool
 	errorMsg   string
+
+	// Set after creating a rule that starts today or earlier, while asking
+	// whether to generate its past occurrences
+	confirmingBackfill bool
+	backfillCount      int
+
+	discard discardGuard[recurringFormValues]
+
+	// Set while asking whether to save a rule that looks like this one
+	duplicateOf *models.RecurringTransaction
+}
+
+// recurringFormValues are the form's editable values, compared with those
+// it was opened with to tell whether esc would lose anything
+type recurringFormValues struct {
+	description    string
+	amount         string
+	frequencyValue string
+	startDate      string
+	endDate        string
+	increase       string
+	txType         models.TransactionType
+	categoryID     uint
+	currency       string
+	frequency      models.RecurrenceFrequency
 }
 
 func (m *RecurringFormModel) IsCompleted() bool {
@@ -76,11 +110,11 @@ func NewRecurringFormModel(
 	descriptionInput.SetValue(recurring.Description)
 
 	amountInput := textinput.New()
-	amountInput.Placeholder = "0.00"
-	amountInput.CharLimit = 15
+	amountInput.Placeholder = "0.00 or 84.60 / 3"
+	amountInput.CharLimit = 40
 	amountInput.Width = 20
 	if recurring.Amount > 0 {
-		amountInput.SetValue(fmt.Sprintf("%.2f", recurring.Amount))
+		amountInput.SetValue(fmt.Sprintf("%.*f", models.CurrencyDecimals(recurring.Currency), recurring.Amount))
 	}
 
 	frequencyValueInput := textinput.New()
@@ -103,10 +137,18 @@ func NewRecurringFormModel(
 		endDateInput.SetValue(recurring.EndDate.Format("2006-01-02"))
 	}
 
+	increaseInput := textinput.New()
+	increaseInput.Placeholder = "0 (optional)"
+	increaseInput.CharLimit = 6
+	increaseInput.Width = 15
+	if recurring.AnnualIncreasePercent > 0 {
+		increaseInput.SetValue(strconv.FormatFloat(recurring.AnnualIncreasePercent, 'f', -1, 64))
+	}
+
 	// Default currencies - in real app, this would come from settings
 	currencies := []string{"USD", "EUR", "AED"}
 
-	return &RecurringFormModel{
+	m := &RecurringFormModel{
 		recurringService:    recurringService,
 		categoryService:     categoryService,
 		recurring:           recurring,
@@ -116,11 +158,31 @@ func NewRecurringFormModel(
 		frequencyValueInput: frequencyValueInput,
 		startDateInput:      startDateInput,
 		endDateInput:        endDateInput,
+		increaseInput:       increaseInput,
 		typeSelected:        recurring.Type,
 		categorySelected:    recurring.CategoryID,
 		currencySelected:    recurring.Currency,
 		frequencySelected:   recurring.Frequency,
 		currencies:          currencies,
+		defaultCurrency:     recurring.Currency,
+		currencyChanged:     isEditing,
+	}
+	m.discard.reset(m.values())
+	return m
+}
+
+func (m *RecurringFormModel) values() recurringFormValues {
+	return recurringFormValues{
+		description:    m.descriptionInput.Value(),
+		amount:         m.amountInput.Value(),
+		frequencyValue: m.frequencyValueInput.Value(),
+		startDate:      m.startDateInput.Value(),
+		endDate:        m.endDateInput.Value(),
+		increase:       m.increaseInput.Value(),
+		txType:         m.typeSelected,
+		categoryID:     m.categorySelected,
+		currency:       m.currencySelected,
+		frequency:      m.frequencySelected,
 	}
 }
 
@@ -143,20 +205,67 @@ func (m *RecurringFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					break
 				}
 			}
+			m.applyCategoryCurrency()
+			// The default category, and its currency, are not a change
+			m.discard.initial.categoryID = m.categorySelected
+			m.discard.initial.currency = m.currencySelected
 		}
 		return m, nil
 		
 	case recurringFormSuccessMsg:
 		m.completed = true
 		return m, nil
+
+	case recurringFormBackfillMsg:
+		m.confirmingBackfill = true
+		m.backfillCount = msg.count
+		m.errorMsg = ""
+		return m, nil
 		
 	case recurringFormErrorMsg:
 		m.errorMsg = msg.error.Error()
 		return m, nil
-		
+
+	case recurringFormDuplicateMsg:
+		m.duplicateOf = msg.existing
+		m.errorMsg = ""
+		return m, nil
+
 	case tea.KeyMsg:
+		// The rule is already saved; only answer the backfill question
+		if m.confirmingBackfill {
+			switch msg.String() {
+			case "y", "Y":
+				return m, m.backfill(true)
+			case "n", "N", "esc":
+				return m, m.backfill(false)
+			}
+			return m, nil
+		}
+
+		if m.duplicateOf != nil {
+			switch msg.String() {
+			case "y", "Y":
+				m.duplicateOf = nil
+				return m, m.submit(true)
+			case "n", "N", "esc":
+				m.duplicateOf = nil
+			}
+			return m, nil
+		}
+
+		if m.discard.confirming {
+			if m.discard.answer(msg.String()) {
+				m.cancelled = true
+			}
+			return m, nil
+		}
+
 		switch msg.String() {
 		case "esc":
+			if !m.discard.canLeave(m.values()) {
+				return m, nil
+			}
 			m.cancelled = true
 			return m, nil
 			
@@ -212,20 +321,24 @@ func (m *RecurringFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "j", "down":
 			if m.focusIndex == 3 {
 				m.nextCategory()
+				m.applyCategoryCurrency()
 			}
 		case "k", "up":
 			if m.focusIndex == 3 {
 				m.prevCategory()
+				m.applyCategoryCurrency()
 			}
 			
 		// Currency navigation
 		case "left":
 			if m.focusIndex == 4 {
 				m.prevCurrency()
+				m.currencyChanged = true
 			}
 		case "right":
 			if m.focusIndex == 4 {
 				m.nextCurrency()
+				m.currencyChanged = true
 			}
 		}
 	}
@@ -243,6 +356,8 @@ func (m *RecurringFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.startDateInput, cmd = m.startDateInput.Update(msg)
 	case 8:
 		m.endDateInput, cmd = m.endDateInput.Update(msg)
+	case 9:
+		m.increaseInput, cmd = m.increaseInput.Update(msg)
 	}
 
 	return m, cmd
@@ -350,6 +465,13 @@ func (m *RecurringFormModel) View() string {
 	b.WriteString(m.renderField("End Date:", m.endDateInput.View(), 8))
 	b.WriteString("\n")
 
+	// Annual increase
+	b.WriteString(m.renderField("Annual Increase (%):", m.increaseInput.View(), 9))
+	if m.focusIndex == 9 {
+		b.WriteString("\n  " + styles.HelpStyle.Render("Raises the amount on each anniversary of the start date"))
+	}
+	b.WriteString("\n")
+
 	// Action buttons
 	b.WriteString("\n")
 	if m.focusIndex == 10 {
@@ -364,12 +486,30 @@ func (m *RecurringFormModel) View() string {
 		b.WriteString(styles.ButtonStyle.Render("[ Cancel ]"))
 	}
 
+	// Backfill confirmation
+	if m.confirmingBackfill {
+		b.WriteString("\n\n")
+		b.WriteString(styles.WarningStyle.Render(fmt.Sprintf("Generate %d past occurrence(s) now? [y/n]", m.backfillCount)))
+		b.WriteString("\n")
+		b.WriteString(styles.HelpStyle.Render("Answering no moves the next due date past today, so nothing is backfilled later"))
+	}
+
 	// Error message
 	if m.errorMsg != "" {
 		b.WriteString("\n\n")
 		b.WriteString(styles.ErrorStyle.Render("❌ " + m.errorMsg))
 	}
 
+	if m.duplicateOf != nil {
+		b.WriteString("\n\n")
+		b.WriteString(styles.WarningStyle.Render(fmt.Sprintf("Possible duplicate of %s — save anyway? (y/n)", m.duplicateOf.Description)))
+	}
+
+	if discard := m.discard.view(); discard != "" {
+		b.WriteString("\n\n")
+		b.WriteString(discard)
+	}
+
 	// Help
 	b.WriteString("\n\n")
 	b.WriteString(styles.HelpStyle.Render("Tab: next field • Shift+Tab: prev field • Ctrl+S: save • Esc: cancel"))
@@ -405,11 +545,17 @@ func (m *RecurringFormModel) prevField() {
 }
 
 func (m *RecurringFormModel) updateFocus() {
+	// Show what an expression typed into the amount comes to once it is left
+	if m.focusIndex != 2 {
+		resolveAmount(&m.amountInput, models.CurrencyDecimals(m.currencySelected))
+	}
+	
 	m.descriptionInput.Blur()
 	m.amountInput.Blur()
 	m.frequencyValueInput.Blur()
 	m.startDateInput.Blur()
 	m.endDateInput.Blur()
+	m.increaseInput.Blur()
 
 	switch m.focusIndex {
 	case 0:
@@ -422,6 +568,8 @@ func (m *RecurringFormModel) updateFocus() {
 		m.startDateInput.Focus()
 	case 8:
 		m.endDateInput.Focus()
+	case 9:
+		m.increaseInput.Focus()
 	}
 }
 
@@ -434,6 +582,32 @@ func (m *RecurringFormModel) updateCategoriesForType() {
 			break
 		}
 	}
+	m.applyCategoryCurrency()
+}
+
+// applyCategoryCurrency switches the currency to the selected category's
+// default currency, or back to the form's default when it has none, unless
+// the currency was picked by hand
+func (m *RecurringFormModel) applyCategoryCurrency() {
+	if m.currencyChanged {
+		return
+	}
+	m.currencySelected = m.defaultCurrency
+	for _, cat := range m.categories {
+		if cat.ID != m.categorySelected || cat.DefaultCurrency == "" {
+			continue
+		}
+		// Categories only take enabled currencies, which may be missing
+		// from the short list offered here
+		listed := false
+		for _, curr := range m.currencies {
+			listed = listed || curr == cat.DefaultCurrency
+		}
+		if !listed {
+			m.currencies = append(m.currencies, cat.DefaultCurrency)
+		}
+		m.currencySelected = cat.DefaultCurrency
+	}
 }
 
 func (m *RecurringFormModel) nextCategory() {
@@ -523,6 +697,12 @@ func (m *RecurringFormModel) getFrequencyUnit() string {
 }
 
 func (m *RecurringFormModel) save() tea.Cmd {
+	return m.submit(false)
+}
+
+// submit saves the form. A new rule that looks like an existing one is only
+// saved with allowDuplicate, after the user has confirmed it.
+func (m *RecurringFormModel) submit(allowDuplicate bool) tea.Cmd {
 	return func() tea.Msg {
 		// Validate inputs
 		description := strings.TrimSpace(m.descriptionInput.Value())
@@ -530,11 +710,13 @@ func (m *RecurringFormModel) save() tea.Cmd {
 			return recurringFormErrorMsg{error: fmt.Errorf("description is required")}
 		}
 
-		amountStr := strings.TrimSpace(m.amountInput.Value())
-		amount, err := strconv.ParseFloat(amountStr, 64)
-		if err != nil || amount <= 0 {
-			return recurringFormErrorMsg{error: fmt.Errorf("valid amount is required")}
+		amount, err := service.ParseAmount(m.amountInput.Value())
+		if err != nil {
+			return recurringFormErrorMsg{error: fmt.Errorf("invalid amount: %w", err)}
 		}
+		// Expressions like "10 / 3" are rounded to the currency's decimals
+		scale := math.Pow10(models.CurrencyDecimals(m.currencySelected))
+		amount = math.Round(amount*scale) / scale
 
 		if m.categorySelected == 0 {
 			return recurringFormErrorMsg{error: fmt.Errorf("category is required")}
@@ -563,6 +745,14 @@ func (m *RecurringFormModel) save() tea.Cmd {
 			endDate = &ed
 		}
 
+		var increase float64
+		if increaseStr := strings.TrimSpace(m.increaseInput.Value()); increaseStr != "" {
+			increase, err = strconv.ParseFloat(strings.TrimSuffix(increaseStr, "%"), 64)
+			if err != nil || increase < 0 || increase > models.MaxAnnualIncreasePercent {
+				return recurringFormErrorMsg{error: fmt.Errorf("annual increase must be a percentage from 0 to %d", models.MaxAnnualIncreasePercent)}
+			}
+		}
+
 		// Update recurring transaction
 		m.recurring.Type = m.typeSelected
 		m.recurring.Amount = amount
@@ -573,26 +763,67 @@ func (m *RecurringFormModel) save() tea.Cmd {
 		m.recurring.FrequencyValue = freqValue
 		m.recurring.StartDate = startDate
 		m.recurring.EndDate = endDate
+		m.recurring.AnnualIncreasePercent = increase
 
 		var err2 error
 		if m.isEditing {
-			err2 = m.recurringService.Update(m.recurring)
+			err2 = m.recurringService.Update(context.Background(), m.recurring)
+		} else if allowDuplicate {
+			err2 = m.recurringService.CreateDuplicate(context.Background(), m.recurring)
 		} else {
-			err2 = m.recurringService.Create(m.recurring)
+			err2 = m.recurringService.Create(context.Background(), m.recurring)
 		}
 
+		var duplicate *service.DuplicateRecurringError
+		if errors.As(err2, &duplicate) {
+			return recurringFormDuplicateMsg{existing: duplicate.Existing}
+		}
 		if err2 != nil {
 			return recurringFormErrorMsg{error: err2}
 		}
 
+		// A new rule starting today or earlier already has occurrences due
+		if !m.isEditing && !startDate.After(endOfToday()) {
+			count, err := m.recurringService.CountDueOccurrences(context.Background(), m.recurring.ID, endOfToday())
+			if err != nil {
+				return recurringFormErrorMsg{error: err}
+			}
+			if count > 0 {
+				return recurringFormBackfillMsg{count: count}
+			}
+		}
+
+		return recurringFormSuccessMsg{}
+	}
+}
+
+// backfill either generates the new rule's past occurrences or moves its
+// next due date past today so they are never generated
+func (m *RecurringFormModel) backfill(generate bool) tea.Cmd {
+	return func() tea.Msg {
+		var err error
+		if generate {
+			_, err = m.recurringService.ProcessOne(context.Background(), m.recurring.ID, endOfToday())
+		} else {
+			err = m.recurringService.SkipPastOccurrences(context.Background(), m.recurring.ID, endOfToday())
+		}
+		if err != nil {
+			return recurringFormErrorMsg{error: err}
+		}
 		return recurringFormSuccessMsg{}
 	}
 }
 
+// endOfToday returns the last instant of the current local day
+func endOfToday() time.Time {
+	now := time.Now()
+	return time.Date(now.Year(), now.Month(), now.Day(), 23, 59, 59, 0, now.Location())
+}
+
 // Commands
 func (m *RecurringFormModel) loadCategories() tea.Cmd {
 	return func() tea.Msg {
-		categories, err := m.categoryService.GetAll()
+		categories, err := m.categoryService.GetPickable(context.Background(), "", m.categorySelected)
 		if err != nil {
 			return recurringFormErrorMsg{error: err}
 		}
@@ -602,9 +833,18 @@ func (m *RecurringFormModel) loadCategories() tea.Cmd {
 
 // Messages
 type recurringFormSuccessMsg struct{}
+type recurringFormBackfillMsg struct {
+	count int
+}
 type recurringFormErrorMsg struct {
 	error error
 }
+type recurringFormDuplicateMsg struct {
+	existing *models.RecurringTransaction
+}
 type categoriesLoadedForRecurringMsg struct {
 	categories []*models.Category
-}
\ No newline at end of file
+}
+
+// SetSize is a no-op; the form keeps its layout whatever the window size
+func (m *RecurringFormModel) SetSize(width, height int) {}
@@ -1,7 +1,9 @@
 package views
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
 
@@ -23,24 +25,34 @@ const (
 	recurringListModeCreate
 	recurringListModeConfirmDelete
 	recurringListModeConfirmPause
+	recurringListModeHistory
 )
 
+// recurringHistoryLimit caps how many generated transactions the history shows
+const recurringHistoryLimit = 15
+
 type RecurringListModel struct {
 	recurringService *service.RecurringTransactionService
 	categoryService  *service.CategoryService
 	list             list.Model
 	recurringItems   []*models.RecurringTransaction
+	generatedCounts  map[uint]int64
+	// monthlyUSD is what each item costs or pays per month in USD,
+	// converted when the list loads rather than while rendering
+	monthlyUSD       map[uint]float64
 	mode             recurringListMode
 	selectedItem     *recurringItem
 	editForm         *RecurringFormModel
 	createForm       *RecurringFormModel
 	confirmMsg       string
-	errorMsg         string
-	successMsg       string
+	history          *recurringHistoryMsg
+	groupByCategory  bool
+	marked           map[uint]bool // selected with space for a bulk delete or pause
 }
 
 type recurringItem struct {
 	recurring *models.RecurringTransaction
+	generated int64
 }
 
 func (i recurringItem) Title() string {
@@ -61,11 +73,25 @@ func (i recurringItem) Title() string {
 
 func (i recurringItem) Description() string {
 	typeStr := string(i.recurring.Type)
-	amountStr := fmt.Sprintf("%s %.2f", i.recurring.Currency, i.recurring.Amount)
+	amountStr := fmt.Sprintf("%s %s", i.recurring.Currency, styles.FormatNumberIn(i.recurring.Amount, i.recurring.Currency))
+	if i.recurring.AnnualIncreasePercent > 0 {
+		amountStr += fmt.Sprintf(" (+%g%%/yr)", i.recurring.AnnualIncreasePercent)
+	}
 	freqStr := i.recurring.GetFrequencyDisplay()
 	nextDue := i.recurring.NextDueDate.Format("Jan 2, 2006")
 	
-	return fmt.Sprintf("%s · %s · %s · Next: %s", typeStr, amountStr, freqStr, nextDue)
+	desc := fmt.Sprintf("%s · %s · %s · Next: %s", typeStr, amountStr, freqStr, nextDue)
+	if i.generated > 0 {
+		desc += fmt.Sprintf(" · %d generated", i.generated)
+	}
+	if i.recurring.LastProcessed != nil {
+		layout := "Jan 2"
+		if i.recurring.LastProcessed.Year() != time.Now().Year() {
+			layout = "Jan 2, 2006"
+		}
+		desc += " · last: " + i.recurring.LastProcessed.Format(layout)
+	}
+	return desc
 }
 
 func (i recurringItem) FilterValue() string {
@@ -95,9 +121,11 @@ func NewRecurringListModel(
 		return []key.Binding{
 			key.NewBinding(key.WithKeys("n"), key.WithHelp("n", "new")),
 			key.NewBinding(key.WithKeys("e"), key.WithHelp("e", "edit")),
+			key.NewBinding(key.WithKeys(" "), key.WithHelp("space", "select")),
 			key.NewBinding(key.WithKeys("p"), key.WithHelp("p", "pause/resume")),
 			key.NewBinding(key.WithKeys("d"), key.WithHelp("d", "delete")),
 			key.NewBinding(key.WithKeys("v"), key.WithHelp("v", "view history")),
+			key.NewBinding(key.WithKeys("g"), key.WithHelp("g", "group by")),
 			key.NewBinding(key.WithKeys("esc"), key.WithHelp("esc", "back")),
 		}
 	}
@@ -115,6 +143,12 @@ func (m *RecurringListModel) Init() tea.Cmd {
 }
 
 func (m *RecurringListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	// Resize the list even while a form is open, as the forms below
+	// consume every message they are given
+	if msg, ok := msg.(tea.WindowSizeMsg); ok {
+		m.SetSize(msg.Width, msg.Height)
+	}
+
 	// Handle mode-specific updates
 	switch m.mode {
 	case recurringListModeEdit:
@@ -124,8 +158,9 @@ func (m *RecurringListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			
 			if m.editForm.completed {
 				m.mode = recurringListModeView
-				m.successMsg = "Recurring transaction updated successfully"
-				return m, tea.Batch(m.loadRecurringTransactions(), m.clearMessages())
+				status := statusInfo(fmt.Sprintf("Recurring transaction updated · next due %s",
+					styles.FormatDate(m.editForm.recurring.NextDueDate)))
+				return m, tea.Batch(m.loadRecurringTransactions(), status)
 			} else if m.editForm.cancelled {
 				m.mode = recurringListModeView
 				m.editForm = nil
@@ -140,8 +175,7 @@ func (m *RecurringListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			
 			if m.createForm.completed {
 				m.mode = recurringListModeView
-				m.successMsg = "Recurring transaction created successfully"
-				return m, tea.Batch(m.loadRecurringTransactions(), m.clearMessages())
+				return m, tea.Batch(m.loadRecurringTransactions(), statusInfo("Recurring transaction created successfully"))
 			} else if m.createForm.cancelled {
 				m.mode = recurringListModeView
 				m.createForm = nil
@@ -154,17 +188,20 @@ func (m *RecurringListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case tea.KeyMsg:
 			switch msg.String() {
 			case "y", "Y":
-				if m.selectedItem != nil {
-					err := m.recurringService.Delete(m.selectedItem.recurring.ID)
+				var status tea.Cmd
+				if m.IsMarking() {
+					status = m.bulkDelete()
+				} else if m.selectedItem != nil {
+					err := m.recurringService.Delete(context.Background(), m.selectedItem.recurring.ID)
 					if err != nil {
-						m.errorMsg = err.Error()
+						status = statusError(err)
 					} else {
-						m.successMsg = "Recurring transaction deleted successfully"
+						status = statusInfo("Recurring transaction deleted successfully")
 					}
 				}
 				m.mode = recurringListModeView
 				m.confirmMsg = ""
-				return m, tea.Batch(m.loadRecurringTransactions(), m.clearMessages())
+				return m, tea.Batch(m.loadRecurringTransactions(), status)
 			case "n", "N", "esc":
 				m.mode = recurringListModeView
 				m.confirmMsg = ""
@@ -172,31 +209,40 @@ func (m *RecurringListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 		
+	case recurringListModeHistory:
+		if msg, ok := msg.(tea.KeyMsg); ok {
+			switch msg.String() {
+			case "esc", "v", "q", "backspace":
+				m.mode = recurringListModeView
+				m.history = nil
+			}
+			return m, nil
+		}
+		
 	case recurringListModeConfirmPause:
 		switch msg := msg.(type) {
 		case tea.KeyMsg:
 			switch msg.String() {
 			case "y", "Y":
-				if m.selectedItem != nil {
+				var status tea.Cmd
+				if m.IsMarking() {
+					status = m.bulkPause()
+				} else if m.selectedItem != nil {
 					var err error
 					if m.selectedItem.recurring.IsActive {
-						err = m.recurringService.Pause(m.selectedItem.recurring.ID)
-						if err == nil {
-							m.successMsg = "Recurring transaction paused"
-						}
+						err = m.recurringService.Pause(context.Background(), m.selectedItem.recurring.ID)
+						status = statusInfo("Recurring transaction paused")
 					} else {
-						err = m.recurringService.Resume(m.selectedItem.recurring.ID)
-						if err == nil {
-							m.successMsg = "Recurring transaction resumed"
-						}
+						err = m.recurringService.Resume(context.Background(), m.selectedItem.recurring.ID)
+						status = statusInfo("Recurring transaction resumed")
 					}
 					if err != nil {
-						m.errorMsg = err.Error()
+						status = statusError(err)
 					}
 				}
 				m.mode = recurringListModeView
 				m.confirmMsg = ""
-				return m, tea.Batch(m.loadRecurringTransactions(), m.clearMessages())
+				return m, tea.Batch(m.loadRecurringTransactions(), status)
 			case "n", "N", "esc":
 				m.mode = recurringListModeView
 				m.confirmMsg = ""
@@ -211,7 +257,11 @@ func (m *RecurringListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		if m.mode == recurringListModeView {
 			switch msg.String() {
 			case "esc", "q":
-				// Return to main menu
+				// Clear the selection first, then return to main menu
+				m.marked = nil
+				return m, nil
+			case " ":
+				m.toggleMark()
 				return m, nil
 			case "n":
 				// Create new recurring transaction
@@ -227,7 +277,12 @@ func (m *RecurringListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					return m, m.editForm.Init()
 				}
 			case "p":
-				// Pause/resume recurring transaction
+				// Pause the selected recurring transactions, or pause/resume
+				// the one under the cursor
+				if m.IsMarking() {
+					m.confirmBulkPause()
+					return m, nil
+				}
 				if item, ok := m.list.SelectedItem().(recurringItem); ok {
 					m.selectedItem = &item
 					if item.recurring.IsActive {
@@ -238,37 +293,42 @@ func (m *RecurringListModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 					m.mode = recurringListModeConfirmPause
 				}
 			case "d":
-				// Delete recurring transaction
+				// Delete the selected recurring transactions, or the one
+				// under the cursor
+				if m.IsMarking() {
+					return m, m.confirmBulkDelete()
+				}
 				if item, ok := m.list.SelectedItem().(recurringItem); ok {
 					m.selectedItem = &item
 					m.confirmMsg = fmt.Sprintf("Delete recurring transaction '%s'? (y/n)", item.recurring.Description)
 					m.mode = recurringListModeConfirmDelete
 				}
 			case "v":
-				// View transaction history (TODO: implement history view)
+				// View the transactions generated so far
 				if item, ok := m.list.SelectedItem().(recurringItem); ok {
-					m.errorMsg = fmt.Sprintf("History view not yet implemented for '%s'", item.recurring.Description)
-					return m, m.clearMessages()
+					return m, m.loadHistory(item.recurring)
 				}
+			case "g":
+				// Switch between grouping by frequency and by category
+				m.groupByCategory = !m.groupByCategory
+				m.setItems(m.recurringItems)
+				return m, nil
 			}
 		}
 	
 	case recurringLoadedMsg:
-		m.recurringItems = msg.items
-		items := make([]list.Item, len(m.recurringItems))
-		for i, rt := range m.recurringItems {
-			items[i] = recurringItem{recurring: rt}
-		}
-		m.list.SetItems(items)
+		m.generatedCounts = msg.generated
+		m.monthlyUSD = msg.monthlyUSD
+		m.setItems(msg.items)
 		return m, nil
 		
-	case clearMessagesMsg:
-		m.handleClearMessages()
-		return m, nil
+	case errMsg:
+		return m, statusError(msg.error)
 		
-	case tea.WindowSizeMsg:
-		h, v := styles.AppStyle.GetFrameSize()
-		m.list.SetSize(msg.Width-h, msg.Height-v-4)
+	case recurringHistoryMsg:
+		m.history = &msg
+		m.mode = recurringListModeHistory
+		return m, nil
 	}
 
 	var cmd tea.Cmd
@@ -283,19 +343,15 @@ func (m *RecurringListModel) View() string {
 	if m.mode == recurringListModeCreate && m.createForm != nil {
 		return m.createForm.View()
 	}
+	if m.mode == recurringListModeHistory && m.history != nil {
+		return m.renderHistory()
+	}
 	
 	var content strings.Builder
 	
 	// Custom grouped view
 	content.WriteString(m.renderGroupedView())
 	
-	// Show messages
-	if m.errorMsg != "" {
-		content.WriteString("\n" + styles.ErrorStyle.Render("❌ "+m.errorMsg))
-	}
-	if m.successMsg != "" {
-		content.WriteString("\n" + styles.SuccessStyle.Render("✅ "+m.successMsg))
-	}
 	if m.confirmMsg != "" {
 		content.WriteString("\n" + styles.WarningStyle.Render("⚠️  "+m.confirmMsg))
 	}
@@ -303,31 +359,152 @@ func (m *RecurringListModel) View() string {
 	return styles.AppStyle.Render(content.String())
 }
 
+// IsShowingHistory reports whether the history of a recurring transaction is
+// open, in which case esc closes it instead of leaving the view
+func (m *RecurringListModel) IsShowingHistory() bool {
+	return m.mode == recurringListModeHistory
+}
+
+// IsEditing reports whether the create or edit form is open, in which case
+// the form needs every key rather than the global shortcuts
+func (m *RecurringListModel) IsEditing() bool {
+	return m.mode == recurringListModeEdit || m.mode == recurringListModeCreate
+}
+
+// IsConfirming reports whether a delete or pause is waiting for y/n, in
+// which case those keys answer it rather than act as global shortcuts
+func (m *RecurringListModel) IsConfirming() bool {
+	return m.mode == recurringListModeConfirmDelete || m.mode == recurringListModeConfirmPause
+}
+
+func (m *RecurringListModel) renderHistory() string {
+	rt := m.history.recurring
+
+	var content strings.Builder
+	content.WriteString(styles.TitleStyle.Render("🔄 History: " + rt.Description))
+	content.WriteString("\n\n")
+
+	verb := "Paid"
+	if rt.Type == models.TransactionTypeIncome {
+		verb = "Received"
+	}
+	times := "times"
+	if m.history.count == 1 {
+		times = "time"
+	}
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render(
+		fmt.Sprintf("%s %d %s, $%s total", verb, m.history.count, times, styles.FormatNumber(m.history.total)),
+	))
+	content.WriteString("\n\n")
+
+	if prices := m.renderPriceHistory(); prices != "" {
+		content.WriteString(prices)
+		content.WriteString("\n\n")
+	}
+
+	if len(m.history.transactions) == 0 {
+		content.WriteString(styles.HelpStyle.Render("No transactions have been generated yet."))
+	}
+	for i, tx := range m.history.transactions {
+		if i >= recurringHistoryLimit {
+			content.WriteString(styles.HelpStyle.Render(
+				fmt.Sprintf("… and %d earlier", len(m.history.transactions)-recurringHistoryLimit),
+			))
+			content.WriteString("\n")
+			break
+		}
+		content.WriteString(fmt.Sprintf("%-12s %s %12s  %s\n",
+			styles.FormatDate(tx.Date), tx.Currency, styles.FormatNumberIn(tx.Amount, tx.Currency), tx.Description))
+	}
+
+	content.WriteString("\n")
+	content.WriteString(styles.HelpStyle.Render("esc/v: back to list"))
+
+	return styles.AppStyle.Render(content.String())
+}
+
+// renderPriceHistory shows how the amount changed over time, e.g.
+// "USD 9.99 → 12.99 → 15.49", with a line per change
+func (m *RecurringListModel) renderPriceHistory() string {
+	changes := m.history.priceHistory
+	if len(changes) == 0 {
+		return ""
+	}
+
+	prices := []string{styles.FormatNumberIn(changes[0].OldAmount, changes[0].Currency)}
+	for _, change := range changes {
+		prices = append(prices, styles.FormatNumberIn(change.NewAmount, change.Currency))
+	}
+
+	var content strings.Builder
+	content.WriteString(lipgloss.NewStyle().Bold(true).Render(
+		fmt.Sprintf("Price: %s %s", changes[0].Currency, strings.Join(prices, " → ")),
+	))
+	for _, change := range changes {
+		line := fmt.Sprintf("%-12s %s → %s", styles.FormatDate(change.ChangedAt),
+			styles.FormatNumberIn(change.OldAmount, change.Currency), styles.FormatNumberIn(change.NewAmount, change.Currency))
+		if change.OldAmount > 0 {
+			line += fmt.Sprintf(" (%+.1f%%)", (change.NewAmount-change.OldAmount)/change.OldAmount*100)
+		}
+		content.WriteString("\n")
+		content.WriteString(styles.HelpStyle.Render(line))
+	}
+	return content.String()
+}
+
 // Messages
 type recurringLoadedMsg struct {
-	items []*models.RecurringTransaction
+	items     []*models.RecurringTransaction
+	generated  map[uint]int64
+	monthlyUSD map[uint]float64
+}
+
+type recurringHistoryMsg struct {
+	recurring    *models.RecurringTransaction
+	transactions []*models.Transaction
+	total        float64
+	count        int
+	priceHistory []*models.RecurringTransactionPriceHistory
 }
 
 // Commands
 func (m *RecurringListModel) loadRecurringTransactions() tea.Cmd {
 	return func() tea.Msg {
-		items, err := m.recurringService.GetAll()
+		items, err := m.recurringService.GetAll(context.Background())
 		if err != nil {
 			return errMsg{err}
 		}
-		return recurringLoadedMsg{items: items}
+		generated, err := m.recurringService.GetGeneratedCounts(context.Background())
+		if err != nil {
+			return errMsg{err}
+		}
+		monthlyUSD := m.recurringService.GetMonthlyAmountsUSD(context.Background(), items)
+		return recurringLoadedMsg{items: items, generated: generated, monthlyUSD: monthlyUSD}
 	}
 }
 
-func (m *RecurringListModel) clearMessages() tea.Cmd {
-	return tea.Tick(styles.MessageTimeout, func(time.Time) tea.Msg {
-		return clearMessagesMsg{}
-	})
-}
-
-func (m *RecurringListModel) handleClearMessages() {
-	m.errorMsg = ""
-	m.successMsg = ""
+func (m *RecurringListModel) loadHistory(rt *models.RecurringTransaction) tea.Cmd {
+	return func() tea.Msg {
+		transactions, err := m.recurringService.GetGeneratedTransactions(context.Background(), rt.ID)
+		if err != nil {
+			return errMsg{err}
+		}
+		total, count, err := m.recurringService.GetTotalGenerated(context.Background(), rt.ID)
+		if err != nil {
+			return errMsg{err}
+		}
+		priceHistory, err := m.recurringService.GetPriceHistory(context.Background(), rt.ID)
+		if err != nil {
+			return errMsg{err}
+		}
+		return recurringHistoryMsg{
+			recurring:    rt,
+			transactions: transactions,
+			total:        total,
+			count:        count,
+			priceHistory: priceHistory,
+		}
+	}
 }
 
 func (m *RecurringListModel) renderGroupedView() string {
@@ -338,106 +515,181 @@ func (m *RecurringListModel) renderGroupedView() string {
 			Render("No recurring transactions found. Press 'n' to create one.")
 	}
 	
-	// Group items by frequency
-	groupedItems := m.groupByFrequency()
-	
 	var content strings.Builder
-	content.WriteString(styles.TitleStyle.Render("🔄 RECURRING EXPENSES"))
+	content.WriteString(styles.TitleStyle.Render("🔄 RECURRING TRANSACTIONS"))
 	content.WriteString("\n\n")
 	
-	// Calculate totals
-	monthlyTotal := 0.0
-	yearlyTotal := 0.0
+	// Render income and expenses as separate sections with their own totals
+	m.renderTypeSection(&content, models.TransactionTypeIncome, "INCOME", styles.IncomeStyle)
+	m.renderTypeSection(&content, models.TransactionTypeExpense, "EXPENSES", styles.ExpenseStyle)
+	totals := models.SumMonthlyAmounts(m.recurringItems, m.monthlyUSD)
+	incomeMonthly, expenseMonthly, netMonthly := totals.Income, totals.Expenses, totals.Net
 	
-	// Render each frequency group
-	for _, freq := range []models.RecurrenceFrequency{
-		models.FrequencyDaily,
-		models.FrequencyWeekly,
-		models.FrequencyMonthly,
-		models.FrequencyYearly,
-	} {
-		items, exists := groupedItems[freq]
-		if !exists || len(items) == 0 {
-			continue
-		}
-		
-		// Calculate group total in monthly terms
-		groupMonthlyTotal := 0.0
-		for _, item := range items {
-			if item.recurring.Type == models.TransactionTypeExpense && item.recurring.IsActive {
-				monthlyAmount := m.calculateMonthlyAmount(item.recurring)
-				groupMonthlyTotal += monthlyAmount
-			}
-		}
-		
-		// Format frequency header
-		freqDisplay := strings.ToUpper(string(freq))
-		totalDisplay := fmt.Sprintf("($%.2f/mo | $%.2f/yr)", groupMonthlyTotal, groupMonthlyTotal*12)
+	// Footer with totals
+	divider := strings.Repeat("━", 60)
+	content.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.Primary)).
+		Render(divider))
+	content.WriteString("\n")
+	
+	content.WriteString(styles.IncomeStyle.Render(fmt.Sprintf("Recurring Income:      +$%.2f/mo", incomeMonthly)))
+	content.WriteString("\n")
+	content.WriteString(styles.ExpenseStyle.Render(fmt.Sprintf("Total Monthly Burn:    -$%.2f/mo", expenseMonthly)))
+	content.WriteString("\n")
+	
+	netStyle := styles.BalanceStyle
+	if netMonthly < 0 {
+		netStyle = styles.ExpenseStyle
+	}
+	content.WriteString(netStyle.Render(fmt.Sprintf("Net Recurring Monthly: $%.2f", netMonthly)))
+	content.WriteString("\n")
+	
+	yearlyLine := fmt.Sprintf("Projected Yearly (net): $%.2f  (income $%.2f | expenses $%.2f)",
+		netMonthly*12, incomeMonthly*12, expenseMonthly*12)
+	content.WriteString(lipgloss.NewStyle().
+		Foreground(lipgloss.Color(styles.Muted)).
+		Render(yearlyLine))
+	content.WriteString("\n\n")
+	
+	// Help text
+	grouping := "category"
+	if m.groupByCategory {
+		grouping = "frequency"
+	}
+	help := fmt.Sprintf("[n]ew  [e]dit  [p]ause/resume  [d]elete  [space] select  [g]roup by %s  [esc] back", grouping)
+	if marked := len(m.markedIDs()); marked > 0 {
+		help = fmt.Sprintf("%d selected · [p]ause  [d]elete  [space] unselect  [esc] clear selection", marked)
+	}
+	content.WriteString(styles.HelpStyle.Render(help))
+	
+	return content.String()
+}
+
+// renderTypeSection renders all groups for one transaction type
+func (m *RecurringListModel) renderTypeSection(content *strings.Builder, txType models.TransactionType, title string, titleStyle lipgloss.Style) {
+	groups := m.groupItems(m.recurringItems, txType)
+	if len(groups) == 0 {
+		return
+	}
+	
+	content.WriteString(titleStyle.Render(title))
+	content.WriteString("\n")
+	
+	for _, group := range groups {
+		totalDisplay := fmt.Sprintf("($%.2f/mo | $%.2f/yr)", group.monthlyTotal, group.monthlyTotal*12)
 		
 		header := lipgloss.NewStyle().
 			Bold(true).
 			Foreground(lipgloss.Color(styles.Primary)).
-			Render(fmt.Sprintf("%s %s", freqDisplay, totalDisplay))
+			Render(fmt.Sprintf("%s %s", group.title, totalDisplay))
 		
 		content.WriteString(header)
 		content.WriteString("\n")
 		
 		// Render items in this group
-		for i, item := range items {
+		for i, rt := range group.items {
 			// Determine if this is the selected item
 			isSelected := false
 			if selectedItem, ok := m.list.SelectedItem().(recurringItem); ok {
-				isSelected = selectedItem.recurring.ID == item.recurring.ID
+				isSelected = selectedItem.recurring.ID == rt.ID
 			}
 			
-			itemStr := m.renderRecurringItem(item.recurring, isSelected)
+			itemStr := m.renderRecurringItem(rt, isSelected)
 			content.WriteString(itemStr)
-			if i < len(items)-1 {
+			if i < len(group.items)-1 {
 				content.WriteString("\n")
 			}
 		}
 		content.WriteString("\n\n")
-		
-		monthlyTotal += groupMonthlyTotal
 	}
+}
+
+// recurringGroup is one headed group of the list: a frequency, or a
+// category when grouping by category
+type recurringGroup struct {
+	title        string
+	items        []*models.RecurringTransaction
+	monthlyTotal float64 // in USD, paused items left out
+}
+
+// groupItems groups the transactions of one type by frequency or, with
+// groupByCategory set, by category with the most expensive first. Within a
+// category, items keep their frequency order.
+func (m *RecurringListModel) groupItems(items []*models.RecurringTransaction, txType models.TransactionType) []*recurringGroup {
+	var groups []*recurringGroup
+	byKey := make(map[string]*recurringGroup)
 	
-	yearlyTotal = monthlyTotal * 12
-	
-	// Footer with totals
-	divider := strings.Repeat("━", 60)
-	content.WriteString(lipgloss.NewStyle().
-		Foreground(lipgloss.Color(styles.Primary)).
-		Render(divider))
-	content.WriteString("\n")
-	
-	totalLine := fmt.Sprintf("Total Monthly Burn: $%.2f", monthlyTotal)
-	content.WriteString(lipgloss.NewStyle().
-		Bold(true).
-		Render(totalLine))
-	content.WriteString("\n")
-	
-	yearlyLine := fmt.Sprintf("Projected Yearly:   $%.2f", yearlyTotal)
-	content.WriteString(lipgloss.NewStyle().
-		Foreground(lipgloss.Color(styles.Muted)).
-		Render(yearlyLine))
-	content.WriteString("\n\n")
+	for _, freq := range models.GetAllFrequencies() {
+		for _, rt := range items {
+			if rt.Type != txType || rt.Frequency != freq {
+				continue
+			}
+			
+			key, title := string(freq), strings.ToUpper(string(freq))
+			if m.groupByCategory {
+				key = fmt.Sprintf("%d", rt.CategoryID)
+				title = strings.TrimSpace(rt.Category.Icon + " " + rt.Category.Name)
+			}
+			
+			group, ok := byKey[key]
+			if !ok {
+				group = &recurringGroup{title: title}
+				byKey[key] = group
+				groups = append(groups, group)
+			}
+			group.items = append(group.items, rt)
+			if rt.IsActive {
+				group.monthlyTotal += m.monthlyUSD[rt.ID]
+			}
+		}
+	}
 	
-	// Help text
-	help := "[n]ew  [e]dit  [p]ause/resume  [d]elete  [esc] back"
-	content.WriteString(styles.HelpStyle.Render(help))
+	if m.groupByCategory {
+		sort.SliceStable(groups, func(i, j int) bool {
+			if groups[i].monthlyTotal != groups[j].monthlyTotal {
+				return groups[i].monthlyTotal > groups[j].monthlyTotal
+			}
+			return groups[i].title < groups[j].title
+		})
+	}
 	
-	return content.String()
+	return groups
 }
 
-func (m *RecurringListModel) groupByFrequency() map[models.RecurrenceFrequency][]recurringItem {
-	grouped := make(map[models.RecurrenceFrequency][]recurringItem)
-	
-	for _, rt := range m.recurringItems {
-		item := recurringItem{recurring: rt}
-		grouped[rt.Frequency] = append(grouped[rt.Frequency], item)
+// orderForDisplay sorts recurring transactions the way the grouped view shows
+// them (income before expenses, then by group) so list navigation follows
+// the rendered order
+func (m *RecurringListModel) orderForDisplay(items []*models.RecurringTransaction) []*models.RecurringTransaction {
+	ordered := make([]*models.RecurringTransaction, 0, len(items))
+	for _, txType := range []models.TransactionType{models.TransactionTypeIncome, models.TransactionTypeExpense} {
+		for _, group := range m.groupItems(items, txType) {
+			ordered = append(ordered, group.items...)
+		}
+	}
+	return ordered
+}
+
+// setItems fills the list in display order, keeping the selection on the
+// same transaction when it is still there
+func (m *RecurringListModel) setItems(items []*models.RecurringTransaction) {
+	var selectedID uint
+	if item, ok := m.list.SelectedItem().(recurringItem); ok {
+		selectedID = item.recurring.ID
 	}
 	
-	return grouped
+	m.recurringItems = m.orderForDisplay(items)
+	listItems := make([]list.Item, len(m.recurringItems))
+	selected := -1
+	for i, rt := range m.recurringItems {
+		listItems[i] = recurringItem{recurring: rt, generated: m.generatedCounts[rt.ID]}
+		if rt.ID == selectedID {
+			selected = i
+		}
+	}
+	m.list.SetItems(listItems)
+	if selected >= 0 {
+		m.list.Select(selected)
+	}
 }
 
 func (m *RecurringListModel) renderRecurringItem(rt *models.RecurringTransaction, isSelected bool) string {
@@ -457,7 +709,11 @@ func (m *RecurringListModel) renderRecurringItem(rt *models.RecurringTransaction
 	name := fmt.Sprintf("%s%s%s", icon, rt.Description, status)
 	
 	// Amount and next due
-	amount := fmt.Sprintf("%s %.2f", rt.Currency, rt.Amount)
+	sign := "-"
+	if rt.Type == models.TransactionTypeIncome {
+		sign = "+"
+	}
+	amount := fmt.Sprintf("%s%s %s", sign, rt.Currency, styles.FormatNumberIn(rt.Amount, rt.Currency))
 	nextDue := rt.NextDueDate.Format("Jan 2")
 	
 	// Format the line
@@ -466,33 +722,35 @@ func (m *RecurringListModel) renderRecurringItem(rt *models.RecurringTransaction
 		name = name[:nameWidth-3] + "..."
 	}
 	
-	line := fmt.Sprintf("  %-*s  %10s  Next: %s", nameWidth, name, amount, nextDue)
+	mark := " "
+	if m.marked[rt.ID] {
+		mark = "●"
+	}
+	line := fmt.Sprintf(" %s%-*s  %11s  Next: %s", mark, nameWidth, name, amount, nextDue)
+	
+	// Paused items don't count towards any total, so show what they would
+	// cost and gray them out
+	if !rt.IsActive {
+		line += fmt.Sprintf("  ($%.2f/mo)", m.monthlyUSD[rt.ID])
+	}
 	
 	// Apply selection styling
 	if isSelected {
 		return lipgloss.NewStyle().
 			Foreground(lipgloss.Color(styles.PrimaryColor)).
 			Bold(true).
-			Render("→ " + line[2:])
+			Render("→" + line[1:])
+	}
+	if !rt.IsActive {
+		return lipgloss.NewStyle().Foreground(styles.Muted).Render(line)
 	}
 	
 	return line
 }
 
-func (m *RecurringListModel) calculateMonthlyAmount(rt *models.RecurringTransaction) float64 {
-	amount := rt.Amount
-	
-	// Convert to monthly based on frequency
-	switch rt.Frequency {
-	case models.FrequencyDaily:
-		return amount * 30.44 / float64(rt.FrequencyValue) // Average days per month
-	case models.FrequencyWeekly:
-		return amount * 4.33 / float64(rt.FrequencyValue) // Average weeks per month
-	case models.FrequencyMonthly:
-		return amount / float64(rt.FrequencyValue)
-	case models.FrequencyYearly:
-		return amount / (12 * float64(rt.FrequencyValue))
-	default:
-		return amount
-	}
-}
\ No newline at end of file
+// SetSize fits the list to the window, leaving room for the messages and
+// help below it
+func (m *RecurringListModel) SetSize(width, height int) {
+	h, v := styles.AppStyle.GetFrameSize()
+	m.list.SetSize(width-h, height-v-4)
+}
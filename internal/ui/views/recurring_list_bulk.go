@@ -0,0 +1,149 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"burnwise/internal/service"
+)
+
+// toggleMark selects or unselects the recurring transaction under the
+// cursor for a bulk delete or pause
+func (m *RecurringListModel) toggleMark() {
+	item, ok := m.list.SelectedItem().(recurringItem)
+	if !ok {
+		return
+	}
+	if m.marked == nil {
+		m.marked = make(map[uint]bool)
+	}
+	if m.marked[item.recurring.ID] {
+		delete(m.marked, item.recurring.ID)
+	} else {
+		m.marked[item.recurring.ID] = true
+	}
+}
+
+// IsMarking reports whether recurring transactions are selected, in which
+// case esc clears the selection instead of leaving the view
+func (m *RecurringListModel) IsMarking() bool {
+	return len(m.markedIDs()) > 0
+}
+
+// markedIDs returns the selected recurring transactions in display order,
+// leaving out any that are gone since
+func (m *RecurringListModel) markedIDs() []uint {
+	var ids []uint
+	for _, rt := range m.recurringItems {
+		if m.marked[rt.ID] {
+			ids = append(ids, rt.ID)
+		}
+	}
+	return ids
+}
+
+// confirmBulkDelete asks before deleting the selected recurring
+// transactions, saying which will only be deactivated
+func (m *RecurringListModel) confirmBulkDelete() tea.Cmd {
+	plan, err := m.recurringService.PlanDeleteMany(context.Background(), m.markedIDs())
+	if err != nil {
+		return statusError(err)
+	}
+	m.confirmMsg = fmt.Sprintf("Delete %d recurring transactions? %s (y/n)",
+		len(plan), deleteSummary(plan, true))
+	m.mode = recurringListModeConfirmDelete
+	return nil
+}
+
+// confirmBulkPause asks before pausing the selected recurring transactions
+func (m *RecurringListModel) confirmBulkPause() {
+	ids := m.markedIDs()
+	paused := 0
+	for _, rt := range m.recurringItems {
+		if m.marked[rt.ID] && !rt.IsActive {
+			paused++
+		}
+	}
+	m.confirmMsg = fmt.Sprintf("Pause %d recurring transactions?", len(ids))
+	if paused > 0 {
+		m.confirmMsg += fmt.Sprintf(" %d already paused will stay as %s.", paused, pluralVerb(paused, "it is", "they are"))
+	}
+	m.confirmMsg += " (y/n)"
+	m.mode = recurringListModeConfirmPause
+}
+
+// bulkDelete deletes the selected recurring transactions and reports what
+// happened to them
+func (m *RecurringListModel) bulkDelete() tea.Cmd {
+	results := m.recurringService.DeleteMany(context.Background(), m.markedIDs())
+	m.marked = nil
+	return bulkStatus(deleteSummary(results, false), results)
+}
+
+// bulkPause pauses the selected recurring transactions and reports what
+// happened to them
+func (m *RecurringListModel) bulkPause() tea.Cmd {
+	results := m.recurringService.PauseMany(context.Background(), m.markedIDs())
+	m.marked = nil
+
+	counts := service.CountOutcomes(results)
+	summary := fmt.Sprintf("%d paused", counts[service.BulkPaused])
+	if counts[service.BulkUnchanged] > 0 {
+		summary += fmt.Sprintf(", %d already paused", counts[service.BulkUnchanged])
+	}
+	return bulkStatus(summary, results)
+}
+
+// deleteSummary says how many recurring transactions are, or with future
+// set will be, deleted and how many deactivated, e.g. "3 will be deleted,
+// 2 will be deactivated because they have history"
+func deleteSummary(results []service.BulkResult, future bool) string {
+	counts := service.CountOutcomes(results)
+	verb := ""
+	if future {
+		verb = "will be "
+	}
+
+	var parts []string
+	if n := counts[service.BulkDeleted]; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d %sdeleted", n, verb))
+	}
+	if n := counts[service.BulkDeactivated]; n > 0 {
+		parts = append(parts, fmt.Sprintf("%d %sdeactivated because %s history", n, verb, pluralVerb(n, "it has", "they have")))
+	}
+	if len(parts) == 0 {
+		return "nothing deleted"
+	}
+	return strings.Join(parts, ", ")
+}
+
+// bulkStatus reports the summary of a bulk action, as an error naming the
+// first failure when some failed
+func bulkStatus(summary string, results []service.BulkResult) tea.Cmd {
+	var failed []service.BulkResult
+	for _, result := range results {
+		if result.Outcome == service.BulkFailed {
+			failed = append(failed, result)
+		}
+	}
+	if len(failed) == 0 {
+		return statusInfo(summary)
+	}
+
+	name := failed[0].Description
+	if name == "" {
+		name = fmt.Sprintf("#%d", failed[0].ID)
+	}
+	return statusError(fmt.Errorf("%s, %d failed ('%s': %w)", summary, len(failed), name, failed[0].Err))
+}
+
+// pluralVerb returns one for a count of 1 and many otherwise
+func pluralVerb(n int, one, many string) string {
+	if n == 1 {
+		return one
+	}
+	return many
+}
@@ -1,10 +1,15 @@
 package views
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"os"
+	"path/filepath"
 	"strings"
 	"time"
 
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
@@ -13,22 +18,86 @@ import (
 	"burnwise/internal/ui/styles"
 )
 
+// reportTopPlaces is how many descriptions "Top places you spend" lists
+const reportTopPlaces = 5
+
+// reportsSideBySideWidth is the narrowest terminal the two report columns
+// are shown side by side in
+const reportsSideBySideWidth = 100
+
 type Reports struct {
 	width           int
 	height          int
 	txService       *service.TransactionService
 	categoryService *service.CategoryService
 	budgetService   *service.BudgetService
+	accountService  *service.AccountService
+	exportService   *service.ExportService
+	exportDir       string
+	tagPattern      string
 	
 	monthSummary    *models.TransactionSummary
 	yearSummary     *models.TransactionSummary
 	categoryTotals  []*models.CategoryWithTotal
+	tagTotals       []*models.TagTotal
+	accountSpending []*models.AccountSummary
+	topPlaces       []*models.DescriptionStat
 	budgetStatuses  []*models.BudgetStatus
+	amountHistogram map[string]int
+	allocation      *models.AllocationSummary
 	
 	selectedMonth   time.Month
 	selectedYear    int
+	firstMonth      time.Time
+	lastMonth       time.Time
+	flash           string
+	showDetails     bool
+	placesOrder     models.DescriptionOrder
 	loading         bool
 	err             error
+	
+	// Calendar of the selected month's daily spending, toggled with 'v'.
+	// calendarDay is the day of the month under the cursor.
+	showCalendar    bool
+	calendarDay     int
+	dailyTotals     map[int]float64
+	
+	// Comparison of the selected month with the same month a year
+	// earlier, toggled with 'y' and only loaded while shown
+	showYearOverYear bool
+	yearOverYear     *models.YearOverYear
+	
+	// The selected year month by month, toggled with 'Y' and only loaded
+	// while shown
+	showYearlyReport bool
+	yearlyReport     *models.YearlyReport
+	
+	// Date range mode, set with 'd': the days from rangeStart to rangeEnd,
+	// inclusive, are shown instead of the selected month
+	rangeMode       bool
+	rangeStart      time.Time
+	rangeEnd        time.Time
+	pickingRange    bool
+	rangeInputs     [2]textinput.Model
+	rangeFocus      int
+	rangeErr        error
+	
+	// Export of the selected month: the path prompt, the overwrite
+	// confirmation and the outcome shown in the status line.
+	// cancelExport is set while the export runs; esc calls it.
+	exportPath       textinput.Model
+	exporting        bool
+	confirmOverwrite bool
+	cancelExport     context.CancelFunc
+	exportStatus     string
+	exportErr        error
+}
+
+type reportExportedMsg struct {
+	year  int
+	month time.Month
+	path  string
+	err   error
 }
 
 func NewReports(txService *service.TransactionService, categoryService *service.CategoryService, budgetService *service.BudgetService) *Reports {
@@ -42,6 +111,38 @@ func NewReports(txService *service.TransactionService, categoryService *service.
 	}
 }
 
+// SetExportService enables exporting the selected month with 'x'. The
+// suggested file path is in dir.
+func (r *Reports) SetExportService(exportService *service.ExportService, dir string) {
+	r.exportService = exportService
+	r.exportDir = dir
+}
+
+// SetAccountService enables the breakdown of spending by account, shown
+// once any account exists
+func (r *Reports) SetAccountService(accountService *service.AccountService) {
+	r.accountService = accountService
+}
+
+// SetTagPattern enables the tag breakdown, grouping expenses by the
+// pattern's match in their description
+func (r *Reports) SetTagPattern(pattern string) {
+	r.tagPattern = pattern
+}
+
+// IsExporting reports whether the export prompt is taking input or an
+// export is running, in which case keys should not be treated as navigation
+// shortcuts
+func (r *Reports) IsExporting() bool {
+	return r.exporting || r.cancelExport != nil
+}
+
+// IsShowingCalendar reports whether the daily spending calendar is open,
+// in which case it handles arrows and esc itself
+func (r *Reports) IsShowingCalendar() bool {
+	return r.showCalendar
+}
+
 func (r *Reports) Init() tea.Cmd {
 	r.loading = true
 	return r.loadReportData
@@ -53,21 +154,78 @@ func (r *Reports) Update(msg tea.Msg) (*Reports, tea.Cmd) {
 		r.SetSize(msg.Width, msg.Height)
 		
 	case tea.KeyMsg:
+		if r.cancelExport != nil {
+			// Only esc does anything until the export is done
+			if msg.String() == "esc" {
+				r.cancelExport()
+			}
+			return r, nil
+		}
+		if r.exporting {
+			return r, r.updateExportPrompt(msg)
+		}
+		if r.pickingRange {
+			return r, r.updateRangePicker(msg)
+		}
+		
+		if r.showCalendar {
+			if cmd, handled := r.updateCalendar(msg); handled {
+				return r, cmd
+			}
+		}
+		
 		switch msg.String() {
 		case "left":
-			r.selectedMonth--
-			if r.selectedMonth < 1 {
-				r.selectedMonth = 12
-				r.selectedYear--
+			if r.rangeMode {
+				return r, r.shiftRange(-1)
 			}
-			return r, r.loadReportData
+			return r, r.prevMonth()
 		case "right":
-			r.selectedMonth++
-			if r.selectedMonth > 12 {
-				r.selectedMonth = 1
-				r.selectedYear++
+			if r.rangeMode {
+				return r, r.shiftRange(1)
+			}
+			return r, r.nextMonth()
+		case "d":
+			return r, r.openRangePicker()
+		case "v":
+			if !r.rangeMode {
+				r.openCalendar()
+			}
+		case "t", "T":
+			return r, r.jumpToMonth(time.Now())
+		case "home":
+			return r, r.jumpToMonth(r.firstMonth)
+		case "end":
+			return r, r.jumpToMonth(r.lastMonth)
+		case "i":
+			r.showDetails = !r.showDetails
+		case "p":
+			if r.placesOrder == models.DescriptionsByCount {
+				r.placesOrder = models.DescriptionsByTotal
+			} else {
+				r.placesOrder = models.DescriptionsByCount
 			}
 			return r, r.loadReportData
+		case "y":
+			if !r.rangeMode {
+				r.showYearOverYear = !r.showYearOverYear
+				r.showYearlyReport = false
+				if r.showYearOverYear && r.yearOverYear == nil {
+					return r, r.loadYearOverYear
+				}
+			}
+		case "Y":
+			if !r.rangeMode {
+				r.showYearlyReport = !r.showYearlyReport
+				r.showYearOverYear = false
+				if r.showYearlyReport && r.yearlyReport == nil {
+					return r, r.loadYearlyReport
+				}
+			}
+		case "x":
+			if r.exportService != nil && !r.rangeMode {
+				return r, r.startExport()
+			}
 		}
 		
 	case reportDataMsg:
@@ -75,8 +233,55 @@ func (r *Reports) Update(msg tea.Msg) (*Reports, tea.Cmd) {
 		r.monthSummary = msg.monthSummary
 		r.yearSummary = msg.yearSummary
 		r.categoryTotals = msg.categoryTotals
+		r.tagTotals = msg.tagTotals
+		r.accountSpending = msg.accountSpending
+		r.topPlaces = msg.topPlaces
 		r.budgetStatuses = msg.budgetStatuses
+		r.amountHistogram = msg.amountHistogram
+		r.allocation = msg.allocation
+		r.firstMonth = msg.firstMonth
+		r.lastMonth = msg.lastMonth
+		r.dailyTotals = msg.dailyTotals
+		r.yearOverYear = nil
+		r.yearlyReport = nil
 		r.err = msg.err
+		r.clampCalendarDay()
+		if !r.rangeMode && r.err == nil {
+			switch {
+			case r.showYearOverYear:
+				return r, r.loadYearOverYear
+			case r.showYearlyReport:
+				return r, r.loadYearlyReport
+			}
+		}
+		
+	case yearOverYearMsg:
+		if msg.err != nil {
+			r.err = msg.err
+		} else if msg.year == r.selectedYear && msg.month == r.selectedMonth {
+			r.yearOverYear = msg.yearOverYear
+		}
+		
+	case yearlyReportMsg:
+		if msg.err != nil {
+			r.err = msg.err
+		} else if msg.report.Year == r.selectedYear {
+			r.yearlyReport = msg.report
+		}
+		
+	case reportExportedMsg:
+		r.cancelExport = nil
+		r.exportErr = msg.err
+		r.exportStatus = ""
+		if errors.Is(msg.err, context.Canceled) {
+			r.exportErr = nil
+			r.exportStatus = "Export cancelled"
+		} else if msg.err == nil {
+			r.exportStatus = fmt.Sprintf("Exported %s %d to %s", msg.month.String(), msg.year, msg.path)
+		}
+		
+	case clearMessagesMsg:
+		r.flash = ""
 	}
 	
 	return r, nil
@@ -95,28 +300,86 @@ func (r *Reports) View() string {
 	monthSummary := r.renderMonthSummary()
 	yearSummary := r.renderYearSummary()
 	categoryBreakdown := r.renderCategoryBreakdown()
+	tagBreakdown := r.renderTagBreakdown()
+	accountBreakdown := r.renderAccountBreakdown()
 	budgetPerformance := r.renderBudgetPerformance()
 	help := r.renderHelp()
+	if status := r.renderExportStatus(); status != "" {
+		help = status + "\n" + help
+	}
+	
+	if r.pickingRange {
+		help = r.renderRangePicker() + "\n\n" + help
+	}
+	
+	if r.showCalendar {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			header,
+			"",
+			r.renderCalendar(),
+			"",
+			help,
+		)
+	}
+	
+	if r.showYearlyReport && !r.rangeMode {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			header,
+			"",
+			r.renderYearlyReport(),
+			"",
+			help,
+		)
+	}
+	
+	if r.showYearOverYear && !r.rangeMode {
+		return lipgloss.JoinVertical(
+			lipgloss.Left,
+			header,
+			"",
+			r.renderYearOverYear(),
+			"",
+			help,
+		)
+	}
 	
+	leftSections := []string{monthSummary, ""}
+	if allocation := r.renderIncomeAllocation(); allocation != "" {
+		leftSections = append(leftSections, allocation, "")
+	}
 	leftColumn := lipgloss.JoinVertical(
 		lipgloss.Left,
-		monthSummary,
-		"",
-		yearSummary,
+		append(leftSections,
+			yearSummary,
+			"",
+			r.renderAmountHistogram(),
+			"",
+			r.renderTopPlaces(),
+		)...,
 	)
 	
 	rightColumn := lipgloss.JoinVertical(
 		lipgloss.Left,
 		categoryBreakdown,
 		"",
+		tagBreakdown,
+		"",
+		accountBreakdown,
+		"",
 		budgetPerformance,
 	)
 	
-	content := lipgloss.JoinHorizontal(
-		lipgloss.Top,
-		lipgloss.NewStyle().Width(r.width/2).Render(leftColumn),
-		lipgloss.NewStyle().Width(r.width/2).Render(rightColumn),
-	)
+	// Narrow terminals get the columns one above the other
+	content := lipgloss.JoinVertical(lipgloss.Left, leftColumn, "", rightColumn)
+	if r.width >= reportsSideBySideWidth {
+		content = lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			lipgloss.NewStyle().Width(r.width/2).Render(leftColumn),
+			lipgloss.NewStyle().Width(r.width/2).Render(rightColumn),
+		)
+	}
 	
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -133,19 +396,72 @@ func (r *Reports) SetSize(width, height int) {
 	r.height = height
 }
 
+func (r *Reports) selectedMonthStart() time.Time {
+	return time.Date(r.selectedYear, r.selectedMonth, 1, 0, 0, 0, 0, time.Local)
+}
+
+func (r *Reports) prevMonth() tea.Cmd {
+	if !r.selectedMonthStart().After(r.firstMonth) {
+		r.flash = "No earlier data"
+		return r.clearFlash()
+	}
+	r.selectedMonth--
+	if r.selectedMonth < 1 {
+		r.selectedMonth = 12
+		r.selectedYear--
+	}
+	return r.loadReportData
+}
+
+func (r *Reports) nextMonth() tea.Cmd {
+	if !r.selectedMonthStart().Before(r.lastMonth) {
+		r.flash = "No later data"
+		return r.clearFlash()
+	}
+	r.selectedMonth++
+	if r.selectedMonth > 12 {
+		r.selectedMonth = 1
+		r.selectedYear++
+	}
+	return r.loadReportData
+}
+
+// jumpToMonth selects the month containing date, leaving range mode, and
+// reloads the report
+func (r *Reports) jumpToMonth(date time.Time) tea.Cmd {
+	r.rangeMode = false
+	r.selectedMonth = date.Month()
+	r.selectedYear = date.Year()
+	return r.loadReportData
+}
+
+func (r *Reports) clearFlash() tea.Cmd {
+	return tea.Tick(styles.MessageTimeout, func(time.Time) tea.Msg {
+		return clearMessagesMsg{}
+	})
+}
+
+type clearMessagesMsg struct{}
+
 func (r *Reports) renderHeader() string {
 	title := styles.TitleStyle.Render("📊 Financial Reports")
 	
-	monthNav := fmt.Sprintf("← %s %d →", r.selectedMonth.String(), r.selectedYear)
+	monthNav := fmt.Sprintf("← %s →", r.periodLabel())
 	navStyle := lipgloss.NewStyle().
 		Foreground(styles.Primary).
 		Bold(true)
 	
+	if r.flash != "" {
+		monthNav = lipgloss.NewStyle().Foreground(styles.Muted).Render(r.flash+"  ") + navStyle.Render(monthNav)
+	} else {
+		monthNav = navStyle.Render(monthNav)
+	}
+	
 	return lipgloss.JoinHorizontal(
 		lipgloss.Top,
 		title,
 		lipgloss.NewStyle().Width(r.width - lipgloss.Width(title) - lipgloss.Width(monthNav) - 2).Render(""),
-		navStyle.Render(monthNav),
+		monthNav,
 	)
 }
 
@@ -157,7 +473,14 @@ func (r *Reports) renderMonthSummary() string {
 	title := lipgloss.NewStyle().
 		Bold(true).
 		Underline(true).
-		Render(fmt.Sprintf("%s %d Summary", r.selectedMonth.String(), r.selectedYear))
+		Render(r.periodLabel() + " Summary")
+	
+	if r.monthSummary.Count == 0 {
+		empty := lipgloss.NewStyle().
+			Foreground(styles.Muted).
+			Render(fmt.Sprintf("No transactions in %s.\nPress 'n' on the dashboard to add one.", r.periodLabel()))
+		return lipgloss.JoinVertical(lipgloss.Left, title, "", empty)
+	}
 	
 	income := styles.IncomeStyle.Render(fmt.Sprintf("Income:    $%.2f", r.monthSummary.TotalIncome))
 	expenses := styles.ExpenseStyle.Render(fmt.Sprintf("Expenses:  $%.2f", r.monthSummary.TotalExpenses))
@@ -178,6 +501,68 @@ func (r *Reports) renderMonthSummary() string {
 		expenses,
 		divider,
 		balance,
+		r.renderSavingsRate(r.monthSummary),
+	)
+}
+
+func (r *Reports) renderSavingsRate(summary *models.TransactionSummary) string {
+	if summary.TotalIncome == 0 {
+		return lipgloss.NewStyle().Foreground(styles.Muted).Render("Savings:   n/a (no income)")
+	}
+	
+	rate := summary.SavingsRate()
+	rateStyle := styles.WarningStyle
+	if rate >= models.SavingsRateTarget {
+		rateStyle = styles.SuccessStyle
+	} else if rate < 0 {
+		rateStyle = styles.ErrorStyle
+	}
+	
+	ratio := ""
+	if summary.TotalExpenses > 0 {
+		ratio = fmt.Sprintf(" (income/expense %.2fx)", summary.IncomeExpenseRatio())
+	}
+	
+	return rateStyle.Render(fmt.Sprintf("Savings:   %.1f%%", rate)) +
+		lipgloss.NewStyle().Foreground(styles.Muted).Render(ratio)
+}
+
+// renderIncomeAllocation shows where the period's income was allocated,
+// e.g. "Of $5,000.00 income, $1,000.00 went to Savings". It is left out when
+// none of the income was allocated.
+func (r *Reports) renderIncomeAllocation() string {
+	if r.allocation == nil || len(r.allocation.Allocations) == 0 {
+		return ""
+	}
+	
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Underline(true).
+		Render("Income Allocation")
+	
+	income := r.allocation.TotalIncome
+	percent := func(amount float64) string {
+		if income == 0 {
+			return ""
+		}
+		return fmt.Sprintf("%3.0f%%", amount/income*100)
+	}
+	
+	rows := []string{fmt.Sprintf("Of $%s income:", styles.FormatNumber(income))}
+	for _, allocation := range r.allocation.Allocations {
+		name := truncateText(allocation.Name, 16)
+		rows = append(rows, fmt.Sprintf("  %-16s %12s %s", name, "$"+styles.FormatNumber(allocation.AmountUSD), percent(allocation.AmountUSD)))
+	}
+	if unallocated := r.allocation.Unallocated(); unallocated > 0 {
+		rows = append(rows, lipgloss.NewStyle().Foreground(styles.Muted).Render(
+			fmt.Sprintf("  %-16s %12s %s", "unallocated", "$"+styles.FormatNumber(unallocated), percent(unallocated))))
+	}
+	
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		strings.Join(rows, "\n"),
 	)
 }
 
@@ -205,6 +590,7 @@ func (r *Reports) renderYearSummary() string {
 		income,
 		expenses,
 		average,
+		r.renderSavingsRate(r.yearSummary),
 	)
 }
 
@@ -216,26 +602,90 @@ func (r *Reports) renderCategoryBreakdown() string {
 	title := lipgloss.NewStyle().
 		Bold(true).
 		Underline(true).
-		Render("Category Breakdown")
+		Render("Expense Breakdown")
 	
 	var rows []string
-	for i, cat := range r.categoryTotals {
-		if i >= 8 { // Limit to top 8 categories
+	shown := 0
+	for _, cat := range r.categoryTotals {
+		if shown >= 8 { // Limit to top 8 categories
 			break
 		}
-		if cat.Total == 0 {
+		// Percentages are per type, so only expenses are compared here
+		if cat.Type != models.TransactionTypeExpense || cat.Total == 0 {
 			continue
 		}
+		shown++
 		
-		name := fmt.Sprintf("%s %s", cat.Icon, cat.Name)
-		if len(name) > 20 {
-			name = name[:20] + "..."
-		}
+		name := truncateText(fmt.Sprintf("%s %s", cat.Icon, cat.Name), 22)
 		
-		bar := r.renderMiniBar(cat.Percentage, 10)
+		bar := r.renderMiniBar(cat.Percentage, 10, cat.Color)
 		amount := fmt.Sprintf("$%.2f", cat.Total)
 		
-		row := fmt.Sprintf("%-22s %s %8s", name, bar, amount)
+		row := fmt.Sprintf("%-22s %s %5.1f%% %10s", name, bar, cat.Percentage, amount)
+		rows = append(rows, row)
+		
+		if r.showDetails {
+			details := fmt.Sprintf("  %d txns · avg $%.2f · median $%.2f", cat.Count, cat.Average, cat.Median)
+			rows = append(rows, lipgloss.NewStyle().Foreground(styles.Muted).Render(details))
+		}
+	}
+	
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		strings.Join(rows, "\n"),
+	)
+}
+
+func (r *Reports) renderTagBreakdown() string {
+	if len(r.tagTotals) == 0 {
+		return ""
+	}
+	
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Underline(true).
+		Render("Tag Breakdown")
+	
+	var rows []string
+	for _, tag := range r.tagTotals {
+		name := truncateText(tag.Tag, 22)
+		
+		row := fmt.Sprintf("%-22s %4d txns %10s", name, tag.Count, fmt.Sprintf("$%.2f", tag.Total))
+		if tag.Tag == models.UntaggedLabel {
+			row = lipgloss.NewStyle().Foreground(styles.Muted).Render(row)
+		}
+		rows = append(rows, row)
+	}
+	
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		strings.Join(rows, "\n"),
+	)
+}
+
+// renderAccountBreakdown shows how much of the period's spending went
+// through each account, e.g. on one credit card
+func (r *Reports) renderAccountBreakdown() string {
+	if len(r.accountSpending) == 0 {
+		return ""
+	}
+	
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Underline(true).
+		Render("Spending by Account")
+	
+	var rows []string
+	for _, spending := range r.accountSpending {
+		row := fmt.Sprintf("%-22s %s %5.1f%% %10s", truncateText(spending.Name(), 20),
+			r.renderMiniBar(spending.Percentage, 10, ""), spending.Percentage, fmt.Sprintf("$%.2f", spending.Expenses))
+		if spending.Account == nil {
+			row = lipgloss.NewStyle().Foreground(styles.Muted).Render(row)
+		}
 		rows = append(rows, row)
 	}
 	
@@ -265,13 +715,13 @@ func (r *Reports) renderBudgetPerformance() string {
 			continue
 		}
 		
-		name := fmt.Sprintf("%s %s", status.Budget.Category.Icon, status.Budget.Category.Name)
+		name := status.Budget.CategoryLabel()
 		if len(name) > 18 {
 			name = name[:18] + "..."
 		}
 		
 		percentStyle := styles.SuccessStyle
-		if status.PercentUsed > 80 {
+		if status.PercentUsed > models.BudgetWarnPercent {
 			percentStyle = styles.WarningStyle
 		}
 		if status.PercentUsed > 100 {
@@ -302,65 +752,360 @@ func (r *Reports) renderBudgetPerformance() string {
 	)
 }
 
-func (r *Reports) renderMiniBar(percent float64, width int) string {
+// renderAmountHistogram charts how many of the month's expenses fall in each
+// size range, showing e.g. many small purchases versus a few big ones
+func (r *Reports) renderAmountHistogram() string {
+	total, most := 0, 0
+	for _, count := range r.amountHistogram {
+		total += count
+		if count > most {
+			most = count
+		}
+	}
+	if total == 0 {
+		return ""
+	}
+	
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Underline(true).
+		Render("Transaction Sizes")
+	
+	var rows []string
+	for _, label := range models.AmountBucketLabels(models.DefaultAmountBuckets) {
+		count := r.amountHistogram[label]
+		bar := r.renderMiniBar(float64(count)/float64(most)*100, 20, "")
+		rows = append(rows, fmt.Sprintf("%-10s %s %4d", label, bar, count))
+	}
+	
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		strings.Join(rows, "\n"),
+	)
+}
+
+// renderTopPlaces lists the descriptions with the most spent on them, or
+// the most frequent, showing merchant-level spending the categories don't
+func (r *Reports) renderTopPlaces() string {
+	if len(r.topPlaces) == 0 {
+		return ""
+	}
+	
+	heading := "Top places you spend"
+	if r.placesOrder == models.DescriptionsByCount {
+		heading = "Places you spend most often"
+	}
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Underline(true).
+		Render(heading)
+	
+	var rows []string
+	for _, place := range r.topPlaces {
+		rows = append(rows, fmt.Sprintf("%-22s %4d txns %10s",
+			truncateText(place.Description, 22), place.Count, fmt.Sprintf("$%.2f", place.Total)))
+	}
+	
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		strings.Join(rows, "\n"),
+	)
+}
+
+func (r *Reports) renderMiniBar(percent float64, width int, color string) string {
 	if percent > 100 {
 		percent = 100
 	}
+	if percent < 0 {
+		percent = 0
+	}
 	
 	filled := int(float64(width) * percent / 100)
 	empty := width - filled
 	
-	return lipgloss.NewStyle().Foreground(styles.Primary).Render(
+	return lipgloss.NewStyle().Foreground(styles.CategoryColor(color)).Render(
 		strings.Repeat("█", filled) + strings.Repeat("░", empty),
 	)
 }
 
 func (r *Reports) renderHelp() string {
+	if r.exporting {
+		return styles.HelpStyle.Render("[enter]export  [esc]cancel")
+	}
+	if r.pickingRange {
+		return styles.HelpStyle.Render("YYYY-MM-DD, today, yesterday, -7d, -2w, -1m, -1y  [tab]switch  [enter]show  [esc]cancel")
+	}
+	if r.showCalendar {
+		return styles.HelpStyle.Render("[←/→/↑/↓]move  [enter]transactions  [[/]]months  [t]his month  [v/esc]close")
+	}
+	
+	if r.rangeMode {
+		return styles.HelpStyle.Render("[←/→]shift range  [d]ate range  [t]his month  [home/end]first/last month  [i]details  [p]laces by total/count  [esc]back")
+	}
+	
 	help := []string{
 		"[←/→]navigate months",
-		"[esc]back",
+		"[d]ate range",
+		"[t]his month",
+		"[home/end]first/last month",
+		"[i]details",
+		"[p]laces by total/count",
+		"[v]calendar",
+		"[y]ear over year",
+		"[Y]early report",
+	}
+	if r.exportService != nil {
+		help = append(help, "e[x]port month")
 	}
+	help = append(help, "[esc]back")
 	
-	return styles.HelpStyle.Render(strings.Join(help, "  "))
+	return styles.HelpStyle.Width(r.width).Render(strings.Join(help, "  "))
 }
 
 func (r *Reports) loadReportData() tea.Msg {
-	monthSummary, err := r.txService.GetMonthSummary(r.selectedYear, r.selectedMonth)
+	// The selected month, or the date range in range mode
+	start, end := r.period()
+	
+	monthSummary, err := r.txService.GetSummary(context.Background(), start, end)
+	if err != nil {
+		return reportDataMsg{err: err}
+	}
+	
+	// The year to date and budgets are per calendar period, so they are
+	// left out for a date range
+	var yearSummary *models.TransactionSummary
+	var budgetStatuses []*models.BudgetStatus
+	if !r.rangeMode {
+		yearSummary, err = r.txService.GetYearSummary(context.Background(), r.selectedYear)
+		if err != nil {
+			return reportDataMsg{err: err}
+		}
+		
+		budgetStatuses, err = r.budgetService.GetAllStatuses(context.Background())
+		if err != nil {
+			return reportDataMsg{err: err}
+		}
+	}
+	
+	categoryTotals, err := r.txService.GetCategoryStats(context.Background(), start, end)
+	if err != nil {
+		return reportDataMsg{err: err}
+	}
+	
+	var tagTotals []*models.TagTotal
+	if r.tagPattern != "" {
+		tagTotals, err = r.txService.GetTagTotals(context.Background(), start, end, r.tagPattern)
+		if err != nil {
+			return reportDataMsg{err: err}
+		}
+	}
+	
+	var accountSpending []*models.AccountSummary
+	if r.accountService != nil {
+		accountSpending, err = r.accountService.GetSpending(context.Background(), start, end)
+		if err != nil {
+			return reportDataMsg{err: err}
+		}
+	}
+	
+	amountHistogram, err := r.txService.GetAmountHistogram(context.Background(), start, end, models.DefaultAmountBuckets)
 	if err != nil {
 		return reportDataMsg{err: err}
 	}
 	
-	yearSummary, err := r.txService.GetYearSummary(r.selectedYear)
+	topPlaces, err := r.txService.GetTopDescriptions(context.Background(), start, end, reportTopPlaces, r.placesOrder)
 	if err != nil {
 		return reportDataMsg{err: err}
 	}
 	
-	// Get category totals for the selected month
-	start := time.Date(r.selectedYear, r.selectedMonth, 1, 0, 0, 0, 0, time.Local)
-	end := start.AddDate(0, 1, 0).Add(-time.Second)
+	allocation, err := r.txService.GetAllocationSummary(context.Background(), start, end)
+	if err != nil {
+		return reportDataMsg{err: err}
+	}
 	
-	categoryTotals, err := r.txService.GetCategorySummary(start, end)
+	totals, err := r.txService.GetDailyTotals(context.Background(), start, end)
 	if err != nil {
 		return reportDataMsg{err: err}
 	}
+	dailyTotals := make(map[int]float64, len(totals))
+	for _, total := range totals {
+		dailyTotals[total.Date.Day()] = total.Total
+	}
 	
-	budgetStatuses, err := r.budgetService.GetAllStatuses()
+	// Navigation is bounded by the months that contain transactions, always
+	// including the current month
+	first, last, err := r.txService.GetDateRange(context.Background())
 	if err != nil {
 		return reportDataMsg{err: err}
 	}
+	now := time.Now()
+	currentMonth := time.Date(now.Year(), now.Month(), 1, 0, 0, 0, 0, time.Local)
+	firstMonth, lastMonth := currentMonth, currentMonth
+	if !first.IsZero() {
+		if m := time.Date(first.Year(), first.Month(), 1, 0, 0, 0, 0, time.Local); m.Before(firstMonth) {
+			firstMonth = m
+		}
+		if m := time.Date(last.Year(), last.Month(), 1, 0, 0, 0, 0, time.Local); m.After(lastMonth) {
+			lastMonth = m
+		}
+	}
 	
 	return reportDataMsg{
-		monthSummary:   monthSummary,
-		yearSummary:    yearSummary,
-		categoryTotals: categoryTotals,
-		budgetStatuses: budgetStatuses,
+		monthSummary:    monthSummary,
+		yearSummary:     yearSummary,
+		categoryTotals:  categoryTotals,
+		tagTotals:       tagTotals,
+		accountSpending: accountSpending,
+		topPlaces:       topPlaces,
+		budgetStatuses:  budgetStatuses,
+		amountHistogram: amountHistogram,
+		allocation:      allocation,
+		firstMonth:      firstMonth,
+		lastMonth:       lastMonth,
+		dailyTotals:     dailyTotals,
 	}
 }
 
+// loadYearOverYear loads the comparison of the selected month with the
+// same month a year earlier
+func (r *Reports) loadYearOverYear() tea.Msg {
+	yearOverYear, err := r.txService.GetYearOverYear(context.Background(), r.selectedYear, r.selectedMonth)
+	return yearOverYearMsg{year: r.selectedYear, month: r.selectedMonth, yearOverYear: yearOverYear, err: err}
+}
+
+// loadYearlyReport loads the month by month report of the selected year
+func (r *Reports) loadYearlyReport() tea.Msg {
+	report, err := r.txService.GetYearlyReport(context.Background(), r.selectedYear, time.Now())
+	return yearlyReportMsg{report: report, err: err}
+}
+
 type reportDataMsg struct {
-	monthSummary   *models.TransactionSummary
-	yearSummary    *models.TransactionSummary
-	categoryTotals []*models.CategoryWithTotal
-	budgetStatuses []*models.BudgetStatus
-	err            error
-}
\ No newline at end of file
+	monthSummary    *models.TransactionSummary
+	yearSummary     *models.TransactionSummary
+	categoryTotals  []*models.CategoryWithTotal
+	tagTotals       []*models.TagTotal
+	accountSpending []*models.AccountSummary
+	topPlaces       []*models.DescriptionStat
+	budgetStatuses  []*models.BudgetStatus
+	amountHistogram map[string]int
+	allocation      *models.AllocationSummary
+	firstMonth      time.Time
+	lastMonth       time.Time
+	dailyTotals     map[int]float64
+	err             error
+}
+
+type yearOverYearMsg struct {
+	year         int
+	month        time.Month
+	yearOverYear *models.YearOverYear
+	err          error
+}
+
+type yearlyReportMsg struct {
+	report *models.YearlyReport
+	err    error
+}
+
+// startExport opens the path prompt for exporting the selected month,
+// suggesting a file named after it in the export directory
+func (r *Reports) startExport() tea.Cmd {
+	name := fmt.Sprintf("burnwise-report-%04d-%02d.csv", r.selectedYear, int(r.selectedMonth))
+	
+	r.exportPath = textinput.New()
+	r.exportPath.Prompt = "Export to: "
+	r.exportPath.CharLimit = 512
+	r.exportPath.Width = 60
+	r.exportPath.SetValue(filepath.Join(r.exportDir, name))
+	r.exportPath.CursorEnd()
+	r.exporting = true
+	r.confirmOverwrite = false
+	r.exportStatus = ""
+	r.exportErr = nil
+	return r.exportPath.Focus()
+}
+
+func (r *Reports) updateExportPrompt(msg tea.KeyMsg) tea.Cmd {
+	if r.confirmOverwrite {
+		switch msg.String() {
+		case "y", "Y":
+			r.exporting = false
+			r.confirmOverwrite = false
+			return r.exportMonth(strings.TrimSpace(r.exportPath.Value()))
+		case "n", "N", "esc":
+			// Back to the prompt to choose another path
+			r.confirmOverwrite = false
+		}
+		return nil
+	}
+	
+	switch msg.String() {
+	case "esc":
+		r.exporting = false
+		return nil
+	case "enter":
+		path := strings.TrimSpace(r.exportPath.Value())
+		if path == "" {
+			return nil
+		}
+		if _, err := os.Stat(path); err == nil {
+			r.confirmOverwrite = true
+			return nil
+		}
+		r.exporting = false
+		return r.exportMonth(path)
+	}
+	
+	var cmd tea.Cmd
+	r.exportPath, cmd = r.exportPath.Update(msg)
+	return cmd
+}
+
+// exportMonth writes the monthly report CSV for the selected month to path.
+// Cancelling it with esc removes the partly written file.
+func (r *Reports) exportMonth(path string) tea.Cmd {
+	year, month := r.selectedYear, r.selectedMonth
+	ctx, cancel := context.WithCancel(context.Background())
+	r.cancelExport = cancel
+	return func() tea.Msg {
+		defer cancel()
+		
+		file, err := os.Create(path)
+		if err != nil {
+			return reportExportedMsg{err: fmt.Errorf("failed to create export file: %w", err)}
+		}
+		
+		if err := r.exportService.ExportMonthlyReportCSV(ctx, file, year, month); err != nil {
+			file.Close()
+			if ctx.Err() != nil {
+				os.Remove(path)
+			}
+			return reportExportedMsg{err: fmt.Errorf("export failed: %w", err)}
+		}
+		// A failed close can mean the file wasn't fully written
+		if err := file.Close(); err != nil {
+			return reportExportedMsg{err: fmt.Errorf("failed to save export file: %w", err)}
+		}
+		return reportExportedMsg{year: year, month: month, path: path}
+	}
+}
+
+func (r *Reports) renderExportStatus() string {
+	switch {
+	case r.confirmOverwrite:
+		return styles.WarningStyle.Render(fmt.Sprintf("⚠️  %s already exists. Overwrite? (y/n)", strings.TrimSpace(r.exportPath.Value())))
+	case r.exporting:
+		return r.exportPath.View()
+	case r.cancelExport != nil:
+		return lipgloss.NewStyle().Foreground(styles.Muted).Render("Exporting... (esc to cancel)")
+	case r.exportErr != nil:
+		return styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", r.exportErr))
+	case r.exportStatus != "":
+		return styles.SuccessStyle.Render(r.exportStatus)
+	}
+	return ""
+}
@@ -0,0 +1,183 @@
+package views
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/ui/styles"
+)
+
+// ShowDayTransactionsMsg asks for the transaction list filtered to one day
+type ShowDayTransactionsMsg struct {
+	Date time.Time
+}
+
+// heatmapShades are the calendar cell backgrounds, from the quietest
+// quarter of spending days to the busiest
+var heatmapShades = []lipgloss.Color{"#FFE0B2", "#FFB74D", "#FB8C00", "#E65100"}
+
+// calendarWeekdays are the grid's column headers; weeks start on Monday
+var calendarWeekdays = []string{"Mon", "Tue", "Wed", "Thu", "Fri", "Sat", "Sun"}
+
+// openCalendar shows the calendar with the cursor on today when the
+// selected month is the current one, otherwise on the first
+func (r *Reports) openCalendar() {
+	r.showCalendar = true
+	r.calendarDay = 1
+	now := time.Now()
+	if now.Year() == r.selectedYear && now.Month() == r.selectedMonth {
+		r.calendarDay = now.Day()
+	}
+	r.clampCalendarDay()
+}
+
+func (r *Reports) daysInSelectedMonth() int {
+	return r.selectedMonthStart().AddDate(0, 1, -1).Day()
+}
+
+// clampCalendarDay keeps the cursor inside the selected month, which may
+// be shorter than the one it was moved from
+func (r *Reports) clampCalendarDay() {
+	if days := r.daysInSelectedMonth(); r.calendarDay > days {
+		r.calendarDay = days
+	}
+	if r.calendarDay < 1 {
+		r.calendarDay = 1
+	}
+}
+
+// updateCalendar handles the keys the calendar uses. Others are left to
+// the report, which is reported by handled being false.
+func (r *Reports) updateCalendar(msg tea.KeyMsg) (cmd tea.Cmd, handled bool) {
+	move := func(days int) {
+		day := r.calendarDay + days
+		if day >= 1 && day <= r.daysInSelectedMonth() {
+			r.calendarDay = day
+		}
+	}
+
+	switch msg.String() {
+	case "left", "h":
+		move(-1)
+	case "right", "l":
+		move(1)
+	case "up", "k":
+		move(-7)
+	case "down", "j":
+		move(7)
+	case "[":
+		return r.prevMonth(), true
+	case "]":
+		return r.nextMonth(), true
+	case "enter":
+		date := r.selectedMonthStart().AddDate(0, 0, r.calendarDay-1)
+		return func() tea.Msg { return ShowDayTransactionsMsg{Date: date} }, true
+	case "v", "esc":
+		r.showCalendar = false
+	default:
+		return nil, false
+	}
+	return nil, true
+}
+
+// heatLevel ranks a day's spending among the month's spending days: 1 for
+// the lowest quarter up to 4 for the highest, and 0 for no spending
+func heatLevel(total float64, sorted []float64) int {
+	if total <= 0 || len(sorted) == 0 {
+		return 0
+	}
+	atOrBelow := sort.Search(len(sorted), func(i int) bool { return sorted[i] > total })
+	level := (atOrBelow*len(heatmapShades) + len(sorted) - 1) / len(sorted)
+	if level < 1 {
+		level = 1
+	}
+	return level
+}
+
+func (r *Reports) renderCalendar() string {
+	start := r.selectedMonthStart()
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Underline(true).
+		Render(fmt.Sprintf("%s %d Daily Spending", start.Month().String(), start.Year()))
+
+	var spending []float64
+	for _, total := range r.dailyTotals {
+		if total > 0 {
+			spending = append(spending, total)
+		}
+	}
+	sort.Float64s(spending)
+
+	var header []string
+	for _, name := range calendarWeekdays {
+		header = append(header, fmt.Sprintf(" %-3s", name))
+	}
+	rows := []string{lipgloss.NewStyle().Foreground(styles.Muted).Render(strings.Join(header, ""))}
+
+	// Go's weekdays start on Sunday, the grid's on Monday
+	offset := (int(start.Weekday()) + 6) % 7
+	cells := make([]string, 0, 7)
+	for i := 0; i < offset; i++ {
+		cells = append(cells, "    ")
+	}
+	for day := 1; day <= r.daysInSelectedMonth(); day++ {
+		cells = append(cells, r.renderCalendarCell(day, heatLevel(r.dailyTotals[day], spending)))
+		if len(cells) == 7 {
+			rows = append(rows, strings.Join(cells, ""))
+			cells = cells[:0]
+		}
+	}
+	if len(cells) > 0 {
+		rows = append(rows, strings.Join(cells, ""))
+	}
+
+	selected := start.AddDate(0, 0, r.calendarDay-1)
+	detail := fmt.Sprintf("%s: no spending", selected.Format("Mon Jan 2"))
+	if total := r.dailyTotals[r.calendarDay]; total > 0 {
+		detail = fmt.Sprintf("%s: $%s spent", selected.Format("Mon Jan 2"), styles.FormatNumber(total))
+	}
+
+	legend := lipgloss.NewStyle().Foreground(styles.Muted).Render("less ")
+	for _, shade := range heatmapShades {
+		legend += lipgloss.NewStyle().Background(shade).Render("  ")
+	}
+	legend += lipgloss.NewStyle().Foreground(styles.Muted).Render(" more")
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		strings.Join(rows, "\n"),
+		"",
+		styles.ExpenseStyle.Render(detail),
+		legend,
+	)
+}
+
+// renderCalendarCell draws one day, bracketed when it is under the cursor
+func (r *Reports) renderCalendarCell(day, level int) string {
+	text := fmt.Sprintf(" %2d ", day)
+	if day == r.calendarDay {
+		text = fmt.Sprintf("[%2d]", day)
+	}
+
+	style := lipgloss.NewStyle()
+	if level == 0 {
+		style = style.Foreground(styles.Muted)
+	} else {
+		style = style.Background(heatmapShades[level-1]).Foreground(lipgloss.Color("#000000"))
+		if level > 2 {
+			style = style.Foreground(lipgloss.Color("#FFFFFF"))
+		}
+	}
+	if day == r.calendarDay {
+		style = style.Bold(true)
+	}
+	return style.Render(text)
+}
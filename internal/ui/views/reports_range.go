@@ -0,0 +1,148 @@
+package views
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/service"
+	"burnwise/internal/ui/styles"
+)
+
+// IsPickingRange reports whether the date range prompt is taking input, in
+// which case keys should not be treated as navigation shortcuts
+func (r *Reports) IsPickingRange() bool {
+	return r.pickingRange
+}
+
+// openRangePicker opens the from/to prompt, filled in with the range shown
+// or else the selected month
+func (r *Reports) openRangePicker() tea.Cmd {
+	start, end := r.rangeStart, r.rangeEnd
+	if !r.rangeMode {
+		start = r.selectedMonthStart()
+		end = start.AddDate(0, 1, -1)
+	}
+
+	for i, date := range []time.Time{start, end} {
+		input := textinput.New()
+		input.Placeholder = "YYYY-MM-DD, today, -7d"
+		input.CharLimit = 10
+		input.Width = 24
+		input.SetValue(date.Format("2006-01-02"))
+		input.CursorEnd()
+		r.rangeInputs[i] = input
+	}
+	r.rangeFocus = 0
+	r.rangeErr = nil
+	r.pickingRange = true
+	return r.rangeInputs[0].Focus()
+}
+
+// updateRangePicker handles a key while the prompt is open: tab moves
+// between the dates, enter shows the range and esc closes the prompt
+func (r *Reports) updateRangePicker(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		r.pickingRange = false
+		return nil
+	case "tab", "shift+tab", "up", "down":
+		r.rangeInputs[r.rangeFocus].Blur()
+		r.rangeFocus = 1 - r.rangeFocus
+		return r.rangeInputs[r.rangeFocus].Focus()
+	case "enter":
+		return r.applyRange()
+	}
+
+	var cmd tea.Cmd
+	r.rangeInputs[r.rangeFocus], cmd = r.rangeInputs[r.rangeFocus].Update(msg)
+	r.rangeErr = nil
+	return cmd
+}
+
+func (r *Reports) applyRange() tea.Cmd {
+	now := time.Now()
+	start, err := service.ParseRelativeDate(r.rangeInputs[0].Value(), now)
+	if err != nil {
+		r.rangeErr = fmt.Errorf("from: %w", err)
+		return nil
+	}
+	end, err := service.ParseRelativeDate(r.rangeInputs[1].Value(), now)
+	if err != nil {
+		r.rangeErr = fmt.Errorf("to: %w", err)
+		return nil
+	}
+	if end.Before(start) {
+		r.rangeErr = fmt.Errorf("the range ends before it starts")
+		return nil
+	}
+
+	r.rangeStart, r.rangeEnd = start, end
+	r.rangeMode = true
+	r.pickingRange = false
+	r.showCalendar = false
+	return r.loadReportData
+}
+
+// rangeDays is the number of days in the range, both ends included
+func (r *Reports) rangeDays() int {
+	// Rounded, since a day across a DST change isn't 24 hours
+	return int(r.rangeEnd.Sub(r.rangeStart).Hours()/24+0.5) + 1
+}
+
+// shiftRange moves the range by its own length, earlier for a negative
+// direction and later for a positive one
+func (r *Reports) shiftRange(direction int) tea.Cmd {
+	days := direction * r.rangeDays()
+	r.rangeStart = r.rangeStart.AddDate(0, 0, days)
+	r.rangeEnd = r.rangeEnd.AddDate(0, 0, days)
+	return r.loadReportData
+}
+
+// period returns the first and last moment of the range shown, or of the
+// selected month outside range mode
+func (r *Reports) period() (time.Time, time.Time) {
+	if r.rangeMode {
+		return r.rangeStart, r.rangeEnd.AddDate(0, 0, 1).Add(-time.Second)
+	}
+	start := r.selectedMonthStart()
+	return start, start.AddDate(0, 1, 0).Add(-time.Second)
+}
+
+// periodLabel names the range shown, or the selected month
+func (r *Reports) periodLabel() string {
+	if !r.rangeMode {
+		return fmt.Sprintf("%s %d", r.selectedMonth.String(), r.selectedYear)
+	}
+	days := r.rangeDays()
+	unit := "days"
+	if days == 1 {
+		unit = "day"
+	}
+	return fmt.Sprintf("%s – %s (%d %s)", styles.FormatDate(r.rangeStart), styles.FormatDate(r.rangeEnd), days, unit)
+}
+
+func (r *Reports) renderRangePicker() string {
+	field := func(label string, i int) string {
+		input := r.rangeInputs[i].View()
+		if i == r.rangeFocus {
+			input = styles.FormInputFocusedStyle.Render(input)
+		} else {
+			input = styles.FormInputStyle.Render(input)
+		}
+		return lipgloss.JoinHorizontal(lipgloss.Top, styles.FormLabelStyle.Width(8).Render(label), input)
+	}
+
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Render("Date range"),
+		field("From:", 0),
+		field("To:", 1),
+	}
+	if r.rangeErr != nil {
+		lines = append(lines, styles.ErrorStyle.Render(r.rangeErr.Error()))
+	}
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
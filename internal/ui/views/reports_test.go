@@ -0,0 +1,19 @@
+package views
+
+import (
+	"testing"
+	"unicode/utf8"
+
+	"github.com/stretchr/testify/assert"
+
+	"burnwise/internal/models"
+)
+
+func TestReports_TagBreakdownTruncatesByCharacter(t *testing.T) {
+	r := &Reports{tagTotals: []*models.TagTotal{{Tag: "#café-crème-ausflüge-ümlaut", Total: 12, Count: 3}}}
+
+	view := r.renderTagBreakdown()
+	assert.True(t, utf8.ValidString(view))
+	assert.Contains(t, view, "#café-crème-ausflüge-…")
+	assert.NotContains(t, view, "ümlaut")
+}
@@ -0,0 +1,91 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/ui/styles"
+)
+
+// renderYearlyReport shows the selected year month by month, with its
+// totals, biggest expense categories and biggest single expense. Months
+// that haven't started are left blank. Until the report is loaded, that
+// is said instead.
+func (r *Reports) renderYearlyReport() string {
+	report := r.yearlyReport
+	if report == nil {
+		return styles.HelpStyle.Render("Loading yearly report...")
+	}
+
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Underline(true).
+		Render(fmt.Sprintf("%d Annual Report", report.Year))
+	muted := lipgloss.NewStyle().Foreground(styles.Muted)
+	money := func(amount float64) string {
+		return "$" + styles.FormatNumber(amount)
+	}
+	netStyle := func(net float64) lipgloss.Style {
+		if net < 0 {
+			return styles.ExpenseStyle
+		}
+		return styles.BalanceStyle
+	}
+
+	header := fmt.Sprintf("%-10s %14s %14s %14s", "Month", "Income", "Expenses", "Net")
+	rows := []string{muted.Render(header)}
+	for i, month := range report.Months {
+		name := fmt.Sprintf("%-10s", time.Month(i+1).String())
+		if month == nil {
+			rows = append(rows, muted.Render(name))
+			continue
+		}
+		row := fmt.Sprintf("%s %s %s %s", name,
+			styles.IncomeStyle.Render(fmt.Sprintf("%14s", money(month.Income))),
+			styles.ExpenseStyle.Render(fmt.Sprintf("%14s", money(month.Expenses))),
+			netStyle(month.Net()).Render(fmt.Sprintf("%14s", money(month.Net()))))
+		if i+1 == int(r.selectedMonth) {
+			row = lipgloss.NewStyle().Bold(true).Render(row)
+		}
+		rows = append(rows, row)
+	}
+	rows = append(rows, strings.Repeat("─", lipgloss.Width(header)))
+	rows = append(rows, fmt.Sprintf("%-10s %14s %14s %s", "Total",
+		money(report.Total.TotalIncome), money(report.Total.TotalExpenses),
+		netStyle(report.Total.Balance).Render(fmt.Sprintf("%14s", money(report.Total.Balance)))))
+	if elapsed := report.MonthsElapsed(); elapsed > 0 {
+		rows = append(rows, muted.Render(fmt.Sprintf("%-10s %14s %14s %14s", "Avg/Month",
+			money(report.Total.TotalIncome/float64(elapsed)), money(report.AverageExpenses()),
+			money(report.Total.Balance/float64(elapsed)))))
+	}
+
+	highlights := []string{lipgloss.NewStyle().Bold(true).Render("Biggest expense categories")}
+	if len(report.TopCategories) == 0 {
+		highlights = append(highlights, muted.Render("No expenses this year."))
+	}
+	for i, cat := range report.TopCategories {
+		name := truncateText(fmt.Sprintf("%s %s", cat.Icon, cat.Name), 20)
+		highlights = append(highlights, fmt.Sprintf("%d. %-20s %12s %5.1f%%", i+1, name, money(cat.Total), cat.Percentage))
+	}
+	if tx := report.Largest; tx != nil {
+		highlights = append(highlights, "",
+			lipgloss.NewStyle().Bold(true).Render("Biggest single expense"),
+			fmt.Sprintf("%s %s", money(tx.AmountUSD), truncateText(tx.Description, 30)),
+			muted.Render(fmt.Sprintf("%s · %s %s", styles.FormatDate(tx.Date), tx.Category.Icon, tx.Category.Name)))
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		lipgloss.JoinHorizontal(
+			lipgloss.Top,
+			strings.Join(rows, "\n"),
+			"    ",
+			strings.Join(highlights, "\n"),
+		),
+	)
+}
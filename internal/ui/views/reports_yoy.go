@@ -0,0 +1,88 @@
+package views
+
+import (
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/models"
+	"burnwise/internal/ui/styles"
+)
+
+// yearOverYearRows caps the categories listed in the year-over-year report
+const yearOverYearRows = 12
+
+// renderYearOverYear compares the selected month with the same month a
+// year earlier: the totals first, then each category's change. Until the
+// comparison is loaded, that is said instead.
+func (r *Reports) renderYearOverYear() string {
+	yoy := r.yearOverYear
+	if yoy == nil {
+		return styles.HelpStyle.Render("Loading comparison...")
+	}
+
+	title := lipgloss.NewStyle().
+		Bold(true).
+		Underline(true).
+		Render(fmt.Sprintf("%s %d vs %s %d", r.selectedMonth.String(), r.selectedYear, r.selectedMonth.String(), r.selectedYear-1))
+	muted := lipgloss.NewStyle().Foreground(styles.Muted)
+
+	if yoy.Current.Count == 0 && yoy.Previous.Count == 0 {
+		empty := muted.Render(fmt.Sprintf("No transactions in %s of either year.", r.selectedMonth.String()))
+		return lipgloss.JoinVertical(lipgloss.Left, title, "", empty)
+	}
+
+	header := fmt.Sprintf("%-22s %12d %12d %12s %8s", "", r.selectedYear, r.selectedYear-1, "Change", "%")
+	rows := []string{
+		muted.Render(header),
+		yearOverYearRow("Income", yoy.Current.TotalIncome, yoy.Previous.TotalIncome, ""),
+		yearOverYearRow("Expenses", yoy.Current.TotalExpenses, yoy.Previous.TotalExpenses, ""),
+		strings.Repeat("─", lipgloss.Width(header)),
+	}
+
+	for i, cat := range yoy.Categories {
+		if i == yearOverYearRows {
+			rows = append(rows, muted.Render(fmt.Sprintf("… and %d more categories", len(yoy.Categories)-yearOverYearRows)))
+			break
+		}
+		note := ""
+		if cat.IsNew {
+			note = "new"
+		}
+		rows = append(rows, yearOverYearRow(truncateText(fmt.Sprintf("%s %s", cat.Icon, cat.Name), 22), cat.Current, cat.Previous, note))
+	}
+
+	return lipgloss.JoinVertical(
+		lipgloss.Left,
+		title,
+		"",
+		strings.Join(rows, "\n"),
+	)
+}
+
+// yearOverYearRow lays out one line of the comparison. The percentage is
+// replaced by note, or "–", when there was nothing a year earlier.
+func yearOverYearRow(label string, current, previous float64, note string) string {
+	comparison := &models.CategoryComparison{Current: current, Previous: previous}
+	delta := comparison.Delta()
+
+	change := note
+	if previous != 0 {
+		change = fmt.Sprintf("%+.0f%%", comparison.Change())
+	} else if change == "" {
+		change = "–"
+	}
+
+	sign := ""
+	if delta > 0 {
+		sign = "+"
+	} else if delta < 0 {
+		sign = "-"
+	}
+
+	return fmt.Sprintf("%-22s %12s %12s %12s %8s", label,
+		"$"+styles.FormatNumber(current), "$"+styles.FormatNumber(previous),
+		sign+"$"+styles.FormatNumber(math.Abs(delta)), change)
+}
@@ -0,0 +1,440 @@
+package views
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/models"
+	"burnwise/internal/service"
+	"burnwise/internal/ui/styles"
+)
+
+// Focus order: the fixed fields, one row per dashboard widget, then Save
+const (
+	settingsFieldDateFormat = iota
+	settingsFieldDecimalPlaces
+	settingsFieldTheme
+	settingsFieldCurrency
+	settingsFieldTagPattern
+	settingsFieldMonthlyLimit
+	settingsFieldWidgets // first dashboard widget row
+)
+
+// SettingsView edits the general UI preferences, the default currency and
+// the monthly spending limit
+type SettingsView struct {
+	width           int
+	height          int
+	settingsService *service.SettingsService
+
+	dateFormat    string
+	decimalPlaces textinput.Model
+	theme         string
+	currency      string
+	currencies    []string
+	tagPattern    textinput.Model
+	monthlyLimit  textinput.Model
+	widgets       []models.DashboardWidget
+
+	focusIndex int
+	fieldErrs  map[int]string
+	message    string
+}
+
+// SettingsSavedMsg carries the preferences that were just saved so they can
+// be applied to every view
+type SettingsSavedMsg struct{ UI models.UISettings }
+
+func NewSettingsView(settingsService *service.SettingsService) *SettingsView {
+	decimalPlaces := textinput.New()
+	decimalPlaces.Placeholder = "2"
+	decimalPlaces.CharLimit = 2
+	decimalPlaces.Width = 4
+
+	tagPattern := textinput.New()
+	tagPattern.Placeholder = `^\[(\w+)\]`
+	tagPattern.CharLimit = 100
+	tagPattern.Width = 30
+
+	monthlyLimit := textinput.New()
+	monthlyLimit.Placeholder = "none"
+	monthlyLimit.CharLimit = 12
+	monthlyLimit.Width = 12
+
+	return &SettingsView{
+		settingsService: settingsService,
+		decimalPlaces:   decimalPlaces,
+		tagPattern:      tagPattern,
+		monthlyLimit:    monthlyLimit,
+		fieldErrs:       make(map[int]string),
+	}
+}
+
+// Init loads the saved preferences into the form
+func (v *SettingsView) Init() tea.Cmd {
+	ui := v.settingsService.Get().UI
+	v.dateFormat = ui.DateFormat
+	v.decimalPlaces.SetValue(strconv.Itoa(ui.DecimalPlaces))
+	v.theme = ui.Theme
+	v.currency = v.settingsService.GetDefaultCurrency()
+	v.currencies = v.settingsService.GetEnabledCurrencies()
+	v.tagPattern.SetValue(ui.TagPattern)
+	v.monthlyLimit.SetValue("")
+	if limit := v.settingsService.GetMonthlyLimit(); limit > 0 {
+		v.monthlyLimit.SetValue(strconv.FormatFloat(limit, 'f', -1, 64))
+	}
+	v.widgets = ui.Dashboard.Layout()
+	v.focusIndex = settingsFieldDateFormat
+	v.fieldErrs = make(map[int]string)
+	v.message = ""
+	v.decimalPlaces.Blur()
+	v.tagPattern.Blur()
+	v.monthlyLimit.Blur()
+
+	// Settings edited by hand may hold values the UI can't use
+	if !models.IsDateLayout(v.dateFormat) {
+		v.fieldErrs[settingsFieldDateFormat] = fmt.Sprintf("%q is not a valid date layout, pick another", v.dateFormat)
+	}
+	if !v.settingsService.IsCurrencyEnabled(v.currency) {
+		v.fieldErrs[settingsFieldCurrency] = fmt.Sprintf("%q is not an enabled currency, pick another", v.currency)
+	}
+	if !models.IsKnownTheme(v.theme) {
+		v.fieldErrs[settingsFieldTheme] = fmt.Sprintf("unknown theme %q, pick another", v.theme)
+	}
+
+	return nil
+}
+
+func (v *SettingsView) Update(msg tea.Msg) (*SettingsView, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.WindowSizeMsg:
+		v.SetSize(msg.Width, msg.Height)
+
+	case tea.KeyMsg:
+		v.message = ""
+
+		// The tag pattern is free text, so only the keys that leave or
+		// save the form are shortcuts there
+		if v.focusIndex == settingsFieldTagPattern {
+			switch msg.String() {
+			case "esc", "tab", "down", "shift+tab", "up", "ctrl+s", "enter":
+			default:
+				var cmd tea.Cmd
+				v.tagPattern, cmd = v.tagPattern.Update(msg)
+				delete(v.fieldErrs, settingsFieldTagPattern)
+				return v, cmd
+			}
+		}
+
+		switch msg.String() {
+		case "esc":
+			return v, func() tea.Msg { return BackToDashboardMsg{} }
+		case "tab", "down":
+			v.moveFocus(1)
+			return v, nil
+		case "shift+tab", "up":
+			v.moveFocus(-1)
+			return v, nil
+		case "ctrl+s":
+			return v, v.save()
+		case "K", "shift+up":
+			v.moveWidget(-1)
+			return v, nil
+		case "J", "shift+down":
+			v.moveWidget(1)
+			return v, nil
+		case "enter":
+			if v.focusIndex == v.saveField() || v.focusIndex == settingsFieldDecimalPlaces || v.focusIndex == settingsFieldTagPattern || v.focusIndex == settingsFieldMonthlyLimit {
+				return v, v.save()
+			}
+			if v.focusedWidget() >= 0 {
+				v.toggleWidget()
+				return v, nil
+			}
+			v.cycle(1)
+			return v, nil
+		case " ":
+			if v.focusedWidget() >= 0 {
+				v.toggleWidget()
+				return v, nil
+			}
+			v.cycle(1)
+			return v, nil
+		case "left", "right":
+			if v.focusIndex == settingsFieldDateFormat || v.focusIndex == settingsFieldTheme || v.focusIndex == settingsFieldCurrency {
+				if msg.String() == "left" {
+					v.cycle(-1)
+				} else {
+					v.cycle(1)
+				}
+				return v, nil
+			}
+		}
+	}
+
+	var cmd tea.Cmd
+	switch v.focusIndex {
+	case settingsFieldDecimalPlaces:
+		v.decimalPlaces, cmd = v.decimalPlaces.Update(msg)
+	case settingsFieldMonthlyLimit:
+		v.monthlyLimit, cmd = v.monthlyLimit.Update(msg)
+		delete(v.fieldErrs, settingsFieldMonthlyLimit)
+	}
+	return v, cmd
+}
+
+func (v *SettingsView) View() string {
+	title := styles.TitleStyle.Render("⚙️  Settings")
+
+	dateValue := v.choiceValue(settingsFieldDateFormat,
+		fmt.Sprintf("%s  (e.g. %s)", v.dateFormat, time.Now().Format(v.dateFormat)))
+
+	decimalInput := v.decimalPlaces.View()
+	if v.focusIndex == settingsFieldDecimalPlaces {
+		decimalInput = styles.FormInputFocusedStyle.Render(decimalInput)
+	} else {
+		decimalInput = styles.FormInputStyle.Render(decimalInput)
+	}
+
+	themeValue := v.choiceValue(settingsFieldTheme, v.theme)
+	currencyValue := v.choiceValue(settingsFieldCurrency, v.currency)
+
+	tagInput := v.tagPattern.View()
+	if v.focusIndex == settingsFieldTagPattern {
+		tagInput = styles.FormInputFocusedStyle.Render(tagInput)
+	} else {
+		tagInput = styles.FormInputStyle.Render(tagInput)
+	}
+
+	limitInput := v.monthlyLimit.View()
+	if v.focusIndex == settingsFieldMonthlyLimit {
+		limitInput = styles.FormInputFocusedStyle.Render(limitInput)
+	} else {
+		limitInput = styles.FormInputStyle.Render(limitInput)
+	}
+	limitInput += lipgloss.NewStyle().Foreground(styles.Muted).Render("  USD, empty for none")
+
+	saveButton := "[Save]"
+	if v.focusIndex == v.saveField() {
+		saveButton = styles.ButtonStyle.Render(saveButton)
+	} else {
+		saveButton = styles.ButtonInactiveStyle.Render(saveButton)
+	}
+
+	rows := []string{
+		v.row("Date format:", dateValue, settingsFieldDateFormat),
+		v.row("Decimals:", decimalInput, settingsFieldDecimalPlaces),
+		v.row("Theme:", themeValue, settingsFieldTheme),
+		v.row("Currency:", currencyValue, settingsFieldCurrency),
+		v.row("Tag pattern:", tagInput, settingsFieldTagPattern),
+		v.row("Month limit:", limitInput, settingsFieldMonthlyLimit),
+		"",
+		styles.FormLabelStyle.Render("Dashboard widgets:"),
+	}
+	for i, widget := range v.widgets {
+		check := "[ ]"
+		if widget.Enabled {
+			check = "[x]"
+		}
+		line := fmt.Sprintf("%s %s", check, models.DashboardWidgetTitles[widget.Name])
+		if v.focusIndex == settingsFieldWidgets+i {
+			line = styles.SelectedStyle.Render("› " + line)
+		} else {
+			line = "  " + line
+		}
+		rows = append(rows, line)
+	}
+	rows = append(rows, "", saveButton)
+
+	if err, ok := v.fieldErrs[v.saveField()]; ok {
+		rows = append(rows, "", styles.ErrorStyle.Render(fmt.Sprintf("Error: %s", err)))
+	} else if v.message != "" {
+		rows = append(rows, "", styles.SuccessStyle.Render(v.message))
+	}
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Primary).
+		Padding(1, 2).
+		Width(60).
+		Render(lipgloss.JoinVertical(lipgloss.Left, append([]string{title, ""}, rows...)...))
+
+	help := styles.HelpStyle.Render("[↑/↓]move  [←/→]change  [space]show/hide widget  [J/K]reorder widget  [ctrl+s]save  [esc]back")
+
+	content := lipgloss.JoinVertical(lipgloss.Left, box, "", help)
+	return lipgloss.Place(v.width, v.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+func (v *SettingsView) SetSize(width, height int) {
+	v.width = width
+	v.height = height
+}
+
+// row renders a labelled field with its inline error, if any
+func (v *SettingsView) row(label, value string, field int) string {
+	line := lipgloss.JoinHorizontal(lipgloss.Top, styles.FormLabelStyle.Width(14).Render(label), value)
+	if err, ok := v.fieldErrs[field]; ok {
+		line += "\n" + lipgloss.NewStyle().PaddingLeft(14).Render(styles.ErrorStyle.Render(err))
+	}
+	return line
+}
+
+func (v *SettingsView) choiceValue(field int, value string) string {
+	if v.focusIndex == field {
+		return styles.SelectedStyle.Render("◀ " + value + " ▶")
+	}
+	return value
+}
+
+// saveField is the focus index of the Save button, after the widget rows
+func (v *SettingsView) saveField() int {
+	return settingsFieldWidgets + len(v.widgets)
+}
+
+// focusedWidget returns the index of the focused widget row, or -1
+func (v *SettingsView) focusedWidget() int {
+	i := v.focusIndex - settingsFieldWidgets
+	if i < 0 || i >= len(v.widgets) {
+		return -1
+	}
+	return i
+}
+
+func (v *SettingsView) toggleWidget() {
+	i := v.focusedWidget()
+	v.widgets[i].Enabled = !v.widgets[i].Enabled
+}
+
+// moveWidget moves the focused widget up or down the dashboard order,
+// keeping it focused
+func (v *SettingsView) moveWidget(delta int) {
+	i := v.focusedWidget()
+	j := i + delta
+	if i < 0 || j < 0 || j >= len(v.widgets) {
+		return
+	}
+	v.widgets[i], v.widgets[j] = v.widgets[j], v.widgets[i]
+	v.focusIndex += delta
+}
+
+func (v *SettingsView) moveFocus(delta int) {
+	count := v.saveField() + 1
+	v.focusIndex = (v.focusIndex + delta + count) % count
+
+	if v.focusIndex == settingsFieldDecimalPlaces {
+		v.decimalPlaces.Focus()
+	} else {
+		v.decimalPlaces.Blur()
+	}
+	if v.focusIndex == settingsFieldTagPattern {
+		v.tagPattern.Focus()
+	} else {
+		v.tagPattern.Blur()
+	}
+	if v.focusIndex == settingsFieldMonthlyLimit {
+		v.monthlyLimit.Focus()
+	} else {
+		v.monthlyLimit.Blur()
+	}
+}
+
+// cycle steps the focused choice field through its options
+func (v *SettingsView) cycle(delta int) {
+	switch v.focusIndex {
+	case settingsFieldDateFormat:
+		v.dateFormat = nextChoice(models.DateFormatPresets, v.dateFormat, delta)
+		delete(v.fieldErrs, settingsFieldDateFormat)
+	case settingsFieldCurrency:
+		if len(v.currencies) > 0 {
+			v.currency = nextChoice(v.currencies, v.currency, delta)
+			delete(v.fieldErrs, settingsFieldCurrency)
+		}
+	case settingsFieldTheme:
+		v.theme = nextChoice(models.Themes, v.theme, delta)
+		delete(v.fieldErrs, settingsFieldTheme)
+	}
+}
+
+func (v *SettingsView) save() tea.Cmd {
+	v.fieldErrs = make(map[int]string)
+
+	// Start from the saved preferences so fields not on this screen survive
+	ui := v.settingsService.Get().UI
+	ui.DateFormat = v.dateFormat
+	ui.Theme = v.theme
+	ui.Dashboard = models.DashboardSettings{
+		Widgets: append([]models.DashboardWidget(nil), v.widgets...),
+	}
+
+	decimals, err := strconv.Atoi(strings.TrimSpace(v.decimalPlaces.Value()))
+	if err != nil || decimals < 0 || decimals > models.MaxDecimalPlaces {
+		v.fieldErrs[settingsFieldDecimalPlaces] = fmt.Sprintf("must be a whole number from 0 to %d", models.MaxDecimalPlaces)
+	}
+	ui.DecimalPlaces = decimals
+
+	ui.TagPattern = strings.TrimSpace(v.tagPattern.Value())
+	if ui.TagPattern != "" {
+		if _, err := regexp.Compile(ui.TagPattern); err != nil {
+			v.fieldErrs[settingsFieldTagPattern] = fmt.Sprintf("not a valid regular expression: %v", err)
+		}
+	}
+
+	var limit float64
+	if value := strings.TrimSpace(v.monthlyLimit.Value()); value != "" {
+		limit, err = strconv.ParseFloat(value, 64)
+		if err != nil || limit < 0 {
+			v.fieldErrs[settingsFieldMonthlyLimit] = "must be an amount of 0 or more, or empty for none"
+		}
+	}
+
+	if !models.IsKnownTheme(ui.Theme) {
+		v.fieldErrs[settingsFieldTheme] = fmt.Sprintf("unknown theme %q, pick one of: %s", ui.Theme, strings.Join(models.Themes, ", "))
+	}
+
+	if !models.IsDateLayout(ui.DateFormat) {
+		v.fieldErrs[settingsFieldDateFormat] = fmt.Sprintf("%q is not a valid date layout", ui.DateFormat)
+	}
+
+	if !v.settingsService.IsCurrencyEnabled(v.currency) {
+		v.fieldErrs[settingsFieldCurrency] = fmt.Sprintf("pick one of the enabled currencies: %s", strings.Join(v.currencies, ", "))
+	}
+
+	if len(v.fieldErrs) > 0 {
+		return nil
+	}
+
+	if err := v.settingsService.UpdatePreferences(ui, v.currency); err != nil {
+		v.fieldErrs[v.saveField()] = err.Error()
+		return nil
+	}
+	if err := v.settingsService.SetMonthlyLimit(limit); err != nil {
+		v.fieldErrs[v.saveField()] = err.Error()
+		return nil
+	}
+
+	v.message = "Settings saved"
+	return func() tea.Msg { return SettingsSavedMsg{UI: ui} }
+}
+
+// nextChoice returns the option delta steps from current, wrapping around.
+// A current value that isn't an option starts from the first one.
+func nextChoice(options []string, current string, delta int) string {
+	idx := -1
+	for i, option := range options {
+		if option == current {
+			idx = i
+			break
+		}
+	}
+	if idx == -1 {
+		return options[0]
+	}
+	return options[(idx+delta+len(options))%len(options)]
+}
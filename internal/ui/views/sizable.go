@@ -0,0 +1,24 @@
+package views
+
+// Sizable is implemented by every view the App can show, so it can hand
+// each one the window size before the view is displayed
+type Sizable interface {
+	SetSize(width, height int)
+}
+
+var (
+	_ Sizable = (*Dashboard)(nil)
+	_ Sizable = (*TransactionList)(nil)
+	_ Sizable = (*TransactionForm)(nil)
+	_ Sizable = (*TransactionDetail)(nil)
+	_ Sizable = (*BudgetList)(nil)
+	_ Sizable = (*BudgetForm)(nil)
+	_ Sizable = (*Reports)(nil)
+	_ Sizable = (*CategoryListModel)(nil)
+	_ Sizable = (*RecurringListModel)(nil)
+	_ Sizable = (*RecurringFormModel)(nil)
+	_ Sizable = (*CurrencySettings)(nil)
+	_ Sizable = (*SettingsView)(nil)
+	_ Sizable = (*AccountList)(nil)
+	_ Sizable = (*Welcome)(nil)
+)
@@ -0,0 +1,78 @@
+package views
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/models"
+	"burnwise/internal/service"
+	"burnwise/internal/ui/styles"
+)
+
+// digestListLimit caps the lines of each section of the startup digest
+const digestListLimit = 6
+
+// StartupDigest is shown over the dashboard on startup when something needs
+// attention. Any key dismisses it.
+type StartupDigest struct {
+	digest *models.StartupDigest
+}
+
+func NewStartupDigest(digest *models.StartupDigest) *StartupDigest {
+	return &StartupDigest{digest: digest}
+}
+
+func (d *StartupDigest) View() string {
+	rows := []string{styles.TitleStyle.Render("⚠️  Needs attention")}
+
+	var overBudget []string
+	for _, status := range d.digest.OverBudget {
+		overBudget = append(overBudget, fmt.Sprintf("%s: $%s of $%s (%.0f%%)",
+			status.Budget.Name, styles.FormatNumber(status.Spent), styles.FormatNumber(status.Budget.Amount), status.PercentUsed))
+	}
+	rows = append(rows, digestSection("🚨 Over budget", styles.ErrorStyle, overBudget)...)
+
+	var expiring []string
+	now := time.Now()
+	for _, rt := range d.digest.Expiring {
+		expiring = append(expiring, fmt.Sprintf("%s (%s) %s, on %s",
+			rt.Description, rt.Category.Name, endsIn(rt, now), styles.FormatDate(*rt.EndDate)))
+	}
+	rows = append(rows, digestSection(fmt.Sprintf("⏳ Recurring ending within %d days", service.DigestExpiringDays), styles.WarningStyle, expiring)...)
+
+	var generated []string
+	for _, tx := range d.digest.Generated {
+		generated = append(generated, fmt.Sprintf("%s  %s %s  %s",
+			styles.FormatDate(tx.Date), styles.FormatNumberIn(tx.Amount, tx.Currency), tx.Currency, tx.Description))
+	}
+	rows = append(rows, digestSection("🔄 Generated on startup", styles.SuccessStyle, generated)...)
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Warning).
+		Padding(1, 2).
+		Width(70).
+		Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+
+	return lipgloss.JoinVertical(lipgloss.Left, box, "", styles.HelpStyle.Render("Press any key to continue"))
+}
+
+// digestSection renders a titled list, or nothing when there is nothing in
+// it, with the lines past digestListLimit counted instead of shown
+func digestSection(title string, style lipgloss.Style, lines []string) []string {
+	if len(lines) == 0 {
+		return nil
+	}
+
+	section := []string{"", style.Bold(true).Render(fmt.Sprintf("%s (%d)", title, len(lines)))}
+	for i, line := range lines {
+		if i == digestListLimit {
+			section = append(section, styles.HelpStyle.Render(fmt.Sprintf("  … and %d more", len(lines)-digestListLimit)))
+			break
+		}
+		section = append(section, "  "+line)
+	}
+	return section
+}
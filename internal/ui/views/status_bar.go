@@ -0,0 +1,134 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/ui/styles"
+)
+
+// StatusMsg is a transient message for the status bar, such as the outcome
+// of a save. Views publish them instead of showing their own, and the bar
+// shows the last one with the time it was sent until
+// styles.MessageTimeout has passed.
+type StatusMsg struct {
+	Text    string
+	IsError bool
+}
+
+// statusInfo publishes text to the status bar
+func statusInfo(text string) tea.Cmd {
+	return func() tea.Msg {
+		return StatusMsg{Text: text}
+	}
+}
+
+// statusError publishes err to the status bar
+func statusError(err error) tea.Cmd {
+	return func() tea.Msg {
+		return StatusMsg{Text: err.Error(), IsError: true}
+	}
+}
+
+// statusClearedMsg clears the message it was sent for, unless a newer one
+// has replaced it since
+type statusClearedMsg struct{ id int }
+
+// StatusBar is the line below the active view, with the view's name, the
+// default currency, the recurring items due today, whether the database is
+// read-only and the last message
+type StatusBar struct {
+	width    int
+	view     string
+	currency string
+	dueToday int
+	readOnly bool
+
+	message StatusMsg
+	sentAt  time.Time
+	id      int // of the message shown, to match statusClearedMsg
+}
+
+func NewStatusBar() *StatusBar {
+	return &StatusBar{}
+}
+
+func (s *StatusBar) SetWidth(width int) {
+	s.width = width
+}
+
+// SetContext sets what the bar shows besides the message
+func (s *StatusBar) SetContext(view, currency string, dueToday int) {
+	s.view = view
+	s.currency = currency
+	s.dueToday = dueToday
+}
+
+// SetReadOnly sets whether the bar warns that changes can't be saved
+func (s *StatusBar) SetReadOnly(readOnly bool) {
+	s.readOnly = readOnly
+}
+
+// Show replaces the message and returns the command that clears it
+func (s *StatusBar) Show(msg StatusMsg) tea.Cmd {
+	s.message = msg
+	s.sentAt = time.Now()
+	s.id++
+	id := s.id
+	return tea.Tick(styles.MessageTimeout, func(time.Time) tea.Msg {
+		return statusClearedMsg{id: id}
+	})
+}
+
+// Update handles the bar's own messages, reporting whether msg was one
+func (s *StatusBar) Update(msg tea.Msg) bool {
+	cleared, ok := msg.(statusClearedMsg)
+	if !ok {
+		return false
+	}
+	if cleared.id == s.id {
+		s.message = StatusMsg{}
+	}
+	return true
+}
+
+func (s *StatusBar) View() string {
+	muted := lipgloss.NewStyle().Foreground(styles.Muted)
+
+	parts := []string{
+		lipgloss.NewStyle().Bold(true).Foreground(styles.Primary).Render(s.view),
+		muted.Render(s.currency),
+	}
+	if s.readOnly {
+		parts = append(parts, styles.WarningStyle.Render("read-only"))
+	}
+	switch s.dueToday {
+	case 0:
+	case 1:
+		parts = append(parts, styles.WarningStyle.Render("1 recurring due today"))
+	default:
+		parts = append(parts, styles.WarningStyle.Render(fmt.Sprintf("%d recurring due today", s.dueToday)))
+	}
+	left := " " + strings.Join(parts, muted.Render(" │ "))
+
+	var right string
+	if s.message.Text != "" {
+		text := "✅ " + s.message.Text
+		style := styles.SuccessStyle
+		if s.message.IsError {
+			text = "❌ " + s.message.Text
+			style = styles.ErrorStyle
+		}
+		right = muted.Render(s.sentAt.Format("15:04:05")+" ") + style.Render(text) + " "
+	}
+
+	gap := s.width - lipgloss.Width(left) - lipgloss.Width(right)
+	if gap < 1 {
+		gap = 1
+	}
+	return left + strings.Repeat(" ", gap) + right
+}
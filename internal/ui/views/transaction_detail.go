@@ -0,0 +1,253 @@
+package views
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/models"
+	"burnwise/internal/service"
+	"burnwise/internal/ui/styles"
+)
+
+// TransactionDetail is a read-only view of a single transaction
+type TransactionDetail struct {
+	width  int
+	height int
+
+	recurringService *service.RecurringTransactionService
+	txService        *service.TransactionService
+
+	tx   *models.Transaction
+	rule *models.RecurringTransaction
+	err  error
+
+	// How an income transaction is allocated, and the input editing it
+	allocations        []*models.IncomeAllocation
+	allocationErr      error
+	editingAllocations bool
+	allocationInput    textinput.Model
+
+	// Attachments whose files are gone, and the one x removes
+	missingAttachments      map[uint]bool
+	attachmentCursor        int
+	confirmRemoveAttachment bool
+}
+
+type TransactionDetailMsg struct{ Transaction *models.Transaction }
+type TransactionDetailClosedMsg struct{}
+type RecurringRuleViewMsg struct{ Rule *models.RecurringTransaction }
+
+func NewTransactionDetail(recurringService *service.RecurringTransactionService, txService *service.TransactionService) *TransactionDetail {
+	return &TransactionDetail{
+		recurringService: recurringService,
+		txService:        txService,
+	}
+}
+
+// SetTransaction shows tx and loads its recurring rule and allocations, if
+// any
+func (d *TransactionDetail) SetTransaction(tx *models.Transaction) tea.Cmd {
+	d.tx = tx
+	d.rule = nil
+	d.err = nil
+	d.allocations = nil
+	d.allocationErr = nil
+	d.editingAllocations = false
+	d.attachmentCursor = 0
+	d.confirmRemoveAttachment = false
+	d.checkAttachments()
+
+	var cmds []tea.Cmd
+	if tx.Type == models.TransactionTypeIncome {
+		cmds = append(cmds, d.loadAllocations())
+	}
+	if tx.RecurringTransactionID != nil {
+		ruleID := *tx.RecurringTransactionID
+		cmds = append(cmds, func() tea.Msg {
+			rule, err := d.recurringService.GetByID(context.Background(), ruleID)
+			return recurringRuleLoadedMsg{rule: rule, err: err}
+		})
+	}
+	return tea.Batch(cmds...)
+}
+
+func (d *TransactionDetail) Update(msg tea.Msg) (*TransactionDetail, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if d.editingAllocations {
+			return d, d.updateAllocationInput(msg)
+		}
+		if d.confirmRemoveAttachment {
+			return d, d.updateRemoveAttachment(msg)
+		}
+
+		switch msg.String() {
+		case "esc", "q":
+			return d, func() tea.Msg { return TransactionDetailClosedMsg{} }
+		case "e":
+			if d.tx.Type == models.TransactionTypeTransfer {
+				return d, nil
+			}
+			tx := d.tx
+			return d, func() tea.Msg { return TransactionEditMsg{Transaction: tx} }
+		case "r":
+			if d.rule != nil {
+				rule := d.rule
+				return d, func() tea.Msg { return RecurringRuleViewMsg{Rule: rule} }
+			}
+		case "o":
+			if original := d.tx.RefundOf; original != nil {
+				return d, func() tea.Msg { return TransactionDetailMsg{Transaction: original} }
+			}
+		case "a":
+			if d.tx.Type == models.TransactionTypeIncome {
+				return d, d.openAllocationInput()
+			}
+		case "up", "k":
+			d.moveAttachmentCursor(-1)
+		case "down", "j":
+			d.moveAttachmentCursor(1)
+		case "x":
+			if len(d.tx.Attachments) > 0 {
+				d.confirmRemoveAttachment = true
+			}
+		}
+
+	case recurringRuleLoadedMsg:
+		d.rule = msg.rule
+		d.err = msg.err
+
+	case allocationsLoadedMsg:
+		d.allocations = msg.allocations
+		d.allocationErr = msg.err
+	}
+
+	return d, nil
+}
+
+func (d *TransactionDetail) View() string {
+	if d.tx == nil {
+		return ""
+	}
+
+	tx := d.tx
+	title := styles.TitleStyle.Render("Transaction Details")
+
+	amountStyle := styles.ExpenseStyle
+	sign := "-"
+	if !tx.IsOutflow() {
+		amountStyle = styles.IncomeStyle
+		sign = "+"
+	}
+
+	rows := []string{
+		d.field("Date:", styles.FormatDate(tx.Date)),
+		d.field("Type:", tx.DisplayType()),
+		d.field("Category:", tx.CategoryLabel()),
+	}
+	for _, line := range tx.Splits {
+		rows = append(rows, d.field("", fmt.Sprintf("└ %-16s %s %s", truncateText(line.Category.Icon+" "+line.Category.Name, 16),
+			styles.FormatNumberIn(line.Amount, tx.Currency), tx.Currency)))
+	}
+	rows = append(rows,
+		d.field("Description:", tx.Description),
+		d.field("Amount:", amountStyle.Render(fmt.Sprintf("%s%s %s", sign, styles.FormatNumberIn(tx.Amount, tx.Currency), tx.Currency))),
+		d.field("USD Amount:", "$"+styles.FormatNumber(tx.AmountUSD)),
+	)
+	if tx.Account != nil {
+		rows = append(rows, d.field("Account:", fmt.Sprintf("%s (%s)", tx.Account.Name, tx.Account.Type)))
+	}
+	rows = append(rows, d.renderAttachments()...)
+	if original := tx.RefundOf; original != nil {
+		rows = append(rows, d.field("Refund of:", fmt.Sprintf("↩ %s · %s %s on %s", original.Description,
+			styles.FormatNumberIn(original.Amount, original.Currency), original.Currency, styles.FormatDate(original.Date))))
+	}
+	if tx.Type == models.TransactionTypeIncome {
+		rows = append(rows, "")
+		rows = append(rows, d.renderAllocations()...)
+	}
+	rows = append(rows,
+		"",
+		d.field("Recurring:", d.renderRule()),
+		"",
+		d.field("Created:", styles.FormatDate(tx.CreatedAt)+tx.CreatedAt.Format(" 15:04")),
+		d.field("Updated:", styles.FormatDate(tx.UpdatedAt)+tx.UpdatedAt.Format(" 15:04")),
+	)
+
+	help := "[e]dit"
+	if tx.Type == models.TransactionTypeTransfer {
+		help = "transfers can't be edited"
+	}
+	if d.rule != nil {
+		help += "  [r]ecurring rule"
+	}
+	if tx.RefundOf != nil {
+		help += "  [o]riginal"
+	}
+	if tx.Type == models.TransactionTypeIncome {
+		help += "  [a]llocate"
+	}
+	if len(tx.Attachments) > 1 {
+		help += "  [↑/↓] choose"
+	}
+	if len(tx.Attachments) > 0 {
+		help += "  [x] remove attachment"
+	}
+	help += "  [esc]back"
+	if d.editingAllocations {
+		help = "e.g. \"Taxes 1200, Savings 20%\"  [enter]save  [esc]cancel"
+	}
+	helpLine := styles.HelpStyle.Render(help)
+	if d.confirmRemoveAttachment {
+		helpLine = d.removeAttachmentPrompt()
+	}
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Primary).
+		Padding(1, 2).
+		Width(60).
+		Render(lipgloss.JoinVertical(
+			lipgloss.Left,
+			append([]string{title, ""}, rows...)...,
+		))
+
+	content := lipgloss.JoinVertical(lipgloss.Left, box, "", helpLine)
+	return lipgloss.Place(d.width, d.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+func (d *TransactionDetail) SetSize(width, height int) {
+	d.width = width
+	d.height = height
+}
+
+func (d *TransactionDetail) field(label, value string) string {
+	return lipgloss.JoinHorizontal(lipgloss.Top, styles.FormLabelStyle.Width(14).Render(label), value)
+}
+
+func (d *TransactionDetail) renderRule() string {
+	if d.tx.RecurringTransactionID == nil {
+		return lipgloss.NewStyle().Foreground(styles.Muted).Render("No (one-time)")
+	}
+	if d.err != nil {
+		return styles.ErrorStyle.Render(fmt.Sprintf("rule #%d unavailable: %v", *d.tx.RecurringTransactionID, d.err))
+	}
+	if d.rule == nil {
+		return "Loading..."
+	}
+
+	status := "active"
+	if !d.rule.IsActive {
+		status = "paused"
+	}
+	return fmt.Sprintf("%s (%s, %s)", d.rule.Description, d.rule.GetFrequencyDisplay(), status)
+}
+
+type recurringRuleLoadedMsg struct {
+	rule *models.RecurringTransaction
+	err  error
+}
@@ -0,0 +1,117 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/models"
+	"burnwise/internal/service"
+	"burnwise/internal/ui/styles"
+)
+
+type allocationsLoadedMsg struct {
+	allocations []*models.IncomeAllocation
+	err         error
+}
+
+func (d *TransactionDetail) loadAllocations() tea.Cmd {
+	id := d.tx.ID
+	return func() tea.Msg {
+		allocations, err := d.txService.GetAllocations(context.Background(), id)
+		return allocationsLoadedMsg{allocations: allocations, err: err}
+	}
+}
+
+// openAllocationInput starts editing the allocations as one line, filled
+// in with the current ones
+func (d *TransactionDetail) openAllocationInput() tea.Cmd {
+	input := textinput.New()
+	input.Placeholder = "Taxes 1200, Savings 20%"
+	input.CharLimit = 200
+	input.Width = 40
+
+	var parts []string
+	for _, allocation := range d.allocations {
+		parts = append(parts, allocation.Name+" "+strconv.FormatFloat(allocation.Amount, 'f', -1, 64))
+	}
+	input.SetValue(strings.Join(parts, ", "))
+
+	d.allocationInput = input
+	d.allocationErr = nil
+	d.editingAllocations = true
+	return d.allocationInput.Focus()
+}
+
+// updateAllocationInput handles a key while editing the allocations: enter
+// saves them, replacing the old ones, and esc cancels
+func (d *TransactionDetail) updateAllocationInput(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		d.editingAllocations = false
+		d.allocationErr = nil
+		return nil
+	case "enter":
+		allocations, err := service.ParseAllocations(d.allocationInput.Value(), d.tx)
+		if err == nil {
+			err = d.txService.SetAllocations(context.Background(), d.tx.ID, allocations)
+		}
+		if err != nil {
+			d.allocationErr = err
+			return nil
+		}
+		d.editingAllocations = false
+		return d.loadAllocations()
+	}
+
+	var cmd tea.Cmd
+	d.allocationInput, cmd = d.allocationInput.Update(msg)
+	return cmd
+}
+
+// renderAllocations lists where the income went, each part with its share,
+// followed by what is left unallocated
+func (d *TransactionDetail) renderAllocations() []string {
+	tx := d.tx
+	muted := lipgloss.NewStyle().Foreground(styles.Muted)
+
+	if d.editingAllocations {
+		rows := []string{d.field("Allocated:", styles.FormInputFocusedStyle.Render(d.allocationInput.View()))}
+		if d.allocationErr != nil {
+			rows = append(rows, d.field("", styles.ErrorStyle.Render(d.allocationErr.Error())))
+		}
+		return rows
+	}
+	if d.allocationErr != nil {
+		return []string{d.field("Allocated:", styles.ErrorStyle.Render(fmt.Sprintf("unavailable: %v", d.allocationErr)))}
+	}
+	if len(d.allocations) == 0 {
+		return []string{d.field("Allocated:", muted.Render("nothing ('a' to allocate)"))}
+	}
+
+	share := func(amount float64) string {
+		return fmt.Sprintf("%s %s %s", styles.FormatNumberIn(amount, tx.Currency), tx.Currency,
+			muted.Render(fmt.Sprintf("(%.0f%%)", amount/tx.Amount*100)))
+	}
+
+	var rows []string
+	allocated := 0.0
+	for i, allocation := range d.allocations {
+		label := ""
+		if i == 0 {
+			label = "Allocated:"
+		}
+		rows = append(rows, d.field(label, fmt.Sprintf("%-14s %s", truncateText(allocation.Name, 14), share(allocation.Amount))))
+		allocated += allocation.Amount
+	}
+	if rest := tx.Amount - allocated; rest >= 0.5*math.Pow10(-models.CurrencyDecimals(tx.Currency)) {
+		rows = append(rows, d.field("", muted.Render(fmt.Sprintf("%-14s ", "unallocated"))+share(rest)))
+	}
+	return rows
+}
@@ -0,0 +1,90 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"slices"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/ui/styles"
+)
+
+// checkAttachments notes which attachments are no longer where they were
+// attached from, once when the transaction is shown rather than on every
+// render
+func (d *TransactionDetail) checkAttachments() {
+	d.missingAttachments = make(map[uint]bool)
+	for _, attachment := range d.tx.Attachments {
+		// Files may have been moved or deleted since they were attached
+		if _, err := os.Stat(attachment.Path); err != nil {
+			d.missingAttachments[attachment.ID] = true
+		}
+	}
+}
+
+// moveAttachmentCursor chooses the attachment x removes, staying within
+// the list
+func (d *TransactionDetail) moveAttachmentCursor(delta int) {
+	d.attachmentCursor = max(0, min(d.attachmentCursor+delta, len(d.tx.Attachments)-1))
+}
+
+// updateRemoveAttachment handles a key while asking whether to remove the
+// chosen attachment: y removes it, n or esc keeps it
+func (d *TransactionDetail) updateRemoveAttachment(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "y", "Y":
+		d.confirmRemoveAttachment = false
+		return d.removeAttachment()
+	case "n", "N", "esc":
+		d.confirmRemoveAttachment = false
+	}
+	return nil
+}
+
+// removeAttachment detaches the chosen attachment from the transaction,
+// leaving the file itself alone
+func (d *TransactionDetail) removeAttachment() tea.Cmd {
+	attachment := d.tx.Attachments[d.attachmentCursor]
+	if err := d.txService.RemoveAttachment(context.Background(), attachment.ID); err != nil {
+		return statusError(err)
+	}
+	// The transaction may be shared with the list, which reloads on return
+	d.tx.Attachments = slices.Delete(slices.Clone(d.tx.Attachments), d.attachmentCursor, d.attachmentCursor+1)
+	d.moveAttachmentCursor(0)
+	return statusInfo("Removed attachment " + filepath.Base(attachment.Path))
+}
+
+// renderAttachments lists the attachments, marking missing files and, when
+// there are several, the one x removes
+func (d *TransactionDetail) renderAttachments() []string {
+	var rows []string
+	for i, attachment := range d.tx.Attachments {
+		label := ""
+		if i == 0 {
+			label = "Attachments:"
+		}
+		path := "📎 " + attachment.Path
+		if d.missingAttachments[attachment.ID] {
+			path = lipgloss.NewStyle().Foreground(styles.Muted).Render(path + " (missing)")
+		}
+		if len(d.tx.Attachments) > 1 {
+			marker := "  "
+			if i == d.attachmentCursor {
+				marker = "▸ "
+			}
+			path = marker + path
+		}
+		rows = append(rows, d.field(label, path))
+	}
+	return rows
+}
+
+// removeAttachmentPrompt asks whether to remove the chosen attachment
+func (d *TransactionDetail) removeAttachmentPrompt() string {
+	attachment := d.tx.Attachments[d.attachmentCursor]
+	return styles.WarningStyle.Render(fmt.Sprintf("Remove attachment %s? The file is kept. (y/n)", filepath.Base(attachment.Path)))
+}
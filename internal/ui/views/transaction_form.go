@@ -1,8 +1,9 @@
 package views
 
 import (
+	"context"
 	"fmt"
-	"strconv"
+	"math"
 	"time"
 
 	"github.com/charmbracelet/bubbles/textinput"
@@ -21,20 +22,68 @@ type TransactionForm struct {
 	txService       *service.TransactionService
 	categoryService *service.CategoryService
 	currencyService *service.CurrencyService
+	accountService  *service.AccountService
 	
 	editingTx       *models.Transaction
 	txType          models.TransactionType
+	isRefund        bool
+	refundOf        *models.Transaction // the expense a refund is linked to
 	amount          textinput.Model
 	currency        string
+	currencyChanged bool // picked by hand, so categories no longer change it
+	manualUSD       bool
+	amountUSD       textinput.Model
 	categoryID      uint
+	categoryChanged bool
+	suggestion      *models.Category
 	description     textinput.Model
 	date            textinput.Model
+	accountID       uint // 0 for no account
 	
 	categories      []*models.Category
+	accounts        []*models.Account
 	currencies      []string
 	
 	focusIndex      int
 	err             error
+	
+	discard discardGuard[transactionFormValues]
+	
+	// An unusual date, such as 2035 for 2025, is confirmed before saving
+	dateWarning   string
+	confirmedDate string
+	
+	// Picking the expense a refund gives money back for, opened with 'o'
+	pickingRefund bool
+	refundSearch  textinput.Model
+	refundMatches []*models.Transaction
+	refundCursor  int // 0 is "not linked", then the matches
+	refundErr     error
+	
+	// Splitting the transaction across categories, opened with 's' on the
+	// category. The transaction isn't split while there are no lines.
+	splits        []splitLine
+	editingSplits bool
+	splitsBefore  []splitLine // restored when the editor is cancelled
+	splitCursor   int
+	splitErr      error
+}
+
+// transactionFormValues are the form's editable values, compared with those
+// it was opened with to tell whether esc would lose anything
+type transactionFormValues struct {
+	txType      models.TransactionType
+	isRefund    bool
+	refundOfID  uint
+	amount      string
+	currency    string
+	manualUSD   bool
+	amountUSD   string
+	categoryID  uint
+	splits      string
+	description string
+	date        string
+	accountID   uint
 }
 
 type TransactionSavedMsg struct{}
@@ -46,9 +95,12 @@ func NewTransactionForm(
 	currencyService *service.CurrencyService,
 ) *TransactionForm {
 	amount := textinput.New()
-	amount.Placeholder = "0.00"
+	amount.Placeholder = "0.00 or 12.50 + 3.99 * 2"
 	amount.Focus()
 	
+	amountUSD := textinput.New()
+	amountUSD.Placeholder = "USD charged, incl. fees"
+	
 	description := textinput.New()
 	description.Placeholder = "Description"
 	
@@ -56,24 +108,34 @@ func NewTransactionForm(
 	date.Placeholder = "YYYY-MM-DD"
 	date.SetValue(time.Now().Format("2006-01-02"))
 	
-	return &TransactionForm{
+	f := &TransactionForm{
 		txService:       txService,
 		categoryService: categoryService,
 		currencyService: currencyService,
 		txType:          models.TransactionTypeExpense,
 		amount:          amount,
-		currency:        "USD",
+		currency:        currencyService.GetDefaultCurrency(),
+		amountUSD:       amountUSD,
 		description:     description,
 		date:            date,
 		currencies:      currencyService.GetSupportedCurrencies(),
 		focusIndex:      0,
 	}
+	f.discard.reset(f.values())
+	return f
+}
+
+// SetAccountService enables picking the account a transaction is paid from
+// or into. The field is only shown once an account exists.
+func (f *TransactionForm) SetAccountService(accountService *service.AccountService) {
+	f.accountService = accountService
 }
 
 func (f *TransactionForm) Init() tea.Cmd {
 	return tea.Batch(
 		textinput.Blink,
 		f.loadCategories,
+		f.loadAccounts,
 	)
 }
 
@@ -82,25 +144,66 @@ func (f *TransactionForm) Update(msg tea.Msg) (*TransactionForm, tea.Cmd) {
 	
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if f.discard.confirming {
+			if f.discard.answer(msg.String()) {
+				return f, func() tea.Msg { return TransactionCancelledMsg{} }
+			}
+			return f, nil
+		}
+		if f.dateWarning != "" {
+			switch msg.String() {
+			case "y", "Y":
+				f.confirmedDate = f.date.Value()
+				f.dateWarning = ""
+				return f, f.save
+			case "n", "N", "esc":
+				f.dateWarning = ""
+			}
+			return f, nil
+		}
+		if f.pickingRefund {
+			return f, f.updateRefundPicker(msg)
+		}
+		if f.editingSplits {
+			return f, f.updateSplitEditor(msg)
+		}
+		
 		switch msg.String() {
 		case "esc":
+			if !f.discard.canLeave(f.values()) {
+				return f, nil
+			}
 			return f, func() tea.Msg { return TransactionCancelledMsg{} }
 		case "tab", "shift+tab":
 			f.nextFocus(msg.String() == "shift+tab")
 		case "enter":
-			if f.focusIndex == 6 { // Save button
+			if f.focusIndex == 8 { // Save button
 				return f, f.save
-			} else if f.focusIndex == 7 { // Cancel button
+			} else if f.focusIndex == 9 { // Cancel button
 				return f, func() tea.Msg { return TransactionCancelledMsg{} }
 			}
 		case "t":
 			if f.focusIndex == 0 { // Type field
-				if f.txType == models.TransactionTypeExpense {
+				// Cycle expense -> refund -> income; refunds use expense categories
+				switch {
+				case f.txType == models.TransactionTypeExpense && !f.isRefund:
+					f.isRefund = true
+					return f, nil
+				case f.txType == models.TransactionTypeExpense:
 					f.txType = models.TransactionTypeIncome
-				} else {
+					f.isRefund = false
+					f.refundOf = nil
+				default:
 					f.txType = models.TransactionTypeExpense
 				}
-				return f, f.loadCategories
+				// The lines' categories were of the other type
+				f.splits = nil
+				f.suggestion = nil
+				return f, tea.Batch(f.loadCategories, f.suggestCategory(f.description.Value()))
+			}
+		case "o":
+			if f.focusIndex == 0 && f.isRefund {
+				return f, f.openRefundPicker()
 			}
 		case "c":
 			if f.focusIndex == 2 { // Currency field
@@ -112,17 +215,67 @@ func (f *TransactionForm) Update(msg tea.Msg) (*TransactionForm, tea.Cmd) {
 					}
 				}
 				f.currency = f.currencies[(currentIdx+1)%len(f.currencies)]
+				f.currencyChanged = true
+			}
+		case "m":
+			if f.focusIndex == 2 && f.currency != "USD" { // Currency field
+				f.toggleManualUSD()
+			}
+		case "s":
+			if f.focusIndex == 4 { // Category field
+				return f, f.openSplitEditor()
 			}
 		case "up", "down":
-			if f.focusIndex == 3 { // Category field
+			if f.focusIndex == 4 && len(f.splits) == 0 { // Category field
 				f.cycleCategory(msg.String() == "up")
+				f.categoryChanged = true
+				f.applyCategoryCurrency()
+			} else if f.focusIndex == 7 { // Account field
+				f.cycleAccount(msg.String() == "up")
+			}
+		case "ctrl+a":
+			if suggestion := f.visibleSuggestion(); suggestion != nil {
+				f.categoryID = suggestion.ID
+				f.categoryChanged = true
+				f.applyCategoryCurrency()
+				return f, nil
 			}
 		}
 		
 	case categoriesLoadedMsg:
 		f.categories = msg.categories
-		if len(f.categories) > 0 {
-			f.categoryID = f.categories[0].ID
+		// Keep the selected category if it suits the type, e.g. when
+		// editing; otherwise fall back to the first one
+		if !f.hasCategory(f.categoryID) {
+			f.categoryID = 0
+			if len(f.categories) > 0 {
+				f.categoryID = f.categories[0].ID
+			}
+		}
+		f.applyCategoryCurrency()
+		// A new form's default category, and its currency, are not a change
+		if f.discard.initial.categoryID == 0 {
+			f.discard.initial.categoryID = f.categoryID
+			f.discard.initial.currency = f.currency
+		}
+		
+	case accountsLoadedMsg:
+		f.accounts = msg.accounts
+		
+	case refundableFoundMsg:
+		// Ignore answers for a search that has since been edited
+		if f.pickingRefund && msg.query == f.refundSearch.Value() {
+			f.refundMatches = msg.matches
+			f.refundErr = msg.err
+			if f.refundCursor > len(f.refundMatches) {
+				f.refundCursor = len(f.refundMatches)
+			}
+		}
+		
+	case categorySuggestedMsg:
+		// Ignore answers for a description or type that has since changed
+		if msg.description == f.description.Value() && msg.txType == f.txType {
+			f.suggestion = msg.category
 		}
 	}
 	
@@ -130,8 +283,15 @@ func (f *TransactionForm) Update(msg tea.Msg) (*TransactionForm, tea.Cmd) {
 	f.amount, cmd = f.amount.Update(msg)
 	cmds = append(cmds, cmd)
 	
+	f.amountUSD, cmd = f.amountUSD.Update(msg)
+	cmds = append(cmds, cmd)
+	
+	description := f.description.Value()
 	f.description, cmd = f.description.Update(msg)
 	cmds = append(cmds, cmd)
+	if f.description.Value() != description {
+		cmds = append(cmds, f.suggestCategory(f.description.Value()))
+	}
 	
 	f.date, cmd = f.date.Update(msg)
 	cmds = append(cmds, cmd)
@@ -147,9 +307,13 @@ func (f *TransactionForm) View() string {
 	title = styles.TitleStyle.Render(title)
 	
 	typeLabel := styles.FormLabelStyle.Render("Type:")
+	typeText := string(f.txType)
+	if f.isRefund {
+		typeText = "refund (reduces expenses)"
+	}
 	typeValue := lipgloss.NewStyle().
 		Foreground(f.getTypeColor()).
-		Render(string(f.txType))
+		Render(typeText)
 	if f.focusIndex == 0 {
 		typeValue = styles.SelectedStyle.Render(typeValue + " (press 't' to toggle)")
 	}
@@ -161,11 +325,41 @@ func (f *TransactionForm) View() string {
 	} else {
 		amountInput = styles.FormInputStyle.Render(amountInput)
 	}
+	amountRow := lipgloss.JoinHorizontal(lipgloss.Top, amountLabel, amountInput)
+	if expr := f.amount.Value(); service.IsAmountExpression(expr) {
+		// Show what the typed expression comes to, or why it doesn't parse yet
+		hint := styles.HelpStyle.Render("= ?")
+		if value, err := service.EvalAmount(expr); err == nil {
+			hint = styles.HelpStyle.Render("= " + styles.FormatNumber(value))
+		}
+		amountRow = lipgloss.JoinVertical(lipgloss.Left, amountRow,
+			lipgloss.JoinHorizontal(lipgloss.Top, styles.FormLabelStyle.Render(""), hint))
+	}
 	
 	currencyLabel := styles.FormLabelStyle.Render("Currency:")
 	currencyValue := f.currency
 	if f.focusIndex == 2 {
-		currencyValue = styles.SelectedStyle.Render(currencyValue + " (press 'c' to change)")
+		hint := " (press 'c' to change)"
+		if f.currency != "USD" {
+			hint = " ('c' change, 'm' enter USD)"
+		}
+		currencyValue = styles.SelectedStyle.Render(currencyValue + hint)
+	}
+	
+	var usdRow string
+	if f.showUSDField() {
+		usdInput := f.amountUSD.View()
+		if f.focusIndex == 3 {
+			usdInput = styles.FormInputFocusedStyle.Render(usdInput)
+		} else {
+			usdInput = styles.FormInputStyle.Render(usdInput)
+		}
+		usdRow = lipgloss.JoinHorizontal(lipgloss.Top, styles.FormLabelStyle.Render("USD:"), usdInput)
+	}
+	if usdRow == "" && f.editingTx != nil && f.editingTx.Currency == f.currency && f.editingTx.RateSource != "" {
+		// How the saved USD amount was converted, kept out of the way
+		hint := styles.HelpStyle.Render(fmt.Sprintf("$%s %s", styles.FormatNumber(f.editingTx.AmountUSD), f.editingTx.RateSource.Describe()))
+		usdRow = lipgloss.JoinHorizontal(lipgloss.Top, styles.FormLabelStyle.Render(""), hint)
 	}
 	
 	categoryLabel := styles.FormLabelStyle.Render("Category:")
@@ -174,17 +368,28 @@ func (f *TransactionForm) View() string {
 		for _, cat := range f.categories {
 			if cat.ID == f.categoryID {
 				categoryValue = fmt.Sprintf("%s %s", cat.Icon, cat.Name)
+				if cat.IsPinned {
+					categoryValue += " 📌"
+				}
 				break
 			}
 		}
 	}
-	if f.focusIndex == 3 {
-		categoryValue = styles.SelectedStyle.Render(categoryValue + " (↑/↓)")
+	if f.focusIndex == 4 {
+		categoryValue = styles.SelectedStyle.Render(categoryValue + " (↑/↓, 's' split)")
+	}
+	categoryRow := lipgloss.JoinHorizontal(lipgloss.Top, categoryLabel, categoryValue)
+	if len(f.splits) > 0 {
+		categoryRow = lipgloss.JoinVertical(lipgloss.Left, f.renderSplitRows()...)
+	} else if suggestion := f.visibleSuggestion(); suggestion != nil {
+		hint := styles.HelpStyle.Render(fmt.Sprintf("Suggested: %s %s (ctrl+a to use)", suggestion.Icon, suggestion.Name))
+		categoryRow = lipgloss.JoinVertical(lipgloss.Left, categoryRow,
+			lipgloss.JoinHorizontal(lipgloss.Top, styles.FormLabelStyle.Render(""), hint))
 	}
 	
 	descLabel := styles.FormLabelStyle.Render("Description:")
 	descInput := f.description.View()
-	if f.focusIndex == 4 {
+	if f.focusIndex == 5 {
 		descInput = styles.FormInputFocusedStyle.Render(descInput)
 	} else {
 		descInput = styles.FormInputStyle.Render(descInput)
@@ -192,20 +397,34 @@ func (f *TransactionForm) View() string {
 	
 	dateLabel := styles.FormLabelStyle.Render("Date:")
 	dateInput := f.date.View()
-	if f.focusIndex == 5 {
+	if f.focusIndex == 6 {
 		dateInput = styles.FormInputFocusedStyle.Render(dateInput)
 	} else {
 		dateInput = styles.FormInputStyle.Render(dateInput)
 	}
 	
+	var accountRow string
+	if f.showAccountField() {
+		accountValue := "none"
+		for _, account := range f.accounts {
+			if account.ID == f.accountID {
+				accountValue = fmt.Sprintf("%s (%s)", account.Name, account.Type)
+			}
+		}
+		if f.focusIndex == 7 {
+			accountValue = styles.SelectedStyle.Render(accountValue + " (↑/↓)")
+		}
+		accountRow = lipgloss.JoinHorizontal(lipgloss.Top, styles.FormLabelStyle.Render("Account:"), accountValue)
+	}
+	
 	saveButton := "[Save]"
 	cancelButton := "[Cancel]"
-	if f.focusIndex == 6 {
+	if f.focusIndex == 8 {
 		saveButton = styles.ButtonStyle.Render(saveButton)
 	} else {
 		saveButton = styles.ButtonInactiveStyle.Render(saveButton)
 	}
-	if f.focusIndex == 7 {
+	if f.focusIndex == 9 {
 		cancelButton = styles.ButtonStyle.Render(cancelButton)
 	} else {
 		cancelButton = styles.ButtonInactiveStyle.Render(cancelButton)
@@ -218,21 +437,48 @@ func (f *TransactionForm) View() string {
 		cancelButton,
 	)
 	
-	form := lipgloss.JoinVertical(
-		lipgloss.Left,
+	rows := []string{
 		lipgloss.JoinHorizontal(lipgloss.Top, typeLabel, typeValue),
-		lipgloss.JoinHorizontal(lipgloss.Top, amountLabel, amountInput),
+	}
+	if f.isRefund {
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, styles.FormLabelStyle.Render("Refund of:"), f.renderRefundOf()))
+	}
+	rows = append(rows,
+		amountRow,
 		lipgloss.JoinHorizontal(lipgloss.Top, currencyLabel, currencyValue),
-		lipgloss.JoinHorizontal(lipgloss.Top, categoryLabel, categoryValue),
+	)
+	if usdRow != "" {
+		rows = append(rows, usdRow)
+	}
+	rows = append(rows,
+		categoryRow,
 		lipgloss.JoinHorizontal(lipgloss.Top, descLabel, descInput),
 		lipgloss.JoinHorizontal(lipgloss.Top, dateLabel, dateInput),
+	)
+	if accountRow != "" {
+		rows = append(rows, accountRow)
+	}
+	rows = append(rows,
 		"",
 		buttons,
 	)
+	form := lipgloss.JoinVertical(lipgloss.Left, rows...)
+	if f.pickingRefund {
+		form += "\n\n" + f.renderRefundPicker()
+	}
+	if f.editingSplits {
+		form += "\n\n" + f.renderSplitEditor()
+	}
 	
 	if f.err != nil {
 		form += "\n\n" + styles.ErrorStyle.Render(fmt.Sprintf("Error: %v", f.err))
 	}
+	if discard := f.discard.view(); discard != "" {
+		form += "\n\n" + discard
+	}
+	if f.dateWarning != "" {
+		form += "\n\n" + styles.WarningStyle.Render(f.dateWarning+" — save anyway? (y/n)")
+	}
 	
 	box := lipgloss.NewStyle().
 		Border(lipgloss.RoundedBorder()).
@@ -252,54 +498,189 @@ func (f *TransactionForm) SetSize(width, height int) {
 func (f *TransactionForm) Reset() {
 	f.editingTx = nil
 	f.txType = models.TransactionTypeExpense
+	f.isRefund = false
+	f.refundOf = nil
+	f.pickingRefund = false
 	f.amount.SetValue("")
-	f.currency = "USD"
+	f.currency = f.currencyService.GetDefaultCurrency()
+	f.currencyChanged = false
+	f.manualUSD = false
+	f.amountUSD.SetValue("")
 	f.categoryID = 0
+	f.categoryChanged = false
+	f.suggestion = nil
+	f.splits = nil
+	f.editingSplits = false
 	f.description.SetValue("")
 	f.date.SetValue(time.Now().Format("2006-01-02"))
+	f.accountID = 0
 	f.focusIndex = 0
 	f.err = nil
+	f.discard.reset(f.values())
+	f.dateWarning = ""
+	f.confirmedDate = ""
 }
 
 func (f *TransactionForm) SetTransaction(tx *models.Transaction) {
 	f.editingTx = tx
 	f.txType = tx.Type
-	f.amount.SetValue(fmt.Sprintf("%.2f", tx.Amount))
+	f.isRefund = tx.IsRefund
+	f.refundOf = tx.RefundOf
+	f.pickingRefund = false
+	f.amount.SetValue(fmt.Sprintf("%.*f", models.CurrencyDecimals(tx.Currency), tx.Amount))
 	f.currency = tx.Currency
+	f.currencyChanged = true // keep the transaction's own currency
+	f.manualUSD = tx.ManualUSD
+	f.amountUSD.SetValue("")
+	if tx.ManualUSD {
+		f.amountUSD.SetValue(fmt.Sprintf("%.2f", tx.AmountUSD))
+	}
 	f.categoryID = tx.CategoryID
+	f.categoryChanged = false
+	f.suggestion = nil
+	f.setSplits(tx)
+	f.editingSplits = false
 	f.description.SetValue(tx.Description)
 	f.date.SetValue(tx.Date.Format("2006-01-02"))
+	f.accountID = 0
+	if tx.AccountID != nil {
+		f.accountID = *tx.AccountID
+	}
 	f.focusIndex = 0
 	f.err = nil
+	f.discard.reset(f.values())
+	f.dateWarning = ""
+	f.confirmedDate = ""
+}
+
+// Prefill fills the form for a new transaction with tx's values, e.g. those
+// understood from the dashboard's quick add. Leaving the form asks before
+// discarding them.
+func (f *TransactionForm) Prefill(tx *models.Transaction) {
+	f.Reset()
+	initial := f.discard.initial
+	f.SetTransaction(tx)
+	f.editingTx = nil
+	f.currencyChanged = tx.Currency != f.currencyService.GetDefaultCurrency()
+	f.discard.initial = initial
+}
+
+func (f *TransactionForm) values() transactionFormValues {
+	var refundOfID uint
+	if f.refundOf != nil {
+		refundOfID = f.refundOf.ID
+	}
+	return transactionFormValues{
+		txType:      f.txType,
+		isRefund:    f.isRefund,
+		refundOfID:  refundOfID,
+		amount:      f.amount.Value(),
+		currency:    f.currency,
+		manualUSD:   f.manualUSD,
+		amountUSD:   f.amountUSD.Value(),
+		categoryID:  f.categoryID,
+		splits:      f.splitsValue(),
+		description: f.description.Value(),
+		date:        f.date.Value(),
+		accountID:   f.accountID,
+	}
 }
 
 func (f *TransactionForm) nextFocus(reverse bool) {
+	// Show what an expression typed into an amount comes to
+	switch f.focusIndex {
+	case 1:
+		resolveAmount(&f.amount, models.CurrencyDecimals(f.currency))
+	case 3:
+		resolveAmount(&f.amountUSD, 2)
+	}
+	
 	if reverse {
 		f.focusIndex--
+		if f.focusIndex == 7 && !f.showAccountField() {
+			f.focusIndex--
+		}
+		if f.focusIndex == 3 && !f.showUSDField() {
+			f.focusIndex--
+		}
 		if f.focusIndex < 0 {
-			f.focusIndex = 7
+			f.focusIndex = 9
 		}
 	} else {
 		f.focusIndex++
-		if f.focusIndex > 7 {
+		if f.focusIndex == 3 && !f.showUSDField() {
+			f.focusIndex++
+		}
+		if f.focusIndex == 7 && !f.showAccountField() {
+			f.focusIndex++
+		}
+		if f.focusIndex > 9 {
 			f.focusIndex = 0
 		}
 	}
 	
 	f.amount.Blur()
+	f.amountUSD.Blur()
 	f.description.Blur()
 	f.date.Blur()
 	
 	switch f.focusIndex {
 	case 1:
 		f.amount.Focus()
-	case 4:
-		f.description.Focus()
+	case 3:
+		f.amountUSD.Focus()
 	case 5:
+		f.description.Focus()
+	case 6:
 		f.date.Focus()
 	}
 }
 
+// showUSDField reports whether the USD amount is being entered by hand,
+// which only applies to foreign-currency transactions
+func (f *TransactionForm) showUSDField() bool {
+	return f.manualUSD && f.currency != "USD"
+}
+
+// showAccountField reports whether there are accounts to pick from, or the
+// transaction is assigned to one
+func (f *TransactionForm) showAccountField() bool {
+	return len(f.accounts) > 0 || f.accountID != 0
+}
+
+// cycleAccount steps through no account and then each account
+func (f *TransactionForm) cycleAccount(reverse bool) {
+	ids := []uint{0}
+	current := 0
+	for i, account := range f.accounts {
+		ids = append(ids, account.ID)
+		if account.ID == f.accountID {
+			current = i + 1
+		}
+	}
+	
+	step := 1
+	if reverse {
+		step = len(ids) - 1
+	}
+	f.accountID = ids[(current+step)%len(ids)]
+}
+
+// toggleManualUSD switches between converting the amount to USD at the
+// current rate and entering the USD amount by hand. The field starts out
+// with the converted amount so only the fees need adjusting.
+func (f *TransactionForm) toggleManualUSD() {
+	f.manualUSD = !f.manualUSD
+	if !f.manualUSD || f.amountUSD.Value() != "" {
+		return
+	}
+	if amount, err := service.EvalAmount(f.amount.Value()); err == nil {
+		if amountUSD, err := f.currencyService.ConvertToUSD(context.Background(), amount, f.currency); err == nil {
+			f.amountUSD.SetValue(fmt.Sprintf("%.2f", amountUSD))
+		}
+	}
+}
+
 func (f *TransactionForm) cycleCategory(reverse bool) {
 	if len(f.categories) == 0 {
 		return
@@ -328,36 +709,151 @@ func (f *TransactionForm) cycleCategory(reverse bool) {
 	f.categoryID = f.categories[currentIdx].ID
 }
 
+// applyCategoryCurrency switches the currency to the selected category's
+// default currency, or the settings' default when it has none, unless the
+// currency was picked by hand
+func (f *TransactionForm) applyCategoryCurrency() {
+	if f.currencyChanged {
+		return
+	}
+	currency := f.currencyService.GetDefaultCurrency()
+	for _, cat := range f.categories {
+		if cat.ID == f.categoryID && cat.DefaultCurrency != "" && f.currencyService.IsSupported(cat.DefaultCurrency) {
+			currency = cat.DefaultCurrency
+		}
+	}
+	if currency != f.currency {
+		f.currency = currency
+		f.manualUSD = false
+	}
+}
+
+// hasCategory reports whether id is one of the loaded categories, which
+// are those of the selected transaction type
+func (f *TransactionForm) hasCategory(id uint) bool {
+	for _, cat := range f.categories {
+		if cat.ID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestCategory looks up the category usually used with a description
+// for the selected transaction type
+func (f *TransactionForm) suggestCategory(description string) tea.Cmd {
+	txType := f.txType
+	return func() tea.Msg {
+		category, err := f.txService.SuggestCategory(context.Background(), description, txType)
+		if err != nil {
+			// A suggestion is only a convenience, so failures are not shown
+			return nil
+		}
+		return categorySuggestedMsg{description: description, txType: txType, category: category}
+	}
+}
+
+// visibleSuggestion returns the suggested category if it is worth offering:
+// it fits the transaction type, differs from the selection, and the user
+// hasn't picked a category themselves in this form
+func (f *TransactionForm) visibleSuggestion() *models.Category {
+	if f.suggestion == nil || f.categoryChanged || len(f.splits) > 0 {
+		return nil
+	}
+	if f.suggestion.ID == f.categoryID || !f.hasCategory(f.suggestion.ID) {
+		return nil
+	}
+	return f.suggestion
+}
+
 func (f *TransactionForm) getTypeColor() lipgloss.Color {
-	if f.txType == models.TransactionTypeIncome {
+	if f.txType == models.TransactionTypeIncome || f.isRefund {
 		return styles.Income
 	}
 	return styles.Expense
 }
 
 func (f *TransactionForm) save() tea.Msg {
-	amount, err := strconv.ParseFloat(f.amount.Value(), 64)
+	amount, err := service.ParseAmount(f.amount.Value())
 	if err != nil {
-		f.err = fmt.Errorf("invalid amount")
+		f.err = fmt.Errorf("invalid amount: %w", err)
 		return nil
 	}
+	// Expressions like "10 / 3" are rounded to cents
+	amount = math.Round(amount*100) / 100
 	
 	date, err := time.Parse("2006-01-02", f.date.Value())
 	if err != nil {
 		f.err = fmt.Errorf("invalid date format")
 		return nil
 	}
+	if f.date.Value() != f.confirmedDate {
+		if warning := models.DateWarning(date, time.Now()); warning != "" {
+			f.err = nil
+			f.dateWarning = warning
+			return nil
+		}
+	}
+	
+	if !f.hasCategory(f.categoryID) {
+		f.err = fmt.Errorf("select a %s category", f.txType)
+		return nil
+	}
+	
+	manualUSD := f.showUSDField()
+	var amountUSD float64
+	if manualUSD {
+		amountUSD, err = service.EvalAmount(f.amountUSD.Value())
+		if err != nil {
+			f.err = fmt.Errorf("invalid USD amount: %w", err)
+			return nil
+		}
+		if amountUSD <= 0 {
+			f.err = fmt.Errorf("USD amount must be positive")
+			return nil
+		}
+		amountUSD = math.Round(amountUSD*100) / 100
+	}
+	
+	splits, err := f.splitItems()
+	if err != nil {
+		f.err = fmt.Errorf("invalid split: %w", err)
+		return nil
+	}
+	
+	var refundOfID *uint
+	if f.isRefund && f.refundOf != nil {
+		id := f.refundOf.ID
+		refundOfID = &id
+	}
+	
+	var accountID *uint
+	if f.accountID != 0 {
+		id := f.accountID
+		accountID = &id
+	}
 	
 	if f.editingTx != nil {
 		// Update existing transaction
 		f.editingTx.Type = f.txType
+		f.editingTx.IsRefund = f.isRefund
+		f.editingTx.RefundOfID = refundOfID
 		f.editingTx.Amount = amount
 		f.editingTx.Currency = f.currency
+		f.editingTx.ManualUSD = manualUSD
+		if manualUSD {
+			f.editingTx.AmountUSD = amountUSD
+		}
 		f.editingTx.CategoryID = f.categoryID
 		f.editingTx.Description = f.description.Value()
 		f.editingTx.Date = date
+		f.editingTx.AccountID = accountID
+		if splits != nil || f.editingTx.IsSplit() {
+			// An empty, rather than nil, list removes the lines
+			f.editingTx.Splits = append([]models.SplitItem{}, splits...)
+		}
 		
-		if err := f.txService.Update(f.editingTx); err != nil {
+		if err := f.txService.Update(context.Background(), f.editingTx); err != nil {
 			f.err = err
 			return nil
 		}
@@ -365,14 +861,20 @@ func (f *TransactionForm) save() tea.Msg {
 		// Create new transaction
 		tx := &models.Transaction{
 			Type:        f.txType,
+			IsRefund:    f.isRefund,
+			RefundOfID:  refundOfID,
 			Amount:      amount,
 			Currency:    f.currency,
+			AmountUSD:   amountUSD,
+			ManualUSD:   manualUSD,
 			CategoryID:  f.categoryID,
 			Description: f.description.Value(),
 			Date:        date,
+			AccountID:   accountID,
+			Splits:      splits,
 		}
 		
-		if err := f.txService.Create(tx); err != nil {
+		if err := f.txService.Create(context.Background(), tx); err != nil {
 			f.err = err
 			return nil
 		}
@@ -382,10 +884,28 @@ func (f *TransactionForm) save() tea.Msg {
 }
 
 func (f *TransactionForm) loadCategories() tea.Msg {
-	categories, _ := f.categoryService.GetByType(f.txType)
+	categories, _ := f.categoryService.GetPickable(context.Background(), f.txType, f.categoryID)
 	return categoriesLoadedMsg{categories: categories}
 }
 
+func (f *TransactionForm) loadAccounts() tea.Msg {
+	if f.accountService == nil {
+		return accountsLoadedMsg{}
+	}
+	accounts, _ := f.accountService.GetAll(context.Background())
+	return accountsLoadedMsg{accounts: accounts}
+}
+
+type accountsLoadedMsg struct {
+	accounts []*models.Account
+}
+
 type categoriesLoadedMsg struct {
 	categories []*models.Category
+}
+
+type categorySuggestedMsg struct {
+	description string
+	txType      models.TransactionType
+	category    *models.Category
 }
\ No newline at end of file
@@ -0,0 +1,151 @@
+package views
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/models"
+	"burnwise/internal/ui/styles"
+)
+
+// refundPickerLimit is how many matching expenses the picker lists
+const refundPickerLimit = 5
+
+// openRefundPicker opens the search for the expense a refund gives money
+// back for, starting with the most recent expenses
+func (f *TransactionForm) openRefundPicker() tea.Cmd {
+	search := textinput.New()
+	search.Placeholder = "description or amount"
+	search.Width = 24
+	f.refundSearch = search
+	f.refundMatches = nil
+	f.refundCursor = 0
+	f.pickingRefund = true
+	return tea.Batch(f.refundSearch.Focus(), f.findRefundable(""))
+}
+
+// updateRefundPicker handles a key while the picker is open: ↑/↓ move
+// through "not linked" and the matches, enter picks and esc closes
+func (f *TransactionForm) updateRefundPicker(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		f.pickingRefund = false
+		return nil
+	case "up":
+		if f.refundCursor > 0 {
+			f.refundCursor--
+		}
+		return nil
+	case "down":
+		if f.refundCursor < len(f.refundMatches) {
+			f.refundCursor++
+		}
+		return nil
+	case "enter":
+		f.pickingRefund = false
+		if f.refundCursor == 0 {
+			f.refundOf = nil
+			return nil
+		}
+		f.linkRefund(f.refundMatches[f.refundCursor-1])
+		return nil
+	}
+
+	query := f.refundSearch.Value()
+	var cmd tea.Cmd
+	f.refundSearch, cmd = f.refundSearch.Update(msg)
+	if f.refundSearch.Value() != query {
+		cmd = tea.Batch(cmd, f.findRefundable(f.refundSearch.Value()))
+	}
+	return cmd
+}
+
+// linkRefund links the refund to original and files it the same way
+func (f *TransactionForm) linkRefund(original *models.Transaction) {
+	f.refundOf = original
+	f.categoryID = original.CategoryID
+	f.categoryChanged = true
+	if f.currency != original.Currency {
+		f.currency = original.Currency
+		f.manualUSD = false
+	}
+	f.currencyChanged = true
+	if f.description.Value() == "" {
+		f.description.SetValue(original.Description + " refund")
+	}
+}
+
+func (f *TransactionForm) findRefundable(query string) tea.Cmd {
+	return func() tea.Msg {
+		matches, err := f.txService.FindRefundable(context.Background(), query, refundPickerLimit)
+		return refundableFoundMsg{query: query, matches: matches, err: err}
+	}
+}
+
+// renderRefundOf is the "Refund of" row's value: the linked expense, or a
+// hint on linking one when the type is focused
+func (f *TransactionForm) renderRefundOf() string {
+	if f.refundOf == nil {
+		hint := "not linked"
+		if f.focusIndex == 0 {
+			hint += " ('o' to link)"
+		}
+		return lipgloss.NewStyle().Foreground(styles.Muted).Render(hint)
+	}
+
+	value := "↩ " + describeRefundable(f.refundOf)
+	if f.focusIndex == 0 {
+		value += styles.HelpStyle.Render(" ('o' to change)")
+	}
+	return value
+}
+
+func (f *TransactionForm) renderRefundPicker() string {
+	lines := []string{
+		lipgloss.NewStyle().Bold(true).Render("Refund of"),
+		styles.FormInputFocusedStyle.Render(f.refundSearch.View()),
+	}
+
+	options := []string{"(not linked)"}
+	for _, match := range f.refundMatches {
+		options = append(options, describeRefundable(match))
+	}
+	for i, option := range options {
+		if i == f.refundCursor {
+			option = styles.SelectedStyle.Render("▸ " + option)
+		} else {
+			option = "  " + option
+		}
+		lines = append(lines, option)
+	}
+	if len(f.refundMatches) == 0 {
+		lines = append(lines, lipgloss.NewStyle().Foreground(styles.Muted).Render("  no matching expenses"))
+	}
+	if f.refundErr != nil {
+		lines = append(lines, styles.ErrorStyle.Render(f.refundErr.Error()))
+	}
+	lines = append(lines, styles.HelpStyle.Render("[↑/↓]move  [enter]link  [esc]close"))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
+
+// describeRefundable sums up an expense for picking it as the original of a
+// refund, e.g. "Jacket · 367.25 AED on 2025-03-02"
+func describeRefundable(tx *models.Transaction) string {
+	description := strings.TrimSpace(tx.Description)
+	if description == "" {
+		description = tx.Category.Name
+	}
+	return fmt.Sprintf("%s · %s %s on %s", truncateText(description, 20),
+		styles.FormatNumberIn(tx.Amount, tx.Currency), tx.Currency, styles.FormatDate(tx.Date))
+}
+
+type refundableFoundMsg struct {
+	query   string
+	matches []*models.Transaction
+	err     error
+}
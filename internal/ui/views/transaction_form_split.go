@@ -0,0 +1,243 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/models"
+	"burnwise/internal/service"
+	"burnwise/internal/ui/styles"
+)
+
+// splitLine is one line being entered for a split transaction. The last
+// line's amount may be left empty for it to take what the others leave.
+type splitLine struct {
+	categoryID uint
+	amount     textinput.Model
+}
+
+func newSplitLine(categoryID uint, amount string) splitLine {
+	input := textinput.New()
+	input.Placeholder = "amount"
+	input.Width = 12
+	input.SetValue(amount)
+	return splitLine{categoryID: categoryID, amount: input}
+}
+
+// setSplits fills the split lines in from a saved split transaction
+func (f *TransactionForm) setSplits(tx *models.Transaction) {
+	f.splits = nil
+	for _, line := range tx.Splits {
+		f.splits = append(f.splits, newSplitLine(line.CategoryID,
+			fmt.Sprintf("%.*f", models.CurrencyDecimals(tx.Currency), line.Amount)))
+	}
+}
+
+// openSplitEditor starts splitting the transaction across categories, or
+// editing its lines. A new split starts with the selected category and the
+// one after it, the second taking whatever the first leaves.
+func (f *TransactionForm) openSplitEditor() tea.Cmd {
+	if len(f.categories) == 0 {
+		return nil
+	}
+	f.splitsBefore = append([]splitLine(nil), f.splits...)
+	if len(f.splits) == 0 {
+		f.splits = []splitLine{
+			newSplitLine(f.categoryID, ""),
+			newSplitLine(f.categoryAfter(f.categoryID, false), ""),
+		}
+	}
+	f.splitCursor = 0
+	f.splitErr = nil
+	f.editingSplits = true
+	return f.focusSplitLine()
+}
+
+// updateSplitEditor handles a key while the split editor is open: ↑/↓ move
+// between lines, ←/→ change a line's category, ctrl+n and ctrl+d add and
+// remove lines, enter keeps the lines and esc goes back to how they were.
+// Other keys go to the amount of the current line.
+func (f *TransactionForm) updateSplitEditor(msg tea.KeyMsg) tea.Cmd {
+	line := &f.splits[f.splitCursor]
+	switch msg.String() {
+	case "esc":
+		f.splits = f.splitsBefore
+		f.editingSplits = false
+		return nil
+	case "up":
+		if f.splitCursor > 0 {
+			f.splitCursor--
+		}
+		return f.focusSplitLine()
+	case "down":
+		if f.splitCursor < len(f.splits)-1 {
+			f.splitCursor++
+		}
+		return f.focusSplitLine()
+	case "left", "right":
+		line.categoryID = f.categoryAfter(line.categoryID, msg.String() == "left")
+		return nil
+	case "ctrl+n":
+		last := f.splits[len(f.splits)-1].categoryID
+		f.splits = append(f.splits, newSplitLine(f.categoryAfter(last, false), ""))
+		f.splitCursor = len(f.splits) - 1
+		return f.focusSplitLine()
+	case "ctrl+d":
+		if len(f.splits) > 1 {
+			f.splits = append(f.splits[:f.splitCursor:f.splitCursor], f.splits[f.splitCursor+1:]...)
+			f.splitCursor = min(f.splitCursor, len(f.splits)-1)
+		}
+		return f.focusSplitLine()
+	case "enter":
+		f.applySplits()
+		return nil
+	}
+
+	var cmd tea.Cmd
+	line.amount, cmd = line.amount.Update(msg)
+	f.splitErr = nil
+	return cmd
+}
+
+// applySplits closes the editor if the lines add up to the amount. A
+// single line left isn't a split, so it just becomes the category.
+func (f *TransactionForm) applySplits() {
+	if len(f.splits) == 1 {
+		f.categoryID = f.splits[0].categoryID
+		f.categoryChanged = true
+		f.splits = nil
+		f.editingSplits = false
+		return
+	}
+	if _, err := f.splitItems(); err != nil {
+		f.splitErr = err
+		return
+	}
+	f.categoryID = f.splits[0].categoryID
+	f.editingSplits = false
+}
+
+// splitItems turns the split lines into the transaction's split items,
+// checking they add up to the amount entered. It returns nil when the
+// transaction isn't split.
+func (f *TransactionForm) splitItems() ([]models.SplitItem, error) {
+	if len(f.splits) == 0 {
+		return nil, nil
+	}
+	amount, err := service.ParseAmount(f.amount.Value())
+	if err != nil {
+		return nil, fmt.Errorf("enter the amount before splitting it: %w", err)
+	}
+
+	exprs := make([]string, len(f.splits))
+	for i, line := range f.splits {
+		exprs[i] = line.amount.Value()
+	}
+	amounts, err := service.SplitAmounts(amount, f.currency, exprs)
+	if err != nil {
+		return nil, err
+	}
+
+	items := make([]models.SplitItem, len(f.splits))
+	for i, line := range f.splits {
+		items[i] = models.SplitItem{CategoryID: line.categoryID, Amount: amounts[i]}
+	}
+	if err := models.CheckSplitTotal(amount, f.currency, items); err != nil {
+		return nil, err
+	}
+	return items, nil
+}
+
+// focusSplitLine focuses the amount of the line under the cursor
+func (f *TransactionForm) focusSplitLine() tea.Cmd {
+	for i := range f.splits {
+		f.splits[i].amount.Blur()
+	}
+	return f.splits[f.splitCursor].amount.Focus()
+}
+
+// categoryAfter returns the category before or after id among those of the
+// transaction's type, wrapping around
+func (f *TransactionForm) categoryAfter(id uint, reverse bool) uint {
+	if len(f.categories) == 0 {
+		return id
+	}
+	current := 0
+	for i, cat := range f.categories {
+		if cat.ID == id {
+			current = i
+		}
+	}
+	step := 1
+	if reverse {
+		step = len(f.categories) - 1
+	}
+	return f.categories[(current+step)%len(f.categories)].ID
+}
+
+// categoryName is a category's icon and name, as shown in the form
+func (f *TransactionForm) categoryName(id uint) string {
+	for _, cat := range f.categories {
+		if cat.ID == id {
+			return cat.Icon + " " + cat.Name
+		}
+	}
+	return "?"
+}
+
+// splitsValue sums up the split lines for telling whether they changed
+func (f *TransactionForm) splitsValue() string {
+	parts := make([]string, len(f.splits))
+	for i, line := range f.splits {
+		parts[i] = fmt.Sprintf("%d:%s", line.categoryID, line.amount.Value())
+	}
+	return strings.Join(parts, ",")
+}
+
+// renderSplitRows are the category field's rows for a split transaction,
+// one per line with its amount. The line left empty shows what it takes.
+func (f *TransactionForm) renderSplitRows() []string {
+	value := fmt.Sprintf("✂ split in %d", len(f.splits))
+	if f.focusIndex == 4 {
+		value = styles.SelectedStyle.Render(value + " ('s' to edit)")
+	}
+	rows := []string{lipgloss.JoinHorizontal(lipgloss.Top, styles.FormLabelStyle.Render("Category:"), value)}
+
+	items, _ := f.splitItems()
+	for i, line := range f.splits {
+		amount := line.amount.Value()
+		if items != nil {
+			amount = styles.FormatNumberIn(items[i].Amount, f.currency)
+		}
+		rows = append(rows, lipgloss.JoinHorizontal(lipgloss.Top, styles.FormLabelStyle.Render(""),
+			fmt.Sprintf("└ %-16s %s", truncateText(f.categoryName(line.categoryID), 16), amount)))
+	}
+	return rows
+}
+
+func (f *TransactionForm) renderSplitEditor() string {
+	lines := []string{lipgloss.NewStyle().Bold(true).Render("Split across categories")}
+
+	for i, line := range f.splits {
+		category := fmt.Sprintf("%-18s", truncateText(f.categoryName(line.categoryID), 18))
+		amount := line.amount.View()
+		if i == len(f.splits)-1 && line.amount.Value() == "" && !line.amount.Focused() {
+			amount = lipgloss.NewStyle().Foreground(styles.Muted).Render("rest")
+		}
+		if i == f.splitCursor {
+			lines = append(lines, styles.SelectedStyle.Render("▸ "+category)+" "+styles.FormInputFocusedStyle.Render(amount))
+		} else {
+			lines = append(lines, "  "+category+" "+amount)
+		}
+	}
+	lines = append(lines, styles.HelpStyle.Render("leave the last amount empty for the rest"))
+	if f.splitErr != nil {
+		lines = append(lines, styles.ErrorStyle.Render(f.splitErr.Error()))
+	}
+	lines = append(lines, styles.HelpStyle.Render("[↑/↓]line  [←/→]category  [ctrl+n]add  [ctrl+d]remove  [enter]done  [esc]cancel"))
+	return lipgloss.JoinVertical(lipgloss.Left, lines...)
+}
@@ -1,12 +1,16 @@
 package views
 
 import (
+	"context"
 	"fmt"
 	"strings"
+	"time"
 
 	"github.com/charmbracelet/bubbles/table"
+	"github.com/charmbracelet/bubbles/textinput"
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
 
 	"burnwise/internal/models"
 	"burnwise/internal/service"
@@ -21,28 +25,67 @@ type TransactionList struct {
 	
 	transactions    []*models.Transaction
 	table           table.Model
+	// rowTx is the transaction shown on each table row; the lines of an
+	// expanded split transaction belong to it
+	rowTx           []*models.Transaction
+	// categoryColors are the colors of the category cells in the table,
+	// applied after it is rendered
+	categoryColors  []categoryCellColor
+	// expanded holds the split transactions whose lines are shown
+	expanded        map[uint]bool
 	loading         bool
 	err             error
 	
 	filter          *models.TransactionFilter
 	showFilter      bool
+	// compact drops the currency column and shortens descriptions to fit
+	// terminals narrower than transactionCompactWidth
+	compact         bool
+	// preset is the one-key filter in use, set with 1-4 and cleared with 0
+	preset          models.FilterPreset
+	
+	// Jumping to the first transaction of a month or year, opened with 'g'
+	jumping         bool
+	jumpInput       textinput.Model
+	jumpErr         error
+	
+	// Attaching a file to attachTo, opened with 'A'
+	attaching       bool
+	attachTo        *models.Transaction
+	attachInput     textinput.Model
+	attachErr       error
+}
+
+// transactionSortCycle is the order the 'o' key steps through
+var transactionSortCycle = []struct {
+	by  models.TransactionSortField
+	dir models.SortDirection
+}{
+	{models.SortByDate, models.SortDesc},
+	{models.SortByDate, models.SortAsc},
+	{models.SortByAmount, models.SortDesc},
+	{models.SortByAmount, models.SortAsc},
+	{models.SortByCategory, models.SortAsc},
+	{models.SortByCategory, models.SortDesc},
+}
+
+// categoryCellColor is the color of a category cell with the given text
+type categoryCellColor struct {
+	label string
+	color string
 }
 
 type transactionDeletedMsg struct{}
 type TransactionEditMsg struct{ Transaction *models.Transaction }
 
 func NewTransactionList(txService *service.TransactionService, categoryService *service.CategoryService) *TransactionList {
-	columns := []table.Column{
-		{Title: "Date", Width: 10},
-		{Title: "Type", Width: 8},
-		{Title: "Category", Width: 20},
-		{Title: "Description", Width: 30},
-		{Title: "Amount", Width: 12},
-		{Title: "Currency", Width: 8},
+	filter := &models.TransactionFilter{
+		SortBy:  models.SortByDate,
+		SortDir: models.SortDesc,
 	}
 	
 	t := table.New(
-		table.WithColumns(columns),
+		table.WithColumns(transactionColumns(filter, false)),
 		table.WithFocused(true),
 		table.WithHeight(10),
 	)
@@ -63,8 +106,98 @@ func NewTransactionList(txService *service.TransactionService, categoryService *
 		txService:       txService,
 		categoryService: categoryService,
 		table:           t,
-		filter:          &models.TransactionFilter{},
+		filter:          filter,
+	}
+}
+
+// transactionCompactWidth is the narrowest terminal the full table fits in
+const transactionCompactWidth = 104
+
+// transactionColumns builds the table columns, marking the sorted column.
+// Compact tables fold the currency into the amount column.
+func transactionColumns(filter *models.TransactionFilter, compact bool) []table.Column {
+	arrow := " ▼"
+	if filter.SortDir == models.SortAsc {
+		arrow = " ▲"
+	}
+	
+	title := func(name string, field models.TransactionSortField) string {
+		if filter.SortBy == field {
+			return name + arrow
+		}
+		return name
 	}
+	
+	if compact {
+		return []table.Column{
+			{Title: title("Date", models.SortByDate), Width: 12},
+			{Title: "Type", Width: 8},
+			{Title: title("Category", models.SortByCategory), Width: 14},
+			{Title: "Description", Width: 16},
+			{Title: title("Amount", models.SortByAmount), Width: 16},
+		}
+	}
+	
+	return []table.Column{
+		{Title: title("Date", models.SortByDate), Width: 12},
+		{Title: "Type", Width: 8},
+		{Title: title("Category", models.SortByCategory), Width: 20},
+		{Title: "Description", Width: 30},
+		{Title: title("Amount", models.SortByAmount), Width: 12},
+		{Title: "Currency", Width: 8},
+	}
+}
+
+func (t *TransactionList) cycleSort() {
+	next := 0
+	for i, option := range transactionSortCycle {
+		if option.by == t.filter.SortBy && option.dir == t.filter.SortDir {
+			next = (i + 1) % len(transactionSortCycle)
+			break
+		}
+	}
+	
+	t.filter.SortBy = transactionSortCycle[next].by
+	t.filter.SortDir = transactionSortCycle[next].dir
+	t.table.SetColumns(transactionColumns(t.filter, t.compact))
+}
+
+// SetDateFilter limits the list to the transactions of one day
+func (t *TransactionList) SetDateFilter(day time.Time) {
+	start := time.Date(day.Year(), day.Month(), day.Day(), 0, 0, 0, 0, day.Location())
+	t.filter.ApplyPreset(&models.TransactionFilter{
+		StartDate: start,
+		EndDate:   start.AddDate(0, 0, 1).Add(-time.Second),
+	})
+	t.preset = ""
+}
+
+// ClearDateFilter shows transactions of every date and category again,
+// dropping any preset
+func (t *TransactionList) ClearDateFilter() {
+	t.filter.ApplyPreset(&models.TransactionFilter{})
+	t.preset = ""
+}
+
+// transactionPresetKeys maps the keys of the filter presets to them
+var transactionPresetKeys = map[string]models.FilterPreset{
+	"1": models.PresetThisMonth,
+	"2": models.PresetLastMonth,
+	"3": models.PresetThisYear,
+	"4": models.PresetUncategorized,
+	"!": models.PresetUnusualDates,
+}
+
+// applyPreset filters the list with a preset, replacing any date range or
+// category but keeping the search and sort order
+func (t *TransactionList) applyPreset(preset models.FilterPreset) tea.Cmd {
+	filter, err := t.categoryService.PresetFilter(context.Background(), preset, time.Now())
+	if err != nil {
+		return statusError(err)
+	}
+	t.filter.ApplyPreset(filter)
+	t.preset = preset
+	return t.loadTransactions
 }
 
 func (t *TransactionList) Init() tea.Cmd {
@@ -83,27 +216,52 @@ func (t *TransactionList) Update(msg tea.Msg) (*TransactionList, tea.Cmd) {
 		if t.showFilter {
 			return t.handleFilterKeys(msg)
 		}
+		if t.jumping {
+			return t, t.updateJump(msg)
+		}
+		if t.attaching {
+			return t, t.updateAttach(msg)
+		}
 		
 		switch msg.String() {
+		case "enter":
+			if tx := t.selected(); tx != nil {
+				return t, func() tea.Msg {
+					return TransactionDetailMsg{Transaction: tx}
+				}
+			}
 		case "e":
-			if len(t.transactions) > 0 {
-				selected := t.table.SelectedRow()
-				if selected != nil && len(selected) > 0 {
-					idx := t.table.Cursor()
-					if idx < len(t.transactions) {
-						return t, func() tea.Msg { 
-							return TransactionEditMsg{Transaction: t.transactions[idx]}
-						}
-					}
+			if tx := t.selected(); tx != nil {
+				if tx.Type == models.TransactionTypeTransfer {
+					return t, statusInfo("Transfers can't be edited; delete the transfer and make it again")
+				}
+				return t, func() tea.Msg { 
+					return TransactionEditMsg{Transaction: tx}
 				}
 			}
 		case "d":
+			if tx := t.selected(); tx != nil {
+				return t, t.deleteTransaction(tx.ID)
+			}
+		case "A":
+			if tx := t.selected(); tx != nil {
+				return t, t.openAttach(tx)
+			}
+		case " ":
+			t.toggleSplit()
+			return t, nil
+		case "o":
+			t.cycleSort()
+			return t, t.loadTransactions
+		case "g":
 			if len(t.transactions) > 0 {
-				idx := t.table.Cursor()
-				if idx < len(t.transactions) {
-					return t, t.deleteTransaction(t.transactions[idx].ID)
-				}
+				return t, t.openJump()
 			}
+		case "1", "2", "3", "4", "!":
+			return t, t.applyPreset(transactionPresetKeys[msg.String()])
+		case "0":
+			t.ClearDateFilter()
+			return t, t.loadTransactions
 		case "f":
 			t.showFilter = !t.showFilter
 		case "/":
@@ -140,12 +298,16 @@ func (t *TransactionList) View() string {
 	
 	var content string
 	if len(t.transactions) == 0 {
+		empty := "No transactions found. Press 'n' to add a new transaction."
+		if t.preset == models.PresetUnusualDates {
+			empty = "No transactions with unusual dates. Press '0' to show all."
+		}
 		content = lipgloss.NewStyle().
 			Foreground(styles.Muted).
 			Padding(2).
-			Render("No transactions found. Press 'n' to add a new transaction.")
+			Render(empty)
 	} else {
-		content = t.table.View()
+		content = t.colorCategories(t.table.View())
 	}
 	
 	help := t.renderHelp()
@@ -153,6 +315,12 @@ func (t *TransactionList) View() string {
 	if t.showFilter {
 		content += "\n\n" + t.renderFilter()
 	}
+	if t.jumping {
+		content += "\n" + t.renderJump()
+	}
+	if t.attaching {
+		content += "\n" + t.renderAttach()
+	}
 	
 	return lipgloss.JoinVertical(
 		lipgloss.Left,
@@ -167,16 +335,42 @@ func (t *TransactionList) View() string {
 func (t *TransactionList) SetSize(width, height int) {
 	t.width = width
 	t.height = height
-	t.table.SetHeight(height - 10)
+	t.table.SetHeight(max(1, height-10))
 	t.table.SetWidth(width)
+	
+	if compact := width < transactionCompactWidth; compact != t.compact {
+		// The rows must match the columns, so they are cleared while the
+		// columns change and rebuilt after
+		t.compact = compact
+		t.table.SetRows(nil)
+		t.table.SetColumns(transactionColumns(t.filter, t.compact))
+		t.updateTable()
+	}
 }
 
 func (t *TransactionList) HasTransactions() bool {
 	return len(t.transactions) > 0
 }
 
+// IsJumping reports whether the month to jump to is being typed, when keys
+// go to the prompt
+func (t *TransactionList) IsJumping() bool {
+	return t.jumping
+}
+
+// IsAttaching reports whether the path of a file to attach is being typed,
+// when keys go to the prompt
+func (t *TransactionList) IsAttaching() bool {
+	return t.attaching
+}
+
 func (t *TransactionList) renderHeader() string {
 	title := styles.TitleStyle.Render("💰 All Transactions")
+	if t.preset != "" {
+		title = styles.TitleStyle.Render("💰 Transactions · " + string(t.preset))
+	} else if !t.filter.StartDate.IsZero() {
+		title = styles.TitleStyle.Render("💰 Transactions on " + t.filter.StartDate.Format("Mon Jan 2, 2006"))
+	}
 	
 	count := fmt.Sprintf("%d transactions", len(t.transactions))
 	countStyle := lipgloss.NewStyle().Foreground(styles.Muted)
@@ -184,22 +378,28 @@ func (t *TransactionList) renderHeader() string {
 	return lipgloss.JoinHorizontal(
 		lipgloss.Top,
 		title,
-		lipgloss.NewStyle().Width(t.width - lipgloss.Width(title) - lipgloss.Width(count) - 2).Render(""),
+		lipgloss.NewStyle().Width(max(0, t.width-lipgloss.Width(title)-lipgloss.Width(count)-2)).Render(""),
 		countStyle.Render(count),
 	)
 }
 
 func (t *TransactionList) renderHelp() string {
 	help := []string{
+		"[enter]details",
 		"[n]ew",
 		"[e]dit",
 		"[d]elete",
+		"[A]ttach file",
+		"[space]expand split",
+		"s[o]rt",
+		"[g]o to month",
+		"[1]this month [2]last month [3]this year [4]uncategorized [!]unusual dates [0]all",
 		"[f]ilter",
 		"[/]search",
 		"[esc]back",
 	}
 	
-	return styles.HelpStyle.Render(strings.Join(help, "  "))
+	return styles.HelpStyle.Width(t.width).Render(strings.Join(help, "  "))
 }
 
 func (t *TransactionList) renderFilter() string {
@@ -212,32 +412,103 @@ func (t *TransactionList) renderFilter() string {
 
 func (t *TransactionList) updateTable() {
 	rows := []table.Row{}
+	t.rowTx = t.rowTx[:0]
+	t.categoryColors = t.categoryColors[:0]
 	
 	for _, tx := range t.transactions {
-		date := tx.Date.Format("2006-01-02")
-		txType := string(tx.Type)
-		category := fmt.Sprintf("%s %s", tx.Category.Icon, tx.Category.Name)
+		date := styles.FormatDate(tx.Date)
+		txType := tx.DisplayType()
+		category := tx.CategoryLabel()
+		if tx.IsSplit() {
+			category = t.splitMarker(tx) + category
+		} else if tx.Type != models.TransactionTypeTransfer {
+			t.categoryColors = append(t.categoryColors, categoryCellColor{category, tx.Category.Color})
+		}
 		description := tx.Description
-		if len(description) > 28 {
+		if t.compact {
+			description = truncateText(description, 14)
+		} else if len(description) > 28 {
 			description = description[:28] + "..."
 		}
+		if tx.RefundOfID != nil {
+			// Linked refunds; the details show and open the original
+			description = "↩ " + description
+		}
+		if len(tx.Attachments) > 0 {
+			description = "📎 " + description
+		}
 		
-		amount := fmt.Sprintf("%.2f", tx.Amount)
-		if tx.Type == models.TransactionTypeExpense {
+		amount := styles.FormatNumberIn(tx.Amount, tx.Currency)
+		if tx.IsOutflow() {
 			amount = "-" + amount
-		} else if tx.Type == models.TransactionTypeIncome {
+		} else {
 			amount = "+" + amount
 		}
 		
 		row := table.Row{date, txType, category, description, amount, tx.Currency}
+		if t.compact {
+			row = table.Row{date, txType, category, description, amount + " " + tx.Currency}
+		}
 		rows = append(rows, row)
+		t.rowTx = append(t.rowTx, tx)
+		
+		if tx.IsSplit() && t.expanded[tx.ID] {
+			for _, line := range t.splitRows(tx) {
+				rows = append(rows, line)
+				t.rowTx = append(t.rowTx, tx)
+			}
+		}
 	}
 	
 	t.table.SetRows(rows)
 }
 
+// colorCategories colors the category cells of the rendered table. The
+// table truncates cells counting color codes as text, which would cut
+// colored cells off, so the colors are put in afterwards. Cells are told
+// apart by their text, and the selected row keeps the selection's colors.
+func (t *TransactionList) colorCategories(view string) string {
+	columns := t.table.Columns()
+	if len(columns) < 3 {
+		return view
+	}
+	// Each cell is padded by a space on either side
+	left := columns[0].Width + columns[1].Width + 4
+	right := left + columns[2].Width + 2
+	
+	lines := strings.Split(view, "\n")
+	// The first two lines are the header and its border
+	for i := 2; i < len(lines); i++ {
+		line := lines[i]
+		if ansi.Strip(line) != line {
+			continue
+		}
+		cell := ansi.Cut(line, left, right)
+		color, ok := t.categoryCellColor(strings.TrimSpace(cell))
+		if !ok {
+			continue
+		}
+		lines[i] = ansi.Cut(line, 0, left) +
+			lipgloss.NewStyle().Foreground(styles.CategoryColor(color)).Render(cell) +
+			ansi.Cut(line, right, ansi.StringWidth(line))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// categoryCellColor returns the color of the category cell with the given
+// text, which may have been truncated
+func (t *TransactionList) categoryCellColor(text string) (string, bool) {
+	prefix, truncated := strings.CutSuffix(text, "…")
+	for _, cell := range t.categoryColors {
+		if cell.label == text || truncated && strings.HasPrefix(cell.label, prefix) {
+			return cell.color, true
+		}
+	}
+	return "", false
+}
+
 func (t *TransactionList) loadTransactions() tea.Msg {
-	transactions, err := t.txService.GetByFilter(t.filter)
+	transactions, err := t.txService.GetByFilter(context.Background(), t.filter)
 	return transactionsLoadedMsg{
 		transactions: transactions,
 		err:          err,
@@ -246,7 +517,7 @@ func (t *TransactionList) loadTransactions() tea.Msg {
 
 func (t *TransactionList) deleteTransaction(id uint) tea.Cmd {
 	return func() tea.Msg {
-		if err := t.txService.Delete(id); err != nil {
+		if err := t.txService.Delete(context.Background(), id); err != nil {
 			return errMsg{err}
 		}
 		return transactionDeletedMsg{}
@@ -0,0 +1,57 @@
+package views
+
+import (
+	"context"
+	"path/filepath"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"burnwise/internal/models"
+	"burnwise/internal/ui/styles"
+)
+
+// openAttach asks for the path of a file, such as a receipt, to attach to
+// tx
+func (t *TransactionList) openAttach(tx *models.Transaction) tea.Cmd {
+	input := textinput.New()
+	input.Placeholder = "~/Documents/receipts/coffee.pdf"
+	input.CharLimit = 1024
+	input.Width = max(20, min(60, t.width-20))
+
+	t.attachInput = input
+	t.attachTo = tx
+	t.attachErr = nil
+	t.attaching = true
+	return t.attachInput.Focus()
+}
+
+// updateAttach handles a key while the path is being typed or pasted:
+// enter attaches the file and esc cancels
+func (t *TransactionList) updateAttach(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		t.attaching = false
+		return nil
+	case "enter":
+		attachment, err := t.txService.AddAttachment(context.Background(), t.attachTo.ID, t.attachInput.Value())
+		if err != nil {
+			t.attachErr = err
+			return nil
+		}
+		t.attaching = false
+		return tea.Batch(t.loadTransactions, statusInfo("Attached "+filepath.Base(attachment.Path)))
+	}
+
+	var cmd tea.Cmd
+	t.attachInput, cmd = t.attachInput.Update(msg)
+	return cmd
+}
+
+func (t *TransactionList) renderAttach() string {
+	line := styles.FormLabelStyle.Render("Attach file:") + styles.FormInputFocusedStyle.Render(t.attachInput.View())
+	if t.attachErr != nil {
+		line += "\n" + styles.ErrorStyle.Render(t.attachErr.Error())
+	}
+	return line + "\n" + styles.HelpStyle.Render("[enter]attach  [esc]cancel")
+}
@@ -0,0 +1,75 @@
+package views
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/charmbracelet/bubbles/textinput"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"burnwise/internal/ui/styles"
+)
+
+// openJump asks for the month or year to move the cursor to
+func (t *TransactionList) openJump() tea.Cmd {
+	input := textinput.New()
+	input.Placeholder = "YYYY-MM or YYYY"
+	input.CharLimit = 7
+	input.Width = 16
+
+	t.jumpInput = input
+	t.jumpErr = nil
+	t.jumping = true
+	return t.jumpInput.Focus()
+}
+
+// updateJump handles a key while the month is being typed: enter moves the
+// cursor to the first transaction listed in that period and esc cancels
+func (t *TransactionList) updateJump(msg tea.KeyMsg) tea.Cmd {
+	switch msg.String() {
+	case "esc":
+		t.jumping = false
+		return nil
+	case "enter":
+		start, end, label, err := parseJumpPeriod(t.jumpInput.Value())
+		if err != nil {
+			t.jumpErr = err
+			return nil
+		}
+		for _, tx := range t.transactions {
+			if !tx.Date.Before(start) && tx.Date.Before(end) {
+				t.table.SetCursor(t.rowOf(tx))
+				t.jumping = false
+				return nil
+			}
+		}
+		t.jumpErr = fmt.Errorf("no transactions in %s", label)
+		return nil
+	}
+
+	var cmd tea.Cmd
+	t.jumpInput, cmd = t.jumpInput.Update(msg)
+	return cmd
+}
+
+// parseJumpPeriod reads "2024-03" as March 2024 and "2024" as the whole
+// year, returning its start, the start of the next one and its name
+func parseJumpPeriod(value string) (start, end time.Time, label string, err error) {
+	value = strings.TrimSpace(value)
+	if month, err := time.ParseInLocation("2006-01", value, time.Local); err == nil {
+		return month, month.AddDate(0, 1, 0), month.Format("January 2006"), nil
+	}
+	if year, err := time.ParseInLocation("2006", value, time.Local); err == nil {
+		return year, year.AddDate(1, 0, 0), year.Format("2006"), nil
+	}
+	return time.Time{}, time.Time{}, "", fmt.Errorf("enter a month as YYYY-MM or a year as YYYY")
+}
+
+func (t *TransactionList) renderJump() string {
+	line := styles.FormLabelStyle.Render("Go to:") + styles.FormInputFocusedStyle.Render(t.jumpInput.View())
+	if t.jumpErr != nil {
+		line += "  " + styles.ErrorStyle.Render(t.jumpErr.Error())
+	}
+	return line + "\n" + styles.HelpStyle.Render("[enter]jump  [esc]cancel")
+}
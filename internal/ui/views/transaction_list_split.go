@@ -0,0 +1,82 @@
+package views
+
+import (
+	"github.com/charmbracelet/bubbles/table"
+
+	"burnwise/internal/models"
+	"burnwise/internal/ui/styles"
+)
+
+// selected returns the transaction of the row under the cursor, which for
+// the line of an expanded split is the split transaction itself
+func (t *TransactionList) selected() *models.Transaction {
+	idx := t.table.Cursor()
+	if idx < 0 || idx >= len(t.rowTx) {
+		return nil
+	}
+	return t.rowTx[idx]
+}
+
+// rowOf returns the row tx is shown on
+func (t *TransactionList) rowOf(tx *models.Transaction) int {
+	for i, rowTx := range t.rowTx {
+		if rowTx == tx {
+			return i
+		}
+	}
+	return 0
+}
+
+// toggleSplit shows or hides the lines of the selected split transaction,
+// keeping the cursor on it
+func (t *TransactionList) toggleSplit() {
+	tx := t.selected()
+	if tx == nil || !tx.IsSplit() {
+		return
+	}
+	if t.expanded == nil {
+		t.expanded = make(map[uint]bool)
+	}
+	t.expanded[tx.ID] = !t.expanded[tx.ID]
+	t.updateTable()
+	t.table.SetCursor(t.rowOf(tx))
+}
+
+// splitMarker shows that a split transaction's lines can be expanded, or
+// are
+func (t *TransactionList) splitMarker(tx *models.Transaction) string {
+	if t.expanded[tx.ID] {
+		return "▾ "
+	}
+	return "▸ "
+}
+
+// splitRows are the rows listing the lines of a split transaction under
+// it, each with its own category and amount
+func (t *TransactionList) splitRows(tx *models.Transaction) []table.Row {
+	var rows []table.Row
+	for _, line := range tx.Splits {
+		category := "└ " + line.Category.Icon + " " + line.Category.Name
+		t.categoryColors = append(t.categoryColors, categoryCellColor{category, line.Category.Color})
+		description := line.Description
+		if t.compact {
+			description = truncateText(description, 14)
+		} else {
+			description = truncateText(description, 28)
+		}
+
+		amount := styles.FormatNumberIn(line.Amount, tx.Currency)
+		if tx.IsOutflow() {
+			amount = "-" + amount
+		} else {
+			amount = "+" + amount
+		}
+
+		row := table.Row{"", "", category, description, amount, tx.Currency}
+		if t.compact {
+			row = table.Row{"", "", category, description, amount + " " + tx.Currency}
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
@@ -0,0 +1,68 @@
+package views
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/charmbracelet/lipgloss"
+	"github.com/charmbracelet/x/ansi"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"burnwise/internal/models"
+)
+
+func TestTransactionList_ColorCategories(t *testing.T) {
+	profile := lipgloss.ColorProfile()
+	lipgloss.SetColorProfile(0) // termenv.TrueColor, so that colors are rendered
+	defer lipgloss.SetColorProfile(profile)
+
+	account := uint(1)
+	list := NewTransactionList(nil, nil)
+	list.transactions = []*models.Transaction{
+		{Type: models.TransactionTypeTransfer, Amount: 100, Currency: "USD", Date: time.Now(), AccountID: &account},
+		{Type: models.TransactionTypeExpense, Amount: 12, Currency: "USD", Date: time.Now(),
+			Category: models.Category{Name: "Food", Icon: "🍔", Color: "#ff0000"}},
+		{Type: models.TransactionTypeExpense, Amount: 30, Currency: "USD", Date: time.Now(),
+			Category: models.Category{Name: "Entertainment and leisure", Icon: "🎬", Color: "#00ff00"}},
+	}
+	list.updateTable()
+
+	plain := list.table.View()
+	colored := list.colorCategories(plain)
+	assert.Equal(t, ansi.Strip(plain), ansi.Strip(colored))
+
+	lines := strings.Split(colored, "\n")
+	require.Greater(t, len(lines), 4)
+	// The selected transfer keeps the selection's colors
+	assert.NotContains(t, lines[2], "38;2;")
+	assert.Contains(t, lines[3], "\x1b[38;2;255;0;0m")
+	// Truncated names are colored too
+	assert.Contains(t, lines[4], "\x1b[38;2;0;255;0m")
+}
+
+func TestParseJumpPeriod(t *testing.T) {
+	start, end, label, err := parseJumpPeriod(" 2025-02 ")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2025, time.February, 1, 0, 0, 0, 0, time.Local), start)
+	assert.Equal(t, time.Date(2025, time.March, 1, 0, 0, 0, 0, time.Local), end)
+	assert.Equal(t, "February 2025", label)
+
+	// December runs into the next year
+	start, end, _, err = parseJumpPeriod("2024-12")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, time.December, 1, 0, 0, 0, 0, time.Local), start)
+	assert.Equal(t, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local), end)
+
+	start, end, label, err = parseJumpPeriod("2024")
+	require.NoError(t, err)
+	assert.Equal(t, time.Date(2024, time.January, 1, 0, 0, 0, 0, time.Local), start)
+	assert.Equal(t, time.Date(2025, time.January, 1, 0, 0, 0, 0, time.Local), end)
+	assert.Equal(t, "2024", label)
+
+	for _, value := range []string{"", "2025-13", "2025-2-1", "March", "25"} {
+		_, _, _, err := parseJumpPeriod(value)
+		assert.ErrorContains(t, err, "YYYY-MM", value)
+	}
+}
@@ -0,0 +1,92 @@
+package views
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"burnwise/internal/models"
+	"burnwise/internal/ui/styles"
+)
+
+// WelcomeClosedMsg is sent when the first-run welcome is dismissed
+type WelcomeClosedMsg struct{}
+
+// Welcome is shown once, after the default categories were created on a
+// fresh database
+type Welcome struct {
+	width  int
+	height int
+
+	incomeCategories  int
+	expenseCategories int
+}
+
+// welcomeKeys are the shortcuts pointed out to new users
+var welcomeKeys = [][2]string{
+	{"n", "add a transaction"},
+	{"b", "set up budgets"},
+	{"s", "add recurring bills and income"},
+	{"c", "rename, recolor or merge categories"},
+	{"r", "see reports"},
+	{":", "search every action"},
+	{"q", "quit"},
+}
+
+func NewWelcome(created []*models.Category) *Welcome {
+	w := &Welcome{}
+	for _, category := range created {
+		if category.Type == models.TransactionTypeIncome {
+			w.incomeCategories++
+		} else {
+			w.expenseCategories++
+		}
+	}
+	return w
+}
+
+func (w *Welcome) Init() tea.Cmd {
+	return nil
+}
+
+func (w *Welcome) Update(msg tea.Msg) (*Welcome, tea.Cmd) {
+	if msg, ok := msg.(tea.KeyMsg); ok {
+		switch msg.String() {
+		case "q", "ctrl+c":
+			return w, tea.Quit
+		case "enter", "esc", " ":
+			return w, func() tea.Msg { return WelcomeClosedMsg{} }
+		}
+	}
+	return w, nil
+}
+
+func (w *Welcome) View() string {
+	title := styles.TitleStyle.Render("🔥 Welcome to BurnWise")
+
+	created := fmt.Sprintf("Created %d income and %d expense categories to get you started.",
+		w.incomeCategories, w.expenseCategories)
+
+	rows := []string{title, "", created, ""}
+	for _, key := range welcomeKeys {
+		rows = append(rows, fmt.Sprintf("%s  %s",
+			lipgloss.NewStyle().Foreground(styles.Primary).Bold(true).Width(3).Render(key[0]),
+			key[1]))
+	}
+
+	box := lipgloss.NewStyle().
+		Border(lipgloss.RoundedBorder()).
+		BorderForeground(styles.Primary).
+		Padding(1, 2).
+		Width(60).
+		Render(lipgloss.JoinVertical(lipgloss.Left, rows...))
+
+	content := lipgloss.JoinVertical(lipgloss.Left, box, "", styles.HelpStyle.Render("[enter]continue  [q]uit"))
+	return lipgloss.Place(w.width, w.height, lipgloss.Center, lipgloss.Center, content)
+}
+
+func (w *Welcome) SetSize(width, height int) {
+	w.width = width
+	w.height = height
+}
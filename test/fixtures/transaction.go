@@ -55,11 +55,27 @@ func (b *TransactionBuilder) WithDescription(description string) *TransactionBui
 	return b
 }
 
+func (b *TransactionBuilder) AsRefund() *TransactionBuilder {
+	b.tx.Type = models.TransactionTypeExpense
+	b.tx.IsRefund = true
+	return b
+}
+
 func (b *TransactionBuilder) WithDate(date time.Time) *TransactionBuilder {
 	b.tx.Date = date
 	return b
 }
 
+func (b *TransactionBuilder) WithRecurring(recurringID uint) *TransactionBuilder {
+	b.tx.RecurringTransactionID = &recurringID
+	return b
+}
+
+func (b *TransactionBuilder) WithAccount(accountID uint) *TransactionBuilder {
+	b.tx.AccountID = &accountID
+	return b
+}
+
 func (b *TransactionBuilder) Build() *models.Transaction {
 	return b.tx
 }
@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"path/filepath"
+	"strings"
 	"testing"
 	"time"
 
@@ -23,7 +24,9 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 		t.Fatalf("Failed to create test data directory: %v", err)
 	}
 
-	dbPath := filepath.Join(testDataDir, fmt.Sprintf("test_%s_%d.db", t.Name(), time.Now().UnixNano()))
+	// Subtest names contain slashes, which would otherwise be treated as directories
+	testName := strings.ReplaceAll(t.Name(), "/", "_")
+	dbPath := filepath.Join(testDataDir, fmt.Sprintf("test_%s_%d.db", testName, time.Now().UnixNano()))
 
 	db, err := gorm.Open(sqlite.Open(dbPath), &gorm.Config{
 		Logger: logger.Default.LogMode(logger.Silent),
@@ -34,9 +37,16 @@ func SetupTestDB(t *testing.T) *gorm.DB {
 		&models.Transaction{},
 		&models.Category{},
 		&models.Budget{},
+		&models.BudgetCategory{},
+		&models.BudgetAmountHistory{},
 		&models.CategoryHistory{},
 		&models.RecurringTransaction{},
 		&models.RecurringTransactionOccurrence{},
+		&models.RecurringTransactionPriceHistory{},
+		&models.IncomeAllocation{},
+		&models.Account{},
+		&models.Attachment{},
+		&models.SplitItem{},
 	)
 	require.NoError(t, err)
 